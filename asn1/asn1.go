@@ -22,6 +22,10 @@
 //   - checkInteger() allows integers that are not minimally encoded (and
 //     so are not correct DER).
 //   - parseObjectIdentifier() allows zero-length OIDs.
+//   - Extra "laxutf8" and "laxtime" tags that recursively apply and accept,
+//     respectively, UTF8Strings which are not valid UTF-8 and times which are
+//     not canonically encoded. The errors returned in their absence are
+//     InvalidUTF8Error and InvalidTimeError.
 //   - Better diagnostics on which particular field causes errors.
 package asn1
 
@@ -42,6 +46,7 @@ import (
 	"math/big"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 	"unicode/utf16"
 	"unicode/utf8"
@@ -364,9 +369,20 @@ func parseBase128Int(bytes []byte, initOffset int, fieldName string) (ret, offse
 	return
 }
 
+// InvalidTimeError is returned for a UTCTime or GeneralizedTime which parses
+// but is not in its canonical form, e.g. as it has fractional seconds or a
+// +0000 offset. The "laxtime" parameter accepts such times.
+type InvalidTimeError struct {
+	Given, Serialized string
+}
+
+func (e InvalidTimeError) Error() string {
+	return fmt.Sprintf("asn1: time did not serialize back to the original value and may be invalid: given %q, but serialized as %q", e.Given, e.Serialized)
+}
+
 // UTCTime
 
-func parseUTCTime(bytes []byte) (ret time.Time, err error) {
+func parseUTCTime(bytes []byte, lax bool) (ret time.Time, err error) {
 	s := string(bytes)
 
 	formatStr := "0601021504Z0700"
@@ -379,8 +395,8 @@ func parseUTCTime(bytes []byte) (ret time.Time, err error) {
 		return
 	}
 
-	if serialized := ret.Format(formatStr); serialized != s {
-		err = fmt.Errorf("asn1: time did not serialize back to the original value and may be invalid: given %q, but serialized as %q", s, serialized)
+	if serialized := ret.Format(formatStr); serialized != s && !lax {
+		err = InvalidTimeError{Given: s, Serialized: serialized}
 		return
 	}
 
@@ -394,7 +410,7 @@ func parseUTCTime(bytes []byte) (ret time.Time, err error) {
 
 // parseGeneralizedTime parses the GeneralizedTime from the given byte slice
 // and returns the resulting time.
-func parseGeneralizedTime(bytes []byte) (ret time.Time, err error) {
+func parseGeneralizedTime(bytes []byte, lax bool) (ret time.Time, err error) {
 	const formatStr = "20060102150405Z0700"
 	s := string(bytes)
 
@@ -402,8 +418,8 @@ func parseGeneralizedTime(bytes []byte) (ret time.Time, err error) {
 		return
 	}
 
-	if serialized := ret.Format(formatStr); serialized != s {
-		err = fmt.Errorf("asn1: time did not serialize back to the original value and may be invalid: given %q, but serialized as %q", s, serialized)
+	if serialized := ret.Format(formatStr); serialized != s && !lax {
+		err = InvalidTimeError{Given: s, Serialized: serialized}
 	}
 
 	return
@@ -518,11 +534,29 @@ func parseT61String(bytes []byte) (ret string, err error) {
 
 // UTF8String
 
+// InvalidUTF8Error is returned for a UTF8String which is not valid UTF-8.
+// The "laxutf8" parameter replaces the invalid bytes of such strings with
+// U+FFFD instead.
+type InvalidUTF8Error struct {
+	Field string
+}
+
+func (e InvalidUTF8Error) Error() string {
+	var prefix string
+	if e.Field != "" {
+		prefix = e.Field + ": "
+	}
+	return "asn1: " + prefix + "invalid UTF-8 string"
+}
+
 // parseUTF8String parses an ASN.1 UTF8String (raw UTF-8) from the given byte
 // array and returns it.
-func parseUTF8String(bytes []byte) (ret string, err error) {
+func parseUTF8String(bytes []byte, lax bool, fieldName string) (ret string, err error) {
 	if !utf8.Valid(bytes) {
-		return "", errors.New("asn1: invalid UTF-8 string")
+		if lax {
+			return strings.ToValidUTF8(string(bytes), "\uFFFD"), nil
+		}
+		return "", InvalidUTF8Error{Field: fieldName}
 	}
 	return string(bytes), nil
 }
@@ -647,7 +681,8 @@ func parseTagAndLength(bytes []byte, initOffset int, fieldName string) (ret tagA
 // parseSequenceOf is used for SEQUENCE OF and SET OF values. It tries to parse
 // a number of ASN.1 values from the given byte slice and returns them as a
 // slice of Go values of the given type.
-func parseSequenceOf(bytes []byte, sliceType reflect.Type, elemType reflect.Type, lax bool, fieldName string) (ret reflect.Value, err error) {
+func parseSequenceOf(bytes []byte, sliceType reflect.Type, elemType reflect.Type, outer fieldParameters) (ret reflect.Value, err error) {
+	fieldName := outer.name
 	matchAny, expectedTag, compoundType, ok := getUniversalType(elemType)
 	if !ok {
 		err = StructuralError{"unknown Go type for slice", fieldName}
@@ -686,7 +721,7 @@ func parseSequenceOf(bytes []byte, sliceType reflect.Type, elemType reflect.Type
 		numElements++
 	}
 	ret = reflect.MakeSlice(sliceType, numElements, numElements)
-	params := fieldParameters{lax: lax}
+	params := outer.laxity()
 	offset := 0
 	for i := 0; i < numElements; i++ {
 		offset, err = parseField(ret.Index(i), bytes, offset, params)
@@ -798,7 +833,7 @@ func parseField(v reflect.Value, bytes []byte, initOffset int, params fieldParam
 			case TagT61String:
 				result, err = parseT61String(innerBytes)
 			case TagUTF8String:
-				result, err = parseUTF8String(innerBytes)
+				result, err = parseUTF8String(innerBytes, params.laxUTF8, params.name)
 			case TagInteger:
 				result, err = parseInt64(innerBytes, params.lax, params.name)
 			case TagBitString:
@@ -806,9 +841,9 @@ func parseField(v reflect.Value, bytes []byte, initOffset int, params fieldParam
 			case TagOID:
 				result, err = parseObjectIdentifier(innerBytes, params.lax, params.name)
 			case TagUTCTime:
-				result, err = parseUTCTime(innerBytes)
+				result, err = parseUTCTime(innerBytes, params.laxTime)
 			case TagGeneralizedTime:
-				result, err = parseGeneralizedTime(innerBytes)
+				result, err = parseGeneralizedTime(innerBytes, params.laxTime)
 			case TagOctetString:
 				result = innerBytes
 			case TagBMPString:
@@ -965,9 +1000,9 @@ func parseField(v reflect.Value, bytes []byte, initOffset int, params fieldParam
 		var time time.Time
 		var err1 error
 		if universalTag == TagUTCTime {
-			time, err1 = parseUTCTime(innerBytes)
+			time, err1 = parseUTCTime(innerBytes, params.laxTime)
 		} else {
-			time, err1 = parseGeneralizedTime(innerBytes)
+			time, err1 = parseGeneralizedTime(innerBytes, params.laxTime)
 		}
 		if err1 == nil {
 			v.Set(reflect.ValueOf(time))
@@ -1041,6 +1076,8 @@ func parseField(v reflect.Value, bytes []byte, initOffset int, params fieldParam
 			innerParams := parseFieldParameters(field.Tag.Get("asn1"))
 			innerParams.name = field.Name
 			innerParams.lax = params.lax
+			innerParams.laxUTF8 = innerParams.laxUTF8 || params.laxUTF8
+			innerParams.laxTime = innerParams.laxTime || params.laxTime
 			innerOffset, err = parseField(val.Field(i), innerBytes, innerOffset, innerParams)
 			if err != nil {
 				return
@@ -1057,7 +1094,7 @@ func parseField(v reflect.Value, bytes []byte, initOffset int, params fieldParam
 			reflect.Copy(val, reflect.ValueOf(innerBytes))
 			return
 		}
-		newSlice, err1 := parseSequenceOf(innerBytes, sliceType, sliceType.Elem(), params.lax, params.name)
+		newSlice, err1 := parseSequenceOf(innerBytes, sliceType, sliceType.Elem(), params)
 		if err1 == nil {
 			val.Set(newSlice)
 		}
@@ -1075,7 +1112,7 @@ func parseField(v reflect.Value, bytes []byte, initOffset int, params fieldParam
 		case TagT61String:
 			v, err = parseT61String(innerBytes)
 		case TagUTF8String:
-			v, err = parseUTF8String(innerBytes)
+			v, err = parseUTF8String(innerBytes, params.laxUTF8, params.name)
 		case TagGeneralString:
 			// GeneralString is specified in ISO-2022/ECMA-35,
 			// A brief review suggests that it includes structures
@@ -1169,6 +1206,8 @@ func setDefaultValue(v reflect.Value, params fieldParameters) (ok bool) {
 //	set         causes a SET, rather than a SEQUENCE type to be expected
 //	tag:x       specifies the ASN.1 tag number; implies ASN.1 CONTEXT SPECIFIC
 //	lax         relax strict encoding checks for this field, and for any fields within it
+//	laxutf8     accept invalid UTF-8 in UTF8Strings in this field, and in any fields within it
+//	laxtime     accept non-canonical times in this field, and in any fields within it
 //
 // If the type of the first field of a structure is RawContent then the raw
 // ASN1 contents of the struct will be stored in it.
@@ -7,6 +7,7 @@ package asn1
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math"
 	"math/big"
@@ -331,7 +332,7 @@ var utcTestData = []timeTest{
 
 func TestUTCTime(t *testing.T) {
 	for i, test := range utcTestData {
-		ret, err := parseUTCTime([]byte(test.in))
+		ret, err := parseUTCTime([]byte(test.in), false)
 		if err != nil {
 			if test.ok {
 				t.Errorf("#%d: parseUTCTime(%q) = error %v", i, test.in, err)
@@ -378,7 +379,7 @@ var generalizedTimeTestData = []timeTest{
 
 func TestGeneralizedTime(t *testing.T) {
 	for i, test := range generalizedTimeTestData {
-		ret, err := parseGeneralizedTime([]byte(test.in))
+		ret, err := parseGeneralizedTime([]byte(test.in), false)
 		if (err == nil) != test.ok {
 			t.Errorf("#%d: Incorrect error result (did fail? %v, expected: %v)", i, err == nil, test.ok)
 		}
@@ -1128,6 +1129,60 @@ func TestUnmarshalInvalidUTF8(t *testing.T) {
 	}
 }
 
+func TestUnmarshalLaxUTF8(t *testing.T) {
+	data := []byte("0\x05\f\x03a\xc9c")
+	var result invalidUTF8Test
+	if _, err := Unmarshal(data, &result); !errors.As(err, new(InvalidUTF8Error)) {
+		t.Errorf("Unmarshal()=%v, want InvalidUTF8Error", err)
+	}
+	if _, err := UnmarshalWithParams(data, &result, "laxutf8"); err != nil {
+		t.Fatalf("UnmarshalWithParams(laxutf8)=%v", err)
+	}
+	if want := "a\uFFFDc"; result.Str != want {
+		t.Errorf("UnmarshalWithParams(laxutf8)=%q, want %q", result.Str, want)
+	}
+}
+
+type laxTimeTest struct {
+	Times []time.Time
+}
+
+func TestUnmarshalLaxTime(t *testing.T) {
+	// A SEQUENCE holding a SEQUENCE of a GeneralizedTime with a +0000 offset.
+	data := []byte("0\x17\x30\x15\x18\x1320100102030405+0000")
+	var result laxTimeTest
+	if _, err := Unmarshal(data, &result); !errors.As(err, new(InvalidTimeError)) {
+		t.Errorf("Unmarshal()=%v, want InvalidTimeError", err)
+	}
+	if _, err := UnmarshalWithParams(data, &result, "laxtime"); err != nil {
+		t.Fatalf("UnmarshalWithParams(laxtime)=%v", err)
+	}
+	if want := time.Date(2010, 1, 2, 3, 4, 5, 0, time.UTC); len(result.Times) != 1 || !result.Times[0].Equal(want) {
+		t.Errorf("UnmarshalWithParams(laxtime)=%v, want [%v]", result.Times, want)
+	}
+}
+
+type laxFieldsTest struct {
+	Str   string      `asn1:"utf8,laxutf8"`
+	Times []time.Time `asn1:"laxtime"`
+}
+
+func TestUnmarshalLaxFieldTags(t *testing.T) {
+	// A SEQUENCE holding an invalid UTF8String and a SEQUENCE of a
+	// GeneralizedTime with a +0000 offset.
+	data := []byte("0\x1c\f\x03a\xc9c\x30\x15\x18\x1320100102030405+0000")
+	var result laxFieldsTest
+	if _, err := Unmarshal(data, &result); err != nil {
+		t.Fatalf("Unmarshal()=%v", err)
+	}
+	if want := "a\uFFFDc"; result.Str != want {
+		t.Errorf("Unmarshal().Str=%q, want %q", result.Str, want)
+	}
+	if want := time.Date(2010, 1, 2, 3, 4, 5, 0, time.UTC); len(result.Times) != 1 || !result.Times[0].Equal(want) {
+		t.Errorf("Unmarshal().Times=%v, want [%v]", result.Times, want)
+	}
+}
+
 func TestMarshalNilValue(t *testing.T) {
 	nilValueTestData := []interface{}{
 		nil,
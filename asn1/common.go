@@ -84,6 +84,8 @@ type fieldParameters struct {
 	set          bool   // true iff this should be encoded as a SET
 	omitEmpty    bool   // true iff this should be omitted if empty when marshaling.
 	lax          bool   // true iff unmarshalling should skip some error checks
+	laxUTF8      bool   // true iff unmarshalling should accept invalid UTF-8
+	laxTime      bool   // true iff unmarshalling should accept non-canonical times
 	name         string // name of field for better diagnostics
 
 	// Invariants:
@@ -143,11 +145,20 @@ func parseFieldParameters(str string) (ret fieldParameters) {
 			ret.omitEmpty = true
 		case part == "lax":
 			ret.lax = true
+		case part == "laxutf8":
+			ret.laxUTF8 = true
+		case part == "laxtime":
+			ret.laxTime = true
 		}
 	}
 	return
 }
 
+// laxity returns the parameters of p which apply to the fields within it.
+func (p fieldParameters) laxity() fieldParameters {
+	return fieldParameters{lax: p.lax, laxUTF8: p.laxUTF8, laxTime: p.laxTime}
+}
+
 // Given a reflected Go type, getUniversalType returns the default tag number
 // and expected compound flag.
 func getUniversalType(t reflect.Type) (matchAny bool, tagNumber int, isCompound, ok bool) {
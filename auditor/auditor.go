@@ -0,0 +1,324 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auditor checks that logs honor the SCTs they issue: once the
+// Maximum Merge Delay (MMD) of an SCT has passed, the entry it promises must
+// be included in the tree of the log, which is checked with an inclusion
+// proof against an STH verified to be consistent with the STHs seen before.
+package auditor
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
+	"github.com/RarimoVoting/certificate-transparency-go/gossip/feedback"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/monitor"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"k8s.io/klog/v2"
+)
+
+// ErrUnknownLog is returned for SCTs of logs the Auditor does not know.
+var ErrUnknownLog = errors.New("unknown log")
+
+// SCT is an SCT to audit, reduced to what identifies the entry it promises.
+type SCT struct {
+	// LogID is the ID of the log which issued the SCT.
+	LogID [sha256.Size]byte
+	// Timestamp is the timestamp of the SCT.
+	Timestamp uint64
+	// LeafHash is the Merkle leaf hash of the entry the SCT promises.
+	LeafHash [sha256.Size]byte
+	// Source describes where the SCT was found.
+	Source string
+}
+
+// Status is the outcome of the audit of an SCT.
+type Status int
+
+// Outcomes of the audit of an SCT.
+const (
+	// Included means that the log proved the inclusion of the entry.
+	Included Status = iota
+	// NotIncluded means that the log does not know the entry.
+	NotIncluded
+	// InvalidProof means that the log served an inclusion proof which does
+	// not verify.
+	InvalidProof
+)
+
+func (s Status) String() string {
+	switch s {
+	case Included:
+		return "Included"
+	case NotIncluded:
+		return "NotIncluded"
+	case InvalidProof:
+		return "InvalidProof"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// Result reports the outcome of the audit of an SCT.
+type Result struct {
+	SCT    *SCT
+	Status Status
+	// STH is the STH the inclusion was checked against.
+	STH *ct.SignedTreeHead
+	// Index is the index of the entry in the log, if it is Included.
+	Index int64
+	// Err holds the details of a failed audit.
+	Err error
+}
+
+func (r *Result) String() string {
+	s := fmt.Sprintf("%s: SCT of log %x timestamped %d for leaf hash %x: %s", r.SCT.Source, r.SCT.LogID, r.SCT.Timestamp, r.SCT.LeafHash, r.Status)
+	if r.Status == Included {
+		s += fmt.Sprintf(" at index %d", r.Index)
+	} else if r.Err != nil {
+		s += fmt.Sprintf(": %v", r.Err)
+	}
+	return s
+}
+
+// Options configures an Auditor.
+type Options struct {
+	// Grace is how long after the MMD of an SCT its inclusion is checked,
+	// giving some leeway to logs and to the propagation of their STHs.
+	Grace time.Duration
+	// Monitor configures the Monitor which fetches and verifies the STHs.
+	Monitor monitor.Options
+}
+
+// Auditor audits SCTs of a set of logs. It is safe for concurrent use.
+type Auditor struct {
+	mon  *monitor.Monitor
+	logs map[[sha256.Size]byte]*monitor.Log
+	opts Options
+	now  func() time.Time
+
+	mu      sync.Mutex
+	pending []*SCT
+}
+
+// New creates an Auditor of SCTs of logs, which keeps the history of STHs of
+// the logs in store.
+func New(logs []*monitor.Log, store ctutil.STHStorage, opts Options) *Auditor {
+	byID := make(map[[sha256.Size]byte]*monitor.Log)
+	for _, l := range logs {
+		byID[l.ID] = l
+	}
+	return &Auditor{mon: monitor.New(logs, store, opts.Monitor), logs: byID, opts: opts, now: time.Now}
+}
+
+// Add queues sct for auditing. Returns ErrUnknownLog if the log of sct is
+// not known.
+func (a *Auditor) Add(sct *SCT) error {
+	if _, ok := a.logs[sct.LogID]; !ok {
+		return ErrUnknownLog
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending = append(a.pending, sct)
+	return nil
+}
+
+// AddObservation queues the SCT of an observation of SCT feedback, whose
+// signature was checked when it was collected.
+func (a *Auditor) AddObservation(o *feedback.Observation) error {
+	var sct ct.SignedCertificateTimestamp
+	if _, err := tls.Unmarshal(o.SCT, &sct); err != nil {
+		return fmt.Errorf("failed to parse SCT: %v", err)
+	}
+	return a.Add(&SCT{LogID: o.LogID, Timestamp: sct.Timestamp, LeafHash: o.LeafHash, Source: "feedback"})
+}
+
+// AddChain queues the SCTs embedded in the leaf certificate of chain, whose
+// issuer must follow it, and the TLS-encoded SCTs in tlsSCTs, which were
+// served for the leaf certificate, e.g. in the TLS extension. The signature
+// of each SCT is checked, and SCTs failing the check or of unknown logs are
+// skipped. Returns the number of SCTs queued.
+func (a *Auditor) AddChain(chain []*x509.Certificate, tlsSCTs [][]byte, source string) (int, error) {
+	if len(chain) == 0 {
+		return 0, errors.New("empty chain")
+	}
+	var queued int
+	add := func(sct *ct.SignedCertificateTimestamp, embedded bool) {
+		l, ok := a.logs[sct.LogID.KeyID]
+		if !ok {
+			klog.V(1).Infof("%s: skipping SCT of unknown log %x", source, sct.LogID.KeyID)
+			return
+		}
+		if err := ctutil.VerifySCTWithVerifier(l.Verifier, chain, sct, embedded); err != nil {
+			klog.Warningf("%s: skipping invalid SCT of log %q: %v", source, l.URL, err)
+			return
+		}
+		leafHash, err := ctutil.LeafHash(chain, sct, embedded)
+		if err != nil {
+			klog.Warningf("%s: skipping SCT of log %q: %v", source, l.URL, err)
+			return
+		}
+		if a.Add(&SCT{LogID: l.ID, Timestamp: sct.Timestamp, LeafHash: leafHash, Source: source}) == nil {
+			queued++
+		}
+	}
+	if len(chain[0].SCTList.SCTList) > 0 && len(chain) < 2 {
+		return 0, errors.New("no issuer for embedded SCTs")
+	}
+	for _, serialized := range chain[0].SCTList.SCTList {
+		sct, err := x509util.ExtractSCT(&serialized)
+		if err != nil {
+			klog.Warningf("%s: skipping embedded SCT: %v", source, err)
+			continue
+		}
+		add(sct, true)
+	}
+	for _, raw := range tlsSCTs {
+		var sct ct.SignedCertificateTimestamp
+		if _, err := tls.Unmarshal(raw, &sct); err != nil {
+			klog.Warningf("%s: skipping SCT: %v", source, err)
+			continue
+		}
+		add(&sct, false)
+	}
+	return queued, nil
+}
+
+// Pending returns the number of SCTs whose audit is not complete.
+func (a *Auditor) Pending() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.pending)
+}
+
+// Audit checks the inclusion of the pending SCTs whose MMD has passed, and
+// returns the results of the complete audits. The audits which could not be
+// made, e.g. because the log could not be reached, its STHs are not
+// consistent or it has not yet produced an STH beyond the MMD of the SCT,
+// are left pending.
+func (a *Auditor) Audit(ctx context.Context) []*Result {
+	now := a.now()
+	a.mu.Lock()
+	due := make(map[[sha256.Size]byte][]*SCT)
+	var waiting []*SCT
+	for _, sct := range a.pending {
+		if now.Before(a.deadline(sct).Add(a.opts.Grace)) {
+			waiting = append(waiting, sct)
+		} else {
+			due[sct.LogID] = append(due[sct.LogID], sct)
+		}
+	}
+	a.pending = nil
+	a.mu.Unlock()
+
+	var results []*Result
+	for id, scts := range due {
+		l := a.logs[id]
+		sth := a.verifiedSTH(ctx, l)
+		for _, sct := range scts {
+			var r *Result
+			if sth != nil && ct.TimestampToTime(sth.Timestamp).After(a.deadline(sct)) {
+				r = a.checkInclusion(ctx, l, sth, sct)
+			}
+			if r == nil {
+				waiting = append(waiting, sct)
+				continue
+			}
+			results = append(results, r)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].SCT.Timestamp < results[j].SCT.Timestamp })
+
+	a.mu.Lock()
+	a.pending = append(a.pending, waiting...)
+	a.mu.Unlock()
+	return results
+}
+
+// Run audits the pending SCTs every interval, passing each result to report,
+// until ctx is done or no SCT is pending.
+func (a *Auditor) Run(ctx context.Context, interval time.Duration, report func(*Result)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, r := range a.Audit(ctx) {
+			report(r)
+		}
+		if a.Pending() == 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deadline returns the time by which the entry of sct must be included.
+func (a *Auditor) deadline(sct *SCT) time.Time {
+	return ct.TimestampToTime(sct.Timestamp).Add(a.logs[sct.LogID].MMD)
+}
+
+// verifiedSTH fetches a fresh STH of l, and returns the largest STH verified
+// to be consistent with the history of l, or nil if the history of l is not
+// consistent.
+func (a *Auditor) verifiedSTH(ctx context.Context, l *monitor.Log) *ct.SignedTreeHead {
+	for _, alert := range a.mon.Check(ctx, l) {
+		switch alert.Kind {
+		case monitor.AlertInconsistent, monitor.AlertInvalidSignature:
+			klog.Errorf("Not auditing SCTs of log %q: %s", l.URL, alert)
+			return nil
+		}
+	}
+	sth, err := a.mon.LatestSTH(ctx, l)
+	if err != nil {
+		klog.Errorf("Failed to get STH of log %q: %v", l.URL, err)
+		return nil
+	}
+	return sth
+}
+
+// checkInclusion checks that the entry of sct is included in the tree of
+// sth, returning nil if the check could not be made.
+func (a *Auditor) checkInclusion(ctx context.Context, l *monitor.Log, sth *ct.SignedTreeHead, sct *SCT) *Result {
+	r := &Result{SCT: sct, STH: sth}
+	rsp, err := l.Client.GetProofByHash(ctx, sct.LeafHash[:], sth.TreeSize)
+	var rspErr jsonclient.RspError
+	if errors.As(err, &rspErr) && (rspErr.StatusCode == http.StatusBadRequest || rspErr.StatusCode == http.StatusNotFound) {
+		r.Status, r.Err = NotIncluded, err
+		return r
+	} else if err != nil {
+		klog.Warningf("Failed to get inclusion proof from log %q: %v", l.URL, err)
+		return nil
+	}
+	if err := proof.VerifyInclusion(rfc6962.DefaultHasher, uint64(rsp.LeafIndex), sth.TreeSize, sct.LeafHash[:], rsp.AuditPath, sth.SHA256RootHash[:]); err != nil {
+		r.Status, r.Err = InvalidProof, err
+		return r
+	}
+	r.Status, r.Index = Included, rsp.LeafIndex
+	return r
+}
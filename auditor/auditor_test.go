@@ -0,0 +1,205 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
+	"github.com/RarimoVoting/certificate-transparency-go/gossip/feedback"
+	"github.com/RarimoVoting/certificate-transparency-go/internal/testonly"
+	"github.com/RarimoVoting/certificate-transparency-go/monitor"
+	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+)
+
+var testNow = time.Unix(1700000000, 0)
+
+func newTestAuditor(t *testing.T) (*Auditor, *monitor.Log, *testonly.TreeLog) {
+	t.Helper()
+	c := testonly.NewTreeLog(t)
+	c.Now = testNow
+	c.Grow(4)
+	l := &monitor.Log{URL: "https://log.example.com/", ID: [sha256.Size]byte{1}, MMD: time.Hour, Client: c, Verifier: c.Verifier()}
+	store, err := ctutil.OpenFileSTHStorage(filepath.Join(t.TempDir(), "sths.jsonl"))
+	if err != nil {
+		t.Fatalf("OpenFileSTHStorage()=%v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	a := New([]*monitor.Log{l}, store, Options{Grace: time.Minute})
+	a.now = func() time.Time { return c.Now }
+	return a, l, c
+}
+
+func leafHash(c *testonly.TreeLog, i uint64) [sha256.Size]byte {
+	var h [sha256.Size]byte
+	copy(h[:], c.Tree.LeafHash(i))
+	return h
+}
+
+func TestAuditor(t *testing.T) {
+	ctx := context.Background()
+	a, l, c := newTestAuditor(t)
+	ts := func(d time.Duration) uint64 { return uint64(testNow.Add(d).UnixMilli()) }
+
+	scts := map[string]*SCT{
+		"included":     {LogID: l.ID, Timestamp: ts(-2 * time.Hour), LeafHash: leafHash(c, 1)},
+		"not-included": {LogID: l.ID, Timestamp: ts(-2 * time.Hour), LeafHash: [sha256.Size]byte{0xff}},
+		"recent":       {LogID: l.ID, Timestamp: ts(-30 * time.Minute), LeafHash: [sha256.Size]byte{0xfe}},
+	}
+	for name, sct := range scts {
+		sct.Source = name
+		if err := a.Add(sct); err != nil {
+			t.Fatalf("Add(%s)=%v", name, err)
+		}
+	}
+	if err := a.Add(&SCT{LogID: [sha256.Size]byte{2}}); !errors.Is(err, ErrUnknownLog) {
+		t.Errorf("Add(unknown log)=%v; want %v", err, ErrUnknownLog)
+	}
+
+	// Nothing is audited while the log cannot be reached.
+	c.Err = errors.New("log unavailable")
+	if got := a.Audit(ctx); len(got) != 0 {
+		t.Errorf("Audit() with unreachable log=%v; want no results", got)
+	}
+	c.Err = nil
+
+	got := a.Audit(ctx)
+	if len(got) != 2 {
+		t.Fatalf("Audit()=%v; want 2 results", got)
+	}
+	for _, r := range got {
+		switch r.SCT.Source {
+		case "included":
+			if r.Status != Included || r.Index != 1 {
+				t.Errorf("Audit(included)=%v; want included at index 1", r)
+			}
+		case "not-included":
+			if r.Status != NotIncluded {
+				t.Errorf("Audit(not-included)=%v; want not included", r)
+			}
+		default:
+			t.Errorf("Audit() audited %v before its MMD", r)
+		}
+	}
+	if got := a.Pending(); got != 1 {
+		t.Errorf("Pending()=%d; want 1", got)
+	}
+
+	// The recent SCT is due once its MMD and grace period have passed, and
+	// the log has an STH beyond its MMD.
+	c.Now = testNow.Add(31 * time.Minute)
+	if got := a.Audit(ctx); len(got) != 1 || got[0].Status != NotIncluded {
+		t.Errorf("Audit() after MMD=%v; want the recent SCT not included", got)
+	}
+	if got := a.Pending(); got != 0 {
+		t.Errorf("Pending()=%d; want 0", got)
+	}
+
+	c.BadProof = true
+	if err := a.Add(scts["included"]); err != nil {
+		t.Fatalf("Add()=%v", err)
+	}
+	if got := a.Audit(ctx); len(got) != 1 || got[0].Status != InvalidProof {
+		t.Errorf("Audit() with bad proof=%v; want invalid proof", got)
+	}
+}
+
+func TestAuditorAddChain(t *testing.T) {
+	der, err := base64.StdEncoding.DecodeString(testdata.LogPublicKeyB64)
+	if err != nil {
+		t.Fatalf("DecodeString()=%v", err)
+	}
+	pubKey, err := ct.PublicKeyFromB64(testdata.LogPublicKeyB64)
+	if err != nil {
+		t.Fatalf("PublicKeyFromB64()=%v", err)
+	}
+	verifier, err := ct.NewSignatureVerifier(pubKey)
+	if err != nil {
+		t.Fatalf("NewSignatureVerifier()=%v", err)
+	}
+	store, err := ctutil.OpenFileSTHStorage(filepath.Join(t.TempDir(), "sths.jsonl"))
+	if err != nil {
+		t.Fatalf("OpenFileSTHStorage()=%v", err)
+	}
+	defer store.Close()
+	l := &monitor.Log{URL: "https://log.example.com/", ID: sha256.Sum256(der), MMD: time.Hour, Verifier: verifier}
+	a := New([]*monitor.Log{l}, store, Options{})
+
+	for _, test := range []struct {
+		name     string
+		chainPEM string
+		tlsSCTs  [][]byte
+		want     int
+		wantHash string
+		wantErr  bool
+	}{
+		{
+			name:     "tls",
+			chainPEM: testdata.TestCertPEM + testdata.CACertPEM,
+			tlsSCTs:  [][]byte{testdata.TestCertProof, testdata.TestInvalidProof, []byte("sct")},
+			want:     1,
+			wantHash: testdata.TestCertB64LeafHash,
+		},
+		{
+			name:     "embedded",
+			chainPEM: testdata.TestEmbeddedCertPEM + testdata.CACertPEM,
+			want:     1,
+			wantHash: testdata.TestPreCertB64LeafHash,
+		},
+		{name: "embedded-no-issuer", chainPEM: testdata.TestEmbeddedCertPEM, wantErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			chain, err := x509util.CertificatesFromPEM([]byte(test.chainPEM))
+			if err != nil {
+				t.Fatalf("CertificatesFromPEM()=%v", err)
+			}
+			a.pending = nil
+			got, err := a.AddChain(chain, test.tlsSCTs, test.name)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("AddChain()=%v; want error: %t", err, test.wantErr)
+			}
+			if got != test.want || len(a.pending) != test.want {
+				t.Fatalf("AddChain()=%d, with %d pending; want %d", got, len(a.pending), test.want)
+			}
+			if test.want > 0 {
+				if got := ct.SHA256Hash(a.pending[0].LeafHash).Base64String(); got != test.wantHash {
+					t.Errorf("AddChain() queued leaf hash %s; want %s", got, test.wantHash)
+				}
+			}
+		})
+	}
+
+	a.pending = nil
+	o := &feedback.Observation{LogID: l.ID, SCT: testdata.TestCertProof, LeafHash: ct.SHA256Hash{1}}
+	if err := a.AddObservation(o); err != nil {
+		t.Fatalf("AddObservation()=%v", err)
+	}
+	var sct ct.SignedCertificateTimestamp
+	if _, err := tls.Unmarshal(testdata.TestCertProof, &sct); err != nil {
+		t.Fatalf("tls.Unmarshal()=%v", err)
+	}
+	if len(a.pending) != 1 || a.pending[0].Timestamp != sct.Timestamp || a.pending[0].LeafHash != o.LeafHash {
+		t.Errorf("AddObservation() queued %+v; want the observed SCT", a.pending)
+	}
+}
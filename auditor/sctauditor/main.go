@@ -0,0 +1,175 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The sctauditor binary audits the inclusion of SCTs found in PEM chain files
+// given as arguments, in a journal of SCT feedback, or served by TLS servers.
+// It waits for the MMD of each SCT to pass, checks its inclusion in the log,
+// and exits with status 1 if any SCT was not honored.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/auditor"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
+	"github.com/RarimoVoting/certificate-transparency-go/gossip/feedback"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/monitor"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+	"k8s.io/klog/v2"
+)
+
+var (
+	logList         = flag.String("log_list", loglist3.AllLogListURL, "File or URL of the JSON list of logs")
+	stateFile       = flag.String("state_file", "sctauditor_sths.jsonl", "File in which the STHs seen for each log are kept")
+	feedbackJournal = flag.String("feedback_journal", "", "If set, journal of SCT feedback whose SCTs are audited, as written by the collector")
+	hosts           = flag.String("hosts", "", "Comma-separated list of TLS servers (host[:port]) whose SCTs are audited")
+	interval        = flag.Duration("interval", 5*time.Minute, "Interval between audits of the SCTs waiting for their MMD")
+	grace           = flag.Duration("grace", 10*time.Minute, "How long after the MMD of an SCT its inclusion is checked")
+	deadline        = flag.Duration("deadline", 30*time.Second, "Timeout deadline for HTTP requests and TLS connections")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	hc := &http.Client{Timeout: *deadline}
+
+	data, err := x509util.ReadFileOrURL(*logList, hc)
+	if err != nil {
+		klog.Exitf("Failed to read log list: %v", err)
+	}
+	ll, err := loglist3.NewFromJSON(data)
+	if err != nil {
+		klog.Exitf("Failed to parse log list: %v", err)
+	}
+	var logs []*monitor.Log
+	for _, op := range ll.Operators {
+		for _, l := range op.Logs {
+			ml, err := monitor.NewLog(l, hc)
+			if err != nil {
+				klog.Exitf("Failed to set up log: %v", err)
+			}
+			logs = append(logs, ml)
+		}
+	}
+	store, err := ctutil.OpenFileSTHStorage(*stateFile)
+	if err != nil {
+		klog.Exitf("Failed to open state file: %v", err)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			klog.Errorf("Failed to close state file: %v", err)
+		}
+	}()
+	a := auditor.New(logs, store, auditor.Options{Grace: *grace})
+
+	for _, path := range flag.Args() {
+		chain, err := x509util.ReadPossiblePEMFile(path, "CERTIFICATE")
+		if err != nil {
+			klog.Exitf("Failed to read %s: %v", path, err)
+		}
+		addChain(a, chain, nil, path)
+	}
+	if len(*feedbackJournal) > 0 {
+		fs, err := feedback.OpenFileStorage(*feedbackJournal)
+		if err != nil {
+			klog.Exitf("Failed to open feedback journal: %v", err)
+		}
+		obs, err := fs.Observations(ctx, time.Time{})
+		if err != nil {
+			klog.Exitf("Failed to read feedback journal: %v", err)
+		}
+		fs.Close()
+		for _, o := range obs {
+			if err := a.AddObservation(o); err != nil {
+				klog.Warningf("Skipping SCT feedback: %v", err)
+			}
+		}
+	}
+	if len(*hosts) > 0 {
+		for _, host := range strings.Split(*hosts, ",") {
+			chain, scts, err := scanHost(host)
+			if err != nil {
+				klog.Errorf("Failed to scan %s: %v", host, err)
+				continue
+			}
+			addChain(a, chain, scts, host)
+		}
+	}
+
+	klog.Infof("Auditing %d SCTs", a.Pending())
+	var failed int
+	a.Run(ctx, *interval, func(r *auditor.Result) {
+		if r.Status != auditor.Included {
+			failed++
+		}
+		fmt.Println(r)
+	})
+	if n := a.Pending(); n > 0 {
+		klog.Warningf("Audit of %d SCTs left incomplete", n)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func addChain(a *auditor.Auditor, rawChain [][]byte, tlsSCTs [][]byte, source string) {
+	chain := make([]*x509.Certificate, 0, len(rawChain))
+	for _, der := range rawChain {
+		cert, err := x509.ParseCertificate(der)
+		if x509.IsFatal(err) {
+			klog.Errorf("%s: failed to parse certificate: %v", source, err)
+			return
+		}
+		chain = append(chain, cert)
+	}
+	if _, err := a.AddChain(chain, tlsSCTs, source); err != nil {
+		klog.Errorf("%s: %v", source, err)
+	}
+}
+
+// scanHost returns the chain served by a TLS server, and the SCTs served in
+// the TLS extension.
+func scanHost(host string) ([][]byte, [][]byte, error) {
+	addr := host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		addr = net.JoinHostPort(host, "443")
+	}
+	dialer := net.Dialer{Timeout: *deadline}
+	// The chain is audited for its SCTs, whether it is trusted or not.
+	conn, err := tls.DialWithDialer(&dialer, "tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer conn.Close()
+	state := conn.ConnectionState()
+	var chain [][]byte
+	for _, cert := range state.PeerCertificates {
+		chain = append(chain, cert.Raw)
+	}
+	return chain, state.SignedCertificateTimestamps, nil
+}
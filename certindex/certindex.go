@@ -0,0 +1,261 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package certindex maintains a local index of log entries, built from the
+// output of the scanner, which can be searched by DNS name (optionally
+// including subdomains) and by issuer.
+package certindex
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/scanner"
+)
+
+// DefaultLimit is the default maximum number of entries returned by Search.
+const DefaultLimit = 100
+
+// ingestBatchSize is the number of records Ingest writes per transaction.
+const ingestBatchSize = 1000
+
+// Entry is an indexed log entry.
+type Entry struct {
+	// ID orders the entries of the index, and is the cursor of searches.
+	ID        int64     `json:"id"`
+	LogURI    string    `json:"log_uri"`
+	Index     int64     `json:"index"`
+	Timestamp uint64    `json:"timestamp"`
+	Precert   bool      `json:"precert"`
+	Subject   string    `json:"subject,omitempty"`
+	Issuer    string    `json:"issuer,omitempty"`
+	Serial    string    `json:"serial,omitempty"`
+	NotBefore time.Time `json:"not_before,omitempty"`
+	NotAfter  time.Time `json:"not_after,omitempty"`
+	DNSNames  []string  `json:"dns_names,omitempty"`
+}
+
+// Query selects entries of the index; all the conditions set must hold.
+type Query struct {
+	// Name is a DNS name which the entries must be for.
+	Name string
+	// Subdomains also selects the entries for subdomains of Name,
+	// including wildcards.
+	Subdomains bool
+	// Issuer is a full-text query over the issuer of the entries, e.g.
+	// "Let's Encrypt" matches issuers containing both words.
+	Issuer string
+	// LogURI restricts the entries to those of one log.
+	LogURI string
+	// After restricts the entries to those with a greater ID, to page
+	// through results.
+	After int64
+	// Limit is the maximum number of entries returned; if zero,
+	// DefaultLimit is used.
+	Limit int
+}
+
+// Index is an index of log entries kept in an SQLite database. It
+// implements scanner.Sink, so that scans can feed it directly. The caller is
+// responsible for loading the database driver, which must support FTS4.
+type Index struct {
+	db *sql.DB
+	// mu serializes writes, which SQLite does not run concurrently.
+	mu sync.Mutex
+}
+
+var _ scanner.Sink = (*Index)(nil)
+
+// NewIndex creates an Index using db, creating its tables if needed.
+func NewIndex(ctx context.Context, db *sql.DB) (*Index, error) {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS entries (id INTEGER PRIMARY KEY, logURI TEXT, idx INTEGER, timestamp INTEGER, precert BOOLEAN, subject TEXT, issuer TEXT, serial TEXT, notBefore INTEGER, notAfter INTEGER, dnsNames TEXT, UNIQUE (logURI, idx))`,
+		`CREATE TABLE IF NOT EXISTS names (name TEXT, entry INTEGER, PRIMARY KEY (name, entry)) WITHOUT ROWID`,
+		`CREATE VIRTUAL TABLE IF NOT EXISTS entries_fts USING fts4 (subject, issuer)`,
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("failed to create index tables: %v", err)
+		}
+	}
+	return &Index{db: db}, nil
+}
+
+// reverseName returns the labels of the DNS name in reverse order, so that
+// the names of the subdomains of a name share its prefix, e.g.
+// "com.example.www" for "www.example.com".
+func reverseName(name string) string {
+	labels := strings.Split(strings.TrimSuffix(strings.ToLower(name), "."), ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+	return strings.Join(labels, ".")
+}
+
+// Put implements scanner.Sink. Entries already in the index are skipped.
+func (x *Index) Put(ctx context.Context, rec *scanner.SinkRecord) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	tx, err := x.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback() // nolint:errcheck
+	if err := put(ctx, tx, rec); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Close implements scanner.Sink. It does not close the database.
+func (x *Index) Close(context.Context) error {
+	return nil
+}
+
+func put(ctx context.Context, tx *sql.Tx, rec *scanner.SinkRecord) error {
+	names, err := json.Marshal(rec.DNSNames)
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO entries (logURI, idx, timestamp, precert, subject, issuer, serial, notBefore, notAfter, dnsNames) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rec.LogURI, rec.Index, int64(rec.Timestamp), rec.Precert, rec.Subject, rec.Issuer, rec.Serial, unixTime(rec.NotBefore), unixTime(rec.NotAfter), names)
+	if err != nil {
+		return fmt.Errorf("failed to index entry %d of %s: %v", rec.Index, rec.LogURI, err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for _, name := range rec.DNSNames {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO names (name, entry) VALUES (?, ?)`, reverseName(name), id); err != nil {
+			return fmt.Errorf("failed to index name of entry %d of %s: %v", rec.Index, rec.LogURI, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO entries_fts (docid, subject, issuer) VALUES (?, ?, ?)`, id, rec.Subject, rec.Issuer); err != nil {
+		return fmt.Errorf("failed to index issuer of entry %d of %s: %v", rec.Index, rec.LogURI, err)
+	}
+	return nil
+}
+
+// unixTime returns t in seconds since the epoch, or 0 for the zero time.
+func unixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// Ingest adds the records read from r, as written by a
+// scanner.JSONLinesSink, to the index, and returns how many were read.
+func (x *Index) Ingest(ctx context.Context, r io.Reader) (int, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	dec := json.NewDecoder(bufio.NewReader(r))
+	count := 0
+	for done := false; !done; {
+		tx, err := x.db.BeginTx(ctx, nil)
+		if err != nil {
+			return count, fmt.Errorf("failed to start transaction: %v", err)
+		}
+		for i := 0; i < ingestBatchSize; i++ {
+			var rec scanner.SinkRecord
+			if err := dec.Decode(&rec); err == io.EOF {
+				done = true
+				break
+			} else if err != nil {
+				tx.Rollback() // nolint:errcheck
+				return count, fmt.Errorf("failed to parse record %d: %v", count, err)
+			}
+			if err := put(ctx, tx, &rec); err != nil {
+				tx.Rollback() // nolint:errcheck
+				return count, err
+			}
+			count++
+		}
+		if err := tx.Commit(); err != nil {
+			return count, fmt.Errorf("failed to commit records: %v", err)
+		}
+	}
+	return count, nil
+}
+
+// Search returns the entries selected by q, ordered by ID.
+func (x *Index) Search(ctx context.Context, q Query) ([]*Entry, error) {
+	stmt := `SELECT id, logURI, idx, timestamp, precert, subject, issuer, serial, notBefore, notAfter, dnsNames FROM entries WHERE id > ?`
+	args := []interface{}{q.After}
+	if len(q.Name) > 0 {
+		name := reverseName(q.Name)
+		if q.Subdomains {
+			// Subdomains sort between name+"." and name+"/".
+			stmt += ` AND id IN (SELECT entry FROM names WHERE name = ? OR (name > ? AND name < ?))`
+			args = append(args, name, name+".", name+"/")
+		} else {
+			stmt += ` AND id IN (SELECT entry FROM names WHERE name = ?)`
+			args = append(args, name)
+		}
+	}
+	if len(q.Issuer) > 0 {
+		stmt += ` AND id IN (SELECT docid FROM entries_fts WHERE issuer MATCH ?)`
+		args = append(args, q.Issuer)
+	}
+	if len(q.LogURI) > 0 {
+		stmt += ` AND logURI = ?`
+		args = append(args, q.LogURI)
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	stmt += ` ORDER BY id LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := x.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search index: %v", err)
+	}
+	defer rows.Close()
+	var entries []*Entry
+	for rows.Next() {
+		var e Entry
+		var timestamp, notBefore, notAfter int64
+		var names []byte
+		if err := rows.Scan(&e.ID, &e.LogURI, &e.Index, &timestamp, &e.Precert, &e.Subject, &e.Issuer, &e.Serial, &notBefore, &notAfter, &names); err != nil {
+			return nil, fmt.Errorf("failed to read entry: %v", err)
+		}
+		e.Timestamp = uint64(timestamp)
+		if notBefore != 0 {
+			e.NotBefore = time.Unix(notBefore, 0).UTC()
+		}
+		if notAfter != 0 {
+			e.NotAfter = time.Unix(notAfter, 0).UTC()
+		}
+		if err := json.Unmarshal(names, &e.DNSNames); err != nil {
+			return nil, fmt.Errorf("failed to parse DNS names of entry %d: %v", e.ID, err)
+		}
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search index: %v", err)
+	}
+	return entries, nil
+}
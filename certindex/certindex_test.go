@@ -0,0 +1,198 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certindex
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/scanner"
+	"github.com/google/go-cmp/cmp"
+
+	_ "github.com/mattn/go-sqlite3" // Load SQLite driver
+)
+
+const (
+	logA = "https://a.example/"
+	logB = "https://b.example/"
+)
+
+var testRecords = []*scanner.SinkRecord{
+	{LogURI: logA, Index: 1, Issuer: "CN=Let's Encrypt R3,O=Let's Encrypt", Subject: "CN=example.com", DNSNames: []string{"example.com", "www.example.com"}},
+	{LogURI: logA, Index: 2, Issuer: "CN=Other CA", Subject: "CN=*.example.com", DNSNames: []string{"*.example.com"}, Precert: true},
+	{LogURI: logB, Index: 1, Issuer: "CN=Let's Encrypt E1,O=Let's Encrypt", DNSNames: []string{"Mail.Example.COM."}},
+	{LogURI: logB, Index: 2, Issuer: "CN=Other CA", DNSNames: []string{"notexample.com", "example.org"},
+		NotBefore: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), NotAfter: time.Date(2026, 4, 2, 3, 4, 5, 0, time.UTC), Serial: "1a", Timestamp: 1234},
+}
+
+func newTestIndex(t *testing.T) *Index {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatalf("sql.Open()=%v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	x, err := NewIndex(context.Background(), db)
+	if err != nil {
+		t.Fatalf("NewIndex()=%v", err)
+	}
+	for _, rec := range testRecords {
+		if err := x.Put(context.Background(), rec); err != nil {
+			t.Fatalf("Put()=%v", err)
+		}
+	}
+	return x
+}
+
+// keys returns the log URIs and indices of entries.
+func keys(entries []*Entry) []string {
+	var keys []string
+	for _, e := range entries {
+		keys = append(keys, e.LogURI+"#"+string(rune('0'+e.Index)))
+	}
+	return keys
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	x := newTestIndex(t)
+	// Entries already indexed are skipped.
+	if err := x.Put(ctx, testRecords[0]); err != nil {
+		t.Fatalf("Put(duplicate)=%v", err)
+	}
+
+	for _, test := range []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{name: "name", query: Query{Name: "example.com"}, want: []string{logA + "#1"}},
+		{name: "name-case", query: Query{Name: "MAIL.example.com"}, want: []string{logB + "#1"}},
+		{name: "subdomains", query: Query{Name: "example.com", Subdomains: true}, want: []string{logA + "#1", logA + "#2", logB + "#1"}},
+		{name: "wildcard", query: Query{Name: "*.example.com"}, want: []string{logA + "#2"}},
+		{name: "unknown", query: Query{Name: "example.net", Subdomains: true}},
+		{name: "issuer", query: Query{Issuer: "Let's Encrypt"}, want: []string{logA + "#1", logB + "#1"}},
+		{name: "issuer-name", query: Query{Issuer: "other", Name: "example.com", Subdomains: true}, want: []string{logA + "#2"}},
+		{name: "log", query: Query{Name: "example.com", Subdomains: true, LogURI: logB}, want: []string{logB + "#1"}},
+		{name: "all", query: Query{}, want: []string{logA + "#1", logA + "#2", logB + "#1", logB + "#2"}},
+		{name: "limit", query: Query{Limit: 2}, want: []string{logA + "#1", logA + "#2"}},
+		{name: "after", query: Query{After: 2, Limit: 1}, want: []string{logB + "#1"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			entries, err := x.Search(ctx, test.query)
+			if err != nil {
+				t.Fatalf("Search()=%v", err)
+			}
+			if diff := cmp.Diff(test.want, keys(entries)); diff != "" {
+				t.Errorf("Search() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	entries, err := x.Search(ctx, Query{Name: "example.org"})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Search(example.org)=%v, %v; want 1 entry", entries, err)
+	}
+	rec := testRecords[3]
+	want := &Entry{ID: 4, LogURI: rec.LogURI, Index: rec.Index, Timestamp: rec.Timestamp, Issuer: rec.Issuer, Serial: rec.Serial, NotBefore: rec.NotBefore, NotAfter: rec.NotAfter, DNSNames: rec.DNSNames}
+	if diff := cmp.Diff(want, entries[0]); diff != "" {
+		t.Errorf("Search(example.org) diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestIngest(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	sink := scanner.NewJSONLinesSink(&buf)
+	for i := int64(0); i < 2500; i++ {
+		if err := sink.Put(ctx, &scanner.SinkRecord{LogURI: logA, Index: i, DNSNames: []string{"host.example.com"}}); err != nil {
+			t.Fatalf("Put()=%v", err)
+		}
+	}
+	if err := sink.Close(ctx); err != nil {
+		t.Fatalf("Close()=%v", err)
+	}
+
+	x := newTestIndex(t)
+	n, err := x.Ingest(ctx, &buf)
+	if err != nil || n != 2500 {
+		t.Fatalf("Ingest()=%d, %v; want 2500 records", n, err)
+	}
+	entries, err := x.Search(ctx, Query{Name: "host.example.com", Limit: 5000})
+	if err != nil {
+		t.Fatalf("Search()=%v", err)
+	}
+	// Entries 1 and 2 of logA were already indexed by newTestIndex.
+	if len(entries) != 2498 {
+		t.Errorf("Search() returned %d entries; want 2498", len(entries))
+	}
+
+	if _, err := x.Ingest(ctx, strings.NewReader(`{"log_uri": "x", "index": 1}`+"\n{")); err == nil {
+		t.Error("Ingest(truncated)=nil; want error")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	srv := httptest.NewServer(&Handler{Index: newTestIndex(t), MaxLimit: 2})
+	defer srv.Close()
+
+	for _, test := range []struct {
+		query      string
+		wantStatus int
+		want       []string
+		wantNext   int64
+	}{
+		{query: "?name=example.com", wantStatus: http.StatusOK, want: []string{logA + "#1"}},
+		{query: "?name=example.com&subdomains=true", wantStatus: http.StatusOK, want: []string{logA + "#1", logA + "#2"}, wantNext: 2},
+		{query: "?name=example.com&subdomains=true&after=2", wantStatus: http.StatusOK, want: []string{logB + "#1"}},
+		{query: "?issuer=encrypt&limit=1", wantStatus: http.StatusOK, want: []string{logA + "#1"}, wantNext: 1},
+		{query: "?name=example.net", wantStatus: http.StatusOK},
+		{query: "", wantStatus: http.StatusBadRequest},
+		{query: "?name=example.com&subdomains=maybe", wantStatus: http.StatusBadRequest},
+		{query: "?name=example.com&limit=0", wantStatus: http.StatusBadRequest},
+	} {
+		t.Run(test.query, func(t *testing.T) {
+			rsp, err := http.Get(srv.URL + SearchPath + test.query)
+			if err != nil {
+				t.Fatalf("GET=%v", err)
+			}
+			defer rsp.Body.Close()
+			if rsp.StatusCode != test.wantStatus {
+				t.Fatalf("GET status=%d; want %d", rsp.StatusCode, test.wantStatus)
+			}
+			if rsp.StatusCode != http.StatusOK {
+				return
+			}
+			var got SearchResponse
+			if err := json.NewDecoder(rsp.Body).Decode(&got); err != nil {
+				t.Fatalf("Decode()=%v", err)
+			}
+			if diff := cmp.Diff(test.want, keys(got.Entries)); diff != "" {
+				t.Errorf("GET diff (-want +got):\n%s", diff)
+			}
+			if got.Next != test.wantNext {
+				t.Errorf("GET next=%d; want %d", got.Next, test.wantNext)
+			}
+		})
+	}
+}
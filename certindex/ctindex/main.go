@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The ctindex binary maintains a local index of log entries in an SQLite
+// database, searchable by DNS name and issuer. The index is fed with the
+// JSON lines output of scanlog (--output_file) or by scanning a log
+// directly, and can be searched from the command line or over HTTP.
+//
+// For example, to watch the certificates for example.com and its subdomains:
+//
+//	ctindex --log_uri=https://ct.example.com/log/ --match_subject_regex='(^|\.)example\.com$'
+//	ctindex --name=example.com --subdomains
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+	"syscall"
+
+	"github.com/RarimoVoting/certificate-transparency-go/certindex"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/scanner"
+	"k8s.io/klog/v2"
+
+	_ "github.com/mattn/go-sqlite3" // Load SQLite driver
+)
+
+var (
+	dbPath = flag.String("db", "ctindex.db", "SQLite database holding the index")
+
+	logURI            = flag.String("log_uri", "", "If set, CT log base URI to scan into the index, resuming from the previous scan")
+	matchSubjectRegex = flag.String("match_subject_regex", ".*", "Regex to match CN/SAN of the entries scanned from --log_uri")
+	batchSize         = flag.Int("batch_size", 1000, "Max number of entries to request at per call to get-entries")
+	parallelFetch     = flag.Int("parallel_fetch", 2, "Number of concurrent GetEntries fetches")
+	numWorkers        = flag.Int("num_workers", 2, "Number of concurrent matchers")
+
+	name         = flag.String("name", "", "If set, print the indexed entries for this DNS name")
+	subdomains   = flag.Bool("subdomains", false, "Also print the entries for subdomains of --name")
+	issuer       = flag.String("issuer", "", "If set, print the indexed entries whose issuer matches this full-text query")
+	limit        = flag.Int("limit", certindex.DefaultLimit, "Maximum number of entries to print or serve at once")
+	httpEndpoint = flag.String("http_endpoint", "", "If set, endpoint (host:port) on which to serve searches of the index")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	db, err := sql.Open("sqlite3", *dbPath)
+	if err != nil {
+		klog.Exitf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	index, err := certindex.NewIndex(ctx, db)
+	if err != nil {
+		klog.Exitf("Failed to set up index: %v", err)
+	}
+
+	// Index the files of scan output given as arguments, "-" being stdin.
+	for _, path := range flag.Args() {
+		var r io.Reader = os.Stdin
+		if path != "-" {
+			f, err := os.Open(path)
+			if err != nil {
+				klog.Exitf("Failed to open %s: %v", path, err)
+			}
+			defer f.Close()
+			r = f
+		}
+		n, err := index.Ingest(ctx, r)
+		if err != nil {
+			klog.Exitf("Failed to index %s: %v", path, err)
+		}
+		klog.Infof("Indexed %d records from %s", n, path)
+	}
+
+	if len(*logURI) > 0 {
+		if err := scan(ctx, index, db); err != nil {
+			klog.Exitf("Failed to scan %s: %v", *logURI, err)
+		}
+	}
+
+	if len(*name) > 0 || len(*issuer) > 0 {
+		entries, err := index.Search(ctx, certindex.Query{Name: *name, Subdomains: *subdomains, Issuer: *issuer, Limit: *limit})
+		if err != nil {
+			klog.Exitf("Failed to search index: %v", err)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				klog.Exitf("Failed to print entry: %v", err)
+			}
+		}
+	}
+
+	if len(*httpEndpoint) > 0 {
+		klog.Infof("Serving searches on %s", *httpEndpoint)
+		srv := &http.Server{Addr: *httpEndpoint, Handler: &certindex.Handler{Index: index, MaxLimit: *limit}}
+		go func() {
+			<-ctx.Done()
+			srv.Close()
+		}()
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			klog.Exitf("Failed to serve: %v", err)
+		}
+	}
+}
+
+// scan scans the entries of --log_uri matching --match_subject_regex into
+// index, keeping the progress of the scan in db.
+func scan(ctx context.Context, index *certindex.Index, db *sql.DB) error {
+	lc, err := client.New(*logURI, http.DefaultClient, jsonclient.Options{UserAgent: "ct-go-ctindex/1.0"})
+	if err != nil {
+		return err
+	}
+	checkpoints, err := scanner.NewSQLiteCheckpointStore(ctx, db)
+	if err != nil {
+		return err
+	}
+	re, err := regexp.Compile(*matchSubjectRegex)
+	if err != nil {
+		return err
+	}
+	s := scanner.NewScanner(lc, scanner.ScannerOptions{
+		FetcherOptions: scanner.FetcherOptions{
+			BatchSize:     *batchSize,
+			ParallelFetch: *parallelFetch,
+		},
+		Matcher:     scanner.MatchSubjectRegex{CertificateSubjectRegex: re, PrecertificateSubjectRegex: re},
+		NumWorkers:  *numWorkers,
+		Checkpoints: checkpoints,
+	})
+	return s.ScanToSink(ctx, index)
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certindex
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"k8s.io/klog/v2"
+)
+
+// SearchPath is the path at which a Handler serves searches.
+const SearchPath = "/search"
+
+// SearchResponse is the JSON response to a search.
+type SearchResponse struct {
+	Entries []*Entry `json:"entries"`
+	// Next, if non-zero, is the after parameter fetching the next page of
+	// entries.
+	Next int64 `json:"next,omitempty"`
+}
+
+// Handler serves searches of an Index over HTTP, as GET requests to
+// SearchPath with the parameters name, subdomains, issuer, log_uri, after
+// and limit, corresponding to the fields of Query.
+type Handler struct {
+	Index *Index
+	// MaxLimit caps the number of entries returned at once; if zero,
+	// DefaultLimit is used.
+	MaxLimit int
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != SearchPath {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	maxLimit := h.MaxLimit
+	if maxLimit <= 0 {
+		maxLimit = DefaultLimit
+	}
+	q := Query{
+		Name:   r.FormValue("name"),
+		Issuer: r.FormValue("issuer"),
+		LogURI: r.FormValue("log_uri"),
+		Limit:  maxLimit,
+	}
+	if v := r.FormValue("subdomains"); len(v) > 0 {
+		var err error
+		if q.Subdomains, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "bad subdomains parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.FormValue("after"); len(v) > 0 {
+		var err error
+		if q.After, err = strconv.ParseInt(v, 10, 64); err != nil {
+			http.Error(w, "bad after parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.FormValue("limit"); len(v) > 0 {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			http.Error(w, "bad limit parameter", http.StatusBadRequest)
+			return
+		}
+		if limit < maxLimit {
+			q.Limit = limit
+		}
+	}
+	if len(q.Name) == 0 && len(q.Issuer) == 0 {
+		http.Error(w, "name or issuer parameter required", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.Index.Search(r.Context(), q)
+	if err != nil {
+		klog.Errorf("Search(%+v): %v", q, err)
+		http.Error(w, "search failed", http.StatusInternalServerError)
+		return
+	}
+	rsp := SearchResponse{Entries: entries}
+	if rsp.Entries == nil {
+		rsp.Entries = []*Entry{}
+	}
+	if len(entries) == q.Limit {
+		rsp.Next = entries[len(entries)-1].ID
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(rsp); err != nil {
+		klog.Warningf("Failed to write search response: %v", err)
+	}
+}
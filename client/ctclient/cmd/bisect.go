@@ -74,6 +74,20 @@ func runBisect(ctx context.Context) {
 		return entry.Leaf.TimestampedEntry.Timestamp >= uint64(target)
 	})
 	when := ct.TimestampToTime(uint64(target))
+	if jsonOutput() {
+		// Entry is unset if there is no entry at or after the timestamp.
+		result := struct {
+			Timestamp int64      `json:"timestamp"`
+			TreeSize  uint64     `json:"tree_size"`
+			Entry     *entryJSON `json:"entry,omitempty"`
+		}{Timestamp: target, TreeSize: sth.TreeSize}
+		if idx < int(sth.TreeSize) {
+			entry := newEntryJSON(int64(idx), getEntry(int64(idx)), nil)
+			result.Entry = &entry
+		}
+		printJSON(result)
+		return
+	}
 	if idx >= int(sth.TreeSize) {
 		fmt.Printf("No entry with timestamp>=%d (%v) found up to tree size %d\n", target, when, sth.TreeSize)
 		return
@@ -0,0 +1,293 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+const (
+	downloadStateFile = "download.state"
+	batchFilePrefix   = "entries-"
+	batchFileSuffix   = ".json"
+)
+
+var (
+	downloadDir       string
+	downloadFirst     int64
+	downloadLast      int64
+	downloadWorkers   int
+	downloadBatchSize int
+	downloadDER       bool
+	downloadAttempts  int
+)
+
+func init() {
+	cmd := cobra.Command{
+		Use:   fmt.Sprintf("download %s --output_dir=dir [--first=idx] [--last=idx] [--der]", connectionFlags),
+		Short: "Download a range of entries in the log to disk, resumably",
+		Long: `Download a range of entries in the log to disk, resumably.
+
+Entries are written in order to files named entries-<first>-<last>.json in the
+output directory, each holding a get-entries response. With --der, the leaf
+certificate of each entry (the precertificate as submitted, for precert
+entries) is also written to der/<index>.der. The progress of the download is
+kept in the download.state file of the directory, so that running the command
+again with the same directory resumes where it stopped.`,
+		Args: cobra.MaximumNArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			runDownload(cmd.Context())
+		},
+	}
+	cmd.Flags().StringVar(&downloadDir, "output_dir", "", "Directory to download entries to")
+	cmd.Flags().Int64Var(&downloadFirst, "first", -1, "First entry to download; defaults to where an earlier download stopped, or 0")
+	cmd.Flags().Int64Var(&downloadLast, "last", -1, "Last entry to download; defaults to the last entry of the current STH")
+	cmd.Flags().IntVar(&downloadWorkers, "parallel_fetch", 4, "Number of concurrent get-entries requests")
+	cmd.Flags().IntVar(&downloadBatchSize, "batch_size", 1000, "Number of entries to request per get-entries request")
+	cmd.Flags().BoolVar(&downloadDER, "der", false, "Also extract the DER leaf certificate of each entry")
+	cmd.Flags().IntVar(&downloadAttempts, "max_attempts", 5, "Maximum number of attempts at each get-entries request")
+	rootCmd.AddCommand(&cmd)
+}
+
+// downloadState records the progress of a download, whose entries in
+// [First, Next) are on disk.
+type downloadState struct {
+	LogURI string `json:"log_uri"`
+	First  int64  `json:"first"`
+	Next   int64  `json:"next"`
+}
+
+// runDownload runs the download command.
+func runDownload(ctx context.Context) {
+	if downloadDir == "" {
+		klog.Exit("No --output_dir option supplied")
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	retryOpts = &jsonclient.RetryOptions{MaxAttempts: downloadAttempts}
+	if err := download(ctx, connect(ctx)); err != nil {
+		exitWithDetails(err)
+	}
+}
+
+// download downloads the entries of the log selected by the flags to
+// downloadDir, continuing any earlier download held there.
+func download(ctx context.Context, logClient *client.LogClient) error {
+	statePath := filepath.Join(downloadDir, downloadStateFile)
+	state, err := readDownloadState(statePath)
+	if err != nil {
+		return err
+	}
+	first := downloadFirst
+	switch {
+	case state == nil:
+		if first < 0 {
+			first = 0
+		}
+		state = &downloadState{LogURI: logClient.BaseURI(), First: first, Next: first}
+	case state.LogURI != logClient.BaseURI():
+		return fmt.Errorf("%s holds entries of log %s, not %s", downloadDir, state.LogURI, logClient.BaseURI())
+	case first >= 0 && (first < state.First || first > state.Next):
+		return fmt.Errorf("%s holds entries [%d, %d), which --first=%d does not continue; use another directory", downloadDir, state.First, state.Next, first)
+	default:
+		first = state.Next
+	}
+
+	last := downloadLast
+	if last < 0 {
+		sth, err := logClient.GetSTH(ctx)
+		if err != nil {
+			return err
+		}
+		last = int64(sth.TreeSize) - 1
+	}
+	if first > last {
+		showDownload(state, first)
+		return nil
+	}
+
+	if err := os.MkdirAll(downloadDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+	if downloadDER {
+		if err := os.MkdirAll(filepath.Join(downloadDir, "der"), 0o755); err != nil {
+			return fmt.Errorf("failed to create DER directory: %v", err)
+		}
+	}
+	// A batch may have been written without the state being updated after it.
+	if err := removeBatchFiles(downloadDir, state.Next); err != nil {
+		return err
+	}
+
+	klog.Infof("Downloading entries [%d, %d] of %s to %s", first, last, logClient.BaseURI(), downloadDir)
+	var lastReport time.Time
+	fetcher := client.NewFetcher(logClient, client.FetcherOptions{
+		Workers:   downloadWorkers,
+		BatchSize: downloadBatchSize,
+		Progress: func(p client.FetchProgress) {
+			if time.Since(lastReport) < 10*time.Second && p.Next != p.End {
+				return
+			}
+			lastReport = time.Now()
+			rate := float64(p.Next-p.Start) / p.Elapsed.Seconds()
+			klog.Infof("Downloaded %d of %d entries (%.0f/s)", p.Next-p.Start, p.End-p.Start, rate)
+		},
+	})
+	err = fetcher.Fetch(ctx, first, last+1, func(start int64, entries []ct.LeafEntry) error {
+		if err := writeBatch(downloadDir, start, entries); err != nil {
+			return err
+		}
+		state.Next = start + int64(len(entries))
+		return writeDownloadState(statePath, state)
+	})
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return fmt.Errorf("download interrupted; entries [%d, %d) are in %s", state.First, state.Next, downloadDir)
+		}
+		return err
+	}
+	showDownload(state, first)
+	return nil
+}
+
+// showDownload reports the entries downloaded from index first, and those
+// held in the output directory.
+func showDownload(state *downloadState, first int64) {
+	if first > state.Next {
+		first = state.Next
+	}
+	if jsonOutput() {
+		printJSON(struct {
+			LogURI    string `json:"log_uri"`
+			OutputDir string `json:"output_dir"`
+			// [First, Next) are held in the directory, of which
+			// [Downloaded, Next) were downloaded by this run.
+			First      int64 `json:"first"`
+			Downloaded int64 `json:"downloaded"`
+			Next       int64 `json:"next"`
+		}{LogURI: state.LogURI, OutputDir: downloadDir, First: state.First, Downloaded: first, Next: state.Next})
+		return
+	}
+	if first == state.Next {
+		fmt.Printf("Nothing to download; %s holds entries [%d, %d)\n", downloadDir, state.First, state.Next)
+		return
+	}
+	fmt.Printf("Downloaded entries [%d, %d); %s holds entries [%d, %d)\n", first, state.Next, downloadDir, state.First, state.Next)
+}
+
+// writeBatch writes the entries starting at index start to a batch file in
+// dir, along with their DER certificates if requested.
+func writeBatch(dir string, start int64, entries []ct.LeafEntry) error {
+	if downloadDER {
+		for i := range entries {
+			index := start + int64(i)
+			rle, err := ct.RawLogEntryFromLeaf(index, &entries[i])
+			if err != nil {
+				klog.Warningf("Failed to extract certificate of entry %d: %v", index, err)
+				continue
+			}
+			if err := os.WriteFile(filepath.Join(dir, "der", fmt.Sprintf("%d.der", index)), rle.Cert.Data, 0o644); err != nil {
+				return fmt.Errorf("failed to write certificate of entry %d: %v", index, err)
+			}
+		}
+	}
+	data, err := json.Marshal(ct.GetEntriesResponse{Entries: entries})
+	if err != nil {
+		return fmt.Errorf("failed to marshal entries: %v", err)
+	}
+	name := fmt.Sprintf("%s%d-%d%s", batchFilePrefix, start, start+int64(len(entries))-1, batchFileSuffix)
+	return writeFileAtomic(filepath.Join(dir, name), data)
+}
+
+// removeBatchFiles removes the batch files in dir which start at or after
+// index from.
+func removeBatchFiles(dir string, from int64) error {
+	names, err := filepath.Glob(filepath.Join(dir, batchFilePrefix+"*"+batchFileSuffix))
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		rng := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(name), batchFilePrefix), batchFileSuffix)
+		startStr, _, _ := strings.Cut(rng, "-")
+		start, err := strconv.ParseInt(startStr, 10, 64)
+		if err != nil || start < from {
+			continue
+		}
+		klog.V(1).Infof("Removing incomplete batch file %s", name)
+		if err := os.Remove(name); err != nil {
+			return fmt.Errorf("failed to remove incomplete batch file: %v", err)
+		}
+	}
+	return nil
+}
+
+func readDownloadState(path string) (*downloadState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read download state: %v", err)
+	}
+	var state downloadState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse download state in %s: %v", path, err)
+	}
+	return &state, nil
+}
+
+func writeDownloadState(path string, state *downloadState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal download state: %v", err)
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic replaces the file at path with data, so that it is never
+// left partially written.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return nil
+}
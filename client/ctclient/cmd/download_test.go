@@ -0,0 +1,282 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeLog is a CT log serving get-sth and get-entries, which fails the
+// get-entries requests for entries from index available on.
+type fakeLog struct {
+	mu        sync.Mutex
+	sth       ct.GetSTHResponse
+	entries   []ct.LeafEntry
+	available int64
+	// starts holds the start of each get-entries request served.
+	starts []int64
+}
+
+// serveFakeLog serves a fakeLog of the given entries, and points the
+// --log_uri flag at it.
+func serveFakeLog(t *testing.T, entries []ct.LeafEntry) *fakeLog {
+	t.Helper()
+	l := &fakeLog{
+		sth: ct.GetSTHResponse{
+			TreeSize:       uint64(len(entries)),
+			Timestamp:      1700000000123,
+			SHA256RootHash: make([]byte, 32),
+			// SHA-256 with ECDSA, of a fake signature.
+			TreeHeadSignature: []byte{4, 3, 0, 2, 0xca, 0xfe},
+		},
+		entries:   entries,
+		available: int64(len(entries)),
+	}
+	for i := range l.sth.SHA256RootHash {
+		l.sth.SHA256RootHash[i] = byte(i)
+	}
+	ts := httptest.NewServer(l)
+	t.Cleanup(ts.Close)
+	setFlag(t, &logURI, ts.URL)
+	return l
+}
+
+func (l *fakeLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var rsp interface{}
+	switch r.URL.Path {
+	case "/ct/v1/get-sth":
+		rsp = l.sth
+	case "/ct/v1/get-entries":
+		start, err := strconv.ParseInt(r.FormValue("start"), 10, 64)
+		if err != nil || start < 0 || start >= l.available {
+			http.Error(w, fmt.Sprintf("entry %s is not available", r.FormValue("start")), http.StatusBadRequest)
+			return
+		}
+		end, err := strconv.ParseInt(r.FormValue("end"), 10, 64)
+		if err != nil || end < start {
+			http.Error(w, "bad end", http.StatusBadRequest)
+			return
+		}
+		if end >= int64(len(l.entries)) {
+			end = int64(len(l.entries)) - 1
+		}
+		l.starts = append(l.starts, start)
+		rsp = ct.GetEntriesResponse{Entries: l.entries[start : end+1]}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(rsp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// setAvailable makes the log serve the entries before index available.
+func (l *fakeLog) setAvailable(available int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.available = available
+}
+
+// served returns the starts of the get-entries requests served since the
+// last call.
+func (l *fakeLog) served() []int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	starts := l.starts
+	l.starts = nil
+	return starts
+}
+
+// setFlag sets the variable of a flag for the duration of the test.
+func setFlag[T any](t *testing.T, flag *T, value T) {
+	t.Helper()
+	old := *flag
+	*flag = value
+	t.Cleanup(func() { *flag = old })
+}
+
+// captureStdout returns what fn prints to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe()=%v", err)
+	}
+	out := make(chan []byte, 1)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- data
+	}()
+	stdout := os.Stdout
+	os.Stdout = w
+	func() {
+		defer func() {
+			os.Stdout = stdout
+			w.Close()
+		}()
+		fn()
+	}()
+	return string(<-out)
+}
+
+func fakeEntries(n int) []ct.LeafEntry {
+	entries := make([]ct.LeafEntry, n)
+	for i := range entries {
+		entries[i] = ct.LeafEntry{
+			LeafInput: []byte(fmt.Sprintf("leaf%d", i)),
+			ExtraData: []byte(fmt.Sprintf("extra%d", i)),
+		}
+	}
+	return entries
+}
+
+func TestDownloadResumes(t *testing.T) {
+	ctx := context.Background()
+	log := serveFakeLog(t, fakeEntries(10))
+	dir := t.TempDir()
+	setFlag(t, &downloadDir, dir)
+	setFlag(t, &downloadFirst, -1)
+	setFlag(t, &downloadLast, -1)
+	setFlag(t, &downloadWorkers, 1)
+	setFlag(t, &downloadBatchSize, 2)
+	setFlag(t, &downloadDER, false)
+	setFlag(t, &outputFormat, outputText)
+
+	wantState := func(next int64) {
+		t.Helper()
+		state, err := readDownloadState(filepath.Join(dir, downloadStateFile))
+		if err != nil {
+			t.Fatalf("readDownloadState()=%v", err)
+		}
+		want := &downloadState{LogURI: logURI, First: 0, Next: next}
+		if diff := cmp.Diff(want, state); diff != "" {
+			t.Errorf("download state diff (-want +got):\n%s", diff)
+		}
+	}
+
+	// The log stops serving entries partway through the download.
+	log.setAvailable(4)
+	captureStdout(t, func() {
+		if err := download(ctx, connect(ctx)); err == nil {
+			t.Error("download()=nil, want error from unavailable entries")
+		}
+	})
+	wantState(4)
+	log.served()
+
+	// A batch written without the state being updated after it.
+	stale := filepath.Join(dir, "entries-4-4.json")
+	if err := os.WriteFile(stale, []byte(`{"entries":[]}`), 0o644); err != nil {
+		t.Fatalf("WriteFile()=%v", err)
+	}
+
+	log.setAvailable(10)
+	got := captureStdout(t, func() {
+		if err := download(ctx, connect(ctx)); err != nil {
+			t.Fatalf("download()=%v", err)
+		}
+	})
+	if want := fmt.Sprintf("Downloaded entries [4, 10); %s holds entries [0, 10)\n", dir); got != want {
+		t.Errorf("download() printed %q, want %q", got, want)
+	}
+	// The fetcher may request batches out of order, but none before the
+	// entries already held.
+	starts := log.served()
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+	if len(starts) == 0 || starts[0] != 4 {
+		t.Errorf("download() requested entries from %v, want from 4", starts)
+	}
+	wantState(10)
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("Stat(%s)=%v, want stale batch file removed", stale, err)
+	}
+
+	var entries []ct.LeafEntry
+	for _, name := range []string{"entries-0-1.json", "entries-2-3.json", "entries-4-5.json", "entries-6-7.json", "entries-8-9.json"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("ReadFile()=%v", err)
+		}
+		var rsp ct.GetEntriesResponse
+		if err := json.Unmarshal(data, &rsp); err != nil {
+			t.Fatalf("Unmarshal(%s)=%v", name, err)
+		}
+		entries = append(entries, rsp.Entries...)
+	}
+	if diff := cmp.Diff(log.entries, entries); diff != "" {
+		t.Errorf("downloaded entries diff (-want +got):\n%s", diff)
+	}
+
+	// Everything in the log is already held.
+	got = captureStdout(t, func() {
+		if err := download(ctx, connect(ctx)); err != nil {
+			t.Fatalf("download()=%v", err)
+		}
+	})
+	if want := fmt.Sprintf("Nothing to download; %s holds entries [0, 10)\n", dir); got != want {
+		t.Errorf("download() printed %q, want %q", got, want)
+	}
+	if got := log.served(); len(got) != 0 {
+		t.Errorf("download() requested entries from %v, want none", got)
+	}
+}
+
+func TestDownloadRejectsOtherDownloads(t *testing.T) {
+	ctx := context.Background()
+	serveFakeLog(t, fakeEntries(4))
+	setFlag(t, &downloadLast, -1)
+
+	for _, test := range []struct {
+		desc  string
+		state downloadState
+		first int64
+	}{
+		{desc: "other-log", state: downloadState{LogURI: "https://other.example.com/", First: 0, Next: 2}, first: -1},
+		{desc: "first-before", state: downloadState{First: 2, Next: 3}, first: 1},
+		{desc: "first-after", state: downloadState{First: 0, Next: 2}, first: 3},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			setFlag(t, &downloadDir, dir)
+			setFlag(t, &downloadFirst, test.first)
+			if test.state.LogURI == "" {
+				test.state.LogURI = logURI
+			}
+			if err := writeDownloadState(filepath.Join(dir, downloadStateFile), &test.state); err != nil {
+				t.Fatalf("writeDownloadState()=%v", err)
+			}
+			if err := download(ctx, connect(ctx)); err == nil {
+				t.Error("download()=nil, want error")
+			}
+		})
+	}
+}
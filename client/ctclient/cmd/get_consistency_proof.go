@@ -87,18 +87,26 @@ func getConsistencyProofBetween(ctx context.Context, logClient client.CheckLogCl
 	if err != nil {
 		exitWithDetails(err)
 	}
+	result := consistencyJSON{FirstSize: first, SecondSize: second, Proof: hexSlices(pf)}
+	if prevHash != nil && treeHash != nil {
+		// We have tree hashes so we can verify the proof.
+		if err := proof.VerifyConsistency(rfc6962.DefaultHasher, first, second, pf, prevHash, treeHash); err != nil {
+			klog.Exitf("Failed to VerifyConsistency(%x @size=%d, %x @size=%d): %v", prevHash, first, treeHash, second, err)
+		}
+		result.FirstHash, result.SecondHash = prevHash, treeHash
+		result.Verified = true
+	}
+	if jsonOutput() {
+		printJSON(result)
+		return
+	}
 	fmt.Printf("Consistency proof from size %d to size %d:\n", first, second)
 	for _, e := range pf {
 		fmt.Printf("  %x\n", e)
 	}
-	if prevHash == nil || treeHash == nil {
-		return
-	}
-	// We have tree hashes so we can verify the proof.
-	if err := proof.VerifyConsistency(rfc6962.DefaultHasher, first, second, pf, prevHash, treeHash); err != nil {
-		klog.Exitf("Failed to VerifyConsistency(%x @size=%d, %x @size=%d): %v", prevHash, first, treeHash, second, err)
+	if result.Verified {
+		fmt.Printf("Verified that hash %x @%d + proof = hash %x @%d\n", prevHash, first, treeHash, second)
 	}
-	fmt.Printf("Verified that hash %x @%d + proof = hash %x @%d\n", prevHash, first, treeHash, second)
 }
 
 func hashFromString(input string) ([]byte, error) {
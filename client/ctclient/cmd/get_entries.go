@@ -65,6 +65,18 @@ func runGetEntries(ctx context.Context) {
 		exitWithDetails(err)
 	}
 
+	if jsonOutput() {
+		entries := make([]entryJSON, len(rsp.Entries))
+		for i := range rsp.Entries {
+			index := getFirst + int64(i)
+			rle, err := ct.RawLogEntryFromLeaf(index, &rsp.Entries[i])
+			entries[i] = newEntryJSON(index, rle, err)
+		}
+		printJSON(struct {
+			Entries []entryJSON `json:"entries"`
+		}{Entries: entries})
+		return
+	}
 	for i, rawEntry := range rsp.Entries {
 		index := getFirst + int64(i)
 		rle, err := ct.RawLogEntryFromLeaf(index, &rawEntry)
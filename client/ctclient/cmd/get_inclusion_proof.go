@@ -107,10 +107,12 @@ func runGetInclusionProof(ctx context.Context) {
 	if len(hash) != sha256.Size {
 		klog.Exit("No leaf hash available")
 	}
-	getInclusionProofForHash(ctx, logClient, hash)
+	showInclusionProof(getInclusionProofForHash(ctx, logClient, hash))
 }
 
-func getInclusionProofForHash(ctx context.Context, logClient client.CheckLogClient, hash []byte) {
+// getInclusionProofForHash gets the inclusion proof for the leaf hash, and
+// verifies it if no tree size was given, against the latest STH.
+func getInclusionProofForHash(ctx context.Context, logClient client.CheckLogClient, hash []byte) *inclusionJSON {
 	var sth *ct.SignedTreeHead
 	size := treeSize
 	if size <= 0 {
@@ -126,16 +128,29 @@ func getInclusionProofForHash(ctx context.Context, logClient client.CheckLogClie
 	if err != nil {
 		exitWithDetails(err)
 	}
-	fmt.Printf("Inclusion proof for index %d in tree of size %d:\n", rsp.LeafIndex, size)
-	for _, e := range rsp.AuditPath {
-		fmt.Printf("  %x\n", e)
-	}
+	result := &inclusionJSON{LeafHash: hash, LeafIndex: rsp.LeafIndex, TreeSize: size, AuditPath: hexSlices(rsp.AuditPath)}
 	if sth != nil {
 		// If we retrieved an STH we can verify the proof.
 		if err := proof.VerifyInclusion(rfc6962.DefaultHasher, uint64(rsp.LeafIndex), sth.TreeSize, hash, rsp.AuditPath, sth.SHA256RootHash[:]); err != nil {
 			klog.Exitf("Failed to VerifyInclusion(%d, %d)=%v", rsp.LeafIndex, sth.TreeSize, err)
 		}
-		fmt.Printf("Verified that hash %x + proof = root hash %x\n", hash, sth.SHA256RootHash)
+		result.RootHash = sth.SHA256RootHash[:]
+		result.Verified = true
+	}
+	return result
+}
+
+func showInclusionProof(result *inclusionJSON) {
+	if jsonOutput() {
+		printJSON(result)
+		return
+	}
+	fmt.Printf("Inclusion proof for index %d in tree of size %d:\n", result.LeafIndex, result.TreeSize)
+	for _, e := range result.AuditPath {
+		fmt.Printf("  %x\n", e)
+	}
+	if result.Verified {
+		fmt.Printf("Verified that hash %x + proof = root hash %x\n", result.LeafHash, result.RootHash)
 	}
 }
 
@@ -43,6 +43,16 @@ func runGetRoots(ctx context.Context) {
 	if err != nil {
 		exitWithDetails(err)
 	}
+	if jsonOutput() {
+		ders := make([][]byte, len(roots))
+		for i, root := range roots {
+			ders[i] = root.Data
+		}
+		printJSON(struct {
+			Roots [][]byte `json:"roots"`
+		}{Roots: ders})
+		return
+	}
 	for _, root := range roots {
 		showRawCert(root)
 	}
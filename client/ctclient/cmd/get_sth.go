@@ -43,6 +43,18 @@ func runGetSTH(ctx context.Context) {
 	}
 	// Display the STH.
 	when := ct.TimestampToTime(sth.Timestamp)
+	if jsonOutput() {
+		printJSON(sthJSON{
+			LogURI:         logClient.BaseURI(),
+			Version:        sth.Version.String(),
+			TreeSize:       sth.TreeSize,
+			Timestamp:      sth.Timestamp,
+			Time:           when,
+			SHA256RootHash: sth.SHA256RootHash[:],
+			Signature:      newSignatureJSON(&sth.TreeHeadSignature),
+		})
+		return
+	}
 	fmt.Printf("%v (timestamp %d): Got STH for %v log (size=%d) at %v, hash %x\n", when, sth.Timestamp, sth.Version, sth.TreeSize, logClient.BaseURI(), sth.SHA256RootHash)
 	fmt.Printf("%v\n", signatureToString(&sth.TreeHeadSignature))
 }
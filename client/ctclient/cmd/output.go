@@ -0,0 +1,149 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"k8s.io/klog/v2"
+)
+
+// Values of the --output flag.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// jsonOutput reports whether subcommands should print JSON rather than text.
+func jsonOutput() bool {
+	return outputFormat == outputJSON
+}
+
+// printJSON prints v to stdout as indented JSON.
+func printJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		klog.Exitf("Failed to encode JSON output: %v", err)
+	}
+}
+
+// hexBytes is marshaled to JSON as a hex string, as hashes are shown in text
+// output. Certificates are left as []byte, so are marshaled as base64 DER.
+type hexBytes []byte
+
+// MarshalJSON implements json.Marshaler.
+func (h hexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(h))
+}
+
+func hexSlices(hs [][]byte) []hexBytes {
+	out := make([]hexBytes, len(hs))
+	for i, h := range hs {
+		out[i] = h
+	}
+	return out
+}
+
+type signatureJSON struct {
+	Hash      string   `json:"hash"`
+	Signature string   `json:"signature"`
+	Value     hexBytes `json:"value"`
+}
+
+func newSignatureJSON(signed *ct.DigitallySigned) signatureJSON {
+	return signatureJSON{
+		Hash:      signed.Algorithm.Hash.String(),
+		Signature: signed.Algorithm.Signature.String(),
+		Value:     signed.Signature,
+	}
+}
+
+type sthJSON struct {
+	LogURI         string        `json:"log_uri"`
+	Version        string        `json:"version"`
+	TreeSize       uint64        `json:"tree_size"`
+	Timestamp      uint64        `json:"timestamp"`
+	Time           time.Time     `json:"time"`
+	SHA256RootHash hexBytes      `json:"sha256_root_hash"`
+	Signature      signatureJSON `json:"signature"`
+}
+
+type entryJSON struct {
+	Index         int64      `json:"index"`
+	Error         string     `json:"error,omitempty"`
+	Timestamp     uint64     `json:"timestamp,omitempty"`
+	Time          *time.Time `json:"time,omitempty"`
+	EntryType     string     `json:"entry_type,omitempty"`
+	LeafHash      hexBytes   `json:"leaf_hash,omitempty"`
+	IssuerKeyHash hexBytes   `json:"issuer_key_hash,omitempty"`
+	// Cert is the X.509 certificate, or the pre-certificate as submitted,
+	// with signature and poison.
+	Cert  []byte   `json:"cert,omitempty"`
+	Chain [][]byte `json:"chain,omitempty"`
+}
+
+// newEntryJSON describes the entry at index, which failed to parse if err
+// is set. The chain is only included if --chain is set.
+func newEntryJSON(index int64, rle *ct.RawLogEntry, err error) entryJSON {
+	if err != nil {
+		return entryJSON{Index: index, Error: err.Error()}
+	}
+	ts := rle.Leaf.TimestampedEntry
+	when := ct.TimestampToTime(ts.Timestamp)
+	e := entryJSON{
+		Index:     index,
+		Timestamp: ts.Timestamp,
+		Time:      &when,
+		EntryType: ts.EntryType.String(),
+		Cert:      rle.Cert.Data,
+	}
+	if hash, err := ct.LeafHashForLeaf(&rle.Leaf); err == nil {
+		e.LeafHash = hash[:]
+	}
+	if ts.EntryType == ct.PrecertLogEntryType {
+		e.IssuerKeyHash = ts.PrecertEntry.IssuerKeyHash[:]
+	}
+	if chainOut {
+		for _, c := range rle.Chain {
+			e.Chain = append(e.Chain, c.Data)
+		}
+	}
+	return e
+}
+
+type inclusionJSON struct {
+	LeafHash  hexBytes   `json:"leaf_hash"`
+	LeafIndex int64      `json:"leaf_index"`
+	TreeSize  uint64     `json:"tree_size"`
+	AuditPath []hexBytes `json:"audit_path"`
+	// RootHash is only set if the proof was verified against it.
+	RootHash hexBytes `json:"root_hash,omitempty"`
+	Verified bool     `json:"verified"`
+}
+
+type consistencyJSON struct {
+	FirstSize  uint64     `json:"first_size"`
+	SecondSize uint64     `json:"second_size"`
+	Proof      []hexBytes `json:"proof"`
+	// The tree hashes are only set if the proof was verified against them.
+	FirstHash  hexBytes `json:"first_hash,omitempty"`
+	SecondHash hexBytes `json:"second_hash,omitempty"`
+	Verified   bool     `json:"verified"`
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+	"github.com/google/go-cmp/cmp"
+)
+
+var updateGolden = flag.Bool("update", false, "Update the golden output files in testdata")
+
+// logURIPlaceholder replaces the URI of the test log, which varies between
+// runs, in the golden output.
+const logURIPlaceholder = "https://log.example.com"
+
+func TestMain(m *testing.M) {
+	// Times are shown in the local time zone, which the golden output
+	// needs to be independent of.
+	time.Local = time.UTC
+	os.Exit(m.Run())
+}
+
+func mustParsePEM(t *testing.T, pemData string) *x509.Certificate {
+	t.Helper()
+	cert, err := x509util.CertificateFromPEM([]byte(pemData))
+	if x509.IsFatal(err) {
+		t.Fatalf("CertificateFromPEM()=%v", err)
+	}
+	return cert
+}
+
+// testLogEntries returns a certificate entry and a pre-certificate entry,
+// both issued by the test CA.
+func testLogEntries(t *testing.T) []ct.LeafEntry {
+	t.Helper()
+	ca := mustParsePEM(t, testdata.CACertPEM)
+	cert := mustParsePEM(t, testdata.TestCertPEM)
+	precert := mustParsePEM(t, testdata.TestPreCertPEM)
+	caChain := []ct.ASN1Cert{{Data: ca.Raw}}
+
+	var entries []ct.LeafEntry
+	for _, e := range []struct {
+		etype ct.LogEntryType
+		cert  *x509.Certificate
+		extra interface{}
+	}{
+		{etype: ct.X509LogEntryType, cert: cert, extra: ct.CertificateChain{Entries: caChain}},
+		{etype: ct.PrecertLogEntryType, cert: precert, extra: ct.PrecertChainEntry{PreCertificate: ct.ASN1Cert{Data: precert.Raw}, CertificateChain: caChain}},
+	} {
+		leaf, err := ct.MerkleTreeLeafFromChain([]*x509.Certificate{e.cert, ca}, e.etype, 1700000000456)
+		if err != nil {
+			t.Fatalf("MerkleTreeLeafFromChain()=%v", err)
+		}
+		leafInput, err := tls.Marshal(*leaf)
+		if err != nil {
+			t.Fatalf("tls.Marshal(leaf)=%v", err)
+		}
+		extraData, err := tls.Marshal(e.extra)
+		if err != nil {
+			t.Fatalf("tls.Marshal(extra)=%v", err)
+		}
+		entries = append(entries, ct.LeafEntry{LeafInput: leafInput, ExtraData: extraData})
+	}
+	return entries
+}
+
+func TestOutput(t *testing.T) {
+	ctx := context.Background()
+	serveFakeLog(t, testLogEntries(t))
+	setFlag(t, &getFirst, 0)
+	setFlag(t, &getLast, 1)
+	setFlag(t, &chainOut, true)
+	setFlag(t, &textOut, true)
+
+	for _, test := range []struct {
+		golden string
+		format string
+		run    func(context.Context)
+	}{
+		{golden: "get_sth.txt", format: outputText, run: runGetSTH},
+		{golden: "get_sth.json", format: outputJSON, run: runGetSTH},
+		{golden: "get_entries.txt", format: outputText, run: runGetEntries},
+		{golden: "get_entries.json", format: outputJSON, run: runGetEntries},
+	} {
+		t.Run(test.golden, func(t *testing.T) {
+			setFlag(t, &outputFormat, test.format)
+			got := captureStdout(t, func() { test.run(ctx) })
+			got = strings.ReplaceAll(got, logURI, logURIPlaceholder)
+
+			path := filepath.Join("testdata", test.golden)
+			if *updateGolden {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatalf("WriteFile()=%v", err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile()=%v", err)
+			}
+			if diff := cmp.Diff(string(want), got); diff != "" {
+				t.Errorf("output diff (-want +got), run with -update to accept:\n%s", diff)
+			}
+		})
+	}
+}
@@ -44,6 +44,13 @@ var (
 	logList         string
 	logURI          string
 	pubKey          string
+	userAgent       string
+	requestIDHeader string
+	outputFormat    string
+
+	// retryOpts, if set by a subcommand before it connects, makes the log
+	// client retry failed reads.
+	retryOpts *jsonclient.RetryOptions
 )
 
 func init() {
@@ -56,6 +63,9 @@ func init() {
 	flags.StringVar(&logList, "log_list", loglist3.AllLogListURL, "Location of master log list (URL or filename)")
 	flags.StringVar(&logURI, "log_uri", "https://ct.googleapis.com/rocketeer", "CT log base URI")
 	flags.StringVar(&pubKey, "pub_key", "", "Name of file containing log's public key")
+	flags.StringVar(&userAgent, "user_agent", "ct-go-ctclient/1.0", "User-Agent header to send with requests")
+	flags.StringVar(&requestIDHeader, "request_id_header", "", "If set, header in which to send a generated ID with each request, e.g. X-Request-ID")
+	flags.StringVar(&outputFormat, "output", outputText, "Output format: text, or json for a JSON document on stdout")
 }
 
 // rootCmd represents the base command when called without any subcommands.
@@ -65,6 +75,9 @@ var rootCmd = &cobra.Command{
 
 	PersistentPreRun: func(cmd *cobra.Command, _ []string) {
 		flag.Parse()
+		if outputFormat != outputText && outputFormat != outputJSON {
+			klog.Exitf("Invalid --output %q; want %q or %q", outputFormat, outputText, outputJSON)
+		}
 	},
 }
 
@@ -106,7 +119,7 @@ func connect(ctx context.Context) *client.LogClient {
 			TLSClientConfig:       tlsCfg,
 		},
 	}
-	opts := jsonclient.Options{UserAgent: "ct-go-ctclient/1.0"}
+	opts := jsonclient.Options{UserAgent: userAgent, RequestIDHeader: requestIDHeader, Retry: retryOpts}
 	if pubKey != "" {
 		pubkey, err := os.ReadFile(pubKey)
 		if err != nil {
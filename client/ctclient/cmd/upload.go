@@ -59,7 +59,9 @@ func runUpload(ctx context.Context) {
 		count, _ := x509util.OIDInExtensions(x509.OIDExtensionCTPoison, leaf.Extensions)
 		if count > 0 {
 			isPrecert = true
-			fmt.Print("Uploading pre-certificate to log\n")
+			if !jsonOutput() {
+				fmt.Print("Uploading pre-certificate to log\n")
+			}
 		}
 	}
 
@@ -81,14 +83,44 @@ func runUpload(ctx context.Context) {
 
 	// Display the SCT.
 	when := ct.TimestampToTime(sct.Timestamp)
+	age := time.Since(when)
+	if jsonOutput() {
+		// Inclusion is only set if the SCT is older than the MMD.
+		result := struct {
+			LogURI      string         `json:"log_uri"`
+			Precert     bool           `json:"precert"`
+			ChainLength int            `json:"chain_length"`
+			SCTVersion  string         `json:"sct_version"`
+			LogID       hexBytes       `json:"log_id"`
+			Timestamp   uint64         `json:"timestamp"`
+			Time        time.Time      `json:"time"`
+			LeafHash    hexBytes       `json:"leaf_hash"`
+			Signature   signatureJSON  `json:"signature"`
+			Inclusion   *inclusionJSON `json:"inclusion,omitempty"`
+		}{
+			LogURI:      logClient.BaseURI(),
+			Precert:     isPrecert,
+			ChainLength: len(chain),
+			SCTVersion:  sct.SCTVersion.String(),
+			LogID:       sct.LogID.KeyID[:],
+			Timestamp:   sct.Timestamp,
+			Time:        when,
+			LeafHash:    leafHash[:],
+			Signature:   newSignatureJSON(&sct.Signature),
+		}
+		if age > logMMD {
+			result.Inclusion = getInclusionProofForHash(ctx, logClient, leafHash[:])
+		}
+		printJSON(result)
+		return
+	}
 	fmt.Printf("Uploaded chain of %d certs to %v log at %v, timestamp: %d (%v)\n", len(chain), sct.SCTVersion, logClient.BaseURI(), sct.Timestamp, when)
 	fmt.Printf("LogID: %x\n", sct.LogID.KeyID[:])
 	fmt.Printf("LeafHash: %x\n", leafHash)
 	fmt.Printf("Signature: %v\n", signatureToString(&sct.Signature))
 
-	age := time.Since(when)
 	if age > logMMD {
 		// SCT's timestamp is old enough that the certificate should be included.
-		getInclusionProofForHash(ctx, logClient, leafHash[:])
+		showInclusionProof(getInclusionProofForHash(ctx, logClient, leafHash[:]))
 	}
 }
@@ -0,0 +1,200 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+)
+
+// EntryGetter is the subset of LogClient used by a Fetcher.
+type EntryGetter interface {
+	GetRawEntries(ctx context.Context, start, end int64) (*ct.GetEntriesResponse, error)
+}
+
+// FetcherOptions configures a Fetcher.
+type FetcherOptions struct {
+	// Workers is the number of concurrent get-entries requests. Defaults to 1.
+	Workers int
+	// BatchSize is the number of entries requested in each get-entries
+	// request. Logs may return fewer entries than requested, in which case
+	// later requests are no bigger than the log's limit. Defaults to 1000.
+	BatchSize int
+	// Progress, if set, is called after each batch of entries is delivered.
+	Progress func(FetchProgress)
+}
+
+// FetchProgress reports the progress of a Fetcher.
+type FetchProgress struct {
+	// [Start, End) is the range being fetched.
+	Start, End int64
+	// Next is the index of the next entry to be delivered.
+	Next int64
+	// Elapsed is the time since fetching started.
+	Elapsed time.Duration
+}
+
+// Fetcher downloads ranges of log entries with concurrent get-entries
+// requests, and delivers them in order. Failed requests are retried as
+// configured by the jsonclient.RetryOptions of the LogClient.
+type Fetcher struct {
+	client EntryGetter
+	opts   FetcherOptions
+
+	mu        sync.Mutex
+	batchSize int64
+}
+
+// NewFetcher creates a Fetcher which gets entries with client.
+func NewFetcher(client EntryGetter, opts FetcherOptions) *Fetcher {
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+	return &Fetcher{client: client, opts: opts, batchSize: int64(opts.BatchSize)}
+}
+
+// fetchJob is a range [start, end) of entries to be fetched by a worker.
+type fetchJob struct {
+	start, end int64
+	done       chan fetchResult
+}
+
+type fetchResult struct {
+	entries []ct.LeafEntry
+	err     error
+}
+
+// Fetch gets the entries in [start, end), and calls fn with each consecutive
+// batch of them in order, along with the index of the batch's first entry.
+// Returns the first error from fetching or from fn, after which no more
+// batches are delivered.
+func (f *Fetcher) Fetch(ctx context.Context, start, end int64, fn func(start int64, entries []ct.LeafEntry) error) error {
+	if start < 0 || end < start {
+		return fmt.Errorf("invalid range [%d, %d)", start, end)
+	}
+	began := time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	// Jobs are queued in order for delivery as well as sent to the workers.
+	// The queue's capacity bounds how far the workers can get ahead of fn.
+	queue := make(chan *fetchJob, 2*f.opts.Workers)
+	jobs := make(chan *fetchJob)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(queue)
+		defer close(jobs)
+		for next := start; next < end; {
+			batchEnd := end
+			if size := f.currentBatchSize(); end-next > size {
+				batchEnd = next + size
+			}
+			job := &fetchJob{start: next, end: batchEnd, done: make(chan fetchResult, 1)}
+			select {
+			case queue <- job:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return
+			}
+			next = batchEnd
+		}
+	}()
+	for i := 0; i < f.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				entries, err := f.fetchRange(ctx, job.start, job.end)
+				job.done <- fetchResult{entries: entries, err: err}
+			}
+		}()
+	}
+
+	for job := range queue {
+		var res fetchResult
+		select {
+		case res = <-job.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if res.err != nil {
+			return res.err
+		}
+		if err := fn(job.start, res.entries); err != nil {
+			return err
+		}
+		if f.opts.Progress != nil {
+			f.opts.Progress(FetchProgress{Start: start, End: end, Next: job.end, Elapsed: time.Since(began)})
+		}
+	}
+	return ctx.Err()
+}
+
+// fetchRange gets all the entries in [start, end), with as many requests as
+// the log needs.
+func (f *Fetcher) fetchRange(ctx context.Context, start, end int64) ([]ct.LeafEntry, error) {
+	entries := make([]ct.LeafEntry, 0, end-start)
+	for next := start; next < end; {
+		rsp, err := f.client.GetRawEntries(ctx, next, end-1)
+		if err != nil {
+			return nil, err
+		}
+		got := int64(len(rsp.Entries))
+		if got == 0 {
+			return nil, errors.New("log returned no entries")
+		}
+		if got > end-next {
+			got = end - next
+		} else if got < end-next {
+			f.limitBatchSize(got)
+		}
+		entries = append(entries, rsp.Entries[:got]...)
+		next += got
+	}
+	return entries, nil
+}
+
+func (f *Fetcher) currentBatchSize() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.batchSize
+}
+
+// limitBatchSize lowers the batch size to the number of entries returned
+// by a truncated get-entries response.
+func (f *Fetcher) limitBatchSize(size int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if size < f.batchSize {
+		f.batchSize = size
+	}
+}
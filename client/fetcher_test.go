@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+)
+
+// fakeEntryGetter serves entries whose ExtraData is their index, returning at
+// most limit entries per request.
+type fakeEntryGetter struct {
+	limit   int64
+	failAt  int64
+	mu      sync.Mutex
+	maxSize int64
+}
+
+func (g *fakeEntryGetter) GetRawEntries(ctx context.Context, start, end int64) (*ct.GetEntriesResponse, error) {
+	time.Sleep(time.Duration(rand.Intn(2000)) * time.Microsecond)
+	g.mu.Lock()
+	if size := end - start + 1; size > g.maxSize {
+		g.maxSize = size
+	}
+	g.mu.Unlock()
+	if g.failAt >= start && g.failAt <= end {
+		return nil, errors.New("injected failure")
+	}
+	if end-start+1 > g.limit {
+		end = start + g.limit - 1
+	}
+	var rsp ct.GetEntriesResponse
+	for i := start; i <= end; i++ {
+		rsp.Entries = append(rsp.Entries, ct.LeafEntry{ExtraData: []byte(strconv.FormatInt(i, 10))})
+	}
+	return &rsp, nil
+}
+
+func TestFetcher(t *testing.T) {
+	ctx := context.Background()
+	g := &fakeEntryGetter{limit: 7, failAt: -1}
+	var progress []client.FetchProgress
+	f := client.NewFetcher(g, client.FetcherOptions{
+		Workers:   4,
+		BatchSize: 10,
+		Progress:  func(p client.FetchProgress) { progress = append(progress, p) },
+	})
+
+	next := int64(5)
+	err := f.Fetch(ctx, 5, 300, func(start int64, entries []ct.LeafEntry) error {
+		if start != next {
+			t.Fatalf("got batch at %d, want %d", start, next)
+		}
+		for _, e := range entries {
+			if got, want := string(e.ExtraData), strconv.FormatInt(next, 10); got != want {
+				t.Fatalf("got entry %s at index %d", got, next)
+			}
+			next++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Fetch()=%v", err)
+	}
+	if next != 300 {
+		t.Errorf("Fetch() delivered up to %d, want 300", next)
+	}
+	if len(progress) == 0 || progress[len(progress)-1].Next != 300 {
+		t.Errorf("last progress=%+v, want Next=300", progress[len(progress)-1])
+	}
+	// Requests should have shrunk to the log's limit.
+	g.maxSize = 0
+	if err := f.Fetch(ctx, 0, 50, func(int64, []ct.LeafEntry) error { return nil }); err != nil {
+		t.Fatalf("Fetch()=%v", err)
+	}
+	if g.maxSize != g.limit {
+		t.Errorf("largest request after truncation=%d, want %d", g.maxSize, g.limit)
+	}
+}
+
+func TestFetcherErrors(t *testing.T) {
+	ctx := context.Background()
+	f := client.NewFetcher(&fakeEntryGetter{limit: 10, failAt: 42}, client.FetcherOptions{Workers: 3, BatchSize: 10})
+	next := int64(0)
+	err := f.Fetch(ctx, 0, 100, func(start int64, entries []ct.LeafEntry) error {
+		next = start + int64(len(entries))
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "injected failure") {
+		t.Errorf("Fetch()=%v, want injected failure", err)
+	}
+	if next != 40 {
+		t.Errorf("Fetch() delivered up to %d before failure, want 40", next)
+	}
+
+	f = client.NewFetcher(&fakeEntryGetter{limit: 10, failAt: -1}, client.FetcherOptions{Workers: 3, BatchSize: 10})
+	calls := 0
+	err = f.Fetch(ctx, 0, 100, func(int64, []ct.LeafEntry) error {
+		calls++
+		return errors.New("stop")
+	})
+	if err == nil || err.Error() != "stop" || calls != 1 {
+		t.Errorf("Fetch()=%v after %d calls, want stop after 1", err, calls)
+	}
+
+	if err := f.Fetch(ctx, 10, 5, nil); err == nil {
+		t.Error("Fetch(10, 5)=nil, want error")
+	}
+}
@@ -16,10 +16,14 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"strconv"
 
 	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
 	"github.com/RarimoVoting/certificate-transparency-go/x509"
 )
 
@@ -37,14 +41,72 @@ func (c *LogClient) GetRawEntries(ctx context.Context, start, end int64) (*ct.Ge
 		"end":   strconv.FormatInt(end, 10),
 	}
 
+	if uint64(end) < c.verifiedSize.Load() {
+		ctx = jsonclient.WithImmutableResponse(ctx)
+	}
 	var resp ct.GetEntriesResponse
-	if _, _, err := c.GetAndParse(ctx, ct.GetEntriesPath, params, &resp); err != nil {
+	if _, err := c.GetAndDecode(ctx, ct.GetEntriesPath, params, func(r io.Reader) error {
+		resp.Entries = nil
+		return decodeEntries(r, &resp)
+	}); err != nil {
 		return nil, err
 	}
 
 	return &resp, nil
 }
 
+// decodeEntries decodes a get-entries response from r one entry at a time,
+// so that the whole response need not be held in memory at once.
+func decodeEntries(r io.Reader, resp *ct.GetEntriesResponse) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key != "entries" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+		if tok, err := dec.Token(); err != nil {
+			return err
+		} else if tok == nil {
+			continue
+		} else if tok != json.Delim('[') {
+			return fmt.Errorf("invalid get-entries response: got %v for entries", tok)
+		}
+		for dec.More() {
+			var entry ct.LeafEntry
+			if err := dec.Decode(&entry); err != nil {
+				return err
+			}
+			resp.Entries = append(resp.Entries, entry)
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// expectDelim reads the next JSON token from dec, which must be want.
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return fmt.Errorf("invalid get-entries response: got %v, want %v", tok, want)
+	}
+	return nil
+}
+
 // GetEntries attempts to retrieve the entries in the sequence [start, end] from the CT log server
 // (RFC6962 s4.6) as parsed [pre-]certificates for convenience, held in a slice of ct.LogEntry structures.
 // However, this does mean that any certificate parsing failures will cause a failure of the whole
@@ -55,6 +117,52 @@ func (c *LogClient) GetEntries(ctx context.Context, start, end int64) ([]ct.LogE
 	if err != nil {
 		return nil, err
 	}
+	return logEntriesFromResponse(start, resp)
+}
+
+// Entries returns an iterator over the entries in the range [start, end] of
+// the log, which pages through get-entries as far as the log allows in each
+// request. It is an iter.Seq2[ct.LogEntry, error], so callers built with Go
+// 1.23 or later can range over it. Iteration stops after an error is yielded.
+func (c *LogClient) Entries(ctx context.Context, start, end int64) func(yield func(ct.LogEntry, error) bool) {
+	return entriesIterator(ctx, c.GetRawEntries, start, end)
+}
+
+// entriesIterator returns an iterator over the entries in the range
+// [start, end], fetched with getRawEntries.
+func entriesIterator(ctx context.Context, getRawEntries func(ctx context.Context, start, end int64) (*ct.GetEntriesResponse, error), start, end int64) func(yield func(ct.LogEntry, error) bool) {
+	return func(yield func(ct.LogEntry, error) bool) {
+		for next := start; next <= end; {
+			resp, err := getRawEntries(ctx, next, end)
+			if err != nil {
+				yield(ct.LogEntry{}, err)
+				return
+			}
+			if len(resp.Entries) == 0 {
+				yield(ct.LogEntry{}, fmt.Errorf("log returned no entries from %d", next))
+				return
+			}
+			for i := range resp.Entries {
+				if next > end {
+					return
+				}
+				logEntry, err := ct.LogEntryFromLeaf(next, &resp.Entries[i])
+				if x509.IsFatal(err) {
+					yield(ct.LogEntry{}, err)
+					return
+				}
+				if !yield(*logEntry, nil) {
+					return
+				}
+				next++
+			}
+		}
+	}
+}
+
+// logEntriesFromResponse parses the entries of a get-entries response which
+// starts at index start.
+func logEntriesFromResponse(start int64, resp *ct.GetEntriesResponse) ([]ct.LogEntry, error) {
 	entries := make([]ct.LogEntry, len(resp.Entries))
 	for i, entry := range resp.Entries {
 		index := start + int64(i)
@@ -0,0 +1,153 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+var (
+	// ErrNotIncorporated is returned by WaitForInclusion when an entry is
+	// not incorporated into the log within its Maximum Merge Delay.
+	ErrNotIncorporated = errors.New("entry not incorporated within MMD")
+	// ErrInvalidInclusionProof is returned by WaitForInclusion when the log
+	// serves an inclusion proof which does not verify against its STH.
+	ErrInvalidInclusionProof = errors.New("invalid inclusion proof")
+)
+
+// InclusionOptions configures WaitForInclusion.
+type InclusionOptions struct {
+	// MMD is the Maximum Merge Delay of the log. Defaults to 24 hours.
+	MMD time.Duration
+	// Slack is added to the MMD, to allow for clock skew and for the time
+	// taken by the log to publish an STH.
+	Slack time.Duration
+	// PollInterval is the initial wait between polls of the log, which
+	// doubles after each poll up to MaxPollInterval. Defaults to 10s and
+	// 5 minutes.
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration
+}
+
+// WaitForInclusion polls the log until the entry for sct, which was issued
+// for chain as the given entry type, is incorporated, or until the MMD plus
+// slack has elapsed since the SCT's timestamp. Returns the STH in which the
+// entry was found, along with a verified inclusion proof for it. If the MMD
+// elapses first, the error wraps ErrNotIncorporated and describes the last
+// error from the log, if any. Other errors from the log are retried.
+func (c *LogClient) WaitForInclusion(ctx context.Context, sct *ct.SignedCertificateTimestamp, ctype ct.LogEntryType, chain []ct.ASN1Cert, opts InclusionOptions) (*ct.SignedTreeHead, *ct.GetProofByHashResponse, error) {
+	leaf, err := ct.MerkleTreeLeafFromRawChain(chain, ctype, sct.Timestamp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build MerkleTreeLeaf: %v", err)
+	}
+	leaf.TimestampedEntry.Extensions = sct.Extensions
+	leafHash, err := ct.LeafHashForLeaf(leaf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to hash MerkleTreeLeaf: %v", err)
+	}
+
+	if opts.MMD <= 0 {
+		opts.MMD = 24 * time.Hour
+	}
+	interval, limit := opts.PollInterval, opts.MaxPollInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	if limit <= 0 {
+		limit = 5 * time.Minute
+	}
+	deadline := ct.TimestampToTime(sct.Timestamp).Add(opts.MMD + opts.Slack)
+
+	var checked uint64
+	var lastErr error
+	for {
+		sth, rsp, err := c.findInclusion(ctx, leafHash[:], checked)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return nil, nil, ctx.Err()
+		case errors.Is(err, ErrInvalidInclusionProof):
+			return nil, nil, err
+		case err != nil:
+			lastErr = err
+		case rsp != nil:
+			return sth, rsp, nil
+		case sth != nil:
+			checked = sth.TreeSize
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			if lastErr != nil {
+				return nil, nil, fmt.Errorf("%w after %v: last error: %v", ErrNotIncorporated, opts.MMD+opts.Slack, lastErr)
+			}
+			return nil, nil, fmt.Errorf("%w after %v: not in tree of size %d", ErrNotIncorporated, opts.MMD+opts.Slack, checked)
+		}
+		if interval < wait {
+			wait = interval
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, ctx.Err()
+		case <-timer.C:
+		}
+		if interval *= 2; interval > limit {
+			interval = limit
+		}
+	}
+}
+
+// findInclusion gets the latest STH from the log and, if it is larger than
+// the given tree size, an inclusion proof for leafHash. Returns a nil proof if
+// the entry is not in the tree.
+func (c *LogClient) findInclusion(ctx context.Context, leafHash []byte, checked uint64) (*ct.SignedTreeHead, *ct.GetProofByHashResponse, error) {
+	sth, err := c.GetSTH(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sth.TreeSize <= checked {
+		return nil, nil, nil
+	}
+	if sth.TreeSize == 1 {
+		// Logs may not serve proofs for a tree of one entry, which need none.
+		if bytes.Equal(leafHash, sth.SHA256RootHash[:]) {
+			return sth, &ct.GetProofByHashResponse{LeafIndex: 0}, nil
+		}
+		return sth, nil, nil
+	}
+	rsp, err := c.GetProofByHash(ctx, leafHash, sth.TreeSize)
+	if err != nil {
+		var rspErr RspError
+		if errors.As(err, &rspErr) && (rspErr.StatusCode == http.StatusNotFound || rspErr.StatusCode == http.StatusBadRequest) {
+			// The entry is not yet in the tree.
+			return sth, nil, nil
+		}
+		return nil, nil, err
+	}
+	if err := proof.VerifyInclusion(rfc6962.DefaultHasher, uint64(rsp.LeafIndex), sth.TreeSize, leafHash, rsp.AuditPath, sth.SHA256RootHash[:]); err != nil {
+		return nil, nil, fmt.Errorf("%w for index %d in tree of size %d: %v", ErrInvalidInclusionProof, rsp.LeafIndex, sth.TreeSize, err)
+	}
+	return sth, rsp, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+// inclusionLog serves a tree of four entries, of which the third is the one
+// being waited for. It only reveals the whole tree after growAfter requests
+// for its STH.
+type inclusionLog struct {
+	leafHash  []byte
+	growAfter int
+	badProof  bool
+
+	mu   sync.Mutex
+	sths int
+}
+
+func (l *inclusionLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h := rfc6962.DefaultHasher
+	leaves := [][]byte{h.HashLeaf([]byte("a")), h.HashLeaf([]byte("b")), l.leafHash, h.HashLeaf([]byte("d"))}
+	left, right := h.HashChildren(leaves[0], leaves[1]), h.HashChildren(leaves[2], leaves[3])
+
+	var rsp interface{}
+	switch r.URL.Path {
+	case "/ct/v1/get-sth":
+		l.mu.Lock()
+		l.sths++
+		grown := l.sths > l.growAfter
+		l.mu.Unlock()
+		sth := ct.GetSTHResponse{TreeSize: 2, SHA256RootHash: left}
+		if grown {
+			sth = ct.GetSTHResponse{TreeSize: 4, SHA256RootHash: h.HashChildren(left, right)}
+		}
+		sth.TreeHeadSignature, _ = tls.Marshal(ct.DigitallySigned{
+			Algorithm: tls.SignatureAndHashAlgorithm{Hash: tls.SHA256, Signature: tls.ECDSA},
+			Signature: []byte{0x01},
+		})
+		rsp = sth
+	case "/ct/v1/get-proof-by-hash":
+		if r.URL.Query().Get("tree_size") != "4" {
+			http.NotFound(w, r)
+			return
+		}
+		path := [][]byte{leaves[3], left}
+		if l.badProof {
+			path[0] = leaves[0]
+		}
+		rsp = ct.GetProofByHashResponse{LeafIndex: 2, AuditPath: path}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(rsp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func TestWaitForInclusion(t *testing.T) {
+	cert, err := x509util.CertificateFromPEM([]byte(testdata.TestCertPEM))
+	if x509.IsFatal(err) {
+		t.Fatalf("Failed to parse certificate from PEM: %v", err)
+	}
+	chain := []ct.ASN1Cert{{Data: cert.Raw}}
+	sct := &ct.SignedCertificateTimestamp{SCTVersion: ct.V1, Timestamp: uint64(time.Now().UnixNano() / int64(time.Millisecond))}
+	leaf, err := ct.MerkleTreeLeafFromRawChain(chain, ct.X509LogEntryType, sct.Timestamp)
+	if err != nil {
+		t.Fatalf("MerkleTreeLeafFromRawChain()=%v", err)
+	}
+	leafHash, err := ct.LeafHashForLeaf(leaf)
+	if err != nil {
+		t.Fatalf("LeafHashForLeaf()=%v", err)
+	}
+
+	opts := client.InclusionOptions{MMD: 500 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+	for _, test := range []struct {
+		desc      string
+		growAfter int
+		badProof  bool
+		wantErr   error
+	}{
+		{desc: "included", growAfter: 3},
+		{desc: "not-incorporated", growAfter: 1 << 30, wantErr: client.ErrNotIncorporated},
+		{desc: "bad-proof", badProof: true, wantErr: client.ErrInvalidInclusionProof},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ts := httptest.NewServer(&inclusionLog{leafHash: leafHash[:], growAfter: test.growAfter, badProof: test.badProof})
+			defer ts.Close()
+			lc, err := client.New(ts.URL, &http.Client{}, jsonclient.Options{})
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+			sth, rsp, err := lc.WaitForInclusion(context.Background(), sct, ct.X509LogEntryType, chain, opts)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Errorf("WaitForInclusion()=%v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WaitForInclusion()=%v", err)
+			}
+			if sth.TreeSize != 4 || rsp.LeafIndex != 2 || !bytes.Equal(rsp.AuditPath[0], rfc6962.DefaultHasher.HashLeaf([]byte("d"))) {
+				t.Errorf("WaitForInclusion()=%+v, %+v; want index 2 in tree of size 4", sth, rsp)
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 
 	ct "github.com/RarimoVoting/certificate-transparency-go"
 	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
@@ -32,6 +33,14 @@ import (
 // LogClient represents a client for a given CT Log instance
 type LogClient struct {
 	jsonclient.JSONClient
+	// SCTChecks, if set, enables further checks on the SCTs returned by
+	// AddChain and AddPreChain, which fail with an *SCTError.
+	SCTChecks *SCTCheckOptions
+
+	// verifiedSize is the largest tree size of an STH whose signature the
+	// client has verified. Entries below it never change, so responses for
+	// them may be cached.
+	verifiedSize atomic.Uint64
 }
 
 // CheckLogClient is an interface that allows (just) checking of various log contents.
@@ -46,14 +55,15 @@ type CheckLogClient interface {
 // |uri| is the base URI of the CT log instance to interact with, e.g.
 // https://ct.googleapis.com/pilot
 // |hc| is the underlying client to be used for HTTP requests to the CT log.
-// |opts| can be used to provide a custom logger interface and a public key
-// for signature verification.
+// |opts| can be used to provide a custom logger interface, a public key
+// for signature verification, and the retrying of requests which fail with
+// HTTP status 429 or 5xx.
 func New(uri string, hc *http.Client, opts jsonclient.Options) (*LogClient, error) {
 	logClient, err := jsonclient.New(uri, hc, opts)
 	if err != nil {
 		return nil, err
 	}
-	return &LogClient{*logClient}, err
+	return &LogClient{JSONClient: *logClient}, err
 }
 
 // RspError represents a server error including HTTP information.
@@ -103,7 +113,11 @@ func (c *LogClient) addChainWithRetry(ctx context.Context, ctype ct.LogEntryType
 		Extensions: ct.CTExtensions(exts),
 		Signature:  ds,
 	}
-	if err := c.VerifySCTSignature(*sct, ctype, chain); err != nil {
+	if c.SCTChecks != nil {
+		if err := c.checkSCT(sct, ctype, chain); err != nil {
+			return nil, err
+		}
+	} else if err := c.VerifySCTSignature(*sct, ctype, chain); err != nil {
 		return nil, RspError{Err: err, StatusCode: httpRsp.StatusCode, Body: body}
 	}
 	return sct, nil
@@ -137,6 +151,13 @@ func (c *LogClient) GetSTH(ctx context.Context) (*ct.SignedTreeHead, error) {
 	if err := c.VerifySTHSignature(*sth); err != nil {
 		return nil, RspError{Err: err, StatusCode: httpRsp.StatusCode, Body: body}
 	}
+	if c.Verifier != nil {
+		for size := c.verifiedSize.Load(); size < sth.TreeSize; size = c.verifiedSize.Load() {
+			if c.verifiedSize.CompareAndSwap(size, sth.TreeSize) {
+				break
+			}
+		}
+	}
 	return sth, nil
 }
 
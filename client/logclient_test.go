@@ -20,6 +20,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
@@ -183,6 +184,66 @@ func TestGetEntries(t *testing.T) {
 	}
 }
 
+func TestEntries(t *testing.T) {
+	var requests []string
+	ts := serveHandlerAt(t, "/ct/v1/get-entries", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Query().Get("start")+"-"+r.URL.Query().Get("end"))
+		if r.URL.Query().Get("start") == "9" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		// Always return two entries, as a log which truncates responses.
+		fmt.Fprintf(w, `{"entries":[{"leaf_input": "%s","extra_data": "%s"},{"leaf_input": "%s","extra_data": "%s"}]}`,
+			PrecertEntryB64,
+			PrecertEntryExtraDataB64,
+			CertEntryB64,
+			CertEntryExtraDataB64)
+	})
+	defer ts.Close()
+	lc, err := client.New(ts.URL, &http.Client{}, jsonclient.Options{})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	var indices []int64
+	lc.Entries(ctx, 0, 4)(func(entry ct.LogEntry, err error) bool {
+		if err != nil {
+			t.Fatalf("Entries(0, 4) yielded error %v", err)
+		}
+		indices = append(indices, entry.Index)
+		return true
+	})
+	if got, want := fmt.Sprint(indices), "[0 1 2 3 4]"; got != want {
+		t.Errorf("Entries(0, 4) yielded indices %s, want %s", got, want)
+	}
+	if got, want := strings.Join(requests, ","), "0-4,2-4,4-4"; got != want {
+		t.Errorf("Entries(0, 4) made requests %s, want %s", got, want)
+	}
+
+	// Iteration stops when the caller is done.
+	requests, indices = nil, nil
+	lc.Entries(ctx, 5, 100)(func(entry ct.LogEntry, err error) bool {
+		indices = append(indices, entry.Index)
+		return len(indices) < 3
+	})
+	if got, want := strings.Join(requests, ","), "5-100,7-100"; got != want || len(indices) != 3 {
+		t.Errorf("Entries(5, 100) made requests %s for %d entries, want %s for 3", got, len(indices), want)
+	}
+
+	// Iteration stops after an error.
+	var errs []error
+	lc.Entries(ctx, 7, 20)(func(entry ct.LogEntry, err error) bool {
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return true
+	})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "503") {
+		t.Errorf("Entries(7, 20) yielded errors %v, want one 503 error", errs)
+	}
+}
+
 func TestGetEntriesErrors(t *testing.T) {
 	ctx := context.Background()
 	var tests = []struct {
@@ -355,6 +416,42 @@ func TestGetSTHErrors(t *testing.T) {
 	}
 }
 
+func TestGetSTHRetries(t *testing.T) {
+	failures := 2
+	ts := serveHandlerAt(t, "/ct/v1/get-sth", func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"tree_size": %d, "timestamp": %d, "sha256_root_hash": "%s", "tree_head_signature": "%s"}`,
+			ValidSTHResponseTreeSize,
+			int64(ValidSTHResponseTimestamp),
+			ValidSTHResponseSHA256RootHash,
+			ValidSTHResponseTreeHeadSignature)
+	})
+	defer ts.Close()
+	retries := 0
+	lc, err := client.New(ts.URL, &http.Client{}, jsonclient.Options{Retry: &jsonclient.RetryOptions{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		OnRetry:        func(jsonclient.RetryInfo) { retries++ },
+	}})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	sth, err := lc.GetSTH(context.Background())
+	if err != nil {
+		t.Fatalf("GetSTH()=nil, %v; want STH", err)
+	}
+	if sth.TreeSize != ValidSTHResponseTreeSize {
+		t.Errorf("GetSTH().TreeSize=%d; want %d", sth.TreeSize, ValidSTHResponseTreeSize)
+	}
+	if retries != 2 {
+		t.Errorf("OnRetry called %d times; want 2", retries)
+	}
+}
+
 func TestAddChainRetries(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping retry test in short mode")
@@ -531,6 +628,75 @@ func TestAddPreChain(t *testing.T) {
 	}
 }
 
+func TestAddChainSCTChecks(t *testing.T) {
+	// Serve the test SCT with the log ID of the test log key.
+	var sct ct.SignedCertificateTimestamp
+	if _, err := tls.Unmarshal(testdata.TestCertProof, &sct); err != nil {
+		t.Fatalf("Failed to unmarshal test SCT: %v", err)
+	}
+	_, keyHash, _, err := ct.PublicKeyFromPEM([]byte(testdata.LogPublicKeyPEM))
+	if err != nil {
+		t.Fatalf("Failed to parse log public key: %v", err)
+	}
+	sig, err := tls.Marshal(sct.Signature)
+	if err != nil {
+		t.Fatalf("Failed to marshal SCT signature: %v", err)
+	}
+	sctJSON, err := json.Marshal(ct.AddChainResponse{
+		SCTVersion: sct.SCTVersion,
+		ID:         keyHash[:],
+		Timestamp:  sct.Timestamp,
+		Signature:  sig,
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal SCT: %v", err)
+	}
+	hs := serveRspAt(t, "/ct/v1/add-chain", string(sctJSON))
+	defer hs.Close()
+	cert, err := x509util.CertificateFromPEM([]byte(testdata.TestCertPEM))
+	if x509.IsFatal(err) {
+		t.Fatalf("Failed to parse certificate from PEM: %v", err)
+	}
+	chain := []ct.ASN1Cert{{Data: cert.Raw}}
+
+	for _, test := range []struct {
+		desc    string
+		pubKey  string
+		checks  client.SCTCheckOptions
+		wantErr error
+	}{
+		{desc: "ok", pubKey: testdata.LogPublicKeyPEM},
+		{desc: "no-key", wantErr: client.ErrSCTNoPublicKey},
+		{desc: "wrong-key", pubKey: testdata.RsaPublicKeyPEM, wantErr: client.ErrSCTLogID},
+		{desc: "too-old", pubKey: testdata.LogPublicKeyPEM, checks: client.SCTCheckOptions{MaxAge: 24 * time.Hour}, wantErr: client.ErrSCTTimestamp},
+		{
+			desc:    "future",
+			pubKey:  testdata.LogPublicKeyPEM,
+			checks:  client.SCTCheckOptions{Now: func() time.Time { return time.Unix(0, 0) }},
+			wantErr: client.ErrSCTTimestamp,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			lc, err := client.New(hs.URL, &http.Client{}, jsonclient.Options{PublicKey: test.pubKey})
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+			lc.SCTChecks = &test.checks
+			sct, err := lc.AddChain(context.Background(), chain)
+			if test.wantErr == nil {
+				if err != nil || sct == nil {
+					t.Errorf("AddChain()=%v,%v; want sct,nil", sct, err)
+				}
+				return
+			}
+			var sctErr *client.SCTError
+			if !errors.As(err, &sctErr) || !errors.Is(err, test.wantErr) {
+				t.Errorf("AddChain()=%v,%v; want SCTError wrapping %v", sct, err, test.wantErr)
+			}
+		})
+	}
+}
+
 func TestGetSTHConsistency(t *testing.T) {
 	hs := serveRspAt(t, "/ct/v1/get-sth-consistency", GetSTHConsistencyResp)
 	defer hs.Close()
@@ -0,0 +1,182 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/ctpolicy"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+)
+
+// PolicySubmitter submits chains to the logs of a log list concurrently,
+// until the SCTs it has obtained satisfy a CT policy.
+type PolicySubmitter struct {
+	ll      *loglist3.LogList
+	policy  ctpolicy.CTPolicy
+	logs    map[string]*loglist3.Log
+	clients map[string]*LogClient
+}
+
+// NewPolicySubmitter creates a PolicySubmitter for the logs in ll, which
+// obtains SCTs as required by policy. The client for each log uses hc and
+// opts, with the public key from the log list.
+func NewPolicySubmitter(ll *loglist3.LogList, policy ctpolicy.CTPolicy, hc *http.Client, opts jsonclient.Options) (*PolicySubmitter, error) {
+	s := &PolicySubmitter{
+		ll:      ll,
+		policy:  policy,
+		logs:    make(map[string]*loglist3.Log),
+		clients: make(map[string]*LogClient),
+	}
+	for _, op := range ll.Operators {
+		for _, log := range op.Logs {
+			logOpts := opts
+			logOpts.PublicKeyDER = log.Key
+			lc, err := New(log.URL, hc, logOpts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create client for %s: %v", log.URL, err)
+			}
+			s.logs[log.URL] = log
+			s.clients[log.URL] = lc
+		}
+	}
+	return s, nil
+}
+
+// SCTBundle holds the outcome of submitting a chain with a PolicySubmitter.
+type SCTBundle struct {
+	// SCTs holds the SCTs which satisfy the policy, in the order in which
+	// they were received.
+	SCTs []*ct.SignedCertificateTimestamp
+	// Submissions holds the result of every submission made, including those
+	// which failed or were cancelled once the policy was satisfied.
+	Submissions []LogSubmission
+}
+
+// Submit submits chain, whose first entry is the certificate or
+// precertificate, to the logs which accept it until the policy is satisfied.
+// For each group of logs defined by the policy, as many logs are submitted to
+// at once as the group requires, and another is tried whenever one fails.
+// Outstanding submissions are cancelled once the policy is satisfied.
+// Returns an error, along with the bundle of submissions made, if the policy
+// cannot be satisfied.
+func (s *PolicySubmitter) Submit(ctx context.Context, chain []ct.ASN1Cert) (*SCTBundle, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("empty chain")
+	}
+	cert, err := x509.ParseCertificate(chain[0].Data)
+	if x509.IsFatal(err) {
+		return nil, fmt.Errorf("failed to parse certificate: %v", err)
+	}
+	compatible := acceptingLogs(s.ll, cert)
+	groups, err := s.policy.LogsByGroup(cert, &compatible)
+	if err != nil {
+		return nil, fmt.Errorf("%s policy cannot be satisfied: %v", s.policy.Name(), err)
+	}
+	precert := cert.IsPrecertificate()
+
+	// Visit groups in a fixed order, and the logs of each in the weighted
+	// random order of a submission session.
+	var names []string
+	sessions := make(map[string][]string)
+	for name, group := range groups {
+		for url := range group.LogURLs {
+			if s.clients[url] == nil {
+				return nil, fmt.Errorf("%s policy chose unknown log %s", s.policy.Name(), url)
+			}
+		}
+		names = append(names, name)
+		sessions[name] = group.GetSubmissionSession()
+	}
+	sort.Strings(names)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan LogSubmission)
+	tried := make(map[string]bool)
+	pending := make(map[string]bool)
+	succeeded := make(map[string]bool)
+	submit := func(url string) {
+		tried[url] = true
+		pending[url] = true
+		go func() {
+			res := LogSubmission{Log: s.logs[url]}
+			if precert {
+				res.SCT, res.Err = s.clients[url].AddPreChain(ctx, chain)
+			} else {
+				res.SCT, res.Err = s.clients[url].AddChain(ctx, chain)
+			}
+			results <- res
+		}()
+	}
+
+	bundle := &SCTBundle{}
+	for {
+		satisfied := true
+		for _, name := range names {
+			group := groups[name]
+			if group.Check(succeeded) == nil {
+				continue
+			}
+			satisfied = false
+			// Submit to the logs the group wants if all the submissions in
+			// flight succeed.
+			expected := make(map[string]bool)
+			for url := range succeeded {
+				expected[url] = true
+			}
+			for url := range pending {
+				expected[url] = true
+			}
+			for _, url := range sessions[name] {
+				if group.Check(expected) == nil {
+					break
+				}
+				if !tried[url] && group.Wants(expected, url) {
+					submit(url)
+					expected[url] = true
+				}
+			}
+		}
+		if satisfied || len(pending) == 0 {
+			break
+		}
+		res := <-results
+		delete(pending, res.Log.URL)
+		bundle.Submissions = append(bundle.Submissions, res)
+		if res.Err == nil {
+			succeeded[res.Log.URL] = true
+			bundle.SCTs = append(bundle.SCTs, res.SCT)
+		}
+	}
+
+	cancel()
+	for range pending {
+		bundle.Submissions = append(bundle.Submissions, <-results)
+	}
+	for _, name := range names {
+		if err := groups[name].Check(succeeded); err != nil {
+			return bundle, fmt.Errorf("%s policy not satisfied: %v", s.policy.Name(), err)
+		}
+	}
+	return bundle, nil
+}
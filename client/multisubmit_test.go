@@ -0,0 +1,115 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/ctpolicy"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+)
+
+// testPolicy requires minInclusions SCTs from any of the approved logs.
+type testPolicy struct {
+	minInclusions int
+}
+
+func (p testPolicy) LogsByGroup(cert *x509.Certificate, approved *loglist3.LogList) (ctpolicy.LogPolicyData, error) {
+	group, err := ctpolicy.BaseGroupFor(approved, p.minInclusions)
+	if err != nil {
+		return nil, err
+	}
+	return ctpolicy.LogPolicyData{group.Name: group}, nil
+}
+
+func (p testPolicy) Name() string {
+	return "Test"
+}
+
+func TestPolicySubmitter(t *testing.T) {
+	cert, err := x509util.CertificateFromPEM([]byte(testdata.TestCertPEM))
+	if x509.IsFatal(err) {
+		t.Fatalf("Failed to parse certificate from PEM: %v", err)
+	}
+	block, _ := pem.Decode([]byte(testdata.LogPublicKeyPEM))
+	if block == nil {
+		t.Fatal("Failed to decode log public key")
+	}
+	chain := []ct.ASN1Cert{{Data: cert.Raw}}
+
+	// Two logs issue SCTs, and two reject every submission.
+	usable := &loglist3.LogStates{Usable: &loglist3.LogState{}}
+	op := &loglist3.Operator{Name: "op"}
+	for i := 0; i < 4; i++ {
+		var ts *httptest.Server
+		if i%2 == 0 {
+			ts = serveSCTAt(t, "/ct/v1/add-chain", testdata.TestCertProof)
+		} else {
+			ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "rejected", http.StatusBadRequest)
+			}))
+		}
+		defer ts.Close()
+		op.Logs = append(op.Logs, &loglist3.Log{Description: fmt.Sprintf("log-%d", i), Key: block.Bytes, URL: ts.URL, State: usable})
+	}
+	ll := &loglist3.LogList{Operators: []*loglist3.Operator{op}}
+
+	for _, test := range []struct {
+		minInclusions int
+		wantErr       bool
+	}{
+		{minInclusions: 1},
+		{minInclusions: 2},
+		{minInclusions: 3, wantErr: true},
+		{minInclusions: 5, wantErr: true},
+	} {
+		t.Run(fmt.Sprint(test.minInclusions), func(t *testing.T) {
+			s, err := client.NewPolicySubmitter(ll, testPolicy{minInclusions: test.minInclusions}, &http.Client{}, jsonclient.Options{})
+			if err != nil {
+				t.Fatalf("NewPolicySubmitter()=%v", err)
+			}
+			bundle, err := s.Submit(context.Background(), chain)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Submit()=%v, want error %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if got := len(bundle.SCTs); got != test.minInclusions {
+				t.Errorf("Submit() returned %d SCTs, want %d", got, test.minInclusions)
+			}
+			failed := 0
+			for _, sub := range bundle.Submissions {
+				if sub.Err != nil {
+					failed++
+				}
+			}
+			if got, want := len(bundle.Submissions)-failed, len(bundle.SCTs); got < want {
+				t.Errorf("Submit() recorded %d successful submissions, want at least %d", got, want)
+			}
+		})
+	}
+}
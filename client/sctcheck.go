@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+)
+
+// defaultMaxClockSkew is how far in the future an SCT timestamp may be by
+// default.
+const defaultMaxClockSkew = time.Minute
+
+// Errors wrapped by an SCTError, identifying the check which failed.
+var (
+	ErrSCTNoPublicKey = errors.New("no log public key to check SCT with")
+	ErrSCTVersion     = errors.New("unsupported SCT version")
+	ErrSCTLogID       = errors.New("SCT log ID does not match log public key")
+	ErrSCTTimestamp   = errors.New("SCT timestamp out of range")
+	ErrSCTSignature   = errors.New("invalid SCT signature")
+)
+
+// SCTCheckOptions configures the checks made by AddChain and AddPreChain on
+// the SCTs returned by a log. The LogClient must have been created with the
+// log's public key. SCTs are checked to be version 1, to have the log ID of
+// the log's public key, to have a valid signature, and to have a sane
+// timestamp.
+type SCTCheckOptions struct {
+	// MaxClockSkew is how far in the future an SCT timestamp may be.
+	// Defaults to one minute.
+	MaxClockSkew time.Duration
+	// MaxAge, if non-zero, is how far in the past an SCT timestamp may be.
+	MaxAge time.Duration
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// SCTError is returned by AddChain and AddPreChain when an SCT fails the
+// checks of SCTCheckOptions. It wraps one of the ErrSCT* errors.
+type SCTError struct {
+	SCT *ct.SignedCertificateTimestamp
+	Err error
+}
+
+func (e *SCTError) Error() string {
+	return fmt.Sprintf("bad SCT from log: %v", e.Err)
+}
+
+func (e *SCTError) Unwrap() error {
+	return e.Err
+}
+
+// checkSCT makes the checks of c.SCTChecks on sct, which was returned for a
+// chain of the given entry type.
+func (c *LogClient) checkSCT(sct *ct.SignedCertificateTimestamp, ctype ct.LogEntryType, chain []ct.ASN1Cert) error {
+	opts := c.SCTChecks
+	sctErr := func(err error) error { return &SCTError{SCT: sct, Err: err} }
+	if c.Verifier == nil {
+		return sctErr(ErrSCTNoPublicKey)
+	}
+	if sct.SCTVersion != ct.V1 {
+		return sctErr(fmt.Errorf("%w: %v", ErrSCTVersion, sct.SCTVersion))
+	}
+	der, err := x509.MarshalPKIXPublicKey(c.Verifier.PubKey)
+	if err != nil {
+		return sctErr(fmt.Errorf("%w: %v", ErrSCTNoPublicKey, err))
+	}
+	if sct.LogID.KeyID != sha256.Sum256(der) {
+		return sctErr(ErrSCTLogID)
+	}
+
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+	skew := opts.MaxClockSkew
+	if skew <= 0 {
+		skew = defaultMaxClockSkew
+	}
+	ts := ct.TimestampToTime(sct.Timestamp)
+	if t := now(); ts.After(t.Add(skew)) {
+		return sctErr(fmt.Errorf("%w: %v is in the future", ErrSCTTimestamp, ts))
+	} else if opts.MaxAge > 0 && ts.Before(t.Add(-opts.MaxAge)) {
+		return sctErr(fmt.Errorf("%w: %v is older than %v", ErrSCTTimestamp, ts, opts.MaxAge))
+	}
+
+	if err := c.VerifySCTSignature(*sct, ctype, chain); err != nil {
+		return sctErr(fmt.Errorf("%w: %v", ErrSCTSignature, err))
+	}
+	return nil
+}
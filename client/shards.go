@@ -0,0 +1,100 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+)
+
+// TemporalShards returns the logs in ll which will accept cert: those which
+// are qualified or usable, and which either have no temporal interval or
+// have one which covers the NotAfter date of cert.
+func TemporalShards(ll *loglist3.LogList, cert *x509.Certificate) []*loglist3.Log {
+	compatible := acceptingLogs(ll, cert)
+	var logs []*loglist3.Log
+	for _, op := range compatible.Operators {
+		logs = append(logs, op.Logs...)
+	}
+	return logs
+}
+
+// acceptingLogs returns the logs in ll which will accept cert, as described
+// for TemporalShards.
+func acceptingLogs(ll *loglist3.LogList, cert *x509.Certificate) loglist3.LogList {
+	active := ll.SelectByStatus([]loglist3.LogStatus{loglist3.QualifiedLogStatus, loglist3.UsableLogStatus})
+	return active.TemporallyCompatible(cert)
+}
+
+// LogSubmission is the result of submitting a chain to a single log.
+type LogSubmission struct {
+	Log *loglist3.Log
+	SCT *ct.SignedCertificateTimestamp
+	Err error
+}
+
+// SubmitToTemporalShards submits chain to each of the TemporalShards of ll
+// for its first certificate, in parallel. Precertificates are submitted with
+// add-pre-chain, and other certificates with add-chain. The client for each
+// log uses hc and opts, with the public key from the log list. Returns an
+// error if the certificate cannot be parsed or no log will accept it;
+// otherwise there is one LogSubmission per log, each of which may have
+// failed.
+func SubmitToTemporalShards(ctx context.Context, ll *loglist3.LogList, chain []ct.ASN1Cert, hc *http.Client, opts jsonclient.Options) ([]LogSubmission, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("empty chain")
+	}
+	cert, err := x509.ParseCertificate(chain[0].Data)
+	if x509.IsFatal(err) {
+		return nil, fmt.Errorf("failed to parse certificate: %v", err)
+	}
+	logs := TemporalShards(ll, cert)
+	if len(logs) == 0 {
+		return nil, fmt.Errorf("no log accepts certificates with NotAfter %v", cert.NotAfter)
+	}
+	precert := cert.IsPrecertificate()
+
+	results := make([]LogSubmission, len(logs))
+	var wg sync.WaitGroup
+	for i, log := range logs {
+		results[i].Log = log
+		logOpts := opts
+		logOpts.PublicKeyDER = log.Key
+		lc, err := New(log.URL, hc, logOpts)
+		if err != nil {
+			results[i].Err = fmt.Errorf("failed to create client for %s: %v", log.URL, err)
+			continue
+		}
+		wg.Add(1)
+		go func(res *LogSubmission) {
+			defer wg.Done()
+			if precert {
+				res.SCT, res.Err = lc.AddPreChain(ctx, chain)
+			} else {
+				res.SCT, res.Err = lc.AddChain(ctx, chain)
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+	return results, nil
+}
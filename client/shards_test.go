@@ -0,0 +1,89 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+)
+
+func TestSubmitToTemporalShards(t *testing.T) {
+	cert, err := x509util.CertificateFromPEM([]byte(testdata.TestCertPEM))
+	if x509.IsFatal(err) {
+		t.Fatalf("Failed to parse certificate from PEM: %v", err)
+	}
+	block, _ := pem.Decode([]byte(testdata.LogPublicKeyPEM))
+	if block == nil {
+		t.Fatal("Failed to decode log public key")
+	}
+	hs := serveSCTAt(t, "/ct/v1/add-chain", testdata.TestCertProof)
+	defer hs.Close()
+
+	// Only the shards covering the certificate's NotAfter should be used.
+	usable := &loglist3.LogStates{Usable: &loglist3.LogState{}}
+	shard := func(desc string, start, end time.Time) *loglist3.Log {
+		return &loglist3.Log{
+			Description:      desc,
+			Key:              block.Bytes,
+			URL:              hs.URL,
+			State:            usable,
+			TemporalInterval: &loglist3.TemporalInterval{StartInclusive: start, EndExclusive: end},
+		}
+	}
+	notAfter := cert.NotAfter
+	ll := &loglist3.LogList{Operators: []*loglist3.Operator{
+		{Name: "a", Logs: []*loglist3.Log{
+			shard("a-before", notAfter.AddDate(-1, 0, 0), notAfter),
+			shard("a-covering", notAfter, notAfter.AddDate(1, 0, 0)),
+			shard("a-after", notAfter.AddDate(1, 0, 0), notAfter.AddDate(2, 0, 0)),
+		}},
+		{Name: "b", Logs: []*loglist3.Log{
+			{Description: "b-unsharded", Key: block.Bytes, URL: hs.URL, State: usable},
+			{Description: "b-retired", Key: block.Bytes, URL: hs.URL, State: &loglist3.LogStates{Retired: &loglist3.LogState{}}},
+		}},
+	}}
+
+	results, err := client.SubmitToTemporalShards(context.Background(), ll, []ct.ASN1Cert{{Data: cert.Raw}}, &http.Client{}, jsonclient.Options{})
+	if err != nil {
+		t.Fatalf("SubmitToTemporalShards()=%v", err)
+	}
+	var got []string
+	for _, res := range results {
+		got = append(got, res.Log.Description)
+		if res.Err != nil || res.SCT == nil {
+			t.Errorf("submission to %s returned (%v, %v), want SCT", res.Log.Description, res.SCT, res.Err)
+		}
+	}
+	if want := []string{"a-covering", "b-unsharded"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("SubmitToTemporalShards() used %v, want %v", got, want)
+	}
+
+	ll.Operators = ll.Operators[:1]
+	ll.Operators[0].Logs = ll.Operators[0].Logs[:1]
+	if _, err := client.SubmitToTemporalShards(context.Background(), ll, []ct.ASN1Cert{{Data: cert.Raw}}, &http.Client{}, jsonclient.Options{}); err == nil {
+		t.Error("SubmitToTemporalShards() with no covering shard succeeded, want error")
+	}
+}
@@ -0,0 +1,428 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/transparency-dev/merkle/compact"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+const (
+	// tileHeight is the number of tree levels covered by a static-ct-api tile.
+	tileHeight = 8
+	// tileWidth is the number of entries or hashes in a full tile.
+	tileWidth = 1 << tileHeight
+	// rfc6962NoteSignatureType identifies a checkpoint signature which is an
+	// RFC 6962 tree head signature.
+	rfc6962NoteSignatureType = 0x05
+)
+
+// LogReader is the read API shared by LogClient and StaticLogClient.
+type LogReader interface {
+	CheckLogClient
+	GetRawEntries(ctx context.Context, start, end int64) (*ct.GetEntriesResponse, error)
+	GetEntries(ctx context.Context, start, end int64) ([]ct.LogEntry, error)
+	Entries(ctx context.Context, start, end int64) func(yield func(ct.LogEntry, error) bool)
+	GetEntryAndProof(ctx context.Context, index, treeSize uint64) (*ct.GetEntryAndProofResponse, error)
+}
+
+var (
+	_ LogReader = (*LogClient)(nil)
+	_ LogReader = (*StaticLogClient)(nil)
+)
+
+// StaticLogClient is a read client for logs which implement the
+// static-ct-api (https://c2sp.org/static-ct-api), serving a checkpoint, Merkle
+// tree tiles and issuer certificates as static files instead of the RFC 6962
+// JSON API. Proofs and entries are assembled from the tiles.
+type StaticLogClient struct {
+	jsonclient.JSONClient
+
+	mu sync.Mutex
+	// treeSize is the size of the latest checkpoint seen.
+	treeSize uint64
+	// issuers caches the issuer certificates by their SHA-256 fingerprint.
+	issuers map[[sha256.Size]byte][]byte
+}
+
+// NewStaticLogClient constructs a new StaticLogClient instance.
+// |uri| is the monitoring prefix of the log, under which the checkpoint and
+// tiles are served.
+// |hc| and |opts| are as for New.
+func NewStaticLogClient(uri string, hc *http.Client, opts jsonclient.Options) (*StaticLogClient, error) {
+	jc, err := jsonclient.New(uri, hc, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &StaticLogClient{JSONClient: *jc, issuers: make(map[[sha256.Size]byte][]byte)}, nil
+}
+
+// GetSTH retrieves the log's checkpoint, and returns it as an STH.
+func (c *StaticLogClient) GetSTH(ctx context.Context) (*ct.SignedTreeHead, error) {
+	httpRsp, body, err := c.GetRaw(ctx, "/checkpoint")
+	if err != nil {
+		return nil, err
+	}
+	sth, err := c.parseCheckpoint(body)
+	if err != nil {
+		return nil, RspError{Err: err, StatusCode: httpRsp.StatusCode, Body: body}
+	}
+	if c.Verifier != nil {
+		if err := c.Verifier.VerifySTHSignature(*sth); err != nil {
+			return nil, RspError{Err: err, StatusCode: httpRsp.StatusCode, Body: body}
+		}
+	}
+	c.mu.Lock()
+	if sth.TreeSize > c.treeSize {
+		c.treeSize = sth.TreeSize
+	}
+	c.mu.Unlock()
+	return sth, nil
+}
+
+// parseCheckpoint parses a checkpoint, using its RFC 6962 note signature
+// from the log for the STH's timestamp and signature.
+func (c *StaticLogClient) parseCheckpoint(note []byte) (*ct.SignedTreeHead, error) {
+	text, sigs, ok := strings.Cut(string(note), "\n\n")
+	if !ok {
+		return nil, errors.New("checkpoint has no signatures")
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) < 3 {
+		return nil, fmt.Errorf("checkpoint has %d lines, want at least 3", len(lines))
+	}
+	origin := lines[0]
+	sth := &ct.SignedTreeHead{Version: ct.V1}
+	var err error
+	if sth.TreeSize, err = strconv.ParseUint(lines[1], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint tree size: %v", err)
+	}
+	hash, err := base64.StdEncoding.DecodeString(lines[2])
+	if err != nil || len(hash) != sha256.Size {
+		return nil, fmt.Errorf("invalid checkpoint root hash %q", lines[2])
+	}
+	copy(sth.SHA256RootHash[:], hash)
+
+	var keyID []byte
+	if c.Verifier != nil {
+		der, err := x509.MarshalPKIXPublicKey(c.Verifier.PubKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal public key: %v", err)
+		}
+		h := sha256.New()
+		h.Write([]byte(origin))
+		h.Write([]byte{'\n', rfc6962NoteSignatureType})
+		h.Write(der)
+		keyID = h.Sum(nil)[:4]
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(sigs, "\n"), "\n") {
+		name, sig, ok := strings.Cut(strings.TrimPrefix(line, "— "), " ")
+		if !ok || name != origin {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(sig)
+		if err != nil || len(raw) < 12 || keyID != nil && !bytes.Equal(raw[:4], keyID) {
+			continue
+		}
+		if rest, err := tls.Unmarshal(raw[12:], &sth.TreeHeadSignature); err != nil || len(rest) > 0 {
+			continue
+		}
+		sth.Timestamp = binary.BigEndian.Uint64(raw[4:12])
+		return sth, nil
+	}
+	return nil, fmt.Errorf("checkpoint has no RFC 6962 signature for %q", origin)
+}
+
+// knownTreeSize returns the size of the latest checkpoint, fetching a new one
+// if the last seen is smaller than size.
+func (c *StaticLogClient) knownTreeSize(ctx context.Context, size uint64) (uint64, error) {
+	c.mu.Lock()
+	treeSize := c.treeSize
+	c.mu.Unlock()
+	if treeSize >= size {
+		return treeSize, nil
+	}
+	sth, err := c.GetSTH(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if sth.TreeSize < size {
+		return 0, fmt.Errorf("tree size %d beyond checkpoint size %d", size, sth.TreeSize)
+	}
+	return sth.TreeSize, nil
+}
+
+// tilePath returns the path of the nth tile at the given level, which holds
+// count entries or hashes in all, so the last tile may be partial.
+func tilePath(level string, n, count uint64) string {
+	index := fmt.Sprintf("%03d", n%1000)
+	for rest := n / 1000; rest > 0; rest /= 1000 {
+		index = fmt.Sprintf("x%03d/%s", rest%1000, index)
+	}
+	path := "/tile/" + level + "/" + index
+	if width := count - n*tileWidth; width < tileWidth {
+		path += ".p/" + strconv.FormatUint(width, 10)
+	}
+	return path
+}
+
+// GetRawEntries retrieves the entries in the range [start, end] from the
+// log's data tiles, in the form of a get-entries response.
+func (c *StaticLogClient) GetRawEntries(ctx context.Context, start, end int64) (*ct.GetEntriesResponse, error) {
+	if start < 0 {
+		return nil, errors.New("start should be >= 0")
+	}
+	if end < start {
+		return nil, errors.New("start should be <= end")
+	}
+	size, err := c.knownTreeSize(ctx, uint64(end)+1)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ct.GetEntriesResponse
+	for n := start / tileWidth; n <= end/tileWidth; n++ {
+		httpRsp, body, err := c.GetRaw(jsonclient.WithImmutableResponse(ctx), tilePath("data", uint64(n), size))
+		if err != nil {
+			return nil, err
+		}
+		entries, err := c.parseDataTile(ctx, body)
+		if err != nil {
+			return nil, RspError{Err: err, StatusCode: httpRsp.StatusCode, Body: body}
+		}
+		first := n * tileWidth
+		for i, entry := range entries {
+			if index := first + int64(i); index >= start && index <= end {
+				resp.Entries = append(resp.Entries, entry)
+			}
+		}
+	}
+	if got, want := int64(len(resp.Entries)), end-start+1; got != want {
+		return nil, fmt.Errorf("got %d entries from data tiles, want %d", got, want)
+	}
+	return &resp, nil
+}
+
+// GetEntries retrieves the entries in the range [start, end] from the log's
+// data tiles, parsed as for LogClient.GetEntries.
+func (c *StaticLogClient) GetEntries(ctx context.Context, start, end int64) ([]ct.LogEntry, error) {
+	resp, err := c.GetRawEntries(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return logEntriesFromResponse(start, resp)
+}
+
+// Entries returns an iterator over the entries in the range [start, end] of
+// the log, as for LogClient.Entries.
+func (c *StaticLogClient) Entries(ctx context.Context, start, end int64) func(yield func(ct.LogEntry, error) bool) {
+	return entriesIterator(ctx, c.GetRawEntries, start, end)
+}
+
+// tileFingerprints holds the SHA-256 fingerprints of the chain of a tile leaf.
+type tileFingerprints struct {
+	Data []byte `tls:"minlen:0,maxlen:65535"`
+}
+
+// parseDataTile parses the TileLeaf structures of a data tile, and returns
+// them as get-entries leaves, with their issuer chains fetched.
+func (c *StaticLogClient) parseDataTile(ctx context.Context, tile []byte) ([]ct.LeafEntry, error) {
+	var entries []ct.LeafEntry
+	for rest := tile; len(rest) > 0; {
+		var entry ct.TimestampedEntry
+		var err error
+		if rest, err = tls.Unmarshal(rest, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse entry %d: %v", len(entries), err)
+		}
+		var preCert ct.ASN1Cert
+		if entry.EntryType == ct.PrecertLogEntryType {
+			if rest, err = tls.Unmarshal(rest, &preCert); err != nil {
+				return nil, fmt.Errorf("failed to parse pre-certificate %d: %v", len(entries), err)
+			}
+		}
+		var fps tileFingerprints
+		if rest, err = tls.Unmarshal(rest, &fps); err != nil {
+			return nil, fmt.Errorf("failed to parse chain %d: %v", len(entries), err)
+		}
+		if len(fps.Data)%sha256.Size != 0 {
+			return nil, fmt.Errorf("chain %d has %d bytes of fingerprints", len(entries), len(fps.Data))
+		}
+		var chain []ct.ASN1Cert
+		for i := 0; i < len(fps.Data); i += sha256.Size {
+			var fp [sha256.Size]byte
+			copy(fp[:], fps.Data[i:])
+			issuer, err := c.getIssuer(ctx, fp)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, ct.ASN1Cert{Data: issuer})
+		}
+
+		leafInput, err := tls.Marshal(ct.MerkleTreeLeaf{
+			Version:          ct.V1,
+			LeafType:         ct.TimestampedEntryLeafType,
+			TimestampedEntry: &entry,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal leaf %d: %v", len(entries), err)
+		}
+		var extraData []byte
+		if entry.EntryType == ct.PrecertLogEntryType {
+			extraData, err = tls.Marshal(ct.PrecertChainEntry{PreCertificate: preCert, CertificateChain: chain})
+		} else {
+			extraData, err = tls.Marshal(ct.CertificateChain{Entries: chain})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal chain %d: %v", len(entries), err)
+		}
+		entries = append(entries, ct.LeafEntry{LeafInput: leafInput, ExtraData: extraData})
+	}
+	return entries, nil
+}
+
+// getIssuer returns the issuer certificate with the given fingerprint.
+func (c *StaticLogClient) getIssuer(ctx context.Context, fp [sha256.Size]byte) ([]byte, error) {
+	c.mu.Lock()
+	issuer, ok := c.issuers[fp]
+	c.mu.Unlock()
+	if ok {
+		return issuer, nil
+	}
+	httpRsp, body, err := c.GetRaw(jsonclient.WithImmutableResponse(ctx), "/issuer/"+hex.EncodeToString(fp[:]))
+	if err != nil {
+		return nil, err
+	}
+	if sha256.Sum256(body) != fp {
+		return nil, RspError{Err: fmt.Errorf("issuer %x has wrong fingerprint", fp), StatusCode: httpRsp.StatusCode, Body: body}
+	}
+	c.mu.Lock()
+	c.issuers[fp] = body
+	c.mu.Unlock()
+	return body, nil
+}
+
+// GetSTHConsistency builds the consistency proof between two tree sizes from
+// the log's hash tiles.
+func (c *StaticLogClient) GetSTHConsistency(ctx context.Context, first, second uint64) ([][]byte, error) {
+	nodes, err := proof.Consistency(first, second)
+	if err != nil {
+		return nil, err
+	}
+	return c.buildProof(ctx, nodes, second)
+}
+
+// GetProofByHash is not supported by static-ct-api logs, which have no index
+// of leaf hashes; use GetEntryAndProof with the leaf's index instead.
+func (c *StaticLogClient) GetProofByHash(ctx context.Context, hash []byte, treeSize uint64) (*ct.GetProofByHashResponse, error) {
+	return nil, errors.New("static-ct-api logs do not support proofs by hash")
+}
+
+// GetEntryAndProof returns a log entry and the audit path for it in the tree
+// of the given size, built from the log's tiles.
+func (c *StaticLogClient) GetEntryAndProof(ctx context.Context, index, treeSize uint64) (*ct.GetEntryAndProofResponse, error) {
+	nodes, err := proof.Inclusion(index, treeSize)
+	if err != nil {
+		return nil, err
+	}
+	path, err := c.buildProof(ctx, nodes, treeSize)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.GetRawEntries(ctx, int64(index), int64(index))
+	if err != nil {
+		return nil, err
+	}
+	return &ct.GetEntryAndProofResponse{
+		LeafInput: resp.Entries[0].LeafInput,
+		ExtraData: resp.Entries[0].ExtraData,
+		AuditPath: path,
+	}, nil
+}
+
+// buildProof fetches the hashes of the nodes of a proof in the tree of the
+// given size, and combines them into the proof.
+func (c *StaticLogClient) buildProof(ctx context.Context, nodes proof.Nodes, treeSize uint64) ([][]byte, error) {
+	size, err := c.knownTreeSize(ctx, treeSize)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := c.nodeHashes(ctx, nodes.IDs, size)
+	if err != nil {
+		return nil, err
+	}
+	return nodes.Rehash(hashes, rfc6962.DefaultHasher.HashChildren)
+}
+
+// nodeHashes returns the hashes of the given perfect subtrees of the tree of
+// the given size. A node between the levels held in tiles is hashed from its
+// descendants in the tile below it.
+func (c *StaticLogClient) nodeHashes(ctx context.Context, ids []compact.NodeID, size uint64) ([][]byte, error) {
+	tiles := make(map[string][]byte)
+	hashes := make([][]byte, 0, len(ids))
+	for _, id := range ids {
+		level, height := id.Level/tileHeight, id.Level%tileHeight
+		first, count := id.Index<<height, uint64(1)<<height
+		n := first / tileWidth
+		path := tilePath(strconv.FormatUint(uint64(level), 10), n, size>>(level*tileHeight))
+		tile, ok := tiles[path]
+		if !ok {
+			httpRsp, body, err := c.GetRaw(jsonclient.WithImmutableResponse(ctx), path)
+			if err != nil {
+				return nil, err
+			}
+			if len(body)%sha256.Size != 0 {
+				return nil, RspError{Err: fmt.Errorf("tile %s has %d bytes", path, len(body)), StatusCode: httpRsp.StatusCode, Body: body}
+			}
+			tile = body
+			tiles[path] = tile
+		}
+		offset := (first - n*tileWidth) * sha256.Size
+		if end := offset + count*sha256.Size; end > uint64(len(tile)) {
+			return nil, fmt.Errorf("tile %s has %d hashes, want at least %d", path, len(tile)/sha256.Size, end/sha256.Size)
+		}
+		level0 := make([][]byte, count)
+		for i := range level0 {
+			level0[i] = tile[offset+uint64(i)*sha256.Size : offset+uint64(i+1)*sha256.Size]
+		}
+		for row := level0; ; row = row[:len(row)/2] {
+			if len(row) == 1 {
+				hashes = append(hashes, row[0])
+				break
+			}
+			for i := 0; i < len(row)/2; i++ {
+				row[i] = rfc6962.DefaultHasher.HashChildren(row[2*i], row[2*i+1])
+			}
+		}
+	}
+	return hashes, nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/transparency-dev/merkle/compact"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+const staticLogOrigin = "example.com/log"
+
+// staticLog serves a static-ct-api log of fake entries.
+type staticLog struct {
+	files  map[string][]byte
+	leaves [][]byte // MerkleTreeLeaf of each entry
+	issuer []byte
+	der    []byte // public key
+}
+
+func newStaticLog(t *testing.T, size int) *staticLog {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey()=%v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey()=%v", err)
+	}
+	l := &staticLog{files: make(map[string][]byte), issuer: []byte("issuer"), der: der}
+	fp := sha256.Sum256(l.issuer)
+	l.files["/issuer/"+hex.EncodeToString(fp[:])] = l.issuer
+
+	var data []byte
+	var hashes [][]byte
+	for i := 0; i < size; i++ {
+		entry := ct.TimestampedEntry{Timestamp: uint64(i), EntryType: ct.X509LogEntryType, X509Entry: &ct.ASN1Cert{Data: []byte(fmt.Sprintf("cert-%d", i))}}
+		if i%5 == 0 {
+			entry = ct.TimestampedEntry{Timestamp: uint64(i), EntryType: ct.PrecertLogEntryType, PrecertEntry: &ct.PreCert{TBSCertificate: []byte(fmt.Sprintf("tbs-%d", i))}}
+		}
+		leaf, err := tls.Marshal(ct.MerkleTreeLeaf{Version: ct.V1, LeafType: ct.TimestampedEntryLeafType, TimestampedEntry: &entry})
+		if err != nil {
+			t.Fatalf("Marshal(leaf)=%v", err)
+		}
+		l.leaves = append(l.leaves, leaf)
+		hashes = append(hashes, rfc6962.DefaultHasher.HashLeaf(leaf))
+
+		tileLeaf, err := tls.Marshal(entry)
+		if err != nil {
+			t.Fatalf("Marshal(entry)=%v", err)
+		}
+		if entry.EntryType == ct.PrecertLogEntryType {
+			pre, err := tls.Marshal(ct.ASN1Cert{Data: []byte(fmt.Sprintf("precert-%d", i))})
+			if err != nil {
+				t.Fatalf("Marshal(precert)=%v", err)
+			}
+			tileLeaf = append(tileLeaf, pre...)
+		}
+		tileLeaf = append(tileLeaf, 0, sha256.Size)
+		tileLeaf = append(tileLeaf, fp[:]...)
+		data = append(data, tileLeaf...)
+		if (i+1)%256 == 0 || i+1 == size {
+			l.files[testTilePath("data", i/256, (i%256)+1)] = data
+			data = nil
+		}
+	}
+
+	// Hash tiles at level n hold the hashes of tree level 8n.
+	for level := 0; len(hashes) > 0; level++ {
+		for n := 0; n*256 < len(hashes); n++ {
+			end := (n + 1) * 256
+			if end > len(hashes) {
+				end = len(hashes)
+			}
+			l.files[testTilePath(fmt.Sprint(level), n, end-n*256)] = bytes.Join(hashes[n*256:end], nil)
+		}
+		for i := 0; i < 8; i++ {
+			var next [][]byte
+			for j := 0; j+1 < len(hashes); j += 2 {
+				next = append(next, rfc6962.DefaultHasher.HashChildren(hashes[j], hashes[j+1]))
+			}
+			hashes = next
+		}
+	}
+
+	sth := ct.SignedTreeHead{Version: ct.V1, TreeSize: uint64(size), Timestamp: 1234}
+	copy(sth.SHA256RootHash[:], l.root(t, size))
+	input, err := ct.SerializeSTHSignatureInput(sth)
+	if err != nil {
+		t.Fatalf("SerializeSTHSignatureInput()=%v", err)
+	}
+	ds, err := tls.CreateSignature(*key, tls.SHA256, input)
+	if err != nil {
+		t.Fatalf("CreateSignature()=%v", err)
+	}
+	sig, err := tls.Marshal(ds)
+	if err != nil {
+		t.Fatalf("Marshal(signature)=%v", err)
+	}
+	keyID := sha256.Sum256(append([]byte(staticLogOrigin+"\n\x05"), der...))
+	noteSig := append(binary.BigEndian.AppendUint64(keyID[:4], sth.Timestamp), sig...)
+	l.files["/checkpoint"] = []byte(fmt.Sprintf("%s\n%d\n%s\n\n— other.example.com AAAA\n— %s %s\n",
+		staticLogOrigin, size, base64.StdEncoding.EncodeToString(sth.SHA256RootHash[:]),
+		staticLogOrigin, base64.StdEncoding.EncodeToString(noteSig)))
+	return l
+}
+
+func testTilePath(level string, n, width int) string {
+	path := fmt.Sprintf("/tile/%s/%03d", level, n)
+	if width < 256 {
+		path += fmt.Sprintf(".p/%d", width)
+	}
+	return path
+}
+
+// root returns the root hash of the tree of the given size.
+func (l *staticLog) root(t *testing.T, size int) []byte {
+	t.Helper()
+	rf := compact.RangeFactory{Hash: rfc6962.DefaultHasher.HashChildren}
+	r := rf.NewEmptyRange(0)
+	for _, leaf := range l.leaves[:size] {
+		if err := r.Append(rfc6962.DefaultHasher.HashLeaf(leaf), nil); err != nil {
+			t.Fatalf("Append()=%v", err)
+		}
+	}
+	root, err := r.GetRootHash(nil)
+	if err != nil {
+		t.Fatalf("GetRootHash()=%v", err)
+	}
+	return root
+}
+
+func (l *staticLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if data, ok := l.files[r.URL.Path]; ok {
+		w.Write(data)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func TestStaticLogClient(t *testing.T) {
+	ctx := context.Background()
+	const size = 700
+	log := newStaticLog(t, size)
+	ts := httptest.NewServer(log)
+	defer ts.Close()
+	lc, err := client.NewStaticLogClient(ts.URL, nil, jsonclient.Options{PublicKeyDER: log.der})
+	if err != nil {
+		t.Fatalf("NewStaticLogClient()=%v", err)
+	}
+
+	sth, err := lc.GetSTH(ctx)
+	if err != nil {
+		t.Fatalf("GetSTH()=%v", err)
+	}
+	if sth.TreeSize != size || sth.Timestamp != 1234 {
+		t.Errorf("GetSTH()=%+v, want size %d at 1234", sth, size)
+	}
+
+	rsp, err := lc.GetRawEntries(ctx, 250, 520)
+	if err != nil {
+		t.Fatalf("GetRawEntries()=%v", err)
+	}
+	if got, want := len(rsp.Entries), 271; got != want {
+		t.Fatalf("GetRawEntries() returned %d entries, want %d", got, want)
+	}
+	for i, entry := range rsp.Entries {
+		index := 250 + int64(i)
+		if !bytes.Equal(entry.LeafInput, log.leaves[index]) {
+			t.Errorf("entry %d has LeafInput %x, want %x", index, entry.LeafInput, log.leaves[index])
+		}
+		raw, err := ct.RawLogEntryFromLeaf(index, &entry)
+		if err != nil {
+			t.Fatalf("RawLogEntryFromLeaf(%d)=%v", index, err)
+		}
+		if len(raw.Chain) != 1 || !bytes.Equal(raw.Chain[0].Data, log.issuer) {
+			t.Errorf("entry %d has chain %v, want the issuer", index, raw.Chain)
+		}
+		if want := fmt.Sprintf("precert-%d", index); index%5 == 0 && string(raw.Cert.Data) != want {
+			t.Errorf("entry %d has precert %q, want %q", index, raw.Cert.Data, want)
+		}
+	}
+
+	for _, sizes := range [][2]uint64{{1, 700}, {255, 700}, {300, 512}, {512, 700}, {699, 700}} {
+		consistency, err := lc.GetSTHConsistency(ctx, sizes[0], sizes[1])
+		if err != nil {
+			t.Fatalf("GetSTHConsistency(%d, %d)=%v", sizes[0], sizes[1], err)
+		}
+		if err := proof.VerifyConsistency(rfc6962.DefaultHasher, sizes[0], sizes[1], consistency, log.root(t, int(sizes[0])), log.root(t, int(sizes[1]))); err != nil {
+			t.Errorf("GetSTHConsistency(%d, %d) returned bad proof: %v", sizes[0], sizes[1], err)
+		}
+	}
+
+	for _, index := range []uint64{0, 255, 256, 600, 699} {
+		rsp, err := lc.GetEntryAndProof(ctx, index, size)
+		if err != nil {
+			t.Fatalf("GetEntryAndProof(%d)=%v", index, err)
+		}
+		leafHash := rfc6962.DefaultHasher.HashLeaf(rsp.LeafInput)
+		if err := proof.VerifyInclusion(rfc6962.DefaultHasher, index, size, leafHash, rsp.AuditPath, sth.SHA256RootHash[:]); err != nil {
+			t.Errorf("GetEntryAndProof(%d) returned bad proof: %v", index, err)
+		}
+	}
+
+	if _, err := lc.GetRawEntries(ctx, 690, 710); err == nil {
+		t.Error("GetRawEntries() beyond tree size succeeded, want error")
+	}
+}
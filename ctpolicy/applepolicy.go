@@ -23,26 +23,13 @@ import (
 type AppleCTPolicy struct{}
 
 // LogsByGroup describes submission requirements for embedded SCTs according to
-// https://support.apple.com/en-us/HT205280. Returns an error if it's not
-// possible to satisfy the policy with the provided loglist.
+// https://support.apple.com/en-us/103214: two SCTs for certificates valid
+// for at most 180 days and three otherwise, from logs of at least two
+// distinct operators. Apple does not trust certificates valid for more than
+// 398 days, whatever their SCTs. Returns an error if it's not possible to
+// satisfy the policy with the provided loglist.
 func (appleP AppleCTPolicy) LogsByGroup(cert *x509.Certificate, approved *loglist3.LogList) (LogPolicyData, error) {
-	var incCount int
-	switch m := lifetimeInMonths(cert); {
-	case m < 15:
-		incCount = 2
-	case m <= 27:
-		incCount = 3
-	case m <= 39:
-		incCount = 4
-	default:
-		incCount = 5
-	}
-	baseGroup, err := BaseGroupFor(approved, incCount)
-	if err != nil {
-		return nil, err
-	}
-	groups := LogPolicyData{baseGroup.Name: baseGroup}
-	return groups, nil
+	return lifetimeBasedGroups(cert, approved)
 }
 
 // Name returns label for the submission policy.
@@ -21,33 +21,6 @@ import (
 	"github.com/kylelemons/godebug/pretty"
 )
 
-func wantedAppleGroups(count int) LogPolicyData {
-	gi := LogPolicyData{
-		BaseName: {
-			Name: BaseName,
-			LogURLs: map[string]bool{
-				"https://ct.googleapis.com/aviator/":        true,
-				"https://ct.googleapis.com/icarus/":         true,
-				"https://ct.googleapis.com/rocketeer/":      true,
-				"https://ct.googleapis.com/racketeer/":      true,
-				"https://ct.googleapis.com/logs/argon2020/": true,
-				"https://log.bob.io":                        true,
-			},
-			MinInclusions: count,
-			IsBase:        true,
-			LogWeights: map[string]float32{
-				"https://ct.googleapis.com/aviator/":        1.0,
-				"https://ct.googleapis.com/icarus/":         1.0,
-				"https://ct.googleapis.com/rocketeer/":      1.0,
-				"https://ct.googleapis.com/racketeer/":      1.0,
-				"https://ct.googleapis.com/logs/argon2020/": 1.0,
-				"https://log.bob.io":                        1.0,
-			},
-		},
-	}
-	return gi
-}
-
 func TestCheckApplePolicy(t *testing.T) {
 	tests := []struct {
 		name string
@@ -55,24 +28,19 @@ func TestCheckApplePolicy(t *testing.T) {
 		want LogPolicyData
 	}{
 		{
-			name: "Short",
-			cert: getTestCertPEMShort(),
-			want: wantedAppleGroups(2),
-		},
-		{
-			name: "2-year",
-			cert: getTestCertPEM2Years(),
-			want: wantedAppleGroups(3),
+			name: "90-day",
+			cert: getTestCertPEM90Days(),
+			want: wantedGroups(2),
 		},
 		{
-			name: "3-year",
-			cert: getTestCertPEM3Years(),
-			want: wantedAppleGroups(4),
+			name: "Short",
+			cert: getTestCertPEMShort(),
+			want: wantedGroups(3),
 		},
 		{
 			name: "Long",
 			cert: getTestCertPEMLongOriginal(),
-			want: wantedAppleGroups(5),
+			want: wantedGroups(3),
 		},
 	}
 
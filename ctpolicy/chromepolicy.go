@@ -24,41 +24,12 @@ type ChromeCTPolicy struct {
 }
 
 // LogsByGroup describes submission requirements for embedded SCTs according to
-// https://github.com/chromium/ct-policy/blob/master/ct_policy.md#qualifying-certificate.
+// https://googlechrome.github.io/CertificateTransparency/ct_policy.html: two
+// SCTs for certificates valid for at most 180 days and three otherwise, from
+// logs of at least two distinct operators.
 // Returns an error if it's not possible to satisfy the policy with the provided loglist.
 func (chromeP ChromeCTPolicy) LogsByGroup(cert *x509.Certificate, approved *loglist3.LogList) (LogPolicyData, error) {
-	googGroup := LogGroupInfo{Name: "Google-operated", IsBase: false}
-	googGroup.populate(approved, func(op *loglist3.Operator) bool { return op.GoogleOperated() })
-	if err := googGroup.setMinInclusions(1); err != nil {
-		return nil, err
-	}
-
-	nonGoogGroup := LogGroupInfo{Name: "Non-Google-operated", IsBase: false}
-	nonGoogGroup.populate(approved, func(op *loglist3.Operator) bool { return !op.GoogleOperated() })
-	if err := nonGoogGroup.setMinInclusions(1); err != nil {
-		return nil, err
-	}
-	var incCount int
-	switch m := lifetimeInMonths(cert); {
-	case m < 15:
-		incCount = 2
-	case m <= 27:
-		incCount = 3
-	case m <= 39:
-		incCount = 4
-	default:
-		incCount = 5
-	}
-	baseGroup, err := BaseGroupFor(approved, incCount)
-	if err != nil {
-		return nil, err
-	}
-	groups := LogPolicyData{
-		googGroup.Name:    &googGroup,
-		nonGoogGroup.Name: &nonGoogGroup,
-		baseGroup.Name:    baseGroup,
-	}
-	return groups, nil
+	return lifetimeBasedGroups(cert, approved)
 }
 
 // Name returns label for the submission policy.
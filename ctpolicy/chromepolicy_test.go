@@ -21,68 +21,36 @@ import (
 	"github.com/kylelemons/godebug/pretty"
 )
 
-func wantedGroups(goog int, nonGoog int, base int, minusBob bool) LogPolicyData {
+var sampleLogOperators = map[string]string{
+	"https://ct.googleapis.com/logs/argon2020/": "Google",
+	"https://ct.googleapis.com/aviator/":        "Google",
+	"https://ct.googleapis.com/icarus/":         "Google",
+	"https://ct.googleapis.com/rocketeer/":      "Google",
+	"https://ct.googleapis.com/racketeer/":      "Google",
+	"https://log.bob.io":                        "Bob's CT Log Shop",
+}
+
+// wantedGroups returns the groups of both the Chrome and the Apple policies
+// for the sample log list.
+func wantedGroups(count int) LogPolicyData {
 	gi := LogPolicyData{
-		"Google-operated": {
-			Name: "Google-operated",
-			LogURLs: map[string]bool{
-				"https://ct.googleapis.com/logs/argon2020/": true,
-				"https://ct.googleapis.com/aviator/":        true,
-				"https://ct.googleapis.com/icarus/":         true,
-				"https://ct.googleapis.com/rocketeer/":      true,
-				"https://ct.googleapis.com/racketeer/":      true,
-			},
-			MinInclusions: goog,
-			IsBase:        false,
-			LogWeights: map[string]float32{
-				"https://ct.googleapis.com/logs/argon2020/": 1.0,
-				"https://ct.googleapis.com/aviator/":        1.0,
-				"https://ct.googleapis.com/icarus/":         1.0,
-				"https://ct.googleapis.com/rocketeer/":      1.0,
-				"https://ct.googleapis.com/racketeer/":      1.0,
-			},
-		},
-		"Non-Google-operated": {
-			Name: "Non-Google-operated",
-			LogURLs: map[string]bool{
-				"https://log.bob.io": true,
-			},
-			MinInclusions: nonGoog,
-			IsBase:        false,
-			LogWeights: map[string]float32{
-				"https://log.bob.io": 1.0,
-			},
-		},
 		BaseName: {
-			Name: BaseName,
-			LogURLs: map[string]bool{
-				"https://ct.googleapis.com/logs/argon2020/": true,
-				"https://ct.googleapis.com/aviator/":        true,
-				"https://ct.googleapis.com/icarus/":         true,
-				"https://ct.googleapis.com/rocketeer/":      true,
-				"https://ct.googleapis.com/racketeer/":      true,
-				"https://log.bob.io":                        true,
-			},
-			MinInclusions: base,
+			Name:          BaseName,
+			LogURLs:       make(map[string]bool),
+			MinInclusions: count,
+			MinOperators:  2,
 			IsBase:        true,
-			LogWeights: map[string]float32{
-				"https://ct.googleapis.com/logs/argon2020/": 1.0,
-				"https://ct.googleapis.com/aviator/":        1.0,
-				"https://ct.googleapis.com/icarus/":         1.0,
-				"https://ct.googleapis.com/rocketeer/":      1.0,
-				"https://ct.googleapis.com/racketeer/":      1.0,
-				"https://log.bob.io":                        1.0,
-			},
+			LogWeights:    make(map[string]float32),
+			LogOperators:  sampleLogOperators,
 		},
 	}
-	if minusBob {
-		delete(gi[BaseName].LogURLs, "https://log.bob.io")
-		delete(gi[BaseName].LogWeights, "https://log.bob.io")
-		delete(gi["Non-Google-operated"].LogURLs, "https://log.bob.io")
-		delete(gi["Non-Google-operated"].LogWeights, "https://log.bob.io")
+	for url := range sampleLogOperators {
+		gi[BaseName].LogURLs[url] = true
+		gi[BaseName].LogWeights[url] = 1.0
 	}
 	return gi
 }
+
 func TestCheckChromePolicy(t *testing.T) {
 	tests := []struct {
 		name string
@@ -90,24 +58,19 @@ func TestCheckChromePolicy(t *testing.T) {
 		want LogPolicyData
 	}{
 		{
-			name: "Short",
-			cert: getTestCertPEMShort(),
-			want: wantedGroups(1, 1, 2, false),
-		},
-		{
-			name: "2-year",
-			cert: getTestCertPEM2Years(),
-			want: wantedGroups(1, 1, 3, false),
+			name: "90-day",
+			cert: getTestCertPEM90Days(),
+			want: wantedGroups(2),
 		},
 		{
-			name: "3-year",
-			cert: getTestCertPEM3Years(),
-			want: wantedGroups(1, 1, 4, false),
+			name: "Short",
+			cert: getTestCertPEMShort(),
+			want: wantedGroups(3),
 		},
 		{
 			name: "Long",
 			cert: getTestCertPEMLongOriginal(),
-			want: wantedGroups(1, 1, 5, false),
+			want: wantedGroups(3),
 		},
 	}
 
@@ -127,57 +90,17 @@ func TestCheckChromePolicy(t *testing.T) {
 }
 
 func TestCheckChromePolicyWarnings(t *testing.T) {
-	tests := []struct {
-		name    string
-		cert    *x509.Certificate
-		want    LogPolicyData
-		warning string
-	}{
-		{
-			name:    "Short",
-			cert:    getTestCertPEMShort(),
-			want:    LogPolicyData{},
-			warning: "trying to assign 1 minimal inclusion number while only 0 logs are part of group \"Non-Google-operated\"",
-		},
-		{
-			name:    "2-year",
-			cert:    getTestCertPEM2Years(),
-			want:    LogPolicyData{},
-			warning: "trying to assign 1 minimal inclusion number while only 0 logs are part of group \"Non-Google-operated\"",
-		},
-		{
-			name:    "3-year",
-			cert:    getTestCertPEM3Years(),
-			want:    LogPolicyData{},
-			warning: "trying to assign 1 minimal inclusion number while only 0 logs are part of group \"Non-Google-operated\"",
-		},
-		{
-			name:    "Long",
-			cert:    getTestCertPEMLongOriginal(),
-			want:    LogPolicyData{},
-			warning: "trying to assign 1 minimal inclusion number while only 0 logs are part of group \"Non-Google-operated\"",
-		},
-	}
-
 	var policy ChromeCTPolicy
 	sampleLogList := sampleLogList(t)
-	// Removing Bob-log.
+	// Removing Bob-log, which leaves a single operator.
 	sampleLogList.Operators = sampleLogList.Operators[:1]
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-
-			got, err := policy.LogsByGroup(test.cert, sampleLogList)
-			if diff := pretty.Compare(test.want, got); diff != "" {
-				t.Errorf("LogsByGroup: (-want +got)\n%s", diff)
-			}
-			if err == nil && len(test.warning) > 0 {
-				t.Errorf("LogsByGroup returned no error when expected")
-			} else if err != nil {
-				if err.Error() != test.warning {
-					t.Errorf("LogsByGroup returned error message %q while expected %q", err.Error(), test.warning)
-				}
-			}
-		})
+	want := "trying to assign 2 minimal operators number while only 1 operators run logs of group \"All-logs\""
+	got, err := policy.LogsByGroup(getTestCertPEM90Days(), sampleLogList)
+	if got != nil {
+		t.Errorf("LogsByGroup()=%v; want nil", got)
+	}
+	if err == nil || err.Error() != want {
+		t.Errorf("LogsByGroup returned error %v while expected %q", err, want)
 	}
 }
@@ -0,0 +1,162 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+)
+
+// PolicyConfig describes a CT policy, for ecosystems whose policies differ
+// from those of Chrome and Apple. It is typically loaded from a JSON file,
+// for example:
+//
+//	{
+//	  "name": "Private",
+//	  "sct_counts": [
+//	    {"max_lifetime_days": 180, "min_scts": 2},
+//	    {"min_scts": 3}
+//	  ],
+//	  "min_operators": 2,
+//	  "log_states": ["usable", "qualified", "readonly"]
+//	}
+type PolicyConfig struct {
+	// Name labels the policy.
+	Name string `json:"name"`
+	// SCTCounts gives the number of SCTs required depending on the lifetime
+	// of certificates. Rules are ordered by increasing MaxLifetimeDays, and
+	// the last one has no maximum.
+	SCTCounts []SCTCountRule `json:"sct_counts"`
+	// MinOperators is the number of distinct operators which must run the
+	// logs the SCTs come from.
+	MinOperators int `json:"min_operators,omitempty"`
+	// LogStates lists the states of the logs whose SCTs count, as named in
+	// the log list ("pending", "qualified", "usable", "readonly", "retired"
+	// or "rejected"). If empty, logs in any state count.
+	LogStates []string `json:"log_states,omitempty"`
+}
+
+// SCTCountRule is the number of SCTs required for certificates whose lifetime
+// is at most MaxLifetimeDays, or any lifetime if MaxLifetimeDays is zero.
+type SCTCountRule struct {
+	MaxLifetimeDays int `json:"max_lifetime_days,omitempty"`
+	MinSCTs         int `json:"min_scts"`
+}
+
+var logStatusNames = map[string]loglist3.LogStatus{
+	"pending":   loglist3.PendingLogStatus,
+	"qualified": loglist3.QualifiedLogStatus,
+	"usable":    loglist3.UsableLogStatus,
+	"readonly":  loglist3.ReadOnlyLogStatus,
+	"retired":   loglist3.RetiredLogStatus,
+	"rejected":  loglist3.RejectedLogStatus,
+}
+
+// ConfigPolicy is a CTPolicy described by a PolicyConfig.
+type ConfigPolicy struct {
+	config   PolicyConfig
+	statuses []loglist3.LogStatus
+}
+
+// NewConfigPolicy checks config and returns the CTPolicy it describes.
+func NewConfigPolicy(config PolicyConfig) (*ConfigPolicy, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("policy has no name")
+	}
+	if len(config.SCTCounts) == 0 {
+		return nil, fmt.Errorf("policy %q has no SCT counts", config.Name)
+	}
+	prevMax := 0
+	for i, rule := range config.SCTCounts {
+		last := i == len(config.SCTCounts)-1
+		switch {
+		case rule.MinSCTs <= 0:
+			return nil, fmt.Errorf("policy %q: SCT count %d requires %d SCTs, want a positive number", config.Name, i, rule.MinSCTs)
+		case last && rule.MaxLifetimeDays != 0:
+			return nil, fmt.Errorf("policy %q: last SCT count has a maximum lifetime, want none", config.Name)
+		case !last && rule.MaxLifetimeDays <= prevMax:
+			return nil, fmt.Errorf("policy %q: SCT count %d has maximum lifetime %d days, want more than %d", config.Name, i, rule.MaxLifetimeDays, prevMax)
+		}
+		prevMax = rule.MaxLifetimeDays
+	}
+	if config.MinOperators < 0 {
+		return nil, fmt.Errorf("policy %q: negative minimal operators number %d", config.Name, config.MinOperators)
+	}
+	p := &ConfigPolicy{config: config}
+	for _, name := range config.LogStates {
+		status, ok := logStatusNames[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("policy %q: unknown log state %q", config.Name, name)
+		}
+		p.statuses = append(p.statuses, status)
+	}
+	return p, nil
+}
+
+// ParsePolicyConfig returns the CTPolicy described by the JSON-encoded
+// PolicyConfig in data.
+func ParsePolicyConfig(data []byte) (*ConfigPolicy, error) {
+	var config PolicyConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse policy config: %v", err)
+	}
+	return NewConfigPolicy(config)
+}
+
+// LoadPolicyConfig returns the CTPolicy described by the JSON-encoded
+// PolicyConfig in the file at path.
+func LoadPolicyConfig(path string) (*ConfigPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy config: %v", err)
+	}
+	return ParsePolicyConfig(data)
+}
+
+// LogsByGroup describes submission requirements for cert according to the
+// policy configuration. Returns an error if it's not possible to satisfy the
+// policy with the provided loglist.
+func (p *ConfigPolicy) LogsByGroup(cert *x509.Certificate, approved *loglist3.LogList) (LogPolicyData, error) {
+	if len(p.statuses) > 0 {
+		selected := approved.SelectByStatus(p.statuses)
+		approved = &selected
+	}
+	var incCount int
+	for _, rule := range p.config.SCTCounts {
+		incCount = rule.MinSCTs
+		if rule.MaxLifetimeDays > 0 && lifetime(cert) <= time.Duration(rule.MaxLifetimeDays)*24*time.Hour {
+			break
+		}
+	}
+	baseGroup, err := BaseGroupFor(approved, incCount)
+	if err != nil {
+		return nil, err
+	}
+	if err := baseGroup.setMinOperators(p.config.MinOperators); err != nil {
+		return nil, err
+	}
+	return LogPolicyData{baseGroup.Name: baseGroup}, nil
+}
+
+// Name returns label for the submission policy.
+func (p *ConfigPolicy) Name() string {
+	return p.config.Name
+}
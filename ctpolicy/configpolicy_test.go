@@ -0,0 +1,214 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctpolicy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestParsePolicyConfig(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			config: `{"name": "Private", "sct_counts": [{"max_lifetime_days": 180, "min_scts": 2}, {"min_scts": 3}], "min_operators": 2, "log_states": ["Usable", "qualified"]}`,
+		},
+		{
+			name:    "not-json",
+			config:  `{"name":`,
+			wantErr: "failed to parse",
+		},
+		{
+			name:    "no-name",
+			config:  `{"sct_counts": [{"min_scts": 3}]}`,
+			wantErr: "no name",
+		},
+		{
+			name:    "no-counts",
+			config:  `{"name": "Private"}`,
+			wantErr: "no SCT counts",
+		},
+		{
+			name:    "zero-scts",
+			config:  `{"name": "Private", "sct_counts": [{"min_scts": 0}]}`,
+			wantErr: "want a positive number",
+		},
+		{
+			name:    "bounded-last",
+			config:  `{"name": "Private", "sct_counts": [{"max_lifetime_days": 180, "min_scts": 2}]}`,
+			wantErr: "want none",
+		},
+		{
+			name:    "unordered",
+			config:  `{"name": "Private", "sct_counts": [{"max_lifetime_days": 180, "min_scts": 2}, {"max_lifetime_days": 90, "min_scts": 2}, {"min_scts": 3}]}`,
+			wantErr: "want more than 180",
+		},
+		{
+			name:    "negative-operators",
+			config:  `{"name": "Private", "sct_counts": [{"min_scts": 3}], "min_operators": -1}`,
+			wantErr: "negative",
+		},
+		{
+			name:    "unknown-state",
+			config:  `{"name": "Private", "sct_counts": [{"min_scts": 3}], "log_states": ["frozen"]}`,
+			wantErr: "unknown log state",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := ParsePolicyConfig([]byte(test.config))
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("ParsePolicyConfig()=%v,%v; want error containing %q", policy, err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePolicyConfig()=nil,%v; want policy", err)
+			}
+			if got, want := policy.Name(), "Private"; got != want {
+				t.Errorf("Name()=%q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(`{"name": "Private", "sct_counts": [{"min_scts": 1}]}`), 0o644); err != nil {
+		t.Fatalf("WriteFile()=%v", err)
+	}
+	if _, err := LoadPolicyConfig(path); err != nil {
+		t.Errorf("LoadPolicyConfig()=nil,%v; want policy", err)
+	}
+	if _, err := LoadPolicyConfig(path + ".missing"); err == nil {
+		t.Error("LoadPolicyConfig(missing)=_,nil; want error")
+	}
+}
+
+func TestConfigPolicyLogsByGroup(t *testing.T) {
+	lifetimeCounts := []SCTCountRule{{MaxLifetimeDays: 180, MinSCTs: 2}, {MinSCTs: 3}}
+	googleOperators := map[string]string{
+		"https://ct.googleapis.com/logs/argon2020/": "Google",
+		"https://ct.googleapis.com/aviator/":        "Google",
+		"https://ct.googleapis.com/icarus/":         "Google",
+		"https://ct.googleapis.com/rocketeer/":      "Google",
+	}
+	googleGroups := func(count int) LogPolicyData {
+		gi := LogPolicyData{
+			BaseName: {
+				Name:          BaseName,
+				LogURLs:       make(map[string]bool),
+				MinInclusions: count,
+				IsBase:        true,
+				LogWeights:    make(map[string]float32),
+				LogOperators:  googleOperators,
+			},
+		}
+		for url := range googleOperators {
+			gi[BaseName].LogURLs[url] = true
+			gi[BaseName].LogWeights[url] = 1.0
+		}
+		return gi
+	}
+
+	for _, test := range []struct {
+		name    string
+		config  PolicyConfig
+		cert    *x509.Certificate
+		want    LogPolicyData
+		wantErr string
+	}{
+		{
+			name:   "like-chrome-90-day",
+			config: PolicyConfig{Name: "Private", SCTCounts: lifetimeCounts, MinOperators: 2},
+			cert:   getTestCertPEM90Days(),
+			want:   wantedGroups(2),
+		},
+		{
+			name:   "like-chrome-long",
+			config: PolicyConfig{Name: "Private", SCTCounts: lifetimeCounts, MinOperators: 2},
+			cert:   getTestCertPEMLongOriginal(),
+			want:   wantedGroups(3),
+		},
+		{
+			name:   "active-logs",
+			config: PolicyConfig{Name: "Private", SCTCounts: lifetimeCounts, LogStates: []string{"usable", "qualified", "readonly"}},
+			cert:   getTestCertPEM90Days(),
+			want:   googleGroups(2),
+		},
+		{
+			name:    "active-logs-diverse",
+			config:  PolicyConfig{Name: "Private", SCTCounts: lifetimeCounts, MinOperators: 2, LogStates: []string{"usable", "qualified", "readonly"}},
+			cert:    getTestCertPEM90Days(),
+			wantErr: "only 1 operators",
+		},
+		{
+			name:    "too-many-scts",
+			config:  PolicyConfig{Name: "Private", SCTCounts: []SCTCountRule{{MinSCTs: 7}}},
+			cert:    getTestCertPEM90Days(),
+			wantErr: "only 6 logs",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := NewConfigPolicy(test.config)
+			if err != nil {
+				t.Fatalf("NewConfigPolicy()=nil,%v; want policy", err)
+			}
+			groups, err := policy.LogsByGroup(test.cert, sampleLogList(t))
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("LogsByGroup()=_,%v; want error containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LogsByGroup returned an error: %v", err)
+			}
+			if diff := pretty.Compare(test.want, groups); diff != "" {
+				t.Errorf("LogsByGroup: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRegisterPolicy(t *testing.T) {
+	policy, err := NewConfigPolicy(PolicyConfig{Name: "Private", SCTCounts: []SCTCountRule{{MinSCTs: 1}}})
+	if err != nil {
+		t.Fatalf("NewConfigPolicy()=nil,%v; want policy", err)
+	}
+	if err := RegisterPolicy("Test-Private", policy); err != nil {
+		t.Fatalf("RegisterPolicy()=%v; want nil", err)
+	}
+	if err := RegisterPolicy("test-private", policy); err == nil {
+		t.Error("RegisterPolicy(duplicate)=nil; want error")
+	}
+	if err := RegisterPolicy(ChromePolicyName, policy); err == nil {
+		t.Error("RegisterPolicy(chrome)=nil; want error")
+	}
+	got, err := PolicyByName("TEST-PRIVATE")
+	if err != nil || got != CTPolicy(policy) {
+		t.Errorf("PolicyByName()=%v,%v; want registered policy", got, err)
+	}
+}
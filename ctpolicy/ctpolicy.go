@@ -17,7 +17,10 @@ package ctpolicy
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
 	"github.com/RarimoVoting/certificate-transparency-go/x509"
@@ -33,8 +36,10 @@ type LogGroupInfo struct {
 	Name          string
 	LogURLs       map[string]bool    // set of members
 	MinInclusions int                // Required number of submissions.
+	MinOperators  int                // Required number of distinct operators of the logs submitted to.
 	IsBase        bool               // True only for Log-group covering all logs.
 	LogWeights    map[string]float32 // weights used for submission, default weight is 1
+	LogOperators  map[string]string  // operator name of each member, if known
 	wMu           sync.RWMutex       // guards weights
 }
 
@@ -50,19 +55,82 @@ func (group *LogGroupInfo) setMinInclusions(i int) error {
 	return nil
 }
 
+func (group *LogGroupInfo) setMinOperators(i int) error {
+	if i < 0 {
+		return fmt.Errorf("cannot assign negative minimal operators number")
+	}
+	group.MinOperators = i
+	operators := make(map[string]bool)
+	for logURL := range group.LogURLs {
+		operators[group.LogOperators[logURL]] = true
+	}
+	if i > len(operators) {
+		return fmt.Errorf("trying to assign %d minimal operators number while only %d operators run logs of group %q", i, len(operators), group.Name)
+	}
+	return nil
+}
+
 func (group *LogGroupInfo) populate(ll *loglist3.LogList, included func(op *loglist3.Operator) bool) {
 	group.LogURLs = make(map[string]bool)
 	group.LogWeights = make(map[string]float32)
+	group.LogOperators = make(map[string]string)
 	for _, op := range ll.Operators {
 		if included(op) {
 			for _, l := range op.Logs {
 				group.LogURLs[l.URL] = true
 				group.LogWeights[l.URL] = 1.0
+				group.LogOperators[l.URL] = op.Name
 			}
 		}
 	}
 }
 
+// progress returns the number of the given logs which are members of the
+// group, and their distinct operators.
+func (group *LogGroupInfo) progress(logURLs map[string]bool) (int, map[string]bool) {
+	count := 0
+	operators := make(map[string]bool)
+	for logURL, ok := range logURLs {
+		if ok && group.LogURLs[logURL] {
+			count++
+			operators[group.LogOperators[logURL]] = true
+		}
+	}
+	return count, operators
+}
+
+// Check returns an error describing how SCTs from the given set of logs fall
+// short of the requirements of the group, or nil if they meet them.
+func (group *LogGroupInfo) Check(logURLs map[string]bool) error {
+	count, operators := group.progress(logURLs)
+	if count < group.MinInclusions {
+		return fmt.Errorf("%d SCTs from log group %q, want %d", count, group.Name, group.MinInclusions)
+	}
+	if len(operators) < group.MinOperators {
+		return fmt.Errorf("SCTs from %d operators in log group %q, want %d", len(operators), group.Name, group.MinOperators)
+	}
+	return nil
+}
+
+// Wants reports whether an SCT from the log at logURL would bring SCTs from
+// the given set of logs closer to meeting the requirements of the group.
+// An SCT from an operator already covered is not wanted if the remaining SCTs
+// required are all needed to reach the minimal number of operators.
+func (group *LogGroupInfo) Wants(logURLs map[string]bool, logURL string) bool {
+	if !group.LogURLs[logURL] || logURLs[logURL] {
+		return false
+	}
+	count, operators := group.progress(logURLs)
+	missingOperators := group.MinOperators - len(operators)
+	if missingOperators > 0 && !operators[group.LogOperators[logURL]] {
+		return true
+	}
+	if missingOperators < 0 {
+		missingOperators = 0
+	}
+	return group.MinInclusions-count > missingOperators
+}
+
 // satisfyMinimalInclusion returns whether number of positive weights is
 // bigger or equal to minimal inclusion number.
 func (group *LogGroupInfo) satisfyMinimalInclusion(weights map[string]float32) bool {
@@ -192,17 +260,29 @@ func BaseGroupFor(approved *loglist3.LogList, incCount int) (*LogGroupInfo, erro
 	return &baseGroup, err
 }
 
-// lifetimeInMonths calculates and returns cert lifetime expressed in months
-// flooring incomplete month.
-func lifetimeInMonths(cert *x509.Certificate) int {
-	startYear, startMonth, startDay := cert.NotBefore.Date()
-	endYear, endMonth, endDay := cert.NotAfter.Date()
-	lifetimeInMonths := (int(endYear)-int(startYear))*12 + (int(endMonth) - int(startMonth))
-	if endDay < startDay {
-		// partial month
-		lifetimeInMonths--
+// lifetime returns the validity period of cert, which includes both its
+// NotBefore and NotAfter seconds (RFC 5280 s4.1.2.5).
+func lifetime(cert *x509.Certificate) time.Duration {
+	return cert.NotAfter.Sub(cert.NotBefore) + time.Second
+}
+
+// lifetimeBasedGroups returns the all-log group requiring two SCTs for
+// certificates valid for at most 180 days and three otherwise, from logs of
+// at least two distinct operators, which both Chrome and Apple policies
+// require.
+func lifetimeBasedGroups(cert *x509.Certificate, approved *loglist3.LogList) (LogPolicyData, error) {
+	incCount := 3
+	if lifetime(cert) <= 180*24*time.Hour {
+		incCount = 2
+	}
+	baseGroup, err := BaseGroupFor(approved, incCount)
+	if err != nil {
+		return nil, err
+	}
+	if err := baseGroup.setMinOperators(2); err != nil {
+		return nil, err
 	}
-	return lifetimeInMonths
+	return LogPolicyData{baseGroup.Name: baseGroup}, nil
 }
 
 // GroupSet is set of Log-group names.
@@ -222,3 +302,51 @@ func GroupByLogs(lg LogPolicyData) map[string]GroupSet {
 	}
 	return result
 }
+
+// Names of the policies available through PolicyByName.
+const (
+	ChromePolicyName = "chrome"
+	ApplePolicyName  = "apple"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]CTPolicy{
+		ChromePolicyName: ChromeCTPolicy{},
+		ApplePolicyName:  AppleCTPolicy{},
+	}
+)
+
+// RegisterPolicy makes policy available through PolicyByName under the given
+// name, which is case-insensitive. It lets ecosystems other than Chrome and
+// Apple plug in their own policies, for example a ConfigPolicy. Returns an
+// error if a policy is already registered under that name.
+func RegisterPolicy(name string, policy CTPolicy) error {
+	if name == "" || policy == nil {
+		return fmt.Errorf("cannot register CT policy %q without a name and a policy", name)
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	key := strings.ToLower(name)
+	if _, ok := registry[key]; ok {
+		return fmt.Errorf("CT policy %q already registered", name)
+	}
+	registry[key] = policy
+	return nil
+}
+
+// PolicyByName returns the CT policy with the given name, which is one of
+// the *PolicyName constants or a name passed to RegisterPolicy.
+func PolicyByName(name string) (CTPolicy, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if policy, ok := registry[strings.ToLower(name)]; ok {
+		return policy, nil
+	}
+	names := make([]string, 0, len(registry))
+	for n := range registry {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown CT policy %q, want one of %s", name, strings.Join(names, ", "))
+}
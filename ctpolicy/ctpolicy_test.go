@@ -16,6 +16,7 @@ package ctpolicy
 import (
 	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,21 +26,15 @@ import (
 	"github.com/RarimoVoting/certificate-transparency-go/x509util"
 )
 
-func getTestCertPEMShort() *x509.Certificate {
-	cert, _ := x509util.CertificateFromPEM([]byte(testdata.TestCertPEM))
-	cert.NotAfter = time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)
-	return cert
-}
-
-func getTestCertPEM2Years() *x509.Certificate {
+func getTestCertPEM90Days() *x509.Certificate {
 	cert, _ := x509util.CertificateFromPEM([]byte(testdata.TestCertPEM))
-	cert.NotAfter = time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+	cert.NotAfter = cert.NotBefore.Add(90 * 24 * time.Hour)
 	return cert
 }
 
-func getTestCertPEM3Years() *x509.Certificate {
+func getTestCertPEMShort() *x509.Certificate {
 	cert, _ := x509util.CertificateFromPEM([]byte(testdata.TestCertPEM))
-	cert.NotAfter = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	cert.NotAfter = time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)
 	return cert
 }
 
@@ -58,36 +53,24 @@ func sampleLogList(t *testing.T) *loglist3.LogList {
 	return &ll
 }
 
-func TestLifetimeInMonths(t *testing.T) {
+func TestLifetime(t *testing.T) {
 	tests := []struct {
 		name      string
 		notBefore time.Time
 		notAfter  time.Time
-		want      int
+		want      time.Duration
 	}{
 		{
-			name:      "ExactMonths",
-			notBefore: time.Date(2012, 6, 1, 0, 0, 0, 0, time.UTC),
-			notAfter:  time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC),
-			want:      7,
+			name:      "180Days",
+			notBefore: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			notAfter:  time.Date(2025, 6, 29, 23, 59, 59, 0, time.UTC),
+			want:      180 * 24 * time.Hour,
 		},
 		{
-			name:      "ExactYears",
-			notBefore: time.Date(2012, 6, 1, 0, 0, 0, 0, time.UTC),
-			notAfter:  time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC),
-			want:      31,
-		},
-		{
-			name:      "PartialSingleMonth",
-			notBefore: time.Date(2012, 6, 1, 0, 0, 0, 0, time.UTC),
-			notAfter:  time.Date(2012, 6, 25, 0, 0, 0, 0, time.UTC),
-			want:      0,
-		},
-		{
-			name:      "PartialMonths",
-			notBefore: time.Date(2012, 6, 25, 0, 0, 0, 0, time.UTC),
-			notAfter:  time.Date(2013, 7, 1, 0, 0, 0, 0, time.UTC),
-			want:      12,
+			name:      "180DaysAndASecond",
+			notBefore: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			notAfter:  time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC),
+			want:      180*24*time.Hour + time.Second,
 		},
 	}
 
@@ -96,9 +79,8 @@ func TestLifetimeInMonths(t *testing.T) {
 			cert := getTestCertPEMLongOriginal()
 			cert.NotBefore = test.notBefore
 			cert.NotAfter = test.notAfter
-			got := lifetimeInMonths(cert)
-			if got != test.want {
-				t.Errorf("lifetimeInMonths(%v, %v)=%d, want %d", test.notBefore, test.notAfter, got, test.want)
+			if got := lifetime(cert); got != test.want {
+				t.Errorf("lifetime(%v, %v)=%v, want %v", test.notBefore, test.notAfter, got, test.want)
 			}
 		})
 	}
@@ -213,3 +195,68 @@ func TestGroupByLogs(t *testing.T) {
 		})
 	}
 }
+
+func TestGroupCheckAndWants(t *testing.T) {
+	group := &LogGroupInfo{
+		Name:          "g",
+		LogURLs:       map[string]bool{"a1": true, "a2": true, "a3": true, "b1": true},
+		MinInclusions: 3,
+		MinOperators:  2,
+		LogOperators:  map[string]string{"a1": "A", "a2": "A", "a3": "A", "b1": "B"},
+	}
+	tests := []struct {
+		name      string
+		got       map[string]bool
+		wantErr   string
+		wantLogs  []string
+		otherLogs []string
+	}{
+		{name: "None", got: map[string]bool{}, wantErr: "0 SCTs", wantLogs: []string{"a1", "b1"}},
+		{name: "OneA", got: map[string]bool{"a1": true}, wantErr: "1 SCTs", wantLogs: []string{"a2", "b1"}, otherLogs: []string{"a1", "other"}},
+		{name: "TwoA", got: map[string]bool{"a1": true, "a2": true}, wantErr: "2 SCTs", wantLogs: []string{"b1"}, otherLogs: []string{"a3"}},
+		{name: "ThreeA", got: map[string]bool{"a1": true, "a2": true, "a3": true}, wantErr: "SCTs from 1 operators", wantLogs: []string{"b1"}},
+		{name: "Satisfied", got: map[string]bool{"a1": true, "a2": true, "b1": true}, otherLogs: []string{"a3"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := group.Check(test.got)
+			if test.wantErr == "" && err != nil {
+				t.Errorf("Check(%v)=%v; want nil", test.got, err)
+			} else if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Errorf("Check(%v)=%v; want error containing %q", test.got, err, test.wantErr)
+			}
+			for _, url := range test.wantLogs {
+				if !group.Wants(test.got, url) {
+					t.Errorf("Wants(%v, %q)=false; want true", test.got, url)
+				}
+			}
+			for _, url := range test.otherLogs {
+				if group.Wants(test.got, url) {
+					t.Errorf("Wants(%v, %q)=true; want false", test.got, url)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicyByName(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		want string
+	}{
+		{name: "chrome", want: "Chrome"},
+		{name: "Apple", want: "Apple"},
+		{name: "firefox"},
+	} {
+		policy, err := PolicyByName(test.name)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("PolicyByName(%q)=%v,nil; want error", test.name, policy.Name())
+			}
+			continue
+		}
+		if err != nil || policy.Name() != test.want {
+			t.Errorf("PolicyByName(%q)=%v,%v; want %s policy", test.name, policy, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctutil
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"errors"
+	"sync"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+)
+
+// verifiedSCT identifies an SCT whose signature verified. The hash of the
+// signature is part of it, so that an SCT with the same contents but a
+// forged signature is not taken as verified.
+type verifiedSCT struct {
+	logID     [sha256.Size]byte
+	leafHash  [sha256.Size]byte
+	timestamp uint64
+	sigHash   [sha256.Size]byte
+}
+
+// VerificationCache remembers the SCTs whose signature verified, so that
+// services verifying the SCTs of each TLS handshake need not verify the
+// signatures of the same SCTs again. It holds up to a maximum number of
+// SCTs, evicting the least recently used. It is safe for concurrent use.
+type VerificationCache struct {
+	mu      sync.Mutex
+	maxSize int
+	lru     *list.List // of verifiedSCT, most recently used first
+	items   map[verifiedSCT]*list.Element
+
+	hits, misses int64
+}
+
+// NewVerificationCache creates a VerificationCache holding up to maxSize
+// SCTs.
+func NewVerificationCache(maxSize int) *VerificationCache {
+	return &VerificationCache{maxSize: maxSize, lru: list.New(), items: make(map[verifiedSCT]*list.Element)}
+}
+
+func cacheKey(sct *ct.SignedCertificateTimestamp, leafHash [sha256.Size]byte) verifiedSCT {
+	return verifiedSCT{
+		logID:     sct.LogID.KeyID,
+		leafHash:  leafHash,
+		timestamp: sct.Timestamp,
+		sigHash:   sha256.Sum256(sct.Signature.Signature),
+	}
+}
+
+// contains reports whether the SCT with the given leaf hash is known to
+// have verified.
+func (c *VerificationCache) contains(key verifiedSCT) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return false
+	}
+	c.hits++
+	c.lru.MoveToFront(elem)
+	return true
+}
+
+// add records that the SCT with the given leaf hash verified.
+func (c *VerificationCache) add(key verifiedSCT) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.maxSize <= 0 {
+		return
+	}
+	if elem, ok := c.items[key]; ok {
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.lru.PushFront(key)
+	for c.lru.Len() > c.maxSize {
+		delete(c.items, c.lru.Remove(c.lru.Back()).(verifiedSCT))
+	}
+}
+
+// verify checks the signature of sct over leaf with sv, unless the cache
+// knows it verified already.
+func (c *VerificationCache) verify(sv *ct.SignatureVerifier, sct *ct.SignedCertificateTimestamp, leaf *ct.MerkleTreeLeaf) error {
+	if c == nil {
+		return sv.VerifySCTSignature(*sct, ct.LogEntry{Leaf: *leaf})
+	}
+	leafHash, err := ct.LeafHashForLeaf(leaf)
+	if err != nil {
+		return err
+	}
+	key := cacheKey(sct, leafHash)
+	if c.contains(key) {
+		return nil
+	}
+	if err := sv.VerifySCTSignature(*sct, ct.LogEntry{Leaf: *leaf}); err != nil {
+		return err
+	}
+	c.add(key)
+	return nil
+}
+
+// VerifySCTWithVerifier does as the package-level VerifySCTWithVerifier
+// does, skipping the verification of the signature of SCTs which verified
+// before.
+func (c *VerificationCache) VerifySCTWithVerifier(sv *ct.SignatureVerifier, chain []*x509.Certificate, sct *ct.SignedCertificateTimestamp, embedded bool) error {
+	if sv == nil {
+		return errors.New("ct.SignatureVerifier is nil")
+	}
+	leaf, err := createLeaf(chain, sct, embedded)
+	if err != nil {
+		return err
+	}
+	return c.verify(sv, sct, leaf)
+}
+
+// Len returns the number of SCTs in the cache.
+func (c *VerificationCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Stats returns the number of lookups which found a verified SCT, and of
+// those which did not.
+func (c *VerificationCache) Stats() (hits, misses int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctutil
+
+import (
+	"sync"
+	"testing"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+)
+
+func cacheTestData(t *testing.T) (*ct.SignatureVerifier, []*x509.Certificate, *ct.SignedCertificateTimestamp) {
+	t.Helper()
+	pk, err := ct.PublicKeyFromB64(testdata.LogPublicKeyB64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv, err := ct.NewSignatureVerifier(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, err := x509util.CertificatesFromPEM([]byte(testdata.TestCertPEM + testdata.CACertPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sct ct.SignedCertificateTimestamp
+	if _, err := tls.Unmarshal(testdata.TestCertProof, &sct); err != nil {
+		t.Fatal(err)
+	}
+	return sv, chain, &sct
+}
+
+func TestVerificationCache(t *testing.T) {
+	sv, chain, sct := cacheTestData(t)
+	c := NewVerificationCache(10)
+
+	for i := 0; i < 3; i++ {
+		if err := c.VerifySCTWithVerifier(sv, chain, sct, false); err != nil {
+			t.Fatalf("VerifySCTWithVerifier()=%v", err)
+		}
+	}
+	if hits, misses := c.Stats(); hits != 2 || misses != 1 {
+		t.Errorf("Stats()=%d,%d, want 2,1", hits, misses)
+	}
+	if got := c.Len(); got != 1 {
+		t.Errorf("Len()=%d, want 1", got)
+	}
+
+	// The same SCT with another signature must not be taken as verified.
+	forged := *sct
+	forged.Signature.Signature = append([]byte{}, sct.Signature.Signature...)
+	forged.Signature.Signature[len(forged.Signature.Signature)-1] ^= 1
+	if err := c.VerifySCTWithVerifier(sv, chain, &forged, false); err == nil {
+		t.Error("VerifySCTWithVerifier(forged)=nil, want error")
+	}
+	// SCTs which fail are not cached.
+	if got := c.Len(); got != 1 {
+		t.Errorf("Len()=%d, want 1", got)
+	}
+
+	// An SCT for another certificate does not hit.
+	if err := c.VerifySCTWithVerifier(sv, chain[1:], sct, false); err == nil {
+		t.Error("VerifySCTWithVerifier(other cert)=nil, want error")
+	}
+}
+
+func TestVerificationCacheEviction(t *testing.T) {
+	c := NewVerificationCache(2)
+	keys := []verifiedSCT{{timestamp: 1}, {timestamp: 2}, {timestamp: 3}}
+	c.add(keys[0])
+	c.add(keys[1])
+	c.contains(keys[0]) // keys[1] is now the least recently used.
+	c.add(keys[2])
+	if got := c.Len(); got != 2 {
+		t.Errorf("Len()=%d, want 2", got)
+	}
+	for i, want := range []bool{true, false, true} {
+		if got := c.contains(keys[i]); got != want {
+			t.Errorf("contains(keys[%d])=%v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestVerificationCacheConcurrent(t *testing.T) {
+	sv, chain, sct := cacheTestData(t)
+	c := NewVerificationCache(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if err := c.VerifySCTWithVerifier(sv, chain, sct, false); err != nil {
+					t.Errorf("VerifySCTWithVerifier()=%v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if hits, misses := c.Stats(); hits+misses != 100 || misses < 1 {
+		t.Errorf("Stats()=%d,%d, want 100 lookups with at least one miss", hits, misses)
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	gotls "crypto/tls"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/ctpolicy"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+	"golang.org/x/crypto/ocsp"
+)
+
+// oidExtensionCTSCTOCSP is the OID of the OCSP single response extension
+// holding an SCT list, defined in RFC 6962 s3.3.
+var oidExtensionCTSCTOCSP = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 5}
+
+// SCTSource is the way an SCT was delivered to a TLS client.
+type SCTSource int
+
+// Sources of SCTs, from RFC 6962 s3.3.
+const (
+	// The SCT was in the signed_certificate_timestamp TLS extension.
+	SCTFromTLSExtension SCTSource = iota
+	// The SCT was in the stapled OCSP response.
+	SCTFromOCSP
+	// The SCT was embedded in the X.509 certificate.
+	SCTFromEmbedded
+)
+
+func (s SCTSource) String() string {
+	switch s {
+	case SCTFromTLSExtension:
+		return "TLS extension"
+	case SCTFromOCSP:
+		return "OCSP response"
+	case SCTFromEmbedded:
+		return "embedded"
+	}
+	return fmt.Sprintf("SCTSource(%d)", int(s))
+}
+
+// SCTResult is the result of the verification of one SCT.
+type SCTResult struct {
+	Source SCTSource
+	// Index is the position of the SCT in the SCT list of its source.
+	Index int
+	// SCT is the parsed SCT, or nil if it could not be parsed.
+	SCT *ct.SignedCertificateTimestamp
+	// Log is the log which issued the SCT, or nil if it is not in the log
+	// list.
+	Log *loglist3.Log
+	// Leaf is the Merkle tree leaf the SCT promises to incorporate, with the
+	// timestamp of the SCT, e.g. to check its inclusion in the log. It is nil
+	// if the SCT could not be parsed or the leaf could not be built.
+	Leaf *ct.MerkleTreeLeaf
+	// Err is nil if the SCT verified.
+	Err error
+}
+
+// ConnectionSCTs holds the results of the verification of all the SCTs
+// delivered on a TLS connection.
+type ConnectionSCTs struct {
+	Results []SCTResult
+	// PolicyErr is nil if the verified SCTs satisfy the CT policy.
+	PolicyErr error
+}
+
+// Verified returns the results of the SCTs which verified.
+func (c *ConnectionSCTs) Verified() []SCTResult {
+	var verified []SCTResult
+	for _, r := range c.Results {
+		if r.Err == nil {
+			verified = append(verified, r)
+		}
+	}
+	return verified
+}
+
+// PolicySatisfied reports whether the verified SCTs satisfy the CT policy.
+func (c *ConnectionSCTs) PolicySatisfied() bool {
+	return c.PolicyErr == nil
+}
+
+// VerifyConnectionSCTs verifies each SCT delivered on a TLS connection, from
+// the TLS extension, the stapled OCSP response and the leaf certificate,
+// against the logs of ll. If policy is not nil, it also checks whether the
+// verified SCTs satisfy it; otherwise PolicyErr is left nil.
+//
+// The issuer of the leaf certificate is required to verify SCTs embedded in
+// it, and is looked for in the verified chains of cs, then in the chain sent
+// by the peer. An error is returned only if the leaf certificate is missing
+// or cannot be parsed.
+func VerifyConnectionSCTs(cs *gotls.ConnectionState, ll *loglist3.LogList, policy ctpolicy.CTPolicy) (*ConnectionSCTs, error) {
+	return VerifyConnectionSCTsWithCache(cs, ll, policy, nil)
+}
+
+// VerifyConnectionSCTsWithCache does as VerifyConnectionSCTs does, skipping
+// the verification of the signatures of the SCTs which cache knows to have
+// verified, and adding those which verify to it. The cache may be nil.
+func VerifyConnectionSCTsWithCache(cs *gotls.ConnectionState, ll *loglist3.LogList, policy ctpolicy.CTPolicy, cache *VerificationCache) (*ConnectionSCTs, error) {
+	if len(cs.PeerCertificates) == 0 {
+		return nil, errors.New("no peer certificates")
+	}
+	leaf, err := x509.ParseCertificate(cs.PeerCertificates[0].Raw)
+	if x509.IsFatal(err) {
+		return nil, fmt.Errorf("failed to parse leaf certificate: %v", err)
+	}
+	issuer := connectionIssuer(cs, leaf)
+
+	v := &connectionVerifier{ll: ll, cache: cache, verifiers: make(map[[sha256.Size]byte]*ct.SignatureVerifier)}
+	if len(cs.SignedCertificateTimestamps) > 0 || len(cs.OCSPResponse) > 0 {
+		// SCTs delivered outside the certificate are for the certificate itself.
+		v.leaf, v.leafErr = ct.MerkleTreeLeafFromChain([]*x509.Certificate{leaf}, ct.X509LogEntryType, 0)
+		for i, sct := range cs.SignedCertificateTimestamps {
+			v.check(SCTFromTLSExtension, i, sct)
+		}
+		if len(cs.OCSPResponse) > 0 {
+			scts, err := ocspSCTs(cs.OCSPResponse)
+			if err != nil {
+				v.results = append(v.results, SCTResult{Source: SCTFromOCSP, Index: -1, Err: err})
+			}
+			for i, sct := range scts {
+				v.check(SCTFromOCSP, i, sct.Val)
+			}
+		}
+	}
+	if len(leaf.SCTList.SCTList) > 0 {
+		if issuer == nil {
+			v.leaf, v.leafErr = nil, errors.New("issuer of leaf certificate not found")
+		} else {
+			v.leaf, v.leafErr = ct.MerkleTreeLeafForEmbeddedSCT([]*x509.Certificate{leaf, issuer}, 0)
+		}
+		for i, sct := range leaf.SCTList.SCTList {
+			v.check(SCTFromEmbedded, i, sct.Val)
+		}
+	}
+
+	c := &ConnectionSCTs{Results: v.results}
+	if policy != nil {
+		c.PolicyErr = checkPolicy(policy, leaf, ll, c.Verified())
+	}
+	return c, nil
+}
+
+// connectionIssuer returns the issuer of leaf on a connection, or nil if it
+// was not sent.
+func connectionIssuer(cs *gotls.ConnectionState, leaf *x509.Certificate) *x509.Certificate {
+	var candidates [][]byte
+	for _, chain := range cs.VerifiedChains {
+		if len(chain) > 1 {
+			candidates = append(candidates, chain[1].Raw)
+		}
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		candidates = append(candidates, cert.Raw)
+	}
+	for _, der := range candidates {
+		c, err := x509.ParseCertificate(der)
+		if x509.IsFatal(err) {
+			continue
+		}
+		if bytes.Equal(c.RawSubject, leaf.RawIssuer) && c.CheckSignature(leaf.SignatureAlgorithm, leaf.RawTBSCertificate, leaf.Signature) == nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// ocspSCTs returns the SCTs in the single response extension of an OCSP
+// response. The signature of the response is not checked.
+func ocspSCTs(der []byte) ([]x509.SerializedSCT, error) {
+	rsp, err := ocsp.ParseResponse(der, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OCSP response: %v", err)
+	}
+	for _, ext := range rsp.Extensions {
+		if !ext.Id.Equal(oidExtensionCTSCTOCSP) {
+			continue
+		}
+		var raw []byte
+		if rest, err := asn1.Unmarshal(ext.Value, &raw); err != nil {
+			return nil, fmt.Errorf("failed to asn1.Unmarshal OCSP SCT list: %v", err)
+		} else if len(rest) != 0 {
+			return nil, errors.New("trailing data after ASN1-encoded OCSP SCT list")
+		}
+		var list x509.SignedCertificateTimestampList
+		if rest, err := tls.Unmarshal(raw, &list); err != nil {
+			return nil, fmt.Errorf("failed to tls.Unmarshal OCSP SCT list: %v", err)
+		} else if len(rest) != 0 {
+			return nil, errors.New("trailing data after TLS-encoded OCSP SCT list")
+		}
+		return list.SCTList, nil
+	}
+	return nil, nil
+}
+
+// connectionVerifier accumulates the results of SCT verifications against
+// the Merkle leaf of the SCTs being checked.
+type connectionVerifier struct {
+	ll        *loglist3.LogList
+	cache     *VerificationCache
+	verifiers map[[sha256.Size]byte]*ct.SignatureVerifier
+	leaf      *ct.MerkleTreeLeaf
+	leafErr   error
+	results   []SCTResult
+}
+
+func (v *connectionVerifier) check(source SCTSource, index int, data []byte) {
+	r := SCTResult{Source: source, Index: index}
+	r.SCT, r.Log, r.Err = v.verify(data)
+	if r.SCT != nil && v.leafErr == nil {
+		r.Leaf = v.leafAt(r.SCT.Timestamp)
+	}
+	v.results = append(v.results, r)
+}
+
+// leafAt returns a copy of the Merkle leaf being checked, with the given
+// timestamp.
+func (v *connectionVerifier) leafAt(timestamp uint64) *ct.MerkleTreeLeaf {
+	leaf := *v.leaf
+	entry := *leaf.TimestampedEntry
+	entry.Timestamp = timestamp
+	leaf.TimestampedEntry = &entry
+	return &leaf
+}
+
+func (v *connectionVerifier) verify(data []byte) (*ct.SignedCertificateTimestamp, *loglist3.Log, error) {
+	sct, err := x509util.ExtractSCT(&x509.SerializedSCT{Val: data})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse SCT: %v", err)
+	}
+	log := v.ll.FindLogByKeyHash(sct.LogID.KeyID)
+	if log == nil {
+		return sct, nil, fmt.Errorf("unknown log with ID %x", sct.LogID.KeyID[:])
+	}
+	if v.leafErr != nil {
+		return sct, log, fmt.Errorf("failed to build Merkle leaf: %v", v.leafErr)
+	}
+	sv := v.verifiers[sct.LogID.KeyID]
+	if sv == nil {
+		pubKey, err := x509.ParsePKIXPublicKey(log.Key)
+		if err != nil {
+			return sct, log, fmt.Errorf("failed to parse key of log %q: %v", log.Description, err)
+		}
+		if sv, err = ct.NewSignatureVerifier(pubKey); err != nil {
+			return sct, log, fmt.Errorf("failed to create verifier for log %q: %v", log.Description, err)
+		}
+		v.verifiers[sct.LogID.KeyID] = sv
+	}
+	if err := v.cache.verify(sv, sct, v.leafAt(sct.Timestamp)); err != nil {
+		return sct, log, err
+	}
+	return sct, log, nil
+}
+
+// checkPolicy returns an error if the logs of the verified SCTs do not
+// satisfy each log group of policy.
+func checkPolicy(policy ctpolicy.CTPolicy, leaf *x509.Certificate, ll *loglist3.LogList, verified []SCTResult) error {
+	groups, err := policy.LogsByGroup(leaf, ll)
+	if err != nil {
+		return fmt.Errorf("%s policy cannot be satisfied by log list: %v", policy.Name(), err)
+	}
+	logs := make(map[string]bool)
+	for _, r := range verified {
+		logs[r.Log.URL] = true
+	}
+	for _, group := range groups {
+		if err := group.Check(logs); err != nil {
+			return fmt.Errorf("%s policy not satisfied: %v", policy.Name(), err)
+		}
+	}
+	return nil
+}
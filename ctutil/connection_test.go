@@ -0,0 +1,278 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	gotls "crypto/tls"
+	gox509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/ctpolicy"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"golang.org/x/crypto/ocsp"
+)
+
+// onePolicy requires an SCT from any one log.
+type onePolicy struct{}
+
+func (onePolicy) LogsByGroup(cert *x509.Certificate, approved *loglist3.LogList) (ctpolicy.LogPolicyData, error) {
+	base, err := ctpolicy.BaseGroupFor(approved, 1)
+	if err != nil {
+		return nil, err
+	}
+	return ctpolicy.LogPolicyData{base.Name: base}, nil
+}
+
+func (onePolicy) Name() string { return "One" }
+
+func goCerts(t *testing.T, pems ...string) []*gox509.Certificate {
+	t.Helper()
+	var certs []*gox509.Certificate
+	for _, p := range pems {
+		block, _ := pem.Decode([]byte(p))
+		if block == nil {
+			t.Fatal("failed to decode PEM")
+		}
+		cert, err := gox509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatalf("failed to parse certificate: %v", err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs
+}
+
+// ocspResponse returns an OCSP response for cert holding the given SCTs.
+func ocspResponse(t *testing.T, cert *gox509.Certificate, scts ...[]byte) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &gox509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "OCSP Responder"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := gox509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responder, err := gox509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rspTemplate := ocsp.Response{Status: ocsp.Good, SerialNumber: cert.SerialNumber, ThisUpdate: time.Now()}
+	if len(scts) > 0 {
+		var list x509.SignedCertificateTimestampList
+		for _, sct := range scts {
+			list.SCTList = append(list.SCTList, x509.SerializedSCT{Val: sct})
+		}
+		tlsList, err := tls.Marshal(list)
+		if err != nil {
+			t.Fatal(err)
+		}
+		extValue, err := asn1.Marshal(tlsList)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rspTemplate.ExtraExtensions = []pkix.Extension{{Id: oidExtensionCTSCTOCSP, Value: extValue}}
+	}
+	rsp, err := ocsp.CreateResponse(responder, responder, rspTemplate, key)
+	if err != nil {
+		t.Fatalf("failed to create OCSP response: %v", err)
+	}
+	return rsp
+}
+
+func TestVerifyConnectionSCTs(t *testing.T) {
+	logKey, err := base64.StdEncoding.DecodeString(testdata.LogPublicKeyB64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logID := sha256.Sum256(logKey)
+	testLog := &loglist3.Log{Description: "Test Log", URL: "https://ct.example.com/", Key: logKey, LogID: logID[:]}
+	otherLog := &loglist3.Log{Description: "Other Log", URL: "https://other.example.com/", Key: logKey, LogID: []byte("other")}
+	ll := &loglist3.LogList{Operators: []*loglist3.Operator{{Name: "Test", Logs: []*loglist3.Log{testLog, otherLog}}}}
+
+	certChain := goCerts(t, testdata.TestCertPEM, testdata.CACertPEM)
+	for _, test := range []struct {
+		desc       string
+		cs         gotls.ConnectionState
+		ll         *loglist3.LogList
+		policy     ctpolicy.CTPolicy
+		wantSource []SCTSource
+		wantValid  []bool
+		wantPolicy bool
+	}{
+		{
+			desc:       "tls-extension",
+			cs:         gotls.ConnectionState{PeerCertificates: certChain, SignedCertificateTimestamps: [][]byte{testdata.TestCertProof}},
+			policy:     onePolicy{},
+			wantSource: []SCTSource{SCTFromTLSExtension},
+			wantValid:  []bool{true},
+			wantPolicy: true,
+		},
+		{
+			desc:       "tls-extension-wrong-cert",
+			cs:         gotls.ConnectionState{PeerCertificates: certChain, SignedCertificateTimestamps: [][]byte{testdata.TestPreCertProof}},
+			policy:     onePolicy{},
+			wantSource: []SCTSource{SCTFromTLSExtension},
+			wantValid:  []bool{false},
+		},
+		{
+			desc:       "ocsp",
+			cs:         gotls.ConnectionState{PeerCertificates: certChain, OCSPResponse: ocspResponse(t, certChain[0], testdata.TestCertProof)},
+			wantSource: []SCTSource{SCTFromOCSP},
+			wantValid:  []bool{true},
+			wantPolicy: true,
+		},
+		{
+			desc:       "bad-ocsp",
+			cs:         gotls.ConnectionState{PeerCertificates: certChain, OCSPResponse: []byte("bad")},
+			wantSource: []SCTSource{SCTFromOCSP},
+			wantValid:  []bool{false},
+			wantPolicy: true,
+		},
+		{
+			desc:       "embedded",
+			cs:         gotls.ConnectionState{PeerCertificates: goCerts(t, testdata.TestEmbeddedCertPEM, testdata.CACertPEM)},
+			policy:     onePolicy{},
+			wantSource: []SCTSource{SCTFromEmbedded},
+			wantValid:  []bool{true},
+			wantPolicy: true,
+		},
+		{
+			desc:       "embedded-in-verified-chain",
+			cs:         gotls.ConnectionState{PeerCertificates: goCerts(t, testdata.TestEmbeddedCertPEM), VerifiedChains: [][]*gox509.Certificate{goCerts(t, testdata.TestEmbeddedCertPEM, testdata.CACertPEM)}},
+			wantSource: []SCTSource{SCTFromEmbedded},
+			wantValid:  []bool{true},
+			wantPolicy: true,
+		},
+		{
+			desc:       "embedded-no-issuer",
+			cs:         gotls.ConnectionState{PeerCertificates: goCerts(t, testdata.TestEmbeddedCertPEM)},
+			wantSource: []SCTSource{SCTFromEmbedded},
+			wantValid:  []bool{false},
+			wantPolicy: true,
+		},
+		{
+			desc:       "embedded-invalid",
+			cs:         gotls.ConnectionState{PeerCertificates: goCerts(t, testdata.TestInvalidEmbeddedCertPEM, testdata.CACertPEM)},
+			wantSource: []SCTSource{SCTFromEmbedded},
+			wantValid:  []bool{false},
+			wantPolicy: true,
+		},
+		{
+			desc:       "unknown-log",
+			cs:         gotls.ConnectionState{PeerCertificates: certChain, SignedCertificateTimestamps: [][]byte{testdata.TestCertProof}},
+			ll:         &loglist3.LogList{Operators: []*loglist3.Operator{{Name: "Other", Logs: []*loglist3.Log{otherLog}}}},
+			policy:     onePolicy{},
+			wantSource: []SCTSource{SCTFromTLSExtension},
+			wantValid:  []bool{false},
+		},
+		{
+			desc:       "apple-policy-unsatisfied",
+			cs:         gotls.ConnectionState{PeerCertificates: certChain, SignedCertificateTimestamps: [][]byte{testdata.TestCertProof}},
+			policy:     ctpolicy.AppleCTPolicy{},
+			wantSource: []SCTSource{SCTFromTLSExtension},
+			wantValid:  []bool{true},
+		},
+		{
+			desc:       "all-sources",
+			cs:         gotls.ConnectionState{PeerCertificates: goCerts(t, testdata.TestEmbeddedCertPEM, testdata.CACertPEM), SignedCertificateTimestamps: [][]byte{testdata.TestCertProof}, OCSPResponse: ocspResponse(t, certChain[0])},
+			policy:     onePolicy{},
+			wantSource: []SCTSource{SCTFromTLSExtension, SCTFromEmbedded},
+			wantValid:  []bool{false, true},
+			wantPolicy: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			testLL := ll
+			if test.ll != nil {
+				testLL = test.ll
+			}
+			got, err := VerifyConnectionSCTs(&test.cs, testLL, test.policy)
+			if err != nil {
+				t.Fatalf("VerifyConnectionSCTs()=%v", err)
+			}
+			if len(got.Results) != len(test.wantSource) {
+				t.Fatalf("VerifyConnectionSCTs() gave %d results, want %d: %+v", len(got.Results), len(test.wantSource), got.Results)
+			}
+			for i, r := range got.Results {
+				if r.Source != test.wantSource[i] {
+					t.Errorf("Results[%d].Source=%v, want %v", i, r.Source, test.wantSource[i])
+				}
+				if valid := r.Err == nil; valid != test.wantValid[i] {
+					t.Errorf("Results[%d].Err=%v, want valid? %v", i, r.Err, test.wantValid[i])
+				}
+				if r.Err == nil && r.Log != testLog {
+					t.Errorf("Results[%d].Log=%v, want %v", i, r.Log, testLog)
+				}
+				if r.Err == nil && (r.Leaf == nil || r.Leaf.TimestampedEntry.Timestamp != r.SCT.Timestamp) {
+					t.Errorf("Results[%d].Leaf=%+v, want leaf with SCT timestamp %d", i, r.Leaf, r.SCT.Timestamp)
+				}
+			}
+			if got.PolicySatisfied() != test.wantPolicy {
+				t.Errorf("PolicySatisfied()=%v (%v), want %v", got.PolicySatisfied(), got.PolicyErr, test.wantPolicy)
+			}
+		})
+	}
+}
+
+func TestVerifyConnectionSCTsNoCerts(t *testing.T) {
+	if _, err := VerifyConnectionSCTs(&gotls.ConnectionState{}, &loglist3.LogList{}, nil); err == nil {
+		t.Error("VerifyConnectionSCTs()=nil, want error")
+	}
+}
+
+func TestVerifyConnectionSCTsWithCache(t *testing.T) {
+	logKey, err := base64.StdEncoding.DecodeString(testdata.LogPublicKeyB64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logID := sha256.Sum256(logKey)
+	ll := &loglist3.LogList{Operators: []*loglist3.Operator{{Name: "Test", Logs: []*loglist3.Log{{Key: logKey, LogID: logID[:]}}}}}
+	cs := &gotls.ConnectionState{PeerCertificates: goCerts(t, testdata.TestEmbeddedCertPEM, testdata.CACertPEM)}
+
+	cache := NewVerificationCache(10)
+	for i := 0; i < 2; i++ {
+		got, err := VerifyConnectionSCTsWithCache(cs, ll, nil, cache)
+		if err != nil {
+			t.Fatalf("VerifyConnectionSCTsWithCache()=%v", err)
+		}
+		if len(got.Verified()) != 1 {
+			t.Errorf("VerifyConnectionSCTsWithCache() verified %d SCTs, want 1: %+v", len(got.Verified()), got.Results)
+		}
+	}
+	if hits, misses := cache.Stats(); hits != 1 || misses != 1 {
+		t.Errorf("Stats()=%d,%d, want 1,1", hits, misses)
+	}
+}
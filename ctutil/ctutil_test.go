@@ -15,12 +15,20 @@
 package ctutil
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"encoding/base64"
+	"math/big"
 	"testing"
+	"time"
 
 	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
 	"github.com/RarimoVoting/certificate-transparency-go/testdata"
 	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
 	"github.com/RarimoVoting/certificate-transparency-go/x509util"
 )
 
@@ -359,3 +367,152 @@ func TestContainsSCT(t *testing.T) {
 		})
 	}
 }
+
+// issue creates a certificate from template for key, signed by parent with
+// parentKey, or self-signed if parent is nil.
+func issue(t *testing.T, template, parent *x509.Certificate, parentKey, key *ecdsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return cert
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// TestLeafHashPrecertChains checks that the leaf hash of a precertificate
+// matches that of the final certificate embedding its SCT, whatever the
+// position of the poison extension and whether the precertificate was
+// signed by a precertificate signing certificate.
+func TestLeafHashPrecertChains(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Root CA"},
+		NotBefore:             now,
+		NotAfter:              now.Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		SubjectKeyId:          []byte{1, 1, 1, 1},
+	}
+	rootKey, preIssuerKey, leafKey := newKey(t), newKey(t), newKey(t)
+	root := issue(t, rootTemplate, nil, nil, rootKey)
+	preIssuer := issue(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "Precertificate Signing"},
+		NotBefore:             now,
+		NotAfter:              now.Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCertificateTransparency},
+		SubjectKeyId:          []byte{2, 2, 2, 2},
+	}, root, rootKey, preIssuerKey)
+
+	sct := &ct.SignedCertificateTimestamp{
+		SCTVersion: ct.V1,
+		Timestamp:  uint64(now.UnixMilli()),
+		Signature: ct.DigitallySigned{
+			Algorithm: tls.SignatureAndHashAlgorithm{Hash: tls.SHA256, Signature: tls.ECDSA},
+			Signature: []byte{1},
+		},
+	}
+	sctData, err := tls.Marshal(*sct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sctList, err := tls.Marshal(x509.SignedCertificateTimestampList{SCTList: []x509.SerializedSCT{{Val: sctData}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sctListValue, err := asn1.Marshal(sctList)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	poison := pkix.Extension{Id: x509.OIDExtensionCTPoison, Critical: true, Value: asn1.NullBytes}
+	sctExt := pkix.Extension{Id: x509.OIDExtensionCTSCT, Value: sctListValue}
+	other := pkix.Extension{Id: asn1.ObjectIdentifier{1, 2, 3, 4}, Value: []byte{0x05, 0x00}}
+	for _, test := range []struct {
+		desc         string
+		usePreIssuer bool
+		poisonAt     int
+	}{
+		{desc: "root-poison-first", poisonAt: 0},
+		{desc: "root-poison-middle", poisonAt: 1},
+		{desc: "root-poison-last", poisonAt: 2},
+		{desc: "pre-issuer-poison-first", usePreIssuer: true, poisonAt: 0},
+		{desc: "pre-issuer-poison-middle", usePreIssuer: true, poisonAt: 1},
+		{desc: "pre-issuer-poison-last", usePreIssuer: true, poisonAt: 2},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			exts := func(ct pkix.Extension) []pkix.Extension {
+				all := []pkix.Extension{other, other, other}
+				all[1].Id = asn1.ObjectIdentifier{1, 2, 3, 5}
+				all = append(all[:test.poisonAt], append([]pkix.Extension{ct}, all[test.poisonAt:]...)...)
+				return all
+			}
+			template := &x509.Certificate{
+				SerialNumber: big.NewInt(3),
+				Subject:      pkix.Name{CommonName: "leaf"},
+				DNSNames:     []string{"example.com"},
+				NotBefore:    now,
+				NotAfter:     now.Add(time.Hour),
+			}
+			template.ExtraExtensions = exts(sctExt)
+			final := issue(t, template, root, rootKey, leafKey)
+
+			template.ExtraExtensions = exts(poison)
+			precertChain := []*x509.Certificate{nil, root}
+			if test.usePreIssuer {
+				precertChain = []*x509.Certificate{nil, preIssuer, root}
+				precertChain[0] = issue(t, template, preIssuer, preIssuerKey, leafKey)
+			} else {
+				precertChain[0] = issue(t, template, root, rootKey, leafKey)
+			}
+
+			want, err := LeafHash(precertChain, sct, false)
+			if err != nil {
+				t.Fatalf("LeafHash(precert)=%v", err)
+			}
+			for _, chain := range [][]*x509.Certificate{{final, root}, {final, preIssuer, root}} {
+				got, err := LeafHash(chain, sct, true)
+				if err != nil {
+					t.Fatalf("LeafHash(embedded)=%v", err)
+				}
+				if got != want {
+					t.Errorf("LeafHash(embedded, chain of %d)=%x, want %x", len(chain), got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("pre-issuer-wrong-issuer", func(t *testing.T) {
+		precert := issue(t, &x509.Certificate{
+			SerialNumber:    big.NewInt(4),
+			Subject:         pkix.Name{CommonName: "leaf"},
+			NotBefore:       now,
+			NotAfter:        now.Add(time.Hour),
+			ExtraExtensions: []pkix.Extension{poison},
+		}, preIssuer, preIssuerKey, leafKey)
+		if _, err := LeafHash([]*x509.Certificate{precert, preIssuer, precert}, sct, false); err == nil {
+			t.Error("LeafHash(pre-issuer with wrong issuer)=nil, want error")
+		}
+	})
+}
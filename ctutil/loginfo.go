@@ -43,6 +43,7 @@ type LogInfo struct {
 
 	mu      sync.RWMutex
 	lastSTH *ct.SignedTreeHead
+	storage STHStorage
 }
 
 // NewLogInfo builds a LogInfo object based on a log list entry.
@@ -114,6 +115,38 @@ func (li *LogInfo) SetSTH(sth *ct.SignedTreeHead) {
 	li.lastSTH = sth
 }
 
+// UseSTHStorage makes the log record the STHs it fetches in st, and sets the
+// last STH known for the log to the latest one recorded in st, if any.
+func (li *LogInfo) UseSTHStorage(ctx context.Context, st STHStorage) error {
+	sth, err := LatestSTH(ctx, st, sha256.Sum256(li.PublicKey))
+	if err != nil {
+		return fmt.Errorf("failed to load STHs for %q log: %v", li.Description, err)
+	}
+	li.mu.Lock()
+	defer li.mu.Unlock()
+	li.storage = st
+	if sth != nil && (li.lastSTH == nil || sth.TreeSize > li.lastSTH.TreeSize) {
+		li.lastSTH = sth
+	}
+	return nil
+}
+
+// RecordSTH sets the last STH known for the log, and records it in the
+// log's STH storage, if any.
+func (li *LogInfo) RecordSTH(ctx context.Context, sth *ct.SignedTreeHead) error {
+	li.SetSTH(sth)
+	li.mu.RLock()
+	st := li.storage
+	li.mu.RUnlock()
+	if st == nil {
+		return nil
+	}
+	if err := st.AddSTH(ctx, sha256.Sum256(li.PublicKey), sth); err != nil {
+		return fmt.Errorf("failed to record STH for %q log: %v", li.Description, err)
+	}
+	return nil
+}
+
 // VerifySCTSignature checks the signature in the SCT matches the given leaf (adjusted for the
 // timestamp in the SCT) and log.
 func (li *LogInfo) VerifySCTSignature(sct ct.SignedCertificateTimestamp, leaf ct.MerkleTreeLeaf) error {
@@ -135,7 +168,9 @@ func (li *LogInfo) VerifyInclusionLatest(ctx context.Context, leaf ct.MerkleTree
 		if err != nil {
 			return -1, fmt.Errorf("failed to get current STH for %q log: %v", li.Description, err)
 		}
-		li.SetSTH(sth)
+		if err := li.RecordSTH(ctx, sth); err != nil {
+			return -1, err
+		}
 	}
 	return li.VerifyInclusionAt(ctx, leaf, timestamp, sth.TreeSize, sth.SHA256RootHash[:])
 }
@@ -148,7 +183,9 @@ func (li *LogInfo) VerifyInclusion(ctx context.Context, leaf ct.MerkleTreeLeaf,
 	if err != nil {
 		return -1, fmt.Errorf("failed to get current STH for %q log: %v", li.Description, err)
 	}
-	li.SetSTH(sth)
+	if err := li.RecordSTH(ctx, sth); err != nil {
+		return -1, err
+	}
 	return li.VerifyInclusionAt(ctx, leaf, timestamp, sth.TreeSize, sth.SHA256RootHash[:])
 }
 
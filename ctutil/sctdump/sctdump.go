@@ -0,0 +1,297 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// sctdump extracts the SCTs (Signed Certificate Timestamps) delivered for a
+// certificate through all three channels of RFC 6962 s3.3 (the TLS
+// extension, the stapled OCSP response and the certificate itself), verifies
+// them against a log list, optionally checks their inclusion in the logs,
+// and prints a JSON report per target.
+//
+// Each argument is either a file or a TLS endpoint (host[:port] or https URL)
+// to connect to. Files hold PEM blocks: the certificate chain, leaf first,
+// and optionally an "OCSP RESPONSE" block stapled for the leaf.
+package main
+
+import (
+	"context"
+	gotls "crypto/tls"
+	gox509 "crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/ctpolicy"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+	"k8s.io/klog/v2"
+)
+
+var (
+	logList        = flag.String("log_list", loglist3.AllLogListURL, "Location of master CT log list (URL or filename)")
+	deadline       = flag.Duration("deadline", 30*time.Second, "Timeout deadline for HTTP requests and TLS connections")
+	checkInclusion = flag.Bool("check_inclusion", false, "Whether to fetch and verify inclusion proofs of the SCTs which verified")
+	policyName     = flag.String("policy", "", "CT policy to check the verified SCTs against: chrome, apple, or empty for none")
+	policyConfig   = flag.String("policy_config", "", "If set, file of a configurable CT policy to check the verified SCTs against, instead of --policy")
+	ocspFile       = flag.String("ocsp_response", "", "If set, file of a DER OCSP response to treat as stapled for the certificate of each file argument")
+	pretty         = flag.Bool("pretty", false, "Indent the JSON reports")
+)
+
+// inclusionReport is the result of the inclusion check of an SCT.
+type inclusionReport struct {
+	LeafIndex *int64 `json:"leaf_index,omitempty"`
+	// Pending is set if the SCT is not included yet, but is younger than
+	// the MMD of its log.
+	Pending bool   `json:"pending,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// sctReport describes one SCT.
+type sctReport struct {
+	Source         string           `json:"source"`
+	Index          int              `json:"index"`
+	LogID          []byte           `json:"log_id,omitempty"`
+	LogDescription string           `json:"log_description,omitempty"`
+	LogURL         string           `json:"log_url,omitempty"`
+	Timestamp      uint64           `json:"timestamp,omitempty"`
+	Time           *time.Time       `json:"time,omitempty"`
+	Valid          bool             `json:"valid"`
+	Error          string           `json:"error,omitempty"`
+	Inclusion      *inclusionReport `json:"inclusion,omitempty"`
+}
+
+// report describes the SCTs of a target.
+type report struct {
+	Target  string      `json:"target"`
+	Subject string      `json:"subject,omitempty"`
+	Issuer  string      `json:"issuer,omitempty"`
+	Serial  string      `json:"serial,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	SCTs    []sctReport `json:"scts"`
+	Policy  string      `json:"policy,omitempty"`
+	// PolicyError is empty if the verified SCTs satisfy Policy.
+	PolicyError string `json:"policy_error,omitempty"`
+}
+
+// failed reports whether any SCT of r failed to verify, or is not included
+// in its log past its MMD.
+func (r *report) failed() bool {
+	if len(r.Error) > 0 || len(r.PolicyError) > 0 {
+		return true
+	}
+	for _, s := range r.SCTs {
+		if !s.Valid || (s.Inclusion != nil && len(s.Inclusion.Error) > 0 && !s.Inclusion.Pending) {
+			return true
+		}
+	}
+	return false
+}
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	ctx := context.Background()
+	hc := &http.Client{Timeout: *deadline}
+
+	llData, err := x509util.ReadFileOrURL(*logList, hc)
+	if err != nil {
+		klog.Exitf("Failed to read log list: %v", err)
+	}
+	ll, err := loglist3.NewFromJSON(llData)
+	if err != nil {
+		klog.Exitf("Failed to parse log list: %v", err)
+	}
+	var policy ctpolicy.CTPolicy
+	switch {
+	case len(*policyConfig) > 0:
+		if policy, err = ctpolicy.LoadPolicyConfig(*policyConfig); err != nil {
+			klog.Exitf("Failed to load policy: %v", err)
+		}
+	case *policyName == "chrome":
+		policy = ctpolicy.ChromeCTPolicy{}
+	case *policyName == "apple":
+		policy = ctpolicy.AppleCTPolicy{}
+	case len(*policyName) > 0:
+		klog.Exitf("Unknown policy %q", *policyName)
+	}
+	var ocspRsp []byte
+	if len(*ocspFile) > 0 {
+		if ocspRsp, err = os.ReadFile(*ocspFile); err != nil {
+			klog.Exitf("Failed to read OCSP response: %v", err)
+		}
+	}
+
+	d := &dumper{ll: ll, policy: policy, hc: hc, logs: make(map[string]*ctutil.LogInfo)}
+	enc := json.NewEncoder(os.Stdout)
+	if *pretty {
+		enc.SetIndent("", "  ")
+	}
+	failed := false
+	for _, arg := range flag.Args() {
+		var cs *gotls.ConnectionState
+		if _, statErr := os.Stat(arg); statErr == nil {
+			cs, err = fileConnectionState(arg, ocspRsp)
+		} else {
+			cs, err = dialConnectionState(arg)
+		}
+		r := &report{Target: arg, SCTs: []sctReport{}}
+		if err != nil {
+			r.Error = err.Error()
+		} else {
+			d.check(ctx, cs, r)
+		}
+		if err := enc.Encode(r); err != nil {
+			klog.Exitf("Failed to write report: %v", err)
+		}
+		failed = failed || r.failed()
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// fileConnectionState returns the state of a connection on which the
+// certificates and OCSP response of the PEM file at path were delivered.
+// If the file holds no OCSP response, ocspRsp is used instead.
+func fileConnectionState(path string, ocspRsp []byte) (*gotls.ConnectionState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	cs := &gotls.ConnectionState{OCSPResponse: ocspRsp}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case "CERTIFICATE":
+			// Only the DER of the certificates is used.
+			cs.PeerCertificates = append(cs.PeerCertificates, &gox509.Certificate{Raw: block.Bytes})
+		case "OCSP RESPONSE":
+			cs.OCSPResponse = block.Bytes
+		}
+	}
+	if len(cs.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return cs, nil
+}
+
+// dialConnectionState connects to the TLS endpoint target, and returns the
+// state of the connection.
+func dialConnectionState(target string) (*gotls.ConnectionState, error) {
+	host := target
+	if strings.Contains(target, "://") {
+		u, err := url.Parse(target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse URL: %v", err)
+		}
+		if u.Scheme != "https" {
+			return nil, errors.New("non-https URL provided")
+		}
+		host = u.Host
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "443")
+	}
+	dialer := net.Dialer{Timeout: *deadline}
+	// The peer is not verified, so that the SCTs of any certificate can be
+	// dumped. Go clients request both SCTs and OCSP stapling.
+	conn, err := gotls.DialWithDialer(&dialer, "tcp", host, &gotls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %q: %v", host, err)
+	}
+	defer conn.Close()
+	cs := conn.ConnectionState()
+	return &cs, nil
+}
+
+// dumper checks the SCTs of connections.
+type dumper struct {
+	ll     *loglist3.LogList
+	policy ctpolicy.CTPolicy
+	hc     *http.Client
+	// logs holds the clients of the logs checked for inclusion, by URL.
+	logs map[string]*ctutil.LogInfo
+}
+
+// check fills r with the results of the verification of the SCTs of cs.
+func (d *dumper) check(ctx context.Context, cs *gotls.ConnectionState, r *report) {
+	if leaf, err := x509.ParseCertificate(cs.PeerCertificates[0].Raw); !x509.IsFatal(err) {
+		r.Subject = leaf.Subject.String()
+		r.Issuer = leaf.Issuer.String()
+		r.Serial = leaf.SerialNumber.Text(16)
+	}
+	scts, err := ctutil.VerifyConnectionSCTs(cs, d.ll, d.policy)
+	if err != nil {
+		r.Error = err.Error()
+		return
+	}
+	if d.policy != nil {
+		r.Policy = d.policy.Name()
+		if scts.PolicyErr != nil {
+			r.PolicyError = scts.PolicyErr.Error()
+		}
+	}
+	for _, res := range scts.Results {
+		s := sctReport{Source: res.Source.String(), Index: res.Index, Valid: res.Err == nil}
+		if res.Err != nil {
+			s.Error = res.Err.Error()
+		}
+		if res.SCT != nil {
+			s.LogID = res.SCT.LogID.KeyID[:]
+			s.Timestamp = res.SCT.Timestamp
+			t := ct.TimestampToTime(res.SCT.Timestamp).UTC()
+			s.Time = &t
+		}
+		if res.Log != nil {
+			s.LogDescription = res.Log.Description
+			s.LogURL = res.Log.URL
+		}
+		if *checkInclusion && res.Err == nil && res.Leaf != nil {
+			s.Inclusion = d.inclusion(ctx, res)
+		}
+		r.SCTs = append(r.SCTs, s)
+	}
+}
+
+// inclusion checks the inclusion of the verified SCT of res in its log.
+func (d *dumper) inclusion(ctx context.Context, res ctutil.SCTResult) *inclusionReport {
+	li := d.logs[res.Log.URL]
+	if li == nil {
+		var err error
+		if li, err = ctutil.NewLogInfo(res.Log, d.hc); err != nil {
+			return &inclusionReport{Error: fmt.Sprintf("failed to create client for log: %v", err)}
+		}
+		d.logs[res.Log.URL] = li
+	}
+	index, err := li.VerifyInclusion(ctx, *res.Leaf, res.SCT.Timestamp)
+	if err != nil {
+		age := time.Since(ct.TimestampToTime(res.SCT.Timestamp))
+		return &inclusionReport{Error: err.Error(), Pending: age < li.MMD}
+	}
+	return &inclusionReport{LeafIndex: &index}
+}
@@ -0,0 +1,399 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	gotls "crypto/tls"
+	gox509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/crypto/ocsp"
+)
+
+const testLogURL = "https://ct.example.com/"
+
+// testDumper returns a dumper whose log list holds the test log.
+func testDumper(t *testing.T) *dumper {
+	t.Helper()
+	logKey, err := base64.StdEncoding.DecodeString(testdata.LogPublicKeyB64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logID := sha256.Sum256(logKey)
+	ll := &loglist3.LogList{Operators: []*loglist3.Operator{{Name: "Test", Logs: []*loglist3.Log{
+		{Description: "Test Log", URL: testLogURL, Key: logKey, LogID: logID[:]},
+	}}}}
+	return &dumper{ll: ll, logs: make(map[string]*ctutil.LogInfo)}
+}
+
+func pemDER(t *testing.T, pemData string) []byte {
+	t.Helper()
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		t.Fatal("failed to decode PEM")
+	}
+	return block.Bytes
+}
+
+// ocspResponse returns an OCSP response for the certificate of certDER
+// holding the given SCTs.
+func ocspResponse(t *testing.T, certDER []byte, scts ...[]byte) []byte {
+	t.Helper()
+	cert, err := gox509.ParseCertificate(certDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &gox509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "OCSP Responder"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := gox509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responder, err := gox509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var list x509.SignedCertificateTimestampList
+	for _, sct := range scts {
+		list.SCTList = append(list.SCTList, x509.SerializedSCT{Val: sct})
+	}
+	tlsList, err := tls.Marshal(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extValue, err := asn1.Marshal(tlsList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsp, err := ocsp.CreateResponse(responder, responder, ocsp.Response{
+		Status:          ocsp.Good,
+		SerialNumber:    cert.SerialNumber,
+		ThisUpdate:      time.Now(),
+		ExtraExtensions: []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 5}, Value: extValue}},
+	}, key)
+	if err != nil {
+		t.Fatalf("failed to create OCSP response: %v", err)
+	}
+	return rsp
+}
+
+// writePEM writes the blocks to a file, and returns its path.
+func writePEM(t *testing.T, blocks ...*pem.Block) string {
+	t.Helper()
+	var data []byte
+	for _, b := range blocks {
+		data = append(data, pem.EncodeToMemory(b)...)
+	}
+	path := filepath.Join(t.TempDir(), "chain.pem")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func certBlock(der []byte) *pem.Block { return &pem.Block{Type: "CERTIFICATE", Bytes: der} }
+
+func ocspBlock(der []byte) *pem.Block { return &pem.Block{Type: "OCSP RESPONSE", Bytes: der} }
+
+// verifiedReport returns the report of sct verified against the test log.
+func verifiedReport(source ctutil.SCTSource, sct *ct.SignedCertificateTimestamp) sctReport {
+	return sctReport{
+		Source:         source.String(),
+		LogID:          sct.LogID.KeyID[:],
+		LogDescription: "Test Log",
+		LogURL:         testLogURL,
+		Timestamp:      sct.Timestamp,
+		Valid:          true,
+	}
+}
+
+// unverifiedReport returns the report of the SCT at index in the list of
+// source, which is of the test log but fails to verify.
+func unverifiedReport(source ctutil.SCTSource, index int, sct *ct.SignedCertificateTimestamp) sctReport {
+	r := verifiedReport(source, sct)
+	r.Index = index
+	r.Valid = false
+	return r
+}
+
+// embeddedSCT returns the single SCT embedded in the certificate of der.
+func embeddedSCT(t *testing.T, der []byte) *ct.SignedCertificateTimestamp {
+	t.Helper()
+	scts, err := x509util.ParseSCTsFromCertificate(der)
+	if err != nil || len(scts) != 1 {
+		t.Fatalf("ParseSCTsFromCertificate()=%d SCTs, %v; want 1 SCT", len(scts), err)
+	}
+	return scts[0]
+}
+
+// checkSCTs compares the SCT reports of r to want, ignoring the text of
+// the errors but not whether there is one.
+func checkSCTs(t *testing.T, r *report, want []sctReport) {
+	t.Helper()
+	for i, s := range r.SCTs {
+		if s.Valid == (len(s.Error) > 0) {
+			t.Errorf("SCTs[%d] has Valid=%v and Error=%q", i, s.Valid, s.Error)
+		}
+		if s.Time != nil && !s.Time.Equal(ct.TimestampToTime(s.Timestamp)) {
+			t.Errorf("SCTs[%d].Time=%v, want time of timestamp %d", i, s.Time, s.Timestamp)
+		}
+	}
+	if diff := cmp.Diff(want, r.SCTs, cmpopts.IgnoreFields(sctReport{}, "Time", "Error"), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("SCT reports diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestDumpFile(t *testing.T) {
+	ctx := context.Background()
+	caDER := pemDER(t, testdata.CACertPEM)
+	certDER := pemDER(t, testdata.TestCertPEM)
+	embeddedDER := pemDER(t, testdata.TestEmbeddedCertPEM)
+	invalidEmbeddedDER := pemDER(t, testdata.TestInvalidEmbeddedCertPEM)
+
+	embeddedCertSCT := embeddedSCT(t, embeddedDER)
+	invalidEmbeddedSCT := embeddedSCT(t, invalidEmbeddedDER)
+	var certSCT ct.SignedCertificateTimestamp
+	if _, err := tls.Unmarshal(testdata.TestCertProof, &certSCT); err != nil {
+		t.Fatal(err)
+	}
+	ocspRsp := ocspResponse(t, certDER, testdata.TestCertProof)
+	ocspReport := verifiedReport(ctutil.SCTFromOCSP, &certSCT)
+
+	for _, test := range []struct {
+		desc    string
+		path    string
+		ocspRsp []byte
+		wantErr bool
+		want    []sctReport
+		// wantFailed is whether the report counts as failed.
+		wantFailed bool
+	}{
+		{
+			desc: "embedded",
+			path: writePEM(t, certBlock(embeddedDER), certBlock(caDER)),
+			want: []sctReport{verifiedReport(ctutil.SCTFromEmbedded, embeddedCertSCT)},
+		},
+		{
+			desc:       "embedded-invalid",
+			path:       writePEM(t, certBlock(invalidEmbeddedDER), certBlock(caDER)),
+			want:       []sctReport{unverifiedReport(ctutil.SCTFromEmbedded, 0, invalidEmbeddedSCT)},
+			wantFailed: true,
+		},
+		{
+			desc: "embedded-no-issuer",
+			path: writePEM(t, certBlock(embeddedDER)),
+			// The SCT cannot be verified without the issuer of the
+			// certificate.
+			want:       []sctReport{unverifiedReport(ctutil.SCTFromEmbedded, 0, embeddedCertSCT)},
+			wantFailed: true,
+		},
+		{
+			desc: "ocsp-in-file",
+			path: writePEM(t, certBlock(certDER), certBlock(caDER), ocspBlock(ocspRsp)),
+			want: []sctReport{ocspReport},
+		},
+		{
+			desc:    "ocsp-flag",
+			path:    writePEM(t, certBlock(certDER), certBlock(caDER)),
+			ocspRsp: ocspRsp,
+			want:    []sctReport{ocspReport},
+		},
+		{
+			desc:    "ocsp-in-file-overrides-flag",
+			path:    writePEM(t, certBlock(certDER), certBlock(caDER), ocspBlock(ocspRsp)),
+			ocspRsp: []byte("bad"),
+			want:    []sctReport{ocspReport},
+		},
+		{
+			desc:       "ocsp-malformed",
+			path:       writePEM(t, certBlock(certDER), certBlock(caDER), ocspBlock([]byte("bad"))),
+			want:       []sctReport{{Source: "OCSP response", Index: -1}},
+			wantFailed: true,
+		},
+		{
+			desc: "no-scts",
+			path: writePEM(t, certBlock(certDER), certBlock(caDER)),
+		},
+		{
+			desc:    "no-certs",
+			path:    writePEM(t, ocspBlock(ocspRsp)),
+			wantErr: true,
+		},
+		{
+			desc:    "not-pem",
+			path:    writePEM(t),
+			wantErr: true,
+		},
+		{
+			desc:    "missing-file",
+			path:    filepath.Join(t.TempDir(), "missing.pem"),
+			wantErr: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			cs, err := fileConnectionState(test.path, test.ocspRsp)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("fileConnectionState()=%v, want error? %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			r := &report{Target: test.path, SCTs: []sctReport{}}
+			testDumper(t).check(ctx, cs, r)
+			if r.Error != "" {
+				t.Fatalf("check() gave report error %q", r.Error)
+			}
+			checkSCTs(t, r, test.want)
+			if got := r.failed(); got != test.wantFailed {
+				t.Errorf("failed()=%v, want %v", got, test.wantFailed)
+			}
+		})
+	}
+}
+
+func TestDumpFileReportsLeaf(t *testing.T) {
+	path := writePEM(t, certBlock(pemDER(t, testdata.TestCertPEM)))
+	cs, err := fileConnectionState(path, nil)
+	if err != nil {
+		t.Fatalf("fileConnectionState()=%v", err)
+	}
+	r := &report{Target: path, SCTs: []sctReport{}}
+	testDumper(t).check(context.Background(), cs, r)
+	want := &report{
+		Target:  path,
+		Subject: "O=Certificate Transparency,L=Erw Wen,ST=Wales,C=GB",
+		Issuer:  "O=Certificate Transparency CA,L=Erw Wen,ST=Wales,C=GB",
+		Serial:  "6",
+		SCTs:    []sctReport{},
+	}
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Errorf("report diff (-want +got):\n%s", diff)
+	}
+}
+
+// tlsServer returns a TLS server whose self-signed certificate is delivered
+// with the given SCTs in the TLS extension.
+func tlsServer(t *testing.T, scts ...[]byte) *httptest.Server {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &gox509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "Test Server"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := gox509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewUnstartedServer(http.NotFoundHandler())
+	// Clients hang up after the handshake.
+	ts.Config.ErrorLog = log.New(io.Discard, "", 0)
+	ts.TLS = &gotls.Config{Certificates: []gotls.Certificate{{
+		Certificate:                 [][]byte{der},
+		PrivateKey:                  key,
+		SignedCertificateTimestamps: scts,
+	}}}
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestDumpTLSEndpoint(t *testing.T) {
+	ctx := context.Background()
+	var certSCT ct.SignedCertificateTimestamp
+	if _, err := tls.Unmarshal(testdata.TestCertProof, &certSCT); err != nil {
+		t.Fatal(err)
+	}
+	ts := tlsServer(t, testdata.TestCertProof, []byte("bad"))
+
+	for _, test := range []struct {
+		desc    string
+		target  string
+		wantErr bool
+	}{
+		{desc: "host-port", target: ts.Listener.Addr().String()},
+		{desc: "https-url", target: ts.URL},
+		{desc: "http-url", target: "http://" + ts.Listener.Addr().String(), wantErr: true},
+		{desc: "bad-url", target: "https://%zz", wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			cs, err := dialConnectionState(test.target)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("dialConnectionState()=%v, want error? %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			r := &report{Target: test.target, SCTs: []sctReport{}}
+			testDumper(t).check(ctx, cs, r)
+			if r.Error != "" {
+				t.Fatalf("check() gave report error %q", r.Error)
+			}
+			if got, want := r.Subject, "CN=Test Server"; got != want {
+				t.Errorf("Subject=%q, want %q", got, want)
+			}
+			// The first SCT is decoded, but is for another certificate,
+			// and the second is malformed.
+			checkSCTs(t, r, []sctReport{
+				unverifiedReport(ctutil.SCTFromTLSExtension, 0, &certSCT),
+				{Source: "TLS extension", Index: 1},
+			})
+			if !r.failed() {
+				t.Error("failed()=false, want true")
+			}
+		})
+	}
+}
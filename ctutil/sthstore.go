@@ -0,0 +1,216 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/internal/journal"
+)
+
+// STHStorage persists the STHs observed for logs, so that they survive
+// restarts and can later be audited for consistency. Logs are identified by
+// the SHA-256 hash of their public key. Implementations must be safe for
+// concurrent use.
+type STHStorage interface {
+	// AddSTH records an STH observed for the log. Recording the same STH
+	// again has no effect.
+	AddSTH(ctx context.Context, logID [sha256.Size]byte, sth *ct.SignedTreeHead) error
+	// STHs returns the STHs recorded for the log, ordered by tree size then
+	// timestamp.
+	STHs(ctx context.Context, logID [sha256.Size]byte) ([]*ct.SignedTreeHead, error)
+}
+
+// LatestSTH returns the STH recorded in st for the log with the largest tree
+// size, and the latest timestamp among those, or nil if there is none.
+func LatestSTH(ctx context.Context, st STHStorage, logID [sha256.Size]byte) (*ct.SignedTreeHead, error) {
+	sths, err := st.STHs(ctx, logID)
+	if err != nil || len(sths) == 0 {
+		return nil, err
+	}
+	return sths[len(sths)-1], nil
+}
+
+// sthKey identifies a distinct STH of a log.
+type sthKey struct {
+	treeSize  uint64
+	timestamp uint64
+	rootHash  ct.SHA256Hash
+}
+
+func keyOf(sth *ct.SignedTreeHead) sthKey {
+	return sthKey{treeSize: sth.TreeSize, timestamp: sth.Timestamp, rootHash: sth.SHA256RootHash}
+}
+
+func sortSTHs(sths []*ct.SignedTreeHead) {
+	sort.SliceStable(sths, func(i, j int) bool {
+		if sths[i].TreeSize != sths[j].TreeSize {
+			return sths[i].TreeSize < sths[j].TreeSize
+		}
+		return sths[i].Timestamp < sths[j].Timestamp
+	})
+}
+
+// fileSTHRecord is a line of the journal of a FileSTHStorage.
+type fileSTHRecord struct {
+	LogID []byte             `json:"log_id"`
+	STH   *ct.SignedTreeHead `json:"sth"`
+}
+
+// FileSTHStorage is an STHStorage which appends the STHs of all logs to a
+// journal file of JSON lines, and keeps them in memory.
+type FileSTHStorage struct {
+	mu   sync.Mutex
+	j    *journal.Journal
+	sths map[[sha256.Size]byte][]*ct.SignedTreeHead
+	seen map[[sha256.Size]byte]map[sthKey]bool
+}
+
+// OpenFileSTHStorage opens the journal at path, creating it if needed, and
+// loads the STHs recorded in it. A partial last line, as left by a crash, is
+// discarded.
+func OpenFileSTHStorage(path string) (*FileSTHStorage, error) {
+	s := &FileSTHStorage{
+		sths: make(map[[sha256.Size]byte][]*ct.SignedTreeHead),
+		seen: make(map[[sha256.Size]byte]map[sthKey]bool),
+	}
+	j, err := journal.Open(path, func(data []byte) error {
+		var rec fileSTHRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		if len(rec.LogID) != sha256.Size || rec.STH == nil {
+			return errors.New("missing log ID or STH")
+		}
+		var logID [sha256.Size]byte
+		copy(logID[:], rec.LogID)
+		s.remember(logID, rec.STH)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, sths := range s.sths {
+		sortSTHs(sths)
+	}
+	s.j = j
+	return s, nil
+}
+
+// remember adds sth to the STHs in memory, unless it is there already.
+func (s *FileSTHStorage) remember(logID [sha256.Size]byte, sth *ct.SignedTreeHead) {
+	seen := s.seen[logID]
+	if seen == nil {
+		seen = make(map[sthKey]bool)
+		s.seen[logID] = seen
+	}
+	if !seen[keyOf(sth)] {
+		seen[keyOf(sth)] = true
+		s.sths[logID] = append(s.sths[logID], sth)
+	}
+}
+
+// AddSTH implements STHStorage.
+func (s *FileSTHStorage) AddSTH(_ context.Context, logID [sha256.Size]byte, sth *ct.SignedTreeHead) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen[logID][keyOf(sth)] {
+		return nil
+	}
+	if err := s.j.Append(fileSTHRecord{LogID: logID[:], STH: sth}); err != nil {
+		return fmt.Errorf("failed to write STH: %v", err)
+	}
+	s.remember(logID, sth)
+	sortSTHs(s.sths[logID])
+	return nil
+}
+
+// STHs implements STHStorage.
+func (s *FileSTHStorage) STHs(_ context.Context, logID [sha256.Size]byte) ([]*ct.SignedTreeHead, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*ct.SignedTreeHead(nil), s.sths[logID]...), nil
+}
+
+// Close syncs and closes the journal.
+func (s *FileSTHStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.j.Close()
+}
+
+// SQLiteSTHStorage is an STHStorage which keeps STHs in a table of an
+// SQLite database. The caller is responsible for loading the database
+// driver.
+type SQLiteSTHStorage struct {
+	db *sql.DB
+}
+
+// NewSQLiteSTHStorage creates an SQLiteSTHStorage using db, creating its
+// table if needed.
+func NewSQLiteSTHStorage(ctx context.Context, db *sql.DB) (*SQLiteSTHStorage, error) {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS observed_sths (logID BLOB, treeSize INTEGER, timestamp INTEGER, rootHash BLOB, sth BLOB, PRIMARY KEY (logID, treeSize, timestamp, rootHash))`); err != nil {
+		return nil, fmt.Errorf("failed to create STH table: %v", err)
+	}
+	return &SQLiteSTHStorage{db: db}, nil
+}
+
+// AddSTH implements STHStorage.
+func (s *SQLiteSTHStorage) AddSTH(ctx context.Context, logID [sha256.Size]byte, sth *ct.SignedTreeHead) error {
+	sthRaw, err := json.Marshal(sth)
+	if err != nil {
+		return fmt.Errorf("failed to marshal STH: %v", err)
+	}
+	// SQLite integers are signed 64-bit, as are tree sizes and timestamps in
+	// practice.
+	if _, err := s.db.ExecContext(ctx, `INSERT OR IGNORE INTO observed_sths (logID, treeSize, timestamp, rootHash, sth) VALUES (?, ?, ?, ?, ?)`,
+		logID[:], int64(sth.TreeSize), int64(sth.Timestamp), sth.SHA256RootHash[:], sthRaw); err != nil {
+		return fmt.Errorf("failed to write STH: %v", err)
+	}
+	return nil
+}
+
+// STHs implements STHStorage.
+func (s *SQLiteSTHStorage) STHs(ctx context.Context, logID [sha256.Size]byte) ([]*ct.SignedTreeHead, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT sth FROM observed_sths WHERE logID = ? ORDER BY treeSize, timestamp`, logID[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read STHs: %v", err)
+	}
+	defer rows.Close()
+	var sths []*ct.SignedTreeHead
+	for rows.Next() {
+		var sthRaw []byte
+		if err := rows.Scan(&sthRaw); err != nil {
+			return nil, fmt.Errorf("failed to read STH: %v", err)
+		}
+		var sth ct.SignedTreeHead
+		if err := json.Unmarshal(sthRaw, &sth); err != nil {
+			return nil, fmt.Errorf("failed to parse STH: %v", err)
+		}
+		sths = append(sths, &sth)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read STHs: %v", err)
+	}
+	return sths, nil
+}
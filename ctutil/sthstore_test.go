@@ -0,0 +1,178 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+
+	_ "github.com/mattn/go-sqlite3" // Load drivers for sqlite3
+)
+
+// testSTHStorage checks st, on which reopen returns a storage backed by the
+// same data.
+func testSTHStorage(t *testing.T, st STHStorage, reopen func() STHStorage) {
+	t.Helper()
+	ctx := context.Background()
+	log1, log2 := sha256.Sum256([]byte("log1")), sha256.Sum256([]byte("log2"))
+	for _, sth := range []*ct.SignedTreeHead{
+		{TreeSize: 20, Timestamp: 200},
+		{TreeSize: 10, Timestamp: 100},
+		{TreeSize: 20, Timestamp: 250},
+		{TreeSize: 20, Timestamp: 200}, // Duplicate.
+	} {
+		if err := st.AddSTH(ctx, log1, sth); err != nil {
+			t.Fatalf("AddSTH()=%v", err)
+		}
+	}
+	if err := st.AddSTH(ctx, log2, &ct.SignedTreeHead{TreeSize: 5, Timestamp: 50}); err != nil {
+		t.Fatalf("AddSTH()=%v", err)
+	}
+
+	check := func(st STHStorage) {
+		t.Helper()
+		sths, err := st.STHs(ctx, log1)
+		if err != nil {
+			t.Fatalf("STHs()=%v", err)
+		}
+		var got []uint64
+		for _, sth := range sths {
+			got = append(got, sth.Timestamp)
+		}
+		if want := []uint64{100, 200, 250}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+			t.Errorf("STHs() timestamps=%v, want %v", got, want)
+		}
+		latest, err := LatestSTH(ctx, st, log2)
+		if err != nil || latest == nil || latest.TreeSize != 5 {
+			t.Errorf("LatestSTH(log2)=%v,%v, want size 5", latest, err)
+		}
+		if latest, err := LatestSTH(ctx, st, sha256.Sum256([]byte("log3"))); err != nil || latest != nil {
+			t.Errorf("LatestSTH(log3)=%v,%v, want nil,nil", latest, err)
+		}
+	}
+	check(st)
+	check(reopen())
+}
+
+func TestFileSTHStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sths.jsonl")
+	st, err := OpenFileSTHStorage(path)
+	if err != nil {
+		t.Fatalf("OpenFileSTHStorage()=%v", err)
+	}
+	testSTHStorage(t, st, func() STHStorage {
+		if err := st.Close(); err != nil {
+			t.Fatalf("Close()=%v", err)
+		}
+		// A partial record left by a crash is discarded.
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.WriteString(`{"log_id":`); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		reopened, err := OpenFileSTHStorage(path)
+		if err != nil {
+			t.Fatalf("OpenFileSTHStorage()=%v", err)
+		}
+		t.Cleanup(func() { reopened.Close() })
+		return reopened
+	})
+}
+
+func TestSQLiteSTHStorage(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "sths.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	st, err := NewSQLiteSTHStorage(ctx, db)
+	if err != nil {
+		t.Fatalf("NewSQLiteSTHStorage()=%v", err)
+	}
+	testSTHStorage(t, st, func() STHStorage {
+		reopened, err := NewSQLiteSTHStorage(ctx, db)
+		if err != nil {
+			t.Fatalf("NewSQLiteSTHStorage()=%v", err)
+		}
+		return reopened
+	})
+}
+
+// sthClient is a CheckLogClient serving a fixed STH, and no proofs.
+type sthClient struct {
+	sth *ct.SignedTreeHead
+}
+
+func (c *sthClient) BaseURI() string { return "https://ct.example.com" }
+func (c *sthClient) GetSTH(context.Context) (*ct.SignedTreeHead, error) {
+	return c.sth, nil
+}
+
+func (c *sthClient) GetSTHConsistency(ctx context.Context, first, second uint64) ([][]byte, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *sthClient) GetProofByHash(ctx context.Context, hash []byte, treeSize uint64) (*ct.GetProofByHashResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestLogInfoSTHStorage(t *testing.T) {
+	ctx := context.Background()
+	st, err := OpenFileSTHStorage(filepath.Join(t.TempDir(), "sths.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	chain, err := x509util.CertificatesFromPEM([]byte(testdata.TestCertPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := ct.MerkleTreeLeafFromChain(chain, ct.X509LogEntryType, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sth := &ct.SignedTreeHead{TreeSize: 42, Timestamp: 1000}
+	li := &LogInfo{Description: "Test", Client: &sthClient{sth: sth}, PublicKey: []byte("key")}
+	if err := li.UseSTHStorage(ctx, st); err != nil {
+		t.Fatalf("UseSTHStorage()=%v", err)
+	}
+	// The proof fails, but the STH is recorded first.
+	if _, err := li.VerifyInclusion(ctx, *leaf, 0); err == nil {
+		t.Fatal("VerifyInclusion()=nil, want error")
+	}
+
+	// A new LogInfo for the same log starts from the recorded STH.
+	restarted := &LogInfo{Description: "Test", PublicKey: []byte("key")}
+	if err := restarted.UseSTHStorage(ctx, st); err != nil {
+		t.Fatalf("UseSTHStorage()=%v", err)
+	}
+	if got := restarted.LastSTH(); got == nil || got.TreeSize != 42 {
+		t.Errorf("LastSTH()=%v, want size 42", got)
+	}
+}
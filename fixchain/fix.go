@@ -57,7 +57,9 @@ func (fix *toFix) handleChain() ([][]*x509.Certificate, []*FixError) {
 		Intermediates:     intermediates,
 		Roots:             fix.roots,
 		DisableTimeChecks: true,
-		KeyUsages:         []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		// Chains are fixed for logs, which ignore policies.
+		DisablePolicyChecks: true,
+		KeyUsages:           []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
 	}
 
 	var retferrs []*FixError
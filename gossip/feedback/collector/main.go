@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The collector binary serves SCT feedback for the logs of a log list, and
+// records the valid SCTs reported in a journal file for later auditing.
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/RarimoVoting/certificate-transparency-go/gossip/feedback"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+	"k8s.io/klog/v2"
+)
+
+var (
+	httpEndpoint = flag.String("http_endpoint", "localhost:6967", "Endpoint for HTTP (host:port)")
+	logList      = flag.String("log_list", loglist3.LogListURL, "File or URL of the JSON list of logs whose SCTs are accepted")
+	journal      = flag.String("journal", "sct_feedback.jsonl", "File to record the valid SCTs reported in, as JSON lines")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	data, err := x509util.ReadFileOrURL(*logList, http.DefaultClient)
+	if err != nil {
+		klog.Exitf("Failed to read log list: %v", err)
+	}
+	ll, err := loglist3.NewFromJSON(data)
+	if err != nil {
+		klog.Exitf("Failed to parse log list: %v", err)
+	}
+	store, err := feedback.OpenFileStorage(*journal)
+	if err != nil {
+		klog.Exitf("Failed to open journal: %v", err)
+	}
+	c, err := feedback.NewCollectorFromLogList(ll, store)
+	if err != nil {
+		klog.Exitf("Failed to set up SCT feedback: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(feedback.FeedbackPath, c)
+	srv := &http.Server{Addr: *httpEndpoint, Handler: mux}
+	go func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		<-sigs
+		if err := srv.Shutdown(context.Background()); err != nil {
+			klog.Errorf("Failed to shut down: %v", err)
+		}
+	}()
+
+	klog.Infof("Serving SCT feedback on %s", *httpEndpoint)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		klog.Errorf("HTTP server exited: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		klog.Exitf("Failed to close journal: %v", err)
+	}
+}
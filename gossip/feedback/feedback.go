@@ -0,0 +1,202 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package feedback implements SCT feedback collection: TLS clients report the
+// SCTs and certificate chains they were served, and those whose signature
+// verifies against the key of a known log are stored, so that auditors can
+// later check that the logs incorporated the entries the SCTs promise.
+package feedback
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// FeedbackPath is the path on which SCT feedback is reported.
+	FeedbackPath = "/.well-known/ct-gossip/v1/sct-feedback"
+
+	// maxRequestSize limits the size of feedback requests.
+	maxRequestSize = 1 << 20
+)
+
+// ErrUnknownLog is returned for SCTs of logs the Collector does not know.
+var ErrUnknownLog = errors.New("unknown log")
+
+// Item is a chain served by a TLS server, with the SCTs served along with it.
+type Item struct {
+	// Chain holds the DER certificates of the chain, leaf first.
+	Chain [][]byte `json:"x509_chain"`
+	// SCTs holds the TLS encoding of each SCT.
+	SCTs [][]byte `json:"sct_data"`
+}
+
+// Feedback is the body of feedback requests.
+type Feedback struct {
+	Items []Item `json:"sct_feedback"`
+}
+
+// Collector validates reported SCTs against the keys of known logs, and
+// stores the valid ones. It implements http.Handler, serving reports of
+// Feedback with POST requests. It is safe for concurrent use.
+type Collector struct {
+	store Storage
+	now   func() time.Time
+
+	mu   sync.RWMutex
+	logs map[[sha256.Size]byte]*ct.SignatureVerifier
+}
+
+// NewCollector creates a Collector storing SCTs in store, which knows no
+// logs.
+func NewCollector(store Storage) *Collector {
+	return &Collector{store: store, now: time.Now, logs: make(map[[sha256.Size]byte]*ct.SignatureVerifier)}
+}
+
+// NewCollectorFromLogList creates a Collector storing SCTs in store, which
+// knows the RFC 6962 logs of ll.
+func NewCollectorFromLogList(ll *loglist3.LogList, store Storage) (*Collector, error) {
+	c := NewCollector(store)
+	for _, op := range ll.Operators {
+		for _, l := range op.Logs {
+			if err := c.AddLog(l.Key); err != nil {
+				return nil, fmt.Errorf("log %q: %v", l.URL, err)
+			}
+		}
+	}
+	return c, nil
+}
+
+// AddLog makes the log with the given DER-encoded public key known to the
+// Collector.
+func (c *Collector) AddLog(pubKeyDER []byte) error {
+	pubKey, err := x509.ParsePKIXPublicKey(pubKeyDER)
+	if err != nil {
+		return fmt.Errorf("failed to parse public key: %v", err)
+	}
+	verifier, err := ct.NewSignatureVerifier(pubKey)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logs[sha256.Sum256(pubKeyDER)] = verifier
+	return nil
+}
+
+// Check validates an SCT reported for chain, returning the observation to
+// store. The SCT is either embedded in the leaf certificate, in which case
+// the chain must hold its issuer, or issued for the leaf certificate itself.
+// Returns ErrUnknownLog if the log of the SCT is not known.
+func (c *Collector) Check(chain [][]byte, rawSCT []byte) (*Observation, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("empty chain")
+	}
+	certs := make([]*x509.Certificate, 0, len(chain))
+	for i, der := range chain {
+		cert, err := x509.ParseCertificate(der)
+		if x509.IsFatal(err) {
+			return nil, fmt.Errorf("failed to parse certificate %d: %v", i, err)
+		}
+		certs = append(certs, cert)
+	}
+	var sct ct.SignedCertificateTimestamp
+	if rest, err := tls.Unmarshal(rawSCT, &sct); err != nil {
+		return nil, fmt.Errorf("failed to parse SCT: %v", err)
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after SCT")
+	}
+
+	c.mu.RLock()
+	verifier, ok := c.logs[sct.LogID.KeyID]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownLog
+	}
+	embedded, err := ctutil.ContainsSCT(certs[0], &sct)
+	if err != nil {
+		return nil, err
+	}
+	if embedded && len(certs) < 2 {
+		return nil, errors.New("no issuer for embedded SCT")
+	}
+	if err := ctutil.VerifySCTWithVerifier(verifier, certs, &sct, embedded); err != nil {
+		return nil, fmt.Errorf("invalid SCT: %v", err)
+	}
+	leafHash, err := ctutil.LeafHash(certs, &sct, embedded)
+	if err != nil {
+		return nil, err
+	}
+	return &Observation{
+		LogID:    sct.LogID.KeyID,
+		SCT:      rawSCT,
+		Chain:    chain,
+		Embedded: embedded,
+		LeafHash: leafHash,
+		Received: c.now(),
+	}, nil
+}
+
+// Add validates an SCT reported for chain, and stores it if it is valid.
+func (c *Collector) Add(ctx context.Context, chain [][]byte, rawSCT []byte) error {
+	o, err := c.Check(chain, rawSCT)
+	if err != nil {
+		return err
+	}
+	return c.store.Add(ctx, o)
+}
+
+// ServeHTTP implements http.Handler. Invalid SCTs are ignored, as clients
+// cannot act upon their rejection.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	var req Feedback
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse SCT feedback: %v", err), http.StatusBadRequest)
+		return
+	}
+	for _, item := range req.Items {
+		for _, rawSCT := range item.SCTs {
+			o, err := c.Check(item.Chain, rawSCT)
+			if err != nil {
+				if !errors.Is(err, ErrUnknownLog) {
+					klog.V(1).Infof("Ignoring SCT feedback from %s: %v", r.RemoteAddr, err)
+				}
+				continue
+			}
+			if err := c.store.Add(r.Context(), o); err != nil {
+				klog.Errorf("Failed to store SCT feedback: %v", err)
+				http.Error(w, "failed to store SCT feedback", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feedback
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+)
+
+func chainDER(t *testing.T, chainPEM string) [][]byte {
+	t.Helper()
+	certs, err := x509util.CertificatesFromPEM([]byte(chainPEM))
+	if err != nil {
+		t.Fatalf("CertificatesFromPEM()=%v", err)
+	}
+	var chain [][]byte
+	for _, cert := range certs {
+		chain = append(chain, cert.Raw)
+	}
+	return chain
+}
+
+func newTestCollector(t *testing.T, store Storage) *Collector {
+	t.Helper()
+	der, err := base64.StdEncoding.DecodeString(testdata.LogPublicKeyB64)
+	if err != nil {
+		t.Fatalf("DecodeString()=%v", err)
+	}
+	c := NewCollector(store)
+	c.now = func() time.Time { return time.Unix(1700000000, 0) }
+	if err := c.AddLog(der); err != nil {
+		t.Fatalf("AddLog()=%v", err)
+	}
+	return c
+}
+
+func TestCollectorCheck(t *testing.T) {
+	c := newTestCollector(t, NewMemoryStorage())
+	certChain := chainDER(t, testdata.TestCertPEM+testdata.CACertPEM)
+	embeddedChain := chainDER(t, testdata.TestEmbeddedCertPEM+testdata.CACertPEM)
+
+	for _, test := range []struct {
+		name         string
+		chain        [][]byte
+		sct          []byte
+		wantErr      bool
+		wantEmbedded bool
+		wantLeafHash string
+	}{
+		{
+			name:         "cert",
+			chain:        certChain,
+			sct:          testdata.TestCertProof,
+			wantLeafHash: testdata.TestCertB64LeafHash,
+		},
+		{
+			name:         "embedded",
+			chain:        embeddedChain,
+			sct:          testdata.TestPreCertProof,
+			wantEmbedded: true,
+			wantLeafHash: testdata.TestPreCertB64LeafHash,
+		},
+		{name: "embedded-no-issuer", chain: embeddedChain[:1], sct: testdata.TestPreCertProof, wantErr: true},
+		{name: "wrong-cert", chain: embeddedChain, sct: testdata.TestCertProof, wantErr: true},
+		{name: "invalid-signature", chain: certChain, sct: testdata.TestInvalidProof, wantErr: true},
+		{name: "empty-chain", sct: testdata.TestCertProof, wantErr: true},
+		{name: "bad-cert", chain: [][]byte{[]byte("cert")}, sct: testdata.TestCertProof, wantErr: true},
+		{name: "bad-sct", chain: certChain, sct: []byte("sct"), wantErr: true},
+		{name: "trailing-data", chain: certChain, sct: append(append([]byte(nil), testdata.TestCertProof...), 0), wantErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			o, err := c.Check(test.chain, test.sct)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Check()=%v; want error: %t", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if o.Embedded != test.wantEmbedded {
+				t.Errorf("Check().Embedded=%t; want %t", o.Embedded, test.wantEmbedded)
+			}
+			if got := o.LeafHash.Base64String(); got != test.wantLeafHash {
+				t.Errorf("Check().LeafHash=%s; want %s", got, test.wantLeafHash)
+			}
+		})
+	}
+
+	if _, err := NewCollector(NewMemoryStorage()).Check(certChain, testdata.TestCertProof); !errors.Is(err, ErrUnknownLog) {
+		t.Errorf("Check() without logs=%v; want %v", err, ErrUnknownLog)
+	}
+}
+
+func TestCollectorServeHTTP(t *testing.T) {
+	store := NewMemoryStorage()
+	srv := httptest.NewServer(newTestCollector(t, store))
+	defer srv.Close()
+
+	certChain := chainDER(t, testdata.TestCertPEM+testdata.CACertPEM)
+	req, err := json.Marshal(Feedback{Items: []Item{
+		{Chain: certChain, SCTs: [][]byte{testdata.TestCertProof, testdata.TestInvalidProof, []byte("sct")}},
+		{Chain: chainDER(t, testdata.TestEmbeddedCertPEM+testdata.CACertPEM), SCTs: [][]byte{testdata.TestPreCertProof}},
+		{Chain: certChain, SCTs: [][]byte{testdata.TestCertProof}},
+	}})
+	if err != nil {
+		t.Fatalf("json.Marshal()=%v", err)
+	}
+
+	for _, test := range []struct {
+		method string
+		body   []byte
+		want   int
+	}{
+		{method: http.MethodPost, body: req, want: http.StatusOK},
+		{method: http.MethodPost, body: []byte("{"), want: http.StatusBadRequest},
+		{method: http.MethodGet, want: http.StatusMethodNotAllowed},
+	} {
+		req, err := http.NewRequest(test.method, srv.URL+FeedbackPath, bytes.NewReader(test.body))
+		if err != nil {
+			t.Fatalf("NewRequest()=%v", err)
+		}
+		rsp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s=%v", test.method, err)
+		}
+		rsp.Body.Close()
+		if rsp.StatusCode != test.want {
+			t.Errorf("%s status=%d; want %d", test.method, rsp.StatusCode, test.want)
+		}
+	}
+
+	obs, err := store.Observations(context.Background(), time.Time{})
+	if err != nil {
+		t.Fatalf("Observations()=%v", err)
+	}
+	if len(obs) != 2 {
+		t.Errorf("stored %d observations; want the 2 distinct valid SCTs", len(obs))
+	}
+}
+
+func TestFileStorage(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "feedback.jsonl")
+	s, err := OpenFileStorage(path)
+	if err != nil {
+		t.Fatalf("OpenFileStorage()=%v", err)
+	}
+	c := newTestCollector(t, s)
+	certChain := chainDER(t, testdata.TestCertPEM+testdata.CACertPEM)
+	for i := 0; i < 2; i++ {
+		if err := c.Add(ctx, certChain, testdata.TestCertProof); err != nil {
+			t.Fatalf("Add()=%v", err)
+		}
+	}
+	c.now = func() time.Time { return time.Unix(1800000000, 0) }
+	if err := c.Add(ctx, chainDER(t, testdata.TestEmbeddedCertPEM+testdata.CACertPEM), testdata.TestPreCertProof); err != nil {
+		t.Fatalf("Add()=%v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close()=%v", err)
+	}
+
+	s, err = OpenFileStorage(path)
+	if err != nil {
+		t.Fatalf("OpenFileStorage()=%v", err)
+	}
+	defer s.Close()
+	obs, err := s.Observations(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("Observations()=%v", err)
+	}
+	if len(obs) != 2 {
+		t.Fatalf("reopened storage has %d observations; want 2", len(obs))
+	}
+	if !bytes.Equal(obs[0].SCT, testdata.TestCertProof) || obs[0].LeafHash.Base64String() != testdata.TestCertB64LeafHash {
+		t.Errorf("reopened storage has observation %+v; want the SCT of the cert", obs[0])
+	}
+	if obs, err := s.Observations(ctx, time.Unix(1800000000, 0)); err != nil || len(obs) != 1 || !obs[0].Embedded {
+		t.Errorf("Observations(since)=%v,%v; want the embedded SCT", obs, err)
+	}
+}
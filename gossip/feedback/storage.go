@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package feedback
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/internal/journal"
+)
+
+// Observation is an SCT reported by a TLS client, along with the chain it
+// was served with. Only SCTs whose signature was verified are stored.
+type Observation struct {
+	// LogID is the ID of the log which issued the SCT.
+	LogID ct.SHA256Hash `json:"log_id"`
+	// SCT is the TLS encoding of the SCT.
+	SCT []byte `json:"sct"`
+	// Chain holds the DER certificates of the chain, leaf first.
+	Chain [][]byte `json:"chain"`
+	// Embedded tells whether the SCT is embedded in the leaf certificate, in
+	// which case the log holds a precertificate entry.
+	Embedded bool `json:"embedded"`
+	// LeafHash is the Merkle leaf hash of the log entry the SCT promises.
+	LeafHash ct.SHA256Hash `json:"leaf_hash"`
+	// Received is when the SCT was first reported.
+	Received time.Time `json:"received"`
+}
+
+// key identifies the observations of the same SCT.
+func (o *Observation) key() [sha256.Size]byte {
+	return sha256.Sum256(append(append([]byte(nil), o.LeafHash[:]...), o.SCT...))
+}
+
+// Storage keeps the observations of SCTs, for auditors to later check their
+// inclusion in logs. Implementations must be safe for concurrent use.
+type Storage interface {
+	// Add records an observation. Recording an SCT again for the same log
+	// entry has no effect.
+	Add(ctx context.Context, o *Observation) error
+	// Observations returns the observations received at or after since,
+	// oldest first.
+	Observations(ctx context.Context, since time.Time) ([]*Observation, error)
+}
+
+// MemoryStorage is a Storage which keeps observations in memory.
+type MemoryStorage struct {
+	mu   sync.Mutex
+	obs  []*Observation
+	seen map[[sha256.Size]byte]bool
+}
+
+// NewMemoryStorage creates an empty MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{seen: make(map[[sha256.Size]byte]bool)}
+}
+
+// remember adds o to the observations, returning whether it was new.
+func (s *MemoryStorage) remember(o *Observation) bool {
+	k := o.key()
+	if s.seen[k] {
+		return false
+	}
+	s.seen[k] = true
+	s.obs = append(s.obs, o)
+	return true
+}
+
+// Add implements Storage.
+func (s *MemoryStorage) Add(_ context.Context, o *Observation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remember(o)
+	return nil
+}
+
+// Observations implements Storage.
+func (s *MemoryStorage) Observations(_ context.Context, since time.Time) ([]*Observation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var obs []*Observation
+	for _, o := range s.obs {
+		if !o.Received.Before(since) {
+			obs = append(obs, o)
+		}
+	}
+	return obs, nil
+}
+
+// FileStorage is a Storage which appends observations to a journal file of
+// JSON lines, and keeps them in memory.
+type FileStorage struct {
+	mem MemoryStorage
+	j   *journal.Journal
+}
+
+// OpenFileStorage opens the journal at path, creating it if needed, and loads
+// the observations recorded in it. A partial last line, as left by a crash,
+// is discarded.
+func OpenFileStorage(path string) (*FileStorage, error) {
+	s := &FileStorage{mem: MemoryStorage{seen: make(map[[sha256.Size]byte]bool)}}
+	j, err := journal.Open(path, func(data []byte) error {
+		var o Observation
+		if err := json.Unmarshal(data, &o); err != nil {
+			return err
+		}
+		s.mem.remember(&o)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.j = j
+	return s, nil
+}
+
+// Add implements Storage.
+func (s *FileStorage) Add(_ context.Context, o *Observation) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+	if s.mem.seen[o.key()] {
+		return nil
+	}
+	if err := s.j.Append(o); err != nil {
+		return fmt.Errorf("failed to write observation: %v", err)
+	}
+	s.mem.remember(o)
+	return nil
+}
+
+// Observations implements Storage.
+func (s *FileStorage) Observations(ctx context.Context, since time.Time) ([]*Observation, error) {
+	return s.mem.Observations(ctx, since)
+}
+
+// Close syncs and closes the journal.
+func (s *FileStorage) Close() error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+	return s.j.Close()
+}
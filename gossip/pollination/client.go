@@ -0,0 +1,60 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pollination
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// Pollinate sends a batch of up to n STHs of p to the pollination endpoint of
+// the server at peerURL, and adds the STHs the peer returns to p.
+func Pollinate(ctx context.Context, client *http.Client, peerURL string, p *Pool, n int) error {
+	body, err := json.Marshal(Pollination{STHs: p.Batch(n)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal pollination request: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(peerURL, "/")+PollinationPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("got HTTP status %q from %s", rsp.Status, peerURL)
+	}
+	var got Pollination
+	if err := json.NewDecoder(io.LimitReader(rsp.Body, maxRequestSize)).Decode(&got); err != nil {
+		return fmt.Errorf("failed to parse pollination response: %v", err)
+	}
+	for _, sth := range got.STHs {
+		if err := p.Add(sth); err != nil && !errors.Is(err, ErrUnknownLog) {
+			klog.V(1).Infof("Ignoring STH of log %x from %s: %v", sth.LogID, peerURL, err)
+		}
+	}
+	return nil
+}
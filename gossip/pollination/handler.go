@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pollination
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultBatchSize is the default number of STHs returned by a Handler.
+	DefaultBatchSize = 100
+	// maxRequestSize limits the size of pollination requests.
+	maxRequestSize = 1 << 20
+)
+
+// Handler serves STH pollination for a Pool: a POST request carries a
+// Pollination whose STHs are added to the Pool, and both POST and GET
+// requests are answered with a Pollination holding a batch of the Pool's
+// STHs. STHs of unknown logs or with invalid signatures are ignored.
+type Handler struct {
+	Pool *Pool
+	// BatchSize is the maximal number of STHs returned; if zero,
+	// DefaultBatchSize is used.
+	BatchSize int
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req Pollination
+		body := http.MaxBytesReader(w, r.Body, maxRequestSize)
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("failed to parse pollination request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			http.Error(w, fmt.Sprintf("failed to read pollination request: %v", err), http.StatusBadRequest)
+			return
+		}
+		for _, sth := range req.STHs {
+			if err := h.Pool.Add(sth); err != nil && !errors.Is(err, ErrUnknownLog) {
+				klog.V(1).Infof("Ignoring STH of log %x from %s: %v", sth.LogID, r.RemoteAddr, err)
+			}
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	batchSize := h.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	rsp := Pollination{STHs: h.Pool.Batch(batchSize)}
+	if rsp.STHs == nil {
+		rsp.STHs = []STH{}
+	}
+	data, err := json.Marshal(rsp)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to marshal pollination response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(data); err != nil {
+		klog.Warningf("Failed to write pollination response: %v", err)
+	}
+}
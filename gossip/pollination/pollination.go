@@ -0,0 +1,250 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pollination implements STH pollination, a minimal form of CT
+// gossip: cooperating servers exchange the signed tree heads (STHs) they have
+// seen, so that a log presenting different views of its tree to different
+// parties is detected when two conflicting STHs meet in the same pool.
+package pollination
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// PollinationPath is the path on which STHs are exchanged.
+	PollinationPath = "/.well-known/ct/v1/sth-pollination"
+
+	// DefaultMaxSTHsPerLog is the default number of STHs kept for each log.
+	DefaultMaxSTHsPerLog = 5
+	// DefaultMaxFuture is the default tolerance for STHs timestamped in the
+	// future.
+	DefaultMaxFuture = 5 * time.Minute
+)
+
+// ErrUnknownLog is returned for STHs of logs the Pool does not know.
+var ErrUnknownLog = errors.New("unknown log")
+
+// STH is the JSON form of an STH exchanged through pollination; it extends
+// the get-sth response with the ID of the log.
+type STH struct {
+	LogID []byte `json:"log_id"`
+	ct.GetSTHResponse
+}
+
+// Pollination is the body of pollination requests and responses.
+type Pollination struct {
+	STHs []STH `json:"sths"`
+}
+
+// Conflict records two validly signed STHs of the same log for the same tree
+// size with different root hashes, which prove that the log presented a
+// split view.
+type Conflict struct {
+	LogID [sha256.Size]byte
+	A, B  ct.SignedTreeHead
+}
+
+// Options configures a Pool.
+type Options struct {
+	// MaxSTHsPerLog is the number of most recent STHs kept for each log; if
+	// zero, DefaultMaxSTHsPerLog is used.
+	MaxSTHsPerLog int
+	// MaxFuture is how far in the future STH timestamps may be; if zero,
+	// DefaultMaxFuture is used.
+	MaxFuture time.Duration
+	// OnConflict, if set, is called for each split view detected.
+	OnConflict func(Conflict)
+}
+
+type poolLog struct {
+	verifier *ct.SignatureVerifier
+	sths     []ct.SignedTreeHead // newest first
+}
+
+// Pool holds the latest STHs seen for a set of known logs. It is safe for
+// concurrent use.
+type Pool struct {
+	opts Options
+	now  func() time.Time
+
+	mu        sync.Mutex
+	logs      map[[sha256.Size]byte]*poolLog
+	conflicts []Conflict
+}
+
+// NewPool creates an empty Pool, which knows no logs.
+func NewPool(opts Options) *Pool {
+	if opts.MaxSTHsPerLog <= 0 {
+		opts.MaxSTHsPerLog = DefaultMaxSTHsPerLog
+	}
+	if opts.MaxFuture <= 0 {
+		opts.MaxFuture = DefaultMaxFuture
+	}
+	return &Pool{opts: opts, now: time.Now, logs: make(map[[sha256.Size]byte]*poolLog)}
+}
+
+// NewPoolFromLogList creates a Pool knowing the RFC 6962 logs of ll.
+func NewPoolFromLogList(ll *loglist3.LogList, opts Options) (*Pool, error) {
+	p := NewPool(opts)
+	for _, op := range ll.Operators {
+		for _, l := range op.Logs {
+			if err := p.AddLog(l.Key); err != nil {
+				return nil, fmt.Errorf("log %q: %v", l.URL, err)
+			}
+		}
+	}
+	return p, nil
+}
+
+// AddLog makes the log with the given DER-encoded public key known to the
+// Pool.
+func (p *Pool) AddLog(pubKeyDER []byte) error {
+	pubKey, err := x509.ParsePKIXPublicKey(pubKeyDER)
+	if err != nil {
+		return fmt.Errorf("failed to parse public key: %v", err)
+	}
+	verifier, err := ct.NewSignatureVerifier(pubKey)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := sha256.Sum256(pubKeyDER)
+	if _, ok := p.logs[id]; !ok {
+		p.logs[id] = &poolLog{verifier: verifier}
+	}
+	return nil
+}
+
+// Add checks the signature of sth with the key of its log, and keeps it if
+// it is among the most recent STHs of the log. Returns ErrUnknownLog if the
+// log is not known.
+func (p *Pool) Add(sth STH) error {
+	var id [sha256.Size]byte
+	if len(sth.LogID) != len(id) {
+		return fmt.Errorf("log ID has %d bytes, want %d", len(sth.LogID), len(id))
+	}
+	copy(id[:], sth.LogID)
+	signed, err := sth.ToSignedTreeHead()
+	if err != nil {
+		return err
+	}
+	signed.LogID = ct.SHA256Hash(id)
+
+	p.mu.Lock()
+	l, ok := p.logs[id]
+	p.mu.Unlock()
+	if !ok {
+		return ErrUnknownLog
+	}
+	if err := l.verifier.VerifySTHSignature(*signed); err != nil {
+		return fmt.Errorf("invalid STH signature: %v", err)
+	}
+	if maxTS := uint64(p.now().Add(p.opts.MaxFuture).UnixMilli()); signed.Timestamp > maxTS {
+		return fmt.Errorf("STH timestamp %d is in the future", signed.Timestamp)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, known := range l.sths {
+		if known.Timestamp == signed.Timestamp && known.TreeSize == signed.TreeSize && known.SHA256RootHash == signed.SHA256RootHash {
+			return nil
+		}
+	}
+	for _, known := range l.sths {
+		if known.TreeSize != signed.TreeSize || known.SHA256RootHash == signed.SHA256RootHash {
+			continue
+		}
+		conflict := Conflict{LogID: id, A: known, B: *signed}
+		klog.Errorf("Split view of log %x: tree size %d has root hashes %x and %x", id, signed.TreeSize, known.SHA256RootHash, signed.SHA256RootHash)
+		p.conflicts = append(p.conflicts, conflict)
+		if p.opts.OnConflict != nil {
+			p.opts.OnConflict(conflict)
+		}
+	}
+	l.sths = append(l.sths, *signed)
+	sort.SliceStable(l.sths, func(i, j int) bool { return l.sths[i].Timestamp > l.sths[j].Timestamp })
+	if len(l.sths) > p.opts.MaxSTHsPerLog {
+		l.sths = l.sths[:p.opts.MaxSTHsPerLog]
+	}
+	return nil
+}
+
+// Batch returns up to n STHs of the Pool, starting with the most recent STH
+// of each log, or all of them if n is not positive.
+func (p *Pool) Batch(n int) []STH {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ids := make([][sha256.Size]byte, 0, len(p.logs))
+	for id := range p.logs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return bytes.Compare(ids[i][:], ids[j][:]) < 0 })
+
+	var batch []STH
+	for depth := 0; depth < p.opts.MaxSTHsPerLog; depth++ {
+		for _, id := range ids {
+			sths := p.logs[id].sths
+			if depth >= len(sths) {
+				continue
+			}
+			if n > 0 && len(batch) >= n {
+				return batch
+			}
+			sth, err := toSTH(sths[depth])
+			if err != nil {
+				klog.Warningf("Skipping STH of log %x: %v", id, err)
+				continue
+			}
+			batch = append(batch, sth)
+		}
+	}
+	return batch
+}
+
+// Conflicts returns the split views detected so far.
+func (p *Pool) Conflicts() []Conflict {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Conflict(nil), p.conflicts...)
+}
+
+func toSTH(sth ct.SignedTreeHead) (STH, error) {
+	sig, err := tls.Marshal(sth.TreeHeadSignature)
+	if err != nil {
+		return STH{}, fmt.Errorf("failed to marshal STH signature: %v", err)
+	}
+	return STH{
+		LogID: append([]byte(nil), sth.LogID[:]...),
+		GetSTHResponse: ct.GetSTHResponse{
+			TreeSize:          sth.TreeSize,
+			Timestamp:         sth.Timestamp,
+			SHA256RootHash:    append([]byte(nil), sth.SHA256RootHash[:]...),
+			TreeHeadSignature: sig,
+		},
+	}, nil
+}
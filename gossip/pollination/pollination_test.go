@@ -0,0 +1,291 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pollination
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+)
+
+var testNow = time.Unix(1700000000, 0)
+
+type testLog struct {
+	t      *testing.T
+	key    *ecdsa.PrivateKey
+	keyDER []byte
+	id     [sha256.Size]byte
+}
+
+func newTestLog(t *testing.T) *testLog {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey()=%v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey()=%v", err)
+	}
+	return &testLog{t: t, key: key, keyDER: der, id: sha256.Sum256(der)}
+}
+
+// sth returns an STH signed by the log, timestamped age before testNow.
+func (l *testLog) sth(size uint64, root byte, age time.Duration) STH {
+	l.t.Helper()
+	signed := ct.SignedTreeHead{
+		Version:        ct.V1,
+		TreeSize:       size,
+		Timestamp:      uint64(testNow.Add(-age).UnixMilli()),
+		SHA256RootHash: ct.SHA256Hash{root},
+	}
+	data, err := ct.SerializeSTHSignatureInput(signed)
+	if err != nil {
+		l.t.Fatalf("SerializeSTHSignatureInput()=%v", err)
+	}
+	sig, err := tls.CreateSignature(*l.key, tls.SHA256, data)
+	if err != nil {
+		l.t.Fatalf("CreateSignature()=%v", err)
+	}
+	signed.TreeHeadSignature = ct.DigitallySigned(sig)
+	signed.LogID = ct.SHA256Hash(l.id)
+	sth, err := toSTH(signed)
+	if err != nil {
+		l.t.Fatalf("toSTH()=%v", err)
+	}
+	return sth
+}
+
+func newTestPool(t *testing.T, opts Options, logs ...*testLog) *Pool {
+	t.Helper()
+	p := NewPool(opts)
+	p.now = func() time.Time { return testNow }
+	for _, l := range logs {
+		if err := p.AddLog(l.keyDER); err != nil {
+			t.Fatalf("AddLog()=%v", err)
+		}
+	}
+	return p
+}
+
+func TestPoolAdd(t *testing.T) {
+	known, unknown := newTestLog(t), newTestLog(t)
+	p := newTestPool(t, Options{}, known)
+
+	badSig := known.sth(10, 1, time.Minute)
+	badSig.TreeSize++
+	badID := known.sth(10, 1, time.Minute)
+	badID.LogID = badID.LogID[1:]
+
+	for _, test := range []struct {
+		name    string
+		sth     STH
+		wantErr bool
+		wantIs  error
+	}{
+		{name: "valid", sth: known.sth(10, 1, time.Minute)},
+		{name: "duplicate", sth: known.sth(10, 1, time.Minute)},
+		{name: "slightly-future", sth: known.sth(11, 2, -time.Minute)},
+		{name: "future", sth: known.sth(12, 3, -time.Hour), wantErr: true},
+		{name: "unknown-log", sth: unknown.sth(10, 1, time.Minute), wantErr: true, wantIs: ErrUnknownLog},
+		{name: "bad-signature", sth: badSig, wantErr: true},
+		{name: "bad-log-id", sth: badID, wantErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := p.Add(test.sth)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Add()=%v; want error: %t", err, test.wantErr)
+			}
+			if test.wantIs != nil && !errors.Is(err, test.wantIs) {
+				t.Errorf("Add()=%v; want %v", err, test.wantIs)
+			}
+		})
+	}
+	if got := p.Batch(0); len(got) != 2 {
+		t.Errorf("Batch() has %d STHs; want 2", len(got))
+	}
+	if got := p.Conflicts(); len(got) != 0 {
+		t.Errorf("Conflicts()=%v; want none", got)
+	}
+}
+
+func TestPoolBatch(t *testing.T) {
+	a, b := newTestLog(t), newTestLog(t)
+	p := newTestPool(t, Options{MaxSTHsPerLog: 2}, a, b)
+	for i, sth := range []STH{
+		a.sth(1, 1, 3*time.Hour),
+		a.sth(3, 3, time.Hour),
+		a.sth(2, 2, 2*time.Hour),
+		b.sth(5, 5, time.Hour),
+	} {
+		if err := p.Add(sth); err != nil {
+			t.Fatalf("Add(#%d)=%v", i, err)
+		}
+	}
+
+	all := p.Batch(0)
+	if len(all) != 3 {
+		t.Fatalf("Batch(0) has %d STHs; want 3, as only 2 are kept per log", len(all))
+	}
+	sizes := make(map[uint64]bool)
+	for _, sth := range all {
+		sizes[sth.TreeSize] = true
+	}
+	if sizes[1] || !sizes[2] || !sizes[3] || !sizes[5] {
+		t.Errorf("Batch(0) has tree sizes %v; want 2, 3 and 5", sizes)
+	}
+
+	// The latest STH of each log comes first.
+	two := p.Batch(2)
+	if len(two) != 2 {
+		t.Fatalf("Batch(2) has %d STHs; want 2", len(two))
+	}
+	for _, sth := range two {
+		if sth.TreeSize != 3 && sth.TreeSize != 5 {
+			t.Errorf("Batch(2) has an STH of tree size %d; want the latest ones", sth.TreeSize)
+		}
+	}
+	for _, sth := range all {
+		if err := newTestPool(t, Options{}, a, b).Add(sth); err != nil {
+			t.Errorf("Add(Batch() STH)=%v; want round trip", err)
+		}
+	}
+}
+
+func TestPoolConflict(t *testing.T) {
+	l := newTestLog(t)
+	var reported []Conflict
+	p := newTestPool(t, Options{OnConflict: func(c Conflict) { reported = append(reported, c) }}, l)
+	for _, sth := range []STH{
+		l.sth(10, 1, 2*time.Minute),
+		l.sth(10, 1, time.Minute),
+		l.sth(11, 2, time.Minute),
+		l.sth(10, 9, time.Minute),
+		l.sth(10, 9, time.Minute),
+	} {
+		if err := p.Add(sth); err != nil {
+			t.Fatalf("Add()=%v", err)
+		}
+	}
+	got := p.Conflicts()
+	if len(got) != 2 || len(reported) != 2 {
+		t.Fatalf("Conflicts()=%v, reported %v; want 2 conflicts for both STHs of root 1", got, reported)
+	}
+	for _, c := range got {
+		if c.LogID != l.id || c.A.TreeSize != 10 || c.A.SHA256RootHash[0] != 1 || c.B.SHA256RootHash[0] != 9 {
+			t.Errorf("Conflict=%+v; want roots 1 and 9 at tree size 10", c)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	a, b := newTestLog(t), newTestLog(t)
+	p := newTestPool(t, Options{}, a, b)
+	if err := p.Add(a.sth(1, 1, time.Hour)); err != nil {
+		t.Fatalf("Add()=%v", err)
+	}
+	srv := httptest.NewServer(&Handler{Pool: p, BatchSize: 10})
+	defer srv.Close()
+
+	req, err := json.Marshal(Pollination{STHs: []STH{b.sth(2, 2, time.Hour), newTestLog(t).sth(3, 3, time.Hour)}})
+	if err != nil {
+		t.Fatalf("json.Marshal()=%v", err)
+	}
+	rsp, err := http.Post(srv.URL+PollinationPath, "application/json", bytes.NewReader(req))
+	if err != nil {
+		t.Fatalf("POST=%v", err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		t.Fatalf("POST status=%d; want 200", rsp.StatusCode)
+	}
+	var got Pollination
+	if err := json.NewDecoder(rsp.Body).Decode(&got); err != nil {
+		t.Fatalf("Decode()=%v", err)
+	}
+	if len(got.STHs) != 2 {
+		t.Errorf("POST returned %d STHs; want those of both known logs", len(got.STHs))
+	}
+
+	for _, test := range []struct {
+		method string
+		body   string
+		want   int
+	}{
+		{method: http.MethodGet, want: http.StatusOK},
+		{method: http.MethodPost, body: "{", want: http.StatusBadRequest},
+		{method: http.MethodPut, body: "{}", want: http.StatusMethodNotAllowed},
+	} {
+		req, err := http.NewRequest(test.method, srv.URL, bytes.NewReader([]byte(test.body)))
+		if err != nil {
+			t.Fatalf("NewRequest()=%v", err)
+		}
+		rsp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s=%v", test.method, err)
+		}
+		rsp.Body.Close()
+		if rsp.StatusCode != test.want {
+			t.Errorf("%s %q status=%d; want %d", test.method, test.body, rsp.StatusCode, test.want)
+		}
+	}
+}
+
+func TestPollinate(t *testing.T) {
+	a, b := newTestLog(t), newTestLog(t)
+	var conflicts int
+	local := newTestPool(t, Options{OnConflict: func(Conflict) { conflicts++ }}, a, b)
+	remote := newTestPool(t, Options{}, a, b)
+	if err := local.Add(a.sth(1, 1, time.Hour)); err != nil {
+		t.Fatalf("Add()=%v", err)
+	}
+	if err := remote.Add(a.sth(1, 2, time.Hour)); err != nil {
+		t.Fatalf("Add()=%v", err)
+	}
+	if err := remote.Add(b.sth(2, 2, time.Hour)); err != nil {
+		t.Fatalf("Add()=%v", err)
+	}
+	srv := httptest.NewServer(&Handler{Pool: remote})
+	defer srv.Close()
+
+	if err := Pollinate(context.Background(), srv.Client(), srv.URL+"/", local, 0); err != nil {
+		t.Fatalf("Pollinate()=%v", err)
+	}
+	if got := local.Batch(0); len(got) != 3 {
+		t.Errorf("Batch() after Pollinate() has %d STHs; want 3", len(got))
+	}
+	if conflicts != 1 || len(remote.Conflicts()) != 1 {
+		t.Errorf("Pollinate() found %d local and %d remote conflicts; want 1 each", conflicts, len(remote.Conflicts()))
+	}
+
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	if err := Pollinate(context.Background(), missing.Client(), missing.URL, local, 0); err == nil {
+		t.Error("Pollinate(missing)=nil; want error")
+	}
+}
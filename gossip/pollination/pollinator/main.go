@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The pollinator binary serves STH pollination for the logs of a log list,
+// and periodically exchanges STHs with a set of peer servers.
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/gossip/pollination"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+	"k8s.io/klog/v2"
+)
+
+var (
+	httpEndpoint  = flag.String("http_endpoint", "localhost:6966", "Endpoint for HTTP (host:port)")
+	logList       = flag.String("log_list", loglist3.LogListURL, "File or URL of the JSON list of logs whose STHs are accepted")
+	maxSTHsPerLog = flag.Int("max_sths_per_log", pollination.DefaultMaxSTHsPerLog, "Number of most recent STHs kept for each log")
+	batchSize     = flag.Int("batch_size", pollination.DefaultBatchSize, "Maximal number of STHs returned to, and sent to, peers")
+	peers         = flag.String("peers", "", "Comma-separated list of base URLs of peer servers to exchange STHs with")
+	interval      = flag.Duration("pollination_interval", 10*time.Minute, "Interval between STH exchanges with --peers")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	ctx := context.Background()
+
+	data, err := x509util.ReadFileOrURL(*logList, http.DefaultClient)
+	if err != nil {
+		klog.Exitf("Failed to read log list: %v", err)
+	}
+	ll, err := loglist3.NewFromJSON(data)
+	if err != nil {
+		klog.Exitf("Failed to parse log list: %v", err)
+	}
+	pool, err := pollination.NewPoolFromLogList(ll, pollination.Options{MaxSTHsPerLog: *maxSTHsPerLog})
+	if err != nil {
+		klog.Exitf("Failed to set up STH pool: %v", err)
+	}
+
+	if len(*peers) > 0 {
+		go pollinatePeers(ctx, pool, strings.Split(*peers, ","))
+	}
+
+	http.Handle(pollination.PollinationPath, &pollination.Handler{Pool: pool, BatchSize: *batchSize})
+	klog.Infof("Serving STH pollination on %s", *httpEndpoint)
+	klog.Exit(http.ListenAndServe(*httpEndpoint, nil))
+}
+
+func pollinatePeers(ctx context.Context, pool *pollination.Pool, peerURLs []string) {
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		for _, peer := range peerURLs {
+			if err := pollination.Pollinate(ctx, http.DefaultClient, peer, pool, *batchSize); err != nil {
+				klog.Warningf("Failed to exchange STHs with %s: %v", peer, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
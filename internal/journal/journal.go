@@ -0,0 +1,94 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package journal implements append-only journal files of JSON records, one
+// per line, which stores use to persist their state across restarts.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Journal is an open journal file, to which records are appended. It is not
+// safe for concurrent use; callers serialize their own appends.
+type Journal struct {
+	f *os.File
+}
+
+// Open opens the journal at path, creating it if needed, and calls fn with
+// each record in it, in order. A partial last line, as left by a crash, is
+// discarded, and later records are appended after the last complete one.
+// Fails if fn returns an error for a record.
+func Open(path string, fn func(record []byte) error) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := load(f, fn)
+	if err == nil {
+		if err = f.Truncate(valid); err == nil {
+			_, err = f.Seek(valid, io.SeekStart)
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to load %s: %v", path, err)
+	}
+	return &Journal{f: f}, nil
+}
+
+// load passes the records of the journal in r to fn, returning the length of
+// its prefix of complete records.
+func load(r io.Reader, fn func([]byte) error) (int64, error) {
+	br := bufio.NewReader(r)
+	var valid int64
+	for {
+		line, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			return valid, nil
+		} else if err != nil {
+			return 0, err
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			if err := fn(line); err != nil {
+				return 0, fmt.Errorf("bad record at offset %d: %v", valid, err)
+			}
+		}
+		valid += int64(len(line))
+	}
+}
+
+// Append marshals v to JSON and appends it as a record.
+func (j *Journal) Append(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = j.f.Write(append(data, '\n'))
+	return err
+}
+
+// Close syncs and closes the journal.
+func (j *Journal) Close() error {
+	if err := j.f.Sync(); err != nil {
+		j.f.Close()
+		return err
+	}
+	return j.f.Close()
+}
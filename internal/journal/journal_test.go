@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type record struct {
+	N int `json:"n"`
+}
+
+// openRecords opens the journal at path, returning the records in it.
+func openRecords(t *testing.T, path string) (*Journal, []int) {
+	t.Helper()
+	var got []int
+	j, err := Open(path, func(data []byte) error {
+		var r record
+		if err := json.Unmarshal(data, &r); err != nil {
+			return err
+		}
+		got = append(got, r.N)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Open()=%v", err)
+	}
+	return j, got
+}
+
+func TestJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	j, got := openRecords(t, path)
+	if len(got) != 0 {
+		t.Errorf("new journal has records %v", got)
+	}
+	for n := 1; n <= 2; n++ {
+		if err := j.Append(record{N: n}); err != nil {
+			t.Fatalf("Append()=%v", err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close()=%v", err)
+	}
+
+	// Simulate a crash part way through appending a record.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"n":`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	j, got = openRecords(t, path)
+	if want := []int{1, 2}; !cmp.Equal(got, want) {
+		t.Errorf("reopened journal has records %v, want %v", got, want)
+	}
+	if err := j.Append(record{N: 3}); err != nil {
+		t.Fatalf("Append()=%v", err)
+	}
+	j.Close()
+
+	j, got = openRecords(t, path)
+	defer j.Close()
+	if want := []int{1, 2, 3}; !cmp.Equal(got, want) {
+		t.Errorf("reopened journal has records %v, want %v", got, want)
+	}
+}
+
+func TestOpenBadRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	if err := os.WriteFile(path, []byte("{\"n\":1}\n\nnot json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path, func(data []byte) error {
+		return json.Unmarshal(data, &record{})
+	}); err == nil {
+		t.Error("Open()=nil, want error for bad record")
+	}
+
+	errBad := errors.New("bad")
+	if err := os.WriteFile(path, []byte("{\"n\":1}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path, func([]byte) error { return errBad }); err == nil {
+		t.Error("Open()=nil, want error from callback")
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testonly holds test helpers shared by the packages of this module.
+package testonly
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/transparency-dev/merkle/rfc6962"
+	merkletest "github.com/transparency-dev/merkle/testonly"
+)
+
+// TreeLog is a fake log client for a log whose tree is held in memory, and
+// which signs its STHs with Key. The fields may be changed between calls to
+// make the log misbehave.
+type TreeLog struct {
+	t       testing.TB
+	Key     *ecdsa.PrivateKey
+	Tree    *merkletest.Tree
+	Entries []ct.LeafEntry
+	// Now is the timestamp of the STHs returned by GetSTH; the current
+	// time is used if it is zero.
+	Now time.Time
+	// Next, if set, is returned by the next GetSTH call.
+	Next *ct.SignedTreeHead
+	// Err, if set, is returned by GetSTH and GetProofByHash.
+	Err error
+	// Fork, if set, is the tree of the consistency proofs served instead.
+	Fork *merkletest.Tree
+	// BadProof, if set, makes inclusion proofs invalid.
+	BadProof bool
+	// MaxEntries, if positive, limits the entries returned by GetRawEntries,
+	// as logs may return fewer entries than requested.
+	MaxEntries int
+	// Gets counts the GetRawEntries calls.
+	Gets int
+}
+
+// NewTreeLog returns a TreeLog of an empty tree, with a fresh key.
+func NewTreeLog(t testing.TB) *TreeLog {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey()=%v", err)
+	}
+	return &TreeLog{t: t, Key: key, Tree: merkletest.New(rfc6962.DefaultHasher)}
+}
+
+// Grow appends n entries to the log.
+func (l *TreeLog) Grow(n int) {
+	for i := 0; i < n; i++ {
+		e := ct.LeafEntry{
+			LeafInput: []byte(fmt.Sprintf("leaf%d", l.Tree.Size())),
+			ExtraData: []byte(fmt.Sprintf("extra%d", l.Tree.Size())),
+		}
+		l.Entries = append(l.Entries, e)
+		l.Tree.AppendData(e.LeafInput)
+	}
+}
+
+// Sign returns an STH of the given size and root, timestamped at when and
+// signed with the key of the log.
+func (l *TreeLog) Sign(size uint64, root []byte, when time.Time) *ct.SignedTreeHead {
+	l.t.Helper()
+	sth := &ct.SignedTreeHead{Version: ct.V1, TreeSize: size, Timestamp: uint64(when.UnixMilli())}
+	copy(sth.SHA256RootHash[:], root)
+	data, err := ct.SerializeSTHSignatureInput(*sth)
+	if err != nil {
+		l.t.Fatalf("SerializeSTHSignatureInput()=%v", err)
+	}
+	sig, err := tls.CreateSignature(*l.Key, tls.SHA256, data)
+	if err != nil {
+		l.t.Fatalf("CreateSignature()=%v", err)
+	}
+	sth.TreeHeadSignature = ct.DigitallySigned(sig)
+	return sth
+}
+
+// Verifier returns a verifier of the signatures of the log.
+func (l *TreeLog) Verifier() *ct.SignatureVerifier {
+	l.t.Helper()
+	v, err := ct.NewSignatureVerifier(l.Key.Public())
+	if err != nil {
+		l.t.Fatalf("NewSignatureVerifier()=%v", err)
+	}
+	return v
+}
+
+// BaseURI returns a fixed name for the log.
+func (l *TreeLog) BaseURI() string { return "tree" }
+
+// GetSTH returns Next if set, or else an STH of the current tree.
+func (l *TreeLog) GetSTH(context.Context) (*ct.SignedTreeHead, error) {
+	if l.Err != nil {
+		return nil, l.Err
+	}
+	if sth := l.Next; sth != nil {
+		l.Next = nil
+		return sth, nil
+	}
+	when := l.Now
+	if when.IsZero() {
+		when = time.Now()
+	}
+	return l.Sign(l.Tree.Size(), l.Tree.Hash(), when), nil
+}
+
+// GetSTHConsistency returns a consistency proof from Fork if set, or else
+// from the tree.
+func (l *TreeLog) GetSTHConsistency(_ context.Context, first, second uint64) ([][]byte, error) {
+	if l.Fork != nil {
+		return l.Fork.ConsistencyProof(first, second)
+	}
+	return l.Tree.ConsistencyProof(first, second)
+}
+
+// GetProofByHash returns an inclusion proof of the leaf with the given hash,
+// or a 404 error if the tree of the given size does not hold it.
+func (l *TreeLog) GetProofByHash(_ context.Context, hash []byte, treeSize uint64) (*ct.GetProofByHashResponse, error) {
+	if l.Err != nil {
+		return nil, l.Err
+	}
+	for i := uint64(0); i < treeSize; i++ {
+		if bytes.Equal(l.Tree.LeafHash(i), hash) {
+			pf, err := l.Tree.InclusionProof(i, treeSize)
+			if err != nil {
+				return nil, err
+			}
+			if l.BadProof {
+				pf[0] = make([]byte, sha256.Size)
+			}
+			return &ct.GetProofByHashResponse{LeafIndex: int64(i), AuditPath: pf}, nil
+		}
+	}
+	return nil, jsonclient.RspError{StatusCode: http.StatusNotFound, Err: errors.New("hash not found")}
+}
+
+// GetRawEntries returns the entries in [start, end], or fewer of them if
+// MaxEntries is set.
+func (l *TreeLog) GetRawEntries(_ context.Context, start, end int64) (*ct.GetEntriesResponse, error) {
+	l.Gets++
+	if l.MaxEntries > 0 && end >= start+int64(l.MaxEntries) {
+		end = start + int64(l.MaxEntries) - 1
+	}
+	if end >= int64(len(l.Entries)) {
+		end = int64(len(l.Entries)) - 1
+	}
+	return &ct.GetEntriesResponse{Entries: l.Entries[start : end+1]}, nil
+}
+
+// GetAcceptedRoots returns a single fake root.
+func (l *TreeLog) GetAcceptedRoots(context.Context) ([]ct.ASN1Cert, error) {
+	return []ct.ASN1Cert{{Data: []byte("root")}}, nil
+}
@@ -15,10 +15,93 @@
 package jsonclient
 
 import (
+	"context"
+	"math/rand"
 	"sync"
 	"time"
 )
 
+// Backoff decides how long a JSONClient waits before retrying a failed
+// request. It must be safe for concurrent use.
+type Backoff interface {
+	// Next returns the wait before retrying a request after the given
+	// attempt at it, where the first attempt is 1.
+	Next(attempt int) time.Duration
+}
+
+// ExponentialBackoff is a Backoff which doubles the wait after each attempt
+// up to a limit, and adds random jitter.
+type ExponentialBackoff struct {
+	// Initial is the wait after the first attempt. Defaults to 1s.
+	Initial time.Duration
+	// Max limits the wait, before jitter is added. Defaults to 128s.
+	Max time.Duration
+	// Jitter is the maximum random duration added to each wait.
+	Jitter time.Duration
+}
+
+// Next implements Backoff.
+func (b ExponentialBackoff) Next(attempt int) time.Duration {
+	wait, limit := b.Initial, b.Max
+	if wait <= 0 {
+		wait = defaultInitialBackoff
+	}
+	if limit <= 0 {
+		limit = defaultMaxBackoff
+	}
+	for i := 1; i < attempt && wait < limit; i++ {
+		wait *= 2
+	}
+	if wait > limit {
+		wait = limit
+	}
+	if b.Jitter > 0 {
+		wait += time.Duration(rand.Int63n(int64(b.Jitter)))
+	}
+	return wait
+}
+
+// Clock waits between attempts at a request.
+type Clock interface {
+	// Sleep waits for d, returning early with ctx.Err() if ctx is done.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type systemClock struct{}
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// FakeClock is a Clock for tests, which returns from Sleep immediately and
+// records the durations slept for.
+type FakeClock struct {
+	mu     sync.Mutex
+	sleeps []time.Duration
+}
+
+// Sleep implements Clock.
+func (c *FakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sleeps = append(c.sleeps, d)
+	return ctx.Err()
+}
+
+// Sleeps returns the durations passed to Sleep so far.
+func (c *FakeClock) Sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.sleeps...)
+}
+
 type backoff struct {
 	mu         sync.RWMutex
 	multiplier uint
@@ -109,3 +109,21 @@ func TestBackoffOverride(t *testing.T) {
 		}
 	}
 }
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff{Initial: time.Second, Max: 5 * time.Second}
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := b.Next(i + 1); got != want {
+			t.Errorf("Next(%d)=%s; want %s", i+1, got, want)
+		}
+	}
+	if got := (ExponentialBackoff{}).Next(100); got != defaultMaxBackoff {
+		t.Errorf("default Next(100)=%s; want %s", got, defaultMaxBackoff)
+	}
+	b.Jitter = 100 * time.Millisecond
+	for i := 0; i < 10; i++ {
+		if got := b.Next(1); got < time.Second || got >= time.Second+b.Jitter {
+			t.Errorf("Next(1)=%s; want within jitter of 1s", got)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxErrorPrefix is how much of a streamed response body is kept for errors.
+const maxErrorPrefix = 4096
+
+// ErrResponseTooLarge is wrapped by the RspError returned when a response
+// body is larger than Options.MaxResponseSize.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// limitBody returns a reader for r which fails once more than the client's
+// maximum response size has been read, if it has one.
+func (c *JSONClient) limitBody(r io.Reader) io.Reader {
+	if c.maxResponseSize <= 0 {
+		return r
+	}
+	return &limitedReader{r: r, limit: c.maxResponseSize}
+}
+
+// limitedReader reads from r, failing with ErrResponseTooLarge if there are
+// more than limit bytes.
+type limitedReader struct {
+	r     io.Reader
+	limit int64
+	n     int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	// Read up to one byte more than the limit, to detect excess.
+	room := l.limit + 1 - l.n
+	if room <= 0 {
+		return 0, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, l.limit)
+	}
+	if int64(len(p)) > room {
+		p = p[:room]
+	}
+	n, err := l.r.Read(p)
+	if l.n += int64(n); l.n > l.limit {
+		return n - 1, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, l.limit)
+	}
+	return n, err
+}
+
+// prefixReader reads from r, keeping the start of what it reads.
+type prefixReader struct {
+	r      io.Reader
+	n      int
+	prefix []byte
+}
+
+func (p *prefixReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if keep := maxErrorPrefix - len(p.prefix); keep > 0 {
+		if keep > n {
+			keep = n
+		}
+		p.prefix = append(p.prefix, b[:keep]...)
+	}
+	p.n += n
+	return n, err
+}
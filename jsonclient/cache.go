@@ -0,0 +1,173 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonclient
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CacheEntry is a response body held in a Cache.
+type CacheEntry struct {
+	// ETag is the entity tag of the response, if it had one.
+	ETag string `json:"etag,omitempty"`
+	Body []byte `json:"body"`
+}
+
+// Cache holds the bodies of responses to GET requests, keyed by URL, so
+// that they need not be downloaded again. A JSONClient with a Cache uses it
+// for responses to requests made with a context from WithImmutableResponse,
+// which are served from the cache without contacting the server, and for
+// responses with an ETag, which are revalidated with the server. A Cache
+// must be safe for concurrent use.
+type Cache interface {
+	// Get returns the entry for key, if there is one.
+	Get(key string) (CacheEntry, bool)
+	// Put stores the entry for key.
+	Put(key string, entry CacheEntry)
+}
+
+type immutableKey struct{}
+
+// WithImmutableResponse returns a context which marks the GET requests made
+// with it as having responses which never change, e.g. entries below the
+// size of a verified STH, so that they may be served from a Cache.
+func WithImmutableResponse(ctx context.Context) context.Context {
+	return context.WithValue(ctx, immutableKey{}, true)
+}
+
+func isImmutable(ctx context.Context) bool {
+	immutable, _ := ctx.Value(immutableKey{}).(bool)
+	return immutable
+}
+
+// MemoryCache is a Cache which holds up to a maximum number of bytes of
+// response bodies in memory, evicting the least recently used.
+type MemoryCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	size     int
+	lru      *list.List // of *memoryCacheItem, most recently used first
+	items    map[string]*list.Element
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewMemoryCache creates a MemoryCache holding up to maxBytes of response
+// bodies.
+func NewMemoryCache(maxBytes int) *MemoryCache {
+	return &MemoryCache{maxBytes: maxBytes, lru: list.New(), items: make(map[string]*list.Element)}
+}
+
+// Get implements Cache.
+func (c *MemoryCache) Get(key string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*memoryCacheItem).entry, true
+}
+
+// Put implements Cache. Bodies larger than the cache are not stored.
+func (c *MemoryCache) Put(key string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(entry.Body) > c.maxBytes {
+		return
+	}
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+	c.items[key] = c.lru.PushFront(&memoryCacheItem{key: key, entry: entry})
+	c.size += len(entry.Body)
+	for c.size > c.maxBytes {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *MemoryCache) remove(elem *list.Element) {
+	item := c.lru.Remove(elem).(*memoryCacheItem)
+	delete(c.items, item.key)
+	c.size -= len(item.entry.Body)
+}
+
+// DiskCache is a Cache which stores each response in a file in a directory.
+// It does not limit the space used.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache creates a DiskCache in dir, creating the directory if needed.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:]))
+}
+
+// Get implements Cache. Unreadable files are treated as missing.
+func (c *DiskCache) Get(key string) (CacheEntry, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return CacheEntry{}, false
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return CacheEntry{}, false
+	}
+	return entry, true
+}
+
+// Put implements Cache. Entries are written to a temporary file which is
+// renamed into place, so that concurrent readers never see partial entries.
+// Failures to write are ignored.
+func (c *DiskCache) Put(key string, entry CacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	f, err := os.CreateTemp(c.dir, "tmp-")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
@@ -28,13 +28,12 @@ import (
 	"math/rand"
 	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
 	"time"
 
 	ct "github.com/RarimoVoting/certificate-transparency-go"
 	"github.com/RarimoVoting/certificate-transparency-go/x509"
-	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/time/rate"
 	"k8s.io/klog/v2"
 )
 
@@ -56,12 +55,22 @@ type backoffer interface {
 // JSONClient provides common functionality for interacting with a JSON server
 // that uses cryptographic signatures.
 type JSONClient struct {
-	uri        string                // the base URI of the server. e.g. https://ct.googleapis/pilot
-	httpClient *http.Client          // used to interact with the server via HTTP
-	Verifier   *ct.SignatureVerifier // nil for no verification (e.g. no public key available)
-	logger     Logger                // interface to use for logging warnings and errors
-	backoff    backoffer             // object used to store and calculate backoff information
-	userAgent  string                // If set, this is sent as the UserAgent header.
+	uri             string                // the base URI of the server. e.g. https://ct.googleapis/pilot
+	httpClient      *http.Client          // used to interact with the server via HTTP
+	Verifier        *ct.SignatureVerifier // nil for no verification (e.g. no public key available)
+	logger          Logger                // interface to use for logging warnings and errors
+	backoff         backoffer             // object used to store and calculate backoff information
+	getBackoff      Backoff               // decides the wait between attempts at GET requests
+	postBackoff     Backoff               // If set, decides the wait between attempts at POST requests instead of backoff.
+	clock           Clock                 // used to wait between attempts
+	roundTrip       RoundTripFunc         // sends requests through any middleware
+	maxResponseSize int64                 // If positive, the maximum size of a response body.
+	cache           Cache                 // If set, holds responses to GET requests.
+	userAgent       string                // If set, this is sent as the UserAgent header.
+	requestIDHeader string                // If set, a request ID is sent in this header.
+	retry           *RetryOptions         // If set, configures the retrying of failed requests.
+	observer        RequestObserver       // If set, notified of each request.
+	limiter         *rateLimiter          // If set, limits the rate of requests.
 }
 
 // Logger is a simple logging interface used to log internal errors and warnings
@@ -81,6 +90,43 @@ type Options struct {
 	PublicKeyDER []byte
 	// UserAgent, if set, will be sent as the User-Agent header with each request.
 	UserAgent string
+	// RequestIDHeader, if set, is the name of a header, e.g. "X-Request-ID",
+	// in which an ID is sent with each request, so that client and server
+	// logs can be correlated. The ID is taken from the context if set with
+	// WithRequestID, or else generated, and is recorded in any RspError.
+	RequestIDHeader string
+	// Retry, if set, makes GetAndParse retry failed requests, and limits the
+	// retries made by PostAndParseWithRetry.
+	Retry *RetryOptions
+	// Observer, if set, is notified of each HTTP request made by the client,
+	// including each retry.
+	Observer RequestObserver
+	// Transport, if set, configures proxies, dialing and connection pooling
+	// for the client's requests.
+	Transport *TransportOptions
+	// RateLimiter, if set, limits the rate of requests made by the client,
+	// including retries. Its limit is halved each time the server responds
+	// with HTTP 429, and recovers gradually as requests succeed. It may be
+	// shared by clients for the same log.
+	RateLimiter *rate.Limiter
+	// Backoff, if set, decides how long to wait before retrying failed
+	// requests, in place of the default exponential backoff with jitter.
+	// A longer Retry-After in the response is still honored.
+	Backoff Backoff
+	// Clock, if set, is used to wait between attempts at requests, e.g. a
+	// FakeClock in tests.
+	Clock Clock
+	// Middleware, if set, wraps the sending of each request, the first
+	// entry being outermost.
+	Middleware []Middleware
+	// MaxResponseSize, if positive, is the maximum size in bytes of a
+	// response body. Reading a larger one fails with an RspError wrapping
+	// ErrResponseTooLarge.
+	MaxResponseSize int64
+	// Cache, if set, holds the responses to GET requests which are marked
+	// with WithImmutableResponse or have an ETag, so that they need not be
+	// downloaded again.
+	Cache Cache
 }
 
 // ParsePublicKey parses and returns the public key contained in opts.
@@ -116,11 +162,25 @@ func (bl *basicLogger) Printf(msg string, args ...interface{}) {
 type RspError struct {
 	Err        error
 	StatusCode int
-	Body       []byte
+	// Body is the body of the response, or just its start for GetAndDecode.
+	Body []byte
+	// RequestID is the ID sent with the request, if any.
+	RequestID string
+	// RetryAfter is the wait requested by the Retry-After header of the
+	// response, if it had one.
+	RetryAfter time.Duration
+}
+
+// Unwrap returns the underlying error.
+func (e RspError) Unwrap() error {
+	return e.Err
 }
 
 // Error formats the RspError instance, focusing on the error.
 func (e RspError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("%v (request ID %s)", e.Err, e.RequestID)
+	}
 	return e.Err.Error()
 }
 
@@ -145,17 +205,40 @@ func New(uri string, hc *http.Client, opts Options) (*JSONClient, error) {
 	if hc == nil {
 		hc = new(http.Client)
 	}
+	if opts.Transport != nil {
+		if hc, err = opts.Transport.httpClient(hc); err != nil {
+			return nil, err
+		}
+	}
 	logger := opts.Logger
 	if logger == nil {
 		logger = &basicLogger{}
 	}
+	var getBackoff Backoff = opts.Retry.backoff()
+	if opts.Backoff != nil {
+		getBackoff = opts.Backoff
+	}
+	var clock Clock = systemClock{}
+	if opts.Clock != nil {
+		clock = opts.Clock
+	}
 	return &JSONClient{
-		uri:        strings.TrimRight(uri, "/"),
-		httpClient: hc,
-		Verifier:   verifier,
-		logger:     logger,
-		backoff:    &backoff{},
-		userAgent:  opts.UserAgent,
+		uri:             strings.TrimRight(uri, "/"),
+		httpClient:      hc,
+		Verifier:        verifier,
+		logger:          logger,
+		backoff:         &backoff{},
+		getBackoff:      getBackoff,
+		postBackoff:     opts.Backoff,
+		clock:           clock,
+		roundTrip:       chainMiddleware(hc, opts.Middleware),
+		maxResponseSize: opts.MaxResponseSize,
+		cache:           opts.Cache,
+		userAgent:       opts.UserAgent,
+		requestIDHeader: opts.RequestIDHeader,
+		retry:           opts.Retry,
+		observer:        opts.Observer,
+		limiter:         newRateLimiter(opts.RateLimiter),
 	}, nil
 }
 
@@ -167,11 +250,38 @@ func (c *JSONClient) BaseURI() string {
 // GetAndParse makes a HTTP GET call to the given path, and attempts to parse
 // the response as a JSON representation of the rsp structure.  Returns the
 // http.Response, the body of the response, and an error (which may be of
-// type RspError if the HTTP response was available).
+// type RspError if the HTTP response was available).  If the client has
+// RetryOptions, requests which fail with retryable errors are retried.
 func (c *JSONClient) GetAndParse(ctx context.Context, path string, params map[string]string, rsp interface{}) (*http.Response, []byte, error) {
 	if ctx == nil {
 		return nil, nil, errors.New("context.Context required")
 	}
+	ctx, id := c.withRequestID(ctx)
+	httpRsp, body, err := c.getWithRetry(ctx, path, func(attempt int) (*http.Response, []byte, error) {
+		return c.getAndParse(ctx, path, params, rsp, attempt)
+	})
+	if err != nil {
+		return nil, nil, annotateError(err, id)
+	}
+	return httpRsp, body, nil
+}
+
+// getAndParse makes a single attempt at GetAndParse, also returning the
+// http.Response with any error.
+func (c *JSONClient) getAndParse(ctx context.Context, path string, params map[string]string, rsp interface{}, attempt int) (*http.Response, []byte, error) {
+	var decode func(io.Reader) error
+	if rsp != nil {
+		decode = func(r io.Reader) error { return json.NewDecoder(r).Decode(rsp) }
+	}
+	return c.get(ctx, path, params, attempt, decode, false)
+}
+
+// get makes a single GET request, and passes the body of a successful
+// response to decode, if set. The body is read in full and returned, unless
+// stream is set, in which case it is passed to decode as it is read and only
+// the start of it is kept for any RspError. Responses are served from and
+// stored in the client's Cache, if it has one.
+func (c *JSONClient) get(ctx context.Context, path string, params map[string]string, attempt int, decode func(io.Reader) error, stream bool) (*http.Response, []byte, error) {
 	// Build a GET request with URL-encoded parameters.
 	vals := url.Values{}
 	for k, v := range params {
@@ -183,31 +293,131 @@ func (c *JSONClient) GetAndParse(ctx context.Context, path string, params map[st
 	if err != nil {
 		return nil, nil, err
 	}
-	if len(c.userAgent) != 0 {
-		httpReq.Header.Set("User-Agent", c.userAgent)
+	c.setHeaders(ctx, httpReq)
+
+	var cached CacheEntry
+	var haveCached bool
+	if c.cache != nil {
+		if cached, haveCached = c.cache.Get(fullURI); haveCached {
+			if isImmutable(ctx) {
+				return c.cachedResponse(httpReq, cached, decode)
+			}
+			if cached.ETag != "" {
+				httpReq.Header.Set("If-None-Match", cached.ETag)
+			}
+		}
 	}
 
-	httpRsp, err := ctxhttp.Do(ctx, c.httpClient, httpReq)
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, nil, err
+	}
+	start := time.Now()
+	httpRsp, err := c.do(ctx, httpReq)
 	if err != nil {
+		c.observe(RequestInfo{Method: http.MethodGet, Path: path, Attempt: attempt, Duration: time.Since(start), Err: err})
 		return nil, nil, err
 	}
 
-	// Read everything now so http.Client can reuse the connection.
-	body, err := io.ReadAll(httpRsp.Body)
+	cacheable := c.cache != nil && httpRsp.StatusCode == http.StatusOK && (isImmutable(ctx) || httpRsp.Header.Get("ETag") != "")
+	var body []byte
+	var size int
+	streamed := stream && decode != nil && httpRsp.StatusCode == http.StatusOK && !cacheable
+	if streamed {
+		r := &prefixReader{r: c.limitBody(httpRsp.Body)}
+		if err = decode(r); err == nil {
+			// Drain the body so http.Client can reuse the connection.
+			_, err = io.Copy(io.Discard, r)
+		}
+		body, size = r.prefix, r.n
+	} else {
+		// Read everything now so http.Client can reuse the connection.
+		body, err = io.ReadAll(c.limitBody(httpRsp.Body))
+		size = len(body)
+	}
 	httpRsp.Body.Close()
+	c.observe(RequestInfo{
+		Method:        http.MethodGet,
+		Path:          path,
+		Attempt:       attempt,
+		Duration:      time.Since(start),
+		StatusCode:    httpRsp.StatusCode,
+		ResponseBytes: size,
+		Err:           err,
+	})
+	c.limiter.update(httpRsp.StatusCode)
+	if err != nil && streamed {
+		return nil, nil, RspError{Err: err, StatusCode: httpRsp.StatusCode, Body: body}
+	}
 	if err != nil {
-		return nil, nil, RspError{Err: fmt.Errorf("failed to read response body: %v", err), StatusCode: httpRsp.StatusCode, Body: body}
+		return httpRsp, nil, RspError{Err: fmt.Errorf("failed to read response body: %w", err), StatusCode: httpRsp.StatusCode, Body: body}
 	}
 
+	if haveCached && httpRsp.StatusCode == http.StatusNotModified {
+		return c.cachedResponse(httpReq, cached, decode)
+	}
 	if httpRsp.StatusCode != http.StatusOK {
-		return nil, nil, RspError{Err: fmt.Errorf("got HTTP Status %q", httpRsp.Status), StatusCode: httpRsp.StatusCode, Body: body}
+		rspErr := RspError{Err: fmt.Errorf("got HTTP Status %q", httpRsp.Status), StatusCode: httpRsp.StatusCode, Body: body}
+		if ra := retryAfter(httpRsp); ra != nil {
+			rspErr.RetryAfter = *ra
+		}
+		return httpRsp, nil, rspErr
+	}
+	if decode != nil && !streamed {
+		if err := decode(bytes.NewReader(body)); err != nil {
+			return nil, nil, RspError{Err: err, StatusCode: httpRsp.StatusCode, Body: body}
+		}
+	}
+	if cacheable {
+		c.cache.Put(fullURI, CacheEntry{ETag: httpRsp.Header.Get("ETag"), Body: body})
+	}
+	return httpRsp, body, nil
+}
+
+// cachedResponse returns a cached response to req, as if it had been
+// received with HTTP status 200.
+func (c *JSONClient) cachedResponse(req *http.Request, cached CacheEntry, decode func(io.Reader) error) (*http.Response, []byte, error) {
+	httpRsp := &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
 	}
+	if cached.ETag != "" {
+		httpRsp.Header.Set("ETag", cached.ETag)
+	}
+	if decode != nil {
+		if err := decode(bytes.NewReader(cached.Body)); err != nil {
+			return nil, nil, RspError{Err: err, StatusCode: httpRsp.StatusCode, Body: cached.Body}
+		}
+	}
+	return httpRsp, cached.Body, nil
+}
 
-	if err := json.NewDecoder(bytes.NewReader(body)).Decode(rsp); err != nil {
-		return nil, nil, RspError{Err: err, StatusCode: httpRsp.StatusCode, Body: body}
+// GetAndDecode makes a HTTP GET call to the given path, and passes the body
+// of a successful response to decode as it is read, rather than reading it
+// into memory first. Returns the http.Response and an error, which is an
+// RspError holding the start of the body if decode fails. Requests are
+// retried as for GetAndParse, so decode may be called more than once.
+func (c *JSONClient) GetAndDecode(ctx context.Context, path string, params map[string]string, decode func(io.Reader) error) (*http.Response, error) {
+	if ctx == nil {
+		return nil, errors.New("context.Context required")
 	}
+	ctx, id := c.withRequestID(ctx)
+	httpRsp, _, err := c.getWithRetry(ctx, path, func(attempt int) (*http.Response, []byte, error) {
+		return c.get(ctx, path, params, attempt, decode, true)
+	})
+	if err != nil {
+		return nil, annotateError(err, id)
+	}
+	return httpRsp, nil
+}
 
-	return httpRsp, body, nil
+// GetRaw makes a HTTP GET call to the given path, and returns the body of
+// the response without parsing it, e.g. for a static file served by a log.
+// Return values and retries are as for GetAndParse.
+func (c *JSONClient) GetRaw(ctx context.Context, path string) (*http.Response, []byte, error) {
+	return c.GetAndParse(ctx, path, nil, nil)
 }
 
 // PostAndParse makes a HTTP POST call to the given path, including the request
@@ -218,6 +428,13 @@ func (c *JSONClient) PostAndParse(ctx context.Context, path string, req, rsp int
 	if ctx == nil {
 		return nil, nil, errors.New("context.Context required")
 	}
+	ctx, id := c.withRequestID(ctx)
+	httpRsp, body, err := c.postAndParse(ctx, path, req, rsp, 1)
+	return httpRsp, body, annotateError(err, id)
+}
+
+// postAndParse makes the given attempt at PostAndParse.
+func (c *JSONClient) postAndParse(ctx context.Context, path string, req, rsp interface{}, attempt int) (*http.Response, []byte, error) {
 	// Build a POST request with JSON body.
 	postBody, err := json.Marshal(req)
 	if err != nil {
@@ -229,19 +446,27 @@ func (c *JSONClient) PostAndParse(ctx context.Context, path string, req, rsp int
 	if err != nil {
 		return nil, nil, err
 	}
-	if len(c.userAgent) != 0 {
-		httpReq.Header.Set("User-Agent", c.userAgent)
-	}
+	c.setHeaders(ctx, httpReq)
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	httpRsp, err := ctxhttp.Do(ctx, c.httpClient, httpReq)
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, nil, err
+	}
+	start := time.Now()
+	httpRsp, err := c.do(ctx, httpReq)
 
 	// Read all of the body, if there is one, so that the http.Client can do Keep-Alive.
 	var body []byte
+	info := RequestInfo{Method: http.MethodPost, Path: path, Attempt: attempt, RequestBytes: len(postBody)}
 	if httpRsp != nil {
-		body, err = io.ReadAll(httpRsp.Body)
+		body, err = io.ReadAll(c.limitBody(httpRsp.Body))
 		httpRsp.Body.Close()
+		info.StatusCode = httpRsp.StatusCode
+		info.ResponseBytes = len(body)
+		c.limiter.update(httpRsp.StatusCode)
 	}
+	info.Duration, info.Err = time.Since(start), err
+	c.observe(info)
 	if err != nil {
 		if httpRsp != nil {
 			return nil, nil, RspError{StatusCode: httpRsp.StatusCode, Body: body, Err: err}
@@ -257,70 +482,93 @@ func (c *JSONClient) PostAndParse(ctx context.Context, path string, req, rsp int
 	return httpRsp, body, nil
 }
 
+// setBackoff records the failure of the given attempt at a POST request, and
+// returns how long to wait before retrying it. A non-nil override is used if
+// it is longer.
+func (c *JSONClient) setBackoff(attempt int, override *time.Duration) time.Duration {
+	if c.postBackoff == nil {
+		return c.backoff.set(override)
+	}
+	wait := c.postBackoff.Next(attempt)
+	if override != nil && *override > wait {
+		wait = *override
+	}
+	return wait
+}
+
 // waitForBackoff blocks until the defined backoff interval or context has expired, if the returned
-// not before time is in the past it returns immediately.
-func (c *JSONClient) waitForBackoff(ctx context.Context) error {
+// not before time is in the past it returns immediately. If the client has a
+// custom Backoff, it waits for the given duration instead.
+func (c *JSONClient) waitForBackoff(ctx context.Context, wait time.Duration) error {
+	if c.postBackoff != nil {
+		return c.clock.Sleep(ctx, wait)
+	}
 	dur := time.Until(c.backoff.until().Add(time.Millisecond * time.Duration(rand.Intn(int(maxJitter.Seconds()*1000)))))
 	if dur < 0 {
 		dur = 0
 	}
-	backoffTimer := time.NewTimer(dur)
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-backoffTimer.C:
-	}
-	return nil
+	return c.clock.Sleep(ctx, dur)
 }
 
 // PostAndParseWithRetry makes a HTTP POST call, but retries (with backoff) on
 // retryable errors; the caller should set a deadline on the provided context
-// to prevent infinite retries.  Return values are as for PostAndParse.
+// to prevent infinite retries, or limit the attempts with RetryOptions.
+// Return values are as for PostAndParse.
 func (c *JSONClient) PostAndParseWithRetry(ctx context.Context, path string, req, rsp interface{}) (*http.Response, []byte, error) {
 	if ctx == nil {
 		return nil, nil, errors.New("context.Context required")
 	}
-	for {
-		httpRsp, body, err := c.PostAndParse(ctx, path, req, rsp)
+	ctx, id := c.withRequestID(ctx)
+	httpRsp, body, err := c.postAndParseWithRetry(ctx, path, req, rsp)
+	return httpRsp, body, annotateError(err, id)
+}
+
+// postAndParseWithRetry implements PostAndParseWithRetry.
+func (c *JSONClient) postAndParseWithRetry(ctx context.Context, path string, req, rsp interface{}) (*http.Response, []byte, error) {
+	for attempt := 1; ; attempt++ {
+		var wait time.Duration
+		httpRsp, body, err := c.postAndParse(ctx, path, req, rsp, attempt)
 		if err != nil {
 			// Don't retry context errors.
 			if err == context.Canceled || err == context.DeadlineExceeded {
 				return nil, nil, err
 			}
-			wait := c.backoff.set(nil)
+			if limit := c.retry.maxAttempts(); limit > 0 && attempt >= limit {
+				return nil, nil, err
+			}
+			wait = c.setBackoff(attempt, nil)
+			c.retry.observe(RetryInfo{Method: http.MethodPost, Path: path, Attempt: attempt, Err: err, Wait: wait})
 			c.logger.Printf("Request to %s failed, backing-off %s: %s", c.uri, wait, err)
 		} else {
-			switch {
-			case httpRsp.StatusCode == http.StatusOK:
+			if httpRsp.StatusCode == http.StatusOK {
 				return httpRsp, body, nil
+			}
+			rspErr := RspError{
+				StatusCode: httpRsp.StatusCode,
+				Body:       body,
+				Err:        fmt.Errorf("got HTTP status %q", httpRsp.Status)}
+			if ra := retryAfter(httpRsp); ra != nil {
+				rspErr.RetryAfter = *ra
+			}
+			if limit := c.retry.maxAttempts(); limit > 0 && attempt >= limit {
+				return nil, nil, rspErr
+			}
+			switch {
 			case httpRsp.StatusCode == http.StatusRequestTimeout:
 				// Request timeout, retry immediately
+				c.retry.observe(RetryInfo{Method: http.MethodPost, Path: path, Attempt: attempt, Err: rspErr})
 				c.logger.Printf("Request to %s timed out, retrying immediately", c.uri)
-			case httpRsp.StatusCode == http.StatusServiceUnavailable:
-				fallthrough
-			case httpRsp.StatusCode == http.StatusTooManyRequests:
-				var backoff *time.Duration
-				// Retry-After may be either a number of seconds as a int or a RFC 1123
-				// date string (RFC 7231 Section 7.1.3)
-				if retryAfter := httpRsp.Header.Get("Retry-After"); retryAfter != "" {
-					if seconds, err := strconv.Atoi(retryAfter); err == nil {
-						b := time.Duration(seconds) * time.Second
-						backoff = &b
-					} else if date, err := time.Parse(time.RFC1123, retryAfter); err == nil {
-						b := time.Until(date)
-						backoff = &b
-					}
-				}
-				wait := c.backoff.set(backoff)
+			case httpRsp.StatusCode == http.StatusServiceUnavailable,
+				httpRsp.StatusCode == http.StatusTooManyRequests,
+				c.retry != nil && retryableStatus(httpRsp.StatusCode):
+				wait = c.setBackoff(attempt, retryAfter(httpRsp))
+				c.retry.observe(RetryInfo{Method: http.MethodPost, Path: path, Attempt: attempt, Err: rspErr, Wait: wait})
 				c.logger.Printf("Request to %s failed, backing-off for %s: got HTTP status %s", c.uri, wait, httpRsp.Status)
 			default:
-				return nil, nil, RspError{
-					StatusCode: httpRsp.StatusCode,
-					Body:       body,
-					Err:        fmt.Errorf("got HTTP status %q", httpRsp.Status)}
+				return nil, nil, rspErr
 			}
 		}
-		if err := c.waitForBackoff(ctx); err != nil {
+		if err := c.waitForBackoff(ctx, wait); err != nil {
 			return nil, nil, err
 		}
 	}
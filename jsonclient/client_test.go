@@ -18,7 +18,10 @@ import (
 	"context"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -29,6 +32,7 @@ import (
 	"time"
 
 	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"golang.org/x/time/rate"
 )
 
 func publicKeyPEMToDER(key string) []byte {
@@ -116,6 +120,51 @@ func TestNewJSONClient(t *testing.T) {
 	}
 }
 
+func TestTransportOptions(t *testing.T) {
+	ctx := context.Background()
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		fmt.Fprintf(w, `{"tree_size": 11, "timestamp": 99}`)
+	}))
+	defer proxy.Close()
+
+	logClient, err := New("http://log.example.com", nil, Options{Transport: &TransportOptions{ProxyURL: proxy.URL}})
+	if err != nil {
+		t.Fatalf("New()=%v", err)
+	}
+	var got TestStruct
+	if _, _, err := logClient.GetAndParse(ctx, "/struct/path", nil, &got); err != nil {
+		t.Fatalf("GetAndParse() through proxy=%v", err)
+	}
+	if len(proxied) != 1 || !strings.HasPrefix(proxied[0], "http://log.example.com/struct/path") {
+		t.Errorf("proxy got requests %v, want one for log.example.com", proxied)
+	}
+
+	ts := MockServer(t, -1, 0)
+	defer ts.Close()
+	dials := 0
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials++
+		var d net.Dialer
+		return d.DialContext(ctx, network, ts.Listener.Addr().String())
+	}
+	logClient, err = New("http://log.example.com", &http.Client{}, Options{Transport: &TransportOptions{DialContext: dial, MaxConnsPerHost: 1}})
+	if err != nil {
+		t.Fatalf("New()=%v", err)
+	}
+	if _, _, err := logClient.GetAndParse(ctx, "/struct/path", nil, &got); err != nil {
+		t.Fatalf("GetAndParse() with custom dialer=%v", err)
+	}
+	if dials != 1 || got.TreeSize != 11 {
+		t.Errorf("GetAndParse()=%+v after %d dials, want tree size 11 after 1", got, dials)
+	}
+
+	if _, err := New("http://log.example.com", nil, Options{Transport: &TransportOptions{ProxyURL: "ftp://proxy"}}); err == nil {
+		t.Error("New() with ftp proxy succeeded, want error")
+	}
+}
+
 type TestStruct struct {
 	TreeSize  int    `json:"tree_size"`
 	Timestamp int    `json:"timestamp"`
@@ -457,7 +506,473 @@ func TestPostAndParseWithRetry(t *testing.T) {
 	}
 }
 
+func TestRetryOptions(t *testing.T) {
+	tests := []struct {
+		desc         string
+		post         bool
+		status       int
+		retryAfter   string
+		failCount    int
+		maxAttempts  int
+		wantErr      string
+		wantAttempts int
+		wantMinWait  time.Duration
+	}{
+		{desc: "get-5xx", status: http.StatusBadGateway, failCount: 2, maxAttempts: 3, wantAttempts: 3},
+		{desc: "get-429-retry-after", status: http.StatusTooManyRequests, retryAfter: "1", failCount: 1, maxAttempts: 3, wantAttempts: 2, wantMinWait: time.Second},
+		{desc: "get-too-many-failures", status: http.StatusServiceUnavailable, failCount: 5, maxAttempts: 3, wantErr: "503", wantAttempts: 3},
+		{desc: "get-not-retryable", status: http.StatusNotFound, failCount: 1, maxAttempts: 3, wantErr: "404", wantAttempts: 1},
+		{desc: "get-no-retries", status: http.StatusServiceUnavailable, failCount: 1, wantErr: "503", wantAttempts: 1},
+		{desc: "post-5xx", post: true, status: http.StatusInternalServerError, failCount: 1, maxAttempts: 3, wantAttempts: 2},
+		{desc: "post-too-many-failures", post: true, status: http.StatusTooManyRequests, failCount: 5, maxAttempts: 2, wantErr: "429", wantAttempts: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var mu sync.Mutex
+			attempts := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				attempts++
+				if attempts <= test.failCount {
+					if test.retryAfter != "" {
+						w.Header().Set("Retry-After", test.retryAfter)
+					}
+					w.WriteHeader(test.status)
+					return
+				}
+				fmt.Fprintf(w, `{"tree_size": 11, "timestamp": 99}`)
+			}))
+			defer ts.Close()
+
+			var retries []RetryInfo
+			opts := Options{Retry: &RetryOptions{
+				MaxAttempts:    test.maxAttempts,
+				InitialBackoff: time.Millisecond,
+				OnRetry:        func(info RetryInfo) { retries = append(retries, info) },
+			}}
+			logClient, err := New(ts.URL, &http.Client{}, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			logClient.backoff = &mockBackoff{}
+
+			var got TestStruct
+			if test.post {
+				_, _, err = logClient.PostAndParseWithRetry(context.Background(), "/retry", nil, &got)
+			} else {
+				_, _, err = logClient.GetAndParse(context.Background(), "/retry", nil, &got)
+			}
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("request error=%v; want error containing %q", err, test.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("request error=%v; want no error", err)
+			} else if got.TreeSize != 11 {
+				t.Errorf("request got %+v; want tree size 11", got)
+			}
+			if attempts != test.wantAttempts {
+				t.Errorf("server got %d attempts; want %d", attempts, test.wantAttempts)
+			}
+			if got, want := len(retries), test.wantAttempts-1; got != want {
+				t.Fatalf("OnRetry called %d times; want %d", got, want)
+			}
+			for i, info := range retries {
+				if info.Attempt != i+1 || info.Path != "/retry" || info.Err == nil {
+					t.Errorf("OnRetry(%+v); want attempt %d at /retry with error", info, i+1)
+				}
+			}
+			if test.wantMinWait > 0 && retries[0].Wait < test.wantMinWait {
+				t.Errorf("OnRetry wait=%s; want at least %s", retries[0].Wait, test.wantMinWait)
+			}
+		})
+	}
+}
+
+// constantBackoff always waits for the same duration.
+type constantBackoff time.Duration
+
+func (b constantBackoff) Next(int) time.Duration { return time.Duration(b) }
+
+func TestCustomBackoff(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	clock := &FakeClock{}
+	opts := Options{
+		Retry:   &RetryOptions{MaxAttempts: 3},
+		Backoff: constantBackoff(time.Hour),
+		Clock:   clock,
+	}
+	logClient, err := New(ts.URL, &http.Client{}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, _, err := logClient.GetAndParse(ctx, "/retry", nil, &TestStruct{}); err == nil {
+		t.Error("GetAndParse()=nil; want error")
+	}
+	if _, _, err := logClient.PostAndParseWithRetry(ctx, "/retry", nil, &TestStruct{}); err == nil {
+		t.Error("PostAndParseWithRetry()=nil; want error")
+	}
+	want := []time.Duration{time.Hour, time.Hour, time.Hour, time.Hour}
+	if got := clock.Sleeps(); !reflect.DeepEqual(got, want) {
+		t.Errorf("slept for %v; want %v", got, want)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"tree_size": 11, "timestamp": 99}`)
+	}))
+	defer ts.Close()
+
+	var order []string
+	var statuses []int
+	opts := Options{Middleware: []Middleware{
+		func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, "outer")
+				req.Header.Set("Authorization", "Bearer token")
+				return next(req)
+			}
+		},
+		func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, "inner")
+				rsp, err := next(req)
+				if err == nil {
+					statuses = append(statuses, rsp.StatusCode)
+				}
+				return rsp, err
+			}
+		},
+	}}
+	logClient, err := New(ts.URL, &http.Client{}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got TestStruct
+	if _, _, err := logClient.GetAndParse(context.Background(), "/", nil, &got); err != nil {
+		t.Fatalf("GetAndParse()=%v", err)
+	}
+	if _, _, err := logClient.PostAndParse(context.Background(), "/", nil, &got); err != nil {
+		t.Fatalf("PostAndParse()=%v", err)
+	}
+	if got.TreeSize != 11 {
+		t.Errorf("got %+v; want tree size 11", got)
+	}
+	if want := []string{"outer", "inner", "outer", "inner"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("middleware called in order %v; want %v", order, want)
+	}
+	if want := []int{http.StatusOK, http.StatusOK}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("middleware saw statuses %v; want %v", statuses, want)
+	}
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	body := `{"tree_size": 11, "timestamp": 99}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer ts.Close()
+
+	for _, test := range []struct {
+		desc    string
+		limit   int64
+		wantErr bool
+	}{
+		{desc: "unlimited"},
+		{desc: "exact", limit: int64(len(body))},
+		{desc: "too-small", limit: int64(len(body)) - 1, wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			logClient, err := New(ts.URL, &http.Client{}, Options{MaxResponseSize: test.limit})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+			var got TestStruct
+			_, _, getErr := logClient.GetAndParse(ctx, "/", nil, &got)
+			_, _, postErr := logClient.PostAndParse(ctx, "/", nil, &got)
+			_, decodeErr := logClient.GetAndDecode(ctx, "/", nil, func(r io.Reader) error {
+				return json.NewDecoder(r).Decode(&got)
+			})
+			for _, err := range []error{getErr, postErr, decodeErr} {
+				if test.wantErr {
+					var rspErr RspError
+					if !errors.Is(err, ErrResponseTooLarge) || !errors.As(err, &rspErr) {
+						t.Errorf("got error %v; want RspError wrapping ErrResponseTooLarge", err)
+					}
+				} else if err != nil {
+					t.Errorf("got error %v; want none", err)
+				}
+			}
+			if !test.wantErr && got.TreeSize != 11 {
+				t.Errorf("got %+v; want tree size 11", got)
+			}
+		})
+	}
+}
+
+func TestGetAndDecode(t *testing.T) {
+	ts := MockServer(t, -1, 0)
+	defer ts.Close()
+	logClient, err := New(ts.URL, &http.Client{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	var got TestStruct
+	if _, err := logClient.GetAndDecode(ctx, "/struct/path", nil, func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&got)
+	}); err != nil {
+		t.Fatalf("GetAndDecode()=%v", err)
+	}
+	if got.TreeSize != 11 {
+		t.Errorf("GetAndDecode() decoded %+v; want tree size 11", got)
+	}
+
+	_, err = logClient.GetAndDecode(ctx, "/struct/path", nil, func(r io.Reader) error {
+		if _, err := io.ReadFull(r, make([]byte, 5)); err != nil {
+			return err
+		}
+		return errors.New("bad response")
+	})
+	var rspErr RspError
+	if !errors.As(err, &rspErr) || rspErr.StatusCode != http.StatusOK || len(rspErr.Body) == 0 {
+		t.Errorf("GetAndDecode()=%v; want RspError with status and body", err)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache(10)
+	c.Put("a", CacheEntry{Body: []byte("aaaa")})
+	c.Put("b", CacheEntry{Body: []byte("bbbb")})
+	c.Get("a")
+	c.Put("c", CacheEntry{Body: []byte("cccc")})
+	c.Put("big", CacheEntry{Body: []byte("0123456789a")})
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "big": false} {
+		if _, got := c.Get(key); got != want {
+			t.Errorf("Get(%q) found=%v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskCache()=%v", err)
+	}
+	want := CacheEntry{ETag: `"v1"`, Body: []byte("body")}
+	c.Put("http://example.com/a", want)
+	got, ok := c.Get("http://example.com/a")
+	if !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("Get()=%+v, %v; want %+v, true", got, ok, want)
+	}
+	if _, ok := c.Get("http://example.com/b"); ok {
+		t.Error("Get() of missing key found an entry")
+	}
+}
+
+func TestCache(t *testing.T) {
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+		if r.URL.Path == "/etag" {
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		fmt.Fprintf(w, `{"tree_size": 11}`)
+	}))
+	defer ts.Close()
+	logClient, err := New(ts.URL, &http.Client{}, Options{Cache: NewMemoryCache(1024)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		path     string
+		ctx      context.Context
+		wantHits int
+	}{
+		{path: "/immutable", ctx: WithImmutableResponse(context.Background()), wantHits: 1},
+		{path: "/etag", ctx: context.Background(), wantHits: 2},
+		{path: "/mutable", ctx: context.Background(), wantHits: 2},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				var got TestStruct
+				if _, _, err := logClient.GetAndParse(test.ctx, test.path, nil, &got); err != nil {
+					t.Fatalf("GetAndParse()=%v", err)
+				}
+				if got.TreeSize != 11 {
+					t.Errorf("GetAndParse() decoded %+v; want tree size 11", got)
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if got := hits[test.path]; got != test.wantHits {
+				t.Errorf("server hit %d times, want %d", got, test.wantHits)
+			}
+		})
+	}
+}
+
+func TestRequestObserver(t *testing.T) {
+	ts := MockServer(t, 1, -1)
+	defer ts.Close()
+
+	var infos []RequestInfo
+	opts := Options{
+		Retry:    &RetryOptions{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+		Observer: RequestObserverFunc(func(info RequestInfo) { infos = append(infos, info) }),
+	}
+	logClient, err := New(ts.URL, &http.Client{}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	var got TestStruct
+	if _, _, err := logClient.GetAndParse(ctx, "/retry", nil, &got); err != nil {
+		t.Fatalf("GetAndParse()=%v", err)
+	}
+	if _, _, err := logClient.PostAndParse(ctx, "/struct/params", TestStruct{TreeSize: 1}, &got); err != nil {
+		t.Fatalf("PostAndParse()=%v", err)
+	}
+
+	want := []RequestInfo{
+		{Method: http.MethodGet, Path: "/retry", Attempt: 1, StatusCode: http.StatusServiceUnavailable},
+		{Method: http.MethodGet, Path: "/retry", Attempt: 2, StatusCode: http.StatusOK},
+		{Method: http.MethodPost, Path: "/struct/params", Attempt: 1, StatusCode: http.StatusOK},
+	}
+	if len(infos) != len(want) {
+		t.Fatalf("observed %d requests, want %d: %+v", len(infos), len(want), infos)
+	}
+	for i, info := range infos {
+		if info.Method != want[i].Method || info.Path != want[i].Path || info.Attempt != want[i].Attempt || info.StatusCode != want[i].StatusCode || info.Err != nil {
+			t.Errorf("request %d: observed %+v, want %+v", i, info, want[i])
+		}
+		if info.Duration <= 0 {
+			t.Errorf("request %d: observed duration %s, want > 0", i, info.Duration)
+		}
+	}
+	if infos[1].ResponseBytes == 0 || infos[2].RequestBytes == 0 {
+		t.Errorf("observed %+v, want request and response sizes", infos)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var ids []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get("X-Request-ID"))
+		if r.UserAgent() != "test-agent" {
+			t.Errorf("got User-Agent %q, want test-agent", r.UserAgent())
+		}
+		if len(ids) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	opts := Options{
+		UserAgent:       "test-agent",
+		RequestIDHeader: "X-Request-ID",
+		Retry:           &RetryOptions{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+	}
+	logClient, err := New(ts.URL, &http.Client{}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	_, _, err = logClient.GetAndParse(ctx, "/fail", nil, &TestStruct{})
+	var rspErr RspError
+	if !errors.As(err, &rspErr) || rspErr.RequestID == "" {
+		t.Fatalf("GetAndParse()=%v, want RspError with request ID", err)
+	}
+	if len(ids) != 2 || ids[0] != rspErr.RequestID || ids[1] != rspErr.RequestID {
+		t.Errorf("sent request IDs %q, want %q for both attempts", ids, rspErr.RequestID)
+	}
+	if !strings.Contains(err.Error(), rspErr.RequestID) {
+		t.Errorf("GetAndParse() error %q does not contain request ID", err)
+	}
+
+	_, _, err = logClient.PostAndParse(WithRequestID(ctx, "my-id"), "/fail", TestStruct{}, &TestStruct{})
+	if err != nil {
+		t.Fatalf("PostAndParse()=%v", err)
+	}
+	if got := ids[len(ids)-1]; got != "my-id" {
+		t.Errorf("sent request ID %q, want my-id", got)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	ts := MockServer(t, -1, 0)
+	defer ts.Close()
+
+	limiter := rate.NewLimiter(1000, 1)
+	logClient, err := New(ts.URL, &http.Client{}, Options{RateLimiter: limiter})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	var got TestStruct
+	for i, want := range []struct {
+		path  string
+		limit rate.Limit
+	}{
+		{path: "/struct/path", limit: 1000},
+		{path: "/error", limit: 500},
+		{path: "/error", limit: 250},
+		{path: "/struct/path", limit: 262.5},
+	} {
+		logClient.GetAndParse(ctx, want.path, map[string]string{"rc": "429"}, &got) // nolint:errcheck
+		if diff := limiter.Limit() - want.limit; diff > 0.01 || diff < -0.01 {
+			t.Errorf("request %d to %s: limit=%v, want %v", i, want.path, limiter.Limit(), want.limit)
+		}
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, err := logClient.GetAndParse(cctx, "/struct/path", nil, &got); err == nil {
+		t.Error("GetAndParse() with cancelled context succeeded, want error from limiter")
+	}
+}
+
 // nolint:staticcheck
+func TestRspErrorRetryAfter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+	logClient, err := New(ts.URL, &http.Client{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got TestStruct
+	_, _, err = logClient.GetAndParse(context.Background(), "/throttled", nil, &got)
+	var rspErr RspError
+	if !errors.As(err, &rspErr) || rspErr.RetryAfter != 7*time.Second {
+		t.Errorf("GetAndParse()=%v; want RspError with RetryAfter 7s", err)
+	}
+}
+
 func TestContextRequired(t *testing.T) {
 	ts := MockServer(t, -1, 0)
 	defer ts.Close()
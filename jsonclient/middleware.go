@@ -0,0 +1,54 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// RoundTripFunc sends an HTTP request and returns its response.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware wraps the sending of each HTTP request made by a JSONClient,
+// including each retry. It may modify the request, e.g. to add
+// authentication or tracing headers, before calling next, and may inspect or
+// replace the response. A middleware which reads the response body must
+// replace it for the client to parse.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// chainMiddleware returns a RoundTripFunc which sends requests with hc
+// through the given middleware, the first of which is outermost.
+func chainMiddleware(hc *http.Client, middleware []Middleware) RoundTripFunc {
+	rt := RoundTripFunc(hc.Do)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		rt = middleware[i](rt)
+	}
+	return rt
+}
+
+// do sends req with the context ctx through the client's middleware. If ctx
+// is done, its error is returned in place of any other.
+func (c *JSONClient) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	rsp, err := c.roundTrip(req.WithContext(ctx))
+	if err != nil {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		default:
+		}
+	}
+	return rsp, err
+}
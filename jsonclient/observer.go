@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonclient
+
+import "time"
+
+// RequestObserver is notified of each HTTP request made by a JSONClient, so
+// that callers can record metrics or traces for them.
+type RequestObserver interface {
+	// ObserveRequest is called once the response to a request has been read,
+	// or the request has failed. It must be safe for concurrent use.
+	ObserveRequest(RequestInfo)
+}
+
+// RequestObserverFunc adapts a function to the RequestObserver interface.
+type RequestObserverFunc func(RequestInfo)
+
+// ObserveRequest calls f(info).
+func (f RequestObserverFunc) ObserveRequest(info RequestInfo) {
+	f(info)
+}
+
+// RequestInfo describes a single HTTP request made by a JSONClient.
+type RequestInfo struct {
+	Method string
+	// Path is the endpoint path, relative to the base URI and without any
+	// query parameters, e.g. "/ct/v1/get-sth".
+	Path string
+	// Attempt is 1 for the first attempt at a request, and one more for each
+	// retry.
+	Attempt int
+	// Duration is the time taken to send the request and read the response.
+	Duration time.Duration
+	// StatusCode is the HTTP status of the response, or 0 if there was none.
+	StatusCode int
+	// RequestBytes and ResponseBytes are the sizes of the request and
+	// response bodies.
+	RequestBytes  int
+	ResponseBytes int
+	// Err is the error, if any, from sending the request or reading the
+	// response. Responses with error statuses are not reported as errors.
+	Err error
+}
+
+// observe reports a request to the client's RequestObserver, if it has one.
+func (c *JSONClient) observe(info RequestInfo) {
+	if c.observer != nil {
+		c.observer.ObserveRequest(info)
+	}
+}
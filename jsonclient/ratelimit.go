@@ -0,0 +1,89 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// throttledRateFactor is the factor by which the rate limit is reduced
+	// when the server returns HTTP 429.
+	throttledRateFactor = 0.5
+	// recoveryRateFactor is the factor by which a reduced rate limit is
+	// increased after each successful request, up to the original limit.
+	recoveryRateFactor = 1.05
+	// minRateFraction is the lowest fraction of the original limit to which
+	// the rate limit is reduced.
+	minRateFraction = 1.0 / 64
+)
+
+// rateLimiter wraps a rate.Limiter whose limit adapts to the responses of
+// the server.
+type rateLimiter struct {
+	limiter *rate.Limiter
+	max     rate.Limit
+
+	mu sync.Mutex
+}
+
+func newRateLimiter(l *rate.Limiter) *rateLimiter {
+	if l == nil {
+		return nil
+	}
+	return &rateLimiter{limiter: l, max: l.Limit()}
+}
+
+// wait blocks until a request may be sent.
+func (r *rateLimiter) wait(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	return r.limiter.Wait(ctx)
+}
+
+// update adjusts the limit after a response with the given status: it is
+// cut when the server is throttling the client, and recovers gradually
+// towards the original limit after successes.
+func (r *rateLimiter) update(statusCode int) {
+	if r == nil || r.max == rate.Inf {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	limit := r.limiter.Limit()
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		limit *= throttledRateFactor
+		if floor := r.max * minRateFraction; limit < floor {
+			limit = floor
+		}
+	case http.StatusOK:
+		if limit == r.max {
+			return
+		}
+		limit *= recoveryRateFactor
+		if limit > r.max {
+			limit = r.max
+		}
+	default:
+		return
+	}
+	r.limiter.SetLimit(limit)
+}
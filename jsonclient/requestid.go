@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonclient
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context which makes a JSONClient with a
+// RequestIDHeader send the given ID with its requests, instead of generating
+// one.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID set on ctx, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+// withRequestID returns ctx with a request ID set, generating a new one if
+// needed, along with the ID. Returns ctx and an empty ID if the client does
+// not send request IDs. The same ID is used for all attempts at a request.
+func (c *JSONClient) withRequestID(ctx context.Context) (context.Context, string) {
+	if c.requestIDHeader == "" {
+		return ctx, ""
+	}
+	if id, ok := RequestIDFromContext(ctx); ok {
+		return ctx, id
+	}
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		c.logger.Printf("Failed to generate request ID: %v", err)
+		return ctx, ""
+	}
+	id := hex.EncodeToString(b[:])
+	return WithRequestID(ctx, id), id
+}
+
+// setHeaders sets the User-Agent and request ID headers of req, if the
+// client sends them.
+func (c *JSONClient) setHeaders(ctx context.Context, req *http.Request) {
+	if len(c.userAgent) != 0 {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.requestIDHeader != "" {
+		if id, ok := RequestIDFromContext(ctx); ok {
+			req.Header.Set(c.requestIDHeader, id)
+		}
+	}
+}
+
+// annotateError records the request ID in err, if it is an RspError.
+func annotateError(err error, id string) error {
+	if rspErr, ok := err.(RspError); ok && id != "" {
+		rspErr.RequestID = id
+		return rspErr
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = time.Second * (1 << (maxMultiplier - 1))
+)
+
+// RetryOptions configures the retrying of requests which fail with a
+// transport error, or with HTTP status 429 (Too Many Requests) or 5xx.
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of attempts at each request,
+	// including the first. GetAndParse only retries if it is greater than
+	// one; PostAndParseWithRetry retries until its context expires if it is
+	// zero.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry of a GET request,
+	// which doubles for each further retry up to MaxBackoff. Up to 250ms of
+	// random jitter is added to each wait, and a longer Retry-After in the
+	// response is honored. Zero values use defaults of 1s and 128s. These
+	// are ignored if Options.Backoff is set.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// OnRetry, if set, is called before waiting to retry a failed request.
+	OnRetry func(RetryInfo)
+}
+
+// RetryInfo describes a failed request which is about to be retried.
+type RetryInfo struct {
+	Method string
+	Path   string
+	// Attempt is the number of attempts made so far.
+	Attempt int
+	// Err is the error from the latest attempt.
+	Err error
+	// Wait is how long the client waits before the next attempt.
+	Wait time.Duration
+}
+
+// maxAttempts returns the maximum number of attempts at a request, or zero
+// for no limit.
+func (o *RetryOptions) maxAttempts() int {
+	if o == nil {
+		return 0
+	}
+	return o.MaxAttempts
+}
+
+// observe calls the OnRetry hook, if any.
+func (o *RetryOptions) observe(info RetryInfo) {
+	if o != nil && o.OnRetry != nil {
+		o.OnRetry(info)
+	}
+}
+
+// backoff returns the Backoff described by the options.
+func (o *RetryOptions) backoff() ExponentialBackoff {
+	b := ExponentialBackoff{Jitter: maxJitter}
+	if o != nil {
+		b.Initial, b.Max = o.InitialBackoff, o.MaxBackoff
+	}
+	return b
+}
+
+// retryableStatus reports whether a request which got the given HTTP status
+// may succeed if retried.
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// retryAfter parses the Retry-After header of the response, which may be
+// either a number of seconds as an int or an RFC 1123 date string (RFC 7231
+// Section 7.1.3). Returns nil if there is no valid header.
+func retryAfter(httpRsp *http.Response) *time.Duration {
+	if httpRsp == nil {
+		return nil
+	}
+	value := httpRsp.Header.Get("Retry-After")
+	if value == "" {
+		return nil
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		b := time.Duration(seconds) * time.Second
+		return &b
+	}
+	if date, err := time.Parse(time.RFC1123, value); err == nil {
+		b := time.Until(date)
+		return &b
+	}
+	return nil
+}
+
+// getWithRetry makes a GET request with get, retrying it as configured by
+// c.retry. The http.Response is returned along with any error.
+func (c *JSONClient) getWithRetry(ctx context.Context, path string, get func(attempt int) (*http.Response, []byte, error)) (*http.Response, []byte, error) {
+	for attempt := 1; ; attempt++ {
+		httpRsp, body, err := get(attempt)
+		if err == nil || attempt >= c.retry.maxAttempts() || ctx.Err() != nil {
+			return httpRsp, body, err
+		}
+		var rspErr RspError
+		if errors.As(err, &rspErr) && !retryableStatus(rspErr.StatusCode) {
+			return httpRsp, body, err
+		}
+
+		wait := c.getBackoff.Next(attempt)
+		if ra := retryAfter(httpRsp); ra != nil && *ra > wait {
+			wait = *ra
+		}
+		c.retry.observe(RetryInfo{Method: http.MethodGet, Path: path, Attempt: attempt, Err: err, Wait: wait})
+		c.logger.Printf("Request to %s%s failed, retrying in %s: %s", c.uri, path, wait, err)
+
+		if err := c.clock.Sleep(ctx, wait); err != nil {
+			return nil, nil, err
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TransportOptions configure the HTTP transport of a JSONClient, so that
+// clients in restricted networks can reach logs without changing
+// http.DefaultTransport.
+type TransportOptions struct {
+	// ProxyURL is the URL of an HTTP, HTTPS or SOCKS5 proxy through which to
+	// send requests, e.g. "socks5://localhost:1080". If unset, the proxy is
+	// taken from the environment as usual.
+	ProxyURL string
+	// DialContext, if set, is used to make network connections.
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	// MaxIdleConnsPerHost, MaxConnsPerHost and IdleConnTimeout tune the
+	// connection pool, as for http.Transport. Zero values leave the
+	// transport's settings unchanged.
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+}
+
+// httpClient returns a copy of hc whose transport is a clone of hc's (or of
+// http.DefaultTransport if hc does not have an http.Transport), adjusted by
+// the options.
+func (o *TransportOptions) httpClient(hc *http.Client) (*http.Client, error) {
+	base, ok := hc.Transport.(*http.Transport)
+	if !ok {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	t := base.Clone()
+	if len(o.ProxyURL) > 0 {
+		u, err := url.Parse(o.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %v", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	if o.DialContext != nil {
+		t.DialContext = o.DialContext
+	}
+	if o.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	}
+	if o.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = o.MaxConnsPerHost
+	}
+	if o.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = o.IdleConnTimeout
+	}
+	client := *hc
+	client.Transport = t
+	return &client, nil
+}
@@ -38,21 +38,33 @@ func (ll *LogList) SelectByStatus(lstats []LogStatus) LogList {
 	for _, op := range ll.Operators {
 		activeOp := *op
 		activeOp.Logs = []*Log{}
+		activeOp.TiledLogs = nil
 		for _, l := range op.Logs {
-			for _, lstat := range lstats {
-				if l.State.LogStatus() == lstat {
-					activeOp.Logs = append(activeOp.Logs, l)
-					break
-				}
+			if hasStatus(l.State, lstats) {
+				activeOp.Logs = append(activeOp.Logs, l)
 			}
 		}
-		if len(activeOp.Logs) > 0 {
+		for _, l := range op.TiledLogs {
+			if hasStatus(l.State, lstats) {
+				activeOp.TiledLogs = append(activeOp.TiledLogs, l)
+			}
+		}
+		if len(activeOp.Logs) > 0 || len(activeOp.TiledLogs) > 0 {
 			active.Operators = append(active.Operators, &activeOp)
 		}
 	}
 	return active
 }
 
+func hasStatus(state *LogStates, lstats []LogStatus) bool {
+	for _, lstat := range lstats {
+		if state.LogStatus() == lstat {
+			return true
+		}
+	}
+	return false
+}
+
 // RootCompatible creates a new LogList containing only the logs of original
 // LogList that are compatible with the provided cert, according to
 // the passed in collection of per-log roots. Logs that are missing from
@@ -71,30 +83,40 @@ func (ll *LogList) RootCompatible(certRoot *x509.Certificate, roots LogRoots) Lo
 	for _, op := range ll.Operators {
 		compatibleOp := *op
 		compatibleOp.Logs = []*Log{}
+		compatibleOp.TiledLogs = nil
 		for _, l := range op.Logs {
-			// If root set is not defined, we treat Log as compatible assuming no
-			// knowledge of its roots.
-			if _, ok := roots[l.URL]; !ok {
+			if rootAccepted(certRoot, roots, l.URL) {
 				compatibleOp.Logs = append(compatibleOp.Logs, l)
-				continue
-			}
-
-			if certRoot == nil {
-				continue
 			}
-
-			// Check root is accepted.
-			if roots[l.URL].Included(certRoot) {
-				compatibleOp.Logs = append(compatibleOp.Logs, l)
+		}
+		for _, l := range op.TiledLogs {
+			if rootAccepted(certRoot, roots, l.SubmissionURL) {
+				compatibleOp.TiledLogs = append(compatibleOp.TiledLogs, l)
 			}
 		}
-		if len(compatibleOp.Logs) > 0 {
+		if len(compatibleOp.Logs) > 0 || len(compatibleOp.TiledLogs) > 0 {
 			compatible.Operators = append(compatible.Operators, &compatibleOp)
 		}
 	}
 	return compatible
 }
 
+// rootAccepted reports whether the log at url accepts certRoot, according to
+// roots.
+func rootAccepted(certRoot *x509.Certificate, roots LogRoots, url string) bool {
+	// If root set is not defined, we treat Log as compatible assuming no
+	// knowledge of its roots.
+	pool, ok := roots[url]
+	if !ok {
+		return true
+	}
+	if certRoot == nil {
+		return false
+	}
+	// Check root is accepted.
+	return pool.Included(certRoot)
+}
+
 // TemporallyCompatible creates a new LogList containing only the logs of
 // original LogList that are compatible with the provided cert, according to
 // NotAfter and TemporalInterval matching.
@@ -108,16 +130,18 @@ func (ll *LogList) TemporallyCompatible(cert *x509.Certificate) LogList {
 	for _, op := range ll.Operators {
 		compatibleOp := *op
 		compatibleOp.Logs = []*Log{}
+		compatibleOp.TiledLogs = nil
 		for _, l := range op.Logs {
-			if l.TemporalInterval == nil {
+			if l.TemporalInterval.Contains(cert.NotAfter) {
 				compatibleOp.Logs = append(compatibleOp.Logs, l)
-				continue
 			}
-			if cert.NotAfter.Before(l.TemporalInterval.EndExclusive) && (cert.NotAfter.After(l.TemporalInterval.StartInclusive) || cert.NotAfter.Equal(l.TemporalInterval.StartInclusive)) {
-				compatibleOp.Logs = append(compatibleOp.Logs, l)
+		}
+		for _, l := range op.TiledLogs {
+			if l.TemporalInterval.Contains(cert.NotAfter) {
+				compatibleOp.TiledLogs = append(compatibleOp.TiledLogs, l)
 			}
 		}
-		if len(compatibleOp.Logs) > 0 {
+		if len(compatibleOp.Logs) > 0 || len(compatibleOp.TiledLogs) > 0 {
 			compatible.Operators = append(compatible.Operators, &compatibleOp)
 		}
 	}
@@ -292,3 +292,39 @@ func TestCompatible(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterTiledLogs(t *testing.T) {
+	tiled := &TiledLog{
+		Description:   "Tiled log",
+		SubmissionURL: "https://tiled.example.com/",
+		MonitoringURL: "https://mon.tiled.example.com/",
+		State:         &LogStates{Usable: &LogState{}},
+		TemporalInterval: &TemporalInterval{
+			StartInclusive: mustParseTime(time.RFC3339, "2025-01-01T00:00:00Z"),
+			EndExclusive:   mustParseTime(time.RFC3339, "2026-01-01T00:00:00Z"),
+		},
+	}
+	ll := LogList{Operators: []*Operator{{Name: "Tiles", TiledLogs: []*TiledLog{tiled}}}}
+	hasTiled := func(ll LogList) bool {
+		return len(ll.Operators) == 1 && len(ll.Operators[0].TiledLogs) == 1 && ll.Operators[0].TiledLogs[0] == tiled
+	}
+
+	for _, test := range []struct {
+		name string
+		got  LogList
+		want bool
+	}{
+		{name: "Usable", got: ll.SelectByStatus([]LogStatus{UsableLogStatus}), want: true},
+		{name: "Retired", got: ll.SelectByStatus([]LogStatus{RetiredLogStatus})},
+		{name: "InInterval", got: ll.TemporallyCompatible(&x509.Certificate{NotAfter: mustParseTime(time.RFC3339, "2025-01-01T00:00:00Z")}), want: true},
+		{name: "AfterInterval", got: ll.TemporallyCompatible(&x509.Certificate{NotAfter: mustParseTime(time.RFC3339, "2026-01-01T00:00:00Z")})},
+		{name: "UnknownRoots", got: ll.RootCompatible(nil, LogRoots{}), want: true},
+		{name: "NoRoot", got: ll.RootCompatible(nil, LogRoots{tiled.SubmissionURL: x509util.NewPEMCertPool()})},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := hasTiled(test.got); got != test.want {
+				t.Errorf("filtered log list %s tiled log: %t; want %t", pretty.Sprint(test.got), got, test.want)
+			}
+		})
+	}
+}
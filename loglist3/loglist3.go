@@ -68,6 +68,9 @@ type Operator struct {
 	Email []string `json:"email"`
 	// Logs is a list of CT logs run by this operator.
 	Logs []*Log `json:"logs"`
+	// TiledLogs is a list of CT logs implementing the Static CT API run by
+	// this operator.
+	TiledLogs []*TiledLog `json:"tiled_logs,omitempty"`
 }
 
 // Log describes a single CT log.
@@ -98,6 +101,36 @@ type Log struct {
 	Type string `json:"log_type,omitempty"`
 }
 
+// TiledLog describes a single CT log implementing the Static CT API, which
+// serves its tree as tiles rather than through the RFC 6962 HTTPS API.
+type TiledLog struct {
+	// Description is a human-readable string that describes the log.
+	Description string `json:"description,omitempty"`
+	// LogID is the SHA-256 hash of the log's public key.
+	LogID []byte `json:"log_id"`
+	// Key is the public key with which signatures can be verified.
+	Key []byte `json:"key"`
+	// SubmissionURL is the address of the submission API.
+	SubmissionURL string `json:"submission_url"`
+	// MonitoringURL is the address from which the tiles, checkpoints and
+	// issuers of the log are served.
+	MonitoringURL string `json:"monitoring_url"`
+	// MMD is the Maximum Merge Delay, in seconds. All submitted
+	// certificates must be incorporated into the log within this time.
+	MMD int32 `json:"mmd"`
+	// PreviousOperators is a list of previous operators and the timestamp
+	// of when they stopped running the log.
+	PreviousOperators []*PreviousOperator `json:"previous_operators,omitempty"`
+	// State is the current state of the log, from the perspective of the
+	// log list distributor.
+	State *LogStates `json:"state,omitempty"`
+	// TemporalInterval, if set, indicates that this log only accepts
+	// certificates with a NotAfter date in this time range.
+	TemporalInterval *TemporalInterval `json:"temporal_interval,omitempty"`
+	// Type indicates the purpose of this log, e.g. "test" or "prod".
+	Type string `json:"log_type,omitempty"`
+}
+
 // PreviousOperator holds information about a log operator and the time at which
 // they stopped running a log.
 type PreviousOperator struct {
@@ -115,6 +148,15 @@ type TemporalInterval struct {
 	EndExclusive time.Time `json:"end_exclusive"`
 }
 
+// Contains reports whether t falls within the time range; a nil
+// TemporalInterval contains all times.
+func (ti *TemporalInterval) Contains(t time.Time) bool {
+	if ti == nil {
+		return true
+	}
+	return t.Before(ti.EndExclusive) && !t.Before(ti.StartInclusive)
+}
+
 // LogStatus indicates Log status.
 type LogStatus int
 
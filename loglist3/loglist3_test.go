@@ -542,6 +542,42 @@ func TestGoogleOperated(t *testing.T) {
 	}
 }
 
+func TestNewFromJSONTiledLogs(t *testing.T) {
+	data := `{"version":"64.3","log_list_timestamp":"2025-06-02T12:55:21Z","operators":[` +
+		`{"name":"Let's Encrypt","email":["sre@letsencrypt.org"],"logs":[],"tiled_logs":[` +
+		`{"description":"Let's Encrypt 'Willow2025h2d' log","log_id":"KTxRllTIOWW6qlD8WAfUt2+/WHopctykwwz05UVH9Hg=",` +
+		`"key":"MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAETtK8v7MICve56qTHHDhhBOuV4IlUaESxZryCfk9QbG9co/CqPvTsgPDbCpp6oFtyAHwlDhnvr7JijXRD9Cb2FA==",` +
+		`"submission_url":"https://willow.ct.letsencrypt.org/2025h2d/","monitoring_url":"https://mon.willow.ct.letsencrypt.org/2025h2d/",` +
+		`"mmd":60,"state":{"qualified":{"timestamp":"2025-05-22T18:15:00Z"}},` +
+		`"temporal_interval":{"start_inclusive":"2025-06-20T00:00:00Z","end_exclusive":"2026-01-20T00:00:00Z"},"log_type":"prod"}]}]}`
+	ll, err := NewFromJSON([]byte(data))
+	if err != nil {
+		t.Fatalf("NewFromJSON()=nil,%v; want _,nil", err)
+	}
+	want := &TiledLog{
+		Description:   "Let's Encrypt 'Willow2025h2d' log",
+		LogID:         deb64("KTxRllTIOWW6qlD8WAfUt2+/WHopctykwwz05UVH9Hg="),
+		Key:           deb64("MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAETtK8v7MICve56qTHHDhhBOuV4IlUaESxZryCfk9QbG9co/CqPvTsgPDbCpp6oFtyAHwlDhnvr7JijXRD9Cb2FA=="),
+		SubmissionURL: "https://willow.ct.letsencrypt.org/2025h2d/",
+		MonitoringURL: "https://mon.willow.ct.letsencrypt.org/2025h2d/",
+		MMD:           60,
+		State: &LogStates{
+			Qualified: &LogState{Timestamp: mustParseTime(time.RFC3339, "2025-05-22T18:15:00Z")},
+		},
+		TemporalInterval: &TemporalInterval{
+			StartInclusive: mustParseTime(time.RFC3339, "2025-06-20T00:00:00Z"),
+			EndExclusive:   mustParseTime(time.RFC3339, "2026-01-20T00:00:00Z"),
+		},
+		Type: "prod",
+	}
+	if len(ll.Operators) != 1 || len(ll.Operators[0].TiledLogs) != 1 {
+		t.Fatalf("NewFromJSON()=%+v; want one operator with one tiled log", ll)
+	}
+	if got := ll.Operators[0].TiledLogs[0]; !reflect.DeepEqual(got, want) {
+		t.Errorf("NewFromJSON() tiled log=%+v; want %+v", got, want)
+	}
+}
+
 func deb64(b string) []byte {
 	data, err := base64.StdEncoding.DecodeString(b)
 	if err != nil {
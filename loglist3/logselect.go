@@ -0,0 +1,140 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loglist3
+
+import (
+	"crypto/sha256"
+	"time"
+)
+
+// LogInfo refers to a log of a LogList, which is either an RFC 6962 log or a
+// Static CT API log, together with its operator.
+type LogInfo struct {
+	Operator *Operator
+	// Exactly one of Log and TiledLog is set.
+	Log      *Log
+	TiledLog *TiledLog
+}
+
+// Description returns the description of the log.
+func (li LogInfo) Description() string {
+	if li.TiledLog != nil {
+		return li.TiledLog.Description
+	}
+	return li.Log.Description
+}
+
+// LogID returns the SHA-256 hash of the log's public key.
+func (li LogInfo) LogID() []byte {
+	if li.TiledLog != nil {
+		return li.TiledLog.LogID
+	}
+	return li.Log.LogID
+}
+
+// Key returns the DER-encoded public key of the log.
+func (li LogInfo) Key() []byte {
+	if li.TiledLog != nil {
+		return li.TiledLog.Key
+	}
+	return li.Log.Key
+}
+
+// MMD returns the Maximum Merge Delay of the log, in seconds.
+func (li LogInfo) MMD() int32 {
+	if li.TiledLog != nil {
+		return li.TiledLog.MMD
+	}
+	return li.Log.MMD
+}
+
+// State returns the current state of the log.
+func (li LogInfo) State() *LogStates {
+	if li.TiledLog != nil {
+		return li.TiledLog.State
+	}
+	return li.Log.State
+}
+
+// TemporalInterval returns the range of NotAfter dates accepted by the log,
+// if it is restricted.
+func (li LogInfo) TemporalInterval() *TemporalInterval {
+	if li.TiledLog != nil {
+		return li.TiledLog.TemporalInterval
+	}
+	return li.Log.TemporalInterval
+}
+
+// AllLogs returns all the logs of the list, of both kinds.
+func (ll *LogList) AllLogs() []LogInfo {
+	var logs []LogInfo
+	for _, op := range ll.Operators {
+		for _, l := range op.Logs {
+			logs = append(logs, LogInfo{Operator: op, Log: l})
+		}
+		for _, l := range op.TiledLogs {
+			logs = append(logs, LogInfo{Operator: op, TiledLog: l})
+		}
+	}
+	return logs
+}
+
+// UsableLogs returns the logs of the list which are usable, and accept
+// certificates with the given NotAfter date.
+func (ll *LogList) UsableLogs(notAfter time.Time) []LogInfo {
+	var logs []LogInfo
+	for _, li := range ll.AllLogs() {
+		if li.State().LogStatus() == UsableLogStatus && li.TemporalInterval().Contains(notAfter) {
+			logs = append(logs, li)
+		}
+	}
+	return logs
+}
+
+// GroupByOperator groups logs by the name of their operator, to help meet
+// requirements on the diversity of the operators of SCTs.
+func GroupByOperator(logs []LogInfo) map[string][]LogInfo {
+	groups := make(map[string][]LogInfo)
+	for _, li := range logs {
+		groups[li.Operator.Name] = append(groups[li.Operator.Name], li)
+	}
+	return groups
+}
+
+// LogIndex finds the logs of a LogList from their log IDs, for example to
+// verify SCTs.
+type LogIndex map[[sha256.Size]byte]LogInfo
+
+// Index returns a LogIndex of all the logs of the list. Logs with malformed
+// log IDs are left out.
+func (ll *LogList) Index() LogIndex {
+	idx := make(LogIndex)
+	for _, li := range ll.AllLogs() {
+		var id [sha256.Size]byte
+		if len(li.LogID()) != len(id) {
+			continue
+		}
+		copy(id[:], li.LogID())
+		idx[id] = li
+	}
+	return idx
+}
+
+// Find returns the log with the given log ID, which is the SHA-256 hash of
+// its public key.
+func (idx LogIndex) Find(logID [sha256.Size]byte) (LogInfo, bool) {
+	li, ok := idx[logID]
+	return li, ok
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loglist3
+
+import (
+	"crypto/sha256"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// selectLogList holds logs of both kinds, with and without temporal
+// intervals, across two operators.
+func selectLogList() *LogList {
+	usable := &LogStates{Usable: &LogState{}}
+	interval := &TemporalInterval{
+		StartInclusive: mustParseTime(time.RFC3339, "2025-01-01T00:00:00Z"),
+		EndExclusive:   mustParseTime(time.RFC3339, "2026-01-01T00:00:00Z"),
+	}
+	id := func(b byte) []byte {
+		h := sha256.Sum256([]byte{b})
+		return h[:]
+	}
+	return &LogList{Operators: []*Operator{
+		{
+			Name: "A",
+			Logs: []*Log{
+				{Description: "A 2025", LogID: id(1), State: usable, TemporalInterval: interval},
+				{Description: "A unsharded", LogID: id(2), State: usable},
+				{Description: "A retired", LogID: id(3), State: &LogStates{Retired: &LogState{}}},
+			},
+		},
+		{
+			Name:      "B",
+			Logs:      []*Log{{Description: "B bad ID", LogID: []byte{1, 2}, State: usable}},
+			TiledLogs: []*TiledLog{{Description: "B tiled 2025", LogID: id(4), State: usable, TemporalInterval: interval}},
+		},
+	}}
+}
+
+func descriptions(logs []LogInfo) []string {
+	var descs []string
+	for _, li := range logs {
+		descs = append(descs, li.Description())
+	}
+	sort.Strings(descs)
+	return descs
+}
+
+func TestUsableLogs(t *testing.T) {
+	ll := selectLogList()
+	for _, test := range []struct {
+		notAfter string
+		want     []string
+	}{
+		{notAfter: "2025-06-01T00:00:00Z", want: []string{"A 2025", "A unsharded", "B bad ID", "B tiled 2025"}},
+		{notAfter: "2026-06-01T00:00:00Z", want: []string{"A unsharded", "B bad ID"}},
+	} {
+		t.Run(test.notAfter, func(t *testing.T) {
+			got := descriptions(ll.UsableLogs(mustParseTime(time.RFC3339, test.notAfter)))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("UsableLogs()=%v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGroupByOperator(t *testing.T) {
+	ll := selectLogList()
+	groups := GroupByOperator(ll.UsableLogs(mustParseTime(time.RFC3339, "2025-06-01T00:00:00Z")))
+	if len(groups) != 2 || len(groups["A"]) != 2 || len(groups["B"]) != 2 {
+		t.Errorf("GroupByOperator()=%v; want 2 logs for each of A and B", groups)
+	}
+}
+
+func TestLogIndex(t *testing.T) {
+	idx := selectLogList().Index()
+	if len(idx) != 4 {
+		t.Errorf("Index() has %d logs; want 4 with valid log IDs", len(idx))
+	}
+	for _, test := range []struct {
+		b    byte
+		want string
+	}{
+		{b: 1, want: "A 2025"},
+		{b: 4, want: "B tiled 2025"},
+		{b: 5},
+	} {
+		li, ok := idx.Find(sha256.Sum256([]byte{test.b}))
+		if test.want == "" {
+			if ok {
+				t.Errorf("Find(%d)=%v,true; want _,false", test.b, li.Description())
+			}
+			continue
+		}
+		if !ok || li.Description() != test.want {
+			t.Errorf("Find(%d)=%v,%t; want %q,true", test.b, li, ok, test.want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loglist3
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/schedule"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// LogListPublicKeyURL has the URL of the public key which signs Google
+	// Chrome's log list, in PEM format.
+	LogListPublicKeyURL = "https://www.gstatic.com/ct/log_list/v3/log_list_pubkey.pem"
+
+	// DefaultRefreshInterval is the default interval between two fetches of
+	// the log list by a LogListProvider.
+	DefaultRefreshInterval = time.Hour
+	// defaultFetchTimeout bounds each request made by a LogListProvider
+	// using its default client.
+	defaultFetchTimeout = 30 * time.Second
+	// maxLogListSize limits the size of the log lists and signatures fetched.
+	maxLogListSize = 16 << 20
+)
+
+// ProviderOptions configures a LogListProvider.
+type ProviderOptions struct {
+	// ListURL is the address of the log list; if empty, LogListURL is used.
+	ListURL string
+	// SignatureURL is the address of the signature over the log list. If
+	// both SignatureURL and ListURL are empty, LogListSignatureURL is used.
+	// If SignatureURL is empty for another list, the list is not verified.
+	SignatureURL string
+	// PublicKey verifies the signature over the log list. It is required
+	// when the list is verified.
+	PublicKey crypto.PublicKey
+	// Client makes the requests; if nil, a client with a 30s timeout is
+	// used.
+	Client *http.Client
+	// RefreshInterval is the interval between two fetches of the list by
+	// Run; if zero, DefaultRefreshInterval is used.
+	RefreshInterval time.Duration
+}
+
+// LogListProvider keeps an up-to-date copy of a log list, fetching it and
+// checking its signature at regular intervals, and notifies subscribers when
+// it changes. It is safe for concurrent use.
+type LogListProvider struct {
+	listURL  string
+	sigURL   string
+	pubKey   crypto.PublicKey
+	client   *http.Client
+	interval time.Duration
+
+	mu         sync.RWMutex
+	json       []byte
+	list       *LogList
+	lastUpdate time.Time
+	subs       []chan *LogList
+}
+
+// NewLogListProvider creates a LogListProvider; the log list is not fetched
+// until Refresh or Run is called.
+func NewLogListProvider(opts ProviderOptions) (*LogListProvider, error) {
+	p := &LogListProvider{
+		listURL:  opts.ListURL,
+		sigURL:   opts.SignatureURL,
+		pubKey:   opts.PublicKey,
+		client:   opts.Client,
+		interval: opts.RefreshInterval,
+	}
+	if p.listURL == "" {
+		p.listURL = LogListURL
+		if p.sigURL == "" {
+			p.sigURL = LogListSignatureURL
+		}
+	}
+	if p.sigURL != "" && p.pubKey == nil {
+		return nil, fmt.Errorf("no public key to verify %q", p.sigURL)
+	}
+	if p.client == nil {
+		p.client = &http.Client{Timeout: defaultFetchTimeout}
+	}
+	if p.interval <= 0 {
+		p.interval = DefaultRefreshInterval
+	}
+	return p, nil
+}
+
+// LogList returns the latest log list, or nil if none was fetched yet. The
+// returned list must not be modified.
+func (p *LogListProvider) LogList() *LogList {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.list
+}
+
+// LastJSON returns the JSON encoding of the latest log list, as fetched.
+func (p *LogListProvider) LastJSON() []byte {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.json
+}
+
+// LastUpdate returns the time at which the log list last changed.
+func (p *LogListProvider) LastUpdate() time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.lastUpdate
+}
+
+// Subscribe returns a channel which receives the log list each time it
+// changes, starting with the current one if any. Only the latest list is
+// kept for slow readers. The channel is never closed.
+func (p *LogListProvider) Subscribe() <-chan *LogList {
+	ch := make(chan *LogList, 1)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.list != nil {
+		ch <- p.list
+	}
+	p.subs = append(p.subs, ch)
+	return ch
+}
+
+// Run refreshes the log list at regular intervals, starting immediately,
+// until ctx is done. Errors are logged, and the previous list is kept.
+func (p *LogListProvider) Run(ctx context.Context) {
+	schedule.Every(ctx, p.interval, func(ctx context.Context) {
+		if _, err := p.Refresh(ctx); err != nil {
+			klog.Warningf("Failed to refresh log list: %v", err)
+		}
+	})
+}
+
+// Refresh fetches and verifies the log list once, and reports whether it
+// changed. A list older than the current one is rejected, so that an
+// attacker cannot roll back to a list it has a signature for.
+func (p *LogListProvider) Refresh(ctx context.Context) (bool, error) {
+	data, err := p.fetch(ctx, p.listURL)
+	if err != nil {
+		return false, err
+	}
+	var ll *LogList
+	if p.sigURL != "" {
+		sig, err := p.fetch(ctx, p.sigURL)
+		if err != nil {
+			return false, err
+		}
+		ll, err = NewFromSignedJSON(data, sig, p.pubKey)
+		if err != nil {
+			return false, fmt.Errorf("log list from %q: %v", p.listURL, err)
+		}
+	} else {
+		ll, err = NewFromJSON(data)
+		if err != nil {
+			return false, fmt.Errorf("log list from %q: %v", p.listURL, err)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if bytes.Equal(data, p.json) {
+		return false, nil
+	}
+	if p.list != nil && ll.LogListTimestamp.Before(p.list.LogListTimestamp) {
+		return false, fmt.Errorf("log list from %q published at %v, before current list published at %v", p.listURL, ll.LogListTimestamp, p.list.LogListTimestamp)
+	}
+	p.json, p.list, p.lastUpdate = data, ll, time.Now()
+	for _, ch := range p.subs {
+		// Replace any list not yet received by a slow subscriber.
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- ll
+	}
+	return true, nil
+}
+
+func (p *LogListProvider) fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request for %q: %v", url, err)
+	}
+	rsp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %q: %v", url, err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get %q: HTTP status %q", url, rsp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(rsp.Body, maxLogListSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %v", url, err)
+	}
+	if len(data) > maxLogListSize {
+		return nil, fmt.Errorf("response from %q is larger than %d bytes", url, maxLogListSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loglist3
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+)
+
+func TestLogListProvider(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var list, sig []byte
+	publish := func(json string, badSig bool) {
+		t.Helper()
+		signed, err := tls.CreateSignature(*key, tls.SHA256, []byte(json))
+		if err != nil {
+			t.Fatal(err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		list, sig = []byte(json), signed.Signature
+		if badSig {
+			list = []byte(strings.Replace(json, "64.1", "64.9", 1))
+		}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/log_list.json":
+			_, _ = w.Write(list)
+		case "/log_list.sig":
+			_, _ = w.Write(sig)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	if _, err := NewLogListProvider(ProviderOptions{}); err == nil {
+		t.Error("NewLogListProvider() without key=_,nil; want error")
+	}
+	p, err := NewLogListProvider(ProviderOptions{
+		ListURL:      srv.URL + "/log_list.json",
+		SignatureURL: srv.URL + "/log_list.sig",
+		PublicKey:    key.Public(),
+	})
+	if err != nil {
+		t.Fatalf("NewLogListProvider()=nil,%v; want _,nil", err)
+	}
+	updates := p.Subscribe()
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		desc        string
+		json        string
+		badSig      bool
+		wantChanged bool
+		wantErr     string
+		wantVersion string
+	}{
+		{desc: "first", json: `{"version":"64.1","log_list_timestamp":"2025-06-02T12:00:00Z","operators":[]}`, wantChanged: true, wantVersion: "64.1"},
+		{desc: "unchanged", json: `{"version":"64.1","log_list_timestamp":"2025-06-02T12:00:00Z","operators":[]}`, wantVersion: "64.1"},
+		{desc: "bad-signature", json: `{"version":"64.1","log_list_timestamp":"2025-06-03T12:00:00Z","operators":[]}`, badSig: true, wantErr: "failed to verify signature", wantVersion: "64.1"},
+		{desc: "newer", json: `{"version":"64.2","log_list_timestamp":"2025-06-03T12:00:00Z","operators":[]}`, wantChanged: true, wantVersion: "64.2"},
+		{desc: "rollback", json: `{"version":"64.1","log_list_timestamp":"2025-06-02T12:00:00Z","operators":[]}`, wantErr: "before current list", wantVersion: "64.2"},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			publish(test.json, test.badSig)
+			changed, err := p.Refresh(ctx)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("Refresh()=_,%v; want error containing %q", err, test.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("Refresh()=_,%v; want _,nil", err)
+			}
+			if changed != test.wantChanged {
+				t.Errorf("Refresh()=%t,_; want %t", changed, test.wantChanged)
+			}
+			if got := p.LogList().Version; got != test.wantVersion {
+				t.Errorf("LogList().Version=%q; want %q", got, test.wantVersion)
+			}
+			select {
+			case ll := <-updates:
+				if !test.wantChanged {
+					t.Errorf("subscriber notified of %q; want no notification", ll.Version)
+				} else if ll.Version != test.wantVersion {
+					t.Errorf("subscriber notified of %q; want %q", ll.Version, test.wantVersion)
+				}
+			default:
+				if test.wantChanged {
+					t.Error("subscriber not notified; want notification")
+				}
+			}
+		})
+	}
+
+	if got := <-p.Subscribe(); got.Version != "64.2" {
+		t.Errorf("new subscriber got version %q; want current version 64.2", got.Version)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The ctmirror binary keeps a local copy of a CT log in an SQLite database:
+// it downloads the entries of the log, verifies that they form the tree of
+// the log's STH, keeps up with the log incrementally, and can serve the
+// read-only endpoints of the log from the copy.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/mirror"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+	"k8s.io/klog/v2"
+
+	_ "github.com/mattn/go-sqlite3" // Load SQLite driver
+)
+
+var (
+	logURI        = flag.String("log_uri", "", "CT log base URI")
+	logList       = flag.String("log_list", loglist3.AllLogListURL, "File or URL of the JSON list of logs, in which the public key of --log_uri is looked up; if empty, STH signatures are not checked")
+	dbPath        = flag.String("db", "ctmirror.db", "SQLite database holding the copy of the log")
+	batchSize     = flag.Int("batch_size", mirror.DefaultBatchSize, "Number of entries fetched at once")
+	interval      = flag.Duration("interval", time.Minute, "Interval between syncs of the copy")
+	once          = flag.Bool("once", false, "Sync the copy once and exit, instead of keeping it in sync")
+	httpEndpoint  = flag.String("http_endpoint", "", "If set, endpoint (host:port) on which to serve the read-only log endpoints from the copy")
+	maxGetEntries = flag.Int64("max_get_entries", mirror.DefaultMaxGetEntries, "Maximum number of entries served by a get-entries request")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if len(*logURI) == 0 {
+		klog.Exit("--log_uri must be set")
+	}
+
+	var opts jsonclient.Options
+	if len(*logList) > 0 {
+		data, err := x509util.ReadFileOrURL(*logList, http.DefaultClient)
+		if err != nil {
+			klog.Exitf("Failed to read log list: %v", err)
+		}
+		ll, err := loglist3.NewFromJSON(data)
+		if err != nil {
+			klog.Exitf("Failed to parse log list: %v", err)
+		}
+		l := ll.FindLogByURL(*logURI)
+		if l == nil {
+			klog.Exitf("Log %q is not in the log list", *logURI)
+		}
+		opts.PublicKeyDER = l.Key
+	}
+	lc, err := client.New(*logURI, http.DefaultClient, opts)
+	if err != nil {
+		klog.Exitf("Failed to create log client: %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", *dbPath)
+	if err != nil {
+		klog.Exitf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	store, err := mirror.NewSQLiteStorage(ctx, db)
+	if err != nil {
+		klog.Exitf("Failed to set up database: %v", err)
+	}
+	m := mirror.New(lc, store, mirror.Options{BatchSize: *batchSize})
+
+	if *once {
+		sth, err := m.Sync(ctx)
+		if err != nil {
+			klog.Exitf("Failed to sync mirror: %v", err)
+		}
+		klog.Infof("Mirror at tree size %d", sth.TreeSize)
+		return
+	}
+	if len(*httpEndpoint) > 0 {
+		go func() {
+			klog.Infof("Serving log endpoints on %s", *httpEndpoint)
+			klog.Exit(http.ListenAndServe(*httpEndpoint, &mirror.Handler{Store: store, MaxGetEntries: *maxGetEntries}))
+		}()
+	}
+	klog.Infof("Mirroring %s into %s", *logURI, *dbPath)
+	m.Run(ctx, *interval)
+}
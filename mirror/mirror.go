@@ -0,0 +1,225 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mirror keeps a local copy of a CT log: it downloads the entries of
+// the log into a Storage, verifies that they form the tree of the log's STH,
+// and keeps up with the log incrementally. The copy can then be served
+// through the read-only endpoints of RFC 6962 by a Handler.
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/transparency-dev/merkle/compact"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"k8s.io/klog/v2"
+)
+
+// DefaultBatchSize is the default number of entries fetched at once.
+const DefaultBatchSize = 1000
+
+// LogClient is the client of the mirrored log.
+type LogClient interface {
+	GetSTH(ctx context.Context) (*ct.SignedTreeHead, error)
+	GetRawEntries(ctx context.Context, start, end int64) (*ct.GetEntriesResponse, error)
+}
+
+// RootsClient is implemented by LogClients which can fetch the roots
+// accepted by the log, which are then mirrored as well.
+type RootsClient interface {
+	GetAcceptedRoots(ctx context.Context) ([]ct.ASN1Cert, error)
+}
+
+// Options configures a Mirror.
+type Options struct {
+	// BatchSize is the number of entries fetched at once; if zero,
+	// DefaultBatchSize is used.
+	BatchSize int
+	// Verifier, if set, checks the signatures of STHs.
+	Verifier *ct.SignatureVerifier
+}
+
+// Mirror copies a log into a Storage.
+type Mirror struct {
+	client LogClient
+	store  Storage
+	opts   Options
+	rf     *compact.RangeFactory
+}
+
+// New creates a Mirror copying the log of client into store.
+func New(client LogClient, store Storage, opts Options) *Mirror {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	return &Mirror{client: client, store: store, opts: opts, rf: &compact.RangeFactory{Hash: rfc6962.DefaultHasher.HashChildren}}
+}
+
+// Sync brings the copy up to the current STH of the log, and returns the STH
+// of the copy. The entries fetched are only served once they are verified to
+// form the tree of the STH; if they do not, they are dropped and an error is
+// returned.
+func (m *Mirror) Sync(ctx context.Context) (*ct.SignedTreeHead, error) {
+	if rc, ok := m.client.(RootsClient); ok {
+		roots, err := rc.GetAcceptedRoots(ctx)
+		if err != nil {
+			klog.Warningf("Failed to get roots: %v", err)
+		} else if err := m.store.SetRoots(ctx, roots); err != nil {
+			return nil, err
+		}
+	}
+
+	sth, err := m.client.GetSTH(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get STH: %v", err)
+	}
+	if m.opts.Verifier != nil {
+		if err := m.opts.Verifier.VerifySTHSignature(*sth); err != nil {
+			return nil, fmt.Errorf("invalid STH signature: %v", err)
+		}
+	}
+	verified, verifiedRange, err := m.store.STH(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if verified != nil {
+		switch {
+		case sth.TreeSize < verified.TreeSize:
+			// The log may serve an older STH from a lagging replica.
+			klog.Warningf("Log STH of size %d is smaller than mirrored size %d", sth.TreeSize, verified.TreeSize)
+			return verified, nil
+		case sth.TreeSize == verified.TreeSize:
+			if sth.SHA256RootHash != verified.SHA256RootHash {
+				return nil, fmt.Errorf("log STH of size %d has root %x, but mirrored tree has root %x", sth.TreeSize, sth.SHA256RootHash[:], verified.SHA256RootHash[:])
+			}
+			if sth.Timestamp > verified.Timestamp {
+				return sth, m.store.SetSTH(ctx, sth, verifiedRange)
+			}
+			return verified, nil
+		}
+	}
+
+	size, hashes, err := m.store.Size(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if size > sth.TreeSize {
+		// Unverified entries from an earlier sync beyond the STH.
+		if err := m.dropUnverified(ctx, verified, verifiedRange); err != nil {
+			return nil, err
+		}
+		if size, hashes, err = m.store.Size(ctx); err != nil {
+			return nil, err
+		}
+	}
+	rng, err := m.rf.NewRange(0, size, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("bad compact range of %d entries: %v", size, err)
+	}
+	for rng.End() < sth.TreeSize {
+		end := rng.End() + uint64(m.opts.BatchSize)
+		if end > sth.TreeSize {
+			end = sth.TreeSize
+		}
+		if err := m.fetch(ctx, rng, end); err != nil {
+			return nil, err
+		}
+	}
+
+	root, err := rng.GetRootHash(nil)
+	if err != nil {
+		return nil, err
+	}
+	if sth.TreeSize == 0 {
+		root = rfc6962.DefaultHasher.EmptyRoot()
+	}
+	if !bytes.Equal(root, sth.SHA256RootHash[:]) {
+		dropErr := m.dropUnverified(ctx, verified, verifiedRange)
+		if dropErr != nil {
+			klog.Errorf("Failed to drop unverified entries: %v", dropErr)
+		}
+		return nil, fmt.Errorf("entries up to %d have root %x, but the log STH has root %x", sth.TreeSize, root, sth.SHA256RootHash[:])
+	}
+	if err := m.store.SetSTH(ctx, sth, rng.Hashes()); err != nil {
+		return nil, err
+	}
+	return sth, nil
+}
+
+// fetch fetches the entries from rng.End() up to end, appends them to rng
+// and stores them.
+func (m *Mirror) fetch(ctx context.Context, rng *compact.Range, end uint64) error {
+	for start := rng.End(); start < end; start = rng.End() {
+		rsp, err := m.client.GetRawEntries(ctx, int64(start), int64(end-1))
+		if err != nil {
+			return fmt.Errorf("failed to get entries [%d, %d): %v", start, end, err)
+		}
+		if len(rsp.Entries) == 0 {
+			return fmt.Errorf("no entries returned from %d", start)
+		}
+		if n := uint64(len(rsp.Entries)); start+n > end {
+			rsp.Entries = rsp.Entries[:end-start]
+		}
+		b := &Batch{Start: start, Entries: rsp.Entries, Nodes: make(map[compact.NodeID][]byte)}
+		visit := func(id compact.NodeID, hash []byte) {
+			if id.Level > 0 {
+				b.Nodes[id] = hash
+			}
+		}
+		for _, e := range rsp.Entries {
+			hash := rfc6962.DefaultHasher.HashLeaf(e.LeafInput)
+			b.LeafHashes = append(b.LeafHashes, hash)
+			if err := rng.Append(hash, visit); err != nil {
+				return err
+			}
+		}
+		b.Range = rng.Hashes()
+		if err := m.store.Append(ctx, b); err != nil {
+			return err
+		}
+		klog.V(1).Infof("Mirrored entries [%d, %d)", start, rng.End())
+	}
+	return nil
+}
+
+// dropUnverified drops the entries beyond the verified STH.
+func (m *Mirror) dropUnverified(ctx context.Context, verified *ct.SignedTreeHead, rng [][]byte) error {
+	var size uint64
+	if verified != nil {
+		size = verified.TreeSize
+	}
+	return m.store.Truncate(ctx, size, rng)
+}
+
+// Run syncs the copy every interval, until ctx is done.
+func (m *Mirror) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if sth, err := m.Sync(ctx); err != nil {
+			klog.Errorf("Failed to sync mirror: %v", err)
+		} else {
+			klog.Infof("Mirror at tree size %d", sth.TreeSize)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
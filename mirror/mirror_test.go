@@ -0,0 +1,288 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/internal/testonly"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+
+	_ "github.com/mattn/go-sqlite3" // Load SQLite driver
+)
+
+// newTreeLog returns a fake log returning at most 4 entries per
+// get-entries request, as logs may return fewer entries than requested.
+func newTreeLog(t *testing.T) *testonly.TreeLog {
+	t.Helper()
+	l := testonly.NewTreeLog(t)
+	l.MaxEntries = 4
+	return l
+}
+
+func newTestStorage(t *testing.T) *SQLiteStorage {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "mirror.db"))
+	if err != nil {
+		t.Fatalf("sql.Open()=%v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	s, err := NewSQLiteStorage(context.Background(), db)
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage()=%v", err)
+	}
+	return s
+}
+
+func checkSize(t *testing.T, s Storage, want uint64) {
+	t.Helper()
+	ctx := context.Background()
+	if size, _, err := s.Size(ctx); err != nil || size != want {
+		t.Errorf("Size()=%d, %v; want %d", size, err, want)
+	}
+	sth, _, err := s.STH(ctx)
+	if err != nil {
+		t.Fatalf("STH()=%v", err)
+	}
+	if got := uint64(0); sth != nil {
+		got = sth.TreeSize
+		if got != want {
+			t.Errorf("STH() tree size %d; want %d", got, want)
+		}
+	} else if want != 0 {
+		t.Errorf("STH()=nil; want tree size %d", want)
+	}
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	c := newTreeLog(t)
+	s := newTestStorage(t)
+	m := New(c, s, Options{BatchSize: 5, Verifier: c.Verifier()})
+
+	if _, err := m.Sync(ctx); err != nil {
+		t.Fatalf("Sync(empty)=%v", err)
+	}
+	checkSize(t, s, 0)
+
+	for _, grow := range []int{13, 0, 1, 18} {
+		c.Grow(grow)
+		sth, err := m.Sync(ctx)
+		if err != nil {
+			t.Fatalf("Sync()=%v", err)
+		}
+		if sth.TreeSize != c.Tree.Size() {
+			t.Errorf("Sync() tree size %d; want %d", sth.TreeSize, c.Tree.Size())
+		}
+		checkSize(t, s, c.Tree.Size())
+	}
+	entries, err := s.Entries(ctx, 0, c.Tree.Size())
+	if err != nil {
+		t.Fatalf("Entries()=%v", err)
+	}
+	for i, e := range entries {
+		if !bytes.Equal(e.LeafInput, c.Entries[i].LeafInput) || !bytes.Equal(e.ExtraData, c.Entries[i].ExtraData) {
+			t.Errorf("Entries()[%d]=%+v; want %+v", i, e, c.Entries[i])
+		}
+	}
+	if got, want := len(entries), len(c.Entries); got != want {
+		t.Errorf("Entries() returned %d entries; want %d", got, want)
+	}
+
+	// Entries are not fetched again.
+	c.Gets = 0
+	if _, err := m.Sync(ctx); err != nil {
+		t.Fatalf("Sync()=%v", err)
+	}
+	if c.Gets != 0 {
+		t.Errorf("Sync() without new entries fetched entries %d times", c.Gets)
+	}
+
+	// The copy survives reopening.
+	c.Grow(3)
+	if _, err := New(c, s, Options{}).Sync(ctx); err != nil {
+		t.Fatalf("Sync(reopened)=%v", err)
+	}
+	checkSize(t, s, c.Tree.Size())
+}
+
+func TestSyncErrors(t *testing.T) {
+	ctx := context.Background()
+	c := newTreeLog(t)
+	s := newTestStorage(t)
+	m := New(c, s, Options{BatchSize: 3, Verifier: c.Verifier()})
+	c.Grow(7)
+	if _, err := m.Sync(ctx); err != nil {
+		t.Fatalf("Sync()=%v", err)
+	}
+
+	c.Grow(5)
+	for _, test := range []struct {
+		name string
+		sth  *ct.SignedTreeHead
+	}{
+		{name: "wrong-root", sth: c.Sign(c.Tree.Size(), c.Tree.HashAt(11), time.Now())},
+		{name: "same-size-wrong-root", sth: c.Sign(7, c.Tree.HashAt(6), time.Now())},
+		{name: "bad-signature", sth: func() *ct.SignedTreeHead {
+			sth := c.Sign(c.Tree.Size(), c.Tree.Hash(), time.Now())
+			sth.Timestamp++
+			return sth
+		}()},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c.Next = test.sth
+			if sth, err := m.Sync(ctx); err == nil {
+				t.Fatalf("Sync()=%+v; want error", sth)
+			}
+			// Unverified entries are dropped.
+			checkSize(t, s, 7)
+		})
+	}
+
+	// A smaller STH from a lagging replica leaves the copy alone.
+	c.Next = c.Sign(5, c.Tree.HashAt(5), time.Now())
+	if sth, err := m.Sync(ctx); err != nil || sth.TreeSize != 7 {
+		t.Errorf("Sync(smaller)=%+v, %v; want tree size 7", sth, err)
+	}
+
+	if _, err := m.Sync(ctx); err != nil {
+		t.Fatalf("Sync()=%v", err)
+	}
+	checkSize(t, s, 12)
+}
+
+func TestHandler(t *testing.T) {
+	ctx := context.Background()
+	c := newTreeLog(t)
+	s := newTestStorage(t)
+	srv := httptest.NewServer(&Handler{Store: s, MaxGetEntries: 4})
+	defer srv.Close()
+
+	der, err := x509.MarshalPKIXPublicKey(c.Key.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey()=%v", err)
+	}
+	lc, err := client.New(srv.URL, srv.Client(), jsonclient.Options{PublicKeyDER: der})
+	if err != nil {
+		t.Fatalf("client.New()=%v", err)
+	}
+	if _, err := lc.GetSTH(ctx); err == nil {
+		t.Error("GetSTH() before Sync()=nil; want error")
+	}
+
+	c.Grow(21)
+	if _, err := New(c, s, Options{BatchSize: 8}).Sync(ctx); err != nil {
+		t.Fatalf("Sync()=%v", err)
+	}
+	// Entries beyond the verified STH are not served.
+	c.Grow(2)
+	c.Next = c.Sign(c.Tree.Size(), c.Tree.HashAt(1), time.Now())
+	if _, err := New(c, s, Options{}).Sync(ctx); err == nil {
+		t.Fatal("Sync(wrong root)=nil; want error")
+	}
+
+	sth, err := lc.GetSTH(ctx)
+	if err != nil {
+		t.Fatalf("GetSTH()=%v", err)
+	}
+	const size = 21
+	if sth.TreeSize != size || !bytes.Equal(sth.SHA256RootHash[:], c.Tree.HashAt(size)) {
+		t.Fatalf("GetSTH()=%+v; want tree size %d", sth, size)
+	}
+
+	for first := uint64(1); first <= size; first++ {
+		for _, second := range []uint64{first, size} {
+			p, err := lc.GetSTHConsistency(ctx, first, second)
+			if err != nil {
+				t.Fatalf("GetSTHConsistency(%d, %d)=%v", first, second, err)
+			}
+			if err := proof.VerifyConsistency(rfc6962.DefaultHasher, first, second, p, c.Tree.HashAt(first), c.Tree.HashAt(second)); err != nil {
+				t.Errorf("GetSTHConsistency(%d, %d) proof: %v", first, second, err)
+			}
+		}
+	}
+
+	for i := uint64(0); i < size; i++ {
+		hash := c.Tree.LeafHash(i)
+		rsp, err := lc.GetProofByHash(ctx, hash, size)
+		if err != nil {
+			t.Fatalf("GetProofByHash(%d)=%v", i, err)
+		}
+		if uint64(rsp.LeafIndex) != i {
+			t.Errorf("GetProofByHash(%d) index %d", i, rsp.LeafIndex)
+		}
+		if err := proof.VerifyInclusion(rfc6962.DefaultHasher, i, size, hash, rsp.AuditPath, c.Tree.HashAt(size)); err != nil {
+			t.Errorf("GetProofByHash(%d) proof: %v", i, err)
+		}
+		erp, err := lc.GetEntryAndProof(ctx, i, i+1)
+		if err != nil {
+			t.Fatalf("GetEntryAndProof(%d)=%v", i, err)
+		}
+		if !bytes.Equal(erp.LeafInput, c.Entries[i].LeafInput) || !bytes.Equal(erp.ExtraData, c.Entries[i].ExtraData) {
+			t.Errorf("GetEntryAndProof(%d)=%+v; want entry %+v", i, erp, c.Entries[i])
+		}
+		if err := proof.VerifyInclusion(rfc6962.DefaultHasher, i, i+1, hash, erp.AuditPath, c.Tree.HashAt(i+1)); err != nil {
+			t.Errorf("GetEntryAndProof(%d) proof: %v", i, err)
+		}
+	}
+	if _, err := lc.GetProofByHash(ctx, c.Tree.LeafHash(size), size+2); err == nil {
+		t.Error("GetProofByHash(unverified)=nil; want error")
+	}
+
+	entries, err := lc.GetRawEntries(ctx, 18, 30)
+	if err != nil {
+		t.Fatalf("GetRawEntries()=%v", err)
+	}
+	if len(entries.Entries) != 3 || !bytes.Equal(entries.Entries[0].LeafInput, c.Entries[18].LeafInput) {
+		t.Errorf("GetRawEntries(18, 30) returned %d entries; want entries 18 to 20", len(entries.Entries))
+	}
+	if entries, err := lc.GetRawEntries(ctx, 0, 10); err != nil || len(entries.Entries) != 4 {
+		t.Errorf("GetRawEntries(0, 10)=%v; want 4 entries", err)
+	}
+
+	roots, err := lc.GetAcceptedRoots(ctx)
+	if err != nil || len(roots) != 1 || string(roots[0].Data) != "root" {
+		t.Errorf("GetAcceptedRoots()=%v, %v; want the log roots", roots, err)
+	}
+
+	for _, path := range []string{
+		ct.GetSTHConsistencyPath + "?first=1&second=22",
+		ct.GetEntryAndProofPath + "?leaf_index=21&tree_size=21",
+		ct.GetEntriesPath + "?start=21&end=22",
+		ct.GetProofByHashPath + "?hash=AAAA&tree_size=21",
+		ct.AddChainPath,
+	} {
+		rsp, err := srv.Client().Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s=%v", path, err)
+		}
+		rsp.Body.Close()
+		if rsp.StatusCode != http.StatusBadRequest && rsp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET %s status=%d; want 400 or 404", path, rsp.StatusCode)
+		}
+	}
+}
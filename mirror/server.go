@@ -0,0 +1,275 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"k8s.io/klog/v2"
+)
+
+// DefaultMaxGetEntries is the default maximum number of entries served by a
+// get-entries request.
+const DefaultMaxGetEntries = 1000
+
+// Handler serves the read-only endpoints of RFC 6962 from the verified tree
+// of a Storage. Entries beyond the STH of the copy are not served.
+type Handler struct {
+	Store Storage
+	// MaxGetEntries is the maximum number of entries served by get-entries;
+	// if zero, DefaultMaxGetEntries is used.
+	MaxGetEntries int64
+}
+
+// httpError is an error with an HTTP status.
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string { return e.err.Error() }
+
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{status: http.StatusBadRequest, err: fmt.Errorf(format, args...)}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	handlers := map[string]func(context.Context, *http.Request, *ct.SignedTreeHead) (interface{}, error){
+		ct.GetSTHPath:            h.getSTH,
+		ct.GetSTHConsistencyPath: h.getSTHConsistency,
+		ct.GetProofByHashPath:    h.getProofByHash,
+		ct.GetEntriesPath:        h.getEntries,
+		ct.GetRootsPath:          h.getRoots,
+		ct.GetEntryAndProofPath:  h.getEntryAndProof,
+	}
+	handle, ok := handlers[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	ctx := r.Context()
+	sth, _, err := h.Store.STH(ctx)
+	if err == nil && sth == nil {
+		err = &httpError{status: http.StatusServiceUnavailable, err: errors.New("mirror has no verified STH yet")}
+	}
+	var rsp interface{}
+	if err == nil {
+		rsp, err = handle(ctx, r, sth)
+	}
+	if err != nil {
+		status := http.StatusInternalServerError
+		var he *httpError
+		if errors.As(err, &he) {
+			status = he.status
+		} else if errors.Is(err, ErrNotFound) {
+			status = http.StatusNotFound
+		}
+		if status == http.StatusInternalServerError {
+			klog.Errorf("%s: %v", r.URL.Path, err)
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(rsp); err != nil {
+		klog.Warningf("Failed to write %s response: %v", r.URL.Path, err)
+	}
+}
+
+// uintParam parses the unsigned integer parameter name of r.
+func uintParam(r *http.Request, name string) (uint64, error) {
+	v, err := strconv.ParseUint(r.FormValue(name), 10, 64)
+	if err != nil {
+		return 0, badRequest("bad %s parameter: %v", name, err)
+	}
+	return v, nil
+}
+
+// treeSizeParam parses a tree_size parameter, which must be within the STH.
+func treeSizeParam(r *http.Request, name string, sth *ct.SignedTreeHead) (uint64, error) {
+	size, err := uintParam(r, name)
+	if err != nil {
+		return 0, err
+	}
+	if size > sth.TreeSize {
+		return 0, badRequest("%s %d is beyond tree size %d", name, size, sth.TreeSize)
+	}
+	return size, nil
+}
+
+// hashes returns the hashes of nodes.
+func (h *Handler) hashes(ctx context.Context, nodes proof.Nodes) ([][]byte, error) {
+	hashes, err := h.Store.NodeHashes(ctx, nodes.IDs)
+	if err != nil {
+		return nil, err
+	}
+	return nodes.Rehash(hashes, rfc6962.DefaultHasher.HashChildren)
+}
+
+func (h *Handler) inclusion(ctx context.Context, index, size uint64) ([][]byte, error) {
+	nodes, err := proof.Inclusion(index, size)
+	if err != nil {
+		return nil, badRequest("%v", err)
+	}
+	return h.hashes(ctx, nodes)
+}
+
+func (h *Handler) getSTH(_ context.Context, _ *http.Request, sth *ct.SignedTreeHead) (interface{}, error) {
+	sig, err := tls.Marshal(sth.TreeHeadSignature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal STH signature: %v", err)
+	}
+	return &ct.GetSTHResponse{
+		TreeSize:          sth.TreeSize,
+		Timestamp:         sth.Timestamp,
+		SHA256RootHash:    sth.SHA256RootHash[:],
+		TreeHeadSignature: sig,
+	}, nil
+}
+
+func (h *Handler) getSTHConsistency(ctx context.Context, r *http.Request, sth *ct.SignedTreeHead) (interface{}, error) {
+	first, err := uintParam(r, "first")
+	if err != nil {
+		return nil, err
+	}
+	second, err := treeSizeParam(r, "second", sth)
+	if err != nil {
+		return nil, err
+	}
+	if first > second {
+		return nil, badRequest("first %d is beyond second %d", first, second)
+	}
+	var rsp ct.GetSTHConsistencyResponse
+	if first == 0 || first == second {
+		rsp.Consistency = [][]byte{}
+		return &rsp, nil
+	}
+	nodes, err := proof.Consistency(first, second)
+	if err != nil {
+		return nil, badRequest("%v", err)
+	}
+	if rsp.Consistency, err = h.hashes(ctx, nodes); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+func (h *Handler) getProofByHash(ctx context.Context, r *http.Request, sth *ct.SignedTreeHead) (interface{}, error) {
+	hash, err := base64.StdEncoding.DecodeString(r.FormValue("hash"))
+	if err != nil || len(hash) != rfc6962.DefaultHasher.Size() {
+		return nil, badRequest("bad hash parameter %q", r.FormValue("hash"))
+	}
+	size, err := treeSizeParam(r, "tree_size", sth)
+	if err != nil {
+		return nil, err
+	}
+	index, err := h.Store.LeafIndex(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if index >= size {
+		return nil, &httpError{status: http.StatusNotFound, err: fmt.Errorf("hash not in tree of size %d", size)}
+	}
+	path, err := h.inclusion(ctx, index, size)
+	if err != nil {
+		return nil, err
+	}
+	return &ct.GetProofByHashResponse{LeafIndex: int64(index), AuditPath: path}, nil
+}
+
+func (h *Handler) getEntries(ctx context.Context, r *http.Request, sth *ct.SignedTreeHead) (interface{}, error) {
+	start, err := uintParam(r, "start")
+	if err != nil {
+		return nil, err
+	}
+	end, err := uintParam(r, "end")
+	if err != nil {
+		return nil, err
+	}
+	if start > end {
+		return nil, badRequest("start %d is beyond end %d", start, end)
+	}
+	if start >= sth.TreeSize {
+		return nil, badRequest("start %d is beyond tree size %d", start, sth.TreeSize)
+	}
+	max := h.MaxGetEntries
+	if max <= 0 {
+		max = DefaultMaxGetEntries
+	}
+	if end >= start+uint64(max) {
+		end = start + uint64(max) - 1
+	}
+	if end >= sth.TreeSize {
+		end = sth.TreeSize - 1
+	}
+	entries, err := h.Store.Entries(ctx, start, end+1)
+	if err != nil {
+		return nil, err
+	}
+	return &ct.GetEntriesResponse{Entries: entries}, nil
+}
+
+func (h *Handler) getRoots(ctx context.Context, _ *http.Request, _ *ct.SignedTreeHead) (interface{}, error) {
+	roots, err := h.Store.Roots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rsp := ct.GetRootsResponse{Certificates: []string{}}
+	for _, root := range roots {
+		rsp.Certificates = append(rsp.Certificates, base64.StdEncoding.EncodeToString(root.Data))
+	}
+	return &rsp, nil
+}
+
+func (h *Handler) getEntryAndProof(ctx context.Context, r *http.Request, sth *ct.SignedTreeHead) (interface{}, error) {
+	index, err := uintParam(r, "leaf_index")
+	if err != nil {
+		return nil, err
+	}
+	size, err := treeSizeParam(r, "tree_size", sth)
+	if err != nil {
+		return nil, err
+	}
+	if index >= size {
+		return nil, badRequest("leaf_index %d is beyond tree_size %d", index, size)
+	}
+	entries, err := h.Store.Entries(ctx, index, index+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) != 1 {
+		return nil, fmt.Errorf("entry %d missing from storage", index)
+	}
+	path, err := h.inclusion(ctx, index, size)
+	if err != nil {
+		return nil, err
+	}
+	return &ct.GetEntryAndProofResponse{LeafInput: entries[0].LeafInput, ExtraData: entries[0].ExtraData, AuditPath: path}, nil
+}
@@ -0,0 +1,290 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/transparency-dev/merkle/compact"
+)
+
+// ErrNotFound is returned by Storage.LeafIndex for unknown leaf hashes.
+var ErrNotFound = errors.New("not found")
+
+// Batch is a batch of consecutive entries appended to a Storage.
+type Batch struct {
+	// Start is the index of the first entry.
+	Start   uint64
+	Entries []ct.LeafEntry
+	// LeafHashes holds the Merkle leaf hash of each entry.
+	LeafHashes [][]byte
+	// Nodes holds the hashes of the internal nodes of the tree completed by
+	// the entries.
+	Nodes map[compact.NodeID][]byte
+	// Range holds the hashes of the compact range of all the entries stored
+	// once the batch is appended.
+	Range [][]byte
+}
+
+// Storage keeps the entries of a mirrored log, along with the hashes of the
+// nodes of its tree and the latest STH whose tree is verified to be formed by
+// the entries. Entries beyond the size of that STH are not verified yet.
+// Implementations must be safe for concurrent use.
+type Storage interface {
+	// Size returns the number of entries stored, and the hashes of their
+	// compact range.
+	Size(ctx context.Context) (uint64, [][]byte, error)
+	// Append stores the entries of b, whose Start must be the current size.
+	Append(ctx context.Context, b *Batch) error
+	// Truncate drops the entries from index size on, along with the nodes
+	// covering them, and resets the compact range to rng.
+	Truncate(ctx context.Context, size uint64, rng [][]byte) error
+	// STH returns the latest verified STH and the hashes of the compact range
+	// of its tree, or nil if there is none.
+	STH(ctx context.Context) (*ct.SignedTreeHead, [][]byte, error)
+	// SetSTH records sth as verified, with rng the hashes of the compact
+	// range of its tree.
+	SetSTH(ctx context.Context, sth *ct.SignedTreeHead, rng [][]byte) error
+	// Entries returns the entries in [start, end).
+	Entries(ctx context.Context, start, end uint64) ([]ct.LeafEntry, error)
+	// LeafIndex returns the index of the first entry with the given leaf
+	// hash, or ErrNotFound.
+	LeafIndex(ctx context.Context, hash []byte) (uint64, error)
+	// NodeHashes returns the hashes of the perfect subtree nodes ids.
+	NodeHashes(ctx context.Context, ids []compact.NodeID) ([][]byte, error)
+	// Roots returns the DER certificates accepted by the log, and SetRoots
+	// records them.
+	Roots(ctx context.Context) ([]ct.ASN1Cert, error)
+	SetRoots(ctx context.Context, roots []ct.ASN1Cert) error
+}
+
+// SQLiteStorage is a Storage which keeps a log in an SQLite database. The
+// caller is responsible for loading the database driver.
+type SQLiteStorage struct {
+	db *sql.DB
+}
+
+// NewSQLiteStorage creates an SQLiteStorage using db, creating its tables if
+// needed.
+func NewSQLiteStorage(ctx context.Context, db *sql.DB) (*SQLiteStorage, error) {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS leaves (idx INTEGER PRIMARY KEY, hash BLOB NOT NULL, leafInput BLOB NOT NULL, extraData BLOB)`,
+		`CREATE INDEX IF NOT EXISTS leavesByHash ON leaves (hash)`,
+		`CREATE TABLE IF NOT EXISTS nodes (level INTEGER, idx INTEGER, hash BLOB NOT NULL, PRIMARY KEY (level, idx))`,
+		`CREATE TABLE IF NOT EXISTS state (id INTEGER PRIMARY KEY CHECK (id = 0), size INTEGER NOT NULL, range BLOB, sth BLOB, sthRange BLOB, roots BLOB)`,
+		`INSERT OR IGNORE INTO state (id, size) VALUES (0, 0)`,
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("failed to create mirror tables: %v", err)
+		}
+	}
+	return &SQLiteStorage{db: db}, nil
+}
+
+func unmarshalHashes(data []byte) ([][]byte, error) {
+	var hashes [][]byte
+	if len(data) == 0 {
+		return hashes, nil
+	}
+	if err := json.Unmarshal(data, &hashes); err != nil {
+		return nil, fmt.Errorf("failed to parse compact range: %v", err)
+	}
+	return hashes, nil
+}
+
+// Size implements Storage.
+func (s *SQLiteStorage) Size(ctx context.Context) (uint64, [][]byte, error) {
+	var size int64
+	var rng []byte
+	if err := s.db.QueryRowContext(ctx, `SELECT size, range FROM state WHERE id = 0`).Scan(&size, &rng); err != nil {
+		return 0, nil, fmt.Errorf("failed to read mirror state: %v", err)
+	}
+	hashes, err := unmarshalHashes(rng)
+	return uint64(size), hashes, err
+}
+
+// Append implements Storage.
+func (s *SQLiteStorage) Append(ctx context.Context, b *Batch) error {
+	rng, err := json.Marshal(b.Range)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint: errcheck
+	var size int64
+	if err := tx.QueryRowContext(ctx, `SELECT size FROM state WHERE id = 0`).Scan(&size); err != nil {
+		return fmt.Errorf("failed to read mirror state: %v", err)
+	}
+	if uint64(size) != b.Start {
+		return fmt.Errorf("batch starts at %d, but %d entries are stored", b.Start, size)
+	}
+	for i, e := range b.Entries {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO leaves (idx, hash, leafInput, extraData) VALUES (?, ?, ?, ?)`, int64(b.Start)+int64(i), b.LeafHashes[i], e.LeafInput, e.ExtraData); err != nil {
+			return fmt.Errorf("failed to store entry %d: %v", int(b.Start)+i, err)
+		}
+	}
+	for id, hash := range b.Nodes {
+		if _, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO nodes (level, idx, hash) VALUES (?, ?, ?)`, id.Level, int64(id.Index), hash); err != nil {
+			return fmt.Errorf("failed to store node: %v", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE state SET size = ?, range = ? WHERE id = 0`, size+int64(len(b.Entries)), rng); err != nil {
+		return fmt.Errorf("failed to update mirror state: %v", err)
+	}
+	return tx.Commit()
+}
+
+// Truncate implements Storage.
+func (s *SQLiteStorage) Truncate(ctx context.Context, size uint64, rng [][]byte) error {
+	rngData, err := json.Marshal(rng)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint: errcheck
+	if _, err := tx.ExecContext(ctx, `DELETE FROM leaves WHERE idx >= ?`, int64(size)); err != nil {
+		return fmt.Errorf("failed to drop entries: %v", err)
+	}
+	// A node at (level, idx) covers the entries up to (idx+1) << level.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM nodes WHERE (idx + 1) << level > ?`, int64(size)); err != nil {
+		return fmt.Errorf("failed to drop nodes: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE state SET size = ?, range = ? WHERE id = 0`, int64(size), rngData); err != nil {
+		return fmt.Errorf("failed to update mirror state: %v", err)
+	}
+	return tx.Commit()
+}
+
+// STH implements Storage.
+func (s *SQLiteStorage) STH(ctx context.Context) (*ct.SignedTreeHead, [][]byte, error) {
+	var sthData, rng []byte
+	if err := s.db.QueryRowContext(ctx, `SELECT sth, sthRange FROM state WHERE id = 0`).Scan(&sthData, &rng); err != nil {
+		return nil, nil, fmt.Errorf("failed to read mirror state: %v", err)
+	}
+	if len(sthData) == 0 {
+		return nil, nil, nil
+	}
+	var sth ct.SignedTreeHead
+	if err := json.Unmarshal(sthData, &sth); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse STH: %v", err)
+	}
+	hashes, err := unmarshalHashes(rng)
+	return &sth, hashes, err
+}
+
+// SetSTH implements Storage.
+func (s *SQLiteStorage) SetSTH(ctx context.Context, sth *ct.SignedTreeHead, rng [][]byte) error {
+	sthData, err := json.Marshal(sth)
+	if err != nil {
+		return err
+	}
+	rngData, err := json.Marshal(rng)
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE state SET sth = ?, sthRange = ? WHERE id = 0`, sthData, rngData); err != nil {
+		return fmt.Errorf("failed to record STH: %v", err)
+	}
+	return nil
+}
+
+// Entries implements Storage.
+func (s *SQLiteStorage) Entries(ctx context.Context, start, end uint64) ([]ct.LeafEntry, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT leafInput, extraData FROM leaves WHERE idx >= ? AND idx < ? ORDER BY idx`, int64(start), int64(end))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read entries: %v", err)
+	}
+	defer rows.Close()
+	var entries []ct.LeafEntry
+	for rows.Next() {
+		var e ct.LeafEntry
+		if err := rows.Scan(&e.LeafInput, &e.ExtraData); err != nil {
+			return nil, fmt.Errorf("failed to read entry: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read entries: %v", err)
+	}
+	return entries, nil
+}
+
+// LeafIndex implements Storage.
+func (s *SQLiteStorage) LeafIndex(ctx context.Context, hash []byte) (uint64, error) {
+	var idx int64
+	err := s.db.QueryRowContext(ctx, `SELECT MIN(idx) FROM leaves WHERE hash = ? HAVING COUNT(*) > 0`, hash).Scan(&idx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to look up leaf hash: %v", err)
+	}
+	return uint64(idx), nil
+}
+
+// NodeHashes implements Storage.
+func (s *SQLiteStorage) NodeHashes(ctx context.Context, ids []compact.NodeID) ([][]byte, error) {
+	hashes := make([][]byte, len(ids))
+	for i, id := range ids {
+		var err error
+		if id.Level == 0 {
+			err = s.db.QueryRowContext(ctx, `SELECT hash FROM leaves WHERE idx = ?`, int64(id.Index)).Scan(&hashes[i])
+		} else {
+			err = s.db.QueryRowContext(ctx, `SELECT hash FROM nodes WHERE level = ? AND idx = ?`, id.Level, int64(id.Index)).Scan(&hashes[i])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read node (%d, %d): %v", id.Level, id.Index, err)
+		}
+	}
+	return hashes, nil
+}
+
+// Roots implements Storage.
+func (s *SQLiteStorage) Roots(ctx context.Context) ([]ct.ASN1Cert, error) {
+	var data []byte
+	if err := s.db.QueryRowContext(ctx, `SELECT roots FROM state WHERE id = 0`).Scan(&data); err != nil {
+		return nil, fmt.Errorf("failed to read roots: %v", err)
+	}
+	var roots []ct.ASN1Cert
+	if len(data) == 0 {
+		return roots, nil
+	}
+	if err := json.Unmarshal(data, &roots); err != nil {
+		return nil, fmt.Errorf("failed to parse roots: %v", err)
+	}
+	return roots, nil
+}
+
+// SetRoots implements Storage.
+func (s *SQLiteStorage) SetRoots(ctx context.Context, roots []ct.ASN1Cert) error {
+	data, err := json.Marshal(roots)
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE state SET roots = ? WHERE id = 0`, data); err != nil {
+		return fmt.Errorf("failed to record roots: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+)
+
+// AlertKind is the kind of violation reported by an Alert.
+type AlertKind string
+
+// Kinds of Alert.
+const (
+	// AlertFetchFailed reports that the STH or a consistency proof could not
+	// be fetched from the log.
+	AlertFetchFailed AlertKind = "FetchFailed"
+	// AlertInvalidSignature reports an STH whose signature does not verify.
+	AlertInvalidSignature AlertKind = "InvalidSignature"
+	// AlertBadTimestamp reports an STH timestamped in the future, or before
+	// an STH of a smaller tree.
+	AlertBadTimestamp AlertKind = "BadTimestamp"
+	// AlertInconsistent reports an STH inconsistent with the history of the
+	// log, i.e. a split view or a rewritten history.
+	AlertInconsistent AlertKind = "Inconsistent"
+	// AlertStaleSTH reports a log whose latest STH is older than its MMD.
+	AlertStaleSTH AlertKind = "StaleSTH"
+)
+
+// Alert reports a violation by a log.
+type Alert struct {
+	Kind           AlertKind `json:"kind"`
+	LogURL         string    `json:"log_url"`
+	LogDescription string    `json:"log_description"`
+	Message        string    `json:"message"`
+	// STH is the STH which showed the violation, if any.
+	STH  *ct.SignedTreeHead `json:"sth,omitempty"`
+	Time time.Time          `json:"time"`
+}
+
+func (a *Alert) String() string {
+	return fmt.Sprintf("%s: %s: %s", a.LogURL, a.Kind, a.Message)
+}
+
+// Alerter delivers alerts.
+type Alerter interface {
+	Alert(ctx context.Context, a *Alert) error
+}
+
+// Alerters is an Alerter delivering alerts through each of its Alerters.
+type Alerters []Alerter
+
+// Alert implements Alerter, returning the errors of all failed Alerters.
+func (as Alerters) Alert(ctx context.Context, a *Alert) error {
+	var errs []error
+	for _, alerter := range as {
+		if err := alerter.Alert(ctx, a); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WebhookAlerter delivers alerts by POSTing them as JSON to a URL.
+type WebhookAlerter struct {
+	URL    string
+	Client *http.Client
+}
+
+// Alert implements Alerter.
+func (w *WebhookAlerter) Alert(ctx context.Context, a *Alert) error {
+	return postJSON(ctx, w.Client, w.URL, a)
+}
+
+// AlertmanagerAlerter delivers alerts to a Prometheus Alertmanager, through
+// its v2 API.
+type AlertmanagerAlerter struct {
+	// URL is the base URL of the Alertmanager.
+	URL    string
+	Client *http.Client
+	// Labels are added to those of each alert.
+	Labels map[string]string
+}
+
+// amAlert is an alert of the Alertmanager v2 API.
+type amAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+}
+
+// Alert implements Alerter. Alerts are named CTLog<kind>, and labelled with
+// the log URL.
+func (am *AlertmanagerAlerter) Alert(ctx context.Context, a *Alert) error {
+	labels := map[string]string{"alertname": "CTLog" + string(a.Kind), "log": a.LogURL}
+	for k, v := range am.Labels {
+		labels[k] = v
+	}
+	alert := amAlert{
+		Labels:      labels,
+		Annotations: map[string]string{"summary": a.Message, "description": a.LogDescription},
+		StartsAt:    a.Time,
+	}
+	return postJSON(ctx, am.Client, strings.TrimRight(am.URL, "/")+"/api/v2/alerts", []amAlert{alert})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send alert to %s: %v", url, err)
+	}
+	defer rsp.Body.Close()
+	_, _ = io.Copy(io.Discard, rsp.Body)
+	if rsp.StatusCode/100 != 2 {
+		return fmt.Errorf("got HTTP status %q sending alert to %s", rsp.Status, url)
+	}
+	return nil
+}
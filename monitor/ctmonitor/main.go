@@ -0,0 +1,129 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The ctmonitor binary continuously verifies the logs of a log list: it
+// fetches their STHs, checks their signatures, their consistency with the
+// STHs seen before and the freshness required by the MMD of each log, and
+// sends alerts on violations. The STHs seen are kept in a local state file.
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/monitor"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+	"github.com/google/trillian/monitoring/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/klog/v2"
+)
+
+var (
+	logList         = flag.String("log_list", loglist3.AllLogListURL, "File or URL of the JSON list of logs")
+	logURLs         = flag.String("logs", "", "Comma-separated list of URLs of the logs to monitor; if empty, all the logs of --log_list are monitored")
+	stateFile       = flag.String("state_file", "ctmonitor_sths.jsonl", "File in which the STHs seen for each log are kept")
+	interval        = flag.Duration("interval", monitor.DefaultInterval, "Interval between STH fetches for each log")
+	maxFuture       = flag.Duration("max_future", monitor.DefaultMaxFuture, "How far in the future STH timestamps may be")
+	repeatInterval  = flag.Duration("repeat_interval", monitor.DefaultRepeatInterval, "Minimal interval between alerts of the same kind for a log")
+	webhookURL      = flag.String("webhook_url", "", "If set, URL to POST alerts to as JSON")
+	alertmanagerURL = flag.String("alertmanager_url", "", "If set, base URL of a Prometheus Alertmanager to send alerts to")
+	metricsEndpoint = flag.String("metrics_endpoint", "", "If set, endpoint (host:port) on which to serve Prometheus metrics")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	data, err := x509util.ReadFileOrURL(*logList, http.DefaultClient)
+	if err != nil {
+		klog.Exitf("Failed to read log list: %v", err)
+	}
+	ll, err := loglist3.NewFromJSON(data)
+	if err != nil {
+		klog.Exitf("Failed to parse log list: %v", err)
+	}
+	var logs []*monitor.Log
+	if len(*logURLs) > 0 {
+		for _, url := range strings.Split(*logURLs, ",") {
+			l := ll.FindLogByURL(url)
+			if l == nil {
+				klog.Exitf("Log %q is not in the log list", url)
+			}
+			logs = append(logs, newLog(l))
+		}
+	} else {
+		for _, op := range ll.Operators {
+			for _, l := range op.Logs {
+				logs = append(logs, newLog(l))
+			}
+		}
+	}
+
+	store, err := ctutil.OpenFileSTHStorage(*stateFile)
+	if err != nil {
+		klog.Exitf("Failed to open state file: %v", err)
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			klog.Errorf("Failed to close state file: %v", err)
+		}
+	}()
+
+	var alerter monitor.Alerters
+	if len(*webhookURL) > 0 {
+		alerter = append(alerter, &monitor.WebhookAlerter{URL: *webhookURL})
+	}
+	if len(*alertmanagerURL) > 0 {
+		alerter = append(alerter, &monitor.AlertmanagerAlerter{URL: *alertmanagerURL})
+	}
+	opts := monitor.Options{
+		Interval:       *interval,
+		MaxFuture:      *maxFuture,
+		RepeatInterval: *repeatInterval,
+		MetricFactory:  prometheus.MetricFactory{},
+	}
+	if len(alerter) > 0 {
+		opts.Alerter = alerter
+	}
+	m := monitor.New(logs, store, opts)
+
+	if len(*metricsEndpoint) > 0 {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		go func() {
+			klog.Infof("Serving metrics on %s", *metricsEndpoint)
+			klog.Exit(http.ListenAndServe(*metricsEndpoint, mux))
+		}()
+	}
+
+	klog.Infof("Monitoring %d logs", len(logs))
+	m.Run(ctx)
+}
+
+func newLog(l *loglist3.Log) *monitor.Log {
+	ml, err := monitor.NewLog(l, http.DefaultClient)
+	if err != nil {
+		klog.Exitf("Failed to set up log: %v", err)
+	}
+	return ml
+}
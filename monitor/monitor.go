@@ -0,0 +1,308 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package monitor continuously verifies CT logs: it fetches their STHs,
+// checks their signatures and their consistency with the history of STHs
+// recorded for each log, and checks that each log produces fresh STHs within
+// its Maximum Merge Delay (MMD), raising alerts on violations.
+package monitor
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/google/trillian/monitoring"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultInterval is the default interval between STH fetches.
+	DefaultInterval = time.Minute
+	// DefaultMaxFuture is the default tolerance for STHs timestamped in the
+	// future.
+	DefaultMaxFuture = 5 * time.Minute
+	// DefaultRepeatInterval is the default interval between alerts of the
+	// same kind for a log.
+	DefaultRepeatInterval = time.Hour
+)
+
+var (
+	monitorOnce sync.Once
+	treeSize    monitoring.Gauge   // log => value
+	sthAge      monitoring.Gauge   // log => value
+	alerts      monitoring.Counter // log, kind => value
+)
+
+func setupMetrics(mf monitoring.MetricFactory) {
+	treeSize = mf.NewGauge("ctmonitor_tree_size", "Tree size of the latest STH of the log", "log")
+	sthAge = mf.NewGauge("ctmonitor_sth_age_seconds", "Age of the latest STH of the log", "log")
+	alerts = mf.NewCounter("ctmonitor_alerts", "Number of alerts raised for the log", "log", "kind")
+}
+
+// Log is a log watched by a Monitor.
+type Log struct {
+	URL         string
+	Description string
+	// ID is the SHA-256 hash of the public key of the log.
+	ID       [sha256.Size]byte
+	MMD      time.Duration
+	Client   client.CheckLogClient
+	Verifier *ct.SignatureVerifier
+}
+
+// NewLog creates a Log for a log of a log list. Its client does not check
+// STH signatures, which are checked by the Monitor instead.
+func NewLog(l *loglist3.Log, hc *http.Client) (*Log, error) {
+	url := l.URL
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		url = "https://" + url
+	}
+	lc, err := client.New(url, hc, jsonclient.Options{UserAgent: "ct-go-ctmonitor/1.0"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for log %q: %v", l.Description, err)
+	}
+	pubKey, err := x509.ParsePKIXPublicKey(l.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key of log %q: %v", l.Description, err)
+	}
+	verifier, err := ct.NewSignatureVerifier(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build verifier for log %q: %v", l.Description, err)
+	}
+	return &Log{
+		URL:         url,
+		Description: l.Description,
+		ID:          sha256.Sum256(l.Key),
+		MMD:         time.Duration(l.MMD) * time.Second,
+		Client:      lc,
+		Verifier:    verifier,
+	}, nil
+}
+
+// Options configures a Monitor.
+type Options struct {
+	// Interval between STH fetches for each log; if zero, DefaultInterval
+	// is used.
+	Interval time.Duration
+	// MaxFuture is how far in the future STH timestamps may be; if zero,
+	// DefaultMaxFuture is used.
+	MaxFuture time.Duration
+	// RepeatInterval is the minimal interval between alerts of the same kind
+	// for a log; if zero, DefaultRepeatInterval is used.
+	RepeatInterval time.Duration
+	// Alerter delivers the alerts, which are logged in any case.
+	Alerter Alerter
+	// MetricFactory, if set, exports the metrics of the Monitor.
+	MetricFactory monitoring.MetricFactory
+}
+
+type alertKey struct {
+	url  string
+	kind AlertKind
+}
+
+// Monitor watches a set of logs, recording their STHs in an STHStorage.
+type Monitor struct {
+	logs  []*Log
+	store ctutil.STHStorage
+	opts  Options
+	now   func() time.Time
+
+	mu    sync.Mutex
+	fired map[alertKey]time.Time
+}
+
+// New creates a Monitor of logs, which keeps their history of STHs in store.
+func New(logs []*Log, store ctutil.STHStorage, opts Options) *Monitor {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.MaxFuture <= 0 {
+		opts.MaxFuture = DefaultMaxFuture
+	}
+	if opts.RepeatInterval <= 0 {
+		opts.RepeatInterval = DefaultRepeatInterval
+	}
+	mf := opts.MetricFactory
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	monitorOnce.Do(func() { setupMetrics(mf) })
+	return &Monitor{logs: logs, store: store, opts: opts, now: time.Now, fired: make(map[alertKey]time.Time)}
+}
+
+// Run checks each log every Interval, until ctx is done.
+func (m *Monitor) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, l := range m.logs {
+		wg.Add(1)
+		go func(l *Log) {
+			defer wg.Done()
+			ticker := time.NewTicker(m.opts.Interval)
+			defer ticker.Stop()
+			for {
+				m.Check(ctx, l)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(l)
+	}
+	wg.Wait()
+}
+
+// Check fetches the current STH of l and verifies it, recording it if it is
+// valid. It returns the alerts raised, including those not delivered because
+// an alert of the same kind was delivered less than RepeatInterval ago.
+func (m *Monitor) Check(ctx context.Context, l *Log) []*Alert {
+	var raised []*Alert
+	raise := func(kind AlertKind, sth *ct.SignedTreeHead, format string, args ...interface{}) {
+		a := &Alert{
+			Kind:           kind,
+			LogURL:         l.URL,
+			LogDescription: l.Description,
+			Message:        fmt.Sprintf(format, args...),
+			STH:            sth,
+			Time:           m.now(),
+		}
+		raised = append(raised, a)
+		m.fire(ctx, a)
+	}
+
+	sth, err := l.Client.GetSTH(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			raise(AlertFetchFailed, nil, "failed to get STH: %v", err)
+		}
+		return raised
+	}
+	if err := l.Verifier.VerifySTHSignature(*sth); err != nil {
+		raise(AlertInvalidSignature, sth, "invalid signature of STH of size %d: %v", sth.TreeSize, err)
+		return raised
+	}
+	now := m.now()
+	if ts := ct.TimestampToTime(sth.Timestamp); ts.After(now.Add(m.opts.MaxFuture)) {
+		raise(AlertBadTimestamp, sth, "STH of size %d is timestamped in the future, at %v", sth.TreeSize, ts)
+		return raised
+	}
+
+	history, err := m.store.STHs(ctx, l.ID)
+	if err != nil {
+		klog.Errorf("%s: failed to read STH history: %v", l.URL, err)
+		return raised
+	}
+	latest := sth
+	if len(history) > 0 {
+		latest = history[len(history)-1]
+		if kind, err := m.checkHistory(ctx, l, history, sth); err != nil {
+			// Unverified STHs are not recorded, so that later ones are
+			// checked against the verified history.
+			raise(kind, sth, "%v", err)
+			return raised
+		}
+		if sth.TreeSize > latest.TreeSize && sth.Timestamp < latest.Timestamp {
+			raise(AlertBadTimestamp, sth, "STH of size %d is timestamped at %d, before the STH of size %d at %d", sth.TreeSize, sth.Timestamp, latest.TreeSize, latest.Timestamp)
+		}
+	}
+	if err := m.store.AddSTH(ctx, l.ID, sth); err != nil {
+		klog.Errorf("%s: failed to record STH: %v", l.URL, err)
+	}
+	if sth.TreeSize > latest.TreeSize || (sth.TreeSize == latest.TreeSize && sth.Timestamp > latest.Timestamp) {
+		latest = sth
+	}
+
+	age := now.Sub(ct.TimestampToTime(latest.Timestamp))
+	treeSize.Set(float64(latest.TreeSize), l.URL)
+	sthAge.Set(age.Seconds(), l.URL)
+	if l.MMD > 0 && age > l.MMD {
+		raise(AlertStaleSTH, latest, "latest STH, of size %d, is %v old, beyond the MMD of %v", latest.TreeSize, age.Round(time.Second), l.MMD)
+	}
+	return raised
+}
+
+// LatestSTH returns the verified STH of l with the largest tree size, or nil
+// if none was recorded yet.
+func (m *Monitor) LatestSTH(ctx context.Context, l *Log) (*ct.SignedTreeHead, error) {
+	return ctutil.LatestSTH(ctx, m.store, l.ID)
+}
+
+// checkHistory verifies that sth is consistent with the STHs in history,
+// ordered by tree size. As the STHs of history were checked against each
+// other when recorded, it is enough to check sth against the STHs of its
+// size, and the largest STH.
+func (m *Monitor) checkHistory(ctx context.Context, l *Log, history []*ct.SignedTreeHead, sth *ct.SignedTreeHead) (AlertKind, error) {
+	for _, known := range history {
+		if known.TreeSize == sth.TreeSize && known.SHA256RootHash != sth.SHA256RootHash {
+			return AlertInconsistent, fmt.Errorf("STHs of size %d have different root hashes %x and %x", sth.TreeSize, known.SHA256RootHash[:], sth.SHA256RootHash[:])
+		}
+	}
+	older, newer := sth, history[len(history)-1]
+	if older.TreeSize > newer.TreeSize {
+		older, newer = newer, older
+	}
+	if older.TreeSize == 0 || older.TreeSize == newer.TreeSize {
+		return "", nil
+	}
+	pf, err := l.Client.GetSTHConsistency(ctx, older.TreeSize, newer.TreeSize)
+	if err != nil {
+		return AlertFetchFailed, fmt.Errorf("failed to get consistency proof from %d to %d: %v", older.TreeSize, newer.TreeSize, err)
+	}
+	if err := proof.VerifyConsistency(rfc6962.DefaultHasher, older.TreeSize, newer.TreeSize, pf, older.SHA256RootHash[:], newer.SHA256RootHash[:]); err != nil {
+		return AlertInconsistent, fmt.Errorf("STH of size %d is inconsistent with STH of size %d: %v", older.TreeSize, newer.TreeSize, err)
+	}
+	return "", nil
+}
+
+// fire logs and counts a, and delivers it unless an alert of the same kind
+// was delivered for the log less than RepeatInterval ago.
+func (m *Monitor) fire(ctx context.Context, a *Alert) {
+	klog.Warningf("Alert: %s", a)
+	alerts.Inc(a.LogURL, string(a.Kind))
+	if m.opts.Alerter == nil {
+		return
+	}
+	key := alertKey{url: a.LogURL, kind: a.Kind}
+	m.mu.Lock()
+	last, ok := m.fired[key]
+	if ok && a.Time.Sub(last) < m.opts.RepeatInterval {
+		m.mu.Unlock()
+		return
+	}
+	m.fired[key] = a.Time
+	m.mu.Unlock()
+	if err := m.opts.Alerter.Alert(ctx, a); err != nil {
+		klog.Errorf("Failed to deliver alert %s: %v", a, err)
+		// Retry with the next alert of the same kind.
+		m.mu.Lock()
+		if m.fired[key] == a.Time {
+			delete(m.fired, key)
+		}
+		m.mu.Unlock()
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
+	"github.com/RarimoVoting/certificate-transparency-go/internal/testonly"
+	"github.com/transparency-dev/merkle/rfc6962"
+	merkletest "github.com/transparency-dev/merkle/testonly"
+)
+
+var testNow = time.Unix(1700000000, 0)
+
+// newTreeLog returns a fake log whose STHs are a minute old at testNow.
+func newTreeLog(t *testing.T) *testonly.TreeLog {
+	t.Helper()
+	l := testonly.NewTreeLog(t)
+	l.Now = testNow.Add(-time.Minute)
+	return l
+}
+
+// recorder is an Alerter recording the alerts delivered.
+type recorder struct {
+	mu     sync.Mutex
+	alerts []*Alert
+}
+
+func (r *recorder) Alert(_ context.Context, a *Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, a)
+	return nil
+}
+
+func newTestMonitor(t *testing.T, opts Options) (*Monitor, *Log, *testonly.TreeLog) {
+	t.Helper()
+	c := newTreeLog(t)
+	l := &Log{URL: "https://log.example.com/", Description: "Test log", MMD: time.Hour, Client: c, Verifier: c.Verifier()}
+	store, err := ctutil.OpenFileSTHStorage(filepath.Join(t.TempDir(), "sths.jsonl"))
+	if err != nil {
+		t.Fatalf("OpenFileSTHStorage()=%v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	m := New([]*Log{l}, store, opts)
+	m.now = func() time.Time { return testNow }
+	return m, l, c
+}
+
+func kinds(alerts []*Alert) []AlertKind {
+	var kinds []AlertKind
+	for _, a := range alerts {
+		kinds = append(kinds, a.Kind)
+	}
+	return kinds
+}
+
+func TestMonitorCheck(t *testing.T) {
+	ctx := context.Background()
+	m, l, c := newTestMonitor(t, Options{})
+	other := merkletest.New(rfc6962.DefaultHasher)
+	for i := 0; i < 8; i++ {
+		other.AppendData([]byte(fmt.Sprintf("other%d", i)))
+	}
+	badSig := c.Sign(3, other.HashAt(3), testNow.Add(-time.Minute))
+	badSig.TreeSize++
+
+	for _, test := range []struct {
+		name string
+		grow int
+		next *ct.SignedTreeHead
+		fork *merkletest.Tree
+		err  error
+		want AlertKind
+	}{
+		{name: "first", grow: 3},
+		{name: "same", grow: 0},
+		{name: "grown", grow: 2},
+		{name: "fetch-failure", err: errors.New("log unavailable"), want: AlertFetchFailed},
+		{name: "split-view", next: c.Sign(5, other.HashAt(5), testNow.Add(-time.Minute)), want: AlertInconsistent},
+		{name: "inconsistent", next: c.Sign(8, other.HashAt(8), testNow.Add(-time.Minute)), fork: other, want: AlertInconsistent},
+		{name: "rolled-back", next: c.Sign(4, other.HashAt(4), testNow.Add(-time.Minute)), want: AlertInconsistent},
+		{name: "invalid-signature", next: badSig, want: AlertInvalidSignature},
+		{name: "future", next: c.Sign(3, other.HashAt(3), testNow.Add(time.Hour)), want: AlertBadTimestamp},
+		{name: "grown-again", grow: 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c.Grow(test.grow)
+			c.Next, c.Fork, c.Err = test.next, test.fork, test.err
+			got := m.Check(ctx, l)
+			if test.want == "" {
+				if len(got) != 0 {
+					t.Errorf("Check()=%v; want no alerts", kinds(got))
+				}
+				return
+			}
+			if len(got) != 1 || got[0].Kind != test.want {
+				t.Errorf("Check()=%v; want %s", kinds(got), test.want)
+			}
+		})
+	}
+
+	sths, err := m.store.STHs(ctx, l.ID)
+	if err != nil {
+		t.Fatalf("STHs()=%v", err)
+	}
+	if len(sths) != 3 {
+		t.Errorf("recorded %d STHs; want only the 3 valid ones", len(sths))
+	}
+
+	// A larger tree signed before the latest STH.
+	c.Grow(1)
+	c.Next = c.Sign(c.Tree.Size(), c.Tree.Hash(), testNow.Add(-time.Hour))
+	if got := m.Check(ctx, l); len(got) != 1 || got[0].Kind != AlertBadTimestamp {
+		t.Errorf("Check()=%v; want %s", kinds(got), AlertBadTimestamp)
+	}
+}
+
+func TestMonitorStaleSTH(t *testing.T) {
+	ctx := context.Background()
+	rec := &recorder{}
+	m, l, c := newTestMonitor(t, Options{Alerter: rec, RepeatInterval: time.Hour})
+	c.Grow(3)
+	if got := m.Check(ctx, l); len(got) != 0 {
+		t.Fatalf("Check()=%v; want no alerts", kinds(got))
+	}
+
+	// The log keeps serving the same STH.
+	sth := c.Sign(c.Tree.Size(), c.Tree.Hash(), testNow.Add(-time.Minute))
+	for i, elapsed := range []time.Duration{2 * time.Hour, 150 * time.Minute, 210 * time.Minute} {
+		now := testNow.Add(elapsed)
+		m.now = func() time.Time { return now }
+		c.Next = sth
+		if got := m.Check(ctx, l); len(got) != 1 || got[0].Kind != AlertStaleSTH {
+			t.Errorf("Check() #%d=%v; want %s", i, kinds(got), AlertStaleSTH)
+		}
+	}
+	if len(rec.alerts) != 2 {
+		t.Errorf("delivered %d alerts; want 2, as the second one was within RepeatInterval", len(rec.alerts))
+	}
+}
+
+func TestAlerters(t *testing.T) {
+	var mu sync.Mutex
+	bodies := make(map[string][]byte)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		bodies[r.URL.Path] = body
+	}))
+	defer srv.Close()
+
+	a := &Alert{Kind: AlertInconsistent, LogURL: "https://log.example.com/", Message: "split view", Time: testNow}
+	alerter := Alerters{
+		&WebhookAlerter{URL: srv.URL + "/fail"},
+		&WebhookAlerter{URL: srv.URL + "/hook"},
+		&AlertmanagerAlerter{URL: srv.URL + "/", Labels: map[string]string{"severity": "page"}},
+	}
+	if err := alerter.Alert(context.Background(), a); err == nil {
+		t.Errorf("Alert()=nil; want error of the failing webhook")
+	}
+
+	var hook Alert
+	if err := json.Unmarshal(bodies["/hook"], &hook); err != nil {
+		t.Fatalf("webhook body: %v", err)
+	}
+	if hook.Kind != a.Kind || hook.Message != a.Message {
+		t.Errorf("webhook got %+v; want %+v", hook, a)
+	}
+	var am []amAlert
+	if err := json.Unmarshal(bodies["/api/v2/alerts"], &am); err != nil {
+		t.Fatalf("Alertmanager body: %v", err)
+	}
+	if len(am) != 1 || am[0].Labels["alertname"] != "CTLogInconsistent" || am[0].Labels["severity"] != "page" || am[0].Annotations["summary"] != "split view" {
+		t.Errorf("Alertmanager got %+v", am)
+	}
+}
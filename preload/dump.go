@@ -0,0 +1,185 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preload
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+)
+
+// Fields of a DumpRecord, as named in the output of the dumpscts tool.
+const (
+	FieldLogID     = "log_id"
+	FieldTimestamp = "timestamp"
+	FieldLeafHash  = "leaf_hash"
+	FieldDomains   = "domains"
+	FieldAdded     = "added"
+	FieldError     = "error"
+)
+
+// DumpFields lists all the fields of a DumpRecord.
+var DumpFields = []string{FieldLogID, FieldTimestamp, FieldLeafHash, FieldDomains, FieldAdded, FieldError}
+
+// ParseDumpFields parses a comma-separated list of field names.
+func ParseDumpFields(s string) ([]string, error) {
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		known := false
+		for _, k := range DumpFields {
+			known = known || f == k
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown field %q, want one of %s", f, strings.Join(DumpFields, ","))
+		}
+		fields = append(fields, f)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields in %q", s)
+	}
+	return fields, nil
+}
+
+// DumpRecord holds the fields of an AddedCert which are dumped.
+type DumpRecord struct {
+	// LogID is the base64 ID of the log which issued the SCT.
+	LogID string
+	// Timestamp of the SCT, in milliseconds since the epoch.
+	Timestamp uint64
+	// LeafHash is the hex Merkle leaf hash of the entry added to the log. It
+	// is empty for precertificates, whose leaf depends on their issuer which
+	// is not recorded.
+	LeafHash string
+	// Domains are the subject common name and DNS names of the certificate.
+	Domains []string
+	Added   bool
+	Error   string
+}
+
+// NewDumpRecord extracts the fields of addedCert. The fields which cannot be
+// derived, e.g. as the certificate does not parse, are left empty.
+func NewDumpRecord(addedCert *AddedCert) *DumpRecord {
+	r := &DumpRecord{Added: addedCert.AddedOk, Error: addedCert.ErrorMessage}
+	sct := &addedCert.SignedCertificateTimestamp
+	if addedCert.AddedOk {
+		r.LogID = base64.StdEncoding.EncodeToString(sct.LogID.KeyID[:])
+		r.Timestamp = sct.Timestamp
+	}
+
+	cert, err := x509.ParseCertificate(addedCert.CertDER.Data)
+	if x509.IsFatal(err) {
+		return r
+	}
+	seen := make(map[string]bool)
+	for _, name := range append([]string{cert.Subject.CommonName}, cert.DNSNames...) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			r.Domains = append(r.Domains, name)
+		}
+	}
+	if addedCert.AddedOk && !cert.IsPrecertificate() {
+		leaf := ct.MerkleTreeLeaf{
+			Version:  ct.V1,
+			LeafType: ct.TimestampedEntryLeafType,
+			TimestampedEntry: &ct.TimestampedEntry{
+				Timestamp:  sct.Timestamp,
+				EntryType:  ct.X509LogEntryType,
+				X509Entry:  &addedCert.CertDER,
+				Extensions: sct.Extensions,
+			},
+		}
+		if hash, err := ct.LeafHashForLeaf(&leaf); err == nil {
+			r.LeafHash = hex.EncodeToString(hash[:])
+		}
+	}
+	return r
+}
+
+// value returns the field of r with the given name.
+func (r *DumpRecord) value(field string) interface{} {
+	switch field {
+	case FieldLogID:
+		return r.LogID
+	case FieldTimestamp:
+		return r.Timestamp
+	case FieldLeafHash:
+		return r.LeafHash
+	case FieldDomains:
+		if r.Domains == nil {
+			return []string{}
+		}
+		return r.Domains
+	case FieldAdded:
+		return r.Added
+	case FieldError:
+		return r.Error
+	}
+	return nil
+}
+
+// JSON returns the given fields of r as a JSON object, in the order of
+// fields.
+func (r *DumpRecord) JSON(fields []string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(r.value(field))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// CSV returns the given fields of r as the columns of a CSV row. Domains are
+// separated by spaces.
+func (r *DumpRecord) CSV(fields []string) []string {
+	row := make([]string, len(fields))
+	for i, field := range fields {
+		switch field {
+		case FieldTimestamp:
+			row[i] = strconv.FormatUint(r.Timestamp, 10)
+		case FieldDomains:
+			row[i] = strings.Join(r.Domains, " ")
+		case FieldAdded:
+			row[i] = strconv.FormatBool(r.Added)
+		default:
+			row[i] = fmt.Sprint(r.value(field))
+		}
+	}
+	return row
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preload
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+)
+
+func TestParseDumpFields(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "log_id,timestamp", want: []string{FieldLogID, FieldTimestamp}},
+		{in: " domains , error,", want: []string{FieldDomains, FieldError}},
+		{in: "", wantErr: true},
+		{in: "log_id,serial", wantErr: true},
+	} {
+		got, err := ParseDumpFields(test.in)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("ParseDumpFields(%q)=_,%v, want err? %v", test.in, err, test.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseDumpFields(%q)=%v, want %v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestDumpRecord(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com", "www.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sct := ct.SignedCertificateTimestamp{Timestamp: 1234, LogID: ct.LogID{KeyID: [32]byte{1, 2, 3}}}
+	r := NewDumpRecord(&AddedCert{CertDER: ct.ASN1Cert{Data: cert.Raw}, SignedCertificateTimestamp: sct, AddedOk: true})
+
+	leaf, err := ct.MerkleTreeLeafFromRawChain([]ct.ASN1Cert{{Data: cert.Raw}}, ct.X509LogEntryType, 1234)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := ct.LeafHashForLeaf(leaf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.LeafHash, hex.EncodeToString(hash[:]); got != want {
+		t.Errorf("LeafHash=%s, want %s", got, want)
+	}
+	if want := []string{"example.com", "www.example.com"}; !reflect.DeepEqual(r.Domains, want) {
+		t.Errorf("Domains=%v, want %v", r.Domains, want)
+	}
+
+	fields := []string{FieldTimestamp, FieldLogID, FieldAdded}
+	json, err := r.JSON(fields)
+	if err != nil {
+		t.Fatalf("JSON()=%v", err)
+	}
+	if got, want := string(json), `{"timestamp":1234,"log_id":"AQIDAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=","added":true}`; got != want {
+		t.Errorf("JSON()=%s, want %s", got, want)
+	}
+	row := r.CSV([]string{FieldTimestamp, FieldDomains, FieldError})
+	if want := []string{"1234", "example.com www.example.com", ""}; !reflect.DeepEqual(row, want) {
+		t.Errorf("CSV()=%v, want %v", row, want)
+	}
+
+	precerts, err := x509util.CertificatesFromPEM([]byte(testdata.TestPreCertPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	precert := NewDumpRecord(&AddedCert{CertDER: ct.ASN1Cert{Data: precerts[0].Raw}, SignedCertificateTimestamp: sct, AddedOk: true})
+	if precert.LeafHash != "" {
+		t.Errorf("LeafHash=%s for precertificate, want empty", precert.LeafHash)
+	}
+
+	failed := NewDumpRecord(&AddedCert{CertDER: ct.ASN1Cert{Data: []byte("bad")}, ErrorMessage: "rejected"})
+	if failed.LeafHash != "" || failed.LogID != "" || failed.Error != "rejected" {
+		t.Errorf("NewDumpRecord(failed)=%+v", failed)
+	}
+	if json, err := failed.JSON([]string{FieldDomains}); err != nil || string(json) != `{"domains":[]}` {
+		t.Errorf("JSON()=%s,%v, want {\"domains\":[]}", json, err)
+	}
+}
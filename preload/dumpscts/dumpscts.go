@@ -13,21 +13,144 @@
 // limitations under the License.
 
 // Dumpscts prints out SCTs written to a file by the preloader command in
-// the ../preloader directory.
+// the ../preloader directory, either as log lines or, with --format, as JSON
+// lines or CSV rows of selected fields.
 package main
 
 import (
+	"bufio"
 	"compress/zlib"
+	"encoding/csv"
 	"encoding/gob"
 	"flag"
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"sync"
 
 	"github.com/RarimoVoting/certificate-transparency-go/preload"
 )
 
-var sctFile = flag.String("sct_file", "", "File to load SCTs & leaf data from")
+var (
+	sctFile    = flag.String("sct_file", "", "File to load SCTs & leaf data from")
+	format     = flag.String("format", "text", "Output format: text, jsonl or csv")
+	fields     = flag.String("fields", "log_id,timestamp,leaf_hash,domains", "Comma-separated fields to output in jsonl or csv format, from: log_id, timestamp, leaf_hash, domains, added, error")
+	outputFile = flag.String("output", "", "File to write jsonl or csv output to; stdout if empty")
+	parallel   = flag.Int("parallel", 4, "Number of workers extracting the fields of the records")
+	batchSize  = flag.Int("batch_size", 1000, "Number of records handed to each worker at a time")
+)
+
+// recordWriter writes DumpRecords in one of the output formats.
+type recordWriter interface {
+	Write(r *preload.DumpRecord) error
+	Flush() error
+}
+
+type jsonlWriter struct {
+	w      *bufio.Writer
+	fields []string
+}
+
+func (j *jsonlWriter) Write(r *preload.DumpRecord) error {
+	data, err := r.JSON(j.fields)
+	if err != nil {
+		return err
+	}
+	if _, err := j.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (j *jsonlWriter) Flush() error {
+	return j.w.Flush()
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	fields []string
+}
+
+func (c *csvWriter) Write(r *preload.DumpRecord) error {
+	return c.w.Write(r.CSV(c.fields))
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func newRecordWriter(out io.Writer, format string, fields []string) (recordWriter, error) {
+	switch format {
+	case "jsonl":
+		return &jsonlWriter{w: bufio.NewWriter(out), fields: fields}, nil
+	case "csv":
+		w := csv.NewWriter(out)
+		if err := w.Write(fields); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: w, fields: fields}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// dumpRecords extracts the fields of the records read by decoder using
+// several workers, and writes them in their original order.
+func dumpRecords(decoder *gob.Decoder, w recordWriter) (numAdded, numFailed int, err error) {
+	workers := *parallel
+	if workers < 1 {
+		workers = 1
+	}
+	size := *batchSize
+	if size < 1 {
+		size = 1
+	}
+	batch := make([]preload.AddedCert, 0, size*workers)
+	records := make([]*preload.DumpRecord, size*workers)
+	for done := false; !done; {
+		batch = batch[:0]
+		for len(batch) < cap(batch) {
+			var addedCert preload.AddedCert
+			if err := decoder.Decode(&addedCert); err != nil {
+				if err != io.EOF {
+					log.Printf("Stopped reading records: %v", err)
+				}
+				done = true
+				break
+			}
+			batch = append(batch, addedCert)
+		}
+
+		var wg sync.WaitGroup
+		for start := 0; start < len(batch); start += size {
+			end := start + size
+			if end > len(batch) {
+				end = len(batch)
+			}
+			wg.Add(1)
+			go func(start, end int) {
+				defer wg.Done()
+				for i := start; i < end; i++ {
+					records[i] = preload.NewDumpRecord(&batch[i])
+				}
+			}(start, end)
+		}
+		wg.Wait()
+
+		for i := range batch {
+			if batch[i].AddedOk {
+				numAdded++
+			} else {
+				numFailed++
+			}
+			if err := w.Write(records[i]); err != nil {
+				return numAdded, numFailed, err
+			}
+		}
+	}
+	return numAdded, numFailed, w.Flush()
+}
 
 func main() {
 	flag.Parse()
@@ -53,6 +176,34 @@ func main() {
 
 	// TODO(alcutter) should probably store this stuff in a protobuf really.
 	decoder := gob.NewDecoder(sctReader)
+	if *format != "text" {
+		dumpFields, err := preload.ParseDumpFields(*fields)
+		if err != nil {
+			log.Fatalf("Invalid --fields: %v", err)
+		}
+		out := os.Stdout
+		if *outputFile != "" {
+			if out, err = os.Create(*outputFile); err != nil {
+				log.Fatal(err)
+			}
+		}
+		w, err := newRecordWriter(out, *format, dumpFields)
+		if err != nil {
+			log.Fatalf("Invalid --format: %v", err)
+		}
+		numAdded, numFailed, err := dumpRecords(decoder, w)
+		if err != nil {
+			log.Fatalf("Failed to write output: %v", err)
+		}
+		if out != os.Stdout {
+			if err := out.Close(); err != nil {
+				log.Fatalf("Failed to close output: %v", err)
+			}
+		}
+		log.Printf("Num certs added: %d, num failed: %d\n", numAdded, numFailed)
+		return
+	}
+
 	var addedCert preload.AddedCert
 	numAdded := 0
 	numFailed := 0
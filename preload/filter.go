@@ -0,0 +1,167 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preload
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+)
+
+// SkipReason is the reason why a Filter excludes an entry from submission.
+type SkipReason string
+
+// Reasons for skipping entries.
+const (
+	// The certificate has expired.
+	SkipExpired SkipReason = "expired"
+	// The certificate's NotAfter is outside the target log's temporal window.
+	SkipOutsideWindow SkipReason = "outside temporal window"
+	// The chain does not end in a root accepted by the target log.
+	SkipUntrustedRoot SkipReason = "untrusted root"
+	// The entry could not be checked.
+	SkipUnparsable SkipReason = "unparsable"
+)
+
+// FilterOptions configures a Filter. The zero value lets every entry through.
+type FilterOptions struct {
+	// SkipExpired excludes certificates whose NotAfter has passed.
+	SkipExpired bool
+	// NotAfterStart and NotAfterLimit, if set, are the inclusive start and
+	// exclusive limit of the NotAfter dates accepted by the target log.
+	NotAfterStart *time.Time
+	NotAfterLimit *time.Time
+	// Roots, if set, are the roots accepted by the target log, as returned by
+	// get-roots. Entries whose chain does not end in one of them, or in a
+	// certificate issued by one of them, are excluded.
+	Roots []ct.ASN1Cert
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Filter decides which entries of the source log are worth submitting to the
+// target log, excluding those which the target log would certainly reject.
+// It is safe for concurrent use.
+type Filter struct {
+	opts  FilterOptions
+	roots map[[sha256.Size]byte]bool
+	// Roots by their raw subject, to find the issuer of a chain without its
+	// root.
+	rootsBySubject map[string][]*x509.Certificate
+
+	mu     sync.Mutex
+	counts map[SkipReason]int64
+}
+
+// NewFilter creates a Filter with the given options.
+func NewFilter(opts FilterOptions) (*Filter, error) {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	f := &Filter{opts: opts, counts: make(map[SkipReason]int64)}
+	if opts.Roots != nil {
+		f.roots = make(map[[sha256.Size]byte]bool)
+		f.rootsBySubject = make(map[string][]*x509.Certificate)
+		for i, root := range opts.Roots {
+			cert, err := x509.ParseCertificate(root.Data)
+			if x509.IsFatal(err) {
+				return nil, fmt.Errorf("failed to parse root %d: %v", i, err)
+			}
+			f.roots[sha256.Sum256(root.Data)] = true
+			f.rootsBySubject[string(cert.RawSubject)] = append(f.rootsBySubject[string(cert.RawSubject)], cert)
+		}
+	}
+	return f, nil
+}
+
+// Skip returns the reason to exclude entry from submission, or "" if it
+// should be submitted.
+func (f *Filter) Skip(entry *ct.LogEntry) SkipReason {
+	reason := f.check(entry)
+	if reason != "" {
+		f.mu.Lock()
+		f.counts[reason]++
+		f.mu.Unlock()
+	}
+	return reason
+}
+
+func (f *Filter) check(entry *ct.LogEntry) SkipReason {
+	var cert *x509.Certificate
+	var leafDER []byte
+	switch {
+	case entry.X509Cert != nil:
+		cert, leafDER = entry.X509Cert, entry.X509Cert.Raw
+	case entry.Precert != nil && entry.Precert.TBSCertificate != nil:
+		cert, leafDER = entry.Precert.TBSCertificate, entry.Precert.Submitted.Data
+	default:
+		return SkipUnparsable
+	}
+
+	if f.opts.SkipExpired && !f.opts.Now().Before(cert.NotAfter) {
+		return SkipExpired
+	}
+	if start := f.opts.NotAfterStart; start != nil && cert.NotAfter.Before(*start) {
+		return SkipOutsideWindow
+	}
+	if limit := f.opts.NotAfterLimit; limit != nil && !cert.NotAfter.Before(*limit) {
+		return SkipOutsideWindow
+	}
+	if f.roots != nil && !f.endsInRoot(leafDER, entry.Chain) {
+		return SkipUntrustedRoot
+	}
+	return ""
+}
+
+// endsInRoot reports whether the chain of leaf ends in an accepted root, or
+// in a certificate signed by one.
+func (f *Filter) endsInRoot(leaf []byte, chain []ct.ASN1Cert) bool {
+	last := leaf
+	if len(chain) > 0 {
+		last = chain[len(chain)-1].Data
+	}
+	if f.roots[sha256.Sum256(last)] {
+		return true
+	}
+	cert, err := x509.ParseCertificate(last)
+	if x509.IsFatal(err) {
+		return false
+	}
+	for _, root := range f.rootsBySubject[string(cert.RawIssuer)] {
+		if bytes.Equal(root.Raw, cert.Raw) {
+			continue
+		}
+		if cert.CheckSignatureFrom(root) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Counts returns the number of entries skipped for each reason.
+func (f *Filter) Counts() map[SkipReason]int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	counts := make(map[SkipReason]int64, len(f.counts))
+	for reason, n := range f.counts {
+		counts[reason] = n
+	}
+	return counts
+}
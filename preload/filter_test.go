@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preload
+
+import (
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+)
+
+func TestFilter(t *testing.T) {
+	chain, err := x509util.CertificatesFromPEM([]byte(testdata.TestCertPEM + testdata.CACertPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, ca := chain[0], chain[1]
+	before := leaf.NotAfter.Add(-time.Hour)
+	after := leaf.NotAfter.Add(time.Hour)
+	caRoot := []ct.ASN1Cert{{Data: ca.Raw}}
+
+	for _, test := range []struct {
+		desc     string
+		opts     FilterOptions
+		withRoot bool
+		want     SkipReason
+	}{
+		{desc: "no-filter", withRoot: true},
+		{desc: "not-expired", opts: FilterOptions{SkipExpired: true, Now: func() time.Time { return before }}},
+		{desc: "expired", opts: FilterOptions{SkipExpired: true, Now: func() time.Time { return after }}, want: SkipExpired},
+		{desc: "in-window", opts: FilterOptions{NotAfterStart: &before, NotAfterLimit: &after}},
+		{desc: "before-window", opts: FilterOptions{NotAfterStart: &after}, want: SkipOutsideWindow},
+		{desc: "after-window", opts: FilterOptions{NotAfterLimit: &before}, want: SkipOutsideWindow},
+		{desc: "chain-with-root", opts: FilterOptions{Roots: caRoot}, withRoot: true},
+		{desc: "chain-without-root", opts: FilterOptions{Roots: caRoot}},
+		{desc: "other-root", opts: FilterOptions{Roots: []ct.ASN1Cert{{Data: leaf.Raw}}}, withRoot: true, want: SkipUntrustedRoot},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			f, err := NewFilter(test.opts)
+			if err != nil {
+				t.Fatalf("NewFilter()=%v", err)
+			}
+			entry := &ct.LogEntry{X509Cert: leaf}
+			if test.withRoot {
+				entry.Chain = []ct.ASN1Cert{{Data: ca.Raw}}
+			}
+			if got := f.Skip(entry); got != test.want {
+				t.Errorf("Skip()=%q, want %q", got, test.want)
+			}
+			if test.want != "" {
+				if got := f.Counts()[test.want]; got != 1 {
+					t.Errorf("Counts()[%q]=%d, want 1", test.want, got)
+				}
+			}
+		})
+	}
+}
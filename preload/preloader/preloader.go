@@ -19,11 +19,13 @@ import (
 	"compress/zlib"
 	"context"
 	"encoding/gob"
+	"errors"
 	"flag"
 	"io"
 	"net/http"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	ct "github.com/RarimoVoting/certificate-transparency-go"
@@ -32,6 +34,7 @@ import (
 	"github.com/RarimoVoting/certificate-transparency-go/preload"
 	"github.com/RarimoVoting/certificate-transparency-go/scanner"
 	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"golang.org/x/time/rate"
 	"k8s.io/klog/v2"
 )
 
@@ -44,7 +47,14 @@ var (
 	parallelFetch         = flag.Int("parallel_fetch", 2, "Number of concurrent GetEntries fetches")
 	parallelSubmit        = flag.Int("parallel_submit", 2, "Number of concurrent add-[pre]-chain requests")
 	startIndex            = flag.Int64("start_index", 0, "Log index to start scanning at")
+	sourceRateLimit       = flag.Float64("source_rate_limit", 0, "Maximum number of requests per second to the source log (0 = unlimited)")
 	sctInputFile          = flag.String("sct_file", "", "File to save SCTs & leaf data to")
+	stateFile             = flag.String("state_file", "", "File in which to record the outcome of each submission, so that a restarted preload skips the entries already submitted")
+	skipExpired           = flag.Bool("skip_expired", false, "Skip certificates which have already expired")
+	notAfterStart         = flag.String("target_not_after_start", "", "Start of the NotAfter dates accepted by the target log, in RFC 3339 format; skip certificates before it (defaults to the start of --target_temporal_log_cfg)")
+	notAfterLimit         = flag.String("target_not_after_limit", "", "Limit of the NotAfter dates accepted by the target log, in RFC 3339 format; skip certificates at or after it (defaults to the limit of --target_temporal_log_cfg)")
+	checkRoots            = flag.Bool("check_target_roots", false, "Skip entries whose chain does not end in a root accepted by the target log, as returned by get-roots")
+	retryFailed           = flag.Bool("retry_failed", true, "Whether to submit again the entries whose submission failed in an earlier run with the same --state_file")
 	precertsOnly          = flag.Bool("precerts_only", false, "Only match precerts")
 	tlsTimeout            = flag.Duration("tls_timeout", 30*time.Second, "TLS handshake timeout (see http.Transport)")
 	rspHeaderTimeout      = flag.Duration("response_header_timeout", 30*time.Second, "Response header timeout (see http.Transport)")
@@ -95,12 +105,54 @@ func sctDumper(addedCerts <-chan *preload.AddedCert, sctWriter io.Writer) {
 	klog.Infof("Added %d certs, %d failed, total: %d\n", numAdded, numFailed, numAdded+numFailed)
 }
 
-func certSubmitter(ctx context.Context, addedCerts chan<- *preload.AddedCert, logClient client.AddLogClient, certs <-chan *ct.LogEntry) {
+// recordOutcome records the outcome of the submission of the entry at index
+// in state, if set.
+func recordOutcome(state *preload.SubmissionState, index int64, subErr error) {
+	if state == nil {
+		return
+	}
+	status := preload.StatusAccepted
+	if subErr != nil {
+		status = preload.StatusFailed
+	}
+	if err := state.Record(index, status, subErr); err != nil {
+		klog.Exitf("Failed to record submission of entry %d in %s: %v", index, *stateFile, err)
+	}
+}
+
+// alreadySubmitted reports whether the entry at index need not be submitted,
+// given its outcome in an earlier run.
+func alreadySubmitted(state *preload.SubmissionState, index int64) bool {
+	if state == nil {
+		return false
+	}
+	status, ok := state.Status(index)
+	return ok && (status == preload.StatusAccepted || status == preload.StatusFailed && !*retryFailed)
+}
+
+// filtered reports whether filter excludes entry from submission, recording
+// the reason in state, if set.
+func filtered(filter *preload.Filter, state *preload.SubmissionState, entry *ct.LogEntry) bool {
+	reason := filter.Skip(entry)
+	if reason == "" {
+		return false
+	}
+	klog.V(2).Infof("Skipping entry %d: %s", entry.Index, reason)
+	if state != nil {
+		if err := state.Record(entry.Index, preload.StatusSkipped, errors.New(string(reason))); err != nil {
+			klog.Exitf("Failed to record skipping of entry %d in %s: %v", entry.Index, *stateFile, err)
+		}
+	}
+	return true
+}
+
+func certSubmitter(ctx context.Context, addedCerts chan<- *preload.AddedCert, logClient client.AddLogClient, state *preload.SubmissionState, certs <-chan *ct.LogEntry) {
 	for c := range certs {
 		chain := make([]ct.ASN1Cert, len(c.Chain)+1)
 		chain[0] = ct.ASN1Cert{Data: c.X509Cert.Raw}
 		copy(chain[1:], c.Chain)
 		sct, err := logClient.AddChain(ctx, chain)
+		recordOutcome(state, c.Index, err)
 		if err != nil {
 			klog.Errorf("failed to add chain with CN %s: %v\n", c.X509Cert.Subject.CommonName, err)
 			recordFailure(addedCerts, chain[0], err)
@@ -111,12 +163,13 @@ func certSubmitter(ctx context.Context, addedCerts chan<- *preload.AddedCert, lo
 	}
 }
 
-func precertSubmitter(ctx context.Context, addedCerts chan<- *preload.AddedCert, logClient client.AddLogClient, precerts <-chan *ct.LogEntry) {
+func precertSubmitter(ctx context.Context, addedCerts chan<- *preload.AddedCert, logClient client.AddLogClient, state *preload.SubmissionState, precerts <-chan *ct.LogEntry) {
 	for c := range precerts {
 		chain := make([]ct.ASN1Cert, len(c.Chain)+1)
 		chain[0] = c.Precert.Submitted
 		copy(chain[1:], c.Chain)
 		sct, err := logClient.AddPreChain(ctx, chain)
+		recordOutcome(state, c.Index, err)
 		if err != nil {
 			klog.Errorf("failed to add pre-chain with CN %s: %v", c.Precert.TBSCertificate.Subject.CommonName, err)
 			recordFailure(addedCerts, chain[0], err)
@@ -144,6 +197,16 @@ func main() {
 		sctFileWriter = io.Discard
 	}
 
+	var state *preload.SubmissionState
+	if *stateFile != "" {
+		state, err = preload.OpenSubmissionState(*stateFile)
+		if err != nil {
+			klog.Exitf("Failed to open state file: %v", err)
+		}
+		counts := state.Counts()
+		klog.Infof("Resuming with %d entries accepted and %d failed in earlier runs", counts[preload.StatusAccepted], counts[preload.StatusFailed])
+	}
+
 	sctWriter := zlib.NewWriter(sctFileWriter)
 	defer func() {
 		err := sctWriter.Close()
@@ -162,14 +225,25 @@ func main() {
 		ExpectContinueTimeout: *expectContinueTimeout,
 	}
 
+	fetchOpts := jsonclient.Options{UserAgent: "ct-go-preloader/1.0"}
+	if *sourceRateLimit > 0 {
+		fetchOpts.RateLimiter = rate.NewLimiter(rate.Limit(*sourceRateLimit), 1)
+	}
 	fetchLogClient, err := client.New(*sourceLogURI, &http.Client{
 		Timeout:   10 * time.Second,
 		Transport: transport,
-	}, jsonclient.Options{UserAgent: "ct-go-preloader/1.0"})
+	}, fetchOpts)
 	if err != nil {
 		klog.Exitf("Failed to create client for source log: %v", err)
 	}
 
+	// Skip the prefix of the log which has been submitted already.
+	var skipped int64
+	for alreadySubmitted(state, *startIndex) {
+		*startIndex++
+		skipped++
+	}
+
 	opts := scanner.ScannerOptions{
 		FetcherOptions: scanner.FetcherOptions{
 			BatchSize:     *batchSize,
@@ -194,12 +268,22 @@ func main() {
 		sctDumper(addedCerts, sctWriter)
 	}()
 
+	filterOpts := preload.FilterOptions{SkipExpired: *skipExpired}
 	var submitLogClient client.AddLogClient
 	if *targetTemporalLogCfg != "" {
 		cfg, err := client.TemporalLogConfigFromFile(*targetTemporalLogCfg)
 		if err != nil {
 			klog.Exitf("Failed to load temporal log config: %v", err)
 		}
+		// The shards of a temporal log are contiguous.
+		if start := cfg.Shard[0].GetNotAfterStart(); start != nil {
+			t := start.AsTime()
+			filterOpts.NotAfterStart = &t
+		}
+		if limit := cfg.Shard[len(cfg.Shard)-1].GetNotAfterLimit(); limit != nil {
+			t := limit.AsTime()
+			filterOpts.NotAfterLimit = &t
+		}
 		submitLogClient, err = client.NewTemporalLogClient(cfg, &http.Client{Transport: transport})
 		if err != nil {
 			klog.Exitf("Failed to create client for destination temporal log: %v", err)
@@ -212,34 +296,72 @@ func main() {
 	}
 
 	ctx := context.Background()
+	if *notAfterStart != "" {
+		t, err := time.Parse(time.RFC3339, *notAfterStart)
+		if err != nil {
+			klog.Exitf("Failed to parse --target_not_after_start: %v", err)
+		}
+		filterOpts.NotAfterStart = &t
+	}
+	if *notAfterLimit != "" {
+		t, err := time.Parse(time.RFC3339, *notAfterLimit)
+		if err != nil {
+			klog.Exitf("Failed to parse --target_not_after_limit: %v", err)
+		}
+		filterOpts.NotAfterLimit = &t
+	}
+	if *checkRoots {
+		filterOpts.Roots, err = submitLogClient.GetAcceptedRoots(ctx)
+		if err != nil {
+			klog.Exitf("Failed to get accepted roots of target log: %v", err)
+		}
+		klog.Infof("Target log accepts %d roots", len(filterOpts.Roots))
+	}
+	filter, err := preload.NewFilter(filterOpts)
+	if err != nil {
+		klog.Exitf("Failed to create filter: %v", err)
+	}
+
 	var submitterWG sync.WaitGroup
 	for w := 0; w < *parallelSubmit; w++ {
 		submitterWG.Add(2)
 		go func() {
 			defer submitterWG.Done()
-			certSubmitter(ctx, addedCerts, submitLogClient, certs)
+			certSubmitter(ctx, addedCerts, submitLogClient, state, certs)
 		}()
 		go func() {
 			defer submitterWG.Done()
-			precertSubmitter(ctx, addedCerts, submitLogClient, precerts)
+			precertSubmitter(ctx, addedCerts, submitLogClient, state, precerts)
 		}()
 	}
 
 	addChainFunc := func(rawEntry *ct.RawLogEntry) {
+		if alreadySubmitted(state, rawEntry.Index) {
+			atomic.AddInt64(&skipped, 1)
+			return
+		}
 		entry, err := rawEntry.ToLogEntry()
 		if x509.IsFatal(err) {
 			klog.Errorf("Failed to parse cert at %d: %v", rawEntry.Index, err)
 			return
 		}
-		certs <- entry
+		if !filtered(filter, state, entry) {
+			certs <- entry
+		}
 	}
 	addPreChainFunc := func(rawEntry *ct.RawLogEntry) {
+		if alreadySubmitted(state, rawEntry.Index) {
+			atomic.AddInt64(&skipped, 1)
+			return
+		}
 		entry, err := rawEntry.ToLogEntry()
 		if x509.IsFatal(err) {
 			klog.Errorf("Failed to parse precert at %d: %v", rawEntry.Index, err)
 			return
 		}
-		precerts <- entry
+		if !filtered(filter, state, entry) {
+			precerts <- entry
+		}
 	}
 	if err := s.Scan(ctx, addChainFunc, addPreChainFunc); err != nil {
 		klog.Errorf("Scan(): %v", err)
@@ -250,4 +372,16 @@ func main() {
 	submitterWG.Wait()
 	close(addedCerts)
 	sctWriterWG.Wait()
+
+	for reason, n := range filter.Counts() {
+		klog.Infof("Skipped %d entries: %s", n, reason)
+	}
+
+	if state != nil {
+		counts := state.Counts()
+		klog.Infof("Skipped %d entries submitted in earlier runs; in all, %d entries accepted and %d failed", atomic.LoadInt64(&skipped), counts[preload.StatusAccepted], counts[preload.StatusFailed])
+		if err := state.Close(); err != nil {
+			klog.Errorf("Failed to close state file: %v", err)
+		}
+	}
 }
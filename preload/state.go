@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preload
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/RarimoVoting/certificate-transparency-go/internal/journal"
+)
+
+// SubmissionStatus is the outcome of submitting an entry of the source log.
+type SubmissionStatus string
+
+// Outcomes of submissions.
+const (
+	// The target log accepted the entry and returned an SCT.
+	StatusAccepted SubmissionStatus = "accepted"
+	// The submission failed, or the target log rejected the entry.
+	StatusFailed SubmissionStatus = "failed"
+	// The entry was not submitted, as the target log would reject it. It is
+	// considered again by a later run.
+	StatusSkipped SubmissionStatus = "skipped"
+)
+
+// SubmissionRecord is the persisted outcome of submitting one entry.
+type SubmissionRecord struct {
+	Index  int64            `json:"index"`
+	Status SubmissionStatus `json:"status"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// SubmissionState records the outcome of the submission of each entry of the
+// source log in a journal file of JSON lines, so that an interrupted preload
+// can resume without submitting the entries again. The latest record for an
+// entry wins. It is safe for concurrent use.
+type SubmissionState struct {
+	mu     sync.Mutex
+	j      *journal.Journal
+	status map[int64]SubmissionStatus
+}
+
+// OpenSubmissionState opens the journal at path, creating it if needed, and
+// loads the outcomes recorded in it. A partial last line, as left by a crash,
+// is discarded.
+func OpenSubmissionState(path string) (*SubmissionState, error) {
+	s := &SubmissionState{status: make(map[int64]SubmissionStatus)}
+	j, err := journal.Open(path, func(data []byte) error {
+		var rec SubmissionRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		s.status[rec.Index] = rec.Status
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.j = j
+	return s, nil
+}
+
+// Status returns the latest recorded outcome for the entry at index, if any.
+func (s *SubmissionState) Status(index int64) (SubmissionStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status, ok := s.status[index]
+	return status, ok
+}
+
+// Record appends the outcome of the submission of the entry at index.
+func (s *SubmissionState) Record(index int64, status SubmissionStatus, subErr error) error {
+	rec := SubmissionRecord{Index: index, Status: status}
+	if subErr != nil {
+		rec.Error = subErr.Error()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.j.Append(rec); err != nil {
+		return err
+	}
+	s.status[index] = status
+	return nil
+}
+
+// Counts returns the number of entries with each latest outcome.
+func (s *SubmissionState) Counts() map[SubmissionStatus]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[SubmissionStatus]int)
+	for _, status := range s.status {
+		counts[status]++
+	}
+	return counts
+}
+
+// Close syncs and closes the journal.
+func (s *SubmissionState) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.j.Close()
+}
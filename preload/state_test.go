@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preload
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSubmissionState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.jsonl")
+	s, err := OpenSubmissionState(path)
+	if err != nil {
+		t.Fatalf("OpenSubmissionState()=%v", err)
+	}
+	for _, rec := range []struct {
+		index  int64
+		status SubmissionStatus
+		err    error
+	}{
+		{0, StatusAccepted, nil},
+		{1, StatusFailed, errors.New("rejected")},
+		{2, StatusFailed, errors.New("timeout")},
+		{2, StatusAccepted, nil},
+	} {
+		if err := s.Record(rec.index, rec.status, rec.err); err != nil {
+			t.Fatalf("Record(%d)=%v", rec.index, err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close()=%v", err)
+	}
+
+	// Simulate a crash in the middle of writing a record.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"index":3,"sta`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	s, err = OpenSubmissionState(path)
+	if err != nil {
+		t.Fatalf("OpenSubmissionState() after crash=%v", err)
+	}
+	for index, want := range map[int64]SubmissionStatus{0: StatusAccepted, 1: StatusFailed, 2: StatusAccepted} {
+		if got, ok := s.Status(index); !ok || got != want {
+			t.Errorf("Status(%d)=%v,%v, want %v", index, got, ok, want)
+		}
+	}
+	if _, ok := s.Status(3); ok {
+		t.Error("Status(3) found the partial record")
+	}
+	if err := s.Record(3, StatusAccepted, nil); err != nil {
+		t.Fatalf("Record(3)=%v", err)
+	}
+	if got := s.Counts(); got[StatusAccepted] != 3 || got[StatusFailed] != 1 {
+		t.Errorf("Counts()=%v, want 3 accepted and 1 failed", got)
+	}
+	s.Close()
+
+	s, err = OpenSubmissionState(path)
+	if err != nil {
+		t.Fatalf("OpenSubmissionState() after resume=%v", err)
+	}
+	defer s.Close()
+	if got, ok := s.Status(3); !ok || got != StatusAccepted {
+		t.Errorf("Status(3)=%v,%v, want %v", got, ok, StatusAccepted)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+)
+
+// Checkpoint records the progress of a scan of a log.
+type Checkpoint struct {
+	// LogURI is the base URI of the log being scanned.
+	LogURI string `json:"log_uri"`
+	// STH is the latest tree head the scan was working towards.
+	STH *ct.SignedTreeHead `json:"sth,omitempty"`
+	// NextIndex is the index of the first entry not yet processed; all the
+	// entries below it have been passed to the scan's callbacks.
+	NextIndex int64 `json:"next_index"`
+}
+
+// CheckpointStore persists Checkpoints, so that an interrupted scan can
+// resume where it left off. Implementations must be safe for concurrent use.
+type CheckpointStore interface {
+	// Load returns the checkpoint for the log with the given URI, or nil if
+	// there is none.
+	Load(ctx context.Context, logURI string) (*Checkpoint, error)
+	// Save stores cp, replacing any checkpoint for the same log.
+	Save(ctx context.Context, cp *Checkpoint) error
+}
+
+// FileCheckpointStore is a CheckpointStore which keeps the checkpoints of
+// all logs in a single JSON file.
+type FileCheckpointStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileCheckpointStore creates a FileCheckpointStore using the file at
+// path, which need not exist yet.
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{path: path}
+}
+
+// read returns the checkpoints in the file, keyed by log URI.
+func (s *FileCheckpointStore) read() (map[string]*Checkpoint, error) {
+	cps := make(map[string]*Checkpoint)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cps, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoints: %v", err)
+	}
+	if err := json.Unmarshal(data, &cps); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoints in %s: %v", s.path, err)
+	}
+	return cps, nil
+}
+
+// Load implements CheckpointStore.
+func (s *FileCheckpointStore) Load(_ context.Context, logURI string) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cps, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return cps[logURI], nil
+}
+
+// Save implements CheckpointStore. The file is replaced atomically, so it
+// is never left partially written.
+func (s *FileCheckpointStore) Save(_ context.Context, cp *Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cps, err := s.read()
+	if err != nil {
+		return err
+	}
+	cps[cp.LogURI] = cp
+	data, err := json.MarshalIndent(cps, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoints: %v", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint file: %v", err)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write checkpoints: %v", err)
+	}
+	return nil
+}
+
+// SQLiteCheckpointStore is a CheckpointStore which keeps checkpoints in a
+// table of an SQLite database. The caller is responsible for loading the
+// database driver.
+type SQLiteCheckpointStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteCheckpointStore creates a SQLiteCheckpointStore using db,
+// creating its table if needed.
+func NewSQLiteCheckpointStore(ctx context.Context, db *sql.DB) (*SQLiteCheckpointStore, error) {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS scan_checkpoints (logURI TEXT PRIMARY KEY, sth BLOB, nextIndex INTEGER)`); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint table: %v", err)
+	}
+	return &SQLiteCheckpointStore{db: db}, nil
+}
+
+// Load implements CheckpointStore.
+func (s *SQLiteCheckpointStore) Load(ctx context.Context, logURI string) (*Checkpoint, error) {
+	cp := Checkpoint{LogURI: logURI}
+	var sthRaw []byte
+	err := s.db.QueryRowContext(ctx, `SELECT sth, nextIndex FROM scan_checkpoints WHERE logURI = ?`, logURI).Scan(&sthRaw, &cp.NextIndex)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+	if len(sthRaw) > 0 {
+		if err := json.Unmarshal(sthRaw, &cp.STH); err != nil {
+			return nil, fmt.Errorf("failed to parse checkpoint STH: %v", err)
+		}
+	}
+	return &cp, nil
+}
+
+// Save implements CheckpointStore.
+func (s *SQLiteCheckpointStore) Save(ctx context.Context, cp *Checkpoint) error {
+	var sthRaw []byte
+	if cp.STH != nil {
+		var err error
+		if sthRaw, err = json.Marshal(cp.STH); err != nil {
+			return fmt.Errorf("failed to marshal checkpoint STH: %v", err)
+		}
+	}
+	if _, err := s.db.ExecContext(ctx, `INSERT OR REPLACE INTO scan_checkpoints (logURI, sth, nextIndex) VALUES (?, ?, ?)`, cp.LogURI, sthRaw, cp.NextIndex); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	return nil
+}
+
+// progress tracks which batches of a scan have been fully processed, and
+// saves a checkpoint each time the processed prefix of the log grows.
+type progress struct {
+	store CheckpointStore
+	uri   string
+	sth   func() *ct.SignedTreeHead
+
+	mu   sync.Mutex
+	next int64
+	done map[int64]int64 // End of each processed batch, keyed by its start.
+}
+
+// pendingBatch counts the entries of a batch which are still being processed.
+type pendingBatch struct {
+	start, end int64
+	remaining  int64
+}
+
+// complete records that the entries in [start, end) have been processed.
+// Checkpoints are saved with a background context, so that the progress made
+// before a scan is cancelled is kept.
+func (p *progress) complete(start, end int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done[start] = end
+	advanced := false
+	for batchEnd, ok := p.done[p.next]; ok; batchEnd, ok = p.done[p.next] {
+		delete(p.done, p.next)
+		p.next = batchEnd
+		advanced = true
+	}
+	if !advanced {
+		return nil
+	}
+	return p.store.Save(context.Background(), &Checkpoint{LogURI: p.uri, STH: p.sth(), NextIndex: p.next})
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	_ "github.com/mattn/go-sqlite3" // Load drivers for sqlite3
+)
+
+// serveFourEntries serves the entries of FourEntries in the ranges requested.
+func serveFourEntries(t *testing.T) *httptest.Server {
+	t.Helper()
+	var all ct.GetEntriesResponse
+	if err := json.Unmarshal([]byte(FourEntries), &all); err != nil {
+		t.Fatalf("Failed to parse test entries: %v", err)
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ct/v1/get-sth":
+			w.Write([]byte(FourEntrySTH))
+		case "/ct/v1/get-entries":
+			start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+			end, _ := strconv.Atoi(r.URL.Query().Get("end"))
+			if end >= len(all.Entries) {
+				end = len(all.Entries) - 1
+			}
+			json.NewEncoder(w).Encode(ct.GetEntriesResponse{Entries: all.Entries[start : end+1]})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func testCheckpointStore(t *testing.T, store CheckpointStore) {
+	t.Helper()
+	ctx := context.Background()
+	if cp, err := store.Load(ctx, "https://log.example.com/"); cp != nil || err != nil {
+		t.Fatalf("Load() of missing checkpoint=%+v, %v; want nil, nil", cp, err)
+	}
+	for _, want := range []*Checkpoint{
+		{LogURI: "https://log.example.com/", NextIndex: 10},
+		{LogURI: "https://other.example.com/", NextIndex: 3},
+		{LogURI: "https://log.example.com/", STH: &ct.SignedTreeHead{TreeSize: 20}, NextIndex: 20},
+	} {
+		if err := store.Save(ctx, want); err != nil {
+			t.Fatalf("Save()=%v", err)
+		}
+		got, err := store.Load(ctx, want.LogURI)
+		if err != nil {
+			t.Fatalf("Load()=%v", err)
+		}
+		if got.LogURI != want.LogURI || got.NextIndex != want.NextIndex || (got.STH == nil) != (want.STH == nil) || got.STH != nil && got.STH.TreeSize != want.STH.TreeSize {
+			t.Errorf("Load()=%+v, want %+v", got, want)
+		}
+	}
+	if got, err := store.Load(ctx, "https://other.example.com/"); err != nil || got.NextIndex != 3 {
+		t.Errorf("Load() of other log=%+v, %v; want next index 3", got, err)
+	}
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	testCheckpointStore(t, NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoints.json")))
+}
+
+func TestSQLiteCheckpointStore(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	store, err := NewSQLiteCheckpointStore(context.Background(), db)
+	if err != nil {
+		t.Fatalf("NewSQLiteCheckpointStore()=%v", err)
+	}
+	testCheckpointStore(t, store)
+}
+
+func TestProgressOutOfOrder(t *testing.T) {
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoints.json"))
+	p := &progress{store: store, uri: "uri", sth: func() *ct.SignedTreeHead { return nil }, next: 0, done: make(map[int64]int64)}
+	for _, b := range []struct {
+		start, end int64
+		wantNext   int64
+	}{
+		{start: 5, end: 10, wantNext: -1},
+		{start: 0, end: 3, wantNext: 3},
+		{start: 3, end: 5, wantNext: 10},
+	} {
+		if err := p.complete(b.start, b.end); err != nil {
+			t.Fatalf("complete(%d, %d)=%v", b.start, b.end, err)
+		}
+		cp, err := store.Load(context.Background(), "uri")
+		if err != nil {
+			t.Fatalf("Load()=%v", err)
+		}
+		if got := int64(-1); cp != nil {
+			got = cp.NextIndex
+			if got != b.wantNext {
+				t.Errorf("after complete(%d, %d) next index=%d, want %d", b.start, b.end, got, b.wantNext)
+			}
+		} else if b.wantNext != -1 {
+			t.Errorf("after complete(%d, %d) no checkpoint, want next index %d", b.start, b.end, b.wantNext)
+		}
+	}
+}
+
+func TestScannerResume(t *testing.T) {
+	ts := serveFourEntries(t)
+	defer ts.Close()
+	logClient, err := client.New(ts.URL, &http.Client{}, jsonclient.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoints.json"))
+
+	for _, test := range []struct {
+		endIndex    int64
+		wantIndices []int64
+	}{
+		{endIndex: 2, wantIndices: []int64{0, 1}},
+		{endIndex: 0, wantIndices: []int64{2, 3}},
+		{endIndex: 0, wantIndices: nil},
+	} {
+		opts := ScannerOptions{
+			FetcherOptions: FetcherOptions{BatchSize: 1, ParallelFetch: 2, EndIndex: test.endIndex},
+			Matcher:        &MatchAll{},
+			NumWorkers:     2,
+			Checkpoints:    store,
+		}
+		var mu sync.Mutex
+		seen := make(map[int64]bool)
+		found := func(e *ct.RawLogEntry) {
+			mu.Lock()
+			defer mu.Unlock()
+			seen[e.Index] = true
+		}
+		if err := NewScanner(logClient, opts).Scan(context.Background(), found, found); err != nil {
+			t.Fatalf("Scan()=%v", err)
+		}
+		if len(seen) != len(test.wantIndices) {
+			t.Errorf("Scan() found entries %v, want %v", seen, test.wantIndices)
+		}
+		for _, idx := range test.wantIndices {
+			if !seen[idx] {
+				t.Errorf("Scan() did not find entry %d", idx)
+			}
+		}
+	}
+	cp, err := store.Load(context.Background(), logClient.BaseURI())
+	if err != nil {
+		t.Fatalf("Load()=%v", err)
+	}
+	if cp.NextIndex != 4 || cp.STH == nil || cp.STH.TreeSize != 4 {
+		t.Errorf("Load()=%+v, want next index 4 of tree size 4", cp)
+	}
+}
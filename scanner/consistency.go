@@ -0,0 +1,101 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+// ConsistencyClient is implemented by LogClients which can fetch consistency
+// proofs, as needed by a Fetcher which checks STH consistency.
+type ConsistencyClient interface {
+	GetSTHConsistency(ctx context.Context, first, second uint64) ([][]byte, error)
+}
+
+// InconsistentSTHError reports two STHs of a Log which are not consistent
+// with each other, i.e. the Log has presented a split view or rewritten its
+// history.
+type InconsistentSTHError struct {
+	URI string
+	Old *ct.SignedTreeHead
+	New *ct.SignedTreeHead
+	Err error
+}
+
+func (e *InconsistentSTHError) Error() string {
+	return fmt.Sprintf("%s: STH with size %d and root %x is inconsistent with STH with size %d and root %x: %v",
+		e.URI, e.New.TreeSize, e.New.SHA256RootHash[:], e.Old.TreeSize, e.Old.SHA256RootHash[:], e.Err)
+}
+
+func (e *InconsistentSTHError) Unwrap() error {
+	return e.Err
+}
+
+// sthChecker verifies that each STH of a Log is consistent with the largest
+// one verified before it, starting from the STH the scan started from.
+type sthChecker struct {
+	uri    string
+	client ConsistencyClient
+
+	mu   sync.Mutex
+	last *ct.SignedTreeHead // Largest STH verified so far.
+}
+
+func newSTHChecker(uri string, client ConsistencyClient, start *ct.SignedTreeHead) *sthChecker {
+	return &sthChecker{uri: uri, client: client, last: start}
+}
+
+// check verifies that sth is consistent with the STHs seen before. It returns
+// an *InconsistentSTHError if it is not, or another error if the check could
+// not be made.
+func (c *sthChecker) check(ctx context.Context, sth *ct.SignedTreeHead) error {
+	// Checks are serialized, so that each one starts from the latest STH.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	older, newer := c.last, sth
+	if older.TreeSize > newer.TreeSize {
+		older, newer = newer, older
+	}
+	inconsistent := func(err error) error {
+		return &InconsistentSTHError{URI: c.uri, Old: c.last, New: sth, Err: err}
+	}
+	switch {
+	case older.TreeSize == newer.TreeSize:
+		if !bytes.Equal(older.SHA256RootHash[:], newer.SHA256RootHash[:]) {
+			return inconsistent(errors.New("different root hashes for the same tree size"))
+		}
+	case older.TreeSize > 0:
+		pf, err := c.client.GetSTHConsistency(ctx, older.TreeSize, newer.TreeSize)
+		if err != nil {
+			return fmt.Errorf("failed to get consistency proof from %d to %d: %v", older.TreeSize, newer.TreeSize, err)
+		}
+		if err := proof.VerifyConsistency(rfc6962.DefaultHasher, older.TreeSize, newer.TreeSize, pf, older.SHA256RootHash[:], newer.SHA256RootHash[:]); err != nil {
+			return inconsistent(err)
+		}
+	}
+	if sth.TreeSize > c.last.TreeSize {
+		c.last = sth
+	}
+	return nil
+}
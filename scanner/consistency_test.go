@@ -0,0 +1,213 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"github.com/transparency-dev/merkle/testonly"
+)
+
+// treeClient is a LogClient for a log whose tree is held in memory.
+type treeClient struct {
+	mu   sync.Mutex
+	tree *testonly.Tree
+	// leaves are the entries served by GetRawEntries, which are those of
+	// the tree unless the test changes them.
+	leaves [][]byte
+	// sths to return from successive calls to GetSTH, after which the STH of
+	// tree is returned.
+	sths []*ct.SignedTreeHead
+	// proofErr, if set, is returned by GetSTHConsistency and GetProofByHash.
+	proofErr error
+}
+
+func newTreeClient(size int, prefix string) *treeClient {
+	c := &treeClient{tree: testonly.New(rfc6962.DefaultHasher)}
+	for i := 0; i < size; i++ {
+		leaf := []byte(fmt.Sprintf("%s%d", prefix, i))
+		c.tree.AppendData(leaf)
+		c.leaves = append(c.leaves, leaf)
+	}
+	return c
+}
+
+func (c *treeClient) sth() *ct.SignedTreeHead {
+	sth := &ct.SignedTreeHead{TreeSize: c.tree.Size()}
+	copy(sth.SHA256RootHash[:], c.tree.Hash())
+	return sth
+}
+
+func (c *treeClient) BaseURI() string { return "tree" }
+
+func (c *treeClient) GetSTH(context.Context) (*ct.SignedTreeHead, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.sths) > 0 {
+		sth := c.sths[0]
+		c.sths = c.sths[1:]
+		return sth, nil
+	}
+	return c.sth(), nil
+}
+
+func (c *treeClient) GetSTHConsistency(_ context.Context, first, second uint64) ([][]byte, error) {
+	if c.proofErr != nil {
+		return nil, c.proofErr
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.ConsistencyProof(first, second)
+}
+
+func (c *treeClient) GetProofByHash(_ context.Context, hash []byte, treeSize uint64) (*ct.GetProofByHashResponse, error) {
+	if c.proofErr != nil {
+		return nil, c.proofErr
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := uint64(0); i < treeSize; i++ {
+		if bytes.Equal(c.tree.LeafHash(i), hash) {
+			pf, err := c.tree.InclusionProof(i, treeSize)
+			if err != nil {
+				return nil, err
+			}
+			return &ct.GetProofByHashResponse{LeafIndex: int64(i), AuditPath: pf}, nil
+		}
+	}
+	return nil, jsonclient.RspError{StatusCode: http.StatusBadRequest, Err: errors.New("hash not found")}
+}
+
+func (c *treeClient) GetRawEntries(_ context.Context, start, end int64) (*ct.GetEntriesResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var rsp ct.GetEntriesResponse
+	for i := start; i <= end && i < int64(len(c.leaves)); i++ {
+		rsp.Entries = append(rsp.Entries, ct.LeafEntry{LeafInput: c.leaves[i]})
+	}
+	return &rsp, nil
+}
+
+func TestSTHChecker(t *testing.T) {
+	ctx := context.Background()
+	log := newTreeClient(10, "leaf")
+	start := log.sth()
+	for i := 10; i < 17; i++ {
+		log.tree.AppendData([]byte(fmt.Sprintf("leaf%d", i)))
+	}
+	grown := log.sth()
+	fork := newTreeClient(17, "fork").sth()
+	empty := newTreeClient(0, "").sth()
+
+	for _, test := range []struct {
+		desc             string
+		sths             []*ct.SignedTreeHead
+		proofErr         error
+		wantErr          bool
+		wantInconsistent bool
+	}{
+		{desc: "same", sths: []*ct.SignedTreeHead{start}},
+		{desc: "grown", sths: []*ct.SignedTreeHead{grown, grown}},
+		{desc: "older", sths: []*ct.SignedTreeHead{grown, start}},
+		{desc: "from-empty", sths: []*ct.SignedTreeHead{empty}},
+		{desc: "fork", sths: []*ct.SignedTreeHead{fork}, wantErr: true, wantInconsistent: true},
+		{desc: "fork-after-grown", sths: []*ct.SignedTreeHead{grown, fork}, wantErr: true, wantInconsistent: true},
+		{desc: "fork-same-size", sths: []*ct.SignedTreeHead{newTreeClient(10, "fork").sth()}, wantErr: true, wantInconsistent: true},
+		{desc: "proof-error", sths: []*ct.SignedTreeHead{grown}, proofErr: errors.New("unavailable"), wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			log.proofErr = test.proofErr
+			c := newSTHChecker("tree", log, start)
+			var err error
+			for _, sth := range test.sths {
+				if err = c.check(ctx, sth); err != nil {
+					break
+				}
+			}
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("check()=%v, want err? %v", err, test.wantErr)
+			}
+			var inconsistent *InconsistentSTHError
+			if got := errors.As(err, &inconsistent); got != test.wantInconsistent {
+				t.Errorf("check()=%v, want InconsistentSTHError? %v", err, test.wantInconsistent)
+			}
+		})
+	}
+}
+
+func TestFetcherInconsistentSTH(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	log := newTreeClient(4, "leaf")
+	// Start from the real STH, then see a forked tree with more entries.
+	log.sths = []*ct.SignedTreeHead{log.sth()}
+	log.tree = newTreeClient(8, "fork").tree
+
+	opts := DefaultFetcherOptions()
+	opts.BatchSize = 2
+	opts.Continuous = true
+	opts.STHCheckInterval = time.Hour
+	f := NewFetcher(log, opts)
+	err := f.Run(ctx, func(EntryBatch) {})
+	var inconsistent *InconsistentSTHError
+	if !errors.As(err, &inconsistent) {
+		t.Fatalf("Run()=%v, want InconsistentSTHError", err)
+	}
+	if got, want := inconsistent.New.TreeSize, uint64(8); got != want {
+		t.Errorf("Run() reported inconsistent STH of size %d, want %d", got, want)
+	}
+	if got, want := f.StartSTH().TreeSize, uint64(4); got != want {
+		t.Errorf("StartSTH().TreeSize=%d, want %d", got, want)
+	}
+}
+
+func TestFetcherCheckSTHPeriodically(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	log := newTreeClient(4, "leaf")
+	log.sths = []*ct.SignedTreeHead{log.sth()}
+	log.tree = newTreeClient(6, "fork").tree
+
+	opts := DefaultFetcherOptions()
+	opts.BatchSize = 1
+	opts.STHCheckInterval = time.Millisecond
+	f := NewFetcher(log, opts)
+	// Hold up fetching until the forked STH has been detected.
+	err := f.Run(ctx, func(EntryBatch) {
+		for {
+			f.mu.Lock()
+			failed := f.failure != nil
+			f.mu.Unlock()
+			if failed {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+	var inconsistent *InconsistentSTHError
+	if !errors.As(err, &inconsistent) {
+		t.Fatalf("Run()=%v, want InconsistentSTHError", err)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"k8s.io/klog/v2"
+)
+
+// PrecertRef identifies a precertificate entry in a log.
+type PrecertRef struct {
+	LogURI string
+	Index  int64
+}
+
+// Deduplicator suppresses the duplicate results of a scan for a
+// precertificate and the final certificate issued from it, which share a
+// TBSCertificate once the poison and SCT list extensions are removed. Only
+// the first of each pair to be matched is passed on. It also tracks the
+// precertificates for which no final certificate was matched.
+//
+// A Deduplicator may be shared by several scans, e.g. of all the logs of a
+// MultiScanner, and holds a hash for each matched entry.
+type Deduplicator struct {
+	mu         sync.Mutex
+	seen       map[[sha256.Size]byte]*dedupState
+	duplicates int64
+}
+
+// dedupState records which of a pair of entries have been matched.
+type dedupState struct {
+	cert    bool
+	precert *PrecertRef
+}
+
+// NewDeduplicator creates an empty Deduplicator.
+func NewDeduplicator() *Deduplicator {
+	return &Deduplicator{seen: make(map[[sha256.Size]byte]*dedupState)}
+}
+
+// tbsHash returns the hash of the TBSCertificate of entry, without the
+// poison and SCT list extensions.
+func tbsHash(entry *ct.RawLogEntry) ([sha256.Size]byte, error) {
+	switch eType := entry.Leaf.TimestampedEntry.EntryType; eType {
+	case ct.PrecertLogEntryType:
+		// The log has already removed the poison extension.
+		return sha256.Sum256(entry.Leaf.TimestampedEntry.PrecertEntry.TBSCertificate), nil
+	case ct.X509LogEntryType:
+		cert, err := x509.ParseCertificate(entry.Cert.Data)
+		if x509.IsFatal(err) {
+			return [sha256.Size]byte{}, fmt.Errorf("failed to parse certificate: %v", err)
+		}
+		tbs, err := x509.RemoveSCTList(cert.RawTBSCertificate)
+		if err != nil {
+			return [sha256.Size]byte{}, fmt.Errorf("failed to remove SCT list: %v", err)
+		}
+		return sha256.Sum256(tbs), nil
+	default:
+		return [sha256.Size]byte{}, fmt.Errorf("unknown entry type: %v", eType)
+	}
+}
+
+// wrap returns callbacks which pass each entry matched in the log at logURI
+// on to foundCert or foundPrecert, unless it is a duplicate.
+func (d *Deduplicator) wrap(logURI string, foundCert, foundPrecert func(*ct.RawLogEntry)) (func(*ct.RawLogEntry), func(*ct.RawLogEntry)) {
+	filter := func(found func(*ct.RawLogEntry)) func(*ct.RawLogEntry) {
+		return func(entry *ct.RawLogEntry) {
+			hash, err := tbsHash(entry)
+			if err != nil {
+				klog.V(1).Infof("%s: Cannot deduplicate entry %d: %v", logURI, entry.Index, err)
+				found(entry)
+				return
+			}
+			if d.record(hash, logURI, entry) {
+				found(entry)
+			}
+		}
+	}
+	return filter(foundCert), filter(foundPrecert)
+}
+
+// record notes that entry has been matched, returning whether it is the
+// first of its pair to be.
+func (d *Deduplicator) record(hash [sha256.Size]byte, logURI string, entry *ct.RawLogEntry) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	state, ok := d.seen[hash]
+	if !ok {
+		state = &dedupState{}
+		d.seen[hash] = state
+	}
+	if entry.Leaf.TimestampedEntry.EntryType == ct.PrecertLogEntryType {
+		if state.precert == nil {
+			state.precert = &PrecertRef{LogURI: logURI, Index: entry.Index}
+		}
+	} else {
+		state.cert = true
+	}
+	if ok {
+		d.duplicates++
+	}
+	return !ok
+}
+
+// Duplicates returns the number of entries suppressed so far. Entries
+// logged in more than one log are counted each time they are seen again.
+func (d *Deduplicator) Duplicates() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.duplicates
+}
+
+// UnmatchedPrecerts returns the precertificates seen for which no final
+// certificate has been matched, ordered by log and index.
+func (d *Deduplicator) UnmatchedPrecerts() []PrecertRef {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var refs []PrecertRef
+	for _, state := range d.seen {
+		if state.precert != nil && !state.cert {
+			refs = append(refs, *state.precert)
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].LogURI != refs[j].LogURI {
+			return refs[i].LogURI < refs[j].LogURI
+		}
+		return refs[i].Index < refs[j].Index
+	})
+	return refs
+}
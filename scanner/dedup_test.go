@@ -0,0 +1,96 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+)
+
+// certAndPrecertEntries returns log entries for a final certificate with an
+// SCT list, and for the precertificate it was issued from.
+func certAndPrecertEntries(t *testing.T, serial int64) (*ct.RawLogEntry, *ct.RawLogEntry) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey()=%v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "www.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{
+			{Id: x509.OIDExtensionCTSCT, Value: []byte{0x04, 0x02, 0x00, 0x00}},
+		},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate()=%v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if x509.IsFatal(err) {
+		t.Fatalf("ParseCertificate()=%v", err)
+	}
+	tbs, err := x509.RemoveSCTList(cert.RawTBSCertificate)
+	if err != nil {
+		t.Fatalf("RemoveSCTList()=%v", err)
+	}
+	certEntry := &ct.RawLogEntry{
+		Leaf: ct.MerkleTreeLeaf{TimestampedEntry: &ct.TimestampedEntry{EntryType: ct.X509LogEntryType}},
+		Cert: ct.ASN1Cert{Data: der},
+	}
+	precertEntry := &ct.RawLogEntry{
+		Leaf: ct.MerkleTreeLeaf{TimestampedEntry: &ct.TimestampedEntry{
+			EntryType:    ct.PrecertLogEntryType,
+			PrecertEntry: &ct.PreCert{TBSCertificate: tbs},
+		}},
+	}
+	return certEntry, precertEntry
+}
+
+func TestDeduplicator(t *testing.T) {
+	cert1, precert1 := certAndPrecertEntries(t, 1)
+	_, precert2 := certAndPrecertEntries(t, 2)
+	cert1.Index, precert1.Index, precert2.Index = 5, 3, 4
+
+	d := NewDeduplicator()
+	var found []int64
+	record := func(e *ct.RawLogEntry) { found = append(found, e.Index) }
+	foundCert, foundPrecert := d.wrap("log", record, record)
+	foundPrecert(precert1)
+	foundPrecert(precert2)
+	foundCert(cert1)
+	foundPrecert(precert1) // Logged again.
+
+	if want := []int64{3, 4}; !reflect.DeepEqual(found, want) {
+		t.Errorf("Passed on entries %v, want %v", found, want)
+	}
+	if got := d.Duplicates(); got != 2 {
+		t.Errorf("Duplicates()=%d, want 2", got)
+	}
+	if got, want := d.UnmatchedPrecerts(), []PrecertRef{{LogURI: "log", Index: 4}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmatchedPrecerts()=%v, want %v", got, want)
+	}
+}
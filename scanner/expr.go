@@ -0,0 +1,760 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+)
+
+// ExpressionMatcher is a Matcher which evaluates an expression over the
+// fields of each [pre-]certificate, in a subset of the syntax of the Common
+// Expression Language (CEL). For example:
+//
+//	issuer.o.exists(o, o == "Let's Encrypt") && key_type == "RSA" && key_bits < 2048
+//	dns_names.exists(n, n.endsWith(".example.com")) && !is_precert
+//
+// The fields available are:
+//
+//	subject, issuer          names, with fields cn and dn (strings) and
+//	                         o, ou and c (lists of strings)
+//	dns_names, emails,
+//	ip_addresses, uris       lists of strings
+//	serial                   string, in lower-case hex
+//	not_before, not_after    timestamps
+//	validity_days            int
+//	key_type                 string: "RSA", "ECDSA", "Ed25519", ...
+//	key_bits                 int
+//	signature_algorithm      string, e.g. "SHA256-RSA"
+//	is_ca, is_precert        bools
+//	extensions, policies     lists of OIDs, as dotted strings
+//
+// Expressions may use literals (strings, ints, true and false, and lists),
+// the operators !, &&, ||, ==, !=, <, <=, >, >= and in, the functions
+// size(string or list) and timestamp(RFC 3339 string), the string methods
+// contains, startsWith, endsWith and matches (an RE2 regular expression),
+// and the list macros exists(var, predicate) and all(var, predicate).
+type ExpressionMatcher struct {
+	expr string
+	eval evalFunc
+}
+
+// NewExpressionMatcher compiles expr into an ExpressionMatcher, checking
+// that it is well-typed and evaluates to a bool.
+func NewExpressionMatcher(expr string) (*ExpressionMatcher, error) {
+	p := &exprParser{lex: exprLexer{src: expr}}
+	p.next()
+	n, err := p.parseExpr()
+	if err == nil && p.tok.kind != tokEOF {
+		err = p.errorf("unexpected %q", p.tok.text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse expression: %v", err)
+	}
+	c := &exprCompiler{}
+	eval, typ, err := c.compile(n)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %v", err)
+	}
+	if typ != typeBool {
+		return nil, fmt.Errorf("invalid expression: has type %s, want bool", typ)
+	}
+	return &ExpressionMatcher{expr: expr, eval: eval}, nil
+}
+
+// String returns the source of the expression.
+func (m *ExpressionMatcher) String() string {
+	return m.expr
+}
+
+// CertificateMatches returns true if the expression is true for the
+// certificate.
+func (m *ExpressionMatcher) CertificateMatches(c *x509.Certificate) bool {
+	return m.matches(c, false)
+}
+
+// PrecertificateMatches returns true if the expression is true for the
+// precertificate.
+func (m *ExpressionMatcher) PrecertificateMatches(p *ct.Precertificate) bool {
+	if p.TBSCertificate == nil {
+		return false
+	}
+	return m.matches(p.TBSCertificate, true)
+}
+
+func (m *ExpressionMatcher) matches(c *x509.Certificate, precert bool) bool {
+	env := &exprEnv{cert: c, precert: precert}
+	match, _ := m.eval(env).(bool)
+	return match
+}
+
+// Types of the values of expressions.
+type exprType int
+
+const (
+	typeBool exprType = iota
+	typeInt
+	typeString
+	typeTime
+	typeList // Of strings.
+	typeName
+)
+
+func (t exprType) String() string {
+	return [...]string{"bool", "int", "string", "timestamp", "list", "name"}[t]
+}
+
+// exprEnv holds the state of one evaluation of an expression.
+type exprEnv struct {
+	cert    *x509.Certificate
+	precert bool
+	vars    []string // Values of the variables bound by macros.
+}
+
+type evalFunc func(env *exprEnv) interface{}
+
+// exprFields maps the names of certificate fields to their types and getters.
+var exprFields = map[string]struct {
+	typ exprType
+	get func(env *exprEnv) interface{}
+}{
+	"subject":   {typeName, func(env *exprEnv) interface{} { return &env.cert.Subject }},
+	"issuer":    {typeName, func(env *exprEnv) interface{} { return &env.cert.Issuer }},
+	"dns_names": {typeList, func(env *exprEnv) interface{} { return env.cert.DNSNames }},
+	"emails":    {typeList, func(env *exprEnv) interface{} { return env.cert.EmailAddresses }},
+	"ip_addresses": {typeList, func(env *exprEnv) interface{} {
+		ips := make([]string, 0, len(env.cert.IPAddresses))
+		for _, ip := range env.cert.IPAddresses {
+			ips = append(ips, ip.String())
+		}
+		return ips
+	}},
+	"uris": {typeList, func(env *exprEnv) interface{} {
+		uris := make([]string, 0, len(env.cert.URIs))
+		for _, u := range env.cert.URIs {
+			uris = append(uris, u.String())
+		}
+		return uris
+	}},
+	"serial": {typeString, func(env *exprEnv) interface{} {
+		if env.cert.SerialNumber == nil {
+			return ""
+		}
+		return env.cert.SerialNumber.Text(16)
+	}},
+	"not_before": {typeTime, func(env *exprEnv) interface{} { return env.cert.NotBefore }},
+	"not_after":  {typeTime, func(env *exprEnv) interface{} { return env.cert.NotAfter }},
+	"validity_days": {typeInt, func(env *exprEnv) interface{} {
+		return int64(env.cert.NotAfter.Sub(env.cert.NotBefore) / (24 * time.Hour))
+	}},
+	"key_type": {typeString, func(env *exprEnv) interface{} { return env.cert.PublicKeyAlgorithm.String() }},
+	"key_bits": {typeInt, func(env *exprEnv) interface{} {
+		switch key := env.cert.PublicKey.(type) {
+		case *rsa.PublicKey:
+			return int64(key.N.BitLen())
+		case *ecdsa.PublicKey:
+			return int64(key.Curve.Params().BitSize)
+		case ed25519.PublicKey:
+			return int64(256)
+		}
+		return int64(0)
+	}},
+	"signature_algorithm": {typeString, func(env *exprEnv) interface{} { return env.cert.SignatureAlgorithm.String() }},
+	"is_ca":               {typeBool, func(env *exprEnv) interface{} { return env.cert.IsCA }},
+	"is_precert":          {typeBool, func(env *exprEnv) interface{} { return env.precert }},
+	"extensions": {typeList, func(env *exprEnv) interface{} {
+		oids := make([]string, 0, len(env.cert.Extensions))
+		for _, ext := range env.cert.Extensions {
+			oids = append(oids, ext.Id.String())
+		}
+		return oids
+	}},
+	"policies": {typeList, func(env *exprEnv) interface{} {
+		oids := make([]string, 0, len(env.cert.PolicyIdentifiers))
+		for _, oid := range env.cert.PolicyIdentifiers {
+			oids = append(oids, oid.String())
+		}
+		return oids
+	}},
+}
+
+// nameFields maps the names of the fields of names to their types and
+// getters.
+var nameFields = map[string]struct {
+	typ exprType
+	get func(n *pkix.Name) interface{}
+}{
+	"cn": {typeString, func(n *pkix.Name) interface{} { return n.CommonName }},
+	"dn": {typeString, func(n *pkix.Name) interface{} { return n.String() }},
+	"o":  {typeList, func(n *pkix.Name) interface{} { return n.Organization }},
+	"ou": {typeList, func(n *pkix.Name) interface{} { return n.OrganizationalUnit }},
+	"c":  {typeList, func(n *pkix.Name) interface{} { return n.Country }},
+}
+
+// Lexer.
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokInt
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string // For strings, the unquoted value.
+	pos  int
+}
+
+type exprLexer struct {
+	src string
+	pos int
+}
+
+func (l *exprLexer) next() (token, error) {
+	for l.pos < len(l.src) && unicode.IsSpace(rune(l.src[l.pos])) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+	c := l.src[l.pos]
+	switch {
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || unicode.IsLetter(rune(l.src[l.pos])) || unicode.IsDigit(rune(l.src[l.pos]))) {
+			l.pos++
+		}
+		return token{kind: tokIdent, text: l.src[start:l.pos], pos: start}, nil
+	case unicode.IsDigit(rune(c)):
+		for l.pos < len(l.src) && unicode.IsDigit(rune(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokInt, text: l.src[start:l.pos], pos: start}, nil
+	case c == '"' || c == '\'':
+		var sb strings.Builder
+		for l.pos++; l.pos < len(l.src); l.pos++ {
+			switch l.src[l.pos] {
+			case c:
+				l.pos++
+				return token{kind: tokString, text: sb.String(), pos: start}, nil
+			case '\\':
+				if l.pos++; l.pos < len(l.src) {
+					sb.WriteByte(l.src[l.pos])
+				}
+			default:
+				sb.WriteByte(l.src[l.pos])
+			}
+		}
+		return token{}, fmt.Errorf("unterminated string at offset %d", start)
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "[", "]", ",", "."} {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			return token{kind: tokPunct, text: op, pos: start}, nil
+		}
+	}
+	return token{}, fmt.Errorf("unexpected character %q at offset %d", c, start)
+}
+
+// Parser.
+
+// exprNode is a node of the syntax tree of an expression.
+type exprNode struct {
+	op   string // Operator, or "lit", "ident", "list", "call" or "member".
+	name string // Identifier, function, method or field name.
+	lit  interface{}
+	args []*exprNode // Operands; for methods and members the receiver is first.
+	pos  int
+}
+
+type exprParser struct {
+	lex exprLexer
+	tok token
+	err error
+}
+
+func (p *exprParser) next() {
+	if p.err != nil {
+		return
+	}
+	p.tok, p.err = p.lex.next()
+	if p.err != nil {
+		p.tok = token{kind: tokEOF}
+	}
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	if p.err != nil {
+		return p.err
+	}
+	return fmt.Errorf("at offset %d: %s", p.tok.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *exprParser) accept(punct string) bool {
+	if p.tok.kind == tokPunct && p.tok.text == punct {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(punct string) error {
+	if !p.accept(punct) {
+		return p.errorf("expected %q", punct)
+	}
+	return nil
+}
+
+func (p *exprParser) parseExpr() (*exprNode, error) {
+	return p.parseBinary(0)
+}
+
+// binaryOps lists the binary operators by increasing precedence.
+var binaryOps = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">=", "in"},
+}
+
+func (p *exprParser) parseBinary(level int) (*exprNode, error) {
+	if level == len(binaryOps) {
+		return p.parseUnary()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, pos := "", p.tok.pos
+		for _, o := range binaryOps[level] {
+			if (p.tok.kind == tokPunct || p.tok.kind == tokIdent) && p.tok.text == o {
+				op = o
+			}
+		}
+		if op == "" {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &exprNode{op: op, args: []*exprNode{left, right}, pos: pos}
+		if level == len(binaryOps)-1 {
+			// Comparisons do not chain.
+			return left, nil
+		}
+	}
+}
+
+func (p *exprParser) parseUnary() (*exprNode, error) {
+	if pos := p.tok.pos; p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &exprNode{op: "!", args: []*exprNode{operand}, pos: pos}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *exprParser) parsePostfix() (*exprNode, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(".") {
+		if p.tok.kind != tokIdent {
+			return nil, p.errorf("expected field or method name")
+		}
+		name, pos := p.tok.text, p.tok.pos
+		p.next()
+		if p.tok.kind == tokPunct && p.tok.text == "(" {
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			n = &exprNode{op: "call", name: name, args: append([]*exprNode{n}, args...), pos: pos}
+		} else {
+			n = &exprNode{op: "member", name: name, args: []*exprNode{n}, pos: pos}
+		}
+	}
+	return n, p.err
+}
+
+// parseArgs parses a parenthesized list of arguments.
+func (p *exprParser) parseArgs() ([]*exprNode, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []*exprNode
+	if p.accept(")") {
+		return args, nil
+	}
+	for {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.accept(")") {
+			return args, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *exprParser) parsePrimary() (*exprNode, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokString:
+		p.next()
+		return &exprNode{op: "lit", lit: tok.text, pos: tok.pos}, nil
+	case tokInt:
+		p.next()
+		v, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("at offset %d: invalid int %s", tok.pos, tok.text)
+		}
+		return &exprNode{op: "lit", lit: v, pos: tok.pos}, nil
+	case tokIdent:
+		p.next()
+		switch tok.text {
+		case "true", "false":
+			return &exprNode{op: "lit", lit: tok.text == "true", pos: tok.pos}, nil
+		}
+		if p.tok.kind == tokPunct && p.tok.text == "(" {
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			return &exprNode{op: "call", name: tok.text, args: args, pos: tok.pos}, nil
+		}
+		return &exprNode{op: "ident", name: tok.text, pos: tok.pos}, nil
+	case tokPunct:
+		switch {
+		case p.accept("("):
+			n, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case p.accept("["):
+			n := &exprNode{op: "list", pos: tok.pos}
+			for !p.accept("]") {
+				if len(n.args) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				elem, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				n.args = append(n.args, elem)
+			}
+			return n, nil
+		}
+	}
+	if tok.kind == tokEOF {
+		return nil, p.errorf("unexpected end of expression")
+	}
+	return nil, p.errorf("unexpected %q", tok.text)
+}
+
+// Compiler.
+
+type exprCompiler struct {
+	vars []string // Names of the variables bound by enclosing macros.
+}
+
+func nodeErrorf(n *exprNode, format string, args ...interface{}) error {
+	return fmt.Errorf("at offset %d: %s", n.pos, fmt.Sprintf(format, args...))
+}
+
+func (c *exprCompiler) compile(n *exprNode) (evalFunc, exprType, error) {
+	switch n.op {
+	case "lit":
+		v := n.lit
+		eval := func(*exprEnv) interface{} { return v }
+		switch v.(type) {
+		case bool:
+			return eval, typeBool, nil
+		case int64:
+			return eval, typeInt, nil
+		default:
+			return eval, typeString, nil
+		}
+	case "list":
+		var elems []evalFunc
+		for _, arg := range n.args {
+			eval, typ, err := c.compile(arg)
+			if err != nil {
+				return nil, 0, err
+			}
+			if typ != typeString {
+				return nil, 0, nodeErrorf(arg, "list elements must be strings, not %s", typ)
+			}
+			elems = append(elems, eval)
+		}
+		return func(env *exprEnv) interface{} {
+			list := make([]string, len(elems))
+			for i, elem := range elems {
+				list[i] = elem(env).(string)
+			}
+			return list
+		}, typeList, nil
+	case "ident":
+		for i := len(c.vars) - 1; i >= 0; i-- {
+			if c.vars[i] == n.name {
+				return func(env *exprEnv) interface{} { return env.vars[i] }, typeString, nil
+			}
+		}
+		field, ok := exprFields[n.name]
+		if !ok {
+			return nil, 0, nodeErrorf(n, "unknown field %q", n.name)
+		}
+		return field.get, field.typ, nil
+	case "member":
+		recv, typ, err := c.compile(n.args[0])
+		if err != nil {
+			return nil, 0, err
+		}
+		field, ok := nameFields[n.name]
+		if typ != typeName || !ok {
+			return nil, 0, nodeErrorf(n, "%s has no field %q", typ, n.name)
+		}
+		return func(env *exprEnv) interface{} { return field.get(recv(env).(*pkix.Name)) }, field.typ, nil
+	case "call":
+		return c.compileCall(n)
+	case "!":
+		operand, err := c.compileTyped(n.args[0], typeBool)
+		if err != nil {
+			return nil, 0, err
+		}
+		return func(env *exprEnv) interface{} { return !operand(env).(bool) }, typeBool, nil
+	case "&&", "||":
+		left, err := c.compileTyped(n.args[0], typeBool)
+		if err != nil {
+			return nil, 0, err
+		}
+		right, err := c.compileTyped(n.args[1], typeBool)
+		if err != nil {
+			return nil, 0, err
+		}
+		if n.op == "&&" {
+			return func(env *exprEnv) interface{} { return left(env).(bool) && right(env).(bool) }, typeBool, nil
+		}
+		return func(env *exprEnv) interface{} { return left(env).(bool) || right(env).(bool) }, typeBool, nil
+	case "in":
+		elem, err := c.compileTyped(n.args[0], typeString)
+		if err != nil {
+			return nil, 0, err
+		}
+		list, err := c.compileTyped(n.args[1], typeList)
+		if err != nil {
+			return nil, 0, err
+		}
+		return func(env *exprEnv) interface{} {
+			e := elem(env).(string)
+			for _, v := range list(env).([]string) {
+				if v == e {
+					return true
+				}
+			}
+			return false
+		}, typeBool, nil
+	default:
+		return c.compileComparison(n)
+	}
+}
+
+// compileTyped compiles n, checking that it has the type want.
+func (c *exprCompiler) compileTyped(n *exprNode, want exprType) (evalFunc, error) {
+	eval, typ, err := c.compile(n)
+	if err != nil {
+		return nil, err
+	}
+	if typ != want {
+		return nil, nodeErrorf(n, "has type %s, want %s", typ, want)
+	}
+	return eval, nil
+}
+
+func (c *exprCompiler) compileComparison(n *exprNode) (evalFunc, exprType, error) {
+	left, ltyp, err := c.compile(n.args[0])
+	if err != nil {
+		return nil, 0, err
+	}
+	right, rtyp, err := c.compile(n.args[1])
+	if err != nil {
+		return nil, 0, err
+	}
+	if ltyp != rtyp {
+		return nil, 0, nodeErrorf(n, "cannot compare %s with %s", ltyp, rtyp)
+	}
+	if ltyp == typeList || ltyp == typeName || ltyp == typeBool && n.op != "==" && n.op != "!=" {
+		return nil, 0, nodeErrorf(n, "cannot compare %s values with %s", ltyp, n.op)
+	}
+	op := n.op
+	return func(env *exprEnv) interface{} {
+		var cmp int
+		switch l := left(env).(type) {
+		case bool:
+			if r := right(env).(bool); l != r {
+				cmp = 1
+			}
+		case int64:
+			if r := right(env).(int64); l < r {
+				cmp = -1
+			} else if l > r {
+				cmp = 1
+			}
+		case string:
+			cmp = strings.Compare(l, right(env).(string))
+		case time.Time:
+			cmp = l.Compare(right(env).(time.Time))
+		}
+		switch op {
+		case "==":
+			return cmp == 0
+		case "!=":
+			return cmp != 0
+		case "<":
+			return cmp < 0
+		case "<=":
+			return cmp <= 0
+		case ">":
+			return cmp > 0
+		default:
+			return cmp >= 0
+		}
+	}, typeBool, nil
+}
+
+func (c *exprCompiler) compileCall(n *exprNode) (evalFunc, exprType, error) {
+	switch n.name {
+	case "size":
+		if len(n.args) != 1 {
+			return nil, 0, nodeErrorf(n, "size takes one argument")
+		}
+		arg, typ, err := c.compile(n.args[0])
+		if err != nil {
+			return nil, 0, err
+		}
+		switch typ {
+		case typeString:
+			return func(env *exprEnv) interface{} { return int64(len(arg(env).(string))) }, typeInt, nil
+		case typeList:
+			return func(env *exprEnv) interface{} { return int64(len(arg(env).([]string))) }, typeInt, nil
+		}
+		return nil, 0, nodeErrorf(n, "size of %s", typ)
+	case "timestamp":
+		if len(n.args) != 1 || n.args[0].op != "lit" {
+			return nil, 0, nodeErrorf(n, "timestamp takes one string literal")
+		}
+		s, ok := n.args[0].lit.(string)
+		if !ok {
+			return nil, 0, nodeErrorf(n, "timestamp takes one string literal")
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, 0, nodeErrorf(n, "invalid timestamp: %v", err)
+		}
+		return func(*exprEnv) interface{} { return t }, typeTime, nil
+	case "exists", "all":
+		return c.compileMacro(n)
+	case "contains", "startsWith", "endsWith", "matches":
+		return c.compileStringMethod(n)
+	}
+	return nil, 0, nodeErrorf(n, "unknown function %q", n.name)
+}
+
+// compileMacro compiles list.exists(var, predicate) or list.all(var,
+// predicate).
+func (c *exprCompiler) compileMacro(n *exprNode) (evalFunc, exprType, error) {
+	if len(n.args) != 3 || n.args[1].op != "ident" {
+		return nil, 0, nodeErrorf(n, "%s takes a variable name and a predicate", n.name)
+	}
+	list, err := c.compileTyped(n.args[0], typeList)
+	if err != nil {
+		return nil, 0, err
+	}
+	slot := len(c.vars)
+	c.vars = append(c.vars, n.args[1].name)
+	pred, err := c.compileTyped(n.args[2], typeBool)
+	c.vars = c.vars[:slot]
+	if err != nil {
+		return nil, 0, err
+	}
+	want := n.name == "exists"
+	return func(env *exprEnv) interface{} {
+		for _, v := range list(env).([]string) {
+			env.vars = append(env.vars[:slot], v)
+			if pred(env).(bool) == want {
+				return want
+			}
+		}
+		return !want
+	}, typeBool, nil
+}
+
+func (c *exprCompiler) compileStringMethod(n *exprNode) (evalFunc, exprType, error) {
+	if len(n.args) != 2 {
+		return nil, 0, nodeErrorf(n, "%s takes one argument", n.name)
+	}
+	recv, err := c.compileTyped(n.args[0], typeString)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n.name == "matches" {
+		pattern, ok := n.args[1].lit.(string)
+		if n.args[1].op != "lit" || !ok {
+			return nil, 0, nodeErrorf(n, "matches takes a string literal")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, 0, nodeErrorf(n, "invalid regular expression: %v", err)
+		}
+		return func(env *exprEnv) interface{} { return re.MatchString(recv(env).(string)) }, typeBool, nil
+	}
+	arg, err := c.compileTyped(n.args[1], typeString)
+	if err != nil {
+		return nil, 0, err
+	}
+	fn := map[string]func(string, string) bool{
+		"contains":   strings.Contains,
+		"startsWith": strings.HasPrefix,
+		"endsWith":   strings.HasSuffix,
+	}[n.name]
+	return func(env *exprEnv) interface{} { return fn(recv(env).(string), arg(env).(string)) }, typeBool, nil
+}
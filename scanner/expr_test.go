@@ -0,0 +1,110 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+)
+
+func TestExpressionMatcher(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject:            pkix.Name{CommonName: "www.example.com", Organization: []string{"Example Inc"}},
+		Issuer:             pkix.Name{CommonName: "Example CA", Country: []string{"US"}},
+		DNSNames:           []string{"www.example.com", "mail.example.com"},
+		IPAddresses:        []net.IP{net.ParseIP("192.0.2.1")},
+		SerialNumber:       big.NewInt(0xabc),
+		NotBefore:          time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:           time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		PublicKeyAlgorithm: x509.ECDSA,
+		PublicKey:          &ecdsa.PublicKey{Curve: elliptic.P256()},
+		SignatureAlgorithm: x509.ECDSAWithSHA256,
+		PolicyIdentifiers:  []asn1.ObjectIdentifier{{2, 23, 140, 1, 2, 1}},
+	}
+
+	for _, test := range []struct {
+		expr string
+		want bool
+	}{
+		{expr: `subject.cn == "www.example.com"`, want: true},
+		{expr: `subject.cn != 'www.example.com'`, want: false},
+		{expr: `"Example Inc" in subject.o && "US" in issuer.c`, want: true},
+		{expr: `dns_names.exists(n, n.endsWith(".example.com")) && size(dns_names) == 2`, want: true},
+		{expr: `dns_names.all(n, n.startsWith("www."))`, want: false},
+		{expr: `dns_names.exists(n, ["a", "b"].exists(m, n.contains(m)))`, want: true},
+		{expr: `subject.cn.matches("^w+\\.example\\.(com|org)$")`, want: true},
+		{expr: `key_type == "ECDSA" && key_bits >= 256`, want: true},
+		{expr: `key_type == "RSA" || key_bits < 256`, want: false},
+		{expr: `validity_days > 89 && validity_days <= 90`, want: true},
+		{expr: `not_after < timestamp("2025-06-01T00:00:00Z")`, want: true},
+		{expr: `serial == "abc" && "192.0.2.1" in ip_addresses`, want: true},
+		{expr: `"2.23.140.1.2.1" in policies && !is_ca && !is_precert`, want: true},
+		{expr: `signature_algorithm == "ECDSA-SHA256"`, want: true},
+		{expr: `!(size(emails) > 0)`, want: true},
+	} {
+		m, err := NewExpressionMatcher(test.expr)
+		if err != nil {
+			t.Errorf("NewExpressionMatcher(%q)=%v", test.expr, err)
+			continue
+		}
+		if got := m.CertificateMatches(cert); got != test.want {
+			t.Errorf("NewExpressionMatcher(%q).CertificateMatches()=%v, want %v", test.expr, got, test.want)
+		}
+	}
+
+	m, err := NewExpressionMatcher(`is_precert && subject.cn == "www.example.com"`)
+	if err != nil {
+		t.Fatalf("NewExpressionMatcher()=%v", err)
+	}
+	if !m.PrecertificateMatches(&ct.Precertificate{TBSCertificate: cert}) || m.CertificateMatches(cert) {
+		t.Error("is_precert did not distinguish precertificates")
+	}
+}
+
+func TestExpressionMatcherErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`subject.cn ==`,
+		`subject.cn == "unterminated`,
+		`unknown_field == 1`,
+		`subject.unknown == "x"`,
+		`key_bits == "2048"`,
+		`subject.cn`,
+		`dns_names < dns_names`,
+		`true < false`,
+		`dns_names.exists(n)`,
+		`subject.cn.matches("(")`,
+		`subject.cn.matches(serial)`,
+		`timestamp("yesterday") < not_after`,
+		`size(key_bits) > 1`,
+		`nosuch(1)`,
+		`1 == 1 == true`,
+		`[1, 2]`,
+		`key_bits @ 2`,
+	} {
+		if _, err := NewExpressionMatcher(expr); err == nil {
+			t.Errorf("NewExpressionMatcher(%q) succeeded, want error", expr)
+		}
+	}
+}
@@ -16,6 +16,8 @@ package scanner
 
 import (
 	"context"
+	"errors"
+	"math/rand"
 	"net/http"
 	"sync"
 	"time"
@@ -49,6 +51,23 @@ type FetcherOptions struct {
 	// Continuous determines whether Fetcher should run indefinitely after
 	// reaching EndIndex.
 	Continuous bool
+
+	// RateControl, if set, makes the Fetcher adapt the number of concurrent
+	// fetches, up to ParallelFetch, to the throttling of the Log.
+	RateControl *RateControlOptions
+
+	// STHCheckInterval, if non-zero, makes the Fetcher get a new STH from the
+	// Log at this interval while it runs, and verify that it and every STH
+	// obtained in Continuous mode are consistent with the STH the Fetcher
+	// started from. The Fetcher aborts with an *InconsistentSTHError if one
+	// is not. The client must implement ConsistencyClient.
+	STHCheckInterval time.Duration
+
+	// InclusionSampleRate is the fraction, between 0 and 1, of fetched
+	// entries for which the Fetcher verifies an inclusion proof against the
+	// current STH. The Fetcher aborts with a *NotIncludedError if an entry is
+	// not included. The client must implement InclusionClient if it is set.
+	InclusionSampleRate float64
 }
 
 // DefaultFetcherOptions returns new FetcherOptions with sensible defaults.
@@ -71,13 +90,22 @@ type Fetcher struct {
 	// Configuration options for this Fetcher instance.
 	opts *FetcherOptions
 
-	// Current STH of the Log this Fetcher sends queries to.
+	// Current STH of the Log this Fetcher sends queries to. Written with mu
+	// held, as it is read concurrently by currentSTH.
 	sth *ct.SignedTreeHead
 	// The STH retrieval backoff state. Used only in Continuous fetch mode.
 	sthBackoff *backoff.Backoff
-
+	// Limits the concurrent fetches, if RateControl is set.
+	control *rateController
+	// The STH the Fetcher started from.
+	startSTH *ct.SignedTreeHead
+	// Verifies new STHs against the earlier ones, if STHCheckInterval is set.
+	checker *sthChecker
+	// The error which aborted Run, if any.
+	failure error
+
+	mu sync.Mutex
 	// Stops range generator, which causes the Fetcher to terminate gracefully.
-	mu     sync.Mutex
 	cancel context.CancelFunc
 }
 
@@ -123,7 +151,10 @@ func (f *Fetcher) Prepare(ctx context.Context) (*ct.SignedTreeHead, error) {
 		klog.V(1).Infof("%s: Reset EndIndex from %d to %d", f.uri, f.opts.EndIndex, size)
 		f.opts.EndIndex = size
 	}
+	f.mu.Lock()
 	f.sth = sth
+	f.startSTH = sth
+	f.mu.Unlock()
 	return sth, nil
 }
 
@@ -132,21 +163,45 @@ func (f *Fetcher) Prepare(ctx context.Context) (*ct.SignedTreeHead, error) {
 // finished). For each successfully fetched batch, runs the fn callback.
 func (f *Fetcher) Run(ctx context.Context, fn func(EntryBatch)) error {
 	klog.V(1).Infof("%s: Starting up Fetcher...", f.uri)
-	if _, err := f.Prepare(ctx); err != nil {
+	sth, err := f.Prepare(ctx)
+	if err != nil {
 		return err
 	}
 
-	cctx, cancel := context.WithCancel(ctx)
+	// The workers run until abort is called on a failed STH check.
+	wctx, abort := context.WithCancel(ctx)
+	defer abort()
+	cctx, cancel := context.WithCancel(wctx)
 	defer cancel()
 
 	f.mu.Lock()
 	f.cancel = cancel
+	f.failure = nil
 	f.mu.Unlock()
 
+	if f.opts.STHCheckInterval > 0 {
+		cc, ok := f.client.(ConsistencyClient)
+		if !ok {
+			return errors.New("client cannot get consistency proofs to check STHs")
+		}
+		f.checker = newSTHChecker(f.uri, cc, sth)
+		go f.watchSTH(wctx, abort)
+	}
+	var ic InclusionClient
+	if f.opts.InclusionSampleRate > 0 {
+		var ok bool
+		if ic, ok = f.client.(InclusionClient); !ok {
+			return errors.New("client cannot get inclusion proofs to verify entries")
+		}
+	}
+
 	// Use a separately-cancelable context for the range generator, so we can
 	// close it down (in Stop) but still let the fetchers below run to
 	// completion.
-	ranges := f.genRanges(cctx)
+	ranges := f.genRanges(cctx, abort)
+	if f.opts.RateControl != nil {
+		f.control = newRateController(f.uri, f.opts.ParallelFetch, *f.opts.RateControl)
+	}
 
 	// Run fetcher workers.
 	var wg sync.WaitGroup
@@ -155,14 +210,57 @@ func (f *Fetcher) Run(ctx context.Context, fn func(EntryBatch)) error {
 		go func(idx int) {
 			defer wg.Done()
 			klog.V(1).Infof("%s: Fetcher worker %d starting...", f.uri, idx)
-			f.runWorker(ctx, ranges, fn)
+			f.runWorker(wctx, ranges, fn, ic, abort)
 			klog.V(1).Infof("%s: Fetcher worker %d finished", f.uri, idx)
 		}(w)
 	}
 	wg.Wait()
 
 	klog.V(1).Infof("%s: Fetcher terminated", f.uri)
-	return nil
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.failure
+}
+
+// watchSTH gets a new STH from the Log every STHCheckInterval and checks its
+// consistency, until ctx is done. It aborts the Fetcher if an STH is
+// inconsistent.
+func (f *Fetcher) watchSTH(ctx context.Context, abort context.CancelFunc) {
+	ticker := time.NewTicker(f.opts.STHCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		sth, err := f.client.GetSTH(ctx)
+		if err != nil {
+			klog.Warningf("%s: GetSTH() failed: %v", f.uri, err)
+			continue
+		}
+		if err := f.checker.check(ctx, sth); err != nil {
+			var inconsistent *InconsistentSTHError
+			if errors.As(err, &inconsistent) {
+				f.fail(err, abort)
+				return
+			}
+			klog.Warningf("%s: Failed to check STH consistency: %v", f.uri, err)
+			continue
+		}
+		klog.V(2).Infof("%s: STH with %d certs is consistent", f.uri, sth.TreeSize)
+	}
+}
+
+// fail records err as the reason for aborting Run, and aborts it.
+func (f *Fetcher) fail(err error, abort context.CancelFunc) {
+	klog.Errorf("%s: LOG MISBEHAVIOR DETECTED, aborting: %v", f.uri, err)
+	f.mu.Lock()
+	if f.failure == nil {
+		f.failure = err
+	}
+	f.mu.Unlock()
+	abort()
 }
 
 // Stop causes the Fetcher to terminate gracefully. After this call Run will
@@ -176,8 +274,9 @@ func (f *Fetcher) Stop() {
 
 // genRanges returns a channel of ranges to fetch, and starts a goroutine that
 // sends things down this channel. The goroutine terminates when all ranges
-// have been generated, or if context is cancelled.
-func (f *Fetcher) genRanges(ctx context.Context) <-chan fetchRange {
+// have been generated, or if context is cancelled. If an inconsistent STH is
+// found, it calls abort.
+func (f *Fetcher) genRanges(ctx context.Context, abort context.CancelFunc) <-chan fetchRange {
 	batch := int64(f.opts.BatchSize)
 	ranges := make(chan fetchRange)
 
@@ -193,6 +292,10 @@ func (f *Fetcher) genRanges(ctx context.Context) <-chan fetchRange {
 			if start == end { // Implies f.opts.Continuous == true.
 				if err := f.updateSTH(ctx); err != nil {
 					klog.Warningf("%s: Failed to obtain bigger STH: %v", f.uri, err)
+					var inconsistent *InconsistentSTHError
+					if errors.As(err, &inconsistent) {
+						f.fail(err, abort)
+					}
 					return
 				}
 				end = f.opts.EndIndex
@@ -247,20 +350,48 @@ func (f *Fetcher) updateSTH(ctx context.Context) error {
 			return backoff.RetriableErrorf("wait for bigger STH than %d (last=%d, target=%d)", sth.TreeSize, lastSize, targetSize)
 		}
 
+		if f.checker != nil {
+			if err := f.checker.check(ctx, sth); err != nil {
+				var inconsistent *InconsistentSTHError
+				if errors.As(err, &inconsistent) {
+					return err
+				}
+				return backoff.RetriableErrorf("check STH consistency: %v", err)
+			}
+		}
 		if quick {
 			f.sthBackoff.Reset() // Growth is presumably fast, set next pause to Min.
 		}
+		f.mu.Lock()
 		f.sth = sth
+		f.mu.Unlock()
 		f.opts.EndIndex = int64(sth.TreeSize)
 		return nil
 	})
 }
 
+// currentSTH returns the latest STH of the Log obtained by the Fetcher.
+func (f *Fetcher) currentSTH() *ct.SignedTreeHead {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sth
+}
+
+// StartSTH returns the STH the Fetcher started from, or nil if it has not
+// been prepared.
+func (f *Fetcher) StartSTH() *ct.SignedTreeHead {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.startSTH
+}
+
 // runWorker is a worker function for handling fetcher ranges.
 // Accepts cert ranges to fetch over the ranges channel, and if the fetch is
 // successful sends the corresponding EntryBatch through the fn callback. Will
 // retry failed attempts to retrieve ranges until the context is cancelled.
-func (f *Fetcher) runWorker(ctx context.Context, ranges <-chan fetchRange, fn func(EntryBatch)) {
+// Verifies the inclusion of a sample of the entries with ic, if set, calling
+// abort if one is not included.
+func (f *Fetcher) runWorker(ctx context.Context, ranges <-chan fetchRange, fn func(EntryBatch), ic InclusionClient, abort context.CancelFunc) {
 	for r := range ranges {
 		// Logs MAY return fewer than the number of leaves requested. Only complete
 		// if we actually got all the leaves we were expecting.
@@ -282,8 +413,16 @@ func (f *Fetcher) runWorker(ctx context.Context, ranges <-chan fetchRange, fn fu
 			var resp *ct.GetEntriesResponse
 			// TODO(pavelkalinnikov): Report errors in a LogClient decorator on failure.
 			if err := bo.Retry(ctx, func() error {
+				if f.control != nil {
+					if err := f.control.acquire(ctx); err != nil {
+						return err
+					}
+				}
 				var err error
 				resp, err = f.client.GetRawEntries(ctx, r.start, r.end)
+				if f.control != nil {
+					f.control.release(err)
+				}
 				return err
 			}); err != nil {
 				if rspErr, isRspErr := err.(jsonclient.RspError); isRspErr && rspErr.StatusCode == http.StatusTooManyRequests {
@@ -294,12 +433,42 @@ func (f *Fetcher) runWorker(ctx context.Context, ranges <-chan fetchRange, fn fu
 				// There is no error reporting yet for this worker, so just retry again.
 				continue
 			}
+			if ic != nil {
+				if err := f.sampleInclusion(ctx, ic, r.start, resp.Entries); err != nil {
+					f.fail(err, abort)
+					return
+				}
+			}
 			fn(EntryBatch{Start: r.start, Entries: resp.Entries})
 			r.start += int64(len(resp.Entries))
 		}
 	}
 }
 
+// sampleInclusion verifies the inclusion in the current STH of a random
+// sample of entries, the first of which is at index start. It returns a
+// *NotIncludedError for the first entry found not to be included. Failures
+// to get a proof are only logged.
+func (f *Fetcher) sampleInclusion(ctx context.Context, ic InclusionClient, start int64, entries []ct.LeafEntry) error {
+	sth := f.currentSTH()
+	for i := range entries {
+		index := start + int64(i)
+		if index >= int64(sth.TreeSize) || rand.Float64() >= f.opts.InclusionSampleRate {
+			continue
+		}
+		if err := verifyInclusion(ctx, ic, f.uri, index, &entries[i], sth); err != nil {
+			var notIncluded *NotIncludedError
+			if errors.As(err, &notIncluded) {
+				return err
+			}
+			klog.Warningf("%s: Failed to verify inclusion: %v", f.uri, err)
+			continue
+		}
+		klog.V(2).Infof("%s: Entry %d is included in STH with %d certs", f.uri, index, sth.TreeSize)
+	}
+	return nil
+}
+
 func min(a, b int64) int64 {
 	if a < b {
 		return a
@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+// InclusionClient is implemented by LogClients which can fetch inclusion
+// proofs, as needed by a Fetcher which verifies the inclusion of entries.
+type InclusionClient interface {
+	GetProofByHash(ctx context.Context, hash []byte, treeSize uint64) (*ct.GetProofByHashResponse, error)
+}
+
+// NotIncludedError reports an entry served by a Log which is not included in
+// the tree of one of its STHs.
+type NotIncludedError struct {
+	URI   string
+	Index int64
+	STH   *ct.SignedTreeHead
+	Err   error
+}
+
+func (e *NotIncludedError) Error() string {
+	return fmt.Sprintf("%s: entry %d is not included in STH with size %d and root %x: %v",
+		e.URI, e.Index, e.STH.TreeSize, e.STH.SHA256RootHash[:], e.Err)
+}
+
+func (e *NotIncludedError) Unwrap() error {
+	return e.Err
+}
+
+// verifyInclusion checks that entry, at index in the Log, is included in the
+// tree of sth. It returns a *NotIncludedError if it is not, or another error if
+// the check could not be made.
+func verifyInclusion(ctx context.Context, client InclusionClient, uri string, index int64, entry *ct.LeafEntry, sth *ct.SignedTreeHead) error {
+	hash := rfc6962.DefaultHasher.HashLeaf(entry.LeafInput)
+	rsp, err := client.GetProofByHash(ctx, hash, sth.TreeSize)
+	var rspErr jsonclient.RspError
+	if errors.As(err, &rspErr) && (rspErr.StatusCode == http.StatusBadRequest || rspErr.StatusCode == http.StatusNotFound) {
+		// The Log does not know the hash of an entry it has served.
+		return &NotIncludedError{URI: uri, Index: index, STH: sth, Err: err}
+	} else if err != nil {
+		return fmt.Errorf("failed to get inclusion proof for entry %d: %v", index, err)
+	}
+	// The proof may be for an earlier copy of an identical leaf, which
+	// commits to the entry just as well.
+	if err := proof.VerifyInclusion(rfc6962.DefaultHasher, uint64(rsp.LeafIndex), sth.TreeSize, hash, rsp.AuditPath, sth.SHA256RootHash[:]); err != nil {
+		return &NotIncludedError{URI: uri, Index: index, STH: sth, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFetcherInclusionSampling(t *testing.T) {
+	for _, test := range []struct {
+		desc      string
+		forge     bool // Whether to serve an entry which is not in the tree.
+		proofErr  bool // Whether the log fails to return proofs.
+		wantErr   bool
+		wantCount int
+	}{
+		{desc: "included", wantCount: 8},
+		{desc: "not-included", forge: true, wantErr: true},
+		{desc: "no-proofs", forge: true, proofErr: true, wantCount: 8},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			log := newTreeClient(8, "leaf")
+			if test.forge {
+				log.leaves[5] = []byte("forged")
+			}
+			if test.proofErr {
+				log.proofErr = errors.New("unavailable")
+			}
+
+			opts := DefaultFetcherOptions()
+			opts.BatchSize = 3
+			opts.InclusionSampleRate = 1
+			f := NewFetcher(log, opts)
+			count := 0
+			err := f.Run(ctx, func(b EntryBatch) { count += len(b.Entries) })
+			var notIncluded *NotIncludedError
+			if got := errors.As(err, &notIncluded); got != test.wantErr {
+				t.Fatalf("Run()=%v, want NotIncludedError? %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				if got, want := notIncluded.Index, int64(5); got != want {
+					t.Errorf("Run() reported entry %d not included, want %d", got, want)
+				}
+				return
+			}
+			if count != test.wantCount {
+				t.Errorf("Run() fetched %d entries, want %d", count, test.wantCount)
+			}
+		})
+	}
+}
@@ -220,6 +220,8 @@ func (m CertVerifyFailMatcher) Matches(leaf *ct.LeafEntry) bool {
 		Roots:         m.roots,
 		Intermediates: x509.NewCertPool(),
 		CurrentTime:   when,
+		// Matching is on the issuing root, whatever the policies of the chain.
+		DisablePolicyChecks: true,
 	}
 	chain := make([]*x509.Certificate, len(entry.Chain))
 	for ii, cert := range entry.Chain {
@@ -0,0 +1,198 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"k8s.io/klog/v2"
+)
+
+// MultiScannerOptions holds configuration options for a MultiScanner.
+type MultiScannerOptions struct {
+	// Options for the scan of each log. Each log is scanned in full, so
+	// StartIndex and EndIndex are ignored; the Matcher and Checkpoints store
+	// are shared by all the logs.
+	ScannerOptions
+
+	// Statuses of the logs in the list to scan. Defaults to usable logs.
+	Statuses []loglist3.LogStatus
+
+	// Maximum number of logs to scan at once, or 0 for all of them.
+	ParallelLogs int
+
+	// NewClient creates the client for a log. Defaults to a client.LogClient
+	// which uses hc and clientOpts, as passed to NewMultiScanner, with the
+	// public key of the log.
+	NewClient func(log *loglist3.Log) (LogClient, error)
+}
+
+// MultiScanner scans all the logs of a log list concurrently.
+type MultiScanner struct {
+	scans []*logScan
+	opts  MultiScannerOptions
+}
+
+// logScan is the state of the scan of one log.
+type logScan struct {
+	log     *loglist3.Log
+	scanner *Scanner
+	done    atomic.Bool
+
+	mu  sync.Mutex
+	err error
+}
+
+// LogProgress is the progress of the scan of one log.
+type LogProgress struct {
+	Log *loglist3.Log
+	// TreeSize is the size of the tree being scanned, once known.
+	TreeSize  uint64
+	Processed int64
+	Matched   int64
+	Done      bool
+	// Err is the error which ended the scan, if any.
+	Err error
+}
+
+// MultiProgress is the progress of a MultiScanner.
+type MultiProgress struct {
+	Logs []LogProgress
+	// Totals over all the logs.
+	Processed int64
+	Matched   int64
+}
+
+// NewMultiScanner creates a MultiScanner for the logs in ll with one of the
+// given statuses.
+func NewMultiScanner(ll *loglist3.LogList, hc *http.Client, clientOpts jsonclient.Options, opts MultiScannerOptions) (*MultiScanner, error) {
+	if len(opts.Statuses) == 0 {
+		opts.Statuses = []loglist3.LogStatus{loglist3.UsableLogStatus}
+	}
+	if opts.NewClient == nil {
+		opts.NewClient = func(log *loglist3.Log) (LogClient, error) {
+			logOpts := clientOpts
+			logOpts.PublicKeyDER = log.Key
+			return client.New(log.URL, hc, logOpts)
+		}
+	}
+
+	m := &MultiScanner{opts: opts}
+	selected := ll.SelectByStatus(opts.Statuses)
+	for _, op := range selected.Operators {
+		for _, log := range op.Logs {
+			lc, err := opts.NewClient(log)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create client for %s: %v", log.URL, err)
+			}
+			scanOpts := opts.ScannerOptions
+			scanOpts.StartIndex, scanOpts.EndIndex = 0, 0
+			m.scans = append(m.scans, &logScan{log: log, scanner: NewScanner(lc, scanOpts)})
+		}
+	}
+	if len(m.scans) == 0 {
+		return nil, errors.New("no logs to scan")
+	}
+	return m, nil
+}
+
+// Scan scans all the logs, calling foundCert and foundPrecert with the log
+// and the entry for each matched certificate and precertificate, as for
+// Scanner.Scan. Blocks until the scans of all the logs are complete. The
+// failure of one log does not stop the others; the returned error joins the
+// errors of all the logs which failed.
+func (m *MultiScanner) Scan(ctx context.Context, foundCert, foundPrecert func(*loglist3.Log, *ct.RawLogEntry)) error {
+	parallel := m.opts.ParallelLogs
+	if parallel <= 0 {
+		parallel = len(m.scans)
+	}
+	sem := make(chan struct{}, parallel)
+
+	var wg sync.WaitGroup
+	for _, ls := range m.scans {
+		wg.Add(1)
+		go func(ls *logScan) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				ls.finish(ctx.Err())
+				return
+			}
+			klog.V(1).Infof("%s: Starting scan", ls.log.URL)
+			err := ls.scanner.Scan(ctx,
+				func(e *ct.RawLogEntry) { foundCert(ls.log, e) },
+				func(e *ct.RawLogEntry) { foundPrecert(ls.log, e) })
+			if err != nil {
+				klog.Errorf("%s: Scan failed: %v", ls.log.URL, err)
+			}
+			ls.finish(err)
+		}(ls)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, ls := range m.scans {
+		if err := ls.result(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ls.log.URL, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (ls *logScan) finish(err error) {
+	ls.mu.Lock()
+	ls.err = err
+	ls.mu.Unlock()
+	ls.done.Store(true)
+}
+
+func (ls *logScan) result() error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.err
+}
+
+// Progress returns the progress of the scans of all the logs. It may be
+// called while Scan is running.
+func (m *MultiScanner) Progress() MultiProgress {
+	var p MultiProgress
+	for _, ls := range m.scans {
+		lp := LogProgress{
+			Log:       ls.log,
+			Processed: atomic.LoadInt64(&ls.scanner.certsProcessed),
+			Matched:   atomic.LoadInt64(&ls.scanner.certsMatched),
+			Done:      ls.done.Load(),
+			Err:       ls.result(),
+		}
+		if sth := ls.scanner.fetcher.currentSTH(); sth != nil {
+			lp.TreeSize = sth.TreeSize
+		}
+		p.Logs = append(p.Logs, lp)
+		p.Processed += lp.Processed
+		p.Matched += lp.Matched
+	}
+	return p
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+)
+
+func TestMultiScanner(t *testing.T) {
+	ts1, ts2 := serveFourEntries(t), serveFourEntries(t)
+	defer ts1.Close()
+	defer ts2.Close()
+	usable := &loglist3.LogStates{Usable: &loglist3.LogState{}}
+	retired := &loglist3.LogStates{Retired: &loglist3.LogState{}}
+	ll := &loglist3.LogList{Operators: []*loglist3.Operator{
+		{Name: "one", Logs: []*loglist3.Log{{URL: ts1.URL, State: usable}, {URL: "https://retired.example.com", State: retired}}},
+		{Name: "two", Logs: []*loglist3.Log{{URL: ts2.URL, State: usable}}},
+	}}
+
+	opts := MultiScannerOptions{
+		ScannerOptions: ScannerOptions{
+			FetcherOptions: FetcherOptions{BatchSize: 2, ParallelFetch: 1, StartIndex: 3},
+			Matcher:        &MatchAll{},
+			NumWorkers:     1,
+		},
+		ParallelLogs: 1,
+		NewClient: func(log *loglist3.Log) (LogClient, error) {
+			return client.New(log.URL, &http.Client{}, jsonclient.Options{})
+		},
+	}
+	m, err := NewMultiScanner(ll, nil, jsonclient.Options{}, opts)
+	if err != nil {
+		t.Fatalf("NewMultiScanner()=%v", err)
+	}
+
+	var mu sync.Mutex
+	found := make(map[string]int)
+	record := func(log *loglist3.Log, _ *ct.RawLogEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		found[log.URL]++
+	}
+	if err := m.Scan(context.Background(), record, record); err != nil {
+		t.Fatalf("Scan()=%v", err)
+	}
+	if len(found) != 2 || found[ts1.URL] != 4 || found[ts2.URL] != 4 {
+		t.Errorf("Scan() found %v, want 4 entries from each usable log", found)
+	}
+
+	p := m.Progress()
+	if len(p.Logs) != 2 || p.Processed != 8 || p.Matched != 8 {
+		t.Errorf("Progress()=%+v, want 8 entries processed and matched in 2 logs", p)
+	}
+	for _, lp := range p.Logs {
+		if !lp.Done || lp.Err != nil || lp.TreeSize != 4 {
+			t.Errorf("Progress() for %s=%+v, want done scanning tree of size 4", lp.Log.URL, lp)
+		}
+	}
+}
+
+func TestMultiScannerNoLogs(t *testing.T) {
+	if _, err := NewMultiScanner(&loglist3.LogList{}, nil, jsonclient.Options{}, MultiScannerOptions{}); err == nil {
+		t.Error("NewMultiScanner() with no logs succeeded, want error")
+	}
+}
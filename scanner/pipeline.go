@@ -0,0 +1,156 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/google/trillian/monitoring"
+)
+
+// A scan is a pipeline of three stages, connected by queues of at most
+// BufferSize entries:
+//
+//	fetch (ParallelFetch workers) -> parse (NumWorkers) -> match (NumWorkers)
+//
+// Each stage blocks while the queue to the next one is full, so a slow
+// matcher or callback holds up parsing and then fetching. In addition,
+// fetched entries are only queued while the total size of the entries in
+// the pipeline is within MaxBufferedBytes.
+
+var (
+	pipelineOnce  sync.Once
+	queuedEntries monitoring.Gauge // log, queue => value
+	queuedBytes   monitoring.Gauge // log => value
+)
+
+func setupPipelineMetrics(mf monitoring.MetricFactory) {
+	queuedEntries = mf.NewGauge("scanner_queued_entries", "Number of entries waiting in a queue of the scan pipeline", "log", "queue")
+	queuedBytes = mf.NewGauge("scanner_buffered_bytes", "Total size of the fetched entries which have not been processed yet", "log")
+}
+
+// QueueDepths is a snapshot of the queues of a running scan.
+type QueueDepths struct {
+	// Fetched entries waiting to be parsed.
+	Fetched int
+	// Parsed entries waiting to be matched.
+	Parsed int
+	// Total size of the entries fetched but not yet fully processed.
+	Bytes int64
+}
+
+// parsedEntry is an entry which has passed through the parse stage.
+type parsedEntry struct {
+	entryInfo
+	// The entry, and its parsed [pre-]certificate if the Matcher needs it.
+	raw    *ct.RawLogEntry
+	parsed *ct.LogEntry
+	// The error which stopped the entry from being parsed, if any.
+	err error
+}
+
+// parseJob is a worker function for the parse stage. It parses the entries
+// received over fetched, as needed for the Matcher, and passes them on over
+// parsed.
+func (s *Scanner) parseJob(fetched <-chan entryInfo, parsed chan<- parsedEntry) {
+	for e := range fetched {
+		p := parsedEntry{entryInfo: e}
+		// A LeafMatcher does not need the parsed entry, and only the
+		// matching entries are parsed further.
+		if _, ok := s.opts.Matcher.(Matcher); ok {
+			p.raw, p.err = ct.RawLogEntryFromLeaf(e.index, &e.entry)
+			if p.err != nil {
+				p.err = fmt.Errorf("failed to build raw log entry %d: %v", e.index, p.err)
+			} else {
+				var err error
+				p.parsed, err = p.raw.ToLogEntry()
+				if s.isCertErrorFatal(err, p.parsed, e.index) {
+					p.err = fmt.Errorf("failed to parse [pre-]certificate in MerkleTreeLeaf[%d]: %v", e.index, err)
+				}
+			}
+		}
+		parsed <- p
+	}
+}
+
+// entrySize returns the size in memory counted against MaxBufferedBytes for
+// entry.
+func entrySize(entry *ct.LeafEntry) int64 {
+	return int64(len(entry.LeafInput) + len(entry.ExtraData))
+}
+
+// byteBudget limits the total size of the entries in the pipeline.
+type byteBudget struct {
+	max int64
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	used int64
+}
+
+func newByteBudget(max int64) *byteBudget {
+	b := &byteBudget{max: max}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// acquire blocks until n more bytes fit within the budget. An entry larger
+// than the whole budget is let through once the pipeline is empty.
+func (b *byteBudget) acquire(n int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.used > 0 && b.used+n > b.max {
+		b.cond.Wait()
+	}
+	b.used += n
+}
+
+// release returns n bytes to the budget.
+func (b *byteBudget) release(n int64) {
+	b.mu.Lock()
+	b.used -= n
+	b.mu.Unlock()
+	b.cond.Broadcast()
+}
+
+// QueueDepths returns the depths of the queues of the running scan, or zero
+// depths if there is none.
+func (s *Scanner) QueueDepths() QueueDepths {
+	var d QueueDepths
+	if q := s.queues.Load(); q != nil {
+		d.Fetched = len(q.fetched)
+		d.Parsed = len(q.parsed)
+	}
+	d.Bytes = atomic.LoadInt64(&s.bufferedBytes)
+	return d
+}
+
+// exportQueueDepths sets the pipeline metrics to the current queue depths.
+func (s *Scanner) exportQueueDepths() {
+	d := s.QueueDepths()
+	uri := s.fetcher.uri
+	queuedEntries.Set(float64(d.Fetched), uri, "fetched")
+	queuedEntries.Set(float64(d.Parsed), uri, "parsed")
+	queuedBytes.Set(float64(d.Bytes), uri)
+}
+
+// pipelineQueues holds the queues between the stages of a running scan.
+type pipelineQueues struct {
+	fetched chan entryInfo
+	parsed  chan parsedEntry
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+)
+
+func TestByteBudget(t *testing.T) {
+	b := newByteBudget(10)
+	b.acquire(6)
+	b.acquire(4)
+
+	acquired := make(chan struct{})
+	go func() {
+		b.acquire(5)
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquire(5) returned beyond the budget")
+	case <-time.After(50 * time.Millisecond):
+	}
+	b.release(6)
+	<-acquired
+
+	// An oversized acquisition goes through once the budget is empty.
+	b.release(4)
+	b.release(5)
+	b.acquire(20)
+}
+
+func TestScannerMaxBufferedBytes(t *testing.T) {
+	ts := serveFourEntries(t)
+	defer ts.Close()
+	logClient, err := client.New(ts.URL, &http.Client{}, jsonclient.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := ScannerOptions{
+		FetcherOptions:   FetcherOptions{BatchSize: 4, ParallelFetch: 2},
+		Matcher:          &MatchAll{},
+		NumWorkers:       2,
+		BufferSize:       4,
+		MaxBufferedBytes: 1,
+	}
+	s := NewScanner(logClient, opts)
+
+	var mu sync.Mutex
+	count := 0
+	found := func(e *ct.RawLogEntry) {
+		// Only the entry being processed fits within the budget.
+		d := s.QueueDepths()
+		if d.Fetched != 0 || d.Parsed != 0 {
+			t.Errorf("QueueDepths()=%+v while processing entry %d, want empty queues", d, e.Index)
+		}
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		count++
+		mu.Unlock()
+	}
+	if err := s.Scan(context.Background(), found, found); err != nil {
+		t.Fatalf("Scan()=%v", err)
+	}
+	if count != 4 {
+		t.Errorf("Scan() found %d entries, want 4", count)
+	}
+	if got := s.QueueDepths(); got != (QueueDepths{}) {
+		t.Errorf("QueueDepths()=%+v after Scan, want zero", got)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"k8s.io/klog/v2"
+)
+
+// RateControlOptions configures the adaptive control of the number of
+// concurrent requests a Fetcher makes, between one and ParallelFetch.
+// Whenever the fraction of recent requests throttled by the log (with HTTP
+// 429 or 503) exceeds MaxErrorRate the number is halved, and after each
+// Window of requests within the ceiling it is increased by one. After each
+// throttled request all workers also pause, for as long as the response's
+// Retry-After header asks or else for an exponentially growing interval.
+type RateControlOptions struct {
+	// MaxErrorRate is the highest tolerated fraction of throttled requests.
+	// Defaults to 0.05.
+	MaxErrorRate float64
+	// Window is the number of recent requests over which the error rate is
+	// measured. Defaults to 20.
+	Window int
+}
+
+const (
+	minThrottlePause = time.Second
+	maxThrottlePause = 30 * time.Second
+)
+
+// rateController limits the number of concurrent requests to a log.
+type rateController struct {
+	uri          string
+	max          int
+	maxErrorRate float64
+
+	mu         sync.Mutex
+	allowed    int
+	active     int
+	pauseUntil time.Time
+	pause      time.Duration // Pause after the next throttled request.
+	outcomes   []bool        // Whether each of the recent requests was throttled.
+	next       int           // Position in outcomes of the next request's outcome.
+	count      int           // Number of outcomes recorded since the last reset.
+	changed    chan struct{}
+}
+
+func newRateController(uri string, parallel int, opts RateControlOptions) *rateController {
+	if opts.MaxErrorRate <= 0 {
+		opts.MaxErrorRate = 0.05
+	}
+	if opts.Window <= 0 {
+		opts.Window = 20
+	}
+	if parallel < 1 {
+		parallel = 1
+	}
+	return &rateController{
+		uri:          uri,
+		max:          parallel,
+		maxErrorRate: opts.MaxErrorRate,
+		allowed:      parallel,
+		pause:        minThrottlePause,
+		outcomes:     make([]bool, opts.Window),
+		changed:      make(chan struct{}),
+	}
+}
+
+// acquire blocks until another request may be sent, or ctx is done.
+func (c *rateController) acquire(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		wait := time.Until(c.pauseUntil)
+		if wait <= 0 && c.active < c.allowed {
+			c.active++
+			c.mu.Unlock()
+			return nil
+		}
+		changed := c.changed
+		c.mu.Unlock()
+
+		var timer *time.Timer
+		var expired <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			expired = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return ctx.Err()
+		case <-changed:
+		case <-expired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// release records the outcome of a request made after acquire, adjusting the
+// number of concurrent requests allowed.
+func (c *rateController) release(err error) {
+	throttled, retryAfter := isThrottled(err)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+	c.active--
+	if err != nil && !throttled {
+		// Other failures say nothing about the load on the log.
+		return
+	}
+	if throttled {
+		pause := retryAfter
+		if pause <= 0 {
+			pause = c.pause
+			if c.pause *= 2; c.pause > maxThrottlePause {
+				c.pause = maxThrottlePause
+			}
+		}
+		if until := time.Now().Add(pause); until.After(c.pauseUntil) {
+			c.pauseUntil = until
+		}
+	} else {
+		c.pause = minThrottlePause
+	}
+
+	c.outcomes[c.next] = throttled
+	c.next = (c.next + 1) % len(c.outcomes)
+	if c.count < len(c.outcomes) {
+		c.count++
+	}
+	throttledCount := 0
+	for _, t := range c.outcomes {
+		if t {
+			throttledCount++
+		}
+	}
+	rate := float64(throttledCount) / float64(len(c.outcomes))
+	switch {
+	case throttled && rate > c.maxErrorRate && c.allowed > 1:
+		c.allowed /= 2
+		c.reset()
+		klog.V(1).Infof("%s: Throttled by log, reduced parallel fetches to %d", c.uri, c.allowed)
+	case !throttled && c.count == len(c.outcomes) && rate <= c.maxErrorRate && c.allowed < c.max:
+		c.allowed++
+		c.reset()
+		klog.V(1).Infof("%s: Increased parallel fetches to %d", c.uri, c.allowed)
+	}
+}
+
+// reset forgets the recorded outcomes, so that the effect of a change is
+// measured afresh. Must be called with mu held.
+func (c *rateController) reset() {
+	for i := range c.outcomes {
+		c.outcomes[i] = false
+	}
+	c.count = 0
+}
+
+// notify wakes up the callers waiting in acquire. Must be called with mu
+// held.
+func (c *rateController) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// isThrottled reports whether err shows that the log is throttling requests,
+// along with the wait it asked for, if any.
+func isThrottled(err error) (bool, time.Duration) {
+	var rspErr jsonclient.RspError
+	if !errors.As(err, &rspErr) {
+		return false, 0
+	}
+	switch rspErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true, rspErr.RetryAfter
+	}
+	return false, 0
+}
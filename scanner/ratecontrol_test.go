@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+)
+
+func TestRateController(t *testing.T) {
+	ctx := context.Background()
+	c := newRateController("uri", 8, RateControlOptions{MaxErrorRate: 0.05, Window: 10})
+	run := func(err error) {
+		t.Helper()
+		c.mu.Lock()
+		c.pauseUntil = time.Time{} // Don't wait for pauses after throttling.
+		c.mu.Unlock()
+		if err := c.acquire(ctx); err != nil {
+			t.Fatalf("acquire()=%v", err)
+		}
+		c.release(err)
+	}
+	check := func(want int) {
+		t.Helper()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.allowed != want {
+			t.Errorf("allowed=%d, want %d", c.allowed, want)
+		}
+	}
+
+	throttled := jsonclient.RspError{StatusCode: http.StatusTooManyRequests}
+	run(throttled)
+	check(4)
+	run(throttled)
+	check(2)
+	run(errors.New("connection reset"))
+	check(2)
+	for i := 0; i < 9; i++ {
+		run(nil)
+	}
+	check(2)
+	run(nil)
+	check(3)
+	for i := 0; i < 50; i++ {
+		run(nil)
+	}
+	check(8)
+}
+
+func TestRateControllerRetryAfter(t *testing.T) {
+	c := newRateController("uri", 2, RateControlOptions{})
+	if err := c.acquire(context.Background()); err != nil {
+		t.Fatalf("acquire()=%v", err)
+	}
+	c.release(jsonclient.RspError{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire() during Retry-After=%v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -31,8 +31,10 @@ import (
 	ct "github.com/RarimoVoting/certificate-transparency-go"
 	"github.com/RarimoVoting/certificate-transparency-go/client"
 	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
 	"github.com/RarimoVoting/certificate-transparency-go/scanner"
 	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -41,13 +43,17 @@ const (
 )
 
 var (
-	logURI = flag.String("log_uri", "https://ct.googleapis.com/aviator", "CT log base URI")
+	logURI      = flag.String("log_uri", "https://ct.googleapis.com/aviator", "CT log base URI")
+	logListFile = flag.String("log_list", "", "File holding a v3 log list, all of whose usable logs are scanned instead of --log_uri")
+	tileURI     = flag.String("tile_uri", "", "Monitoring prefix of the log's static-ct-api tiles, from which to fetch entries if the log serves them, instead of get-entries")
 
 	matchSubjectRegex = flag.String("match_subject_regex", ".*", "Regex to match CN/SAN")
 	matchIssuerRegex  = flag.String("match_issuer_regex", "", "Regex to match in issuer CN")
 	precertsOnly      = flag.Bool("precerts_only", false, "Only match precerts")
 	serialNumber      = flag.String("serial_number", "", "Serial number of certificate of interest")
 	sctTimestamp      = flag.Uint64("sct_timestamp_ms", 0, "Timestamp of logged SCT")
+	sctLogList        = flag.String("audit_sct_log_list", "", "File holding a v3 log list against whose keys to verify the SCTs embedded in certificates, reporting SCTs from unknown logs or with invalid signatures, instead of matching")
+	matchExpression   = flag.String("match_expression", "", "CEL-style expression over certificate fields to match, e.g. 'key_type == \"RSA\" && key_bits < 2048'")
 
 	parseErrors    = flag.Bool("parse_errors", false, "Only match certificates with parse errors")
 	nfParseErrors  = flag.Bool("non_fatal_errors", false, "Treat non-fatal parse errors as also matching (with --parse_errors)")
@@ -55,12 +61,21 @@ var (
 
 	batchSize     = flag.Int("batch_size", 1000, "Max number of entries to request at per call to get-entries")
 	numWorkers    = flag.Int("num_workers", 2, "Number of concurrent matchers")
+	maxBuffered   = flag.Int64("max_buffered_bytes", 0, "Maximum total size of the fetched entries waiting to be matched (0 = unlimited)")
 	parallelFetch = flag.Int("parallel_fetch", 2, "Number of concurrent GetEntries fetches")
 	startIndex    = flag.Int64("start_index", 0, "Log index to start scanning at")
 	endIndex      = flag.Int64("end_index", 0, "Log index to end scanning at (non-inclusive, 0 = end of log)")
+	rateLimit     = flag.Float64("rate_limit", 0, "Maximum number of requests per second to the log (0 = unlimited)")
+	adaptiveFetch = flag.Bool("adaptive_fetch", false, "Reduce the number of concurrent fetches, down to one, while the log is throttling requests")
+	sthCheck      = flag.Duration("sth_check_interval", 0, "Interval at which to fetch a new STH and verify its consistency with the STH the scan started from, aborting the scan if it is inconsistent (0 = never)")
+	inclusionRate = flag.Float64("inclusion_sample_rate", 0, "Fraction of fetched entries for which to verify an inclusion proof, aborting the scan if one is not included")
+
+	checkpointFile = flag.String("checkpoint_file", "", "File in which to record the progress of the scan, so that it resumes where it left off if restarted")
 
 	printChains = flag.Bool("print_chains", false, "If true prints the whole chain rather than a summary")
 	dumpDir     = flag.String("dump_dir", "", "Directory to store matched certificates in")
+	outputFile  = flag.String("output_file", "", "File to write matched entries to as lines of JSON, instead of logging them")
+	dedup       = flag.Bool("dedup", false, "Only report one of each matched precertificate and its final certificate, and list precertificates with no matched final certificate")
 )
 
 func dumpData(entry *ct.RawLogEntry) {
@@ -150,6 +165,9 @@ func createRegexes(regexValue string) (*regexp.Regexp, *regexp.Regexp) {
 }
 
 func createMatcherFromFlags(logClient *client.LogClient) (interface{}, error) {
+	if *matchExpression != "" {
+		return scanner.NewExpressionMatcher(*matchExpression)
+	}
 	if *parseErrors {
 		return scanner.CertParseFailMatcher{MatchNonFatalErrs: *nfParseErrors}, nil
 	}
@@ -183,10 +201,44 @@ func createMatcherFromFlags(logClient *client.LogClient) (interface{}, error) {
 		PrecertificateSubjectRegex: precertRegex}, nil
 }
 
+// scanLogList scans all the usable logs in the log list file, logging
+// the matched entries.
+func scanLogList(ctx context.Context, hc *http.Client, logOpts jsonclient.Options, opts scanner.ScannerOptions) error {
+	data, err := os.ReadFile(*logListFile)
+	if err != nil {
+		return err
+	}
+	ll, err := loglist3.NewFromJSON(data)
+	if err != nil {
+		return err
+	}
+	m, err := scanner.NewMultiScanner(ll, hc, logOpts, scanner.MultiScannerOptions{ScannerOptions: opts})
+	if err != nil {
+		return err
+	}
+	found := func(l *loglist3.Log, entry *ct.RawLogEntry) {
+		log.Printf("Log %s:", l.URL)
+		if *printChains {
+			logFullChain(entry)
+		} else {
+			logCertInfo(entry)
+		}
+	}
+	err = m.Scan(ctx, found, found)
+	p := m.Progress()
+	log.Printf("Processed %d entries in %d logs, matched %d", p.Processed, len(p.Logs), p.Matched)
+	reportUnmatched(opts.Deduplicator)
+	return err
+}
+
 func main() {
 	flag.Parse()
 
-	logClient, err := client.New(*logURI, &http.Client{
+	logOpts := jsonclient.Options{UserAgent: "ct-go-scanlog/1.0"}
+	if *rateLimit > 0 {
+		logOpts.RateLimiter = rate.NewLimiter(rate.Limit(*rateLimit), 1)
+	}
+	hc := &http.Client{
 		Timeout: 10 * time.Second,
 		Transport: &http.Transport{
 			TLSHandshakeTimeout:   30 * time.Second,
@@ -197,7 +249,8 @@ func main() {
 			IdleConnTimeout:       90 * time.Second,
 			ExpectContinueTimeout: 1 * time.Second,
 		},
-	}, jsonclient.Options{UserAgent: "ct-go-scanlog/1.0"})
+	}
+	logClient, err := client.New(*logURI, hc, logOpts)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -212,13 +265,61 @@ func main() {
 			ParallelFetch: *parallelFetch,
 			StartIndex:    *startIndex,
 			EndIndex:      *endIndex,
+
+			STHCheckInterval:    *sthCheck,
+			InclusionSampleRate: *inclusionRate,
 		},
-		Matcher:    matcher,
-		NumWorkers: *numWorkers,
+		Matcher:          matcher,
+		NumWorkers:       *numWorkers,
+		MaxBufferedBytes: *maxBuffered,
+	}
+	if *adaptiveFetch {
+		opts.RateControl = &scanner.RateControlOptions{}
+	}
+	if *dedup {
+		opts.Deduplicator = scanner.NewDeduplicator()
+	}
+	if *checkpointFile != "" {
+		opts.Checkpoints = scanner.NewFileCheckpointStore(*checkpointFile)
 	}
-	s := scanner.NewScanner(logClient, opts)
-
 	ctx := context.Background()
+	if *logListFile != "" {
+		if err := scanLogList(ctx, hc, logOpts, opts); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	var scanClient scanner.LogClient = logClient
+	if *tileURI != "" {
+		tileClient, err := client.NewStaticLogClient(*tileURI, hc, logOpts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		scanClient = scanner.NewTileClient(tileClient, logClient)
+	}
+	if *sctLogList != "" {
+		if err := auditSCTs(ctx, scanClient, opts); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	s := scanner.NewScanner(scanClient, opts)
+
+	if *outputFile != "" {
+		f, err := os.Create(*outputFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sink := scanner.NewJSONLinesSink(f)
+		if err := s.ScanToSink(ctx, sink); err != nil {
+			log.Fatal(err)
+		}
+		if err := sink.Close(ctx); err != nil {
+			log.Fatal(err)
+		}
+		reportUnmatched(opts.Deduplicator)
+		return
+	}
 	if *printChains {
 		if err := s.Scan(ctx, logFullChain, logFullChain); err != nil {
 			log.Fatal(err)
@@ -228,4 +329,47 @@ func main() {
 			log.Fatal(err)
 		}
 	}
+	reportUnmatched(opts.Deduplicator)
+}
+
+// auditSCTs scans the log, verifying the SCTs embedded in its certificates
+// against the logs of --audit_sct_log_list.
+func auditSCTs(ctx context.Context, lc scanner.LogClient, opts scanner.ScannerOptions) error {
+	data, err := os.ReadFile(*sctLogList)
+	if err != nil {
+		return err
+	}
+	ll, err := loglist3.NewFromJSON(data)
+	if err != nil {
+		return err
+	}
+	auditor, err := scanner.NewSCTAuditor(ll, func(f *scanner.SCTFinding) {
+		logName := fmt.Sprintf("%x", f.LogID)
+		if f.Log != nil {
+			logName = f.Log.Description
+		}
+		log.Printf("Index %d: SCT %d from log %s: %v: %v", f.Entry.Index, f.SCTIndex, logName, f.Problem, f.Err)
+	})
+	if err != nil {
+		return err
+	}
+	opts.Matcher = auditor
+	err = scanner.NewScanner(lc, opts).Scan(ctx, auditor.Audit, func(*ct.RawLogEntry) {})
+	st := auditor.Stats()
+	log.Printf("Checked %d SCTs in %d certificates: %d verified, %d from unknown logs, %d with invalid signatures, %d uncheckable",
+		st.SCTs, st.Certs, st.Verified, st.UnknownLog, st.InvalidSignature, st.Uncheckable)
+	return err
+}
+
+// reportUnmatched logs the matched precertificates without a matched final
+// certificate, if deduplicating.
+func reportUnmatched(d *scanner.Deduplicator) {
+	if d == nil {
+		return
+	}
+	unmatched := d.UnmatchedPrecerts()
+	log.Printf("Suppressed %d duplicates; %d precertificates have no final certificate", d.Duplicates(), len(unmatched))
+	for _, ref := range unmatched {
+		log.Printf("Unmatched precert at index %d of %s", ref.Index, ref.LogURI)
+	}
 }
@@ -24,6 +24,7 @@ import (
 
 	ct "github.com/RarimoVoting/certificate-transparency-go"
 	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/google/trillian/monitoring"
 	"k8s.io/klog/v2"
 )
 
@@ -42,8 +43,28 @@ type ScannerOptions struct { // nolint:revive
 	// Number of concurrent matchers to run.
 	NumWorkers int
 
-	// Number of fetched entries to buffer on their way to the callbacks.
+	// Number of fetched entries to buffer on their way to the callbacks, in
+	// each of the queues between the stages of the scan.
 	BufferSize int
+
+	// Maximum total size in bytes of the fetched entries which have not been
+	// processed yet, or 0 for no limit beyond BufferSize. Fetching pauses
+	// while the limit is reached, so that the memory used by a scan with slow
+	// matchers or callbacks stays bounded.
+	MaxBufferedBytes int64
+
+	// MetricFactory is used to export the depths of the scan's queues.
+	// Defaults to monitoring.InertMetricFactory.
+	MetricFactory monitoring.MetricFactory
+
+	// Store for the progress of the scan. If set, the scan resumes from the
+	// checkpoint for the log, if it is past StartIndex, and records a new
+	// checkpoint each time a further prefix of the log has been processed.
+	Checkpoints CheckpointStore
+
+	// Deduplicator, if set, suppresses the second of each matched pair of a
+	// precertificate and its final certificate.
+	Deduplicator *Deduplicator
 }
 
 // DefaultScannerOptions returns a new ScannerOptions with sensible defaults.
@@ -71,8 +92,17 @@ type Scanner struct {
 	unparsableEntries         int64
 	entriesWithNonFatalErrors int64
 
+	// Total size of the entries in the pipeline of the current scan.
+	bufferedBytes int64
+
 	fetcher *Fetcher
 
+	// Queues of the current scan, if one is running.
+	queues atomic.Pointer[pipelineQueues]
+
+	// Progress of the current scan, if it is being checkpointed.
+	progress *progress
+
 	// Configuration options for this Scanner instance.
 	opts ScannerOptions
 }
@@ -83,6 +113,8 @@ type entryInfo struct {
 	index int64
 	// The log entry returned by the log server.
 	entry ct.LeafEntry
+	// The batch the entry was fetched in, if the scan is being checkpointed.
+	batch *pendingBatch
 }
 
 // Takes the error returned by either x509.ParseCertificate() or
@@ -106,29 +138,25 @@ func (s *Scanner) isCertErrorFatal(err error, logEntry *ct.LogEntry, index int64
 }
 
 // Processes the given entry in the specified log.
-func (s *Scanner) processEntry(info entryInfo, foundCert func(*ct.RawLogEntry), foundPrecert func(*ct.RawLogEntry)) error {
+func (s *Scanner) processEntry(p parsedEntry, foundCert func(*ct.RawLogEntry), foundPrecert func(*ct.RawLogEntry)) error {
 	atomic.AddInt64(&s.certsProcessed, 1)
+	if p.err != nil {
+		return p.err
+	}
 
 	switch matcher := s.opts.Matcher.(type) {
 	case Matcher:
-		return s.processMatcherEntry(matcher, info, foundCert, foundPrecert)
+		return s.processMatcherEntry(matcher, p, foundCert, foundPrecert)
 	case LeafMatcher:
-		return s.processMatcherLeafEntry(matcher, info, foundCert, foundPrecert)
+		return s.processMatcherLeafEntry(matcher, p.entryInfo, foundCert, foundPrecert)
 	default:
 		return fmt.Errorf("unexpected matcher type %T", matcher)
 	}
 }
 
-func (s *Scanner) processMatcherEntry(matcher Matcher, info entryInfo, foundCert func(*ct.RawLogEntry), foundPrecert func(*ct.RawLogEntry)) error {
-	rawLogEntry, err := ct.RawLogEntryFromLeaf(info.index, &info.entry)
-	if err != nil {
-		return fmt.Errorf("failed to build raw log entry %d: %v", info.index, err)
-	}
-	// Matcher instances need the parsed [pre-]certificate.
-	logEntry, err := rawLogEntry.ToLogEntry()
-	if s.isCertErrorFatal(err, logEntry, info.index) {
-		return fmt.Errorf("failed to parse [pre-]certificate in MerkleTreeLeaf[%d]: %v", info.index, err)
-	}
+func (s *Scanner) processMatcherEntry(matcher Matcher, p parsedEntry, foundCert func(*ct.RawLogEntry), foundPrecert func(*ct.RawLogEntry)) error {
+	// Matcher instances need the [pre-]certificate parsed by parseJob.
+	rawLogEntry, logEntry := p.raw, p.parsed
 
 	switch {
 	case logEntry.X509Cert != nil:
@@ -178,15 +206,54 @@ func (s *Scanner) processMatcherLeafEntry(matcher LeafMatcher, info entryInfo, f
 }
 
 // Worker function to match certs.
-// Accepts MatcherJobs over the entries channel, and processes them.
-// Returns true over the done channel when the entries channel is closed.
-func (s *Scanner) matcherJob(entries <-chan entryInfo, foundCert func(*ct.RawLogEntry), foundPrecert func(*ct.RawLogEntry)) {
+// Accepts parsed entries over the entries channel, and processes them.
+// Returns when the entries channel is closed. Releases the size of each
+// entry from budget, if set.
+func (s *Scanner) matcherJob(entries <-chan parsedEntry, budget *byteBudget, foundCert func(*ct.RawLogEntry), foundPrecert func(*ct.RawLogEntry)) {
 	for e := range entries {
 		if err := s.processEntry(e, foundCert, foundPrecert); err != nil {
 			atomic.AddInt64(&s.unparsableEntries, 1)
 			klog.Errorf("Failed to parse entry at index %d: %s", e.index, err.Error())
 		}
+		size := entrySize(&e.entry)
+		atomic.AddInt64(&s.bufferedBytes, -size)
+		if budget != nil {
+			budget.release(size)
+		}
+		if e.batch != nil && atomic.AddInt64(&e.batch.remaining, -1) == 0 {
+			s.completeBatch(e.batch)
+		}
+	}
+}
+
+// completeBatch records that all the entries of a batch have been processed.
+// Failures to save a checkpoint are logged, but do not stop the scan.
+func (s *Scanner) completeBatch(b *pendingBatch) {
+	if err := s.progress.complete(b.start, b.end); err != nil {
+		klog.Warningf("%s: Failed to save checkpoint: %v", s.fetcher.uri, err)
+	}
+}
+
+// resume sets up checkpointing of the scan, moving StartIndex up to the
+// stored checkpoint for the log, if any.
+func (s *Scanner) resume(ctx context.Context) error {
+	uri := s.fetcher.uri
+	cp, err := s.opts.Checkpoints.Load(ctx, uri)
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint for %s: %v", uri, err)
+	}
+	if cp != nil && cp.NextIndex > s.opts.StartIndex {
+		klog.V(1).Infof("%s: Resuming scan from index %d", uri, cp.NextIndex)
+		s.opts.StartIndex = cp.NextIndex
 	}
+	s.progress = &progress{
+		store: s.opts.Checkpoints,
+		uri:   uri,
+		sth:   s.fetcher.currentSTH,
+		next:  s.opts.StartIndex,
+		done:  make(map[int64]int64),
+	}
+	return nil
 }
 
 // Pretty prints the passed in duration into a human readable string.
@@ -222,6 +289,7 @@ func (s *Scanner) logThroughput(treeSize int64, stop <-chan bool) {
 		case <-stop:
 			return
 		case <-ticker.C:
+			s.exportQueueDepths()
 			certsCnt := atomic.LoadInt64(&s.certsProcessed)
 			certsMatched := atomic.LoadInt64(&s.certsMatched)
 
@@ -259,11 +327,22 @@ func (s *Scanner) Scan(ctx context.Context, foundCert func(*ct.RawLogEntry), fou
 // ScanLog performs a scan against the Log, returning the count of scanned entries.
 func (s *Scanner) ScanLog(ctx context.Context, foundCert func(*ct.RawLogEntry), foundPrecert func(*ct.RawLogEntry)) (int64, error) {
 	klog.V(1).Infof("Starting up Scanner...")
-	s.certsProcessed = 0
-	s.certsMatched = 0
-	s.precertsSeen = 0
-	s.unparsableEntries = 0
-	s.entriesWithNonFatalErrors = 0
+	atomic.StoreInt64(&s.certsProcessed, 0)
+	atomic.StoreInt64(&s.certsMatched, 0)
+	atomic.StoreInt64(&s.precertsSeen, 0)
+	atomic.StoreInt64(&s.unparsableEntries, 0)
+	atomic.StoreInt64(&s.entriesWithNonFatalErrors, 0)
+
+	if s.opts.Deduplicator != nil {
+		foundCert, foundPrecert = s.opts.Deduplicator.wrap(s.fetcher.uri, foundCert, foundPrecert)
+	}
+
+	s.progress = nil
+	if s.opts.Checkpoints != nil {
+		if err := s.resume(ctx); err != nil {
+			return -1, err
+		}
+	}
 
 	sth, err := s.fetcher.Prepare(ctx)
 	if err != nil {
@@ -278,27 +357,61 @@ func (s *Scanner) ScanLog(ctx context.Context, foundCert func(*ct.RawLogEntry),
 		close(stop)
 	}()
 
-	// Start matcher workers.
-	var wg sync.WaitGroup
-	entries := make(chan entryInfo, s.opts.BufferSize)
+	// Start parser and matcher workers.
+	q := &pipelineQueues{
+		fetched: make(chan entryInfo, s.opts.BufferSize),
+		parsed:  make(chan parsedEntry, s.opts.BufferSize),
+	}
+	s.queues.Store(q)
+	defer s.queues.Store(nil)
+	atomic.StoreInt64(&s.bufferedBytes, 0)
+	var budget *byteBudget
+	if s.opts.MaxBufferedBytes > 0 {
+		budget = newByteBudget(s.opts.MaxBufferedBytes)
+	}
+
+	var parsers, matchers sync.WaitGroup
 	for w, cnt := 0, s.opts.NumWorkers; w < cnt; w++ {
-		wg.Add(1)
+		parsers.Add(1)
 		go func(idx int) {
-			defer wg.Done()
+			defer parsers.Done()
+			klog.V(1).Infof("Parser %d starting", idx)
+			s.parseJob(q.fetched, q.parsed)
+			klog.V(1).Infof("Parser %d finished", idx)
+		}(w)
+		matchers.Add(1)
+		go func(idx int) {
+			defer matchers.Done()
 			klog.V(1).Infof("Matcher %d starting", idx)
-			s.matcherJob(entries, foundCert, foundPrecert)
+			s.matcherJob(q.parsed, budget, foundCert, foundPrecert)
 			klog.V(1).Infof("Matcher %d finished", idx)
 		}(w)
 	}
+	go func() {
+		parsers.Wait()
+		close(q.parsed) // Causes matcher workers to terminate.
+	}()
 
 	flatten := func(b EntryBatch) {
+		var batch *pendingBatch
+		if s.progress != nil {
+			batch = &pendingBatch{start: b.Start, end: b.Start + int64(len(b.Entries)), remaining: int64(len(b.Entries))}
+			if len(b.Entries) == 0 {
+				s.completeBatch(batch)
+			}
+		}
 		for i, e := range b.Entries {
-			entries <- entryInfo{index: b.Start + int64(i), entry: e}
+			size := entrySize(&e)
+			if budget != nil {
+				budget.acquire(size)
+			}
+			atomic.AddInt64(&s.bufferedBytes, size)
+			q.fetched <- entryInfo{index: b.Start + int64(i), entry: e, batch: batch}
 		}
 	}
 	err = s.fetcher.Run(ctx, flatten)
-	close(entries) // Causes matcher workers to terminate.
-	wg.Wait()      // Wait until they terminate.
+	close(q.fetched) // Causes parser workers, and then matchers, to terminate.
+	matchers.Wait()  // Wait until they terminate.
 	if err != nil {
 		return -1, err
 	}
@@ -318,6 +431,12 @@ func NewScanner(client LogClient, opts ScannerOptions) *Scanner {
 	scanner.opts = opts
 	scanner.fetcher = NewFetcher(client, &scanner.opts.FetcherOptions)
 
+	mf := opts.MetricFactory
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	pipelineOnce.Do(func() { setupPipelineMetrics(mf) })
+
 	// Set a default match-everything regex if none was provided.
 	if opts.Matcher == nil {
 		opts.Matcher = &MatchAll{}
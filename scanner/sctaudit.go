@@ -0,0 +1,201 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+)
+
+// SCTProblem is the kind of problem found with an embedded SCT.
+type SCTProblem int
+
+// Kinds of SCTProblem.
+const (
+	// The SCT could not be checked, e.g. because it is malformed or the
+	// issuer of the certificate is unavailable.
+	SCTUncheckable SCTProblem = iota
+	// The SCT is from a log which is not in the log list.
+	SCTUnknownLog
+	// The signature of the SCT does not verify with the key of its log.
+	SCTInvalidSignature
+)
+
+func (p SCTProblem) String() string {
+	switch p {
+	case SCTUncheckable:
+		return "uncheckable"
+	case SCTUnknownLog:
+		return "unknown log"
+	case SCTInvalidSignature:
+		return "invalid signature"
+	}
+	return fmt.Sprintf("SCTProblem(%d)", int(p))
+}
+
+// SCTFinding reports a problem with an SCT embedded in a certificate.
+type SCTFinding struct {
+	// The entry holding the certificate.
+	Entry *ct.RawLogEntry
+	// Position of the SCT in the certificate's SCT list, or -1 if the problem
+	// affects all of them.
+	SCTIndex int
+	// LogID of the SCT, if it could be parsed.
+	LogID ct.SHA256Hash
+	// Log is the log which issued the SCT, if it is in the log list.
+	Log     *loglist3.Log
+	Problem SCTProblem
+	Err     error
+}
+
+// SCTAuditStats counts the results of an SCTAuditor.
+type SCTAuditStats struct {
+	Certs            int64 // Certificates checked.
+	SCTs             int64 // SCTs checked.
+	Verified         int64 // SCTs with a valid signature from a known log.
+	UnknownLog       int64
+	InvalidSignature int64
+	Uncheckable      int64
+}
+
+// auditedLog is a log of the log list, with a verifier for its signatures.
+type auditedLog struct {
+	log      *loglist3.Log
+	verifier *ct.SignatureVerifier
+}
+
+// SCTAuditor verifies the SCTs embedded in the certificates found by a scan
+// against the keys of the logs in a log list, reporting SCTs from unknown
+// logs or with invalid signatures. It is a Matcher for the certificates with
+// embedded SCTs, and its Audit method is the callback for them:
+//
+//	opts.Matcher = auditor
+//	s.Scan(ctx, auditor.Audit, func(*ct.RawLogEntry) {})
+type SCTAuditor struct {
+	logs   map[[sha256.Size]byte]*auditedLog
+	report func(*SCTFinding)
+
+	certs, scts, verified, unknownLog, invalidSignature, uncheckable int64
+}
+
+// NewSCTAuditor creates an SCTAuditor which knows the logs of ll, whatever
+// their state, and calls report for each problem found. The report function
+// must be safe for concurrent use.
+func NewSCTAuditor(ll *loglist3.LogList, report func(*SCTFinding)) (*SCTAuditor, error) {
+	a := &SCTAuditor{logs: make(map[[sha256.Size]byte]*auditedLog), report: report}
+	for _, op := range ll.Operators {
+		for _, log := range op.Logs {
+			pubKey, err := x509.ParsePKIXPublicKey(log.Key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse key of log %q: %v", log.Description, err)
+			}
+			verifier, err := ct.NewSignatureVerifier(pubKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create verifier for log %q: %v", log.Description, err)
+			}
+			a.logs[sha256.Sum256(log.Key)] = &auditedLog{log: log, verifier: verifier}
+		}
+	}
+	return a, nil
+}
+
+// CertificateMatches matches the certificates with embedded SCTs.
+func (a *SCTAuditor) CertificateMatches(cert *x509.Certificate) bool {
+	return len(cert.SCTList.SCTList) > 0
+}
+
+// PrecertificateMatches matches no precertificates, which hold no SCTs.
+func (a *SCTAuditor) PrecertificateMatches(*ct.Precertificate) bool {
+	return false
+}
+
+// Audit checks the SCTs embedded in the certificate of entry, whose chain
+// must start with its issuer, and reports the problems found.
+func (a *SCTAuditor) Audit(entry *ct.RawLogEntry) {
+	atomic.AddInt64(&a.certs, 1)
+	fail := func(sctIndex int, logID ct.SHA256Hash, log *loglist3.Log, problem SCTProblem, err error) {
+		switch problem {
+		case SCTUnknownLog:
+			atomic.AddInt64(&a.unknownLog, 1)
+		case SCTInvalidSignature:
+			atomic.AddInt64(&a.invalidSignature, 1)
+		default:
+			atomic.AddInt64(&a.uncheckable, 1)
+		}
+		if a.report != nil {
+			a.report(&SCTFinding{Entry: entry, SCTIndex: sctIndex, LogID: logID, Log: log, Problem: problem, Err: err})
+		}
+	}
+
+	cert, err := x509.ParseCertificate(entry.Cert.Data)
+	if x509.IsFatal(err) {
+		fail(-1, ct.SHA256Hash{}, nil, SCTUncheckable, fmt.Errorf("failed to parse certificate: %v", err))
+		return
+	}
+	if len(entry.Chain) == 0 {
+		fail(-1, ct.SHA256Hash{}, nil, SCTUncheckable, errors.New("no issuer in chain"))
+		return
+	}
+	issuer, err := x509.ParseCertificate(entry.Chain[0].Data)
+	if x509.IsFatal(err) {
+		fail(-1, ct.SHA256Hash{}, nil, SCTUncheckable, fmt.Errorf("failed to parse issuer: %v", err))
+		return
+	}
+	// The same leaf serves for all the SCTs, once its timestamp is set.
+	leaf, err := ct.MerkleTreeLeafForEmbeddedSCT([]*x509.Certificate{cert, issuer}, 0)
+	if err != nil {
+		fail(-1, ct.SHA256Hash{}, nil, SCTUncheckable, fmt.Errorf("failed to build Merkle leaf: %v", err))
+		return
+	}
+
+	for i := range cert.SCTList.SCTList {
+		atomic.AddInt64(&a.scts, 1)
+		sct, err := x509util.ExtractSCT(&cert.SCTList.SCTList[i])
+		if err != nil {
+			fail(i, ct.SHA256Hash{}, nil, SCTUncheckable, fmt.Errorf("failed to parse SCT: %v", err))
+			continue
+		}
+		log := a.logs[sct.LogID.KeyID]
+		if log == nil {
+			fail(i, sct.LogID.KeyID, nil, SCTUnknownLog, fmt.Errorf("no log with ID %x", sct.LogID.KeyID[:]))
+			continue
+		}
+		leaf.TimestampedEntry.Timestamp = sct.Timestamp
+		if err := log.verifier.VerifySCTSignature(*sct, ct.LogEntry{Leaf: *leaf}); err != nil {
+			fail(i, sct.LogID.KeyID, log.log, SCTInvalidSignature, err)
+			continue
+		}
+		atomic.AddInt64(&a.verified, 1)
+	}
+}
+
+// Stats returns the counts of the SCTs audited so far.
+func (a *SCTAuditor) Stats() SCTAuditStats {
+	return SCTAuditStats{
+		Certs:            atomic.LoadInt64(&a.certs),
+		SCTs:             atomic.LoadInt64(&a.scts),
+		Verified:         atomic.LoadInt64(&a.verified),
+		UnknownLog:       atomic.LoadInt64(&a.unknownLog),
+		InvalidSignature: atomic.LoadInt64(&a.invalidSignature),
+		Uncheckable:      atomic.LoadInt64(&a.uncheckable),
+	}
+}
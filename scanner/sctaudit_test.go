@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"encoding/base64"
+	"sync"
+	"testing"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+)
+
+func TestSCTAuditor(t *testing.T) {
+	logKey, err := base64.StdEncoding.DecodeString(testdata.LogPublicKeyB64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testLog := &loglist3.Log{Description: "Test Log", Key: logKey}
+	withLog := &loglist3.LogList{Operators: []*loglist3.Operator{{Name: "Test", Logs: []*loglist3.Log{testLog}}}}
+
+	for _, test := range []struct {
+		desc      string
+		ll        *loglist3.LogList
+		certPEM   string
+		noIssuer  bool
+		wantStats SCTAuditStats
+		wantProbs []SCTProblem
+	}{
+		{
+			desc:      "valid",
+			ll:        withLog,
+			certPEM:   testdata.TestEmbeddedCertPEM,
+			wantStats: SCTAuditStats{Certs: 1, SCTs: 1, Verified: 1},
+		},
+		{
+			desc:      "invalid-signature",
+			ll:        withLog,
+			certPEM:   testdata.TestInvalidEmbeddedCertPEM,
+			wantStats: SCTAuditStats{Certs: 1, SCTs: 1, InvalidSignature: 1},
+			wantProbs: []SCTProblem{SCTInvalidSignature},
+		},
+		{
+			desc:      "unknown-log",
+			ll:        &loglist3.LogList{},
+			certPEM:   testdata.TestEmbeddedCertPEM,
+			wantStats: SCTAuditStats{Certs: 1, SCTs: 1, UnknownLog: 1},
+			wantProbs: []SCTProblem{SCTUnknownLog},
+		},
+		{
+			desc:      "no-issuer",
+			ll:        withLog,
+			certPEM:   testdata.TestEmbeddedCertPEM,
+			noIssuer:  true,
+			wantStats: SCTAuditStats{Certs: 1, Uncheckable: 1},
+			wantProbs: []SCTProblem{SCTUncheckable},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			var mu sync.Mutex
+			var probs []SCTProblem
+			a, err := NewSCTAuditor(test.ll, func(f *SCTFinding) {
+				mu.Lock()
+				defer mu.Unlock()
+				probs = append(probs, f.Problem)
+				if f.Problem == SCTInvalidSignature && f.Log != testLog {
+					t.Errorf("finding for log %v, want %v", f.Log, testLog)
+				}
+			})
+			if err != nil {
+				t.Fatalf("NewSCTAuditor()=%v", err)
+			}
+
+			chain, err := x509util.CertificatesFromPEM([]byte(test.certPEM + testdata.CACertPEM))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !a.CertificateMatches(chain[0]) {
+				t.Error("CertificateMatches()=false, want true")
+			}
+			entry := &ct.RawLogEntry{Cert: ct.ASN1Cert{Data: chain[0].Raw}}
+			if !test.noIssuer {
+				entry.Chain = []ct.ASN1Cert{{Data: chain[1].Raw}}
+			}
+			a.Audit(entry)
+
+			if got := a.Stats(); got != test.wantStats {
+				t.Errorf("Stats()=%+v, want %+v", got, test.wantStats)
+			}
+			if len(probs) != len(test.wantProbs) {
+				t.Fatalf("reported %v, want %v", probs, test.wantProbs)
+			}
+			for i := range probs {
+				if probs[i] != test.wantProbs[i] {
+					t.Errorf("reported %v, want %v", probs, test.wantProbs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// SinkRecord is the form in which a Sink outputs a matched log entry.
+type SinkRecord struct {
+	LogURI    string `json:"log_uri"`
+	Index     int64  `json:"index"`
+	Timestamp uint64 `json:"timestamp"`
+	Precert   bool   `json:"precert"`
+	// DER of the certificate or precertificate, and of its chain.
+	Cert  []byte   `json:"cert"`
+	Chain [][]byte `json:"chain,omitempty"`
+
+	// Fields of the parsed [pre-]certificate, if it could be parsed.
+	Subject   string    `json:"subject,omitempty"`
+	Issuer    string    `json:"issuer,omitempty"`
+	Serial    string    `json:"serial,omitempty"`
+	NotBefore time.Time `json:"not_before,omitempty"`
+	NotAfter  time.Time `json:"not_after,omitempty"`
+	DNSNames  []string  `json:"dns_names,omitempty"`
+}
+
+// NewSinkRecord builds the SinkRecord for an entry of the log at logURI.
+func NewSinkRecord(logURI string, entry *ct.RawLogEntry) *SinkRecord {
+	rec := &SinkRecord{
+		LogURI:    logURI,
+		Index:     entry.Index,
+		Timestamp: entry.Leaf.TimestampedEntry.Timestamp,
+		Precert:   entry.Leaf.TimestampedEntry.EntryType == ct.PrecertLogEntryType,
+		Cert:      entry.Cert.Data,
+	}
+	for _, c := range entry.Chain {
+		rec.Chain = append(rec.Chain, c.Data)
+	}
+
+	var cert *x509.Certificate
+	if logEntry, err := entry.ToLogEntry(); !x509.IsFatal(err) && logEntry != nil {
+		if logEntry.X509Cert != nil {
+			cert = logEntry.X509Cert
+		} else if logEntry.Precert != nil {
+			cert = logEntry.Precert.TBSCertificate
+		}
+	}
+	if cert != nil {
+		rec.Subject = cert.Subject.String()
+		rec.Issuer = cert.Issuer.String()
+		rec.Serial = cert.SerialNumber.Text(16)
+		rec.NotBefore = cert.NotBefore
+		rec.NotAfter = cert.NotAfter
+		rec.DNSNames = cert.DNSNames
+	}
+	return rec
+}
+
+// Sink receives the entries matched by a scan, e.g. to pass them on to a data
+// pipeline. Implementations must be safe for concurrent use.
+type Sink interface {
+	// Put outputs a record. Implementations may buffer records until Close.
+	Put(ctx context.Context, rec *SinkRecord) error
+	// Close outputs any buffered records and releases the Sink's resources.
+	Close(ctx context.Context) error
+}
+
+// ScanToSink performs a scan against the Log, like ScanLog, passing each
+// matched entry to sink. The scan stops at the first error from the sink,
+// which is returned. The sink is not closed.
+func (s *Scanner) ScanToSink(ctx context.Context, sink Sink) error {
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var once sync.Once
+	var sinkErr error
+	put := func(entry *ct.RawLogEntry) {
+		if err := sink.Put(cctx, NewSinkRecord(s.fetcher.uri, entry)); err != nil {
+			once.Do(func() {
+				sinkErr = fmt.Errorf("failed to output entry %d: %v", entry.Index, err)
+				cancel()
+			})
+		}
+	}
+	_, err := s.ScanLog(cctx, put, put)
+	// Matcher workers have finished by the time ScanLog returns.
+	if sinkErr != nil {
+		return sinkErr
+	}
+	return err
+}
+
+// JSONLinesSink is a Sink which writes each record as a line of JSON.
+type JSONLinesSink struct {
+	mu  sync.Mutex
+	w   *bufio.Writer
+	out io.Writer
+}
+
+// NewJSONLinesSink creates a JSONLinesSink writing to w. If w is an
+// io.Closer, it is closed when the sink is.
+func NewJSONLinesSink(w io.Writer) *JSONLinesSink {
+	return &JSONLinesSink{w: bufio.NewWriter(w), out: w}
+}
+
+// Put implements Sink.
+func (s *JSONLinesSink) Put(_ context.Context, rec *SinkRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+// Close implements Sink.
+func (s *JSONLinesSink) Close(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.w.Flush()
+	if c, ok := s.out.(io.Closer); ok {
+		if closeErr := c.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// KafkaProducer sends messages to a Kafka topic. It is satisfied by a thin
+// wrapper around the producer of any Kafka client library.
+type KafkaProducer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+	Close() error
+}
+
+// KafkaSink is a Sink which sends each record as a JSON message to a Kafka
+// topic, keyed by the log URI and entry index.
+type KafkaSink struct {
+	producer KafkaProducer
+	topic    string
+}
+
+// NewKafkaSink creates a KafkaSink sending to topic with producer, which is
+// closed when the sink is.
+func NewKafkaSink(producer KafkaProducer, topic string) *KafkaSink {
+	return &KafkaSink{producer: producer, topic: topic}
+}
+
+// Put implements Sink.
+func (s *KafkaSink) Put(ctx context.Context, rec *SinkRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	key := rec.LogURI + "/" + strconv.FormatInt(rec.Index, 10)
+	return s.producer.Produce(ctx, s.topic, []byte(key), data)
+}
+
+// Close implements Sink.
+func (s *KafkaSink) Close(_ context.Context) error {
+	return s.producer.Close()
+}
+
+// ObjectUploader stores objects in a bucket of an object store.
+type ObjectUploader interface {
+	Upload(ctx context.Context, name string, data []byte) error
+}
+
+// ObjectSink is a Sink which batches records into objects of newline-
+// delimited JSON, uploaded to an object store such as S3 or GCS. Objects are
+// named with a prefix followed by a sequence number.
+type ObjectSink struct {
+	uploader  ObjectUploader
+	prefix    string
+	batchSize int
+
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	count int
+	seq   int
+}
+
+// NewObjectSink creates an ObjectSink which uploads objects of up to
+// batchSize records with uploader.
+func NewObjectSink(uploader ObjectUploader, prefix string, batchSize int) *ObjectSink {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	return &ObjectSink{uploader: uploader, prefix: prefix, batchSize: batchSize}
+}
+
+// Put implements Sink.
+func (s *ObjectSink) Put(ctx context.Context, rec *SinkRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf.Write(data)
+	s.buf.WriteByte('\n')
+	if s.count++; s.count < s.batchSize {
+		return nil
+	}
+	return s.flush(ctx)
+}
+
+// flush uploads the buffered records, if any. Must be called with mu held.
+func (s *ObjectSink) flush(ctx context.Context) error {
+	if s.count == 0 {
+		return nil
+	}
+	name := fmt.Sprintf("%s%08d.jsonl", s.prefix, s.seq)
+	if err := s.uploader.Upload(ctx, name, s.buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to upload %s: %v", name, err)
+	}
+	s.buf.Reset()
+	s.count = 0
+	s.seq++
+	return nil
+}
+
+// Close implements Sink, uploading any remaining records.
+func (s *ObjectSink) Close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush(ctx)
+}
+
+// S3Uploader is an ObjectUploader for a bucket of Amazon S3.
+type S3Uploader struct {
+	Uploader *s3manager.Uploader
+	Bucket   string
+}
+
+// Upload implements ObjectUploader.
+func (u *S3Uploader) Upload(ctx context.Context, name string, data []byte) error {
+	_, err := u.Uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(u.Bucket),
+		Key:         aws.String(name),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/jsonl"),
+	})
+	return err
+}
+
+// GCSUploader is an ObjectUploader for a bucket of Google Cloud Storage,
+// using its JSON API. Client must add credentials to its requests, e.g. a
+// client from golang.org/x/oauth2/google.
+type GCSUploader struct {
+	Client *http.Client
+	Bucket string
+}
+
+// Upload implements ObjectUploader.
+func (u *GCSUploader) Upload(ctx context.Context, name string, data []byte) error {
+	uri := fmt.Sprintf("https://storage.googleapis.com/upload/storage/v1/b/%s/o?uploadType=media&name=%s", url.PathEscape(u.Bucket), url.QueryEscape(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/jsonl")
+	rsp, err := u.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(rsp.Body, 1024))
+		return fmt.Errorf("got HTTP status %q: %s", rsp.Status, body)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+)
+
+type fakeUploader struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (u *fakeUploader) Upload(_ context.Context, name string, data []byte) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.objects[name] = append([]byte(nil), data...)
+	return nil
+}
+
+type failingSink struct{}
+
+func (failingSink) Put(context.Context, *SinkRecord) error { return errors.New("sink full") }
+func (failingSink) Close(context.Context) error            { return nil }
+
+func newSinkTestScanner(t *testing.T) *Scanner {
+	t.Helper()
+	ts := serveFourEntries(t)
+	t.Cleanup(ts.Close)
+	logClient, err := client.New(ts.URL, &http.Client{}, jsonclient.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := ScannerOptions{
+		FetcherOptions: FetcherOptions{BatchSize: 2, ParallelFetch: 1},
+		Matcher:        &MatchAll{},
+		NumWorkers:     2,
+	}
+	return NewScanner(logClient, opts)
+}
+
+func TestJSONLinesSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONLinesSink(&buf)
+	if err := newSinkTestScanner(t).ScanToSink(context.Background(), sink); err != nil {
+		t.Fatalf("ScanToSink()=%v", err)
+	}
+	if err := sink.Close(context.Background()); err != nil {
+		t.Fatalf("Close()=%v", err)
+	}
+
+	var indices []int
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var rec SinkRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatalf("Failed to parse record %q: %v", sc.Text(), err)
+		}
+		if len(rec.Cert) == 0 || rec.Subject == "" {
+			t.Errorf("Record %d has no certificate details: %+v", rec.Index, rec)
+		}
+		indices = append(indices, int(rec.Index))
+	}
+	sort.Ints(indices)
+	if got, want := indices, []int{0, 1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Sink got records for indices %v, want %v", got, want)
+	}
+}
+
+func TestObjectSink(t *testing.T) {
+	uploader := &fakeUploader{objects: make(map[string][]byte)}
+	sink := NewObjectSink(uploader, "scan/", 3)
+	if err := newSinkTestScanner(t).ScanToSink(context.Background(), sink); err != nil {
+		t.Fatalf("ScanToSink()=%v", err)
+	}
+	if err := sink.Close(context.Background()); err != nil {
+		t.Fatalf("Close()=%v", err)
+	}
+	for name, wantLines := range map[string]int{"scan/00000000.jsonl": 3, "scan/00000001.jsonl": 1} {
+		if got := strings.Count(string(uploader.objects[name]), "\n"); got != wantLines {
+			t.Errorf("Object %s has %d records, want %d", name, got, wantLines)
+		}
+	}
+	if len(uploader.objects) != 2 {
+		t.Errorf("Uploaded %d objects, want 2", len(uploader.objects))
+	}
+}
+
+func TestScanToSinkError(t *testing.T) {
+	err := newSinkTestScanner(t).ScanToSink(context.Background(), failingSink{})
+	if err == nil || !strings.Contains(err.Error(), "sink full") {
+		t.Errorf("ScanToSink()=%v, want error from sink", err)
+	}
+}
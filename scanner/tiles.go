@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"k8s.io/klog/v2"
+)
+
+// TileClient is a LogClient which reads a log through the static-ct-api
+// (https://c2sp.org/static-ct-api) if the log serves it, and otherwise falls
+// back to the RFC 6962 API. Tiles are immutable, so fetches from them are
+// both faster and cacheable; a BatchSize which is a multiple of the tile
+// width of 256 entries avoids fetching a tile twice.
+//
+// Whether the log serves tiles is decided by the first call to GetSTH: if
+// the checkpoint cannot be fetched, all further requests go to the RFC 6962
+// API. Otherwise, entries whose data tile is missing are still fetched from
+// the RFC 6962 API.
+type TileClient struct {
+	tiles    LogClient
+	fallback LogClient
+
+	mu sync.Mutex
+	// Whether the log serves tiles, once known.
+	decided  bool
+	useTiles bool
+}
+
+// NewTileClient creates a TileClient which reads tiles with tiles, usually a
+// client.StaticLogClient for the log's monitoring prefix, and falls back to
+// fallback, usually a client.LogClient.
+func NewTileClient(tiles, fallback LogClient) *TileClient {
+	return &TileClient{tiles: tiles, fallback: fallback}
+}
+
+// BaseURI returns the base URI of the RFC 6962 API of the log, which
+// identifies it.
+func (c *TileClient) BaseURI() string {
+	return c.fallback.BaseURI()
+}
+
+// UsesTiles reports whether the client reads the log's tiles, which is known
+// after the first call to GetSTH.
+func (c *TileClient) UsesTiles() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.useTiles
+}
+
+// GetSTH returns the log's checkpoint as an STH, or its RFC 6962 STH if the
+// log does not serve tiles.
+func (c *TileClient) GetSTH(ctx context.Context) (*ct.SignedTreeHead, error) {
+	c.mu.Lock()
+	decided, useTiles := c.decided, c.useTiles
+	c.mu.Unlock()
+	if decided && !useTiles {
+		return c.fallback.GetSTH(ctx)
+	}
+
+	sth, err := c.tiles.GetSTH(ctx)
+	if decided {
+		return sth, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.decided = true
+	if err != nil {
+		klog.Infof("%s: Log does not serve tiles, falling back to get-entries: %v", c.fallback.BaseURI(), err)
+		return c.fallback.GetSTH(ctx)
+	}
+	klog.V(1).Infof("%s: Fetching entries from tiles at %s", c.fallback.BaseURI(), c.tiles.BaseURI())
+	c.useTiles = true
+	return sth, nil
+}
+
+// GetRawEntries returns the entries in the range [start, end], from the data
+// tiles if the log serves them.
+func (c *TileClient) GetRawEntries(ctx context.Context, start, end int64) (*ct.GetEntriesResponse, error) {
+	if !c.UsesTiles() {
+		return c.fallback.GetRawEntries(ctx, start, end)
+	}
+	rsp, err := c.tiles.GetRawEntries(ctx, start, end)
+	var rspErr jsonclient.RspError
+	if errors.As(err, &rspErr) && rspErr.StatusCode == http.StatusNotFound {
+		klog.Warningf("%s: Missing tile for entries [%d, %d], falling back to get-entries: %v", c.fallback.BaseURI(), start, end, err)
+		return c.fallback.GetRawEntries(ctx, start, end)
+	}
+	return rsp, err
+}
+
+// GetSTHConsistency returns a consistency proof from the tiles if the log
+// serves them, so that it matches the checkpoints returned by GetSTH.
+func (c *TileClient) GetSTHConsistency(ctx context.Context, first, second uint64) ([][]byte, error) {
+	client := c.fallback
+	if c.UsesTiles() {
+		client = c.tiles
+	}
+	cc, ok := client.(ConsistencyClient)
+	if !ok {
+		return nil, fmt.Errorf("%T cannot get consistency proofs", client)
+	}
+	return cc.GetSTHConsistency(ctx, first, second)
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+)
+
+// missingTiles is a tile LogClient for a log which serves no tiles, or is
+// missing the tiles holding the entries from missingFrom onwards.
+type missingTiles struct {
+	*treeClient
+	noCheckpoint bool
+	missingFrom  int64
+}
+
+func (c *missingTiles) GetSTH(ctx context.Context) (*ct.SignedTreeHead, error) {
+	if c.noCheckpoint {
+		return nil, jsonclient.RspError{StatusCode: http.StatusNotFound, Err: errors.New("no checkpoint")}
+	}
+	return c.treeClient.GetSTH(ctx)
+}
+
+func (c *missingTiles) GetRawEntries(ctx context.Context, start, end int64) (*ct.GetEntriesResponse, error) {
+	if end >= c.missingFrom {
+		return nil, jsonclient.RspError{StatusCode: http.StatusNotFound, Err: errors.New("no tile")}
+	}
+	return c.treeClient.GetRawEntries(ctx, start, end)
+}
+
+func TestTileClient(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		desc         string
+		noCheckpoint bool
+		missingFrom  int64
+		wantTiles    bool
+		wantFrom     []string // The client each entry should come from.
+	}{
+		{desc: "tiles", missingFrom: 4, wantTiles: true, wantFrom: []string{"tiles", "tiles", "tiles", "tiles"}},
+		{desc: "missing-tile", missingFrom: 2, wantTiles: true, wantFrom: []string{"tiles", "tiles", "rfc6962", "rfc6962"}},
+		{desc: "no-tiles", noCheckpoint: true, missingFrom: 4, wantFrom: []string{"rfc6962", "rfc6962", "rfc6962", "rfc6962"}},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			// Both APIs serve the same tree, but their entries are marked
+			// with the client which served them.
+			tiles := &missingTiles{treeClient: newTreeClient(4, "leaf"), noCheckpoint: test.noCheckpoint, missingFrom: test.missingFrom}
+			rfc6962 := newTreeClient(4, "leaf")
+			for i := range tiles.leaves {
+				tiles.leaves[i] = []byte("tiles")
+				rfc6962.leaves[i] = []byte("rfc6962")
+			}
+			c := NewTileClient(tiles, rfc6962)
+
+			sth, err := c.GetSTH(ctx)
+			if err != nil {
+				t.Fatalf("GetSTH()=%v", err)
+			}
+			if sth.TreeSize != 4 {
+				t.Errorf("GetSTH().TreeSize=%d, want 4", sth.TreeSize)
+			}
+			if got := c.UsesTiles(); got != test.wantTiles {
+				t.Errorf("UsesTiles()=%v, want %v", got, test.wantTiles)
+			}
+			for i, want := range test.wantFrom {
+				rsp, err := c.GetRawEntries(ctx, int64(i), int64(i))
+				if err != nil {
+					t.Fatalf("GetRawEntries(%d)=%v", i, err)
+				}
+				if got := rsp.Entries[0].LeafInput; !bytes.Equal(got, []byte(want)) {
+					t.Errorf("GetRawEntries(%d) came from %q, want %q", i, got, want)
+				}
+			}
+			if _, err := c.GetSTHConsistency(ctx, 2, 4); err != nil {
+				t.Errorf("GetSTHConsistency()=%v", err)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package ct
 
 import (
+	"bytes"
 	"crypto"
 	"crypto/sha256"
 	"fmt"
@@ -143,6 +144,9 @@ func MerkleTreeLeafFromChain(chain []*x509.Certificate, etype LogEntryType, time
 			return nil, fmt.Errorf("no issuer cert available for pre-issuer")
 		}
 		issuer = chain[2]
+		if !bytes.Equal(preIssuer.RawIssuer, issuer.RawSubject) {
+			return nil, fmt.Errorf("pre-issuer not issued by chain[2] cert")
+		}
 	}
 
 	// Next, post-process the DER-encoded TBSCertificate, to remove the CT poison
@@ -163,7 +167,9 @@ func MerkleTreeLeafFromChain(chain []*x509.Certificate, etype LogEntryType, time
 // MerkleTreeLeafForEmbeddedSCT generates a MerkleTreeLeaf from a chain and an
 // SCT timestamp, where the leaf certificate at chain[0] is a certificate that
 // contains embedded SCTs.  It is assumed that the timestamp provided is from
-// one of the SCTs embedded within the leaf certificate.
+// one of the SCTs embedded within the leaf certificate.  If chain[1] is the
+// pre-issuer of the precertificate rather than the issuer of the leaf
+// certificate, the issuer is taken from chain[2].
 func MerkleTreeLeafForEmbeddedSCT(chain []*x509.Certificate, timestamp uint64) (*MerkleTreeLeaf, error) {
 	// For building the leaf for a certificate and SCT where the SCT is embedded
 	// in the certificate, we need to build the original precertificate TBS
@@ -173,6 +179,14 @@ func MerkleTreeLeafForEmbeddedSCT(chain []*x509.Certificate, timestamp uint64) (
 	}
 	issuer := chain[1]
 	cert := chain[0]
+	if IsPreIssuer(issuer) && !bytes.Equal(cert.RawIssuer, issuer.RawSubject) {
+		// The chain is that of the precertificate, whose pre-issuer did not
+		// issue the final certificate; the leaf names the final issuer.
+		if len(chain) < 3 {
+			return nil, fmt.Errorf("no issuer cert available for pre-issuer")
+		}
+		issuer = chain[2]
+	}
 
 	// Next, post-process the DER-encoded TBSCertificate, to remove the SCTList
 	// extension.
@@ -31,6 +31,10 @@ import (
 	"github.com/RarimoVoting/certificate-transparency-go/x509"
 	"github.com/RarimoVoting/certificate-transparency-go/x509util"
 	"github.com/google/trillian/monitoring"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/klog/v2"
 
 	ct "github.com/RarimoVoting/certificate-transparency-go"
@@ -44,7 +48,23 @@ var (
 	errCounter    monitoring.Counter   // logurl, ep, status => value
 	logRspLatency monitoring.Histogram // logurl, ep => value
 	// Per-log
-	lastGetRootsSuccess monitoring.Gauge // Unix time
+	lastGetRootsSuccess monitoring.Gauge   // Unix time
+	logErrorRate        monitoring.Gauge   // logurl => value
+	logLatency          monitoring.Gauge   // logurl => value
+	logEvictions        monitoring.Counter // logurl => value
+	logEvictedUntil     monitoring.Gauge   // logurl => Unix time
+	submissionResults   monitoring.Counter // logurl, ep, result => value
+
+	// tracer records spans covering the fan-out of submissions to logs. They
+	// are exported through the global TracerProvider, if one is set up.
+	tracer = otel.Tracer("github.com/RarimoVoting/certificate-transparency-go/submission")
+)
+
+// Results of submissions to a log, for metrics.
+const (
+	resultSuccess  = "success"
+	resultError    = "error"
+	resultCanceled = "canceled"
 )
 
 // distInitMetrics initializes all the exported metrics.
@@ -54,6 +74,11 @@ func distInitMetrics(mf monitoring.MetricFactory) {
 	errCounter = mf.NewCounter("err_count", "Number of errors", "logurl", "ep", "errtype")
 	logRspLatency = mf.NewHistogram("http_log_latency", "Latency of responses in seconds", "logurl", "ep")
 	lastGetRootsSuccess = mf.NewGauge("last_get_roots_success", "Unix timestamp for last successful get-roots request", "logurl")
+	logErrorRate = mf.NewGauge("log_error_rate", "Moving average of the error rate of submissions", "logurl")
+	logLatency = mf.NewGauge("log_latency", "Moving average of the latency of submissions in seconds", "logurl")
+	logEvictions = mf.NewCounter("log_evictions", "Number of times the log was left out of submissions for being unhealthy", "logurl")
+	logEvictedUntil = mf.NewGauge("log_evicted_until", "Unix timestamp until which the log is left out of submissions", "logurl")
+	submissionResults = mf.NewCounter("log_submission_results", "Number of submissions to the log by result: success, error, or canceled once the policy was satisfied", "logurl", "ep", "result")
 }
 
 const (
@@ -93,6 +118,8 @@ type Distributor struct {
 
 	policy            ctpolicy.CTPolicy
 	pendingLogsPolicy ctpolicy.CTPolicy
+
+	health *logHealth
 }
 
 // RefreshRoots requests roots from Logs and updates local copy.
@@ -216,17 +243,35 @@ func (d *Distributor) SubmitToLog(ctx context.Context, logURL string, chain []ct
 		endpoint = string(ctfe.AddPreChainName)
 	}
 
-	defer func(start time.Time) {
-		logRspLatency.Observe(time.Since(start).Seconds(), logURL, endpoint)
-	}(time.Now())
+	ctx, span := tracer.Start(ctx, "submission.SubmitToLog", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("ct.log_url", logURL), attribute.String("ct.endpoint", endpoint)))
+	defer span.End()
+
+	start := time.Now()
 	reqsCounter.Inc(logURL, endpoint)
 	addChain := lc.AddChain
 	if asPreChain {
 		addChain = lc.AddPreChain
 	}
 	sct, err := addChain(ctx, chain)
+	latency := time.Since(start)
+	logRspLatency.Observe(latency.Seconds(), logURL, endpoint)
 	incRspsCounter(logURL, endpoint, err)
 	incErrCounter(logURL, endpoint, err)
+	// Submissions canceled once the policy is satisfied say nothing of the log.
+	switch {
+	case err == nil:
+		submissionResults.Inc(logURL, endpoint, resultSuccess)
+		d.health.record(logURL, latency, err)
+	case ctx.Err() != nil:
+		submissionResults.Inc(logURL, endpoint, resultCanceled)
+		span.SetAttributes(attribute.Bool("ct.canceled", true))
+	default:
+		submissionResults.Inc(logURL, endpoint, resultError)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		d.health.record(logURL, latency, err)
+	}
 	return sct, err
 }
 
@@ -245,7 +290,18 @@ func parseRawChain(rawChain [][]byte) ([]*x509.Certificate, error) {
 
 // addSomeChain is helper calling one of AddChain or AddPreChain based
 // on asPreChain param.
-func (d *Distributor) addSomeChain(ctx context.Context, rawChain [][]byte, loadPendingLogs bool, asPreChain bool) ([]*AssignedSCT, error) {
+func (d *Distributor) addSomeChain(ctx context.Context, rawChain [][]byte, loadPendingLogs bool, asPreChain bool) (scts []*AssignedSCT, err error) {
+	ctx, span := tracer.Start(ctx, "submission.Distributor.addSomeChain",
+		trace.WithAttributes(attribute.Bool("ct.precert", asPreChain), attribute.String("ct.policy", d.policy.Name())))
+	defer func() {
+		span.SetAttributes(attribute.Int("ct.scts", len(scts)))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	if len(rawChain) == 0 {
 		return nil, fmt.Errorf("distributor unable to process empty chain")
 	}
@@ -292,11 +348,18 @@ func (d *Distributor) addSomeChain(ctx context.Context, rawChain [][]byte, loadP
 		return nil, fmt.Errorf("add-%schain method expected %scertificate, got %scertificate", methodType, methodType, inputType)
 	}
 
-	// Set up policy structs.
-	groups, err := d.policy.LogsByGroup(parsedChain[0], &compatibleLogs)
+	// Set up policy structs, leaving unhealthy logs out unless the policy
+	// cannot be satisfied without them.
+	healthyLogs := d.health.healthyLogs(&compatibleLogs)
+	groups, err := d.policy.LogsByGroup(parsedChain[0], &healthyLogs)
+	if err != nil {
+		groups, err = d.policy.LogsByGroup(parsedChain[0], &compatibleLogs)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("distributor does not have enough compatible Logs to comply with the policy: %v", err)
 	}
+	span.SetAttributes(attribute.Int("ct.compatible_logs", len(compatibleLogs.AllLogs())),
+		attribute.Int("ct.healthy_logs", len(healthyLogs.AllLogs())))
 	chain := make([]ct.ASN1Cert, len(parsedChain))
 	for i, c := range parsedChain {
 		chain[i] = ct.ASN1Cert{Data: c.Raw}
@@ -363,6 +426,7 @@ func NewDistributor(ll *loglist3.LogList, plc ctpolicy.CTPolicy, lcBuilder LogCl
 
 	d.policy = plc
 	d.pendingLogsPolicy = pendingLogsPolicy{}
+	d.health = newLogHealth(DefaultLogHealthOptions)
 	d.logClients = make(map[string]client.AddLogClient)
 	d.logRoots = make(loglist3.LogRoots)
 	d.rootPool = x509util.NewPEMCertPool()
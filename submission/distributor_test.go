@@ -505,3 +505,33 @@ func TestDistributorAddTypeMismatch(t *testing.T) {
 		})
 	}
 }
+
+func TestDistributorSubmitToLogHealth(t *testing.T) {
+	dist, err := NewDistributor(sampleValidLogList(), buildStubCTPolicy(1), newEmptyStubLogClient, monitoring.InertMetricFactory{})
+	if err != nil {
+		t.Fatalf("NewDistributor()=nil,%v", err)
+	}
+	dist.health = newLogHealth(LogHealthOptions{Decay: 0.5, MaxConsecutiveFailures: 2, EvictionPeriod: time.Minute})
+	logURL := "https://ct.googleapis.com/icarus/"
+
+	// Submissions canceled once the policy is satisfied are not failures.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 2; i++ {
+		if _, err := dist.SubmitToLog(ctx, logURL, nil, false); err == nil {
+			t.Fatalf("SubmitToLog()=_,nil; want error")
+		}
+	}
+	if !dist.health.healthy(logURL) {
+		t.Fatalf("log evicted after canceled submissions")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := dist.SubmitToLog(context.Background(), logURL, nil, false); err == nil {
+			t.Fatalf("SubmitToLog()=_,nil; want error")
+		}
+	}
+	if dist.health.healthy(logURL) {
+		t.Errorf("log not evicted after failed submissions")
+	}
+}
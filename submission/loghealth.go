@@ -0,0 +1,174 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package submission
+
+import (
+	"sync"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"k8s.io/klog/v2"
+)
+
+// LogHealthOptions configures when logs are considered unhealthy, and
+// temporarily left out of submissions.
+type LogHealthOptions struct {
+	// Decay is the weight of the latest submission in the moving averages of
+	// the error rate and latency of a log, between 0 and 1.
+	Decay float64
+	// MaxErrorRate is the error rate above which a log is evicted, once it
+	// had at least MinSamples submissions. Zero disables this check.
+	MaxErrorRate float64
+	MinSamples   int
+	// MaxConsecutiveFailures is the number of failed submissions in a row
+	// after which a log is evicted. Zero disables this check.
+	MaxConsecutiveFailures int
+	// EvictionPeriod is how long an unhealthy log is left out of
+	// submissions. Zero disables evictions.
+	EvictionPeriod time.Duration
+}
+
+// DefaultLogHealthOptions are the LogHealthOptions used unless set otherwise.
+var DefaultLogHealthOptions = LogHealthOptions{
+	Decay:                  0.1,
+	MaxErrorRate:           0.5,
+	MinSamples:             10,
+	MaxConsecutiveFailures: 5,
+	EvictionPeriod:         5 * time.Minute,
+}
+
+// LogHealthStatus describes the recent behaviour of a log.
+type LogHealthStatus struct {
+	// ErrorRate and Latency are moving averages over recent submissions.
+	ErrorRate float64
+	Latency   time.Duration
+	// EvictedUntil is set while the log is left out of submissions.
+	EvictedUntil time.Time
+}
+
+type logStats struct {
+	LogHealthStatus
+	samples             int
+	consecutiveFailures int
+}
+
+// logHealth tracks the error rate and latency of submissions to each log, and
+// evicts unhealthy logs for a while. After an eviction the log starts over
+// with a clean record. It is safe for concurrent use, and outlives the
+// Distributors of a Proxy so that log-list updates keep the history of logs.
+type logHealth struct {
+	opts LogHealthOptions
+	now  func() time.Time
+
+	mu   sync.Mutex
+	logs map[string]*logStats
+}
+
+func newLogHealth(opts LogHealthOptions) *logHealth {
+	return &logHealth{opts: opts, now: time.Now, logs: make(map[string]*logStats)}
+}
+
+// record accounts for a submission to logURL which took latency, and failed
+// if err is not nil.
+func (h *logHealth) record(logURL string, latency time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.logs[logURL]
+	if !ok {
+		s = &logStats{}
+		h.logs[logURL] = s
+	}
+	var failure float64
+	if err != nil {
+		failure = 1
+		s.consecutiveFailures++
+	} else {
+		s.consecutiveFailures = 0
+	}
+	if s.samples == 0 {
+		s.ErrorRate, s.Latency = failure, latency
+	} else {
+		s.ErrorRate += h.opts.Decay * (failure - s.ErrorRate)
+		s.Latency += time.Duration(h.opts.Decay * float64(latency-s.Latency))
+	}
+	s.samples++
+
+	now := h.now()
+	evict := h.opts.EvictionPeriod > 0 && !now.Before(s.EvictedUntil) &&
+		((h.opts.MaxConsecutiveFailures > 0 && s.consecutiveFailures >= h.opts.MaxConsecutiveFailures) ||
+			(h.opts.MaxErrorRate > 0 && s.samples >= h.opts.MinSamples && s.ErrorRate > h.opts.MaxErrorRate))
+	if evict {
+		klog.Warningf("Evicting log %s for %v: error rate %.2f, %d consecutive failures", logURL, h.opts.EvictionPeriod, s.ErrorRate, s.consecutiveFailures)
+		*s = logStats{LogHealthStatus: LogHealthStatus{EvictedUntil: now.Add(h.opts.EvictionPeriod)}}
+		logEvictions.Inc(logURL)
+		logEvictedUntil.Set(float64(s.EvictedUntil.Unix()), logURL)
+	}
+	logErrorRate.Set(s.ErrorRate, logURL)
+	logLatency.Set(s.Latency.Seconds(), logURL)
+}
+
+// healthy reports whether logURL may be submitted to.
+func (h *logHealth) healthy(logURL string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.logs[logURL]
+	return !ok || !h.now().Before(s.EvictedUntil)
+}
+
+// status returns the health of logURL.
+func (h *logHealth) status(logURL string) LogHealthStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.logs[logURL]; ok {
+		return s.LogHealthStatus
+	}
+	return LogHealthStatus{}
+}
+
+// retain forgets logs absent from ll, for example retired ones.
+func (h *logHealth) retain(ll *loglist3.LogList) {
+	urls := make(map[string]bool)
+	for _, op := range ll.Operators {
+		for _, l := range op.Logs {
+			urls[l.URL] = true
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for logURL := range h.logs {
+		if !urls[logURL] {
+			delete(h.logs, logURL)
+		}
+	}
+}
+
+// healthyLogs creates a new LogList containing only the logs of ll which are
+// not evicted.
+func (h *logHealth) healthyLogs(ll *loglist3.LogList) loglist3.LogList {
+	var healthy loglist3.LogList
+	for _, op := range ll.Operators {
+		healthyOp := *op
+		healthyOp.Logs = []*loglist3.Log{}
+		for _, l := range op.Logs {
+			if h.healthy(l.URL) {
+				healthyOp.Logs = append(healthyOp.Logs, l)
+			}
+		}
+		if len(healthyOp.Logs) > 0 {
+			healthy.Operators = append(healthy.Operators, &healthyOp)
+		}
+	}
+	return healthy
+}
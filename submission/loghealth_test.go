@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package submission
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+)
+
+func newTestLogHealth(opts LogHealthOptions) (*logHealth, *time.Time) {
+	distOnce.Do(func() { distInitMetrics(imf) })
+	now := time.Unix(1700000000, 0)
+	h := newLogHealth(opts)
+	h.now = func() time.Time { return now }
+	return h, &now
+}
+
+func TestLogHealthConsecutiveFailures(t *testing.T) {
+	h, now := newTestLogHealth(LogHealthOptions{Decay: 0.5, MaxConsecutiveFailures: 3, EvictionPeriod: time.Minute})
+	failure := errors.New("log unavailable")
+
+	h.record("a", time.Second, failure)
+	h.record("a", time.Second, failure)
+	h.record("a", time.Second, nil)
+	h.record("a", time.Second, failure)
+	h.record("a", time.Second, failure)
+	if !h.healthy("a") {
+		t.Fatalf("healthy(a)=false after 2 consecutive failures; want true")
+	}
+	h.record("a", time.Second, failure)
+	if h.healthy("a") {
+		t.Fatalf("healthy(a)=true after 3 consecutive failures; want false")
+	}
+	if got, want := h.status("a").EvictedUntil, now.Add(time.Minute); !got.Equal(want) {
+		t.Errorf("status(a).EvictedUntil=%v; want %v", got, want)
+	}
+	if !h.healthy("b") {
+		t.Errorf("healthy(b)=false for unknown log; want true")
+	}
+
+	*now = now.Add(time.Minute)
+	if !h.healthy("a") {
+		t.Errorf("healthy(a)=false after eviction period; want true")
+	}
+	// The log starts over after its eviction.
+	h.record("a", time.Second, failure)
+	if !h.healthy("a") {
+		t.Errorf("healthy(a)=false after 1 failure following eviction; want true")
+	}
+}
+
+func TestLogHealthErrorRate(t *testing.T) {
+	h, _ := newTestLogHealth(LogHealthOptions{Decay: 0.5, MaxErrorRate: 0.5, MinSamples: 4, EvictionPeriod: time.Minute})
+	failure := errors.New("log unavailable")
+
+	h.record("a", 2*time.Second, failure)
+	h.record("a", 4*time.Second, nil)
+	if got := h.status("a"); got.ErrorRate != 0.5 || got.Latency != 3*time.Second {
+		t.Errorf("status(a)=%+v; want error rate 0.5 and latency 3s", got)
+	}
+	h.record("a", time.Second, failure)
+	if !h.healthy("a") {
+		t.Fatalf("healthy(a)=false before MinSamples; want true")
+	}
+	h.record("a", time.Second, failure)
+	if h.healthy("a") {
+		t.Errorf("healthy(a)=true with error rate above 0.5; want false")
+	}
+}
+
+func TestLogHealthLogs(t *testing.T) {
+	h, _ := newTestLogHealth(LogHealthOptions{MaxConsecutiveFailures: 1, EvictionPeriod: time.Minute})
+	ll := &loglist3.LogList{Operators: []*loglist3.Operator{
+		{Name: "A", Logs: []*loglist3.Log{{URL: "a1"}, {URL: "a2"}}},
+		{Name: "B", Logs: []*loglist3.Log{{URL: "b"}}},
+	}}
+	h.record("a1", time.Second, errors.New("log unavailable"))
+	h.record("b", time.Second, errors.New("log unavailable"))
+	h.record("gone", time.Second, errors.New("log unavailable"))
+
+	healthy := h.healthyLogs(ll)
+	if len(healthy.Operators) != 1 || len(healthy.Operators[0].Logs) != 1 || healthy.Operators[0].Logs[0].URL != "a2" {
+		t.Errorf("healthyLogs()=%+v; want only a2", healthy)
+	}
+	if len(ll.Operators[0].Logs) != 2 {
+		t.Errorf("healthyLogs() modified the original list")
+	}
+
+	h.retain(ll)
+	if _, ok := h.logs["gone"]; ok {
+		t.Errorf("retain() kept a log absent from the list")
+	}
+	if h.healthy("a1") {
+		t.Errorf("retain() forgot the eviction of a listed log")
+	}
+}
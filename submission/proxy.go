@@ -27,8 +27,14 @@ import (
 	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
 	"github.com/RarimoVoting/certificate-transparency-go/schedule"
 	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
 	"github.com/RarimoVoting/certificate-transparency-go/x509util"
 	"github.com/google/trillian/monitoring"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 	"k8s.io/klog/v2"
 )
 
@@ -45,12 +51,16 @@ var (
 	proxyOnce      sync.Once
 	logListUpdates monitoring.Counter
 	rspLatency     monitoring.Histogram // ep => value
+	sctCacheHits   monitoring.Counter   // ep => value
+	sctDedups      monitoring.Counter   // ep => value
 )
 
 // proxyInitMetrics initializes all the exported metrics.
 func proxyInitMetrics(mf monitoring.MetricFactory) {
 	logListUpdates = mf.NewCounter("log_list_updates", "Number of Log-list updates")
 	rspLatency = mf.NewHistogram("http_latency", "Latency of policy-multiplexed add-responses in seconds", "ep")
+	sctCacheHits = mf.NewCounter("sct_cache_hits", "Number of add-requests answered with cached SCTs", "ep")
+	sctDedups = mf.NewCounter("sct_dedups", "Number of add-requests which shared the submission of a concurrent identical request", "ep")
 }
 
 // DistributorBuilder builds distributor instance for a given Log list.
@@ -60,19 +70,25 @@ type DistributorBuilder func(*loglist3.LogList) (*Distributor, error)
 // Distributor c-tor.
 func GetDistributorBuilder(plc CTPolicyType, lcBuilder LogClientBuilder, mf monitoring.MetricFactory) DistributorBuilder {
 	if plc == AppleCTPolicy {
-		return func(ll *loglist3.LogList) (*Distributor, error) {
-			return NewDistributor(ll, ctpolicy.AppleCTPolicy{}, lcBuilder, mf)
-		}
+		return GetPolicyDistributorBuilder(ctpolicy.AppleCTPolicy{}, lcBuilder, mf)
 	}
+	return GetPolicyDistributorBuilder(ctpolicy.ChromeCTPolicy{}, lcBuilder, mf)
+}
+
+// GetPolicyDistributorBuilder given CT-policy and Log-client builder produces
+// Distributor c-tor. Unlike GetDistributorBuilder, it accepts any policy, for
+// example one loaded with ctpolicy.LoadPolicyConfig.
+func GetPolicyDistributorBuilder(plc ctpolicy.CTPolicy, lcBuilder LogClientBuilder, mf monitoring.MetricFactory) DistributorBuilder {
 	return func(ll *loglist3.LogList) (*Distributor, error) {
-		return NewDistributor(ll, ctpolicy.ChromeCTPolicy{}, lcBuilder, mf)
+		return NewDistributor(ll, plc, lcBuilder, mf)
 	}
 }
 
-// ASN1MarshalSCTs serializes list of AssignedSCTs according to RFC6962 3.3
-func ASN1MarshalSCTs(scts []*AssignedSCT) ([]byte, error) {
+// TLSMarshalSCTs serializes list of AssignedSCTs into a
+// SignedCertificateTimestampList, as carried by the TLS extension of RFC6962 3.3.
+func TLSMarshalSCTs(scts []*AssignedSCT) ([]byte, error) {
 	if len(scts) == 0 {
-		return nil, fmt.Errorf("ASN1MarshalSCTs requires positive number of SCTs, 0 provided")
+		return nil, fmt.Errorf("SCT list requires positive number of SCTs, 0 provided")
 	}
 	unassignedSCTs := make([]*ct.SignedCertificateTimestamp, 0, len(scts))
 	for _, sct := range scts {
@@ -82,7 +98,13 @@ func ASN1MarshalSCTs(scts []*AssignedSCT) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	encdSCTList, err := tls.Marshal(*sctList)
+	return tls.Marshal(*sctList)
+}
+
+// ASN1MarshalSCTs serializes list of AssignedSCTs according to RFC6962 3.3,
+// as the value of the X.509v3 extension.
+func ASN1MarshalSCTs(scts []*AssignedSCT) ([]byte, error) {
+	encdSCTList, err := TLSMarshalSCTs(scts)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +115,16 @@ func ASN1MarshalSCTs(scts []*AssignedSCT) ([]byte, error) {
 	return encoded, nil
 }
 
+// OCSPMarshalSCTs serializes list of AssignedSCTs into the DER-encoded
+// singleExtension of an OCSP response, according to RFC6962 3.3.
+func OCSPMarshalSCTs(scts []*AssignedSCT) ([]byte, error) {
+	value, err := ASN1MarshalSCTs(scts)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pkix.Extension{Id: x509.OIDExtensionCTOCSPSCT, Value: value})
+}
+
 // Proxy wraps Log List updates watcher and Distributor running on fresh Log List.
 type Proxy struct {
 	Init chan bool
@@ -106,6 +138,11 @@ type Proxy struct {
 	distMu     sync.RWMutex // guards the distributor
 	dist       *Distributor
 	distCancel context.CancelFunc // used to cancel distributor updates
+
+	cache    *sctCache // nil unless EnableSCTCache is called
+	inflight singleflight.Group
+
+	health *logHealth // shared by successive distributors
 }
 
 // NewProxy creates an inactive Proxy instance. Call Run() to activate.
@@ -115,6 +152,7 @@ func NewProxy(llm *LogListManager, db DistributorBuilder, mf monitoring.MetricFa
 	p.distributorBuilder = db
 	p.Init = make(chan bool, 1)
 	p.rootsRefreshInterval = 24 * time.Hour
+	p.health = newLogHealth(DefaultLogHealthOptions)
 
 	if mf == nil {
 		mf = monitoring.InertMetricFactory{}
@@ -124,6 +162,27 @@ func NewProxy(llm *LogListManager, db DistributorBuilder, mf monitoring.MetricFa
 	return &p
 }
 
+// EnableSCTCache makes the Proxy keep the SCTs it obtains for ttl, keyed by
+// the hash of the leaf certificate, and answer repeated submissions of the
+// same chain with them instead of submitting to logs again. At most
+// maxEntries chains are remembered, or any number if maxEntries is zero.
+// Must be called before Run.
+func (p *Proxy) EnableSCTCache(ttl time.Duration, maxEntries int) {
+	p.cache = newSCTCache(ttl, maxEntries)
+}
+
+// SetLogHealthOptions sets when logs are considered unhealthy and left out of
+// submissions for a while. Must be called before Run.
+func (p *Proxy) SetLogHealthOptions(opts LogHealthOptions) {
+	p.health = newLogHealth(opts)
+}
+
+// LogHealth returns the health of the log with the given URL, as observed
+// through submissions.
+func (p *Proxy) LogHealth(logURL string) LogHealthStatus {
+	return p.health.status(logURL)
+}
+
 // Run starts regular LogList checks and associated Distributor initialization.
 // Sends true via Init channel when init is complete.
 // Terminates upon context cancellation.
@@ -165,6 +224,10 @@ func (p *Proxy) restartDistributor(ctx context.Context, ll *loglist3.LogList) er
 		// losing ll info. No good.
 		return err
 	}
+	// Keep the health of logs across Log-list updates, forgetting those
+	// which left the list.
+	p.health.retain(ll)
+	d.health = p.health
 
 	// Start refreshing roots periodically so they stay up-to-date.
 	refreshCtx, refreshCancel := context.WithCancel(ctx)
@@ -188,23 +251,64 @@ func (p *Proxy) restartDistributor(ctx context.Context, ll *loglist3.LogList) er
 
 // AddPreChain passes call to underlying Distributor instance.
 func (p *Proxy) AddPreChain(ctx context.Context, rawChain [][]byte, loadPendingLogs bool) ([]*AssignedSCT, error) {
-	if p.dist == nil {
-		return []*AssignedSCT{}, fmt.Errorf("proxy distributor is not initialized. call Run()")
-	}
-
-	defer func(start time.Time) {
-		rspLatency.Observe(time.Since(start).Seconds(), "add-pre-chain")
-	}(time.Now())
-	return p.dist.AddPreChain(ctx, rawChain, loadPendingLogs)
+	return p.addSomeChain(ctx, "add-pre-chain", rawChain, loadPendingLogs, func(ctx context.Context, d *Distributor) ([]*AssignedSCT, error) {
+		return d.AddPreChain(ctx, rawChain, loadPendingLogs)
+	})
 }
 
 // AddChain passes call to underlying Distributor instance.
 func (p *Proxy) AddChain(ctx context.Context, rawChain [][]byte, loadPendingLogs bool) ([]*AssignedSCT, error) {
+	return p.addSomeChain(ctx, "add-chain", rawChain, loadPendingLogs, func(ctx context.Context, d *Distributor) ([]*AssignedSCT, error) {
+		return d.AddChain(ctx, rawChain, loadPendingLogs)
+	})
+}
+
+// addSomeChain runs add on the underlying Distributor instance. If the SCT
+// cache is enabled, cached SCTs are returned when available, and concurrent
+// identical requests share a single submission, with the deadline of the
+// first one.
+func (p *Proxy) addSomeChain(ctx context.Context, ep string, rawChain [][]byte, loadPendingLogs bool, add func(context.Context, *Distributor) ([]*AssignedSCT, error)) (scts []*AssignedSCT, err error) {
 	if p.dist == nil {
 		return []*AssignedSCT{}, fmt.Errorf("proxy distributor is not initialized. call Run()")
 	}
+	ctx, span := tracer.Start(ctx, "submission.Proxy/"+ep, trace.WithSpanKind(trace.SpanKindServer))
 	defer func(start time.Time) {
-		rspLatency.Observe(time.Since(start).Seconds(), "add-chain")
+		rspLatency.Observe(time.Since(start).Seconds(), ep)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
 	}(time.Now())
-	return p.dist.AddChain(ctx, rawChain, loadPendingLogs)
+	if p.cache == nil {
+		return add(ctx, p.dist)
+	}
+
+	key := sctCacheKey(ep, rawChain, loadPendingLogs)
+	if scts, ok := p.cache.get(key); ok {
+		sctCacheHits.Inc(ep)
+		span.SetAttributes(attribute.Bool("ct.cache_hit", true))
+		return scts, nil
+	}
+	ch := p.inflight.DoChan(key, func() (interface{}, error) {
+		scts, err := add(ctx, p.dist)
+		if err != nil {
+			return nil, err
+		}
+		p.cache.put(key, scts)
+		return scts, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Shared {
+			sctDedups.Inc(ep)
+			span.SetAttributes(attribute.Bool("ct.shared", true))
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]*AssignedSCT), nil
+	}
 }
@@ -43,6 +43,18 @@ func NewProxyServer(logListPath string, dBuilder DistributorBuilder, reqTimeout
 	return s
 }
 
+// EnableSCTCache makes the server answer repeated submissions of a chain
+// with the SCTs obtained for it within ttl. See Proxy.EnableSCTCache.
+func (s *ProxyServer) EnableSCTCache(ttl time.Duration, maxEntries int) {
+	s.p.EnableSCTCache(ttl, maxEntries)
+}
+
+// SetLogHealthOptions sets when logs are left out of submissions. See
+// Proxy.SetLogHealthOptions.
+func (s *ProxyServer) SetLogHealthOptions(opts LogHealthOptions) {
+	s.p.SetLogHealthOptions(opts)
+}
+
 // Run starts regular Log list updates in the background, running until the
 // context is canceled. Blocks until initialization happens.
 func (s *ProxyServer) Run(ctx context.Context, logListRefreshInterval time.Duration, rootsRefreshInterval time.Duration, loadPendingLogs bool) {
@@ -55,6 +67,12 @@ func (s *ProxyServer) Run(ctx context.Context, logListRefreshInterval time.Durat
 // SCTBatch represents JSON response to add-pre-chain method of proxy.
 type SCTBatch struct {
 	SCTs []ct.SignedCertificateTimestamp `json:"scts"`
+	// SCTList holds the SCTs encoded as a SignedCertificateTimestampList,
+	// ready for the TLS extension of RFC6962 3.3.
+	SCTList []byte `json:"sct_list,omitempty"`
+	// OCSPExtension holds the SCTs encoded as a DER singleExtension, ready
+	// for inclusion in OCSP responses (RFC6962 3.3).
+	OCSPExtension []byte `json:"ocsp_extension,omitempty"`
 }
 
 func marshalSCTs(scts []*AssignedSCT) ([]byte, error) {
@@ -63,6 +81,15 @@ func marshalSCTs(scts []*AssignedSCT) ([]byte, error) {
 	for _, sct := range scts {
 		jsonSCTsObj.SCTs = append(jsonSCTsObj.SCTs, *sct.SCT)
 	}
+	if len(scts) > 0 {
+		var err error
+		if jsonSCTsObj.SCTList, err = TLSMarshalSCTs(scts); err != nil {
+			return nil, err
+		}
+		if jsonSCTsObj.OCSPExtension, err = OCSPMarshalSCTs(scts); err != nil {
+			return nil, err
+		}
+	}
 	return json.Marshal(jsonSCTsObj)
 }
 
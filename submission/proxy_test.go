@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -25,10 +26,13 @@ import (
 	"time"
 
 	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
 	"github.com/RarimoVoting/certificate-transparency-go/client"
 	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
 	"github.com/RarimoVoting/certificate-transparency-go/testdata"
 	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
 	"github.com/google/trillian/monitoring"
 )
 
@@ -221,3 +225,56 @@ func TestASN1MarshalSCT(t *testing.T) {
 		})
 	}
 }
+
+func TestMarshalSCTFormats(t *testing.T) {
+	scts := buildAssignedSCTs(t, 2)
+	x509Ext, err := ASN1MarshalSCTs(scts)
+	if err != nil {
+		t.Fatalf("ASN1MarshalSCTs()=nil,%v", err)
+	}
+
+	sctList, err := TLSMarshalSCTs(scts)
+	if err != nil {
+		t.Fatalf("TLSMarshalSCTs()=nil,%v", err)
+	}
+	var wantSCTList []byte
+	if _, err := asn1.Unmarshal(x509Ext, &wantSCTList); err != nil {
+		t.Fatalf("asn1.Unmarshal(ASN1MarshalSCTs())=%v", err)
+	}
+	if !bytes.Equal(sctList, wantSCTList) {
+		t.Errorf("TLSMarshalSCTs()=%x; want %x", sctList, wantSCTList)
+	}
+
+	ocspExt, err := OCSPMarshalSCTs(scts)
+	if err != nil {
+		t.Fatalf("OCSPMarshalSCTs()=nil,%v", err)
+	}
+	var ext pkix.Extension
+	if rest, err := asn1.Unmarshal(ocspExt, &ext); err != nil || len(rest) > 0 {
+		t.Fatalf("asn1.Unmarshal(OCSPMarshalSCTs())=%x,%v; want no trailing data", rest, err)
+	}
+	if !ext.Id.Equal(x509.OIDExtensionCTOCSPSCT) || ext.Critical || !bytes.Equal(ext.Value, x509Ext) {
+		t.Errorf("OCSPMarshalSCTs()=%+v; want non-critical %v extension with value %x", ext, x509.OIDExtensionCTOCSPSCT, x509Ext)
+	}
+
+	if _, err := TLSMarshalSCTs(nil); err == nil {
+		t.Error("TLSMarshalSCTs(nil)=_,nil; want error")
+	}
+	if _, err := OCSPMarshalSCTs(buildNilAssignedSCT()); err == nil {
+		t.Error("OCSPMarshalSCTs(nil SCT)=_,nil; want error")
+	}
+}
+
+func TestMarshalSCTBatch(t *testing.T) {
+	data, err := marshalSCTs(buildAssignedSCTs(t, 1))
+	if err != nil {
+		t.Fatalf("marshalSCTs()=nil,%v", err)
+	}
+	var batch SCTBatch
+	if err := json.Unmarshal(data, &batch); err != nil {
+		t.Fatalf("json.Unmarshal(marshalSCTs())=%v", err)
+	}
+	if len(batch.SCTs) != 1 || len(batch.SCTList) == 0 || len(batch.OCSPExtension) == 0 {
+		t.Errorf("marshalSCTs()=%s; want 1 SCT in all formats", data)
+	}
+}
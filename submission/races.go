@@ -55,6 +55,11 @@ type safeSubmissionState struct {
 	mu          sync.Mutex
 	logToGroups map[string]ctpolicy.GroupSet
 	groupNeeds  map[string]int
+	// Distinct operators required by each group, operators of the SCTs set
+	// for each group, and the operator of each log.
+	groupMinOps map[string]int
+	groupOps    map[string]map[string]bool
+	logOps      map[string]string
 
 	results map[string]*submissionResult
 	cancels map[string]context.CancelFunc
@@ -64,14 +69,43 @@ func newSafeSubmissionState(groups ctpolicy.LogPolicyData) *safeSubmissionState
 	var s safeSubmissionState
 	s.logToGroups = ctpolicy.GroupByLogs(groups)
 	s.groupNeeds = make(map[string]int)
+	s.groupMinOps = make(map[string]int)
+	s.groupOps = make(map[string]map[string]bool)
+	s.logOps = make(map[string]string)
 	for _, g := range groups {
 		s.groupNeeds[g.Name] = g.MinInclusions
+		s.groupMinOps[g.Name] = g.MinOperators
+		s.groupOps[g.Name] = make(map[string]bool)
+		for logURL, op := range g.LogOperators {
+			s.logOps[logURL] = op
+		}
 	}
 	s.results = make(map[string]*submissionResult)
 	s.cancels = make(map[string]context.CancelFunc)
 	return &s
 }
 
+// needed returns whether the group still needs SCTs, either to reach its
+// minimal inclusion number or its minimal number of operators.
+func (sub *safeSubmissionState) needed(groupName string) bool {
+	return sub.groupNeeds[groupName] > 0 || len(sub.groupOps[groupName]) < sub.groupMinOps[groupName]
+}
+
+// wants returns whether an SCT from logURL helps the group, given that slack
+// SCTs can be set for it besides those needed by other groups. Once the
+// remaining SCTs are all needed to reach the group's minimal number of
+// operators, only SCTs from new operators help.
+func (sub *safeSubmissionState) wants(groupName, logURL string, slack int) bool {
+	missingOps := sub.groupMinOps[groupName] - len(sub.groupOps[groupName])
+	if missingOps > 0 && !sub.groupOps[groupName][sub.logOps[logURL]] {
+		return true
+	}
+	if missingOps < 0 {
+		missingOps = 0
+	}
+	return slack > missingOps
+}
+
 // request includes empty submissionResult in the set, returns whether
 // the entry is requested for the first time.
 func (sub *safeSubmissionState) request(logURL string, cancel context.CancelFunc) bool {
@@ -84,7 +118,7 @@ func (sub *safeSubmissionState) request(logURL string, cancel context.CancelFunc
 	sub.results[logURL] = &submissionResult{}
 	isAwaited := false
 	for g := range sub.logToGroups[logURL] {
-		if sub.groupNeeds[g] > 0 {
+		if sub.needed(g) {
 			isAwaited = true
 			break
 		}
@@ -113,8 +147,9 @@ func (sub *safeSubmissionState) setResult(logURL string, sct *ct.SignedCertifica
 		if groupName == ctpolicy.BaseName {
 			continue
 		}
-		if sub.groupNeeds[groupName] > 0 {
+		if sub.wants(groupName, logURL, sub.groupNeeds[groupName]) {
 			sub.results[logURL] = &submissionResult{sct: sct, err: err}
+			sub.groupOps[groupName][sub.logOps[logURL]] = true
 		}
 		sub.groupNeeds[groupName]--
 	}
@@ -124,7 +159,8 @@ func (sub *safeSubmissionState) setResult(logURL string, sct *ct.SignedCertifica
 		if sub.results[logURL].sct != nil {
 			// It is already processed in a non-base group, so we can reduce the groupNeeds for the base group as well.
 			sub.groupNeeds[ctpolicy.BaseName]--
-		} else if sub.groupNeeds[ctpolicy.BaseName] > 0 {
+			sub.groupOps[ctpolicy.BaseName][sub.logOps[logURL]] = true
+		} else if sub.needed(ctpolicy.BaseName) {
 			minInclusionsForOtherGroup := 0
 			for g, cnt := range sub.groupNeeds {
 				if g != ctpolicy.BaseName && cnt > 0 {
@@ -132,10 +168,11 @@ func (sub *safeSubmissionState) setResult(logURL string, sct *ct.SignedCertifica
 				}
 			}
 			// Set the result only if the base group still needs SCTs more than total counts
-			// of minimum inclusions for other groups.
-			if sub.groupNeeds[ctpolicy.BaseName] > minInclusionsForOtherGroup {
+			// of minimum inclusions for other groups, or needs its operator.
+			if sub.wants(ctpolicy.BaseName, logURL, sub.groupNeeds[ctpolicy.BaseName]-minInclusionsForOtherGroup) {
 				sub.results[logURL] = &submissionResult{sct: sct, err: err}
 				sub.groupNeeds[ctpolicy.BaseName]--
+				sub.groupOps[ctpolicy.BaseName][sub.logOps[logURL]] = true
 			}
 		}
 	}
@@ -145,7 +182,7 @@ func (sub *safeSubmissionState) setResult(logURL string, sct *ct.SignedCertifica
 	for logURL, groupSet := range sub.logToGroups {
 		isAwaited := false
 		for g := range groupSet {
-			if sub.groupNeeds[g] > 0 {
+			if sub.needed(g) {
 				isAwaited = true
 				break
 			}
@@ -161,11 +198,10 @@ func (sub *safeSubmissionState) setResult(logURL string, sct *ct.SignedCertifica
 func (sub *safeSubmissionState) groupComplete(groupName string) bool {
 	sub.mu.Lock()
 	defer sub.mu.Unlock()
-	needs, ok := sub.groupNeeds[groupName]
-	if !ok {
+	if _, ok := sub.groupNeeds[groupName]; !ok {
 		return true
 	}
-	return needs <= 0
+	return !sub.needed(groupName)
 }
 
 func (sub *safeSubmissionState) collectSCTs() []*AssignedSCT {
@@ -145,6 +145,22 @@ func TestGetSCTs(t *testing.T) {
 			},
 			resultTrail: map[string]int{"a": 1, "b": 1, ctpolicy.BaseName: 5},
 		},
+		{
+			name:   "operatorDiversity",
+			sbMock: &mockSubmitter{fixedDelay: map[byte]time.Duration{'a': 0, 'b': time.Second}, firstLetterURLReqNumber: make(map[byte]int)},
+			groups: ctpolicy.LogPolicyData{
+				ctpolicy.BaseName: {
+					Name:          ctpolicy.BaseName,
+					LogURLs:       map[string]bool{"a1.com": true, "a2.com": true, "a3.com": true, "b1.com": true},
+					MinInclusions: 2,
+					MinOperators:  2,
+					IsBase:        true,
+					LogWeights:    map[string]float32{"a1.com": 1.0, "a2.com": 1.0, "a3.com": 1.0, "b1.com": 1.0},
+					LogOperators:  map[string]string{"a1.com": "A", "a2.com": "A", "a3.com": "A", "b1.com": "B"},
+				},
+			},
+			resultTrail: map[string]int{"b": 1, ctpolicy.BaseName: 2},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,101 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package submission
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// sctCacheKey identifies the SCTs obtained for a chain, by the hash of its
+// leaf certificate, the kind of submission and whether pending logs were
+// submitted to.
+func sctCacheKey(ep string, rawChain [][]byte, loadPendingLogs bool) string {
+	var leaf []byte
+	if len(rawChain) > 0 {
+		leaf = rawChain[0]
+	}
+	return fmt.Sprintf("%s/%t/%x", ep, loadPendingLogs, sha256.Sum256(leaf))
+}
+
+type sctCacheEntry struct {
+	key     string
+	scts    []*AssignedSCT
+	expires time.Time
+}
+
+// sctCache holds the SCTs recently obtained for chains, so that repeated
+// submissions of a chain are answered without submitting it to logs again.
+// Entries expire after a fixed TTL, and the oldest entries are dropped when
+// the cache is full. It is safe for concurrent use.
+type sctCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // of *sctCacheEntry, oldest first
+	entries map[string]*list.Element
+}
+
+func newSCTCache(ttl time.Duration, maxEntries int) *sctCache {
+	return &sctCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// get returns the SCTs cached under key, if they have not expired.
+func (c *sctCache) get(key string) ([]*AssignedSCT, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	return elem.Value.(*sctCacheEntry).scts, true
+}
+
+// put caches scts under key for the TTL of the cache.
+func (c *sctCache) put(key string, scts []*AssignedSCT) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+	}
+	c.entries[key] = c.order.PushBack(&sctCacheEntry{key: key, scts: scts, expires: c.now().Add(c.ttl)})
+	c.expire()
+}
+
+// expire drops the expired entries, and the oldest ones beyond maxEntries. As
+// all entries live for the same TTL, the oldest entries expire first.
+func (c *sctCache) expire() {
+	now := c.now()
+	for elem := c.order.Front(); elem != nil; elem = c.order.Front() {
+		entry := elem.Value.(*sctCacheEntry)
+		if now.Before(entry.expires) && (c.maxEntries <= 0 || c.order.Len() <= c.maxEntries) {
+			return
+		}
+		c.order.Remove(elem)
+		delete(c.entries, entry.key)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package submission
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSCTCacheKey(t *testing.T) {
+	chain := [][]byte{[]byte("leaf"), []byte("issuer")}
+	key := sctCacheKey("add-chain", chain, false)
+	if got := sctCacheKey("add-chain", [][]byte{[]byte("leaf"), []byte("other")}, false); got != key {
+		t.Errorf("sctCacheKey() differs for chains with the same leaf: %q != %q", got, key)
+	}
+	for _, other := range []string{
+		sctCacheKey("add-pre-chain", chain, false),
+		sctCacheKey("add-chain", chain, true),
+		sctCacheKey("add-chain", [][]byte{[]byte("other")}, false),
+	} {
+		if other == key {
+			t.Errorf("sctCacheKey()=%q for different submissions", key)
+		}
+	}
+}
+
+func TestSCTCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	c := newSCTCache(time.Minute, 2)
+	c.now = func() time.Time { return now }
+	scts := buildAssignedSCTs(t, 2)
+
+	c.put("a", scts)
+	if got, ok := c.get("a"); !ok || len(got) != 2 {
+		t.Errorf("get(a)=%v,%t; want 2 SCTs", got, ok)
+	}
+	if _, ok := c.get("b"); ok {
+		t.Error("get(b)=_,true; want false")
+	}
+
+	now = now.Add(30 * time.Second)
+	c.put("b", scts)
+	c.put("c", scts)
+	if _, ok := c.get("a"); ok {
+		t.Error("get(a)=_,true beyond capacity; want false")
+	}
+	if _, ok := c.get("b"); !ok {
+		t.Error("get(b)=_,false; want true")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.get("c"); ok {
+		t.Error("get(c)=_,true after TTL; want false")
+	}
+	if len(c.entries) != 0 || c.order.Len() != 0 {
+		t.Errorf("cache holds %d/%d entries after TTL; want none", len(c.entries), c.order.Len())
+	}
+}
+
+func TestProxySCTCache(t *testing.T) {
+	p := NewProxy(stubLogListManager(), GetDistributorBuilder(ChromeCTPolicy, NewStubLogClient, imf), imf)
+	p.dist = &Distributor{}
+	p.EnableSCTCache(time.Hour, 0)
+
+	var calls int32
+	release := make(chan struct{})
+	add := func(context.Context, *Distributor) ([]*AssignedSCT, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-release
+			return nil, fmt.Errorf("log unavailable")
+		}
+		return buildAssignedSCTs(t, 2), nil
+	}
+	chain := [][]byte{[]byte("leaf")}
+	ctx := context.Background()
+
+	// Failures are shared by concurrent requests, but not cached.
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = p.addSomeChain(ctx, "add-chain", chain, false, add)
+		}(i)
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	for i, err := range errs {
+		if err == nil {
+			t.Errorf("addSomeChain() #%d=_,nil; want error", i)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("concurrent addSomeChain() submitted %d times; want 1", got)
+	}
+
+	for i := 0; i < 3; i++ {
+		scts, err := p.addSomeChain(ctx, "add-chain", chain, false, add)
+		if err != nil || len(scts) != 2 {
+			t.Errorf("addSomeChain() #%d=%v,%v; want 2 SCTs", i, scts, err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("addSomeChain() submitted %d times; want 2", got)
+	}
+	if _, err := p.addSomeChain(ctx, "add-pre-chain", chain, false, add); err != nil {
+		t.Errorf("addSomeChain(add-pre-chain)=_,%v; want nil", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("addSomeChain(add-pre-chain) submitted %d times in total; want 3", got)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/RarimoVoting/certificate-transparency-go/ctpolicy"
 	"github.com/RarimoVoting/certificate-transparency-go/submission"
 	"github.com/google/trillian/monitoring/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -36,8 +37,14 @@ var (
 	logListRefreshInterval   = flag.Duration("loglist_refresh_interval", 24*time.Hour, "Interval between consecutive reads of Log-list")
 	rootsRefreshInterval     = flag.Duration("roots_refresh_interval", 24*time.Hour, "Interval between consecutive get-roots calls")
 	policyType               = flag.String("policy_type", "chrome", "CT-policy <chrome|apple>")
+	policyConfig             = flag.String("policy_config", "", "Path to a JSON CT-policy description, used instead of --policy_type if set")
 	dryRun                   = flag.Bool("dry_run", false, "No real submissions done")
 	addPreChainTimeout       = flag.Duration("add_prechain_timeout", 10*time.Second, "Timeout for each add-prechain call")
+	sctCacheTTL              = flag.Duration("sct_cache_ttl", 10*time.Minute, "How long SCTs are reused for repeated submissions of the same chain, 0 to disable")
+	sctCacheSize             = flag.Int("sct_cache_size", 100000, "Maximal number of chains whose SCTs are cached")
+	logEvictionPeriod        = flag.Duration("log_eviction_period", submission.DefaultLogHealthOptions.EvictionPeriod, "How long unhealthy Logs are left out of submissions, 0 to never evict them")
+	logMaxErrorRate          = flag.Float64("log_max_error_rate", submission.DefaultLogHealthOptions.MaxErrorRate, "Recent error rate above which a Log is evicted")
+	logMaxFailures           = flag.Int("log_max_consecutive_failures", submission.DefaultLogHealthOptions.MaxConsecutiveFailures, "Number of consecutive failed submissions after which a Log is evicted")
 	loadPendingQualifiedLogs = flag.Bool("load_pending_qualified_logs", true, "Whether to submit cert to one of Pending+Qualified Logs along main submission")
 )
 
@@ -55,15 +62,32 @@ func main() {
 	klog.InitFlags(nil)
 	flag.Parse()
 
-	plc := parsePolicyType()
-
 	lcb := submission.BuildLogClient
 	if *dryRun {
 		lcb = submission.NewStubLogClient
 	}
 	mf := prometheus.MetricFactory{}
 
-	s := submission.NewProxyServer(*logListPath, submission.GetDistributorBuilder(plc, lcb, mf), *addPreChainTimeout, mf)
+	var db submission.DistributorBuilder
+	if *policyConfig != "" {
+		plc, err := ctpolicy.LoadPolicyConfig(*policyConfig)
+		if err != nil {
+			klog.Exitf("Failed to load CT-policy: %v", err)
+		}
+		db = submission.GetPolicyDistributorBuilder(plc, lcb, mf)
+	} else {
+		db = submission.GetDistributorBuilder(parsePolicyType(), lcb, mf)
+	}
+
+	s := submission.NewProxyServer(*logListPath, db, *addPreChainTimeout, mf)
+	healthOpts := submission.DefaultLogHealthOptions
+	healthOpts.EvictionPeriod = *logEvictionPeriod
+	healthOpts.MaxErrorRate = *logMaxErrorRate
+	healthOpts.MaxConsecutiveFailures = *logMaxFailures
+	s.SetLogHealthOptions(healthOpts)
+	if *sctCacheTTL > 0 {
+		s.EnableSCTCache(*sctCacheTTL, *sctCacheSize)
+	}
 	s.Run(context.Background(), *logListRefreshInterval, *rootsRefreshInterval, *loadPendingQualifiedLogs)
 	http.HandleFunc("/ct/v1/proxy/add-pre-chain/", s.HandleAddPreChain)
 	http.HandleFunc("/ct/v1/proxy/add-chain/", s.HandleAddChain)
@@ -59,6 +59,117 @@ func (e syntaxError) Error() string {
 	return "tls: syntax error: " + prefix + e.msg
 }
 
+// LimitKind identifies one of the limits of a DecodeLimits.
+type LimitKind int
+
+// Kinds of decoding limits.
+const (
+	// VectorLengthLimit bounds the length of a variable-length vector.
+	VectorLengthLimit LimitKind = iota
+	// DepthLimit bounds the nesting depth of structures and vectors.
+	DepthLimit
+	// AllocationLimit bounds the memory allocated for the decoded value.
+	AllocationLimit
+)
+
+func (k LimitKind) String() string {
+	switch k {
+	case VectorLengthLimit:
+		return "vector length"
+	case DepthLimit:
+		return "nesting depth"
+	case AllocationLimit:
+		return "allocation"
+	}
+	return fmt.Sprintf("LimitKind(%d)", int(k))
+}
+
+// A LimitError indicates that decoding the TLS data would exceed one of the
+// limits passed to UnmarshalWithLimits.
+type LimitError struct {
+	Field string
+	Kind  LimitKind
+	Value uint64 // Value reached by the limited quantity
+	Max   uint64 // Configured maximum for the limited quantity
+}
+
+func (e *LimitError) Error() string {
+	var prefix string
+	if e.Field != "" {
+		prefix = e.Field + ": "
+	}
+	return fmt.Sprintf("tls: limit exceeded: %s%s %d over maximum %d", prefix, e.Kind, e.Value, e.Max)
+}
+
+// DecodeLimits bounds the resources used by UnmarshalWithLimits to decode
+// data from untrusted sources. A zero field means no limit.
+type DecodeLimits struct {
+	// MaxVectorLength is the maximum encoded length in bytes of any
+	// variable-length vector.
+	MaxVectorLength uint64
+	// MaxDepth is the maximum nesting depth of structures and vectors; the
+	// top-level value is at depth 1.
+	MaxDepth int
+	// MaxAllocation is the maximum total number of bytes allocated for the
+	// vectors and variants of the decoded value.
+	MaxAllocation uint64
+}
+
+// DefaultDecodeLimits accommodates all the CT structures, including entries
+// holding the largest certificates allowed by RFC 6962.
+var DefaultDecodeLimits = DecodeLimits{
+	MaxVectorLength: 1<<24 - 1,
+	MaxDepth:        16,
+	MaxAllocation:   64 << 20,
+}
+
+// decodeState tracks the resources used by a decoding with limits. A nil
+// *decodeState imposes no limits.
+type decodeState struct {
+	limits    DecodeLimits
+	depth     int
+	allocated uint64
+}
+
+// enter records the start of a nested structure or vector, which must be
+// matched by a call to leave.
+func (d *decodeState) enter(field string) error {
+	if d == nil {
+		return nil
+	}
+	d.depth++
+	if d.limits.MaxDepth > 0 && d.depth > d.limits.MaxDepth {
+		return &LimitError{Field: field, Kind: DepthLimit, Value: uint64(d.depth), Max: uint64(d.limits.MaxDepth)}
+	}
+	return nil
+}
+
+func (d *decodeState) leave() {
+	if d != nil {
+		d.depth--
+	}
+}
+
+// vector checks the encoded length of a variable-length vector.
+func (d *decodeState) vector(field string, length uint64) error {
+	if d == nil || d.limits.MaxVectorLength == 0 || length <= d.limits.MaxVectorLength {
+		return nil
+	}
+	return &LimitError{Field: field, Kind: VectorLengthLimit, Value: length, Max: d.limits.MaxVectorLength}
+}
+
+// alloc records the allocation of size bytes.
+func (d *decodeState) alloc(field string, size uint64) error {
+	if d == nil {
+		return nil
+	}
+	d.allocated += size
+	if d.limits.MaxAllocation > 0 && d.allocated > d.limits.MaxAllocation {
+		return &LimitError{Field: field, Kind: AllocationLimit, Value: d.allocated, Max: d.limits.MaxAllocation}
+	}
+	return nil
+}
+
 // Uint24 is an unsigned 3-byte integer.
 type Uint24 uint32
 
@@ -96,13 +207,21 @@ var (
 //	struct { }	struct { }
 //	select(T) {
 //	 case e1: Type	*T		selector:Field,val:e1
+//	 case e2, e3: Type	*T		selector:Field,val:e2|e3
+//	 default: Type	*T		selector:Field,default
 //	}
 //
 // TLS variants (RFC 5246 s4.6.1) are only supported when the value of the
 // associated enumeration type is available earlier in the same enclosing
 // struct, and each possible variant is marked with a selector tag (to
 // indicate which field selects the variants) and a val tag (to indicate
-// what value of the selector picks this particular field).
+// what value of the selector picks this particular field). The selector can
+// be a tls.Enum (or a type based on it) or any other unsigned integer type.
+// A val tag can list several values separated by '|', for variants which
+// share a type. A variant marked default is picked when the selector has
+// none of the values listed by the other variants; as TLS does not encode
+// the length of variants, its type must delimit itself, for example by
+// holding a single variable-length vector.
 //
 // For example, a TLS structure:
 //
@@ -150,6 +269,18 @@ func Unmarshal(b []byte, val interface{}) ([]byte, error) {
 // UnmarshalWithParams allows field parameters to be specified for the
 // top-level element. The form of the params is the same as the field tags.
 func UnmarshalWithParams(b []byte, val interface{}, params string) ([]byte, error) {
+	return unmarshal(b, val, params, nil)
+}
+
+// UnmarshalWithLimits is like UnmarshalWithParams, but decodes the data
+// within the given limits, for data from untrusted sources. If the data
+// exceeds a limit, it returns a *LimitError. Vectors of structures are also
+// rejected if one of their elements is encoded as nothing.
+func UnmarshalWithLimits(b []byte, val interface{}, params string, limits DecodeLimits) ([]byte, error) {
+	return unmarshal(b, val, params, &decodeState{limits: limits})
+}
+
+func unmarshal(b []byte, val interface{}, params string, d *decodeState) ([]byte, error) {
 	info, err := fieldTagToFieldInfo(params, "")
 	if err != nil {
 		return nil, err
@@ -158,7 +289,7 @@ func UnmarshalWithParams(b []byte, val interface{}, params string) ([]byte, erro
 	// to); extract the pointed-to object as a reflect.Value, so parseField
 	// can do various introspection things.
 	v := reflect.ValueOf(val).Elem()
-	offset, err := parseField(v, b, 0, info)
+	offset, err := parseField(v, b, 0, info, d)
 	if err != nil {
 		return nil, err
 	}
@@ -188,13 +319,14 @@ func byteCount(x uint64) uint {
 }
 
 type fieldInfo struct {
-	count    uint // Number of bytes
-	countSet bool
-	minlen   uint64 // Only relevant for slices
-	maxlen   uint64 // Only relevant for slices
-	selector string // Only relevant for select sub-values
-	val      uint64 // Only relevant for select sub-values
-	name     string // Used for better error messages
+	count     uint // Number of bytes
+	countSet  bool
+	minlen    uint64   // Only relevant for slices
+	maxlen    uint64   // Only relevant for slices
+	selector  string   // Only relevant for select sub-values
+	vals      []uint64 // Only relevant for select sub-values
+	isDefault bool     // Only relevant for select sub-values
+	name      string   // Used for better error messages
 }
 
 func (i *fieldInfo) fieldName() string {
@@ -244,14 +376,27 @@ func fieldTagToFieldInfo(str string, name string) (*fieldInfo, error) {
 			}
 			info.selector = part[9:]
 		case strings.HasPrefix(part, "val:"):
-			v, err := strconv.ParseUint(part[4:], 10, 64)
-			if err != nil {
+			var vals []uint64
+			for _, val := range strings.Split(part[4:], "|") {
+				v, err := strconv.ParseUint(val, 10, 64)
+				if err != nil {
+					vals = nil
+					break
+				}
+				vals = append(vals, v)
+			}
+			if vals == nil {
 				continue
 			}
 			if info == nil {
 				info = &fieldInfo{}
 			}
-			info.val = v
+			info.vals = vals
+		case part == "default":
+			if info == nil {
+				info = &fieldInfo{}
+			}
+			info.isDefault = true
 		}
 	}
 	if info != nil {
@@ -263,9 +408,11 @@ func fieldTagToFieldInfo(str string, name string) (*fieldInfo, error) {
 				return nil, structuralError{name, "specified size too large in " + str}
 			} else if info.minlen > info.maxlen {
 				return nil, structuralError{name, "specified length range inverted in " + str}
-			} else if info.val > 0 {
+			} else if len(info.vals) > 0 || info.isDefault {
 				return nil, structuralError{name, "specified selector value but not field in " + str}
 			}
+		} else if len(info.vals) > 0 && info.isDefault {
+			return nil, structuralError{name, "specified both selector values and default in " + str}
 		}
 	} else if name != "" {
 		info = &fieldInfo{name: name}
@@ -273,6 +420,61 @@ func fieldTagToFieldInfo(str string, name string) (*fieldInfo, error) {
 	return info, nil
 }
 
+// chosen reports whether a variant field described by i is selected by the
+// selector value choice; listed holds the values listed by all the variants
+// of the selector.
+func (i *fieldInfo) chosen(choice uint64, listed map[uint64]bool) bool {
+	if i.isDefault {
+		return !listed[choice]
+	}
+	for _, val := range i.selectorVals() {
+		if val == choice {
+			return true
+		}
+	}
+	return false
+}
+
+// selectorVals returns the selector values listed for a variant field; a
+// variant with no val tag is picked by zero.
+func (i *fieldInfo) selectorVals() []uint64 {
+	if len(i.vals) == 0 && !i.isDefault {
+		return []uint64{0}
+	}
+	return i.vals
+}
+
+// selectorValues returns, for each selector of the variants of structType,
+// the values listed by its variants.
+func selectorValues(structType reflect.Type) (map[string]map[uint64]bool, error) {
+	listed := make(map[string]map[uint64]bool)
+	for i := 0; i < structType.NumField(); i++ {
+		info, err := fieldTagToFieldInfo(structType.Field(i).Tag.Get("tls"), structType.Field(i).Name)
+		if err != nil {
+			return nil, err
+		}
+		if info == nil || info.selector == "" {
+			continue
+		}
+		if listed[info.selector] == nil {
+			listed[info.selector] = make(map[uint64]bool)
+		}
+		for _, val := range info.selectorVals() {
+			listed[info.selector][val] = true
+		}
+	}
+	return listed, nil
+}
+
+// isSelectorKind reports whether a field of kind k can select variants.
+func isSelectorKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
 // Check that a value fits into a field described by a fieldInfo structure.
 func (i fieldInfo) check(val uint64, fldName string) error {
 	if val >= (1 << (8 * i.count)) {
@@ -310,8 +512,8 @@ func readVarUint(data []byte, info *fieldInfo) (uint64, error) {
 
 // parseField is the main parsing function. Given a byte slice and an offset
 // (in bytes) into the data, it will try to parse a suitable ASN.1 value out
-// and store it in the given Value.
-func parseField(v reflect.Value, data []byte, initOffset int, info *fieldInfo) (int, error) {
+// and store it in the given Value, within the limits tracked by d.
+func parseField(v reflect.Value, data []byte, initOffset int, info *fieldInfo, d *decodeState) (int, error) {
 	offset := initOffset
 	rest := data[offset:]
 
@@ -336,7 +538,7 @@ func parseField(v reflect.Value, data []byte, initOffset int, info *fieldInfo) (
 		if len(rest) < 3 {
 			return offset, syntaxError{info.fieldName(), "truncated uint24"}
 		}
-		v.SetUint(uint64(data[0])<<16 | uint64(data[1])<<8 | uint64(data[2]))
+		v.SetUint(uint64(rest[0])<<16 | uint64(rest[1])<<8 | uint64(rest[2]))
 		offset += 3
 		return offset, nil
 	case uint32Type:
@@ -368,6 +570,10 @@ func parseField(v reflect.Value, data []byte, initOffset int, info *fieldInfo) (
 		offset += int(info.count)
 		return offset, nil
 	case reflect.Struct:
+		if err := d.enter(info.fieldName()); err != nil {
+			return offset, err
+		}
+		defer d.leave()
 		structType := fieldType
 		// TLS includes a select(Enum) {..} construct, where the value of an enum
 		// indicates which variant field is present (like a C union). We require
@@ -382,6 +588,11 @@ func parseField(v reflect.Value, data []byte, initOffset int, info *fieldInfo) (
 
 		// To deal with this, we track any enum-like fields and their values...
 		enums := make(map[string]uint64)
+		// ... and the values listed for each selector, to find default variants.
+		listed, err := selectorValues(structType)
+		if err != nil {
+			return offset, err
+		}
 		// .. and we track which selector names we've seen (in the destination field tags),
 		// and whether a destination for that selector has been chosen.
 		selectorSeen := make(map[string]bool)
@@ -409,7 +620,7 @@ func parseField(v reflect.Value, data []byte, initOffset int, info *fieldInfo) (
 				if !ok {
 					selectorSeen[fieldInfo.selector] = false
 				}
-				if choice != fieldInfo.val {
+				if !fieldInfo.chosen(choice, listed[fieldInfo.selector]) {
 					// This destination field was not the chosen one, so make it nil (we checked
 					// it was a pointer above).
 					v.Field(i).Set(reflect.Zero(structType.Field(i).Type))
@@ -422,16 +633,19 @@ func parseField(v reflect.Value, data []byte, initOffset int, info *fieldInfo) (
 				}
 				selectorSeen[fieldInfo.selector] = true
 				// Make an object of the pointed-to type and parse into that.
+				if err := d.alloc(fieldInfo.name, uint64(structType.Field(i).Type.Elem().Size())); err != nil {
+					return offset, err
+				}
 				v.Field(i).Set(reflect.New(structType.Field(i).Type.Elem()))
 				destination = v.Field(i).Elem()
 			}
-			offset, err = parseField(destination, data, offset, fieldInfo)
+			offset, err = parseField(destination, data, offset, fieldInfo, d)
 			if err != nil {
 				return offset, err
 			}
 
-			// Remember any possible tls.Enum values encountered in case they are selectors.
-			if structType.Field(i).Type.Kind() == enumType.Kind() {
+			// Remember any possible selector values encountered.
+			if isSelectorKind(structType.Field(i).Type.Kind()) {
 				enums[structType.Field(i).Name] = v.Field(i).Uint()
 			}
 
@@ -468,6 +682,9 @@ func parseField(v reflect.Value, data []byte, initOffset int, info *fieldInfo) (
 		if err != nil {
 			return offset, err
 		}
+		if err := d.vector(info.fieldName(), varlen); err != nil {
+			return offset, err
+		}
 		datalen := int(varlen)
 		offset += int(info.count)
 		rest = rest[info.count:]
@@ -479,19 +696,41 @@ func parseField(v reflect.Value, data []byte, initOffset int, info *fieldInfo) (
 		offset += datalen
 		if fieldType.Elem().Kind() == reflect.Uint8 {
 			// Fast version for []byte
+			if err := d.alloc(info.fieldName(), varlen); err != nil {
+				return offset, err
+			}
 			v.Set(reflect.MakeSlice(sliceType, datalen, datalen))
 			reflect.Copy(v, reflect.ValueOf(inner))
 			return offset, nil
 		}
 
-		v.Set(reflect.MakeSlice(sliceType, 0, datalen))
+		if err := d.enter(info.fieldName()); err != nil {
+			return offset, err
+		}
+		defer d.leave()
+		if d == nil {
+			v.Set(reflect.MakeSlice(sliceType, 0, datalen))
+		} else {
+			// Elements can be much larger in memory than in their encoding,
+			// so grow the slice as they are parsed, within the limits.
+			v.Set(reflect.MakeSlice(sliceType, 0, 0))
+		}
 		single := reflect.New(sliceType.Elem())
 		for innerOffset := 0; innerOffset < len(inner); {
+			start := innerOffset
 			var err error
-			innerOffset, err = parseField(single.Elem(), inner, innerOffset, nil)
+			innerOffset, err = parseField(single.Elem(), inner, innerOffset, nil, d)
 			if err != nil {
 				return offset, err
 			}
+			if d != nil {
+				if innerOffset == start {
+					return offset, syntaxError{info.fieldName(), "empty vector element"}
+				}
+				if err := d.alloc(info.fieldName(), uint64(sliceType.Elem().Size())); err != nil {
+					return offset, err
+				}
+			}
 			v.Set(reflect.Append(v, single.Elem()))
 		}
 		return offset, nil
@@ -575,7 +814,11 @@ func marshalField(out *bytes.Buffer, v reflect.Value, info *fieldInfo) error {
 		return nil
 	case reflect.Struct:
 		structType := fieldType
-		enums := make(map[string]uint64) // Values of any Enum fields
+		enums := make(map[string]uint64) // Values of any selector fields
+		listed, err := selectorValues(structType)
+		if err != nil {
+			return err
+		}
 		// The comment parseField() describes the mapping of the TLS select(Enum) {..} construct;
 		// here we have selector and source (rather than destination) fields.
 
@@ -606,7 +849,7 @@ func marshalField(out *bytes.Buffer, v reflect.Value, info *fieldInfo) error {
 				if !ok {
 					selectorSeen[fieldInfo.selector] = false
 				}
-				if choice != fieldInfo.val {
+				if !fieldInfo.chosen(choice, listed[fieldInfo.selector]) {
 					// This source was not chosen; police that it should be nil.
 					if v.Field(i).Pointer() != uintptr(0) {
 						return structuralError{fieldInfo.name, "unchosen field is non-nil"}
@@ -632,8 +875,8 @@ func marshalField(out *bytes.Buffer, v reflect.Value, info *fieldInfo) error {
 			}
 			out.Write(fieldData.Bytes())
 
-			// Remember any tls.Enum values encountered in case they are selectors.
-			if structType.Field(i).Type.Kind() == enumType.Kind() {
+			// Remember any selector values encountered.
+			if isSelectorKind(structType.Field(i).Type.Kind()) {
 				enums[structType.Field(i).Name] = v.Field(i).Uint()
 			}
 		}
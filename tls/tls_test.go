@@ -17,6 +17,7 @@ package tls
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
@@ -52,6 +53,19 @@ type testAliasEnum struct {
 	Val32 *uint32   `tls:"selector:Val,val:2"`
 }
 
+type testMultiValVariant struct {
+	Which Enum           `tls:"size:1"`
+	Val16 *uint16        `tls:"selector:Which,val:1|2"`
+	Val32 *uint32        `tls:"selector:Which,val:3"`
+	Other *testInnerType `tls:"selector:Which,default"`
+}
+
+type testUintSelector struct {
+	Which uint16
+	Val16 *uint16   `tls:"selector:Which,val:258"`
+	Void  *struct{} `tls:"selector:Which,val:0"`
+}
+
 type testNonByteSlice struct {
 	Vals []uint16 `tls:"minlen:2,maxlen:6"`
 }
@@ -68,6 +82,19 @@ type testSliceOfSlices struct {
 	Inners []testInnerType `tls:"minlen:0,maxlen:65535"`
 }
 
+type testUint24Field struct {
+	Prefix uint8
+	Val    Uint24
+}
+
+type testNestedSlices struct {
+	Kids []testNestedSlices `tls:"minlen:0,maxlen:255"`
+}
+
+type testEmptyElements struct {
+	Vals []struct{} `tls:"minlen:0,maxlen:255"`
+}
+
 func TestMarshalUnmarshalRoundTrip(t *testing.T) {
 	thing := testStruct{Data: []byte{0x01, 0x02, 0x03}, IntVal: 42, Other: [4]byte{1, 2, 3, 4}, Enum: 17}
 	data, err := Marshal(thing)
@@ -135,8 +162,13 @@ func TestFieldTagToFieldInfo(t *testing.T) {
 		{"size:1x", nil, ""},
 		{"size:1,val:9", nil, "selector value"},
 		{"selector:Bob,val:x9", &fieldInfo{selector: "Bob"}, ""},
-		{"selector:Fred,val:1", &fieldInfo{selector: "Fred", val: 1}, ""},
-		{"val:9,selector:Fred,val:1", &fieldInfo{selector: "Fred", val: 1}, ""},
+		{"selector:Fred,val:1", &fieldInfo{selector: "Fred", vals: []uint64{1}}, ""},
+		{"val:9,selector:Fred,val:1", &fieldInfo{selector: "Fred", vals: []uint64{1}}, ""},
+		{"selector:Fred,val:1|3", &fieldInfo{selector: "Fred", vals: []uint64{1, 3}}, ""},
+		{"selector:Fred,val:1|x", &fieldInfo{selector: "Fred"}, ""},
+		{"selector:Fred,default", &fieldInfo{selector: "Fred", isDefault: true}, ""},
+		{"size:1,default", nil, "selector value"},
+		{"selector:Fred,val:1,default", nil, "both selector values and default"},
 	}
 	for _, test := range tests {
 		got, err := fieldTagToFieldInfo(test.tag, "")
@@ -210,6 +242,13 @@ func TestUnmarshalMarshalWithParamsRoundTrip(t *testing.T) {
 			},
 		},
 		{"011011", "", &testAliasEnum{Val: 1, Val16: newUint16(0x1011)}},
+		{"01020304", "", &testUint24Field{Prefix: 1, Val: 0x020304}},
+		{"010102", "", &testMultiValVariant{Which: 1, Val16: newUint16(0x0102)}},
+		{"020102", "", &testMultiValVariant{Which: 2, Val16: newUint16(0x0102)}},
+		{"0301020304", "", &testMultiValVariant{Which: 3, Val32: newUint32(0x01020304)}},
+		{"09000201ff", "", &testMultiValVariant{Which: 9, Other: &testInnerType{Val: []byte{0x01, 0xff}}}},
+		{"01020304", "", &testUintSelector{Which: 0x0102, Val16: newUint16(0x0304)}},
+		{"0000", "", &testUintSelector{Which: 0, Void: &struct{}{}}},
 		{"0403", "", &SignatureAndHashAlgorithm{Hash: SHA256, Signature: ECDSA}},
 		{"04030003010203", "",
 			&DigitallySigned{
@@ -294,6 +333,8 @@ func TestUnmarshalWithParamsFailures(t *testing.T) {
 		{"092122", "", &testVariant{Which: 0, Val16: newUint16(0x2122)}, "unhandled value for selector"},
 		{"0001020304", "", &testDuplicateSelectorVal{Which: 0, Val: newUint16(0x0102)}, "duplicate selector value"},
 		{"0102", "", &testMissingSelector{Val: newUint16(1)}, "selector not seen"},
+		{"090003", "", &testMultiValVariant{}, "truncated"},
+		{"0001", "", &testUintSelector{}, "unhandled value for selector"},
 		{"000007", "", &testChoiceNotPointer{Which: 0, Val: 7}, "choice field not a pointer type"},
 		{"05010102020303", "", &testNonByteSlice{Vals: []uint16{0x101, 0x202, 0x303}}, "truncated"},
 		{"0101", "size:2", newNonEnumAlias(0x0102), "unsupported type"},
@@ -341,6 +382,9 @@ func TestMarshalWithParamsFailures(t *testing.T) {
 		{testMissingSelector{Val: newUint16(1)}, "", "selector not seen"},
 		{testChoiceNotPointer{Which: 0, Val: 7}, "", "choice field not a pointer"},
 		{testDuplicateSelectorVal{Which: 0, Val: newUint16(1)}, "", "duplicate selector value"},
+		{testMultiValVariant{Which: 2, Val32: newUint32(1)}, "", "chosen field is nil"},
+		{testMultiValVariant{Which: 7, Val16: newUint16(1)}, "", "unchosen field is non-nil"},
+		{testMultiValVariant{Which: 7}, "", "chosen field is nil"},
 		{testNonByteSlice{Vals: []uint16{1, 2, 3, 4}}, "", "too large"},
 		{testSliceOfStructs{[]testVariant{{Which: 3}}}, "", "unhandled value for selector"},
 		{nonEnumAlias(0x0102), "", "unsupported type"},
@@ -353,3 +397,47 @@ func TestMarshalWithParamsFailures(t *testing.T) {
 		}
 	}
 }
+
+func TestUnmarshalWithLimits(t *testing.T) {
+	var tests = []struct {
+		desc     string
+		in       string // hex encoded
+		item     interface{}
+		limits   DecodeLimits
+		errstr   string // Empty if no error is expected.
+		wantKind LimitKind
+	}{
+		{desc: "unlimited", in: "0003010101", item: &testInnerType{}},
+		{desc: "defaults", in: "0003010101", item: &testInnerType{}, limits: DefaultDecodeLimits},
+		{desc: "vector-length", in: "0003010101", item: &testInnerType{}, limits: DecodeLimits{MaxVectorLength: 2}, errstr: "Val: vector length 3 over maximum 2", wantKind: VectorLengthLimit},
+		{desc: "vector-length-ok", in: "0003010101", item: &testInnerType{}, limits: DecodeLimits{MaxVectorLength: 3}},
+		{desc: "depth", in: "03020100", item: &testNestedSlices{}, limits: DecodeLimits{MaxDepth: 6}, errstr: "nesting depth 7 over maximum 6", wantKind: DepthLimit},
+		{desc: "depth-ok", in: "03020100", item: &testNestedSlices{}, limits: DecodeLimits{MaxDepth: 8}},
+		{desc: "allocation-bytes", in: "0004aabbccdd", item: &testInnerType{}, limits: DecodeLimits{MaxAllocation: 3}, errstr: "Val: allocation 4 over maximum 3", wantKind: AllocationLimit},
+		{desc: "allocation-elements", in: "0008" + "0000000000000000", item: &testSliceOfSlices{}, limits: DecodeLimits{MaxAllocation: 50}, errstr: "Inners: allocation", wantKind: AllocationLimit},
+		{desc: "allocation-variant", in: "000102", item: &testVariant{}, limits: DecodeLimits{MaxAllocation: 1}, errstr: "Val16: allocation 2 over maximum 1", wantKind: AllocationLimit},
+		{desc: "empty-elements", in: "0100", item: &testEmptyElements{}, errstr: "empty vector element"},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			in, err := hex.DecodeString(test.in)
+			if err != nil {
+				t.Fatalf("failed to decode %q: %v", test.in, err)
+			}
+			_, err = UnmarshalWithLimits(in, test.item, "", test.limits)
+			if test.errstr == "" {
+				if err != nil {
+					t.Errorf("UnmarshalWithLimits(%s)=_,%v; want _,nil", test.in, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.errstr) {
+				t.Fatalf("UnmarshalWithLimits(%s)=_,%v; want error %q", test.in, err, test.errstr)
+			}
+			var limitErr *LimitError
+			if errors.As(err, &limitErr) && limitErr.Kind != test.wantKind {
+				t.Errorf("UnmarshalWithLimits(%s)=_,%v; want limit kind %v", test.in, err, test.wantKind)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tls
+
+import (
+	"fmt"
+)
+
+// This file holds the structures of Certificate Transparency version 2, as
+// defined in RFC 9162 (RFC 6962-bis), which are all carried in a TransItem.
+
+// VersionedTransType enum from RFC 9162 s4.4, which identifies the contents
+// of a TransItem.
+type VersionedTransType Enum
+
+// VersionedTransType constants from RFC 9162 s4.4.
+const (
+	ReservedTransType  VersionedTransType = 0
+	X509EntryV2        VersionedTransType = 1
+	PrecertEntryV2     VersionedTransType = 2
+	X509SCTV2          VersionedTransType = 3
+	PrecertSCTV2       VersionedTransType = 4
+	SignedTreeHeadV2   VersionedTransType = 5
+	ConsistencyProofV2 VersionedTransType = 6
+	InclusionProofV2   VersionedTransType = 7
+)
+
+func (t VersionedTransType) String() string {
+	switch t {
+	case ReservedTransType:
+		return "Reserved"
+	case X509EntryV2:
+		return "X509EntryV2"
+	case PrecertEntryV2:
+		return "PrecertEntryV2"
+	case X509SCTV2:
+		return "X509SCTV2"
+	case PrecertSCTV2:
+		return "PrecertSCTV2"
+	case SignedTreeHeadV2:
+		return "SignedTreeHeadV2"
+	case ConsistencyProofV2:
+		return "ConsistencyProofV2"
+	case InclusionProofV2:
+		return "InclusionProofV2"
+	default:
+		return fmt.Sprintf("UNKNOWN(%d)", t)
+	}
+}
+
+// TransItem is the container of all the CT v2 data structures, from RFC 9162
+// s4.4. Exactly one of the pointer fields is set, as selected by
+// VersionedType.
+type TransItem struct {
+	VersionedType          VersionedTransType                 `tls:"maxval:65535"`
+	X509EntryV2Data        *TimestampedCertificateEntryDataV2 `tls:"selector:VersionedType,val:1"`
+	PrecertEntryV2Data     *TimestampedCertificateEntryDataV2 `tls:"selector:VersionedType,val:2"`
+	X509SCTV2Data          *SignedCertificateTimestampDataV2  `tls:"selector:VersionedType,val:3"`
+	PrecertSCTV2Data       *SignedCertificateTimestampDataV2  `tls:"selector:VersionedType,val:4"`
+	SignedTreeHeadV2Data   *SignedTreeHeadDataV2              `tls:"selector:VersionedType,val:5"`
+	ConsistencyProofV2Data *ConsistencyProofDataV2            `tls:"selector:VersionedType,val:6"`
+	InclusionProofV2Data   *InclusionProofDataV2              `tls:"selector:VersionedType,val:7"`
+}
+
+// SctExtensionType enum from RFC 9162 s4.5.
+type SctExtensionType Enum
+
+// SctExtension is an extension of an SCT, from RFC 9162 s4.5.
+type SctExtension struct {
+	SctExtensionType SctExtensionType `tls:"maxval:65535"`
+	SctExtensionData []byte           `tls:"minlen:0,maxlen:65535"`
+}
+
+// ExtensionType enum from RFC 9162 s4.7.
+type ExtensionType Enum
+
+// Extension is an extension of a tree head, from RFC 9162 s4.7.
+type Extension struct {
+	ExtensionType ExtensionType `tls:"maxval:65535"`
+	ExtensionData []byte        `tls:"minlen:0,maxlen:65535"`
+}
+
+// NodeHash is the hash of a node of a Merkle tree, from RFC 9162 s4.7.
+type NodeHash struct {
+	Value []byte `tls:"minlen:32,maxlen:255"`
+}
+
+// TimestampedCertificateEntryDataV2 is the data of a log entry, from RFC
+// 9162 s4.3. TBSCertificate is the DER encoding of the TBSCertificate of the
+// certificate, or of the pre-certificate without its poison extension.
+type TimestampedCertificateEntryDataV2 struct {
+	Timestamp      uint64
+	IssuerKeyHash  []byte         `tls:"minlen:32,maxlen:255"`
+	TBSCertificate []byte         `tls:"minlen:1,maxlen:16777215"`
+	SctExtensions  []SctExtension `tls:"minlen:0,maxlen:65535"`
+}
+
+// SignedCertificateTimestampDataV2 is a v2 SCT, from RFC 9162 s4.8. LogID is
+// the contents of the DER encoding of the log's OID, without its tag and
+// length octets.
+type SignedCertificateTimestampDataV2 struct {
+	LogID         []byte `tls:"minlen:2,maxlen:127"`
+	Timestamp     uint64
+	SctExtensions []SctExtension `tls:"minlen:0,maxlen:65535"`
+	Signature     []byte         `tls:"minlen:0,maxlen:65535"`
+}
+
+// TreeHeadDataV2 is the tree head signed in a SignedTreeHeadDataV2, from RFC
+// 9162 s4.9.
+type TreeHeadDataV2 struct {
+	Timestamp  uint64
+	TreeSize   uint64
+	RootHash   NodeHash
+	Extensions []Extension `tls:"minlen:0,maxlen:65535"`
+}
+
+// SignedTreeHeadDataV2 is a v2 STH, from RFC 9162 s4.10.
+type SignedTreeHeadDataV2 struct {
+	LogID     []byte `tls:"minlen:2,maxlen:127"`
+	TreeHead  TreeHeadDataV2
+	Signature []byte `tls:"minlen:0,maxlen:65535"`
+}
+
+// ConsistencyProofDataV2 is a v2 consistency proof between two tree sizes,
+// from RFC 9162 s4.11.
+type ConsistencyProofDataV2 struct {
+	LogID           []byte `tls:"minlen:2,maxlen:127"`
+	TreeSize1       uint64
+	TreeSize2       uint64
+	ConsistencyPath []NodeHash `tls:"minlen:0,maxlen:65535"`
+}
+
+// InclusionProofDataV2 is a v2 inclusion proof of a leaf, from RFC 9162
+// s4.12.
+type InclusionProofDataV2 struct {
+	LogID         []byte `tls:"minlen:2,maxlen:127"`
+	TreeSize      uint64
+	LeafIndex     uint64
+	InclusionPath []NodeHash `tls:"minlen:0,maxlen:65535"`
+}
+
+// ParseTransItem parses a TransItem from data, which must hold nothing else.
+func ParseTransItem(data []byte) (*TransItem, error) {
+	var item TransItem
+	rest, err := Unmarshal(data, &item)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, syntaxError{"TransItem", fmt.Sprintf("%d bytes of trailing data", len(rest))}
+	}
+	return &item, nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tls
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTransItemRoundTrip(t *testing.T) {
+	logID := []byte{0x2b, 0x06, 0x01, 0x04, 0x01}
+	hash := func(b byte) NodeHash { return NodeHash{Value: bytes.Repeat([]byte{b}, 32)} }
+	entry := &TimestampedCertificateEntryDataV2{
+		Timestamp:      1234,
+		IssuerKeyHash:  bytes.Repeat([]byte{0x11}, 32),
+		TBSCertificate: []byte{0x30, 0x00},
+		SctExtensions:  []SctExtension{},
+	}
+	sct := &SignedCertificateTimestampDataV2{
+		LogID:         logID,
+		Timestamp:     1234,
+		SctExtensions: []SctExtension{{SctExtensionType: 1, SctExtensionData: []byte{0xff}}},
+		Signature:     []byte{0x01, 0x02},
+	}
+	for _, item := range []TransItem{
+		{VersionedType: X509EntryV2, X509EntryV2Data: entry},
+		{VersionedType: PrecertEntryV2, PrecertEntryV2Data: entry},
+		{VersionedType: X509SCTV2, X509SCTV2Data: sct},
+		{VersionedType: PrecertSCTV2, PrecertSCTV2Data: sct},
+		{VersionedType: SignedTreeHeadV2, SignedTreeHeadV2Data: &SignedTreeHeadDataV2{
+			LogID: logID,
+			TreeHead: TreeHeadDataV2{
+				Timestamp:  5678,
+				TreeSize:   10,
+				RootHash:   hash(0x22),
+				Extensions: []Extension{{ExtensionType: 2, ExtensionData: []byte{}}},
+			},
+			Signature: []byte{0x03},
+		}},
+		{VersionedType: ConsistencyProofV2, ConsistencyProofV2Data: &ConsistencyProofDataV2{
+			LogID:           logID,
+			TreeSize1:       4,
+			TreeSize2:       10,
+			ConsistencyPath: []NodeHash{hash(0x33), hash(0x44)},
+		}},
+		{VersionedType: InclusionProofV2, InclusionProofV2Data: &InclusionProofDataV2{
+			LogID:         logID,
+			TreeSize:      10,
+			LeafIndex:     3,
+			InclusionPath: []NodeHash{},
+		}},
+	} {
+		t.Run(item.VersionedType.String(), func(t *testing.T) {
+			data, err := Marshal(item)
+			if err != nil {
+				t.Fatalf("Marshal()=nil,%v; want _,nil", err)
+			}
+			got, err := ParseTransItem(data)
+			if err != nil {
+				t.Fatalf("ParseTransItem(%x)=nil,%v; want _,nil", data, err)
+			}
+			if !reflect.DeepEqual(*got, item) {
+				t.Errorf("ParseTransItem(Marshal(%+v))=%+v", item, *got)
+			}
+		})
+	}
+}
+
+func TestTransItemEncoding(t *testing.T) {
+	item := TransItem{VersionedType: InclusionProofV2, InclusionProofV2Data: &InclusionProofDataV2{
+		LogID:         []byte{0x2b, 0x06},
+		TreeSize:      2,
+		LeafIndex:     1,
+		InclusionPath: []NodeHash{{Value: bytes.Repeat([]byte{0xaa}, 32)}},
+	}}
+	want := "0007" + // versioned_type
+		"022b06" + // log_id
+		"0000000000000002" + // tree_size
+		"0000000000000001" + // leaf_index
+		"0021" + "20" + strings.Repeat("aa", 32) // inclusion_path
+	data, err := Marshal(item)
+	if err != nil {
+		t.Fatalf("Marshal()=nil,%v; want _,nil", err)
+	}
+	if got := hex.EncodeToString(data); got != want {
+		t.Errorf("Marshal()=%s; want %s", got, want)
+	}
+}
+
+func TestParseTransItemErrors(t *testing.T) {
+	for _, test := range []struct {
+		desc string
+		data string
+		want string
+	}{
+		{desc: "reserved", data: "0000", want: "unhandled value"},
+		{desc: "unknown-type", data: "0008", want: "unhandled value"},
+		{desc: "short-log-id", data: "0007" + "012b" + "0000000000000002" + "0000000000000001" + "0000", want: "too small"},
+		{desc: "short-hash", data: "0007" + "022b06" + "0000000000000002" + "0000000000000001" + "0002" + "01aa", want: "too small"},
+		{desc: "truncated", data: "0007" + "022b06" + "00000000", want: "truncated"},
+		{desc: "trailing", data: "0007" + "022b06" + "0000000000000002" + "0000000000000001" + "0000" + "00", want: "trailing data"},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			data, err := hex.DecodeString(test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = ParseTransItem(data)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("ParseTransItem(%s)=_,%v; want error containing %q", test.data, err, test.want)
+			}
+		})
+	}
+}
+
+func TestMarshalTransItemWrongVariant(t *testing.T) {
+	item := TransItem{VersionedType: X509SCTV2, PrecertSCTV2Data: &SignedCertificateTimestampDataV2{LogID: []byte{1, 2}}}
+	if _, err := Marshal(item); err == nil {
+		t.Error("Marshal(mismatched variant)=_,nil; want error")
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/internal/journal"
+	"github.com/RarimoVoting/certificate-transparency-go/schedule"
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/util"
+	"github.com/google/trillian/monitoring"
+	"k8s.io/klog/v2"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+)
+
+var (
+	anchorOnce        sync.Once
+	anchorLag         monitoring.Gauge   // logid => value
+	anchoredTreeSize  monitoring.Gauge   // logid => value
+	anchoredTimestamp monitoring.Gauge   // logid => value
+	anchorSubmissions monitoring.Counter // logid => value
+	anchorFailures    monitoring.Counter // logid => value
+)
+
+func setupAnchorMetrics(mf monitoring.MetricFactory) {
+	anchorLag = mf.NewGauge("sth_anchor_lag_seconds", "Seconds between the timestamps of the latest STH and the latest anchored STH", "logid")
+	anchoredTreeSize = mf.NewGauge("sth_anchored_tree_size", "Tree size of the latest anchored STH", "logid")
+	anchoredTimestamp = mf.NewGauge("sth_anchored_timestamp", "Time of the latest anchored STH in ms since epoch", "logid")
+	anchorSubmissions = mf.NewCounter("sth_anchor_submissions", "Number of STHs submitted for anchoring", "logid")
+	anchorFailures = mf.NewCounter("sth_anchor_failures", "Number of failed attempts to anchor an STH", "logid")
+}
+
+// Anchorer publishes STHs to an external, append-only ledger such as a
+// blockchain, so that the history of a log's tree heads can be verified
+// independently of the log.
+type Anchorer interface {
+	// Anchor publishes the STH of the log with the given ID, and returns an
+	// identifier for the ledger record, e.g. a transaction hash.
+	Anchor(ctx context.Context, logID [32]byte, sth *ct.SignedTreeHead) (string, error)
+}
+
+// AnchorRecord describes an STH which has been anchored.
+type AnchorRecord struct {
+	LogPrefix  string    `json:"log_prefix"`
+	TreeSize   uint64    `json:"tree_size"`
+	Timestamp  uint64    `json:"timestamp"`
+	RootHash   []byte    `json:"sha256_root_hash"`
+	TxHash     string    `json:"tx_hash"`
+	AnchoredAt time.Time `json:"anchored_at"`
+}
+
+// AnchorRecords is a file of JSON AnchorRecord lines, one appended for each
+// anchored STH of any log, which remembers the last STH anchored for each log
+// across restarts. It is safe for concurrent use.
+type AnchorRecords struct {
+	mu   sync.Mutex
+	j    *journal.Journal
+	last map[string]*AnchorRecord // by log prefix
+}
+
+// OpenAnchorRecords opens the anchor records file at path, creating it if
+// needed.
+func OpenAnchorRecords(path string) (*AnchorRecords, error) {
+	r := &AnchorRecords{last: make(map[string]*AnchorRecord)}
+	j, err := journal.Open(path, func(line []byte) error {
+		var rec AnchorRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return err
+		}
+		if rec.LogPrefix == "" {
+			return errors.New("missing log prefix")
+		}
+		r.last[rec.LogPrefix] = &rec
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.j = j
+	return r, nil
+}
+
+// Last returns the last record of the log with the given prefix, or nil if
+// none of its STHs has been anchored.
+func (r *AnchorRecords) Last(prefix string) *AnchorRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last[prefix]
+}
+
+// Append appends a record.
+func (r *AnchorRecords) Append(rec *AnchorRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.j.Append(rec); err != nil {
+		return fmt.Errorf("failed to write anchor record: %v", err)
+	}
+	r.last[rec.LogPrefix] = rec
+	return nil
+}
+
+// Close closes the file.
+func (r *AnchorRecords) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.j.Close()
+}
+
+// STHAnchor periodically anchors the latest STH of a log.
+type STHAnchor struct {
+	anchorer Anchorer
+	// records, if set, has a record appended for each anchored STH.
+	records *AnchorRecords
+
+	inst       *Instance
+	logID      [32]byte
+	label      string
+	timeSource util.TimeSource
+
+	mu   sync.Mutex
+	last *AnchorRecord
+}
+
+// NewSTHAnchor creates an STHAnchor which anchors the STHs of the given log
+// with a, and appends a record of each to records if it is not nil. The last
+// STH of the log in records is taken to be anchored already.
+func NewSTHAnchor(inst *Instance, a Anchorer, records *AnchorRecords, mf monitoring.MetricFactory) (*STHAnchor, error) {
+	anchorOnce.Do(func() { setupAnchorMetrics(mf) })
+	logID, err := inst.ctLogID()
+	if err != nil {
+		return nil, err
+	}
+	s := &STHAnchor{
+		anchorer:   a,
+		records:    records,
+		inst:       inst,
+		logID:      logID,
+		label:      strconv.FormatInt(inst.li.logID, 10),
+		timeSource: inst.li.TimeSource,
+	}
+	if records != nil {
+		if last := records.Last(inst.li.instanceOpts.Validated.Config.Prefix); last != nil {
+			s.last = last
+			anchoredTreeSize.Set(float64(last.TreeSize), s.label)
+			anchoredTimestamp.Set(float64(last.Timestamp), s.label)
+		}
+	}
+	return s, nil
+}
+
+// Last returns the most recently anchored STH, or nil if none has been.
+func (s *STHAnchor) Last() *AnchorRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+// Run anchors the log's latest STH every period, if it has changed since the
+// last one anchored, until the context is done.
+func (s *STHAnchor) Run(ctx context.Context, period time.Duration) {
+	schedule.Every(ctx, period, func(ctx context.Context) {
+		if err := s.anchorLatest(ctx); err != nil {
+			anchorFailures.Inc(s.label)
+			klog.Warningf("%s: failed to anchor STH: %v", s.inst.li.LogPrefix, err)
+		}
+	})
+}
+
+// anchorLatest anchors the log's latest STH, unless it is already anchored.
+func (s *STHAnchor) anchorLatest(ctx context.Context) error {
+	sth, err := s.inst.li.getSTH(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get STH: %v", err)
+	}
+	s.mu.Lock()
+	last := s.last
+	s.mu.Unlock()
+	if last != nil && last.TreeSize == sth.TreeSize && last.Timestamp == sth.Timestamp {
+		anchorLag.Set(0, s.label)
+		return nil
+	}
+	if last != nil {
+		anchorLag.Set(float64(sth.Timestamp-last.Timestamp)/1000, s.label)
+	}
+
+	anchorSubmissions.Inc(s.label)
+	txHash, err := s.anchorer.Anchor(ctx, s.logID, sth)
+	if err != nil {
+		return err
+	}
+	rec := &AnchorRecord{
+		LogPrefix:  s.inst.li.instanceOpts.Validated.Config.Prefix,
+		TreeSize:   sth.TreeSize,
+		Timestamp:  sth.Timestamp,
+		RootHash:   sth.SHA256RootHash[:],
+		TxHash:     txHash,
+		AnchoredAt: s.timeSource.Now(),
+	}
+	klog.Infof("%s: anchored STH at size %d in %s", s.inst.li.LogPrefix, rec.TreeSize, txHash)
+	s.mu.Lock()
+	s.last = rec
+	s.mu.Unlock()
+	anchorLag.Set(0, s.label)
+	anchoredTreeSize.Set(float64(rec.TreeSize), s.label)
+	anchoredTimestamp.Set(float64(rec.Timestamp), s.label)
+
+	if s.records != nil {
+		return s.records.Append(rec)
+	}
+	return nil
+}
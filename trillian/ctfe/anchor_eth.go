@@ -0,0 +1,193 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"golang.org/x/crypto/sha3"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+)
+
+// EthereumAnchorMethod is the signature of the contract method called by
+// EthereumAnchorer. The signature argument is the TLS-encoded
+// DigitallySigned tree head signature.
+const EthereumAnchorMethod = "anchor(bytes32,uint64,uint64,bytes32,bytes)"
+
+// EthereumAnchorer anchors STHs by calling EthereumAnchorMethod on a smart
+// contract, with a transaction sent through an Ethereum JSON-RPC endpoint.
+// The transaction is signed by the node, using its account for From. An STH
+// only counts as anchored once its transaction is mined without reverting.
+type EthereumAnchorer struct {
+	// URL is the JSON-RPC endpoint of the node.
+	URL string
+	// From is the hex address of the account which sends the transactions.
+	From string
+	// Contract is the hex address of the anchoring contract.
+	Contract string
+	// Client is used to make requests to the node.
+	Client *http.Client
+	// ReceiptPoll is the interval between requests for the receipt of a
+	// transaction; 5 seconds if zero.
+	ReceiptPoll time.Duration
+	// ReceiptTimeout bounds the wait for a transaction to be mined; 5 minutes
+	// if zero.
+	ReceiptTimeout time.Duration
+
+	nextID atomic.Int64
+}
+
+// Anchor implements Anchorer, returning the transaction hash.
+func (e *EthereumAnchorer) Anchor(ctx context.Context, logID [32]byte, sth *ct.SignedTreeHead) (string, error) {
+	sig, err := tls.Marshal(sth.TreeHeadSignature)
+	if err != nil {
+		return "", fmt.Errorf("failed to tls.Marshal signature: %s", err)
+	}
+	tx := map[string]string{
+		"from": e.From,
+		"to":   e.Contract,
+		"data": "0x" + hex.EncodeToString(ethereumAnchorCallData(logID, sth, sig)),
+	}
+	var txHash string
+	if err := e.call(ctx, "eth_sendTransaction", []interface{}{tx}, &txHash); err != nil {
+		return "", err
+	}
+	if err := e.waitForReceipt(ctx, txHash); err != nil {
+		return "", err
+	}
+	return txHash, nil
+}
+
+// waitForReceipt polls for the receipt of the transaction until it is mined,
+// and fails if the transaction reverted.
+func (e *EthereumAnchorer) waitForReceipt(ctx context.Context, txHash string) error {
+	poll, timeout := e.ReceiptPoll, e.ReceiptTimeout
+	if poll <= 0 {
+		poll = 5 * time.Second
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		// The receipt is null until the transaction is mined.
+		var receipt *struct {
+			Status string `json:"status"`
+		}
+		if err := e.call(ctx, "eth_getTransactionReceipt", []interface{}{txHash}, &receipt); err != nil {
+			return err
+		}
+		if receipt != nil {
+			if receipt.Status != "0x1" {
+				return fmt.Errorf("transaction %s failed with status %q", txHash, receipt.Status)
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("transaction %s not mined: %v", txHash, ctx.Err())
+		case <-time.After(poll):
+		}
+	}
+}
+
+// ethereumAnchorCallData returns the ABI-encoded call of EthereumAnchorMethod
+// for the STH.
+func ethereumAnchorCallData(logID [32]byte, sth *ct.SignedTreeHead, sig []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(EthereumAnchorMethod))
+	data := h.Sum(nil)[:4]
+
+	word := func(v uint64) []byte {
+		var w [32]byte
+		binary.BigEndian.PutUint64(w[24:], v)
+		return w[:]
+	}
+	data = append(data, logID[:]...)
+	data = append(data, word(sth.TreeSize)...)
+	data = append(data, word(sth.Timestamp)...)
+	data = append(data, sth.SHA256RootHash[:]...)
+	// The dynamic bytes argument is encoded after the five head words, as its
+	// length followed by its contents padded to a whole number of words.
+	data = append(data, word(5*32)...)
+	data = append(data, word(uint64(len(sig)))...)
+	data = append(data, sig...)
+	if pad := len(sig) % 32; pad != 0 {
+		data = append(data, make([]byte, 32-pad)...)
+	}
+	return data
+}
+
+// call makes a JSON-RPC request to the node, and unmarshals its result into
+// result.
+func (e *EthereumAnchorer) call(ctx context.Context, method string, params []interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      e.nextID.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(contentTypeHeader, contentTypeJSON)
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %v", method, err)
+	}
+	defer rsp.Body.Close()
+	rspBody, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %v", method, err)
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s request failed: %s: %s", method, rsp.Status, strings.TrimSpace(string(rspBody)))
+	}
+	var rpcRsp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rspBody, &rpcRsp); err != nil {
+		return fmt.Errorf("failed to parse %s response: %v", method, err)
+	}
+	if rpcRsp.Error != nil {
+		return fmt.Errorf("%s failed: %d: %s", method, rpcRsp.Error.Code, rpcRsp.Error.Message)
+	}
+	return json.Unmarshal(rpcRsp.Result, result)
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/trillian/monitoring"
+)
+
+func TestSTHAnchor(t *testing.T) {
+	signer, err := setupSigner(fakeSignature)
+	if err != nil {
+		t.Fatalf("Failed to create test signer: %v", err)
+	}
+	info := setupTest(t, nil, signer)
+	defer info.mockCtrl.Finish()
+	inst := &Instance{li: info.li}
+	rootHash := []byte("abcdabcdabcdabcdabcdabcdabcdabcd")
+	logID, err := GetCTLogID(signer.Public())
+	if err != nil {
+		t.Fatalf("GetCTLogID()=%v", err)
+	}
+
+	var txs []map[string]string
+	// receipts holds the results of the next receipt requests, after which
+	// transactions succeed.
+	var receipts []string
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Params) != 1 {
+			t.Errorf("node got request %+v, err %v; want one parameter", req, err)
+			return
+		}
+		switch req.Method {
+		case "eth_sendTransaction":
+			var tx map[string]string
+			if err := json.Unmarshal(req.Params[0], &tx); err != nil {
+				t.Errorf("failed to parse transaction %s: %v", req.Params[0], err)
+			}
+			txs = append(txs, tx)
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1234"}`))
+		case "eth_getTransactionReceipt":
+			if got := string(req.Params[0]); got != `"0x1234"` {
+				t.Errorf("receipt requested for %s, want 0x1234", got)
+			}
+			result := `{"status":"0x1"}`
+			if len(receipts) > 0 {
+				result, receipts = receipts[0], receipts[1:]
+			}
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":%s}`, result)
+		default:
+			t.Errorf("node got %s request", req.Method)
+		}
+	}))
+	defer node.Close()
+
+	anchorer := &EthereumAnchorer{URL: node.URL, From: "0xf00", Contract: "0xc0", ReceiptPoll: time.Millisecond}
+	path := filepath.Join(t.TempDir(), "anchors.jsonl")
+	records, err := OpenAnchorRecords(path)
+	if err != nil {
+		t.Fatalf("OpenAnchorRecords()=%v", err)
+	}
+	a, err := NewSTHAnchor(inst, anchorer, records, monitoring.InertMetricFactory{})
+	if err != nil {
+		t.Fatalf("NewSTHAnchor()=%v", err)
+	}
+	ctx := context.Background()
+
+	// The same STH is only anchored once, when its transaction is mined.
+	receipts = []string{"null"}
+	info.client.EXPECT().GetLatestSignedLogRoot(gomock.Any(), gomock.Any()).Return(makeGetRootResponseForTest(t, 12345000000, 25, rootHash), nil).Times(2)
+	for i := 0; i < 2; i++ {
+		if err := a.anchorLatest(ctx); err != nil {
+			t.Fatalf("anchorLatest()=%v", err)
+		}
+	}
+	if len(txs) != 1 {
+		t.Fatalf("sent %d transactions, want 1", len(txs))
+	}
+	if got := txs[0]["from"] + " " + txs[0]["to"]; got != "0xf00 0xc0" {
+		t.Errorf("transaction from, to=%s; want 0xf00 0xc0", got)
+	}
+	sig := append([]byte{0x04, 0x03, 0x00, 0x06}, fakeSignature...)
+	want := "0xd7d188fd" + hex.EncodeToString(logID[:]) +
+		strings.Repeat("0", 62) + "19" + // tree size
+		strings.Repeat("0", 60) + "3039" + // timestamp
+		hex.EncodeToString(rootHash) +
+		strings.Repeat("0", 62) + "a0" + // offset of signature
+		strings.Repeat("0", 62) + "0a" + // length of signature
+		hex.EncodeToString(sig) + strings.Repeat("0", 44)
+	if got := txs[0]["data"]; got != want {
+		t.Errorf("transaction data=%s\nwant %s", got, want)
+	}
+
+	last := a.Last()
+	if last == nil || last.TreeSize != 25 || last.TxHash != "0x1234" {
+		t.Errorf("Last()=%+v; want size 25 in 0x1234", last)
+	}
+	if len(receipts) != 0 {
+		t.Errorf("receipt requested %d fewer times than expected", len(receipts))
+	}
+	record, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile()=%v", err)
+	}
+	var rec AnchorRecord
+	if err := json.Unmarshal(record, &rec); err != nil {
+		t.Fatalf("failed to parse record %q: %v", record, err)
+	}
+	if rec.LogPrefix != "test" || rec.Timestamp != 12345 || !bytes.Equal(rec.RootHash, rootHash) || rec.TxHash != "0x1234" {
+		t.Errorf("record=%+v, want STH at 12345 in 0x1234", rec)
+	}
+
+	// A newer STH whose transaction reverts is not anchored.
+	info.client.EXPECT().GetLatestSignedLogRoot(gomock.Any(), gomock.Any()).Return(makeGetRootResponseForTest(t, 12346000000, 26, rootHash), nil)
+	receipts = []string{`{"status":"0x0"}`}
+	if err := a.anchorLatest(ctx); err == nil || !strings.Contains(err.Error(), "failed with status") {
+		t.Errorf("anchorLatest()=%v; want failed transaction error", err)
+	}
+	if got := a.Last().TreeSize; got != 25 {
+		t.Errorf("Last().TreeSize=%d after reverted transaction, want 25", got)
+	}
+
+	// After a restart, the anchored STH is not anchored again.
+	if err := records.Close(); err != nil {
+		t.Fatalf("Close()=%v", err)
+	}
+	records, err = OpenAnchorRecords(path)
+	if err != nil {
+		t.Fatalf("OpenAnchorRecords()=%v", err)
+	}
+	defer records.Close()
+	a, err = NewSTHAnchor(inst, anchorer, records, monitoring.InertMetricFactory{})
+	if err != nil {
+		t.Fatalf("NewSTHAnchor()=%v", err)
+	}
+	if last := a.Last(); last == nil || last.TreeSize != 25 || last.TxHash != "0x1234" {
+		t.Errorf("Last()=%+v after restart; want size 25 in 0x1234", last)
+	}
+	sent := len(txs)
+	info.client.EXPECT().GetLatestSignedLogRoot(gomock.Any(), gomock.Any()).Return(makeGetRootResponseForTest(t, 12345000000, 25, rootHash), nil)
+	if err := a.anchorLatest(ctx); err != nil {
+		t.Fatalf("anchorLatest()=%v", err)
+	}
+	if len(txs) != sent {
+		t.Errorf("anchored STH sent again after restart")
+	}
+
+	// A newer STH is anchored, and a node error is reported.
+	info.client.EXPECT().GetLatestSignedLogRoot(gomock.Any(), gomock.Any()).Return(makeGetRootResponseForTest(t, 12346000000, 26, rootHash), nil)
+	node.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":2,"error":{"code":-32000,"message":"insufficient funds"}}`))
+	})
+	if err := a.anchorLatest(ctx); err == nil || !strings.Contains(err.Error(), "insufficient funds") {
+		t.Errorf("anchorLatest()=%v; want insufficient funds error", err)
+	}
+	if got := a.Last().TreeSize; got != 25 {
+		t.Errorf("Last().TreeSize=%d after failure, want 25", got)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/configpb"
+	"github.com/google/trillian/monitoring"
+	"golang.org/x/time/rate"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+// APIKeyQuotaUserPrefix is prepended to the key name to form the User quota
+// id charged for submissions made with an API key.
+const APIKeyQuotaUserPrefix = "@apikey"
+
+var (
+	apiKeyOnce        sync.Once
+	apiKeySubmissions monitoring.Counter // key => value
+	apiKeyRateLimited monitoring.Counter // key => value
+	apiKeyRejections  monitoring.Counter // reason => value
+)
+
+// APIKey describes the holder of an API key.
+type APIKey struct {
+	// Name identifies the key holder.
+	Name string
+	// RateLimit is the number of submissions per second allowed with the key,
+	// or 0 for no limit.
+	RateLimit float64
+	// Burst is the number of submissions allowed in a burst above RateLimit.
+	Burst int
+}
+
+// QuotaUser returns the Trillian User quota id for submissions with the key.
+func (k *APIKey) QuotaUser() string {
+	return fmt.Sprintf("%s %s", APIKeyQuotaUserPrefix, k.Name)
+}
+
+// APIKeyStore looks up submitters' API keys.
+type APIKeyStore interface {
+	// LookupAPIKey returns the holder of the given key, or nil if the key is
+	// unknown.
+	LookupAPIKey(ctx context.Context, key string) (*APIKey, error)
+}
+
+// StaticAPIKeyStore is an APIKeyStore holding a fixed set of keys, indexed by
+// their SHA-256 hashes.
+type StaticAPIKeyStore struct {
+	keys map[[sha256.Size]byte]*APIKey
+}
+
+// NewStaticAPIKeyStore checks the given config and creates a
+// StaticAPIKeyStore holding its keys.
+func NewStaticAPIKeyStore(cfg *configpb.APIKeyConfig) (*StaticAPIKeyStore, error) {
+	s := &StaticAPIKeyStore{keys: make(map[[sha256.Size]byte]*APIKey)}
+	names := make(map[string]bool)
+	for _, k := range cfg.GetKeys() {
+		if len(k.Name) == 0 {
+			return nil, errors.New("empty API key name")
+		}
+		if names[k.Name] {
+			return nil, fmt.Errorf("duplicate API key name %q", k.Name)
+		}
+		names[k.Name] = true
+		hash, err := hex.DecodeString(k.KeySha256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("API key %q: key_sha256 is not a hex-encoded SHA-256 hash", k.Name)
+		}
+		if k.RateLimit < 0 {
+			return nil, fmt.Errorf("API key %q: negative rate limit", k.Name)
+		}
+		if k.Burst < 0 {
+			return nil, fmt.Errorf("API key %q: negative burst", k.Name)
+		}
+		var h [sha256.Size]byte
+		copy(h[:], hash)
+		if other, ok := s.keys[h]; ok {
+			return nil, fmt.Errorf("API keys %q and %q have the same hash", other.Name, k.Name)
+		}
+		s.keys[h] = &APIKey{Name: k.Name, RateLimit: k.RateLimit, Burst: int(k.Burst)}
+	}
+	return s, nil
+}
+
+// LookupAPIKey returns the holder of the given key, or nil if the key is
+// unknown.
+func (s *StaticAPIKeyStore) LookupAPIKey(_ context.Context, key string) (*APIKey, error) {
+	return s.keys[sha256.Sum256([]byte(key))], nil
+}
+
+// APIKeyConfigFromFile reads an APIKeyConfig from the given filename, which
+// should contain text or binary-encoded protobuf configuration data.
+func APIKeyConfigFromFile(filename string) (*configpb.APIKeyConfig, error) {
+	cfgBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg configpb.APIKeyConfig
+	if txtErr := prototext.Unmarshal(cfgBytes, &cfg); txtErr != nil {
+		if binErr := proto.Unmarshal(cfgBytes, &cfg); binErr != nil {
+			return nil, fmt.Errorf("failed to parse APIKeyConfig from %q as text protobuf (%v) or binary protobuf (%v)", filename, txtErr, binErr)
+		}
+	}
+	return &cfg, nil
+}
+
+// APIKeyAuthenticator authenticates add-chain and add-pre-chain submitters by
+// the API key given in the request's "Authorization: Bearer <key>" header,
+// and applies each key's rate limit. It can be shared by log instances, in
+// which case the rate limits apply across all of them.
+type APIKeyAuthenticator struct {
+	store    APIKeyStore
+	required bool
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter // key name => limiter
+}
+
+// NewAPIKeyAuthenticator creates an APIKeyAuthenticator for the keys in the
+// given store. If required is false, requests without an API key are
+// accepted (and charged quota as usual), but requests with an unknown key are
+// always rejected.
+func NewAPIKeyAuthenticator(store APIKeyStore, required bool, mf monitoring.MetricFactory) *APIKeyAuthenticator {
+	apiKeyOnce.Do(func() {
+		apiKeySubmissions = mf.NewCounter("api_key_submissions", "Number of submissions accepted with each API key", "key")
+		apiKeyRateLimited = mf.NewCounter("api_key_rate_limited", "Number of submissions rejected for exceeding the API key's rate limit", "key")
+		apiKeyRejections = mf.NewCounter("api_key_rejections", "Number of submissions rejected for a missing or unknown API key", "reason")
+	})
+	return &APIKeyAuthenticator{
+		store:    store,
+		required: required,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// authenticate returns the holder of the API key presented in a request's
+// Authorization header, or nil if there is none and keys are optional. If the
+// request must be rejected it returns the HTTP status to reject it with, and
+// an error.
+func (a *APIKeyAuthenticator) authenticate(ctx context.Context, auth string) (*APIKey, int, error) {
+	if a == nil {
+		return nil, http.StatusOK, nil
+	}
+	if len(auth) == 0 {
+		if a.required {
+			apiKeyRejections.Inc("missing")
+			return nil, http.StatusUnauthorized, errors.New("API key required")
+		}
+		return nil, http.StatusOK, nil
+	}
+	secret, ok := strings.CutPrefix(auth, "Bearer ")
+	if !ok {
+		apiKeyRejections.Inc("malformed")
+		return nil, http.StatusUnauthorized, errors.New("malformed Authorization header, want Bearer API key")
+	}
+	key, err := a.store.LookupAPIKey(ctx, secret)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to look up API key: %v", err)
+	}
+	if key == nil {
+		apiKeyRejections.Inc("unknown")
+		return nil, http.StatusUnauthorized, errors.New("unknown API key")
+	}
+	if !a.limiter(key).Allow() {
+		apiKeyRateLimited.Inc(key.Name)
+		return nil, http.StatusTooManyRequests, fmt.Errorf("rate limit exceeded for API key %q", key.Name)
+	}
+	apiKeySubmissions.Inc(key.Name)
+	return key, http.StatusOK, nil
+}
+
+// limiter returns the rate limiter for the given key, creating it if needed.
+func (a *APIKeyAuthenticator) limiter(key *APIKey) *rate.Limiter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	l, ok := a.limiters[key.Name]
+	if !ok {
+		limit := rate.Inf
+		if key.RateLimit > 0 {
+			limit = rate.Limit(key.RateLimit)
+		}
+		burst := key.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		l = rate.NewLimiter(limit, burst)
+		a.limiters[key.Name] = l
+	}
+	return l
+}
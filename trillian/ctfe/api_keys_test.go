@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/configpb"
+	"github.com/google/trillian/monitoring"
+)
+
+func keyHash(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+func TestNewStaticAPIKeyStore(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		keys    []*configpb.APIKey
+		wantErr string
+	}{
+		{desc: "valid", keys: []*configpb.APIKey{{Name: "a", KeySha256: keyHash("a")}, {Name: "b", KeySha256: keyHash("b"), RateLimit: 1, Burst: 2}}},
+		{desc: "empty-name", keys: []*configpb.APIKey{{KeySha256: keyHash("a")}}, wantErr: "empty API key name"},
+		{desc: "duplicate-name", keys: []*configpb.APIKey{{Name: "a", KeySha256: keyHash("a")}, {Name: "a", KeySha256: keyHash("b")}}, wantErr: "duplicate"},
+		{desc: "bad-hash", keys: []*configpb.APIKey{{Name: "a", KeySha256: "abcd"}}, wantErr: "not a hex-encoded SHA-256"},
+		{desc: "same-hash", keys: []*configpb.APIKey{{Name: "a", KeySha256: keyHash("a")}, {Name: "b", KeySha256: keyHash("a")}}, wantErr: "same hash"},
+		{desc: "negative-rate", keys: []*configpb.APIKey{{Name: "a", KeySha256: keyHash("a"), RateLimit: -1}}, wantErr: "negative rate limit"},
+		{desc: "negative-burst", keys: []*configpb.APIKey{{Name: "a", KeySha256: keyHash("a"), Burst: -1}}, wantErr: "negative burst"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := NewStaticAPIKeyStore(&configpb.APIKeyConfig{Keys: tc.keys})
+			if len(tc.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("NewStaticAPIKeyStore()=%v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("NewStaticAPIKeyStore()=%v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	store, err := NewStaticAPIKeyStore(&configpb.APIKeyConfig{Keys: []*configpb.APIKey{
+		{Name: "ca1", KeySha256: keyHash("secret1"), RateLimit: 0.001, Burst: 2},
+		{Name: "ca2", KeySha256: keyHash("secret2")},
+	}})
+	if err != nil {
+		t.Fatalf("NewStaticAPIKeyStore()=%v", err)
+	}
+	for _, tc := range []struct {
+		desc     string
+		required bool
+		auth     string
+		wantKey  string
+		wantCode int
+	}{
+		{desc: "no-key-optional", wantCode: http.StatusOK},
+		{desc: "no-key-required", required: true, wantCode: http.StatusUnauthorized},
+		{desc: "malformed", auth: "Basic c2VjcmV0Mg==", wantCode: http.StatusUnauthorized},
+		{desc: "unknown", auth: "Bearer secret3", wantCode: http.StatusUnauthorized},
+		{desc: "known", auth: "Bearer secret2", wantKey: "ca2", wantCode: http.StatusOK},
+		{desc: "known-required", required: true, auth: "Bearer secret2", wantKey: "ca2", wantCode: http.StatusOK},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			a := NewAPIKeyAuthenticator(store, tc.required, monitoring.InertMetricFactory{})
+			key, code, err := a.authenticate(context.Background(), tc.auth)
+			if code != tc.wantCode {
+				t.Errorf("authenticate()=%d, %v; want %d", code, err, tc.wantCode)
+			}
+			var got string
+			if key != nil {
+				got = key.Name
+			}
+			if got != tc.wantKey {
+				t.Errorf("authenticate() key=%q, want %q", got, tc.wantKey)
+			}
+		})
+	}
+
+	// The first key allows a burst of two, then is rate limited.
+	a := NewAPIKeyAuthenticator(store, false, monitoring.InertMetricFactory{})
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		if _, code, err := a.authenticate(context.Background(), "Bearer secret1"); code != want {
+			t.Errorf("authenticate() #%d=%d, %v; want %d", i, code, err, want)
+		}
+	}
+	if got, want := (&APIKey{Name: "ca1"}).QuotaUser(), "@apikey ca1"; got != want {
+		t.Errorf("QuotaUser()=%q, want %q", got, want)
+	}
+}
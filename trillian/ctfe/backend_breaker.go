@@ -0,0 +1,171 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/util"
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// BreakerState is the state of a CircuitBreaker.
+type BreakerState int
+
+// Circuit breaker states, as exported in the backend_breaker_state metric.
+const (
+	// BreakerClosed lets all RPCs through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails all RPCs without sending them to the backend.
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe RPC through to test whether the
+	// backend has recovered.
+	BreakerHalfOpen
+)
+
+var (
+	breakerOnce  sync.Once
+	breakerState monitoring.Gauge // backend => value
+)
+
+// BackendUnavailableError is returned for RPCs which were not sent to the
+// backend because its circuit breaker is open. It maps to a gRPC Unavailable
+// status, and so to an HTTP 503 response.
+type BackendUnavailableError struct {
+	// Backend is the name of the backend whose breaker is open.
+	Backend string
+	// RetryAfter is how long until the breaker will next let an RPC through.
+	RetryAfter time.Duration
+}
+
+func (e *BackendUnavailableError) Error() string {
+	return fmt.Sprintf("backend %s unavailable: circuit breaker open, retry after %v", e.Backend, e.RetryAfter)
+}
+
+// GRPCStatus allows status.FromError and status.Code to classify the error.
+func (e *BackendUnavailableError) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, e.Error())
+}
+
+// CircuitBreaker stops sending RPCs to a backend that is failing, so that
+// requests fail fast rather than piling up until the RPC deadline. After
+// threshold consecutive RPCs fail with an error indicating that the backend is
+// down or overloaded, the breaker opens for the cooldown period. Once that has
+// passed a single probe RPC is allowed through; the breaker closes if it
+// succeeds and reopens if it fails.
+type CircuitBreaker struct {
+	name       string
+	threshold  int
+	cooldown   time.Duration
+	timeSource util.TimeSource
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker for the named backend.
+func NewCircuitBreaker(name string, threshold int, cooldown time.Duration, mf monitoring.MetricFactory, ts util.TimeSource) *CircuitBreaker {
+	breakerOnce.Do(func() {
+		breakerState = mf.NewGauge("backend_breaker_state", "State of the backend circuit breaker: 0=closed, 1=open, 2=half-open", "backend")
+	})
+	b := &CircuitBreaker{name: name, threshold: threshold, cooldown: cooldown, timeSource: ts}
+	breakerState.Set(float64(BreakerClosed), name)
+	return b
+}
+
+// State returns the current state of the breaker.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// UnaryClientInterceptor returns a gRPC interceptor which applies the breaker
+// to all unary RPCs on a connection.
+func (b *CircuitBreaker) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := b.allow(); err != nil {
+			return err
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		b.record(err)
+		return err
+	}
+}
+
+// allow returns an error if the RPC should not be sent to the backend.
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		wait := b.openedAt.Add(b.cooldown).Sub(b.timeSource.Now())
+		if wait > 0 {
+			return &BackendUnavailableError{Backend: b.name, RetryAfter: wait}
+		}
+		b.setState(BreakerHalfOpen)
+		return nil
+	case BreakerHalfOpen:
+		// A probe is already in flight.
+		return &BackendUnavailableError{Backend: b.name, RetryAfter: b.cooldown}
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of an RPC.
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !isBackendFailure(err) {
+		b.failures = 0
+		if b.state != BreakerClosed {
+			klog.Infof("Backend %s recovered, closing circuit breaker", b.name)
+			b.setState(BreakerClosed)
+		}
+		return
+	}
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		if b.state != BreakerOpen {
+			klog.Warningf("Backend %s failing (%d consecutive errors, last: %v), opening circuit breaker for %v", b.name, b.failures, err, b.cooldown)
+		}
+		b.openedAt = b.timeSource.Now()
+		b.setState(BreakerOpen)
+	}
+}
+
+func (b *CircuitBreaker) setState(s BreakerState) {
+	b.state = s
+	breakerState.Set(float64(s), b.name)
+}
+
+// isBackendFailure reports whether err indicates that the backend is down or
+// saturated, as opposed to rejecting an individual request.
+func isBackendFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// steppingTimeSource is a util.TimeSource whose time can be moved forward.
+type steppingTimeSource struct {
+	now time.Time
+}
+
+func (s *steppingTimeSource) Now() time.Time { return s.now }
+
+func TestCircuitBreaker(t *testing.T) {
+	ts := &steppingTimeSource{now: time.Unix(1000, 0)}
+	b := NewCircuitBreaker("test", 2, 10*time.Second, monitoring.InertMetricFactory{}, ts)
+	intercept := b.UnaryClientInterceptor()
+
+	var rpcErr error
+	var sent int
+	invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		sent++
+		return rpcErr
+	}
+	call := func() error {
+		return intercept(context.Background(), "/trillian.TrillianLog/QueueLeaf", nil, nil, nil, invoker)
+	}
+
+	// Request-specific errors don't count as backend failures.
+	rpcErr = status.Error(codes.InvalidArgument, "bad request")
+	for i := 0; i < 5; i++ {
+		_ = call()
+	}
+	if got, want := b.State(), BreakerClosed; got != want {
+		t.Fatalf("State()=%v after request errors, want %v", got, want)
+	}
+
+	rpcErr = status.Error(codes.Unavailable, "down")
+	_ = call()
+	if got, want := b.State(), BreakerClosed; got != want {
+		t.Fatalf("State()=%v after 1 failure, want %v", got, want)
+	}
+	_ = call()
+	if got, want := b.State(), BreakerOpen; got != want {
+		t.Fatalf("State()=%v after 2 failures, want %v", got, want)
+	}
+
+	// While open, RPCs fail fast with a retry hint.
+	sent = 0
+	ts.now = ts.now.Add(4 * time.Second)
+	err := call()
+	var bue *BackendUnavailableError
+	if !errors.As(err, &bue) {
+		t.Fatalf("call()=%v, want BackendUnavailableError", err)
+	}
+	if got, want := bue.RetryAfter, 6*time.Second; got != want {
+		t.Errorf("RetryAfter=%v, want %v", got, want)
+	}
+	if got, want := status.Code(err), codes.Unavailable; got != want {
+		t.Errorf("status.Code()=%v, want %v", got, want)
+	}
+	if sent != 0 {
+		t.Errorf("%d RPCs sent while breaker open, want 0", sent)
+	}
+
+	// After the cooldown a failed probe reopens the breaker.
+	ts.now = ts.now.Add(6 * time.Second)
+	_ = call()
+	if sent != 1 {
+		t.Errorf("%d RPCs sent for probe, want 1", sent)
+	}
+	if got, want := b.State(), BreakerOpen; got != want {
+		t.Fatalf("State()=%v after failed probe, want %v", got, want)
+	}
+
+	// A successful probe closes it.
+	ts.now = ts.now.Add(10 * time.Second)
+	rpcErr = nil
+	if err := call(); err != nil {
+		t.Errorf("call()=%v, want nil", err)
+	}
+	if got, want := b.State(), BreakerClosed; got != want {
+		t.Errorf("State()=%v after successful probe, want %v", got, want)
+	}
+}
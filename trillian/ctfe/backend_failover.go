@@ -0,0 +1,146 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/util"
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc"
+	"k8s.io/klog/v2"
+)
+
+var (
+	failoverOnce   sync.Once
+	failoverActive monitoring.Gauge   // log => value
+	failoverEvents monitoring.Counter // log => value
+	failoverRPCs   monitoring.Counter // log => value
+)
+
+// FailoverConn sends a log's backend RPCs to its primary backend, and fails
+// over to a standby backend serving the same tree once the primary has been
+// failing continuously for longer than a threshold. Only idempotent reads
+// fail over, unless writes are marked as safe to send to the standby.
+//
+// While failed over, one RPC per threshold period is sent to the primary as a
+// probe, and the connection fails back as soon as the primary succeeds. It
+// implements grpc.ClientConnInterface, so it can be passed to
+// trillian.NewTrillianLogClient.
+type FailoverConn struct {
+	log            string
+	primary        grpc.ClientConnInterface
+	standby        grpc.ClientConnInterface
+	threshold      time.Duration
+	failoverWrites bool
+	timeSource     util.TimeSource
+
+	mu           sync.Mutex
+	failingSince time.Time // zero if the last primary RPC succeeded
+	failedOver   bool
+	lastProbe    time.Time
+}
+
+// NewFailoverConn creates a FailoverConn for the named log.
+func NewFailoverConn(log string, primary, standby grpc.ClientConnInterface, threshold time.Duration, failoverWrites bool, mf monitoring.MetricFactory, ts util.TimeSource) *FailoverConn {
+	failoverOnce.Do(func() {
+		failoverActive = mf.NewGauge("backend_failover_active", "Set to 1 while the log's RPCs are failed over to its standby backend", "log")
+		failoverEvents = mf.NewCounter("backend_failovers", "Number of times the log has failed over to its standby backend", "log")
+		failoverRPCs = mf.NewCounter("backend_failover_rpcs", "Number of RPCs sent to the log's standby backend", "log")
+	})
+	failoverActive.Set(0, log)
+	return &FailoverConn{
+		log:            log,
+		primary:        primary,
+		standby:        standby,
+		threshold:      threshold,
+		failoverWrites: failoverWrites,
+		timeSource:     ts,
+	}
+}
+
+// FailedOver reports whether RPCs are currently being sent to the standby.
+func (f *FailoverConn) FailedOver() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.failedOver
+}
+
+// Invoke performs a unary RPC on the primary or standby backend.
+func (f *FailoverConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	canFailover := f.failoverWrites || idempotentBackendMethods[method]
+	if canFailover && f.useStandby() {
+		failoverRPCs.Inc(f.log)
+		return f.standby.Invoke(ctx, method, args, reply, opts...)
+	}
+	err := f.primary.Invoke(ctx, method, args, reply, opts...)
+	if f.record(err) && canFailover {
+		// The primary is (still) down, so don't fail this RPC because of it.
+		failoverRPCs.Inc(f.log)
+		return f.standby.Invoke(ctx, method, args, reply, opts...)
+	}
+	return err
+}
+
+// NewStream begins a streaming RPC on the primary backend; the Trillian log
+// API has no streaming RPCs which could fail over.
+func (f *FailoverConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return f.primary.NewStream(ctx, desc, method, opts...)
+}
+
+// useStandby reports whether an RPC should go to the standby, which is the
+// case while failed over, except when a probe of the primary is due.
+func (f *FailoverConn) useStandby() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.failedOver {
+		return false
+	}
+	if now := f.timeSource.Now(); now.Sub(f.lastProbe) >= f.threshold {
+		f.lastProbe = now
+		return false
+	}
+	return true
+}
+
+// record updates the primary's health with the outcome of an RPC sent to it,
+// and reports whether the connection is failed over as a result of an error.
+func (f *FailoverConn) record(err error) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !isBackendFailure(err) {
+		f.failingSince = time.Time{}
+		if f.failedOver {
+			klog.Infof("%s: primary backend recovered, failing back from standby", f.log)
+			f.failedOver = false
+			failoverActive.Set(0, f.log)
+		}
+		return false
+	}
+	now := f.timeSource.Now()
+	if f.failingSince.IsZero() {
+		f.failingSince = now
+	}
+	if !f.failedOver && now.Sub(f.failingSince) >= f.threshold {
+		klog.Warningf("%s: primary backend failing since %v (last error: %v), failing over to standby", f.log, f.failingSince, err)
+		f.failedOver = true
+		f.lastProbe = now
+		failoverActive.Set(1, f.log)
+		failoverEvents.Inc(f.log)
+	}
+	return f.failedOver
+}
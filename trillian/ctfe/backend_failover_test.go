@@ -0,0 +1,110 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeConn is a grpc.ClientConnInterface which counts unary RPCs and returns
+// a canned error.
+type fakeConn struct {
+	err   error
+	calls int
+}
+
+func (c *fakeConn) Invoke(context.Context, string, interface{}, interface{}, ...grpc.CallOption) error {
+	c.calls++
+	return c.err
+}
+
+func (c *fakeConn) NewStream(context.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, status.Error(codes.Unimplemented, "no streams")
+}
+
+func TestFailoverConn(t *testing.T) {
+	const (
+		read  = "/trillian.TrillianLog/GetLatestSignedLogRoot"
+		write = "/trillian.TrillianLog/QueueLeaf"
+	)
+	ts := &steppingTimeSource{now: time.Unix(1000, 0)}
+	primary, standby := &fakeConn{}, &fakeConn{}
+	f := NewFailoverConn("test", primary, standby, 10*time.Second, false, monitoring.InertMetricFactory{}, ts)
+	call := func(method string) error {
+		return f.Invoke(context.Background(), method, nil, nil)
+	}
+
+	// Failures shorter than the threshold don't cause a failover.
+	primary.err = status.Error(codes.Unavailable, "down")
+	if err := call(read); status.Code(err) != codes.Unavailable {
+		t.Fatalf("Invoke()=%v, want Unavailable", err)
+	}
+	ts.now = ts.now.Add(5 * time.Second)
+	_ = call(read)
+	if f.FailedOver() || standby.calls != 0 {
+		t.Fatalf("FailedOver()=%v with %d standby calls after 5s, want false, 0", f.FailedOver(), standby.calls)
+	}
+
+	// Once past the threshold, the failing read is retried on the standby.
+	ts.now = ts.now.Add(5 * time.Second)
+	if err := call(read); err != nil {
+		t.Fatalf("Invoke()=%v after failover, want nil", err)
+	}
+	if !f.FailedOver() || standby.calls != 1 {
+		t.Fatalf("FailedOver()=%v with %d standby calls after 10s, want true, 1", f.FailedOver(), standby.calls)
+	}
+
+	// Reads now go straight to the standby; writes stay on the primary.
+	primary.calls = 0
+	if err := call(read); err != nil {
+		t.Errorf("Invoke(read)=%v, want nil", err)
+	}
+	if err := call(write); status.Code(err) != codes.Unavailable {
+		t.Errorf("Invoke(write)=%v, want Unavailable", err)
+	}
+	if primary.calls != 1 || standby.calls != 2 {
+		t.Errorf("primary/standby calls=%d/%d, want 1/2", primary.calls, standby.calls)
+	}
+
+	// After the threshold a read probes the primary, and fails back if the
+	// primary has recovered.
+	primary.err = nil
+	ts.now = ts.now.Add(10 * time.Second)
+	if err := call(read); err != nil {
+		t.Errorf("Invoke(probe)=%v, want nil", err)
+	}
+	if f.FailedOver() {
+		t.Error("FailedOver()=true after successful probe, want false")
+	}
+}
+
+func TestFailoverConnWrites(t *testing.T) {
+	ts := &steppingTimeSource{now: time.Unix(1000, 0)}
+	primary, standby := &fakeConn{err: status.Error(codes.Unavailable, "down")}, &fakeConn{}
+	f := NewFailoverConn("test", primary, standby, 0, true, monitoring.InertMetricFactory{}, ts)
+	if err := f.Invoke(context.Background(), "/trillian.TrillianLog/QueueLeaf", nil, nil); err != nil {
+		t.Fatalf("Invoke(write)=%v, want nil", err)
+	}
+	if standby.calls != 1 {
+		t.Errorf("standby calls=%d, want 1", standby.calls)
+	}
+}
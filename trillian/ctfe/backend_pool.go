@@ -0,0 +1,98 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// BackendPoolHealthCheckConfig is a gRPC service config which enables
+// round-robin load balancing with client-side health checking, so that
+// subconnections to backends reporting themselves as not SERVING are skipped.
+// The dialing binary must link in the google.golang.org/grpc/health package
+// for the health checking to take effect.
+const BackendPoolHealthCheckConfig = `{"loadBalancingConfig": [{"round_robin":{}}], "healthCheckConfig": {"serviceName": ""}}`
+
+// BackendPool is a fixed-size set of gRPC connections to a single Trillian
+// backend specification. It implements grpc.ClientConnInterface, so it can be
+// passed to trillian.NewTrillianLogClient, and spreads RPCs round-robin over
+// the connections that are not known to be failing.
+type BackendPool struct {
+	conns []*grpc.ClientConn
+	next  atomic.Uint32
+}
+
+// DialBackendPool opens size connections to target using the given options.
+// If any of the dials fail, the connections opened so far are closed.
+func DialBackendPool(target string, size int, opts ...grpc.DialOption) (*BackendPool, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("invalid backend pool size %d", size)
+	}
+	p := &BackendPool{conns: make([]*grpc.ClientConn, 0, size)}
+	for i := 0; i < size; i++ {
+		conn, err := grpc.Dial(target, opts...)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.conns = append(p.conns, conn)
+	}
+	return p, nil
+}
+
+// Invoke performs a unary RPC on one of the pool's connections.
+func (p *BackendPool) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	return p.pick().Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewStream begins a streaming RPC on one of the pool's connections.
+func (p *BackendPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return p.pick().NewStream(ctx, desc, method, opts...)
+}
+
+// Close closes all connections in the pool.
+func (p *BackendPool) Close() error {
+	var errs []error
+	for _, conn := range p.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pick returns the next usable connection in round-robin order. Connections
+// which are in TRANSIENT_FAILURE or SHUTDOWN state are skipped, unless all
+// connections are in such a state, in which case the RPC is attempted anyway
+// so that the caller gets a meaningful error.
+func (p *BackendPool) pick() *grpc.ClientConn {
+	n := uint32(len(p.conns))
+	start := p.next.Add(1)
+	for i := uint32(0); i < n; i++ {
+		conn := p.conns[(start+i)%n]
+		switch conn.GetState() {
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			continue
+		}
+		return conn
+	}
+	return p.conns[start%n]
+}
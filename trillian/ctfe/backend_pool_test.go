@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// countingLogServer counts GetLatestSignedLogRoot calls.
+type countingLogServer struct {
+	trillian.UnimplementedTrillianLogServer
+	calls atomic.Int32
+}
+
+func (s *countingLogServer) GetLatestSignedLogRoot(context.Context, *trillian.GetLatestSignedLogRootRequest) (*trillian.GetLatestSignedLogRootResponse, error) {
+	s.calls.Add(1)
+	return &trillian.GetLatestSignedLogRootResponse{}, nil
+}
+
+// startTestLogServer runs a gRPC Trillian log server on a local port and
+// returns its address.
+func startTestLogServer(t *testing.T, srv trillian.TrillianLogServer) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("net.Listen()=%v", err)
+	}
+	s := grpc.NewServer()
+	trillian.RegisterTrillianLogServer(s, srv)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func TestDialBackendPool(t *testing.T) {
+	srv := &countingLogServer{}
+	addr := startTestLogServer(t, srv)
+
+	if _, err := DialBackendPool(addr, 0, grpc.WithTransportCredentials(insecure.NewCredentials())); err == nil {
+		t.Error("DialBackendPool(size=0)=nil, want error")
+	}
+
+	pool, err := DialBackendPool(addr, 3, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if err != nil {
+		t.Fatalf("DialBackendPool()=%v", err)
+	}
+	defer pool.Close()
+
+	client := trillian.NewTrillianLogClient(pool)
+	const calls = 10
+	for i := 0; i < calls; i++ {
+		if _, err := client.GetLatestSignedLogRoot(context.Background(), &trillian.GetLatestSignedLogRootRequest{}); err != nil {
+			t.Fatalf("GetLatestSignedLogRoot()=%v", err)
+		}
+	}
+	if got := srv.calls.Load(); got != calls {
+		t.Errorf("server saw %d calls, want %d", got, calls)
+	}
+}
+
+func TestBackendPoolSkipsClosedConns(t *testing.T) {
+	addr := startTestLogServer(t, &countingLogServer{})
+	pool, err := DialBackendPool(addr, 3, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if err != nil {
+		t.Fatalf("DialBackendPool()=%v", err)
+	}
+	defer pool.Close()
+
+	// Shut down all but the last connection.
+	for _, conn := range pool.conns[:2] {
+		conn.Close()
+	}
+	for i := 0; i < 6; i++ {
+		if got, want := pool.pick(), pool.conns[2]; got != want {
+			t.Fatalf("pick()=%p, want %p", got, want)
+		}
+	}
+
+	// With every connection shut down, pick still returns one of them.
+	pool.conns[2].Close()
+	if got := pool.pick(); got == nil {
+		t.Error("pick()=nil, want a connection")
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// idempotentBackendMethods holds the full gRPC method names of the Trillian
+// read RPCs which may safely be retried or hedged. Write RPCs such as
+// QueueLeaf are deliberately absent, as repeating them could cause duplicate
+// submissions.
+var idempotentBackendMethods = map[string]bool{
+	"/trillian.TrillianLog/GetLatestSignedLogRoot":  true,
+	"/trillian.TrillianLog/GetLeavesByRange":        true,
+	"/trillian.TrillianLog/GetConsistencyProof":     true,
+	"/trillian.TrillianLog/GetInclusionProof":       true,
+	"/trillian.TrillianLog/GetInclusionProofByHash": true,
+	"/trillian.TrillianLog/GetEntryAndProof":        true,
+}
+
+var (
+	retryOnce       sync.Once
+	backendRetries  monitoring.Counter // method => value
+	backendHedges   monitoring.Counter // method => value
+	backendHedgeWin monitoring.Counter // method => value
+)
+
+// BackendRetryOptions configures retries of idempotent backend reads.
+type BackendRetryOptions struct {
+	// MaxAttempts is the maximum number of times an RPC is attempted,
+	// including the first. Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the upper bound of the random delay before the first
+	// retry; it doubles for each subsequent retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the backoff between attempts.
+	MaxBackoff time.Duration
+	// HedgeDelay, if non-zero, is how long to wait for an attempt before
+	// sending a second, concurrent copy of it, which the load balancer will
+	// normally route to a different backend replica. The first successful
+	// response is used.
+	HedgeDelay time.Duration
+	// MetricFactory allows creating metrics.
+	MetricFactory monitoring.MetricFactory
+}
+
+// BackendRetryInterceptor returns a gRPC interceptor which retries, with
+// jittered exponential backoff, and optionally hedges idempotent Trillian
+// read RPCs which fail with a transient error. Other RPCs are passed through
+// unchanged.
+func BackendRetryInterceptor(opts BackendRetryOptions) grpc.UnaryClientInterceptor {
+	mf := opts.MetricFactory
+	if mf == nil {
+		mf = monitoring.InertMetricFactory{}
+	}
+	retryOnce.Do(func() {
+		backendRetries = mf.NewCounter("backend_rpc_retries", "Number of retried backend read RPCs", "method")
+		backendHedges = mf.NewCounter("backend_rpc_hedges", "Number of hedged backend read RPCs sent", "method")
+		backendHedgeWin = mf.NewCounter("backend_rpc_hedge_wins", "Number of hedged backend read RPCs which returned first", "method")
+	})
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		if !idempotentBackendMethods[method] {
+			return invoker(ctx, method, req, reply, cc, callOpts...)
+		}
+		attempt := func() error {
+			if opts.HedgeDelay <= 0 {
+				return invoker(ctx, method, req, reply, cc, callOpts...)
+			}
+			return hedgedInvoke(ctx, opts.HedgeDelay, method, req, reply, cc, invoker, callOpts...)
+		}
+
+		backoff := opts.InitialBackoff
+		var err error
+		for i := 0; ; i++ {
+			if err = attempt(); err == nil || !isRetryable(err) || i+1 >= opts.MaxAttempts {
+				return err
+			}
+			var delay time.Duration
+			if backoff > 0 {
+				delay = time.Duration(rand.Int63n(int64(backoff)))
+			}
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(delay):
+			}
+			backendRetries.Inc(method)
+			if backoff *= 2; opts.MaxBackoff > 0 && backoff > opts.MaxBackoff {
+				backoff = opts.MaxBackoff
+			}
+		}
+	}
+}
+
+// hedgedInvoke sends the RPC, and a second copy of it if the first has not
+// completed after delay. It returns as soon as either copy succeeds, or once
+// both have failed.
+func hedgedInvoke(ctx context.Context, delay time.Duration, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+	msg, ok := reply.(proto.Message)
+	if !ok {
+		return invoker(ctx, method, req, reply, cc, callOpts...)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		reply  proto.Message
+		err    error
+		hedged bool
+	}
+	results := make(chan result, 2)
+	send := func(hedged bool) {
+		rsp := msg.ProtoReflect().New().Interface()
+		go func() {
+			results <- result{reply: rsp, err: invoker(ctx, method, req, rsp, cc, callOpts...), hedged: hedged}
+		}()
+	}
+
+	send(false)
+	inflight := 1
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	hedge := timer.C
+	var err error
+	for inflight > 0 {
+		select {
+		case <-hedge:
+			hedge = nil
+			backendHedges.Inc(method)
+			send(true)
+			inflight++
+		case res := <-results:
+			inflight--
+			if res.err == nil {
+				if res.hedged {
+					backendHedgeWin.Inc(method)
+				}
+				proto.Reset(msg)
+				proto.Merge(msg, res.reply)
+				return nil
+			}
+			if err == nil || status.Code(err) == codes.Canceled {
+				err = res.err
+			}
+		}
+	}
+	return err
+}
+
+// isRetryable reports whether a failed read may succeed if attempted again.
+func isRetryable(err error) bool {
+	var bue *BackendUnavailableError
+	if errors.As(err, &bue) {
+		// The circuit breaker is open, so retrying would fail straight away.
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Aborted:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBackendRetryInterceptor(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	tests := []struct {
+		desc      string
+		method    string
+		errs      []error // returned by successive attempts, then nil
+		attempts  int
+		wantCalls int32
+		wantCode  codes.Code
+	}{
+		{
+			desc:      "read-retried",
+			method:    "/trillian.TrillianLog/GetLeavesByRange",
+			errs:      []error{unavailable, unavailable},
+			attempts:  3,
+			wantCalls: 3,
+			wantCode:  codes.OK,
+		},
+		{
+			desc:      "read-attempts-exhausted",
+			method:    "/trillian.TrillianLog/GetLatestSignedLogRoot",
+			errs:      []error{unavailable, unavailable, unavailable},
+			attempts:  2,
+			wantCalls: 2,
+			wantCode:  codes.Unavailable,
+		},
+		{
+			desc:      "read-not-retryable",
+			method:    "/trillian.TrillianLog/GetConsistencyProof",
+			errs:      []error{status.Error(codes.InvalidArgument, "bad")},
+			attempts:  3,
+			wantCalls: 1,
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			desc:      "read-breaker-open",
+			method:    "/trillian.TrillianLog/GetEntryAndProof",
+			errs:      []error{&BackendUnavailableError{Backend: "be"}},
+			attempts:  3,
+			wantCalls: 1,
+			wantCode:  codes.Unavailable,
+		},
+		{
+			desc:      "write-not-retried",
+			method:    "/trillian.TrillianLog/QueueLeaf",
+			errs:      []error{unavailable},
+			attempts:  3,
+			wantCalls: 1,
+			wantCode:  codes.Unavailable,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var calls atomic.Int32
+			invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+				n := int(calls.Add(1))
+				if n <= len(test.errs) {
+					return test.errs[n-1]
+				}
+				return nil
+			}
+			intercept := BackendRetryInterceptor(BackendRetryOptions{MaxAttempts: test.attempts, InitialBackoff: time.Millisecond})
+			err := intercept(context.Background(), test.method, nil, nil, nil, invoker)
+			if got := status.Code(err); got != test.wantCode {
+				t.Errorf("intercept()=%v, want code %v", err, test.wantCode)
+			}
+			if got := calls.Load(); got != test.wantCalls {
+				t.Errorf("invoker called %d times, want %d", got, test.wantCalls)
+			}
+		})
+	}
+}
+
+func TestBackendRetryInterceptorHedges(t *testing.T) {
+	var calls atomic.Int32
+	invoker := func(ctx context.Context, _ string, _, reply interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		if calls.Add(1) == 1 {
+			// The first attempt hangs until the hedged attempt has won.
+			<-ctx.Done()
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		reply.(*trillian.GetLeavesByRangeResponse).Leaves = []*trillian.LogLeaf{{LeafIndex: 7}}
+		return nil
+	}
+	intercept := BackendRetryInterceptor(BackendRetryOptions{MaxAttempts: 1, HedgeDelay: time.Millisecond})
+
+	var rsp trillian.GetLeavesByRangeResponse
+	if err := intercept(context.Background(), "/trillian.TrillianLog/GetLeavesByRange", nil, &rsp, nil, invoker); err != nil {
+		t.Fatalf("intercept()=%v, want nil", err)
+	}
+	if got, want := calls.Load(), int32(2); got != want {
+		t.Errorf("invoker called %d times, want %d", got, want)
+	}
+	if len(rsp.Leaves) != 1 || rsp.Leaves[0].LeafIndex != 7 {
+		t.Errorf("reply=%v, want the hedged attempt's leaves", &rsp)
+	}
+}
@@ -25,6 +25,63 @@ import (
 	"github.com/RarimoVoting/certificate-transparency-go/x509util"
 )
 
+// Causes of chain rejection, as reported in ChainValidationError.Reason.
+const (
+	// ChainRejectUnparseable means a certificate in the chain doesn't parse.
+	ChainRejectUnparseable = "unparseable"
+	// ChainRejectUnknownRoot means the chain doesn't lead to an accepted root.
+	ChainRejectUnknownRoot = "unknown_root"
+	// ChainRejectInvalidSignature means a certificate in the chain isn't
+	// validly signed by the next one.
+	ChainRejectInvalidSignature = "invalid_signature"
+	// ChainRejectInvalidChain means the chain is otherwise invalid, e.g. it
+	// isn't in the order required by RFC 6962.
+	ChainRejectInvalidChain = "invalid_chain"
+	// ChainRejectExpired means the certificate or an intermediate has expired.
+	ChainRejectExpired = "expired"
+	// ChainRejectUnexpired means the log only accepts expired certificates.
+	ChainRejectUnexpired = "unexpired"
+	// ChainRejectNotYetValid means the certificate's NotBefore is too far in
+	// the future.
+	ChainRejectNotYetValid = "not_yet_valid"
+	// ChainRejectShardWindow means the certificate's NotAfter is outside the
+	// log's temporal shard.
+	ChainRejectShardWindow = "shard_window"
+	// ChainRejectBadPoison means a precertificate's poison extension is
+	// invalid.
+	ChainRejectBadPoison = "bad_poison"
+	// ChainRejectWrongType means a certificate was submitted as a
+	// precertificate or vice versa.
+	ChainRejectWrongType = "wrong_type"
+	// ChainRejectPolicy means the chain breaks one of the log's other
+	// acceptance rules, e.g. on EKUs or extensions.
+	ChainRejectPolicy = "policy"
+)
+
+// ChainValidationError is returned when a submitted chain is rejected.
+type ChainValidationError struct {
+	// Reason is one of the ChainReject* causes.
+	Reason string
+	// CertIndex is the index in the submitted chain of the certificate which
+	// caused the rejection, or -1 if it is not down to a single certificate.
+	CertIndex int
+	// Err describes the failure.
+	Err error
+}
+
+func (e *ChainValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ChainValidationError) Unwrap() error {
+	return e.Err
+}
+
+// rejectChain returns a ChainValidationError for the given failure.
+func rejectChain(reason string, certIndex int, err error) error {
+	return &ChainValidationError{Reason: reason, CertIndex: certIndex, Err: err}
+}
+
 // IsPrecertificate tests if a certificate is a pre-certificate as defined in CT.
 // An error is returned if the CT extension is present but is not ASN.1 NULL as defined
 // by the spec.
@@ -55,7 +112,7 @@ func ValidateChain(rawChain [][]byte, validationOpts CertValidationOpts) ([]*x50
 	for i, certBytes := range rawChain {
 		cert, err := x509.ParseCertificate(certBytes)
 		if x509.IsFatal(err) {
-			return nil, err
+			return nil, rejectChain(ChainRejectUnparseable, i, err)
 		}
 
 		chain = append(chain, cert)
@@ -72,14 +129,14 @@ func ValidateChain(rawChain [][]byte, validationOpts CertValidationOpts) ([]*x50
 
 	// Check whether the expiry date of the cert is within the acceptable range.
 	if naStart != nil && cert.NotAfter.Before(*naStart) {
-		return nil, fmt.Errorf("certificate NotAfter (%v) < %v", cert.NotAfter, *naStart)
+		return nil, rejectChain(ChainRejectShardWindow, 0, fmt.Errorf("certificate NotAfter (%v) < %v", cert.NotAfter, *naStart))
 	}
 	if naLimit != nil && !cert.NotAfter.Before(*naLimit) {
-		return nil, fmt.Errorf("certificate NotAfter (%v) >= %v", cert.NotAfter, *naLimit)
+		return nil, rejectChain(ChainRejectShardWindow, 0, fmt.Errorf("certificate NotAfter (%v) >= %v", cert.NotAfter, *naLimit))
 	}
 
 	if validationOpts.acceptOnlyCA && !cert.IsCA {
-		return nil, errors.New("only certificates with CA bit set are accepted")
+		return nil, rejectChain(ChainRejectPolicy, 0, errors.New("only certificates with CA bit set are accepted"))
 	}
 
 	now := validationOpts.currentTime
@@ -87,11 +144,14 @@ func ValidateChain(rawChain [][]byte, validationOpts CertValidationOpts) ([]*x50
 		now = time.Now()
 	}
 	expired := now.After(cert.NotAfter)
-	if validationOpts.rejectExpired && expired {
-		return nil, errors.New("rejecting expired certificate")
+	if validationOpts.rejectExpired && now.Add(-validationOpts.clockSkew).After(cert.NotAfter) {
+		return nil, rejectChain(ChainRejectExpired, 0, errors.New("rejecting expired certificate"))
 	}
 	if validationOpts.rejectUnexpired && !expired {
-		return nil, errors.New("rejecting unexpired certificate")
+		return nil, rejectChain(ChainRejectUnexpired, 0, errors.New("rejecting unexpired certificate"))
+	}
+	if limit := validationOpts.maxNotBeforeFuture; limit != nil && cert.NotBefore.After(now.Add(*limit+validationOpts.clockSkew)) {
+		return nil, rejectChain(ChainRejectNotYetValid, 0, fmt.Errorf("rejecting certificate with NotBefore (%v) more than %v in the future", cert.NotBefore, *limit))
 	}
 
 	// Check for unwanted extension types, if required.
@@ -105,7 +165,7 @@ func ValidateChain(rawChain [][]byte, validationOpts CertValidationOpts) ([]*x50
 		for idx, ext := range cert.Extensions {
 			extOid := ext.Id.String()
 			if _, ok := badIDs[extOid]; ok {
-				return nil, fmt.Errorf("rejecting certificate containing extension %v at index %d", extOid, idx)
+				return nil, rejectChain(ChainRejectPolicy, 0, fmt.Errorf("rejecting certificate containing extension %v at index %d", extOid, idx))
 			}
 		}
 	}
@@ -125,7 +185,7 @@ func ValidateChain(rawChain [][]byte, validationOpts CertValidationOpts) ([]*x50
 			}
 		}
 		if !good {
-			return nil, fmt.Errorf("rejecting certificate without EKU in %v", validationOpts.extKeyUsages)
+			return nil, rejectChain(ChainRejectPolicy, 0, fmt.Errorf("rejecting certificate without EKU in %v", validationOpts.extKeyUsages))
 		}
 	}
 
@@ -136,9 +196,8 @@ func ValidateChain(rawChain [][]byte, validationOpts CertValidationOpts) ([]*x50
 		CurrentTime:       now,
 		Intermediates:     intermediatePool.CertPool(),
 		DisableTimeChecks: true,
-		// Precertificates have the poison extension; also the Go library code does not
-		// support the standard PolicyConstraints extension (which is required to be marked
-		// critical, RFC 5280 s4.2.1.11), so never check unhandled critical extensions.
+		// Precertificates have the poison extension, so never check unhandled critical
+		// extensions.
 		DisableCriticalExtensionChecks: true,
 		// Pre-issued precertificates have the Certificate Transparency EKU; also some
 		// leaves have unknown EKUs that should not be bounced just because the intermediate
@@ -150,17 +209,19 @@ func ValidateChain(rawChain [][]byte, validationOpts CertValidationOpts) ([]*x50
 		// pre-issuer intermediate, so disable them.
 		DisablePathLenChecks:        true,
 		DisableNameConstraintChecks: true,
-		DisableNameChecks:           false,
-		KeyUsages:                   validationOpts.extKeyUsages,
+		// Logs accept certificates whatever their policies.
+		DisablePolicyChecks: true,
+		DisableNameChecks:   false,
+		KeyUsages:           validationOpts.extKeyUsages,
 	}
 
 	verifiedChains, err := cert.Verify(verifyOpts)
 	if err != nil {
-		return nil, err
+		return nil, verifyFailure(chain, err)
 	}
 
 	if len(verifiedChains) == 0 {
-		return nil, errors.New("no path to root found when trying to validate chains")
+		return nil, rejectChain(ChainRejectUnknownRoot, -1, errors.New("no path to root found when trying to validate chains"))
 	}
 
 	// Verify might have found multiple paths to roots. Now we check that we have a path that
@@ -168,11 +229,30 @@ func ValidateChain(rawChain [][]byte, validationOpts CertValidationOpts) ([]*x50
 	// requirements detailed in Section 3.1.
 	for _, verifiedChain := range verifiedChains {
 		if chainsEquivalent(chain, verifiedChain) {
+			if err := checkPath(verifiedChain, validationOpts, now); err != nil {
+				return nil, err
+			}
 			return verifiedChain, nil
 		}
 	}
 
-	return nil, errors.New("no RFC compliant path to root found when trying to validate chain")
+	return nil, rejectChain(ChainRejectInvalidChain, -1, errors.New("no RFC compliant path to root found when trying to validate chain"))
+}
+
+// verifyFailure classifies an error from verifying the submitted chain.
+func verifyFailure(chain []*x509.Certificate, err error) error {
+	var uae x509.UnknownAuthorityError
+	if !errors.As(err, &uae) {
+		return rejectChain(ChainRejectInvalidChain, -1, err)
+	}
+	// The submitted certificates must each be signed by the next one, so a
+	// signature which doesn't verify explains the missing path to a root.
+	for i := 0; i+1 < len(chain); i++ {
+		if chain[i].CheckSignatureFrom(chain[i+1]) != nil {
+			return rejectChain(ChainRejectInvalidSignature, i, err)
+		}
+	}
+	return rejectChain(ChainRejectUnknownRoot, len(chain)-1, err)
 }
 
 func chainsEquivalent(inChain []*x509.Certificate, verifiedChain []*x509.Certificate) bool {
@@ -190,3 +270,71 @@ func chainsEquivalent(inChain []*x509.Certificate, verifiedChain []*x509.Certifi
 	}
 	return true
 }
+
+// checkPath applies the log's chain acceptance policy to a verified path,
+// which runs from the submitted certificate to a trusted root.
+func checkPath(path []*x509.Certificate, opts CertValidationOpts, now time.Time) error {
+	if opts.maxChainLength > 0 && len(path) > opts.maxChainLength {
+		return rejectChain(ChainRejectPolicy, -1, fmt.Errorf("chain of %d certificates is longer than the maximum of %d", len(path), opts.maxChainLength))
+	}
+	if opts.requireServerAuthPath && !allowsServerAuth(path[0]) {
+		return rejectChain(ChainRejectPolicy, 0, errors.New("rejecting certificate without ServerAuth EKU"))
+	}
+	if len(path) < 3 {
+		return nil // No intermediates.
+	}
+	for i, cert := range path[1 : len(path)-1] {
+		if opts.rejectExpiredIntermediates && now.Add(-opts.clockSkew).After(cert.NotAfter) {
+			return rejectChain(ChainRejectExpired, i+1, fmt.Errorf("intermediate certificate %d expired at %v", i+1, cert.NotAfter))
+		}
+		if opts.rejectBridgedChains && isCrossSigned(cert, opts.trustedRoots) {
+			return rejectChain(ChainRejectPolicy, i+1, fmt.Errorf("intermediate certificate %d is a cross-signed root", i+1))
+		}
+		// A precertificate signing certificate only has the CT EKU.
+		if opts.requireServerAuthPath && !isPreIssuer(cert) && !allowsServerAuth(cert) {
+			return rejectChain(ChainRejectPolicy, i+1, fmt.Errorf("intermediate certificate %d does not allow ServerAuth EKU", i+1))
+		}
+	}
+	return nil
+}
+
+// allowsServerAuth reports whether the certificate may be used for TLS server
+// authentication, i.e. whether it has no EKU restrictions, or an EKU of
+// ServerAuth or Any.
+func allowsServerAuth(cert *x509.Certificate) bool {
+	if len(cert.ExtKeyUsage) == 0 && len(cert.UnknownExtKeyUsage) == 0 {
+		return true
+	}
+	for _, eku := range cert.ExtKeyUsage {
+		if eku == x509.ExtKeyUsageServerAuth || eku == x509.ExtKeyUsageAny {
+			return true
+		}
+	}
+	return false
+}
+
+// isPreIssuer reports whether the certificate is a precertificate signing
+// certificate, as identified by the CT EKU.
+func isPreIssuer(cert *x509.Certificate) bool {
+	for _, eku := range cert.ExtKeyUsage {
+		if eku == x509.ExtKeyUsageCertificateTransparency {
+			return true
+		}
+	}
+	return false
+}
+
+// isCrossSigned reports whether the certificate is a cross-sign of one of the
+// trusted roots, i.e. it carries the subject and key of a root but is issued
+// by another CA. A path through it bridges from one hierarchy to another.
+func isCrossSigned(cert *x509.Certificate, roots *x509util.PEMCertPool) bool {
+	if bytes.Equal(cert.RawSubject, cert.RawIssuer) {
+		return false
+	}
+	for _, root := range roots.RawCertificates() {
+		if bytes.Equal(root.RawSubject, cert.RawSubject) && bytes.Equal(root.RawSubjectPublicKeyInfo, cert.RawSubjectPublicKeyInfo) {
+			return true
+		}
+	}
+	return false
+}
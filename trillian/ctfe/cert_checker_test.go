@@ -15,8 +15,13 @@
 package ctfe
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"encoding/base64"
 	"encoding/pem"
+	"errors"
+	"math/big"
 	"strings"
 	"testing"
 	"time"
@@ -45,6 +50,14 @@ func makePoisonNonNull(cert *x509.Certificate) *x509.Certificate {
 	return cert
 }
 
+// corruptSignature flips a bit in the signature of the first certificate in
+// the chain, which is at the end of its DER encoding.
+func corruptSignature(chain [][]byte) [][]byte {
+	leaf := append([]byte(nil), chain[0]...)
+	leaf[len(leaf)-1] ^= 1
+	return append([][]byte{leaf}, chain[1:]...)
+}
+
 func TestIsPrecertificate(t *testing.T) {
 	var tests = []struct {
 		desc        string
@@ -122,28 +135,39 @@ func TestValidateChain(t *testing.T) {
 		desc        string
 		chain       [][]byte
 		wantErr     bool
+		wantReason  string
 		wantPathLen int
 		modifyOpts  func(v *CertValidationOpts)
 	}{
 		{
-			desc:    "missing-intermediate-cert",
-			chain:   pemsToDERChain(t, []string{testonly.LeafSignedByFakeIntermediateCertPEM}),
-			wantErr: true,
+			desc:       "missing-intermediate-cert",
+			chain:      pemsToDERChain(t, []string{testonly.LeafSignedByFakeIntermediateCertPEM}),
+			wantErr:    true,
+			wantReason: ChainRejectUnknownRoot,
 		},
 		{
-			desc:    "wrong-cert-order",
-			chain:   pemsToDERChain(t, []string{testonly.FakeIntermediateCertPEM, testonly.LeafSignedByFakeIntermediateCertPEM}),
-			wantErr: true,
+			desc:       "wrong-cert-order",
+			chain:      pemsToDERChain(t, []string{testonly.FakeIntermediateCertPEM, testonly.LeafSignedByFakeIntermediateCertPEM}),
+			wantErr:    true,
+			wantReason: ChainRejectInvalidChain,
 		},
 		{
-			desc:    "unrelated-cert-in-chain",
-			chain:   pemsToDERChain(t, []string{testonly.FakeIntermediateCertPEM, testonly.TestCertPEM}),
-			wantErr: true,
+			desc:       "unrelated-cert-in-chain",
+			chain:      pemsToDERChain(t, []string{testonly.FakeIntermediateCertPEM, testonly.TestCertPEM}),
+			wantErr:    true,
+			wantReason: ChainRejectInvalidChain,
 		},
 		{
-			desc:    "unrelated-cert-after-chain",
-			chain:   pemsToDERChain(t, []string{testonly.LeafSignedByFakeIntermediateCertPEM, testonly.FakeIntermediateCertPEM, testonly.TestCertPEM}),
-			wantErr: true,
+			desc:       "unrelated-cert-after-chain",
+			chain:      pemsToDERChain(t, []string{testonly.LeafSignedByFakeIntermediateCertPEM, testonly.FakeIntermediateCertPEM, testonly.TestCertPEM}),
+			wantErr:    true,
+			wantReason: ChainRejectInvalidChain,
+		},
+		{
+			desc:       "bad-leaf-signature",
+			chain:      corruptSignature(pemsToDERChain(t, []string{testonly.LeafSignedByFakeIntermediateCertPEM, testonly.FakeIntermediateCertPEM})),
+			wantErr:    true,
+			wantReason: ChainRejectInvalidSignature,
 		},
 		{
 			desc:        "valid-chain",
@@ -176,9 +200,10 @@ func TestValidateChain(t *testing.T) {
 			wantPathLen: 4,
 		},
 		{
-			desc:    "misordered-chain-of-len-4",
-			chain:   pemFileToDERChain(t, "../testdata/subleaf.misordered.chain"),
-			wantErr: true,
+			desc:       "misordered-chain-of-len-4",
+			chain:      pemFileToDERChain(t, "../testdata/subleaf.misordered.chain"),
+			wantErr:    true,
+			wantReason: ChainRejectInvalidChain,
 		},
 		{
 			desc:  "reject-non-existent-ext-id",
@@ -199,27 +224,30 @@ func TestValidateChain(t *testing.T) {
 			wantPathLen: 2,
 		},
 		{
-			desc:    "reject-ext-id",
-			chain:   pemsToDERChain(t, []string{testonly.LeafSignedByFakeIntermediateCertPEM, testonly.FakeIntermediateCertPEM}),
-			wantErr: true,
+			desc:       "reject-ext-id",
+			chain:      pemsToDERChain(t, []string{testonly.LeafSignedByFakeIntermediateCertPEM, testonly.FakeIntermediateCertPEM}),
+			wantErr:    true,
+			wantReason: ChainRejectPolicy,
 			modifyOpts: func(v *CertValidationOpts) {
 				// reject SubjectKeyIdentifier extension
 				v.rejectExtIds = []asn1.ObjectIdentifier{[]int{2, 5, 29, 14}}
 			},
 		},
 		{
-			desc:    "reject-ext-id-precert",
-			chain:   pemsToDERChain(t, []string{testonly.PrecertPEMValid}),
-			wantErr: true,
+			desc:       "reject-ext-id-precert",
+			chain:      pemsToDERChain(t, []string{testonly.PrecertPEMValid}),
+			wantErr:    true,
+			wantReason: ChainRejectPolicy,
 			modifyOpts: func(v *CertValidationOpts) {
 				// reject SubjectKeyIdentifier extension
 				v.rejectExtIds = []asn1.ObjectIdentifier{[]int{2, 5, 29, 14}}
 			},
 		},
 		{
-			desc:    "reject-eku-not-present-in-cert",
-			chain:   pemsToDERChain(t, []string{testonly.LeafSignedByFakeIntermediateCertPEM, testonly.FakeIntermediateCertPEM}),
-			wantErr: true,
+			desc:       "reject-eku-not-present-in-cert",
+			chain:      pemsToDERChain(t, []string{testonly.LeafSignedByFakeIntermediateCertPEM, testonly.FakeIntermediateCertPEM}),
+			wantErr:    true,
+			wantReason: ChainRejectPolicy,
 			modifyOpts: func(v *CertValidationOpts) {
 				// reject cert without ExtKeyUsageEmailProtection
 				v.extKeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection}
@@ -234,9 +262,10 @@ func TestValidateChain(t *testing.T) {
 			},
 		},
 		{
-			desc:    "reject-eku-not-present-in-precert",
-			chain:   pemsToDERChain(t, []string{testonly.RealPrecertWithEKUPEM}),
-			wantErr: true,
+			desc:       "reject-eku-not-present-in-precert",
+			chain:      pemsToDERChain(t, []string{testonly.RealPrecertWithEKUPEM}),
+			wantErr:    true,
+			wantReason: ChainRejectPolicy,
 			modifyOpts: func(v *CertValidationOpts) {
 				// reject cert without ExtKeyUsageEmailProtection
 				v.extKeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection}
@@ -262,6 +291,12 @@ func TestValidateChain(t *testing.T) {
 				if !test.wantErr {
 					t.Errorf("ValidateChain()=%v,%v; want _,nil", gotPath, err)
 				}
+				var cve *ChainValidationError
+				if !errors.As(err, &cve) {
+					t.Errorf("ValidateChain()=%v; want ChainValidationError", err)
+				} else if cve.Reason != test.wantReason {
+					t.Errorf("ValidateChain() rejected for %q; want %q", cve.Reason, test.wantReason)
+				}
 				return
 			}
 			if test.wantErr {
@@ -415,13 +450,17 @@ func TestRejectExpiredUnexpired(t *testing.T) {
 	beforeValidPeriod := time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC)
 	currentValidPeriod := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
 	afterValidPeriod := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	leaf := pemToCert(t, testonly.LeafSignedByFakeIntermediateCertPEM)
+	zero, week := time.Duration(0), 7*24*time.Hour
 
 	for _, tc := range []struct {
-		desc            string
-		rejectExpired   bool
-		rejectUnexpired bool
-		now             time.Time
-		wantErr         string
+		desc               string
+		rejectExpired      bool
+		rejectUnexpired    bool
+		maxNotBeforeFuture *time.Duration
+		clockSkew          time.Duration
+		now                time.Time
+		wantErr            string
 	}{
 		// No flags: accept anything.
 		{
@@ -493,11 +532,56 @@ func TestRejectExpiredUnexpired(t *testing.T) {
 			now:             currentValidPeriod,
 			wantErr:         "rejecting unexpired certificate",
 		},
+		// Clock skew and limits on NotBefore.
+		{
+			desc:          "reject-expired-within-skew",
+			rejectExpired: true,
+			clockSkew:     2 * time.Hour,
+			now:           leaf.NotAfter.Add(time.Hour),
+		},
+		{
+			desc:          "reject-expired-beyond-skew",
+			rejectExpired: true,
+			clockSkew:     time.Hour,
+			now:           leaf.NotAfter.Add(2 * time.Hour),
+			wantErr:       "rejecting expired certificate",
+		},
+		{
+			desc:               "max-not-before-future-current",
+			maxNotBeforeFuture: &zero,
+			now:                currentValidPeriod,
+		},
+		{
+			desc:               "max-not-before-future-before",
+			maxNotBeforeFuture: &week,
+			now:                beforeValidPeriod,
+			wantErr:            "in the future",
+		},
+		{
+			desc:               "max-not-before-future-within-limit",
+			maxNotBeforeFuture: &week,
+			now:                leaf.NotBefore.Add(-6 * 24 * time.Hour),
+		},
+		{
+			desc:               "max-not-before-future-within-skew",
+			maxNotBeforeFuture: &zero,
+			clockSkew:          time.Hour,
+			now:                leaf.NotBefore.Add(-time.Minute),
+		},
+		{
+			desc:               "max-not-before-future-beyond-skew",
+			maxNotBeforeFuture: &zero,
+			clockSkew:          time.Minute,
+			now:                leaf.NotBefore.Add(-time.Hour),
+			wantErr:            "in the future",
+		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
 			validateOpts.currentTime = tc.now
 			validateOpts.rejectExpired = tc.rejectExpired
 			validateOpts.rejectUnexpired = tc.rejectUnexpired
+			validateOpts.maxNotBeforeFuture = tc.maxNotBeforeFuture
+			validateOpts.clockSkew = tc.clockSkew
 			_, err := ValidateChain(chain, validateOpts)
 			if err != nil {
 				if len(tc.wantErr) == 0 {
@@ -599,3 +683,180 @@ func TestCMPreIssuedCert(t *testing.T) {
 		})
 	}
 }
+
+// testCA is a generated CA certificate along with its key.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// issueCert creates a certificate from the template, with the given key,
+// signed by parent (or self-signed if parent is nil).
+func issueCert(t *testing.T, tmpl *x509.Certificate, key *ecdsa.PrivateKey, parent *testCA) *x509.Certificate {
+	t.Helper()
+	issuer, signer := tmpl, key
+	if parent != nil {
+		issuer, signer = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, issuer, key.Public(), signer)
+	if err != nil {
+		t.Fatalf("CreateCertificate(%v): %v", tmpl.Subject, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate(%v): %v", tmpl.Subject, err)
+	}
+	return cert
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	return key
+}
+
+func caTemplate(serial int64, cn string, notAfter time.Time, eku ...x509.ExtKeyUsage) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign,
+		ExtKeyUsage:           eku,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+}
+
+func TestChainPolicy(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	earlier := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newRoot := func(serial int64, cn string) *testCA {
+		key := newTestKey(t)
+		return &testCA{cert: issueCert(t, caTemplate(serial, cn, later), key, nil), key: key}
+	}
+	newIntermediate := func(serial int64, cn string, notAfter time.Time, parent *testCA, eku ...x509.ExtKeyUsage) *testCA {
+		key := newTestKey(t)
+		return &testCA{cert: issueCert(t, caTemplate(serial, cn, notAfter, eku...), key, parent), key: key}
+	}
+	newLeaf := func(serial int64, parent *testCA, eku ...x509.ExtKeyUsage) *x509.Certificate {
+		return issueCert(t, &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "leaf.example.com"},
+			DNSNames:     []string{"leaf.example.com"},
+			NotBefore:    earlier,
+			NotAfter:     later,
+			ExtKeyUsage:  eku,
+		}, newTestKey(t), parent)
+	}
+
+	oldRoot := newRoot(1, "Old Root")
+	newRootCA := newRoot(2, "New Root")
+	// A cross-sign of the new root by the old one.
+	bridge := &testCA{cert: issueCert(t, caTemplate(3, "New Root", later), newRootCA.key, oldRoot), key: newRootCA.key}
+	current := newIntermediate(4, "Current Intermediate", later, newRootCA)
+	expired := newIntermediate(5, "Expired Intermediate", earlier, newRootCA)
+	clientAuth := newIntermediate(6, "Client Intermediate", later, newRootCA, x509.ExtKeyUsageClientAuth)
+	serverAuth := newIntermediate(7, "Server Intermediate", later, newRootCA, x509.ExtKeyUsageServerAuth)
+
+	roots := x509util.NewPEMCertPool()
+	roots.AddCert(oldRoot.cert)
+	roots.AddCert(newRootCA.cert)
+
+	der := func(certs ...*x509.Certificate) [][]byte {
+		var chain [][]byte
+		for _, c := range certs {
+			chain = append(chain, c.Raw)
+		}
+		return chain
+	}
+	leaf := newLeaf(10, current, x509.ExtKeyUsageServerAuth)
+
+	for _, tc := range []struct {
+		desc       string
+		chain      [][]byte
+		modifyOpts func(v *CertValidationOpts)
+		wantErr    string
+	}{
+		{
+			desc:  "default-policy",
+			chain: der(leaf, current.cert, bridge.cert),
+		},
+		{
+			desc:       "within-max-length",
+			chain:      der(leaf, current.cert),
+			modifyOpts: func(v *CertValidationOpts) { v.maxChainLength = 3 },
+		},
+		{
+			desc:       "exceeds-max-length",
+			chain:      der(leaf, current.cert, bridge.cert),
+			modifyOpts: func(v *CertValidationOpts) { v.maxChainLength = 3 },
+			wantErr:    "longer than the maximum",
+		},
+		{
+			desc:       "bridged-rejected",
+			chain:      der(leaf, current.cert, bridge.cert),
+			modifyOpts: func(v *CertValidationOpts) { v.rejectBridgedChains = true },
+			wantErr:    "cross-signed root",
+		},
+		{
+			desc:       "unbridged-accepted",
+			chain:      der(leaf, current.cert),
+			modifyOpts: func(v *CertValidationOpts) { v.rejectBridgedChains = true },
+		},
+		{
+			desc:  "expired-intermediate-allowed",
+			chain: der(newLeaf(11, expired), expired.cert),
+		},
+		{
+			desc:       "expired-intermediate-rejected",
+			chain:      der(newLeaf(12, expired), expired.cert),
+			modifyOpts: func(v *CertValidationOpts) { v.rejectExpiredIntermediates = true },
+			wantErr:    "intermediate certificate 1 expired",
+		},
+		{
+			desc:       "server-auth-path",
+			chain:      der(newLeaf(13, serverAuth, x509.ExtKeyUsageServerAuth), serverAuth.cert),
+			modifyOpts: func(v *CertValidationOpts) { v.requireServerAuthPath = true },
+		},
+		{
+			desc:       "server-auth-path-no-ekus",
+			chain:      der(newLeaf(14, current), current.cert),
+			modifyOpts: func(v *CertValidationOpts) { v.requireServerAuthPath = true },
+		},
+		{
+			desc:       "server-auth-path-leaf",
+			chain:      der(newLeaf(15, current, x509.ExtKeyUsageClientAuth), current.cert),
+			modifyOpts: func(v *CertValidationOpts) { v.requireServerAuthPath = true },
+			wantErr:    "without ServerAuth EKU",
+		},
+		{
+			desc:       "server-auth-path-intermediate",
+			chain:      der(newLeaf(16, clientAuth, x509.ExtKeyUsageServerAuth), clientAuth.cert),
+			modifyOpts: func(v *CertValidationOpts) { v.requireServerAuthPath = true },
+			wantErr:    "does not allow ServerAuth EKU",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			opts := CertValidationOpts{trustedRoots: roots, currentTime: now}
+			if tc.modifyOpts != nil {
+				tc.modifyOpts(&opts)
+			}
+			_, err := ValidateChain(tc.chain, opts)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateChain()=%v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("ValidateChain()=%v, want err containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
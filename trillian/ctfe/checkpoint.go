@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+)
+
+// CheckpointPath is the path, under a log's prefix, on which its latest STH
+// is served as a checkpoint.
+const CheckpointPath = "/checkpoint"
+
+// rfc6962NoteSignatureType identifies a note signature which is an RFC 6962
+// tree head signature, as defined by the C2SP signed-note specification.
+const rfc6962NoteSignatureType = 0x05
+
+// getCheckpoint serves the log's latest STH as a checkpoint, i.e. a signed
+// note in the format of https://go.dev/design/25530-sumdb and
+// https://c2sp.org/tlog-checkpoint, so that it can be consumed by generic
+// transparency log tooling.
+func getCheckpoint(ctx context.Context, li *logInfo, w http.ResponseWriter, r *http.Request) (int, error) {
+	qctx := ctx
+	if li.instanceOpts.RemoteQuotaUser != nil {
+		qctx = context.WithValue(qctx, remoteQuotaCtxKey, li.instanceOpts.RemoteQuotaUser(r))
+	}
+	sth, err := li.getSTH(qctx)
+	if err != nil {
+		return li.toHTTPStatus(err), err
+	}
+	origin := li.instanceOpts.Validated.Config.CheckpointOrigin
+	if len(origin) == 0 {
+		origin = r.Host + strings.TrimSuffix(r.URL.Path, CheckpointPath)
+	}
+	note, err := formatCheckpoint(origin, sth, li.signer.Public())
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	w.Header().Set(cacheControlHeader, cacheControlSTH)
+	w.Header().Set(contentTypeHeader, "text/plain; charset=utf-8")
+	if _, err := w.Write(note); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to write checkpoint: %s", err)
+	}
+	return http.StatusOK, nil
+}
+
+// formatCheckpoint returns the checkpoint for the given STH as a signed note.
+// The note's single signature reuses the STH's signature, in the RFC 6962
+// note signature format: the key ID, then the STH's timestamp, then its
+// TLS-encoded DigitallySigned signature.
+func formatCheckpoint(origin string, sth *ct.SignedTreeHead, pubKey crypto.PublicKey) ([]byte, error) {
+	if len(origin) == 0 || strings.ContainsAny(origin, "\n+ ") {
+		return nil, fmt.Errorf("invalid checkpoint origin %q", origin)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %v", err)
+	}
+	keyHash := sha256.New()
+	keyHash.Write([]byte(origin))
+	keyHash.Write([]byte{'\n', rfc6962NoteSignatureType})
+	keyHash.Write(der)
+
+	sig, err := tls.Marshal(sth.TreeHeadSignature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tls.Marshal signature: %s", err)
+	}
+	noteSig := make([]byte, 0, 4+8+len(sig))
+	noteSig = append(noteSig, keyHash.Sum(nil)[:4]...)
+	noteSig = binary.BigEndian.AppendUint64(noteSig, sth.Timestamp)
+	noteSig = append(noteSig, sig...)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s\n%d\n%s\n", origin, sth.TreeSize, base64.StdEncoding.EncodeToString(sth.SHA256RootHash[:]))
+	fmt.Fprintf(&b, "\n— %s %s\n", origin, base64.StdEncoding.EncodeToString(noteSig))
+	return b.Bytes(), nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/golang/mock/gomock"
+)
+
+func TestGetCheckpoint(t *testing.T) {
+	signer, err := setupSigner(fakeSignature)
+	if err != nil {
+		t.Fatalf("Failed to create test signer: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey()=%v", err)
+	}
+	rootHash := []byte("abcdabcdabcdabcdabcdabcdabcdabcd")
+
+	for _, tc := range []struct {
+		desc   string
+		origin string
+		url    string
+		want   string
+	}{
+		{desc: "configured", origin: "ct.example.com/logs/test", url: "http://log.example.com/test/checkpoint", want: "ct.example.com/logs/test"},
+		{desc: "from-request", url: "http://log.example.com/logs/test/checkpoint", want: "log.example.com/logs/test"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			info := setupTest(t, nil, signer)
+			defer info.mockCtrl.Finish()
+			info.li.instanceOpts.Validated.Config.CheckpointOrigin = tc.origin
+			info.client.EXPECT().GetLatestSignedLogRoot(gomock.Any(), gomock.Any()).Return(makeGetRootResponseForTest(t, 12345000000, 25, rootHash), nil)
+			handler := AppHandler{Info: info.li, Handler: getCheckpoint, Name: GetCheckpointName, Method: http.MethodGet}
+
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if got, want := w.Code, http.StatusOK; got != want {
+				t.Fatalf("ServeHTTP()=%d, want %d: %s", got, want, w.Body)
+			}
+
+			body, sigLine, ok := strings.Cut(w.Body.String(), "\n\n")
+			if !ok {
+				t.Fatalf("checkpoint %q has no signature", w.Body)
+			}
+			if want := tc.want + "\n25\n" + base64.StdEncoding.EncodeToString(rootHash) + "\n"; body+"\n" != want {
+				t.Errorf("checkpoint body=%q, want %q", body+"\n", want)
+			}
+			prefix := "— " + tc.want + " "
+			if !strings.HasPrefix(sigLine, prefix) || !strings.HasSuffix(sigLine, "\n") {
+				t.Fatalf("signature line %q, want prefix %q", sigLine, prefix)
+			}
+			sig, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(sigLine, prefix), "\n"))
+			if err != nil {
+				t.Fatalf("failed to decode signature: %v", err)
+			}
+			keyID := sha256.Sum256(append([]byte(tc.want+"\n\x05"), der...))
+			if len(sig) < 12 || !bytes.Equal(sig[:4], keyID[:4]) {
+				t.Errorf("signature %x, want key ID %x", sig, keyID[:4])
+			}
+			if got, want := binary.BigEndian.Uint64(sig[4:12]), uint64(12345); got != want {
+				t.Errorf("signature timestamp=%d, want %d", got, want)
+			}
+			if got, want := sig[12:], append([]byte{0x04, 0x03, 0x00, 0x06}, fakeSignature...); !bytes.Equal(got, want) {
+				t.Errorf("signature DigitallySigned=%x, want %x", got, want)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	ct "github.com/RarimoVoting/certificate-transparency-go"
@@ -29,6 +30,10 @@ import (
 	"k8s.io/klog/v2"
 )
 
+// maxCTExtensionsLen is the maximum length of the CtExtensions field of an
+// SCT, which is a TLS opaque<0..2^16-1>.
+const maxCTExtensionsLen = 65535
+
 // ValidatedLogConfig represents the LogConfig with the information that has
 // been successfully parsed as a result of validating it.
 type ValidatedLogConfig struct {
@@ -39,6 +44,9 @@ type ValidatedLogConfig struct {
 	NotAfterStart *time.Time
 	NotAfterLimit *time.Time
 	FrozenSTH     *ct.SignedTreeHead
+	// MaxNotBeforeFuture is nil if future NotBefore values are not limited.
+	MaxNotBeforeFuture *time.Duration
+	ClockSkew          time.Duration
 }
 
 // LogConfigFromFile creates a slice of LogConfig options from the given
@@ -106,6 +114,14 @@ func MultiLogConfigFromFile(filename string) (*configpb.LogMultiConfig, error) {
 //     proto. If both are set then NotBeforeStart <= NotBeforeLimit.
 //   - Merge delays (if present) are correct.
 //   - Frozen STH (if present) is correct and signed by the provided public key.
+//   - SCT extensions (if present) fit in an SCT and are not set for a mirror.
+//   - A mirror source URI (if present) is only set for a mirror.
+//   - The maximum chain length is not negative.
+//   - At most one of PrecertsOnly and CertsOnly is set.
+//   - StandbyAcceptsWrites is only set along with a standby backend.
+//   - The virtual host (if present) is a host name without a port.
+//   - MaxNotBeforeFuture and ClockSkew (if present) are valid, non-negative
+//     durations.
 //
 // Returns the validated structures (useful to avoid double validation).
 func ValidateLogConfig(cfg *configpb.LogConfig) (*ValidatedLogConfig, error) {
@@ -141,10 +157,39 @@ func ValidateLogConfig(cfg *configpb.LogConfig) (*ValidatedLogConfig, error) {
 		return nil, errors.New("unnecessary private key for mirror")
 	}
 
+	if len(cfg.MirrorSourceUri) > 0 && !cfg.IsMirror {
+		return nil, errors.New("mirror source URI for non-mirror log")
+	}
+
+	if strings.ContainsAny(cfg.VirtualHost, "/:") {
+		return nil, fmt.Errorf("invalid virtual host %q: must be a host name without a port", cfg.VirtualHost)
+	}
+
+	if cfg.StandbyAcceptsWrites && len(cfg.StandbyLogBackendName) == 0 {
+		return nil, errors.New("standby accepts writes but no standby backend")
+	}
+
+	if cfg.PrecertsOnly && cfg.CertsOnly {
+		return nil, errors.New("log accepts neither certificates nor precertificates")
+	}
+
+	if cfg.MaxChainLength < 0 {
+		return nil, fmt.Errorf("negative max chain length: %d", cfg.MaxChainLength)
+	}
+
 	if cfg.RejectExpired && cfg.RejectUnexpired {
 		return nil, errors.New("rejecting all certificates")
 	}
 
+	if len(cfg.SctExtensions) > 0 {
+		if cfg.IsMirror {
+			return nil, errors.New("unnecessary SCT extensions for mirror")
+		}
+		if len(cfg.SctExtensions) > maxCTExtensionsLen {
+			return nil, fmt.Errorf("SCT extensions too long: %d bytes, limit %d", len(cfg.SctExtensions), maxCTExtensionsLen)
+		}
+	}
+
 	// Validate the extended key usages list.
 	if len(cfg.ExtKeyUsages) > 0 {
 		for _, kuStr := range cfg.ExtKeyUsages {
@@ -183,6 +228,25 @@ func ValidateLogConfig(cfg *configpb.LogConfig) (*ValidatedLogConfig, error) {
 		return nil, errors.New("limit before start")
 	}
 
+	if d := cfg.MaxNotBeforeFuture; d != nil {
+		if err := d.CheckValid(); err != nil {
+			return nil, fmt.Errorf("invalid max NotBefore future: %v", err)
+		}
+		if d.AsDuration() < 0 {
+			return nil, errors.New("negative max NotBefore future")
+		}
+		vCfg.MaxNotBeforeFuture = new(time.Duration)
+		*vCfg.MaxNotBeforeFuture = d.AsDuration()
+	}
+	if d := cfg.ClockSkew; d != nil {
+		if err := d.CheckValid(); err != nil {
+			return nil, fmt.Errorf("invalid clock skew: %v", err)
+		}
+		if vCfg.ClockSkew = d.AsDuration(); vCfg.ClockSkew < 0 {
+			return nil, errors.New("negative clock skew")
+		}
+	}
+
 	switch {
 	case cfg.MaxMergeDelaySec < 0:
 		return nil, errors.New("negative maximum merge delay")
@@ -249,13 +313,15 @@ func validateConfigs(cfg []*configpb.LogConfig) error {
 		if _, err := ValidateLogConfig(logCfg); err != nil {
 			return fmt.Errorf("log config: %v: %v", err, logCfg)
 		}
-		if len(logCfg.Prefix) == 0 {
+		if len(logCfg.Prefix) == 0 && len(logCfg.VirtualHost) == 0 {
 			return fmt.Errorf("log config: empty prefix: %v", logCfg)
 		}
-		if logNameMap[logCfg.Prefix] {
+		// Prefixes only need to be distinct within a virtual host.
+		name := logCfg.VirtualHost + "/" + logCfg.Prefix
+		if logNameMap[name] {
 			return fmt.Errorf("log config: duplicate prefix: %s: %v", logCfg.Prefix, logCfg)
 		}
-		logNameMap[logCfg.Prefix] = true
+		logNameMap[name] = true
 	}
 
 	return nil
@@ -265,8 +331,8 @@ func validateConfigs(cfg []*configpb.LogConfig) error {
 // server. The rules applied are:
 //
 // 1. All log configs must be valid (see ValidateLogConfig).
-// 2. The prefixes of configured logs must all be distinct and must not be
-// empty.
+// 2. The prefixes of configured logs must all be distinct within each virtual
+// host, and must not be empty unless a virtual host is set.
 // 3. The set of tree IDs must be distinct.
 func ValidateLogConfigs(cfg []*configpb.LogConfig) error {
 	if err := validateConfigs(cfg); err != nil {
@@ -316,6 +382,14 @@ func ValidateLogMultiConfig(cfg *configpb.LogMultiConfig) (LogBackendMap, error)
 		if _, ok := backendMap[logCfg.LogBackendName]; !ok {
 			return nil, fmt.Errorf("log config: references undefined backend: %s: %v", logCfg.LogBackendName, logCfg)
 		}
+		if sb := logCfg.StandbyLogBackendName; len(sb) > 0 {
+			if _, ok := backendMap[sb]; !ok {
+				return nil, fmt.Errorf("log config: references undefined standby backend: %s: %v", sb, logCfg)
+			}
+			if sb == logCfg.LogBackendName {
+				return nil, fmt.Errorf("log config: standby backend is the same as the primary: %s: %v", sb, logCfg)
+			}
+		}
 		logIDKey := fmt.Sprintf("%s-%d", logCfg.LogBackendName, logCfg.LogId)
 		if ok := logIDMap[logIDKey]; ok {
 			return nil, fmt.Errorf("log config: dup tree id: %d for: %v", logCfg.LogId, logCfg)
@@ -22,11 +22,13 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/configpb"
 	"github.com/google/trillian/crypto/keyspb"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -259,6 +261,89 @@ func TestValidateLogConfig(t *testing.T) {
 				FrozenSth:  corruptedSTH,
 			},
 		},
+		{
+			desc:    "sct-extensions-mirror",
+			wantErr: "unnecessary SCT extensions",
+			cfg: &configpb.LogConfig{
+				LogId:         123,
+				PublicKey:     pubKey,
+				IsMirror:      true,
+				SctExtensions: []byte{0x00},
+			},
+		},
+		{
+			desc:    "sct-extensions-too-long",
+			wantErr: "SCT extensions too long",
+			cfg: &configpb.LogConfig{
+				LogId:         123,
+				PrivateKey:    privKey,
+				SctExtensions: make([]byte, 65536),
+			},
+		},
+		{
+			desc:    "virtual-host-with-port",
+			wantErr: "invalid virtual host",
+			cfg: &configpb.LogConfig{
+				LogId:       123,
+				PrivateKey:  privKey,
+				VirtualHost: "log.example.com:443",
+			},
+		},
+		{
+			desc:    "standby-writes-without-standby",
+			wantErr: "standby accepts writes but no standby backend",
+			cfg: &configpb.LogConfig{
+				LogId:                123,
+				PrivateKey:           privKey,
+				StandbyAcceptsWrites: true,
+			},
+		},
+		{
+			desc:    "precerts-and-certs-only",
+			wantErr: "accepts neither certificates nor precertificates",
+			cfg: &configpb.LogConfig{
+				LogId:        123,
+				PrivateKey:   privKey,
+				PrecertsOnly: true,
+				CertsOnly:    true,
+			},
+		},
+		{
+			desc:    "negative-max-not-before-future",
+			wantErr: "negative max NotBefore future",
+			cfg: &configpb.LogConfig{
+				LogId:              123,
+				PrivateKey:         privKey,
+				MaxNotBeforeFuture: durationpb.New(-time.Hour),
+			},
+		},
+		{
+			desc:    "negative-clock-skew",
+			wantErr: "negative clock skew",
+			cfg: &configpb.LogConfig{
+				LogId:      123,
+				PrivateKey: privKey,
+				ClockSkew:  durationpb.New(-time.Minute),
+			},
+		},
+		{
+			desc:    "negative-max-chain-length",
+			wantErr: "negative max chain length",
+			cfg: &configpb.LogConfig{
+				LogId:          123,
+				PrivateKey:     privKey,
+				MaxChainLength: -1,
+			},
+		},
+		{
+			desc:    "mirror-source-uri-non-mirror",
+			wantErr: "mirror source URI for non-mirror log",
+			cfg: &configpb.LogConfig{
+				LogId:           123,
+				PrivateKey:      privKey,
+				MirrorSourceUri: "https://ct.example.com/log",
+			},
+		},
 		{
 			desc: "ok",
 			cfg: &configpb.LogConfig{
@@ -266,6 +351,14 @@ func TestValidateLogConfig(t *testing.T) {
 				PrivateKey: privKey,
 			},
 		},
+		{
+			desc: "ok-sct-extensions",
+			cfg: &configpb.LogConfig{
+				LogId:         123,
+				PrivateKey:    privKey,
+				SctExtensions: []byte{0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x00, 0x2a},
+			},
+		},
 		{
 			// Note: Substituting an arbitrary proto.Message as a PrivateKey will not
 			// fail the validation because the actual key loading happens at runtime.
@@ -285,6 +378,15 @@ func TestValidateLogConfig(t *testing.T) {
 				IsMirror:  true,
 			},
 		},
+		{
+			desc: "ok-mirror-source-uri",
+			cfg: &configpb.LogConfig{
+				LogId:           123,
+				PublicKey:       pubKey,
+				IsMirror:        true,
+				MirrorSourceUri: "https://ct.example.com/log",
+			},
+		},
 		{
 			desc: "ok-ext-key-usages",
 			cfg: &configpb.LogConfig{
@@ -456,6 +558,23 @@ func TestValidateLogMultiConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:    "duplicate-prefix-on-virtual-host",
+			wantErr: "duplicate prefix",
+			cfg: &configpb.LogMultiConfig{
+				Backends: &configpb.LogBackendSet{
+					Backend: []*configpb.LogBackend{
+						{Name: "log1", BackendSpec: "testspec1"},
+					},
+				},
+				LogConfigs: &configpb.LogConfigSet{
+					Config: []*configpb.LogConfig{
+						{LogId: 1, VirtualHost: "log2024.example.com", PrivateKey: privKey, LogBackendName: "log1"},
+						{LogId: 2, VirtualHost: "log2024.example.com", PrivateKey: privKey, LogBackendName: "log1"},
+					},
+				},
+			},
+		},
 		{
 			desc:    "references-undefined-backend",
 			wantErr: "references undefined backend",
@@ -472,6 +591,38 @@ func TestValidateLogMultiConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:    "references-undefined-standby-backend",
+			wantErr: "references undefined standby backend",
+			cfg: &configpb.LogMultiConfig{
+				Backends: &configpb.LogBackendSet{
+					Backend: []*configpb.LogBackend{
+						{Name: "log1", BackendSpec: "testspec"},
+					},
+				},
+				LogConfigs: &configpb.LogConfigSet{
+					Config: []*configpb.LogConfig{
+						{LogId: 2, Prefix: "pref2", PrivateKey: privKey, LogBackendName: "log1", StandbyLogBackendName: "log2"},
+					},
+				},
+			},
+		},
+		{
+			desc:    "standby-backend-is-primary",
+			wantErr: "standby backend is the same as the primary",
+			cfg: &configpb.LogMultiConfig{
+				Backends: &configpb.LogBackendSet{
+					Backend: []*configpb.LogBackend{
+						{Name: "log1", BackendSpec: "testspec"},
+					},
+				},
+				LogConfigs: &configpb.LogConfigSet{
+					Config: []*configpb.LogConfig{
+						{LogId: 2, Prefix: "pref2", PrivateKey: privKey, LogBackendName: "log1", StandbyLogBackendName: "log1"},
+					},
+				},
+			},
+		},
 		{
 			desc:    "dup-tree-id-on-same-backend",
 			wantErr: "dup tree id",
@@ -509,6 +660,24 @@ func TestValidateLogMultiConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "ok-dup-prefixes-on-different-virtual-hosts",
+			cfg: &configpb.LogMultiConfig{
+				Backends: &configpb.LogBackendSet{
+					Backend: []*configpb.LogBackend{
+						{Name: "log1", BackendSpec: "testspec1"},
+					},
+				},
+				LogConfigs: &configpb.LogConfigSet{
+					Config: []*configpb.LogConfig{
+						{LogId: 1, VirtualHost: "log2024.example.com", PrivateKey: privKey, LogBackendName: "log1"},
+						{LogId: 2, VirtualHost: "log2025.example.com", PrivateKey: privKey, LogBackendName: "log1"},
+						{LogId: 3, Prefix: "pref", VirtualHost: "log2025.example.com", PrivateKey: privKey, LogBackendName: "log1"},
+						{LogId: 4, Prefix: "pref", PrivateKey: privKey, LogBackendName: "log1"},
+					},
+				},
+			},
+		},
 		{
 			desc: "ok-dup-tree-ids-on-different-backends",
 			cfg: &configpb.LogMultiConfig{
@@ -25,6 +25,7 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	anypb "google.golang.org/protobuf/types/known/anypb"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -196,7 +197,7 @@ func (x *LogConfigSet) GetConfig() []*LogConfig {
 
 // LogConfig describes the configuration options for a log instance.
 //
-// NEXT_ID: 20
+// NEXT_ID: 34
 type LogConfig struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -211,8 +212,20 @@ type LogConfig struct {
 	// override handler prefix. For example if the handler prefix is "/logs"
 	// and prefix is "vogon" the get-sth handler for this log will be
 	// available at "/logs/vogon/ct/v1/get-sth". The prefix cannot be empty
-	// and must not include "/" path separator characters.
+	// (unless virtual_host is set) and must not include "/" path separator
+	// characters.
 	Prefix string `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// If set, the log is only served to requests for this virtual host, as
+	// given in the Host header, e.g. "log2025.example.com". This allows logs on
+	// different hosts to share a prefix, or to have an empty prefix so that the
+	// get-sth handler is available at "log2025.example.com/ct/v1/get-sth". Must
+	// be a host name without a port.
+	VirtualHost string `protobuf:"bytes,32,opt,name=virtual_host,json=virtualHost,proto3" json:"virtual_host,omitempty"`
+	// checkpoint_origin is the origin line of the checkpoints served on the
+	// log's /checkpoint endpoint, and the name of the key which signs them. It
+	// should uniquely identify the log, e.g. "ct.example.com/logs/vogon". If
+	// unset, the host and path under which the checkpoint is requested are used.
+	CheckpointOrigin string `protobuf:"bytes,33,opt,name=checkpoint_origin,json=checkpointOrigin,proto3" json:"checkpoint_origin,omitempty"`
 	// override_handler_prefix if set to a non empty value overrides the global
 	// handler prefix for an individual log. For example this field is set to
 	// "/otherlogs" then a log with prefix "vogon" will make it's get-sth handler
@@ -236,6 +249,14 @@ type LogConfig struct {
 	// If reject_unexpired is true then CTFE rejects certificates that are either
 	// currently valid or not yet valid.
 	RejectUnexpired bool `protobuf:"varint,17,opt,name=reject_unexpired,json=rejectUnexpired,proto3" json:"reject_unexpired,omitempty"`
+	// If set, CTFE rejects certificates whose NotBefore is more than this far in
+	// the future. A zero duration rejects all not yet valid certificates.
+	MaxNotBeforeFuture *durationpb.Duration `protobuf:"bytes,28,opt,name=max_not_before_future,json=maxNotBeforeFuture,proto3" json:"max_not_before_future,omitempty"`
+	// The tolerance for differences between the submitter's and the server's
+	// clocks, applied when checking reject_expired, max_not_before_future and
+	// reject_expired_intermediates. Certificates are only rejected when they
+	// are outside their validity period by more than this.
+	ClockSkew *durationpb.Duration `protobuf:"bytes,29,opt,name=clock_skew,json=clockSkew,proto3" json:"clock_skew,omitempty"`
 	// If set, ext_key_usages will restrict the set of such usages that the
 	// server will accept. By default all are accepted. The values specified
 	// must be ones known to the x509 package.
@@ -254,6 +275,13 @@ type LogConfig struct {
 	// backend_name if set indicates which backend serves this log. The name must be
 	// one of those defined in the LogBackendSet.
 	LogBackendName string `protobuf:"bytes,11,opt,name=log_backend_name,json=logBackendName,proto3" json:"log_backend_name,omitempty"`
+	// If set, the name of a standby backend, defined in the LogBackendSet, which
+	// serves the same tree as log_backend_name (e.g. a replica in another
+	// region). Reads fail over to it while the primary backend is failing.
+	StandbyLogBackendName string `protobuf:"bytes,30,opt,name=standby_log_backend_name,json=standbyLogBackendName,proto3" json:"standby_log_backend_name,omitempty"`
+	// If set, writes (add-chain etc.) also fail over to the standby backend. Only
+	// set this if the standby can safely accept writes for the tree.
+	StandbyAcceptsWrites bool `protobuf:"varint,31,opt,name=standby_accepts_writes,json=standbyAcceptsWrites,proto3" json:"standby_accepts_writes,omitempty"`
 	// If set, the log is a mirror, i.e. it serves the data of another (source)
 	// log. It doesn't handle write requests (add-chain, etc.), so it's not a
 	// fully fledged RFC-6962 log, but the tree read requests like get-entries and
@@ -261,9 +289,20 @@ type LogConfig struct {
 	// log's key and can't sign STHs. Consequently, the log operator must ensure
 	// to channel source log's STHs into CTFE.
 	IsMirror bool `protobuf:"varint,12,opt,name=is_mirror,json=isMirror,proto3" json:"is_mirror,omitempty"`
+	// The base URI of the source log, e.g. "https://ct.example.com/log". If set
+	// for a mirror, the CTFE continuously fetches the source log's entries and
+	// STHs, stores the entries in the mirror's PREORDERED_LOG tree, and serves
+	// the source log's STHs, as verified by public_key. Only allowed for mirrors.
+	MirrorSourceUri string `protobuf:"bytes,21,opt,name=mirror_source_uri,json=mirrorSourceUri,proto3" json:"mirror_source_uri,omitempty"`
 	// If set, the log serves only read endpoints, and rejects writes through the
 	// add-[pre-]chain endpoint.
 	IsReadonly bool `protobuf:"varint,19,opt,name=is_readonly,json=isReadonly,proto3" json:"is_readonly,omitempty"`
+	// If set, the log only accepts precertificates: add-chain requests are
+	// rejected with an error pointing at add-pre-chain.
+	PrecertsOnly bool `protobuf:"varint,26,opt,name=precerts_only,json=precertsOnly,proto3" json:"precerts_only,omitempty"`
+	// If set, the log only accepts final certificates: add-pre-chain requests
+	// are rejected with an error pointing at add-chain.
+	CertsOnly bool `protobuf:"varint,27,opt,name=certs_only,json=certsOnly,proto3" json:"certs_only,omitempty"`
 	// The Maximum Merge Delay (MMD) of this log in seconds. See RFC6962 section 3
 	// for definition of MMD. If zero, the log does not provide an MMD guarantee
 	// (for example, it is a frozen log).
@@ -283,6 +322,25 @@ type LogConfig struct {
 	// A list of X.509 extension OIDs, in dotted string form (e.g. "2.3.4.5")
 	// which should cause submissions to be rejected.
 	RejectExtensions []string `protobuf:"bytes,18,rep,name=reject_extensions,json=rejectExtensions,proto3" json:"reject_extensions,omitempty"`
+	// If positive, the maximum number of certificates in the validated chain of
+	// a submission, counting the leaf and the root. Longer chains are rejected.
+	MaxChainLength int32 `protobuf:"varint,22,opt,name=max_chain_length,json=maxChainLength,proto3" json:"max_chain_length,omitempty"`
+	// If set, the submitted certificate and every intermediate on its validated
+	// chain (other than precertificate signing certificates) must allow the
+	// ServerAuth extended key usage.
+	RequireServerAuthPath bool `protobuf:"varint,23,opt,name=require_server_auth_path,json=requireServerAuthPath,proto3" json:"require_server_auth_path,omitempty"`
+	// If set, chains which reach a trusted root only via a cross-signed version
+	// of another trusted root (a bridging cross-sign) are rejected.
+	RejectBridgedChains bool `protobuf:"varint,24,opt,name=reject_bridged_chains,json=rejectBridgedChains,proto3" json:"reject_bridged_chains,omitempty"`
+	// If set, chains containing an intermediate which has expired are rejected.
+	// By default only the validity of the submitted certificate is considered.
+	RejectExpiredIntermediates bool `protobuf:"varint,25,opt,name=reject_expired_intermediates,json=rejectExpiredIntermediates,proto3" json:"reject_expired_intermediates,omitempty"`
+	// sct_extensions holds the raw CtExtensions (see RFC6962 section 3.2) which
+	// are included in every SCT issued by this log. The same bytes are stored in
+	// the TimestampedEntry of the log's leaves, so they are covered by the SCT
+	// signature and remain consistent with the entries served by get-entries.
+	// Must be at most 65535 bytes long. Not allowed for mirrors.
+	SctExtensions []byte `protobuf:"bytes,20,opt,name=sct_extensions,json=sctExtensions,proto3" json:"sct_extensions,omitempty"`
 }
 
 func (x *LogConfig) Reset() {
@@ -331,6 +389,20 @@ func (x *LogConfig) GetPrefix() string {
 	return ""
 }
 
+func (x *LogConfig) GetVirtualHost() string {
+	if x != nil {
+		return x.VirtualHost
+	}
+	return ""
+}
+
+func (x *LogConfig) GetCheckpointOrigin() string {
+	if x != nil {
+		return x.CheckpointOrigin
+	}
+	return ""
+}
+
 func (x *LogConfig) GetOverrideHandlerPrefix() string {
 	if x != nil {
 		return x.OverrideHandlerPrefix
@@ -373,6 +445,20 @@ func (x *LogConfig) GetRejectUnexpired() bool {
 	return false
 }
 
+func (x *LogConfig) GetMaxNotBeforeFuture() *durationpb.Duration {
+	if x != nil {
+		return x.MaxNotBeforeFuture
+	}
+	return nil
+}
+
+func (x *LogConfig) GetClockSkew() *durationpb.Duration {
+	if x != nil {
+		return x.ClockSkew
+	}
+	return nil
+}
+
 func (x *LogConfig) GetExtKeyUsages() []string {
 	if x != nil {
 		return x.ExtKeyUsages
@@ -408,6 +494,20 @@ func (x *LogConfig) GetLogBackendName() string {
 	return ""
 }
 
+func (x *LogConfig) GetStandbyLogBackendName() string {
+	if x != nil {
+		return x.StandbyLogBackendName
+	}
+	return ""
+}
+
+func (x *LogConfig) GetStandbyAcceptsWrites() bool {
+	if x != nil {
+		return x.StandbyAcceptsWrites
+	}
+	return false
+}
+
 func (x *LogConfig) GetIsMirror() bool {
 	if x != nil {
 		return x.IsMirror
@@ -415,6 +515,13 @@ func (x *LogConfig) GetIsMirror() bool {
 	return false
 }
 
+func (x *LogConfig) GetMirrorSourceUri() string {
+	if x != nil {
+		return x.MirrorSourceUri
+	}
+	return ""
+}
+
 func (x *LogConfig) GetIsReadonly() bool {
 	if x != nil {
 		return x.IsReadonly
@@ -422,6 +529,20 @@ func (x *LogConfig) GetIsReadonly() bool {
 	return false
 }
 
+func (x *LogConfig) GetPrecertsOnly() bool {
+	if x != nil {
+		return x.PrecertsOnly
+	}
+	return false
+}
+
+func (x *LogConfig) GetCertsOnly() bool {
+	if x != nil {
+		return x.CertsOnly
+	}
+	return false
+}
+
 func (x *LogConfig) GetMaxMergeDelaySec() int32 {
 	if x != nil {
 		return x.MaxMergeDelaySec
@@ -450,6 +571,41 @@ func (x *LogConfig) GetRejectExtensions() []string {
 	return nil
 }
 
+func (x *LogConfig) GetMaxChainLength() int32 {
+	if x != nil {
+		return x.MaxChainLength
+	}
+	return 0
+}
+
+func (x *LogConfig) GetRequireServerAuthPath() bool {
+	if x != nil {
+		return x.RequireServerAuthPath
+	}
+	return false
+}
+
+func (x *LogConfig) GetRejectBridgedChains() bool {
+	if x != nil {
+		return x.RejectBridgedChains
+	}
+	return false
+}
+
+func (x *LogConfig) GetRejectExpiredIntermediates() bool {
+	if x != nil {
+		return x.RejectExpiredIntermediates
+	}
+	return false
+}
+
+func (x *LogConfig) GetSctExtensions() []byte {
+	if x != nil {
+		return x.SctExtensions
+	}
+	return nil
+}
+
 // LogMultiConfig wraps up a LogBackendSet and corresponding LogConfigSet so
 // that they can easily be parsed as a single proto.
 type LogMultiConfig struct {
@@ -511,6 +667,137 @@ func (x *LogMultiConfig) GetLogConfigs() *LogConfigSet {
 	return nil
 }
 
+// APIKeyConfig is the set of API keys which submitters can present to
+// add-chain and add-pre-chain, in order to be rate limited and charged quota
+// per key rather than per IP address.
+type APIKeyConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The API keys. Their names must all be distinct.
+	Keys []*APIKey `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+}
+
+func (x *APIKeyConfig) Reset() {
+	*x = APIKeyConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_ctfe_configpb_config_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *APIKeyConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*APIKeyConfig) ProtoMessage() {}
+
+func (x *APIKeyConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_ctfe_configpb_config_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use APIKeyConfig.ProtoReflect.Descriptor instead.
+func (*APIKeyConfig) Descriptor() ([]byte, []int) {
+	return file_trillian_ctfe_configpb_config_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *APIKeyConfig) GetKeys() []*APIKey {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+// APIKey describes a single submitter's API key.
+type APIKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name identifies the key holder in metrics, logs and Trillian quota. It
+	// must not be empty.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Hex-encoded SHA-256 hash of the key, so that the keys themselves need not
+	// be stored in the config file.
+	KeySha256 string `protobuf:"bytes,2,opt,name=key_sha256,json=keySha256,proto3" json:"key_sha256,omitempty"`
+	// Maximum number of submissions per second allowed with this key (0 for no
+	// limit).
+	RateLimit float64 `protobuf:"fixed64,3,opt,name=rate_limit,json=rateLimit,proto3" json:"rate_limit,omitempty"`
+	// Maximum number of submissions allowed in a burst above rate_limit (if 0,
+	// one submission).
+	Burst int32 `protobuf:"varint,4,opt,name=burst,proto3" json:"burst,omitempty"`
+}
+
+func (x *APIKey) Reset() {
+	*x = APIKey{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_ctfe_configpb_config_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *APIKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*APIKey) ProtoMessage() {}
+
+func (x *APIKey) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_ctfe_configpb_config_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use APIKey.ProtoReflect.Descriptor instead.
+func (*APIKey) Descriptor() ([]byte, []int) {
+	return file_trillian_ctfe_configpb_config_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *APIKey) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *APIKey) GetKeySha256() string {
+	if x != nil {
+		return x.KeySha256
+	}
+	return ""
+}
+
+func (x *APIKey) GetRateLimit() float64 {
+	if x != nil {
+		return x.RateLimit
+	}
+	return 0
+}
+
+func (x *APIKey) GetBurst() int32 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
 // SignedTreeHead represents the structure returned by the get-sth CT method.
 // See RFC6962 sections 3.5 and 4.3 for reference.
 // TODO(pavelkalinnikov): Find a better place for this type.
@@ -528,7 +815,7 @@ type SignedTreeHead struct {
 func (x *SignedTreeHead) Reset() {
 	*x = SignedTreeHead{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_trillian_ctfe_configpb_config_proto_msgTypes[5]
+		mi := &file_trillian_ctfe_configpb_config_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -541,7 +828,7 @@ func (x *SignedTreeHead) String() string {
 func (*SignedTreeHead) ProtoMessage() {}
 
 func (x *SignedTreeHead) ProtoReflect() protoreflect.Message {
-	mi := &file_trillian_ctfe_configpb_config_proto_msgTypes[5]
+	mi := &file_trillian_ctfe_configpb_config_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -554,7 +841,7 @@ func (x *SignedTreeHead) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SignedTreeHead.ProtoReflect.Descriptor instead.
 func (*SignedTreeHead) Descriptor() ([]byte, []int) {
-	return file_trillian_ctfe_configpb_config_proto_rawDescGZIP(), []int{5}
+	return file_trillian_ctfe_configpb_config_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *SignedTreeHead) GetTreeSize() int64 {
@@ -594,6 +881,8 @@ var file_trillian_ctfe_configpb_config_proto_rawDesc = []byte{
 	0x1a, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x2f, 0x6b, 0x65, 0x79, 0x73, 0x70, 0x62, 0x2f, 0x6b,
 	0x65, 0x79, 0x73, 0x70, 0x62, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x19, 0x67, 0x6f, 0x6f,
 	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x61, 0x6e, 0x79,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
 	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
 	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
 	0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x43, 0x0a, 0x0a, 0x4c, 0x6f, 0x67, 0x42, 0x61,
@@ -608,11 +897,16 @@ var file_trillian_ctfe_configpb_config_proto_rawDesc = []byte{
 	0x0c, 0x4c, 0x6f, 0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x53, 0x65, 0x74, 0x12, 0x2b, 0x0a,
 	0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e,
 	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x43, 0x6f, 0x6e, 0x66,
-	0x69, 0x67, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0xdd, 0x06, 0x0a, 0x09, 0x4c,
+	0x69, 0x67, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0x94, 0x0c, 0x0a, 0x09, 0x4c,
 	0x6f, 0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x5f,
 	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x64, 0x12,
 	0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x36, 0x0a, 0x17, 0x6f, 0x76, 0x65, 0x72, 0x72,
+	0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x21, 0x0a, 0x0c, 0x76, 0x69, 0x72, 0x74, 0x75,
+	0x61, 0x6c, 0x5f, 0x68, 0x6f, 0x73, 0x74, 0x18, 0x20, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x76,
+	0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x48, 0x6f, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x11, 0x63, 0x68,
+	0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x5f, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x18,
+	0x21, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x12, 0x36, 0x0a, 0x17, 0x6f, 0x76, 0x65, 0x72, 0x72,
 	0x69, 0x64, 0x65, 0x5f, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x72, 0x5f, 0x70, 0x72, 0x65, 0x66,
 	0x69, 0x78, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x15, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69,
 	0x64, 0x65, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x72, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12,
@@ -630,63 +924,112 @@ var file_trillian_ctfe_configpb_config_proto_rawDesc = []byte{
 	0x70, 0x69, 0x72, 0x65, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x5f,
 	0x75, 0x6e, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x64, 0x18, 0x11, 0x20, 0x01, 0x28, 0x08, 0x52,
 	0x0f, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x55, 0x6e, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x64,
-	0x12, 0x24, 0x0a, 0x0e, 0x65, 0x78, 0x74, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x75, 0x73, 0x61, 0x67,
-	0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x65, 0x78, 0x74, 0x4b, 0x65, 0x79,
-	0x55, 0x73, 0x61, 0x67, 0x65, 0x73, 0x12, 0x42, 0x0a, 0x0f, 0x6e, 0x6f, 0x74, 0x5f, 0x61, 0x66,
-	0x74, 0x65, 0x72, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0d, 0x6e, 0x6f, 0x74,
-	0x41, 0x66, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x42, 0x0a, 0x0f, 0x6e, 0x6f,
-	0x74, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x09, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
-	0x0d, 0x6e, 0x6f, 0x74, 0x41, 0x66, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x24,
-	0x0a, 0x0e, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x5f, 0x63, 0x61,
-	0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x4f, 0x6e,
-	0x6c, 0x79, 0x43, 0x61, 0x12, 0x28, 0x0a, 0x10, 0x6c, 0x6f, 0x67, 0x5f, 0x62, 0x61, 0x63, 0x6b,
-	0x65, 0x6e, 0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
-	0x6c, 0x6f, 0x67, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1b,
-	0x0a, 0x09, 0x69, 0x73, 0x5f, 0x6d, 0x69, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x0c, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x08, 0x69, 0x73, 0x4d, 0x69, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x69,
-	0x73, 0x5f, 0x72, 0x65, 0x61, 0x64, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x13, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x0a, 0x69, 0x73, 0x52, 0x65, 0x61, 0x64, 0x6f, 0x6e, 0x6c, 0x79, 0x12, 0x2d, 0x0a, 0x13,
-	0x6d, 0x61, 0x78, 0x5f, 0x6d, 0x65, 0x72, 0x67, 0x65, 0x5f, 0x64, 0x65, 0x6c, 0x61, 0x79, 0x5f,
-	0x73, 0x65, 0x63, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x6d, 0x61, 0x78, 0x4d, 0x65,
-	0x72, 0x67, 0x65, 0x44, 0x65, 0x6c, 0x61, 0x79, 0x53, 0x65, 0x63, 0x12, 0x37, 0x0a, 0x18, 0x65,
-	0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x6d, 0x65, 0x72, 0x67, 0x65, 0x5f, 0x64, 0x65,
-	0x6c, 0x61, 0x79, 0x5f, 0x73, 0x65, 0x63, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x05, 0x52, 0x15, 0x65,
-	0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x4d, 0x65, 0x72, 0x67, 0x65, 0x44, 0x65, 0x6c, 0x61,
-	0x79, 0x53, 0x65, 0x63, 0x12, 0x37, 0x0a, 0x0a, 0x66, 0x72, 0x6f, 0x7a, 0x65, 0x6e, 0x5f, 0x73,
-	0x74, 0x68, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
-	0x67, 0x70, 0x62, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x54, 0x72, 0x65, 0x65, 0x48, 0x65,
-	0x61, 0x64, 0x52, 0x09, 0x66, 0x72, 0x6f, 0x7a, 0x65, 0x6e, 0x53, 0x74, 0x68, 0x12, 0x2b, 0x0a,
-	0x11, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f,
-	0x6e, 0x73, 0x18, 0x12, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74,
-	0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x7e, 0x0a, 0x0e, 0x4c, 0x6f,
-	0x67, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x33, 0x0a, 0x08,
-	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17,
-	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x42, 0x61, 0x63,
-	0x6b, 0x65, 0x6e, 0x64, 0x53, 0x65, 0x74, 0x52, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
-	0x73, 0x12, 0x37, 0x0a, 0x0b, 0x6c, 0x6f, 0x67, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x73,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x70,
-	0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x53, 0x65, 0x74, 0x52, 0x0a,
-	0x6c, 0x6f, 0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x73, 0x22, 0xa5, 0x01, 0x0a, 0x0e, 0x53,
-	0x69, 0x67, 0x6e, 0x65, 0x64, 0x54, 0x72, 0x65, 0x65, 0x48, 0x65, 0x61, 0x64, 0x12, 0x1b, 0x0a,
-	0x09, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
-	0x52, 0x08, 0x74, 0x72, 0x65, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69,
-	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74,
-	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x28, 0x0a, 0x10, 0x73, 0x68, 0x61, 0x32,
-	0x35, 0x36, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x0c, 0x52, 0x0e, 0x73, 0x68, 0x61, 0x32, 0x35, 0x36, 0x52, 0x6f, 0x6f, 0x74, 0x48, 0x61,
-	0x73, 0x68, 0x12, 0x2e, 0x0a, 0x13, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x5f,
-	0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x11, 0x74, 0x72, 0x65, 0x65, 0x48, 0x65, 0x61, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75,
-	0x72, 0x65, 0x42, 0x46, 0x5a, 0x44, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
-	0x2f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x63, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63,
-	0x61, 0x74, 0x65, 0x2d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x63, 0x79,
-	0x2d, 0x67, 0x6f, 0x2f, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2f, 0x63, 0x74, 0x66,
-	0x65, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
+	0x12, 0x4c, 0x0a, 0x15, 0x6d, 0x61, 0x78, 0x5f, 0x6e, 0x6f, 0x74, 0x5f, 0x62, 0x65, 0x66, 0x6f,
+	0x72, 0x65, 0x5f, 0x66, 0x75, 0x74, 0x75, 0x72, 0x65, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x12, 0x6d, 0x61, 0x78, 0x4e,
+	0x6f, 0x74, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x46, 0x75, 0x74, 0x75, 0x72, 0x65, 0x12, 0x38,
+	0x0a, 0x0a, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x73, 0x6b, 0x65, 0x77, 0x18, 0x1d, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x63,
+	0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x6b, 0x65, 0x77, 0x12, 0x24, 0x0a, 0x0e, 0x65, 0x78, 0x74, 0x5f,
+	0x6b, 0x65, 0x79, 0x5f, 0x75, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0c, 0x65, 0x78, 0x74, 0x4b, 0x65, 0x79, 0x55, 0x73, 0x61, 0x67, 0x65, 0x73, 0x12, 0x42,
+	0x0a, 0x0f, 0x6e, 0x6f, 0x74, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x5f, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x0d, 0x6e, 0x6f, 0x74, 0x41, 0x66, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61,
+	0x72, 0x74, 0x12, 0x42, 0x0a, 0x0f, 0x6e, 0x6f, 0x74, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x5f,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0d, 0x6e, 0x6f, 0x74, 0x41, 0x66, 0x74, 0x65,
+	0x72, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x24, 0x0a, 0x0e, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74,
+	0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x5f, 0x63, 0x61, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c,
+	0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x4f, 0x6e, 0x6c, 0x79, 0x43, 0x61, 0x12, 0x28, 0x0a, 0x10,
+	0x6c, 0x6f, 0x67, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x6c, 0x6f, 0x67, 0x42, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x37, 0x0a, 0x18, 0x73, 0x74, 0x61, 0x6e, 0x64, 0x62,
+	0x79, 0x5f, 0x6c, 0x6f, 0x67, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x15, 0x73, 0x74, 0x61, 0x6e, 0x64, 0x62,
+	0x79, 0x4c, 0x6f, 0x67, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x12,
+	0x34, 0x0a, 0x16, 0x73, 0x74, 0x61, 0x6e, 0x64, 0x62, 0x79, 0x5f, 0x61, 0x63, 0x63, 0x65, 0x70,
+	0x74, 0x73, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x65, 0x73, 0x18, 0x1f, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x14, 0x73, 0x74, 0x61, 0x6e, 0x64, 0x62, 0x79, 0x41, 0x63, 0x63, 0x65, 0x70, 0x74, 0x73, 0x57,
+	0x72, 0x69, 0x74, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x73, 0x5f, 0x6d, 0x69, 0x72, 0x72,
+	0x6f, 0x72, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x73, 0x4d, 0x69, 0x72, 0x72,
+	0x6f, 0x72, 0x12, 0x2a, 0x0a, 0x11, 0x6d, 0x69, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x5f, 0x75, 0x72, 0x69, 0x18, 0x15, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x6d,
+	0x69, 0x72, 0x72, 0x6f, 0x72, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x55, 0x72, 0x69, 0x12, 0x1f,
+	0x0a, 0x0b, 0x69, 0x73, 0x5f, 0x72, 0x65, 0x61, 0x64, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x13, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x73, 0x52, 0x65, 0x61, 0x64, 0x6f, 0x6e, 0x6c, 0x79, 0x12,
+	0x23, 0x0a, 0x0d, 0x70, 0x72, 0x65, 0x63, 0x65, 0x72, 0x74, 0x73, 0x5f, 0x6f, 0x6e, 0x6c, 0x79,
+	0x18, 0x1a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x70, 0x72, 0x65, 0x63, 0x65, 0x72, 0x74, 0x73,
+	0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x65, 0x72, 0x74, 0x73, 0x5f, 0x6f, 0x6e,
+	0x6c, 0x79, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x65, 0x72, 0x74, 0x73, 0x4f,
+	0x6e, 0x6c, 0x79, 0x12, 0x2d, 0x0a, 0x13, 0x6d, 0x61, 0x78, 0x5f, 0x6d, 0x65, 0x72, 0x67, 0x65,
+	0x5f, 0x64, 0x65, 0x6c, 0x61, 0x79, 0x5f, 0x73, 0x65, 0x63, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x10, 0x6d, 0x61, 0x78, 0x4d, 0x65, 0x72, 0x67, 0x65, 0x44, 0x65, 0x6c, 0x61, 0x79, 0x53,
+	0x65, 0x63, 0x12, 0x37, 0x0a, 0x18, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x6d,
+	0x65, 0x72, 0x67, 0x65, 0x5f, 0x64, 0x65, 0x6c, 0x61, 0x79, 0x5f, 0x73, 0x65, 0x63, 0x18, 0x0f,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x15, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x4d, 0x65,
+	0x72, 0x67, 0x65, 0x44, 0x65, 0x6c, 0x61, 0x79, 0x53, 0x65, 0x63, 0x12, 0x37, 0x0a, 0x0a, 0x66,
+	0x72, 0x6f, 0x7a, 0x65, 0x6e, 0x5f, 0x73, 0x74, 0x68, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x18, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x70, 0x62, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65,
+	0x64, 0x54, 0x72, 0x65, 0x65, 0x48, 0x65, 0x61, 0x64, 0x52, 0x09, 0x66, 0x72, 0x6f, 0x7a, 0x65,
+	0x6e, 0x53, 0x74, 0x68, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x65,
+	0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x12, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x10, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x12, 0x28, 0x0a, 0x10, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x5f, 0x6c,
+	0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x16, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x6d, 0x61, 0x78,
+	0x43, 0x68, 0x61, 0x69, 0x6e, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x37, 0x0a, 0x18, 0x72,
+	0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x61, 0x75,
+	0x74, 0x68, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x17, 0x20, 0x01, 0x28, 0x08, 0x52, 0x15, 0x72,
+	0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x41, 0x75, 0x74, 0x68,
+	0x50, 0x61, 0x74, 0x68, 0x12, 0x32, 0x0a, 0x15, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x62,
+	0x72, 0x69, 0x64, 0x67, 0x65, 0x64, 0x5f, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x73, 0x18, 0x18, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x13, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x42, 0x72, 0x69, 0x64, 0x67,
+	0x65, 0x64, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x73, 0x12, 0x40, 0x0a, 0x1c, 0x72, 0x65, 0x6a, 0x65,
+	0x63, 0x74, 0x5f, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x64, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65, 0x73, 0x18, 0x19, 0x20, 0x01, 0x28, 0x08, 0x52, 0x1a,
+	0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x45, 0x78, 0x70, 0x69, 0x72, 0x65, 0x64, 0x49, 0x6e, 0x74,
+	0x65, 0x72, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x74, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x63,
+	0x74, 0x5f, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x14, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x0d, 0x73, 0x63, 0x74, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x22, 0x7e, 0x0a, 0x0e, 0x4c, 0x6f, 0x67, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x43, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x12, 0x33, 0x0a, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x70, 0x62,
+	0x2e, 0x4c, 0x6f, 0x67, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x53, 0x65, 0x74, 0x52, 0x08,
+	0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x12, 0x37, 0x0a, 0x0b, 0x6c, 0x6f, 0x67, 0x5f,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x53, 0x65, 0x74, 0x52, 0x0a, 0x6c, 0x6f, 0x67, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x73, 0x22, 0x34, 0x0a, 0x0c, 0x41, 0x50, 0x49, 0x4b, 0x65, 0x79, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x12, 0x24, 0x0a, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x70, 0x62, 0x2e, 0x41, 0x50, 0x49, 0x4b, 0x65,
+	0x79, 0x52, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x22, 0x70, 0x0a, 0x06, 0x41, 0x50, 0x49, 0x4b, 0x65,
+	0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6b, 0x65, 0x79, 0x5f, 0x73, 0x68, 0x61,
+	0x32, 0x35, 0x36, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6b, 0x65, 0x79, 0x53, 0x68,
+	0x61, 0x32, 0x35, 0x36, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x61, 0x74, 0x65, 0x5f, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x72, 0x61, 0x74, 0x65, 0x4c, 0x69,
+	0x6d, 0x69, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x75, 0x72, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x05, 0x62, 0x75, 0x72, 0x73, 0x74, 0x22, 0xa5, 0x01, 0x0a, 0x0e, 0x53, 0x69,
+	0x67, 0x6e, 0x65, 0x64, 0x54, 0x72, 0x65, 0x65, 0x48, 0x65, 0x61, 0x64, 0x12, 0x1b, 0x0a, 0x09,
+	0x74, 0x72, 0x65, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x08, 0x74, 0x72, 0x65, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x28, 0x0a, 0x10, 0x73, 0x68, 0x61, 0x32, 0x35,
+	0x36, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0e, 0x73, 0x68, 0x61, 0x32, 0x35, 0x36, 0x52, 0x6f, 0x6f, 0x74, 0x48, 0x61, 0x73,
+	0x68, 0x12, 0x2e, 0x0a, 0x13, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x5f, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x11,
+	0x74, 0x72, 0x65, 0x65, 0x48, 0x65, 0x61, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x42, 0x4c, 0x5a, 0x4a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x52, 0x61, 0x72, 0x69, 0x6d, 0x6f, 0x56, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2f, 0x63, 0x65, 0x72,
+	0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x2d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x61,
+	0x72, 0x65, 0x6e, 0x63, 0x79, 0x2d, 0x67, 0x6f, 0x2f, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61,
+	0x6e, 0x2f, 0x63, 0x74, 0x66, 0x65, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x70, 0x62, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -701,33 +1044,39 @@ func file_trillian_ctfe_configpb_config_proto_rawDescGZIP() []byte {
 	return file_trillian_ctfe_configpb_config_proto_rawDescData
 }
 
-var file_trillian_ctfe_configpb_config_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_trillian_ctfe_configpb_config_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_trillian_ctfe_configpb_config_proto_goTypes = []interface{}{
 	(*LogBackend)(nil),            // 0: configpb.LogBackend
 	(*LogBackendSet)(nil),         // 1: configpb.LogBackendSet
 	(*LogConfigSet)(nil),          // 2: configpb.LogConfigSet
 	(*LogConfig)(nil),             // 3: configpb.LogConfig
 	(*LogMultiConfig)(nil),        // 4: configpb.LogMultiConfig
-	(*SignedTreeHead)(nil),        // 5: configpb.SignedTreeHead
-	(*anypb.Any)(nil),             // 6: google.protobuf.Any
-	(*keyspb.PublicKey)(nil),      // 7: keyspb.PublicKey
-	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+	(*APIKeyConfig)(nil),          // 5: configpb.APIKeyConfig
+	(*APIKey)(nil),                // 6: configpb.APIKey
+	(*SignedTreeHead)(nil),        // 7: configpb.SignedTreeHead
+	(*anypb.Any)(nil),             // 8: google.protobuf.Any
+	(*keyspb.PublicKey)(nil),      // 9: keyspb.PublicKey
+	(*durationpb.Duration)(nil),   // 10: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
 }
 var file_trillian_ctfe_configpb_config_proto_depIdxs = []int32{
-	0, // 0: configpb.LogBackendSet.backend:type_name -> configpb.LogBackend
-	3, // 1: configpb.LogConfigSet.config:type_name -> configpb.LogConfig
-	6, // 2: configpb.LogConfig.private_key:type_name -> google.protobuf.Any
-	7, // 3: configpb.LogConfig.public_key:type_name -> keyspb.PublicKey
-	8, // 4: configpb.LogConfig.not_after_start:type_name -> google.protobuf.Timestamp
-	8, // 5: configpb.LogConfig.not_after_limit:type_name -> google.protobuf.Timestamp
-	5, // 6: configpb.LogConfig.frozen_sth:type_name -> configpb.SignedTreeHead
-	1, // 7: configpb.LogMultiConfig.backends:type_name -> configpb.LogBackendSet
-	2, // 8: configpb.LogMultiConfig.log_configs:type_name -> configpb.LogConfigSet
-	9, // [9:9] is the sub-list for method output_type
-	9, // [9:9] is the sub-list for method input_type
-	9, // [9:9] is the sub-list for extension type_name
-	9, // [9:9] is the sub-list for extension extendee
-	0, // [0:9] is the sub-list for field type_name
+	0,  // 0: configpb.LogBackendSet.backend:type_name -> configpb.LogBackend
+	3,  // 1: configpb.LogConfigSet.config:type_name -> configpb.LogConfig
+	8,  // 2: configpb.LogConfig.private_key:type_name -> google.protobuf.Any
+	9,  // 3: configpb.LogConfig.public_key:type_name -> keyspb.PublicKey
+	10, // 4: configpb.LogConfig.max_not_before_future:type_name -> google.protobuf.Duration
+	10, // 5: configpb.LogConfig.clock_skew:type_name -> google.protobuf.Duration
+	11, // 6: configpb.LogConfig.not_after_start:type_name -> google.protobuf.Timestamp
+	11, // 7: configpb.LogConfig.not_after_limit:type_name -> google.protobuf.Timestamp
+	7,  // 8: configpb.LogConfig.frozen_sth:type_name -> configpb.SignedTreeHead
+	1,  // 9: configpb.LogMultiConfig.backends:type_name -> configpb.LogBackendSet
+	2,  // 10: configpb.LogMultiConfig.log_configs:type_name -> configpb.LogConfigSet
+	6,  // 11: configpb.APIKeyConfig.keys:type_name -> configpb.APIKey
+	12, // [12:12] is the sub-list for method output_type
+	12, // [12:12] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_trillian_ctfe_configpb_config_proto_init() }
@@ -797,6 +1146,30 @@ func file_trillian_ctfe_configpb_config_proto_init() {
 			}
 		}
 		file_trillian_ctfe_configpb_config_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*APIKeyConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_ctfe_configpb_config_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*APIKey); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_ctfe_configpb_config_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SignedTreeHead); i {
 			case 0:
 				return &v.state
@@ -815,7 +1188,7 @@ func file_trillian_ctfe_configpb_config_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_trillian_ctfe_configpb_config_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// unixEndpointPrefix marks an endpoint as the path of a Unix domain socket.
+	unixEndpointPrefix = "unix:"
+	// systemdEndpointPrefix marks an endpoint as a socket passed in by systemd
+	// socket activation. It is followed by the index of the socket amongst
+	// those passed, or by its FileDescriptorName; on its own it means the
+	// first socket.
+	systemdEndpointPrefix = "systemd:"
+	// listenFDsStart is the first file descriptor used for socket activation.
+	listenFDsStart = 3
+)
+
+// listen returns a listener for the given endpoint, which is one of:
+//   - host:port, to listen on a TCP port;
+//   - unix:/path/to.sock, to listen on a Unix domain socket;
+//   - systemd:[index|name], to use a socket passed in by systemd.
+func listen(endpoint string) (net.Listener, error) {
+	switch {
+	case strings.HasPrefix(endpoint, unixEndpointPrefix):
+		path := strings.TrimPrefix(endpoint, unixEndpointPrefix)
+		// Remove any socket left behind by a previous instance, but don't
+		// clobber anything else.
+		if fi, err := os.Stat(path); err == nil && fi.Mode()&fs.ModeSocket != 0 {
+			if err := os.Remove(path); err != nil {
+				return nil, fmt.Errorf("failed to remove stale socket %q: %v", path, err)
+			}
+		}
+		return net.Listen("unix", path)
+	case strings.HasPrefix(endpoint, systemdEndpointPrefix):
+		return systemdListener(strings.TrimPrefix(endpoint, systemdEndpointPrefix))
+	default:
+		return net.Listen("tcp", endpoint)
+	}
+}
+
+// isTCPEndpoint reports whether the endpoint is a TCP host:port, which other
+// hosts can dial, rather than a Unix domain or systemd socket.
+func isTCPEndpoint(endpoint string) bool {
+	return !strings.HasPrefix(endpoint, unixEndpointPrefix) && !strings.HasPrefix(endpoint, systemdEndpointPrefix)
+}
+
+// systemdListener returns one of the listening sockets passed to this process
+// by systemd, as described in sd_listen_fds(3).
+func systemdListener(which string) (net.Listener, error) {
+	fd, name, err := systemdSocket(which, os.Getenv, os.Getpid())
+	if err != nil {
+		return nil, err
+	}
+	f := os.NewFile(uintptr(fd), name)
+	defer f.Close()
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("systemd socket %q is not a listening socket: %v", name, err)
+	}
+	klog.Infof("Using systemd socket %s: %v", name, l.Addr())
+	return l, nil
+}
+
+// systemdSocket returns the file descriptor and name of the socket passed by
+// systemd which is selected by which, according to the environment given by
+// getenv of the process with the given pid.
+func systemdSocket(which string, getenv func(string) string, pid int) (int, string, error) {
+	if p, err := strconv.Atoi(getenv("LISTEN_PID")); err != nil || p != pid {
+		return 0, "", errors.New("no sockets passed by systemd: LISTEN_PID is not set to our pid")
+	}
+	n, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return 0, "", errors.New("no sockets passed by systemd: LISTEN_FDS is not set")
+	}
+	names := strings.Split(getenv("LISTEN_FDNAMES"), ":")
+
+	idx := 0
+	if which != "" {
+		if idx, err = strconv.Atoi(which); err != nil {
+			idx = -1
+			for i, name := range names {
+				if name == which {
+					idx = i
+					break
+				}
+			}
+		}
+	}
+	if idx < 0 || idx >= n {
+		return 0, "", fmt.Errorf("systemd socket %q not found amongst %d passed socket(s)", which, n)
+	}
+
+	name := fmt.Sprintf("LISTEN_FD_%d", listenFDsStart+idx)
+	if idx < len(names) && names[idx] != "" {
+		name = names[idx]
+	}
+	return listenFDsStart + idx, name, nil
+}
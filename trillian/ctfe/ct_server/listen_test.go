@@ -0,0 +1,157 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSystemdSocket(t *testing.T) {
+	const pid = 1234
+	for _, test := range []struct {
+		desc     string
+		env      map[string]string
+		which    string
+		wantFD   int
+		wantName string
+		wantErr  bool
+	}{
+		{
+			desc:    "not-activated",
+			env:     map[string]string{},
+			wantErr: true,
+		},
+		{
+			desc:    "other-pid",
+			env:     map[string]string{"LISTEN_PID": "1", "LISTEN_FDS": "1"},
+			wantErr: true,
+		},
+		{
+			desc:    "no-fds",
+			env:     map[string]string{"LISTEN_PID": "1234", "LISTEN_FDS": "0"},
+			wantErr: true,
+		},
+		{
+			desc:     "default",
+			env:      map[string]string{"LISTEN_PID": "1234", "LISTEN_FDS": "1"},
+			wantFD:   3,
+			wantName: "LISTEN_FD_3",
+		},
+		{
+			desc:     "index",
+			env:      map[string]string{"LISTEN_PID": "1234", "LISTEN_FDS": "2", "LISTEN_FDNAMES": "http:metrics"},
+			which:    "1",
+			wantFD:   4,
+			wantName: "metrics",
+		},
+		{
+			desc:    "index-out-of-range",
+			env:     map[string]string{"LISTEN_PID": "1234", "LISTEN_FDS": "2"},
+			which:   "2",
+			wantErr: true,
+		},
+		{
+			desc:     "name",
+			env:      map[string]string{"LISTEN_PID": "1234", "LISTEN_FDS": "3", "LISTEN_FDNAMES": "http:metrics:write"},
+			which:    "write",
+			wantFD:   5,
+			wantName: "write",
+		},
+		{
+			desc:    "unknown-name",
+			env:     map[string]string{"LISTEN_PID": "1234", "LISTEN_FDS": "2", "LISTEN_FDNAMES": "http:metrics"},
+			which:   "write",
+			wantErr: true,
+		},
+		{
+			// More names than sockets must not select a missing socket.
+			desc:    "name-beyond-fds",
+			env:     map[string]string{"LISTEN_PID": "1234", "LISTEN_FDS": "1", "LISTEN_FDNAMES": "http:metrics"},
+			which:   "metrics",
+			wantErr: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			getenv := func(key string) string { return test.env[key] }
+			fd, name, err := systemdSocket(test.which, getenv, pid)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("systemdSocket(%q)=_,_,%v, want err=%t", test.which, err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if fd != test.wantFD || name != test.wantName {
+				t.Errorf("systemdSocket(%q)=%d,%q, want %d,%q", test.which, fd, name, test.wantFD, test.wantName)
+			}
+		})
+	}
+}
+
+func TestIsTCPEndpoint(t *testing.T) {
+	for ep, want := range map[string]bool{
+		"localhost:6962":    true,
+		"[::]:80":           true,
+		"unix:/run/ct.sock": false,
+		"systemd:":          false,
+		"systemd:http":      false,
+	} {
+		if got := isTCPEndpoint(ep); got != want {
+			t.Errorf("isTCPEndpoint(%q)=%t, want %t", ep, got, want)
+		}
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("stale-socket", func(t *testing.T) {
+		path := filepath.Join(dir, "stale.sock")
+		// Leave a socket behind, as a crashed instance would.
+		old, err := net.Listen("unix", path)
+		if err != nil {
+			t.Fatalf("net.Listen()=%v", err)
+		}
+		old.(*net.UnixListener).SetUnlinkOnClose(false)
+		old.Close()
+
+		l, err := listen(unixEndpointPrefix + path)
+		if err != nil {
+			t.Fatalf("listen()=%v, want nil", err)
+		}
+		defer l.Close()
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatalf("net.Dial()=%v", err)
+		}
+		conn.Close()
+	})
+
+	t.Run("not-a-socket", func(t *testing.T) {
+		path := filepath.Join(dir, "file")
+		if err := os.WriteFile(path, []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if l, err := listen(unixEndpointPrefix + path); err == nil {
+			l.Close()
+			t.Fatal("listen()=nil, want error for existing file")
+		}
+		if data, err := os.ReadFile(path); err != nil || string(data) != "data" {
+			t.Errorf("file was clobbered: %q, %v", data, err)
+		}
+	})
+}
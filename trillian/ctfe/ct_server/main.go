@@ -21,18 +21,33 @@ import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"flag"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
+	"github.com/RarimoVoting/certificate-transparency-go/gossip/pollination"
+	"github.com/RarimoVoting/certificate-transparency-go/loglist3"
+	"github.com/RarimoVoting/certificate-transparency-go/schedule"
 	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe"
 	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/configpb"
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/ctapipb"
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/migrillian/core"
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/util"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
 	"github.com/google/trillian"
 	"github.com/google/trillian/crypto/keys"
 	"github.com/google/trillian/crypto/keys/der"
@@ -40,13 +55,15 @@ import (
 	"github.com/google/trillian/crypto/keys/pkcs11"
 	"github.com/google/trillian/crypto/keyspb"
 	"github.com/google/trillian/monitoring/opencensus"
-	"github.com/google/trillian/monitoring/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
 	"github.com/tomasen/realip"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/client/v3/naming/endpoints"
+	"go.opencensus.io/plugin/ochttp"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/health" // Register client-side health checking.
 	"google.golang.org/grpc/resolver"
 	"google.golang.org/grpc/resolver/manual"
 	"google.golang.org/protobuf/proto"
@@ -55,24 +72,65 @@ import (
 
 // Global flags that affect all log instances.
 var (
-	httpEndpoint       = flag.String("http_endpoint", "localhost:6962", "Endpoint for HTTP (host:port)")
-	metricsEndpoint    = flag.String("metrics_endpoint", "", "Endpoint for serving metrics; if left empty, metrics will be visible on --http_endpoint")
+	httpEndpoint       = flag.String("http_endpoint", "localhost:6962", "Comma-separated list of endpoints for HTTP, each one of: host:port, unix:/path/to.sock for a Unix domain socket, or systemd:[index|name] for a socket passed by systemd socket activation")
+	httpTimeout        = flag.Duration("http_timeout", 0, "Timeout for reading requests from and writing responses to --http_endpoint (0 for no timeout)")
+	httpRateLimit      = flag.Float64("http_rate_limit", 0, "Maximum number of requests per second served on --http_endpoint (0 for no limit)")
+	writeEndpoint      = flag.String("http_write_endpoint", "", "If set, serve add-chain and add-pre-chain on these endpoints (same format as --http_endpoint) rather than on --http_endpoint")
+	writeTimeout       = flag.Duration("http_write_timeout", 0, "Timeout for reading requests from and writing responses to --http_write_endpoint (0 for no timeout)")
+	writeRateLimit     = flag.Float64("http_write_rate_limit", 0, "Maximum number of requests per second served on --http_write_endpoint (0 for no limit)")
+	metricsEndpoint    = flag.String("metrics_endpoint", "", "Endpoint for serving metrics, in the same format as a single --http_endpoint; if left empty, metrics will be visible on --http_endpoint, and announced in etcd at its first endpoint")
 	rpcBackend         = flag.String("log_rpc_server", "", "Backend specification; comma-separated list or etcd service name (if --etcd_servers specified). If unset backends are specified in config (as a LogMultiConfig proto)")
+	backendPoolSize    = flag.Int("backend_pool_size", 1, "Number of gRPC connections to open to each backend")
+	backendHealthCheck = flag.Bool("backend_health_check", false, "If true, use gRPC health checking to route requests away from unhealthy backend instances")
+	breakerThreshold   = flag.Int("backend_breaker_threshold", 0, "Number of consecutive backend RPC failures after which requests to that backend fail fast with a 503 (0 to disable)")
+	breakerCooldown    = flag.Duration("backend_breaker_cooldown", time.Second*30, "How long requests fail fast once the backend circuit breaker has opened")
+	readRetries        = flag.Int("backend_read_attempts", 1, "Maximum number of attempts for idempotent backend read RPCs (1 disables retries)")
+	readRetryBackoff   = flag.Duration("backend_read_backoff", time.Millisecond*100, "Initial maximum jittered backoff between backend read RPC attempts")
+	readHedgeDelay     = flag.Duration("backend_read_hedge_delay", 0, "If non-zero, send a hedged copy of a backend read RPC that has not completed after this delay")
+	mirrorBatchSize    = flag.Int("mirror_batch_size", 1000, "Number of entries to request per get-entries call when fetching a mirror's source log")
+	mirrorFetchers     = flag.Int("mirror_fetchers", 2, "Number of concurrent get-entries fetchers (and Trillian submitters) per mirror with mirror_source_uri set")
+	mirrorFetchTimeout = flag.Duration("mirror_fetch_timeout", time.Second*30, "Timeout for HTTP requests to a mirror's source log")
+	mirrorRestartDelay = flag.Duration("mirror_restart_delay", time.Second*30, "Maximum random delay before restarting mirroring of a source log after an error")
+	failoverThreshold  = flag.Duration("backend_failover_threshold", time.Second*30, "How long a log's primary backend must fail continuously before RPCs fail over to its standby backend, if it has one")
+	coalesceQueueLeaf  = flag.Bool("coalesce_queue_leaf", false, "If true, concurrent add-chain/add-pre-chain requests for the same leaf share a single backend QueueLeaf RPC")
+	maintenanceMode    = flag.Bool("maintenance_mode", false, "If true, start in maintenance mode, where add-chain and add-pre-chain fail with a 503; SIGUSR1 enters and SIGUSR2 leaves maintenance mode at runtime")
+	maintenanceRetry   = flag.Duration("maintenance_retry_after", time.Minute*5, "Retry-After duration returned for writes rejected in maintenance mode")
 	rpcDeadline        = flag.Duration("rpc_deadline", time.Second*10, "Deadline for backend RPC requests")
 	getSTHInterval     = flag.Duration("get_sth_interval", time.Second*180, "Interval between internal get-sth operations (0 to disable)")
+	sthStorageFile     = flag.String("sth_storage_file", "", "If set, JSON-lines file recording the STHs fetched by internal get-sth operations and the source log STHs of mirrors, so that they survive restarts")
 	logConfig          = flag.String("log_config", "", "File holding log config in text proto format")
 	maxGetEntries      = flag.Int64("max_get_entries", 0, "Max number of entries we allow in a get-entries request (0=>use default 1000)")
 	etcdServers        = flag.String("etcd_servers", "", "A comma-separated list of etcd servers")
 	etcdHTTPService    = flag.String("etcd_http_service", "trillian-ctfe-http", "Service name to announce our HTTP endpoint under")
 	etcdMetricsService = flag.String("etcd_metrics_service", "trillian-ctfe-metrics-http", "Service name to announce our HTTP metrics endpoint under")
+	etcdCAFile         = flag.String("etcd_ca_file", "", "File holding PEM CA certificate(s) used to verify etcd servers; enables TLS for etcd if set")
+	etcdCertFile       = flag.String("etcd_cert_file", "", "File holding PEM client certificate to present to etcd servers (requires --etcd_key_file)")
+	etcdKeyFile        = flag.String("etcd_key_file", "", "File holding PEM private key for --etcd_cert_file")
+	etcdUsername       = flag.String("etcd_username", "", "Username for etcd authentication")
+	etcdPasswordFile   = flag.String("etcd_password_file", "", "File holding the password for etcd authentication; if unset, the password is taken from the "+etcdPasswordEnv+" environment variable")
+	etcdHealthInterval = flag.Duration("etcd_health_interval", time.Second*30, "Interval between etcd endpoint health checks (0 to disable)")
+	chainDiagnostics   = flag.Bool("chain_diagnostics", false, "If true, responses to rejected add-chain/add-pre-chain requests have a JSON body giving the cause of the rejection and the offending certificate")
 	maskInternalErrors = flag.Bool("mask_internal_errors", false, "Don't return error strings with Internal Server Error HTTP responses")
 	tracing            = flag.Bool("tracing", false, "If true opencensus Stackdriver tracing will be enabled. See https://opencensus.io/.")
 	tracingProjectID   = flag.String("tracing_project_id", "", "project ID to pass to stackdriver. Can be empty for GCP, consult docs for other platforms.")
 	tracingPercent     = flag.Int("tracing_percent", 0, "Percent of requests to be traced. Zero is a special case to use the DefaultSampler")
 	quotaRemote        = flag.Bool("quota_remote", true, "Enable requesting of quota for IP address sending incoming requests")
+	logListPath        = flag.String("log_list_path", "/logs", "URL path on which to serve JSON metadata for all the configured logs (empty to disable)")
+	anchorRPCURL       = flag.String("sth_anchor_rpc_url", "", "If set, Ethereum JSON-RPC endpoint through which to periodically anchor each log's STH in --sth_anchor_contract")
+	anchorFrom         = flag.String("sth_anchor_from", "", "Hex address of the node account which sends STH anchoring transactions")
+	anchorContract     = flag.String("sth_anchor_contract", "", "Hex address of the contract whose "+ctfe.EthereumAnchorMethod+" method anchors STHs")
+	anchorInterval     = flag.Duration("sth_anchor_interval", time.Hour, "Interval between attempts to anchor each log's latest STH")
+	anchorRecord       = flag.String("sth_anchor_record", "", "If set, file to append a JSON record of each anchored STH and its transaction hash to; the last STH recorded for each log is not anchored again after a restart")
+	grpcEndpoint       = flag.String("grpc_endpoint", "", "If set, host:port on which to serve the CTLog gRPC API for the configured logs")
+	dnsEndpoint        = flag.String("dns_endpoint", "", "If set, UDP host:port on which to answer CT-over-DNS queries for the configured logs")
+	dnsDomain          = flag.String("dns_domain", "", "Domain under which logs are served by --dns_endpoint; each log is served in the zone <prefix>.<dns_domain>")
+	apiKeysFile        = flag.String("api_keys", "", "If set, file holding an APIKeyConfig in text proto format; submitters presenting one of its keys in an \"Authorization: Bearer\" header are rate limited and charged quota per key")
+	requireAPIKey      = flag.Bool("require_api_key", false, "If true, reject add-chain and add-pre-chain requests without an API key from --api_keys")
 	quotaIntermediate  = flag.Bool("quota_intermediate", true, "Enable requesting of quota for intermediate certificates in submitted chains")
 	handlerPrefix      = flag.String("handler_prefix", "", "If set e.g. to '/logs' will prefix all handlers that don't define a custom prefix")
 	pkcs11ModulePath   = flag.String("pkcs11_module_path", "", "Path to the PKCS#11 module to use for keys that use the PKCS#11 interface")
+	sthPollination     = flag.Bool("sth_pollination", false, "If true, serve STH pollination on "+pollination.PollinationPath+" for the configured logs and those of --sth_pollination_log_list")
+	pollinationLogList = flag.String("sth_pollination_log_list", "", "If set, file or URL of a JSON log list whose logs are also accepted by --sth_pollination")
 )
 
 const unknownRemoteUser = "UNKNOWN_REMOTE"
@@ -123,19 +181,44 @@ func main() {
 	klog.CopyStandardLogTo("WARNING")
 	klog.Info("**** CT HTTP Server Starting ****")
 
-	metricsAt := *metricsEndpoint
-	if metricsAt == "" {
-		metricsAt = *httpEndpoint
+	if err := setUpMetrics(ctx); err != nil {
+		klog.Exitf("Failed to set up metrics: %v", err)
 	}
 
+	httpEndpoints := splitEndpoints(*httpEndpoint)
+	if len(httpEndpoints) == 0 {
+		klog.Exit("No HTTP endpoints specified in --http_endpoint")
+	}
+	metricsAt, sharedMetrics := metricsEndpointFor(*metricsEndpoint, httpEndpoints)
+
 	dialOpts := []grpc.DialOption{grpc.WithInsecure()}
+	serviceConfig := `{"loadBalancingConfig": [{"round_robin":{}}]}`
+	if *backendHealthCheck {
+		serviceConfig = ctfe.BackendPoolHealthCheckConfig
+	}
 	if len(*etcdServers) > 0 {
 		// Use etcd to provide endpoint resolution.
-		cfg := clientv3.Config{Endpoints: strings.Split(*etcdServers, ","), DialTimeout: 5 * time.Second}
+		password, err := etcdPassword(*etcdPasswordFile, os.Getenv)
+		if err != nil {
+			klog.Exitf("Failed to get etcd password: %v", err)
+		}
+		cfg := clientv3.Config{
+			Endpoints:   strings.Split(*etcdServers, ","),
+			DialTimeout: 5 * time.Second,
+			Username:    *etcdUsername,
+			Password:    password,
+		}
+		cfg.TLS, err = etcdTLSConfig(*etcdCAFile, *etcdCertFile, *etcdKeyFile)
+		if err != nil {
+			klog.Exitf("Failed to set up etcd TLS: %v", err)
+		}
 		client, err := clientv3.New(cfg)
 		if err != nil {
 			klog.Exitf("Failed to connect to etcd at %v: %v", *etcdServers, err)
 		}
+		if *etcdHealthInterval > 0 {
+			go monitorEtcdEndpoints(ctx, client, *etcdHealthInterval)
+		}
 
 		httpManager, err := endpoints.NewManager(client, *etcdHTTPService)
 		if err != nil {
@@ -146,28 +229,49 @@ func main() {
 			klog.Exitf("Failed to create etcd metrics manager: %v", err)
 		}
 
-		etcdHTTPKey := fmt.Sprintf("%s/%s", *etcdHTTPService, *httpEndpoint)
-		klog.Infof("Announcing our presence at %v with %+v", etcdHTTPKey, *httpEndpoint)
-		if err := httpManager.AddEndpoint(ctx, etcdHTTPKey, endpoints.Endpoint{Addr: *httpEndpoint}); err != nil {
-			klog.Exitf("AddEndpoint(): %v", err)
+		var etcdHTTPKeys []string
+		for _, ep := range httpEndpoints {
+			if !isTCPEndpoint(ep) {
+				klog.Infof("Not announcing non-TCP endpoint %s in etcd", ep)
+				continue
+			}
+			etcdHTTPKey := fmt.Sprintf("%s/%s", *etcdHTTPService, ep)
+			klog.Infof("Announcing our presence at %v with %+v", etcdHTTPKey, ep)
+			if err := httpManager.AddEndpoint(ctx, etcdHTTPKey, endpoints.Endpoint{Addr: ep}); err != nil {
+				klog.Exitf("AddEndpoint(): %v", err)
+			}
+			etcdHTTPKeys = append(etcdHTTPKeys, etcdHTTPKey)
 		}
 
 		etcdMetricsKey := fmt.Sprintf("%s/%s", *etcdMetricsService, metricsAt)
-		klog.Infof("Announcing our presence in %v with %+v", *etcdMetricsService, metricsAt)
-		if err := metricsManager.AddEndpoint(ctx, etcdMetricsKey, endpoints.Endpoint{Addr: metricsAt}); err != nil {
-			klog.Exitf("AddEndpoint(): %v", err)
+		announceMetrics := isTCPEndpoint(metricsAt)
+		if announceMetrics {
+			klog.Infof("Announcing our presence in %v with %+v", *etcdMetricsService, metricsAt)
+			if err := metricsManager.AddEndpoint(ctx, etcdMetricsKey, endpoints.Endpoint{Addr: metricsAt}); err != nil {
+				klog.Exitf("AddEndpoint(): %v", err)
+			}
+		} else {
+			klog.Infof("Not announcing non-TCP metrics endpoint %s in etcd", metricsAt)
 		}
 
 		defer func() {
-			klog.Infof("Removing our presence in %v", etcdHTTPKey)
-			if err := httpManager.DeleteEndpoint(ctx, etcdHTTPKey); err != nil {
-				klog.Errorf("DeleteEndpoint(): %v", err)
+			for _, etcdHTTPKey := range etcdHTTPKeys {
+				klog.Infof("Removing our presence in %v", etcdHTTPKey)
+				if err := httpManager.DeleteEndpoint(ctx, etcdHTTPKey); err != nil {
+					klog.Errorf("DeleteEndpoint(): %v", err)
+				}
+			}
+			if !announceMetrics {
+				return
 			}
 			klog.Infof("Removing our presence in %v", etcdMetricsKey)
 			if err := metricsManager.DeleteEndpoint(ctx, etcdMetricsKey); err != nil {
 				klog.Errorf("DeleteEndpoint(): %v", err)
 			}
 		}()
+		if *backendHealthCheck {
+			dialOpts = append(dialOpts, grpc.WithDefaultServiceConfig(serviceConfig))
+		}
 	} else if strings.Contains(*rpcBackend, ",") {
 		// This should probably not be used in production. Either use etcd or a gRPC
 		// load balancer. It's only used by the integration tests.
@@ -180,14 +284,15 @@ func main() {
 		}
 		res.InitialState(resolver.State{Endpoints: endpoints})
 		resolver.SetDefaultScheme(res.Scheme())
-		dialOpts = append(dialOpts, grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"round_robin":{}}]}`), grpc.WithResolvers(res))
+		dialOpts = append(dialOpts, grpc.WithDefaultServiceConfig(serviceConfig), grpc.WithResolvers(res))
 	} else {
 		klog.Infof("Using regular DNS resolver")
-		dialOpts = append(dialOpts, grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"round_robin":{}}]}`))
+		dialOpts = append(dialOpts, grpc.WithDefaultServiceConfig(serviceConfig))
 	}
 
 	// Dial all our log backends.
 	clientMap := make(map[string]trillian.TrillianLogClient)
+	connMap := make(map[string]grpc.ClientConnInterface)
 	for _, be := range beMap {
 		klog.Infof("Dialling backend: %v", be)
 		if len(beMap) == 1 {
@@ -195,12 +300,29 @@ func main() {
 			// serve anything until connected.
 			dialOpts = append(dialOpts, grpc.WithBlock())
 		}
-		conn, err := grpc.Dial(be.BackendSpec, dialOpts...)
+		beDialOpts := append([]grpc.DialOption{}, dialOpts...)
+		// Retries are the outermost interceptor so that the circuit breaker
+		// sees each attempt.
+		if *readRetries > 1 || *readHedgeDelay > 0 {
+			beDialOpts = append(beDialOpts, grpc.WithChainUnaryInterceptor(ctfe.BackendRetryInterceptor(ctfe.BackendRetryOptions{
+				MaxAttempts:    *readRetries,
+				InitialBackoff: *readRetryBackoff,
+				MaxBackoff:     *rpcDeadline,
+				HedgeDelay:     *readHedgeDelay,
+				MetricFactory:  metricFactory,
+			})))
+		}
+		if *breakerThreshold > 0 {
+			breaker := ctfe.NewCircuitBreaker(be.Name, *breakerThreshold, *breakerCooldown, metricFactory, util.SystemTimeSource{})
+			beDialOpts = append(beDialOpts, grpc.WithChainUnaryInterceptor(breaker.UnaryClientInterceptor()))
+		}
+		pool, err := ctfe.DialBackendPool(be.BackendSpec, *backendPoolSize, beDialOpts...)
 		if err != nil {
 			klog.Exitf("Could not dial RPC server: %v: %v", be, err)
 		}
-		defer conn.Close()
-		clientMap[be.Name] = trillian.NewTrillianLogClient(conn)
+		defer pool.Close()
+		clientMap[be.Name] = trillian.NewTrillianLogClient(pool)
+		connMap[be.Name] = pool
 	}
 
 	// Allow cross-origin requests to all handlers registered on corsMux.
@@ -210,17 +332,123 @@ func main() {
 	corsHandler := cors.AllowAll().Handler(corsMux)
 	http.Handle("/", corsHandler)
 
+	// The add-chain/add-pre-chain handlers go on writeMux, which is served on
+	// its own endpoint if one is configured.
+	writeMux := corsMux
+	if len(*writeEndpoint) > 0 {
+		writeMux = http.NewServeMux()
+	}
+
+	maintenance := ctfe.NewMaintenanceMode(*maintenanceRetry, metricFactory)
+	maintenance.Set(*maintenanceMode)
+	go handleMaintenanceSignals(ctx, maintenance)
+
+	var apiKeys *ctfe.APIKeyAuthenticator
+	if len(*apiKeysFile) > 0 {
+		keyCfg, err := ctfe.APIKeyConfigFromFile(*apiKeysFile)
+		if err != nil {
+			klog.Exitf("Failed to read API keys: %v", err)
+		}
+		store, err := ctfe.NewStaticAPIKeyStore(keyCfg)
+		if err != nil {
+			klog.Exitf("Invalid API keys: %v", err)
+		}
+		apiKeys = ctfe.NewAPIKeyAuthenticator(store, *requireAPIKey, metricFactory)
+	} else if *requireAPIKey {
+		klog.Exit("--require_api_key needs --api_keys")
+	}
+
 	// Register handlers for all the configured logs using the correct RPC
 	// client.
+	var pollinationPool *pollination.Pool
+	if *sthPollination {
+		pollinationPool = newPollinationPool(*pollinationLogList)
+	}
+
 	var publicKeys []crypto.PublicKey
+	var logList []*ctfe.LogMetadata
+	var anchorer ctfe.Anchorer
+	var anchorRecords *ctfe.AnchorRecords
+	if len(*anchorRPCURL) > 0 {
+		if len(*anchorFrom) == 0 || len(*anchorContract) == 0 {
+			klog.Exit("--sth_anchor_rpc_url requires --sth_anchor_from and --sth_anchor_contract")
+		}
+		anchorer = &ctfe.EthereumAnchorer{URL: *anchorRPCURL, From: *anchorFrom, Contract: *anchorContract, Client: &http.Client{Timeout: time.Minute}}
+		if len(*anchorRecord) > 0 {
+			r, err := ctfe.OpenAnchorRecords(*anchorRecord)
+			if err != nil {
+				klog.Exitf("Failed to open STH anchor record: %v", err)
+			}
+			defer r.Close()
+			anchorRecords = r
+		}
+	}
+	var grpcServer *ctfe.GRPCServer
+	if len(*grpcEndpoint) > 0 {
+		grpcServer = ctfe.NewGRPCServer(metricFactory)
+	}
+	var dnsFrontend *ctfe.DNSFrontend
+	if len(*dnsEndpoint) > 0 {
+		if len(*dnsDomain) == 0 {
+			klog.Exit("--dns_endpoint requires --dns_domain")
+		}
+		dnsFrontend = ctfe.NewDNSFrontend(metricFactory)
+	}
+	var observedSTHs ctutil.STHStorage
+	if len(*sthStorageFile) > 0 {
+		st, err := ctutil.OpenFileSTHStorage(*sthStorageFile)
+		if err != nil {
+			klog.Exitf("Failed to open STH storage: %v", err)
+		}
+		defer st.Close()
+		observedSTHs = st
+	}
 	for _, c := range cfg.LogConfigs.Config {
-		inst, err := setupAndRegister(ctx, clientMap[c.LogBackendName], *rpcDeadline, c, corsMux, *handlerPrefix, *maskInternalErrors)
+		var sths ctfe.MirrorSTHStorage
+		var mirror *core.Controller
+		if c.IsMirror && len(c.MirrorSourceUri) > 0 {
+			var onSTH func(*ct.SignedTreeHead)
+			sths, onSTH = newMirrorSTHStorage(ctx, c, observedSTHs)
+			if mirror, err = newMirrorController(ctx, connMap[c.LogBackendName], c, onSTH); err != nil {
+				klog.Exitf("Failed to set up mirroring of %s for %q: %v", c.MirrorSourceUri, c.Prefix, err)
+			}
+		}
+		client := clientMap[c.LogBackendName]
+		if sb := c.StandbyLogBackendName; len(sb) > 0 {
+			conn := ctfe.NewFailoverConn(c.Prefix, connMap[c.LogBackendName], connMap[sb], *failoverThreshold, c.StandbyAcceptsWrites, metricFactory, util.SystemTimeSource{})
+			client = trillian.NewTrillianLogClient(conn)
+		}
+		if *coalesceQueueLeaf {
+			client = ctfe.NewCoalescingLogClient(client, *rpcDeadline, metricFactory)
+		}
+		inst, err := setupAndRegister(ctx, client, *rpcDeadline, c, sths, observedSTHs, maintenance, apiKeys, corsMux, writeMux, *handlerPrefix, *maskInternalErrors)
 		if err != nil {
 			klog.Exitf("Failed to set up log instance for %+v: %v", cfg, err)
 		}
+		if mirror != nil {
+			go mirror.RunWhenMasterWithRestarts(ctx)
+		}
 		if *getSTHInterval > 0 {
 			go inst.RunUpdateSTH(ctx, *getSTHInterval)
 		}
+		md, err := inst.Metadata(logHandlerPrefix(c, *handlerPrefix))
+		if err != nil {
+			klog.Exitf("Failed to describe log %q: %v", c.Prefix, err)
+		}
+		logList = append(logList, md)
+		if grpcServer != nil {
+			grpcServer.AddLog(inst)
+		}
+		if anchorer != nil {
+			a, err := ctfe.NewSTHAnchor(inst, anchorer, anchorRecords, metricFactory)
+			if err != nil {
+				klog.Exitf("Failed to set up STH anchoring for %q: %v", c.Prefix, err)
+			}
+			go a.Run(ctx, *anchorInterval)
+		}
+		if dnsFrontend != nil && len(c.Prefix) > 0 {
+			dnsFrontend.AddLog(c.Prefix+"."+*dnsDomain, inst)
+		}
 
 		// Ensure that this log does not share the same private key as any other
 		// log that has already been set up and registered.
@@ -242,9 +470,58 @@ func main() {
 				}
 			}
 			publicKeys = append(publicKeys, publicKey)
+			if pollinationPool != nil {
+				der, err := x509.MarshalPKIXPublicKey(publicKey)
+				if err != nil {
+					klog.Exitf("Failed to marshal public key of %q: %v", c.Prefix, err)
+				}
+				if err := pollinationPool.AddLog(der); err != nil {
+					klog.Exitf("Failed to set up STH pollination for %q: %v", c.Prefix, err)
+				}
+			}
 		}
 	}
 
+	if len(*logListPath) > 0 {
+		h, err := ctfe.LogListHandler(logList)
+		if err != nil {
+			klog.Exitf("Failed to build log list: %v", err)
+		}
+		corsMux.Handle(*logListPath, h)
+	}
+	if pollinationPool != nil {
+		corsMux.Handle(pollination.PollinationPath, &pollination.Handler{Pool: pollinationPool})
+	}
+
+	var grpcSrv *grpc.Server
+	if grpcServer != nil {
+		lis, err := net.Listen("tcp", *grpcEndpoint)
+		if err != nil {
+			klog.Exitf("Failed to listen on gRPC endpoint %s: %v", *grpcEndpoint, err)
+		}
+		grpcSrv = grpc.NewServer()
+		ctapipb.RegisterCTLogServer(grpcSrv, grpcServer)
+		go func() {
+			klog.Infof("Serving gRPC on %s", *grpcEndpoint)
+			if err := grpcSrv.Serve(lis); err != nil {
+				klog.Errorf("gRPC server exited: %v", err)
+			}
+		}()
+	}
+
+	if dnsFrontend != nil {
+		conn, err := net.ListenPacket("udp", *dnsEndpoint)
+		if err != nil {
+			klog.Exitf("Failed to listen for DNS queries on %s: %v", *dnsEndpoint, err)
+		}
+		klog.Infof("Serving CT-over-DNS on %s for zones under %s", *dnsEndpoint, *dnsDomain)
+		go func() {
+			if err := dnsFrontend.Serve(ctx, conn); err != nil {
+				klog.Errorf("DNS frontend stopped: %v", err)
+			}
+		}()
+	}
+
 	// Return a 200 on the root, for GCE default health checking :/
 	corsMux.HandleFunc("/", func(resp http.ResponseWriter, req *http.Request) {
 		if req.URL.Path == "/" {
@@ -255,20 +532,28 @@ func main() {
 	})
 
 	// Export a healthz target.
-	corsMux.HandleFunc("/healthz", func(resp http.ResponseWriter, req *http.Request) {
+	healthz := func(resp http.ResponseWriter, req *http.Request) {
 		// TODO(al): Wire this up to tell the truth.
 		if _, err := resp.Write([]byte("ok")); err != nil {
 			klog.Errorf("resp.Write(): %v", err)
 		}
-	})
+	}
+	corsMux.HandleFunc("/healthz", healthz)
+	if writeMux != corsMux {
+		writeMux.HandleFunc("/healthz", healthz)
+	}
 
-	if metricsAt != *httpEndpoint {
+	if !sharedMetrics {
 		// Run a separate handler for metrics.
 		go func() {
 			mux := http.NewServeMux()
 			mux.Handle("/metrics", promhttp.Handler())
-			metricsServer := http.Server{Addr: metricsAt, Handler: mux}
-			err := metricsServer.ListenAndServe()
+			metricsServer := http.Server{Handler: mux}
+			lis, err := listen(metricsAt)
+			if err != nil {
+				klog.Exitf("Failed to listen on metrics endpoint %s: %v", metricsAt, err)
+			}
+			err = metricsServer.Serve(lis)
 			klog.Warningf("Metrics server exited: %v", err)
 		}()
 	} else {
@@ -277,40 +562,152 @@ func main() {
 	}
 
 	// If we're enabling tracing we need to use an instrumented http.Handler.
-	var handler http.Handler
+	var handler http.Handler = http.DefaultServeMux
+	var writeHandler http.Handler = cors.AllowAll().Handler(writeMux)
 	if *tracing {
 		handler, err = opencensus.EnableHTTPServerTracing(*tracingProjectID, *tracingPercent)
 		if err != nil {
 			klog.Exitf("Failed to initialize stackdriver / opencensus tracing: %v", err)
 		}
+		writeHandler = &ochttp.Handler{Handler: writeHandler}
+	}
+
+	// Bring up the HTTP server(s) and serve until we get a signal not to.
+	servers := httpServers(handler, writeHandler)
+	for _, s := range servers {
+		if s.lis, err = listen(s.endpoint); err != nil {
+			klog.Exitf("Failed to listen on HTTP endpoint %s: %v", s.endpoint, err)
+		}
 	}
 
-	// Bring up the HTTP server and serve until we get a signal not to.
-	srv := http.Server{Addr: *httpEndpoint, Handler: handler}
-	shutdownWG := new(sync.WaitGroup)
+	var shuttingDown atomic.Bool
+	shutdownDone := make(chan struct{})
 	go awaitSignal(func() {
-		shutdownWG.Add(1)
-		defer shutdownWG.Done()
+		shuttingDown.Store(true)
+		defer close(shutdownDone)
 		// Allow 60s for any pending requests to finish then terminate any stragglers
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second*60)
 		defer cancel()
 		klog.Info("Shutting down HTTP server...")
-		if err := srv.Shutdown(ctx); err != nil {
-			klog.Errorf("srv.Shutdown(): %v", err)
+		var wg sync.WaitGroup
+		if grpcSrv != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				grpcSrv.GracefulStop()
+			}()
 		}
+		for _, s := range servers {
+			wg.Add(1)
+			go func(s *server) {
+				defer wg.Done()
+				if err := s.srv.Shutdown(ctx); err != nil {
+					klog.Errorf("srv.Shutdown(%s): %v", s.endpoint, err)
+				}
+			}(s)
+		}
+		wg.Wait()
 		klog.Info("HTTP server shutdown")
 	})
 
-	err = srv.ListenAndServe()
-	if err != http.ErrServerClosed {
-		klog.Warningf("Server exited: %v", err)
+	serveErrs := make(chan error, len(servers))
+	for _, s := range servers {
+		go func(s *server) {
+			klog.Infof("Serving HTTP on %s", s.endpoint)
+			serveErrs <- s.srv.Serve(s.lis)
+		}(s)
+	}
+	for range servers {
+		if err := <-serveErrs; err != http.ErrServerClosed {
+			klog.Warningf("Server exited: %v", err)
+			break
+		}
+	}
+	// Only block if the function passed to awaitSignal was called, in which
+	// case wait until the HTTP servers have gracefully shutdown.
+	if shuttingDown.Load() {
+		<-shutdownDone
 	}
-	// Wait will only block if the function passed to awaitSignal was called,
-	// in which case it'll block until the HTTP server has gracefully shutdown
-	shutdownWG.Wait()
 	klog.Flush()
 }
 
+// server is an HTTP server for one of our endpoints.
+type server struct {
+	endpoint string
+	lis      net.Listener
+	srv      *http.Server
+}
+
+// newServers creates a server for each of the given comma-separated endpoints,
+// which share the handler and apply the given I/O timeout and request rate
+// limit, if they are non-zero. The rate limit applies to all of the servers
+// together.
+func newServers(endpoints string, handler http.Handler, timeout time.Duration, qps float64) []*server {
+	if qps > 0 {
+		handler = rateLimited(handler, rate.NewLimiter(rate.Limit(qps), int(math.Ceil(qps))))
+	}
+	var servers []*server
+	for _, endpoint := range splitEndpoints(endpoints) {
+		servers = append(servers, &server{
+			endpoint: endpoint,
+			srv:      &http.Server{Handler: handler, ReadTimeout: timeout, WriteTimeout: timeout},
+		})
+	}
+	return servers
+}
+
+// httpServers returns the servers of --http_endpoint, serving handler, and of
+// --http_write_endpoint if it is set, serving writeHandler, each with the
+// timeout and rate limit of its flags.
+func httpServers(handler, writeHandler http.Handler) []*server {
+	servers := newServers(*httpEndpoint, handler, *httpTimeout, *httpRateLimit)
+	if len(*writeEndpoint) > 0 {
+		servers = append(servers, newServers(*writeEndpoint, writeHandler, *writeTimeout, *writeRateLimit)...)
+	}
+	return servers
+}
+
+// splitEndpoints splits a comma-separated list of endpoints, ignoring empty
+// entries, so that e.g. an IPv4 and an IPv6 address can both be listened on.
+func splitEndpoints(endpoints string) []string {
+	var ret []string
+	for _, ep := range strings.Split(endpoints, ",") {
+		if ep = strings.TrimSpace(ep); len(ep) > 0 {
+			ret = append(ret, ep)
+		}
+	}
+	return ret
+}
+
+// metricsEndpointFor returns the endpoint to serve metrics on, given the
+// --metrics_endpoint flag and the HTTP endpoints, and whether it is one of the
+// HTTP endpoints, so that metrics are served by the HTTP servers. The metrics
+// endpoint defaults to the first HTTP endpoint.
+func metricsEndpointFor(metricsEndpoint string, httpEndpoints []string) (string, bool) {
+	if metricsEndpoint == "" {
+		return httpEndpoints[0], true
+	}
+	for _, ep := range httpEndpoints {
+		if ep == metricsEndpoint {
+			return metricsEndpoint, true
+		}
+	}
+	return metricsEndpoint, false
+}
+
+// rateLimited wraps handler so that requests beyond those allowed by limiter
+// are rejected with HTTP 429.
+func rateLimited(handler http.Handler, limiter *rate.Limiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !limiter.Allow() {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
 // awaitSignal waits for standard termination signals, then runs the given
 // function; it should be run as a separate goroutine.
 func awaitSignal(doneFn func()) {
@@ -326,7 +723,97 @@ func awaitSignal(doneFn func()) {
 	doneFn()
 }
 
-func setupAndRegister(ctx context.Context, client trillian.TrillianLogClient, deadline time.Duration, cfg *configpb.LogConfig, mux *http.ServeMux, globalHandlerPrefix string, maskInternalErrors bool) (*ctfe.Instance, error) {
+// handleMaintenanceSignals turns maintenance mode on when SIGUSR1 is received
+// and off when SIGUSR2 is received, until the context is done.
+func handleMaintenanceSignals(ctx context.Context, maintenance *ctfe.MaintenanceMode) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(sigs)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-sigs:
+			maintenance.Set(sig == syscall.SIGUSR1)
+		}
+	}
+}
+
+// etcdTLSConfig builds the TLS configuration for talking to etcd from the
+// given PEM files. It returns nil if none of the files are specified, in which
+// case the etcd connection is unencrypted.
+// etcdPasswordEnv is the environment variable holding the etcd password if
+// --etcd_password_file is not set. The password is never taken from a flag, as
+// flags are visible in process listings.
+const etcdPasswordEnv = "CTFE_ETCD_PASSWORD"
+
+// etcdPassword returns the password for etcd authentication, read from
+// passwordFile if it is set, or else from the etcdPasswordEnv environment
+// variable. A trailing newline in the file is ignored.
+func etcdPassword(passwordFile string, getenv func(string) string) (string, error) {
+	if passwordFile == "" {
+		return getenv(etcdPasswordEnv), nil
+	}
+	data, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read etcd password file: %v", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// etcdTLSConfig returns the TLS configuration of the etcd client, or nil if
+// none of the files enabling TLS is set.
+func etcdTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	if caFile == "" && certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("both or neither of --etcd_cert_file and --etcd_key_file must be set")
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read etcd CA file: %v", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in etcd CA file %q", caFile)
+		}
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load etcd client key pair: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// monitorEtcdEndpoints periodically checks the status of each of the etcd
+// client's endpoints, and exports per-endpoint health metrics so that a
+// single unreachable or misconfigured etcd member is visible to operators.
+func monitorEtcdEndpoints(ctx context.Context, client *clientv3.Client, period time.Duration) {
+	up := metricFactory.NewGauge("etcd_endpoint_up", "Set to 1 if the last status check of the etcd endpoint succeeded", "endpoint")
+	failures := metricFactory.NewCounter("etcd_endpoint_failures", "Number of failed status checks of the etcd endpoint", "endpoint")
+	schedule.Every(ctx, period, func(ctx context.Context) {
+		for _, ep := range client.Endpoints() {
+			cctx, cancel := context.WithTimeout(ctx, period)
+			_, err := client.Status(cctx, ep)
+			cancel()
+			if err != nil {
+				klog.Warningf("etcd endpoint %s unhealthy: %v", ep, err)
+				failures.Inc(ep)
+				up.Set(0, ep)
+				continue
+			}
+			up.Set(1, ep)
+		}
+	})
+}
+
+func setupAndRegister(ctx context.Context, client trillian.TrillianLogClient, deadline time.Duration, cfg *configpb.LogConfig, sths ctfe.MirrorSTHStorage, observedSTHs ctutil.STHStorage, maintenance *ctfe.MaintenanceMode, apiKeys *ctfe.APIKeyAuthenticator, mux, writeMux *http.ServeMux, globalHandlerPrefix string, maskInternalErrors bool) (*ctfe.Instance, error) {
 	vCfg, err := ctfe.ValidateLogConfig(cfg)
 	if err != nil {
 		return nil, err
@@ -336,8 +823,13 @@ func setupAndRegister(ctx context.Context, client trillian.TrillianLogClient, de
 		Validated:          vCfg,
 		Client:             client,
 		Deadline:           deadline,
-		MetricFactory:      prometheus.MetricFactory{},
+		MetricFactory:      metricFactory,
 		RequestLog:         new(ctfe.DefaultRequestLog),
+		STHStorage:         sths,
+		ObservedSTHs:       observedSTHs,
+		Maintenance:        maintenance,
+		APIKeys:            apiKeys,
+		ChainDiagnostics:   *chainDiagnostics,
 		MaskInternalErrors: maskInternalErrors,
 	}
 	if *quotaRemote {
@@ -361,17 +853,56 @@ func setupAndRegister(ctx context.Context, client trillian.TrillianLogClient, de
 	// have an existing URL path that differs from the global one. For example
 	// if all new logs are served on "/logs/log/..." and a previously existing
 	// log is at "/log/..." this is now supported.
-	lhp := globalHandlerPrefix
+	lhp := logHandlerPrefix(cfg, globalHandlerPrefix)
 	if ohPrefix := cfg.OverrideHandlerPrefix; len(ohPrefix) > 0 {
 		klog.Infof("Log with prefix: %s is using a custom HandlerPrefix: %s", cfg.Prefix, ohPrefix)
-		lhp = "/" + strings.Trim(ohPrefix, "/")
 	}
 	inst, err := ctfe.SetUpInstance(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
+	// A virtual host at the start of the pattern restricts it to requests with
+	// that Host header.
+	pattern := cfg.VirtualHost + lhp
+	if len(cfg.VirtualHost) > 0 {
+		klog.Infof("Log with prefix: %s is served on virtual host: %s", cfg.Prefix, cfg.VirtualHost)
+	}
 	for path, handler := range inst.Handlers {
-		mux.Handle(lhp+path, handler)
+		if handler.Method == http.MethodPost {
+			writeMux.Handle(pattern+path, handler)
+		} else {
+			mux.Handle(pattern+path, handler)
+		}
 	}
 	return inst, nil
 }
+
+// logHandlerPrefix returns the handler prefix for the given log, which is the
+// global one unless the log's config overrides it.
+func logHandlerPrefix(cfg *configpb.LogConfig, globalHandlerPrefix string) string {
+	if ohPrefix := cfg.OverrideHandlerPrefix; len(ohPrefix) > 0 {
+		return "/" + strings.Trim(ohPrefix, "/")
+	}
+	return globalHandlerPrefix
+}
+
+// newPollinationPool creates the pool of STHs for --sth_pollination, which
+// knows the logs of the log list at logListPath, if any.
+func newPollinationPool(logListPath string) *pollination.Pool {
+	if len(logListPath) == 0 {
+		return pollination.NewPool(pollination.Options{})
+	}
+	data, err := x509util.ReadFileOrURL(logListPath, http.DefaultClient)
+	if err != nil {
+		klog.Exitf("Failed to read --sth_pollination_log_list: %v", err)
+	}
+	ll, err := loglist3.NewFromJSON(data)
+	if err != nil {
+		klog.Exitf("Failed to parse --sth_pollination_log_list: %v", err)
+	}
+	p, err := pollination.NewPoolFromLogList(ll, pollination.Options{})
+	if err != nil {
+		klog.Exitf("Failed to set up STH pollination: %v", err)
+	}
+	return p
+}
@@ -0,0 +1,219 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplitEndpoints(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want []string
+	}{
+		{in: "", want: nil},
+		{in: "localhost:6962", want: []string{"localhost:6962"}},
+		{in: "0.0.0.0:80, [::]:80", want: []string{"0.0.0.0:80", "[::]:80"}},
+		{in: ",unix:/run/ct.sock,,systemd:http,", want: []string{"unix:/run/ct.sock", "systemd:http"}},
+	} {
+		if got := splitEndpoints(test.in); !cmp.Equal(got, test.want) {
+			t.Errorf("splitEndpoints(%q)=%q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestMetricsEndpointFor(t *testing.T) {
+	httpEndpoints := []string{"a:1", "b:2"}
+	for _, test := range []struct {
+		metrics    string
+		want       string
+		wantShared bool
+	}{
+		{metrics: "", want: "a:1", wantShared: true},
+		{metrics: "b:2", want: "b:2", wantShared: true},
+		{metrics: "c:3", want: "c:3", wantShared: false},
+	} {
+		got, shared := metricsEndpointFor(test.metrics, httpEndpoints)
+		if got != test.want || shared != test.wantShared {
+			t.Errorf("metricsEndpointFor(%q, %q)=%q,%t, want %q,%t", test.metrics, httpEndpoints, got, shared, test.want, test.wantShared)
+		}
+	}
+}
+
+func TestNewServers(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {})
+	servers := newServers("a:1, b:2", handler, time.Minute, 1)
+	var endpoints []string
+	for _, s := range servers {
+		endpoints = append(endpoints, s.endpoint)
+		if s.srv.ReadTimeout != time.Minute || s.srv.WriteTimeout != time.Minute {
+			t.Errorf("server for %s has timeouts %v/%v, want %v", s.endpoint, s.srv.ReadTimeout, s.srv.WriteTimeout, time.Minute)
+		}
+	}
+	if want := []string{"a:1", "b:2"}; !cmp.Equal(endpoints, want) {
+		t.Fatalf("newServers() endpoints=%q, want %q", endpoints, want)
+	}
+
+	// The rate limit is shared by the servers, so the burst allowed by the
+	// first leaves none for the second.
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		servers[i].srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != want {
+			t.Errorf("request to server %d got status %d, want %d", i, w.Code, want)
+		}
+	}
+
+	if got := newServers("", handler, 0, 0); len(got) != 0 {
+		t.Errorf("newServers(\"\") returned %d servers, want none", len(got))
+	}
+}
+
+func TestEtcdPassword(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	getenv := func(name string) string {
+		if name == etcdPasswordEnv {
+			return "from-env"
+		}
+		return ""
+	}
+	for _, test := range []struct {
+		desc    string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{desc: "env", want: "from-env"},
+		{desc: "file", file: passwordFile, want: "s3cret"},
+		{desc: "missing-file", file: filepath.Join(dir, "missing"), wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := etcdPassword(test.file, getenv)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("etcdPassword()=%q, %v; want error %v", got, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("etcdPassword()=%q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// writeKeyPair writes a self-signed certificate and its key to PEM files in
+// dir, returning their paths.
+func writeKeyPair(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "etcd test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestEtcdTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	cert, key := writeKeyPair(t, dir)
+	otherCert, _ := writeKeyPair(t, t.TempDir())
+	garbage := filepath.Join(dir, "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("not PEM"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.pem")
+
+	for _, test := range []struct {
+		desc                      string
+		caFile, certFile, keyFile string
+		wantErr                   string
+		wantCA, wantCert          bool
+	}{
+		{desc: "no-tls"},
+		{desc: "ca-only", caFile: cert, wantCA: true},
+		{desc: "ca-and-client-cert", caFile: cert, certFile: cert, keyFile: key, wantCA: true, wantCert: true},
+		{desc: "client-cert-only", certFile: cert, keyFile: key, wantCert: true},
+		{desc: "cert-without-key", caFile: cert, certFile: cert, wantErr: "both or neither"},
+		{desc: "key-without-cert", keyFile: key, wantErr: "both or neither"},
+		{desc: "missing-ca", caFile: missing, wantErr: "failed to read etcd CA file"},
+		{desc: "invalid-ca", caFile: garbage, wantErr: "no certificates found"},
+		{desc: "missing-cert", certFile: missing, keyFile: key, wantErr: "failed to load etcd client key pair"},
+		{desc: "invalid-key", certFile: cert, keyFile: garbage, wantErr: "failed to load etcd client key pair"},
+		{desc: "mismatched-key", certFile: otherCert, keyFile: key, wantErr: "failed to load etcd client key pair"},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			cfg, err := etcdTLSConfig(test.caFile, test.certFile, test.keyFile)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("etcdTLSConfig()=%v; want error containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("etcdTLSConfig()=%v", err)
+			}
+			if test.caFile == "" && test.certFile == "" {
+				if cfg != nil {
+					t.Errorf("etcdTLSConfig()=%+v, want nil", cfg)
+				}
+				return
+			}
+			if got := cfg.RootCAs != nil; got != test.wantCA {
+				t.Errorf("etcdTLSConfig() has CA pool %v, want %v", got, test.wantCA)
+			}
+			if got := len(cfg.Certificates) == 1; got != test.wantCert {
+				t.Errorf("etcdTLSConfig() has client certificate %v, want %v", got, test.wantCert)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/schedule"
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/util/statsd"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/monitoring/prometheus"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/klog/v2"
+)
+
+var (
+	metricsExporter     = flag.String("metrics_exporter", "prometheus", "Where to export metrics: prometheus (served on --metrics_endpoint, and optionally pushed to --metrics_push_url), statsd or dogstatsd (sent to --statsd_address)")
+	statsdAddress       = flag.String("statsd_address", "localhost:8125", "host:port of the statsd or DogStatsD agent to send metrics to")
+	statsdPrefix        = flag.String("statsd_prefix", "ct_server", "Prefix for the names of metrics sent to statsd")
+	metricsPushURL      = flag.String("metrics_push_url", "", "If set, URL of a Prometheus Pushgateway (or compatible endpoint) to push metrics to")
+	metricsPushJob      = flag.String("metrics_push_job", "ct_server", "Job name to push metrics under")
+	metricsPushInterval = flag.Duration("metrics_push_interval", time.Second*15, "Interval between pushes of metrics to --metrics_push_url")
+	metricsPushFormat   = flag.String("metrics_push_format", "protobuf", "Format of pushed metrics: protobuf (as expected by the Prometheus Pushgateway), text or openmetrics")
+)
+
+// metricFactory creates all of the server's metrics.
+var metricFactory monitoring.MetricFactory = prometheus.MetricFactory{}
+
+// setUpMetrics sets metricFactory according to the flags, and starts pushing
+// metrics if required.
+func setUpMetrics(ctx context.Context) error {
+	switch *metricsExporter {
+	case "prometheus":
+		metricFactory = prometheus.MetricFactory{}
+	case "statsd", "dogstatsd":
+		if len(*metricsPushURL) > 0 {
+			return fmt.Errorf("--metrics_push_url requires --metrics_exporter=prometheus")
+		}
+		client, err := statsd.Dial(*statsdAddress)
+		if err != nil {
+			return err
+		}
+		client.DogStatsD = *metricsExporter == "dogstatsd"
+		client.Prefix = *statsdPrefix
+		metricFactory = statsd.MetricFactory{Client: client}
+		klog.Infof("Sending metrics to %s at %s", *metricsExporter, *statsdAddress)
+	default:
+		return fmt.Errorf("unknown --metrics_exporter %q", *metricsExporter)
+	}
+
+	if len(*metricsPushURL) == 0 {
+		return nil
+	}
+	var format expfmt.Format
+	switch *metricsPushFormat {
+	case "protobuf":
+		format = expfmt.FmtProtoDelim
+	case "text":
+		format = expfmt.FmtText
+	case "openmetrics":
+		format = expfmt.FmtOpenMetrics_1_0_0
+	default:
+		return fmt.Errorf("unknown --metrics_push_format %q", *metricsPushFormat)
+	}
+	pusher := push.New(*metricsPushURL, *metricsPushJob).Gatherer(prom.DefaultGatherer).Format(format)
+	klog.Infof("Pushing metrics to %s every %v", *metricsPushURL, *metricsPushInterval)
+	go schedule.Every(ctx, *metricsPushInterval, func(ctx context.Context) {
+		if err := pusher.PushContext(ctx); err != nil {
+			klog.Warningf("Failed to push metrics to %s: %v", *metricsPushURL, err)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/scanner"
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe"
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/configpb"
+	mpb "github.com/RarimoVoting/certificate-transparency-go/trillian/migrillian/configpb"
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/migrillian/core"
+	"github.com/google/trillian"
+	"github.com/google/trillian/util/election2"
+	"google.golang.org/grpc"
+	"k8s.io/klog/v2"
+)
+
+// newMirrorSTHStorage returns the storage of the source log STHs of the given
+// mirror config, and the function recording them in it. The STHs are kept in
+// observed if it is set, so that they survive restarts, and otherwise in
+// memory.
+func newMirrorSTHStorage(ctx context.Context, cfg *configpb.LogConfig, observed ctutil.STHStorage) (ctfe.MirrorSTHStorage, func(*ct.SignedTreeHead)) {
+	if observed == nil {
+		sths := ctfe.NewMemoryMirrorSTHStorage()
+		return sths, sths.AddSTH
+	}
+	sths := ctfe.NewPersistentMirrorSTHStorage(observed, sha256.Sum256(cfg.PublicKey.GetDer()))
+	return sths, func(sth *ct.SignedTreeHead) {
+		if err := sths.AddSTH(ctx, sth); err != nil {
+			klog.Warningf("%s: failed to record source log STH: %v", cfg.Prefix, err)
+		}
+	}
+}
+
+// newMirrorController returns a Controller which continuously copies the
+// entries of the source log of the given mirror config into the mirror's
+// PREORDERED_LOG tree, and passes the source log's STHs to onSTH once the
+// entries they cover have been submitted.
+//
+// Each CTFE replica runs its own Controller. This is safe, as Trillian
+// ignores sequenced leaves which are already present, but operators of large
+// mirrors may prefer to run a single replica with mirror_source_uri set.
+func newMirrorController(ctx context.Context, conn grpc.ClientConnInterface, cfg *configpb.LogConfig, onSTH func(*ct.SignedTreeHead)) (*core.Controller, error) {
+	ctOpts := jsonclient.Options{PublicKeyDER: cfg.PublicKey.GetDer(), UserAgent: "ct-go-ct_server-mirror/1.0"}
+	ctClient, err := client.New(cfg.MirrorSourceUri, &http.Client{Timeout: *mirrorFetchTimeout}, ctOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CT client: %v", err)
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, *rpcDeadline)
+	defer cancel()
+	tree, err := trillian.NewTrillianAdminClient(conn).GetTree(cctx, &trillian.GetTreeRequest{TreeId: cfg.LogId})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree %d: %v", cfg.LogId, err)
+	}
+	plClient, err := core.NewPreorderedLogClient(trillian.NewTrillianLogClient(conn), tree, mpb.IdentityFunction_SHA256_LEAF_INDEX, cfg.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PreorderedLogClient: %v", err)
+	}
+
+	opts := core.Options{
+		FetcherOptions: scanner.FetcherOptions{
+			BatchSize:     *mirrorBatchSize,
+			ParallelFetch: *mirrorFetchers,
+			Continuous:    true,
+		},
+		Submitters:  *mirrorFetchers,
+		ChannelSize: *mirrorFetchers,
+		StartDelay:  *mirrorRestartDelay,
+		OnSTH:       onSTH,
+	}
+	return core.NewController(opts, ctClient, plClient, election2.NoopFactory{}, metricFactory), nil
+}
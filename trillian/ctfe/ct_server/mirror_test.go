@@ -0,0 +1,65 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe"
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/configpb"
+	"github.com/google/trillian/crypto/keyspb"
+)
+
+func TestNewMirrorSTHStorage(t *testing.T) {
+	ctx := context.Background()
+	cfg := &configpb.LogConfig{Prefix: "mirror", PublicKey: &keyspb.PublicKey{Der: []byte("source key")}}
+	sth := &ct.SignedTreeHead{TreeSize: 10, Timestamp: 1000}
+
+	sths, onSTH := newMirrorSTHStorage(ctx, cfg, nil)
+	if _, ok := sths.(*ctfe.MemoryMirrorSTHStorage); !ok {
+		t.Errorf("newMirrorSTHStorage(nil)=%T, want *ctfe.MemoryMirrorSTHStorage", sths)
+	}
+	onSTH(sth)
+	if got, err := sths.GetMirrorSTH(ctx, 10); err != nil || got != sth {
+		t.Errorf("GetMirrorSTH(10)=%v, %v; want %v", got, err, sth)
+	}
+
+	// The STHs recorded in a persistent store survive a restart.
+	path := filepath.Join(t.TempDir(), "sths.jsonl")
+	store, err := ctutil.OpenFileSTHStorage(path)
+	if err != nil {
+		t.Fatalf("OpenFileSTHStorage()=%v", err)
+	}
+	_, onSTH = newMirrorSTHStorage(ctx, cfg, store)
+	onSTH(sth)
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close()=%v", err)
+	}
+	if store, err = ctutil.OpenFileSTHStorage(path); err != nil {
+		t.Fatalf("OpenFileSTHStorage()=%v", err)
+	}
+	defer store.Close()
+	sths, _ = newMirrorSTHStorage(ctx, cfg, store)
+	if got, err := sths.GetMirrorSTH(ctx, 20); err != nil || got.TreeSize != 10 || got.Timestamp != 1000 {
+		t.Errorf("GetMirrorSTH(20) after restart=%+v, %v; want size 10 at 1000", got, err)
+	}
+	if _, err := sths.GetMirrorSTH(ctx, 9); err == nil {
+		t.Error("GetMirrorSTH(9) succeeded, want error")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/configpb"
+	"github.com/google/trillian/crypto/keys"
+	"github.com/google/trillian/crypto/keys/pem"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// setFlag sets a flag variable for the duration of the test.
+func setFlag[T any](t *testing.T, flag *T, value T) {
+	t.Helper()
+	old := *flag
+	*flag = value
+	t.Cleanup(func() { *flag = old })
+}
+
+func TestSetupAndRegisterWriteMux(t *testing.T) {
+	keys.RegisterHandler(&keyspb.PEMKeyFile{}, pem.FromProto)
+	setFlag(t, &metricFactory, monitoring.MetricFactory(monitoring.InertMetricFactory{}))
+	privKey, err := anypb.New(&keyspb.PEMKeyFile{Path: "../../testdata/ct-http-server.privkey.pem", Password: "dirk"})
+	if err != nil {
+		t.Fatalf("anypb.New()=%v", err)
+	}
+	cfg := &configpb.LogConfig{LogId: 1, Prefix: "log", RootsPemFile: []string{"../../testdata/fake-ca.cert"}, PrivateKey: privKey}
+
+	for _, test := range []struct {
+		desc          string
+		separateWrite bool
+	}{
+		{desc: "shared-mux"},
+		{desc: "write-mux", separateWrite: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			mux := http.NewServeMux()
+			writeMux := mux
+			if test.separateWrite {
+				writeMux = http.NewServeMux()
+			}
+			if _, err := setupAndRegister(context.Background(), nil, time.Second, cfg, nil, nil, nil, nil, mux, writeMux, "", false); err != nil {
+				t.Fatalf("setupAndRegister()=%v", err)
+			}
+			handled := func(mux *http.ServeMux, method, path string) bool {
+				_, pattern := mux.Handler(httptest.NewRequest(method, path, nil))
+				return pattern != ""
+			}
+			for _, path := range []string{"/log/ct/v1/add-chain", "/log/ct/v1/add-pre-chain"} {
+				if !handled(writeMux, http.MethodPost, path) {
+					t.Errorf("%s not served on the write mux", path)
+				}
+				if test.separateWrite && handled(mux, http.MethodPost, path) {
+					t.Errorf("%s served on the main mux with a write endpoint", path)
+				}
+			}
+			for _, path := range []string{"/log/ct/v1/get-sth", "/log/ct/v1/get-entries"} {
+				if !handled(mux, http.MethodGet, path) {
+					t.Errorf("%s not served on the main mux", path)
+				}
+				if test.separateWrite && handled(writeMux, http.MethodGet, path) {
+					t.Errorf("%s served on the write mux", path)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPServers(t *testing.T) {
+	setFlag(t, httpEndpoint, "a:1,b:2")
+	setFlag(t, httpTimeout, time.Minute)
+	setFlag(t, httpRateLimit, 1.0)
+	setFlag(t, writeEndpoint, "w:1")
+	setFlag(t, writeTimeout, time.Hour)
+	setFlag(t, writeRateLimit, 2.0)
+
+	ok := func(w http.ResponseWriter, _ *http.Request) {}
+	servers := httpServers(http.HandlerFunc(ok), http.HandlerFunc(ok))
+	for i, want := range []struct {
+		endpoint string
+		timeout  time.Duration
+	}{
+		{"a:1", time.Minute},
+		{"b:2", time.Minute},
+		{"w:1", time.Hour},
+	} {
+		if i >= len(servers) {
+			t.Fatalf("httpServers() returned %d servers, want 3", len(servers))
+		}
+		s := servers[i]
+		if s.endpoint != want.endpoint || s.srv.ReadTimeout != want.timeout || s.srv.WriteTimeout != want.timeout {
+			t.Errorf("server %d is for %s with timeouts %v/%v, want %s with %v", i, s.endpoint, s.srv.ReadTimeout, s.srv.WriteTimeout, want.endpoint, want.timeout)
+		}
+	}
+
+	// The write endpoint has its own rate limit, with a burst of 2, which
+	// the requests to the main endpoints do not use up.
+	serve := func(s *server) int {
+		w := httptest.NewRecorder()
+		s.srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+		return w.Code
+	}
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		s := servers[0]
+		if i >= 2 {
+			s = servers[2]
+		}
+		if got := serve(s); got != want {
+			t.Errorf("request %d to %s got status %d, want %d", i, s.endpoint, got, want)
+		}
+	}
+
+	setFlag(t, writeEndpoint, "")
+	if got := httpServers(http.HandlerFunc(ok), http.HandlerFunc(ok)); len(got) != 2 {
+		t.Errorf("httpServers() without a write endpoint returned %d servers, want 2", len(got))
+	}
+}
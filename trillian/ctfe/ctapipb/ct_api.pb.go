@@ -0,0 +1,946 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        v3.20.1
+// source: trillian/ctfe/ctapipb/ct_api.proto
+
+package ctapipb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AddChainRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// log_prefix identifies the log to submit to.
+	LogPrefix string `protobuf:"bytes,1,opt,name=log_prefix,json=logPrefix,proto3" json:"log_prefix,omitempty"`
+	// chain holds the DER-encoded certificates, starting with the end-entity
+	// certificate or precertificate.
+	Chain [][]byte `protobuf:"bytes,2,rep,name=chain,proto3" json:"chain,omitempty"`
+}
+
+func (x *AddChainRequest) Reset() {
+	*x = AddChainRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddChainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddChainRequest) ProtoMessage() {}
+
+func (x *AddChainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddChainRequest.ProtoReflect.Descriptor instead.
+func (*AddChainRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_ctfe_ctapipb_ct_api_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AddChainRequest) GetLogPrefix() string {
+	if x != nil {
+		return x.LogPrefix
+	}
+	return ""
+}
+
+func (x *AddChainRequest) GetChain() [][]byte {
+	if x != nil {
+		return x.Chain
+	}
+	return nil
+}
+
+// AddChainResponse holds the fields of a V1 SignedCertificateTimestamp.
+type AddChainResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SctVersion uint32 `protobuf:"varint,1,opt,name=sct_version,json=sctVersion,proto3" json:"sct_version,omitempty"`
+	Id         []byte `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Timestamp  uint64 `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Extensions []byte `protobuf:"bytes,4,opt,name=extensions,proto3" json:"extensions,omitempty"`
+	// signature is the TLS-encoded DigitallySigned struct.
+	Signature []byte `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (x *AddChainResponse) Reset() {
+	*x = AddChainResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddChainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddChainResponse) ProtoMessage() {}
+
+func (x *AddChainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddChainResponse.ProtoReflect.Descriptor instead.
+func (*AddChainResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_ctfe_ctapipb_ct_api_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AddChainResponse) GetSctVersion() uint32 {
+	if x != nil {
+		return x.SctVersion
+	}
+	return 0
+}
+
+func (x *AddChainResponse) GetId() []byte {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *AddChainResponse) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *AddChainResponse) GetExtensions() []byte {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
+func (x *AddChainResponse) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+type GetSTHRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LogPrefix string `protobuf:"bytes,1,opt,name=log_prefix,json=logPrefix,proto3" json:"log_prefix,omitempty"`
+}
+
+func (x *GetSTHRequest) Reset() {
+	*x = GetSTHRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSTHRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSTHRequest) ProtoMessage() {}
+
+func (x *GetSTHRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSTHRequest.ProtoReflect.Descriptor instead.
+func (*GetSTHRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_ctfe_ctapipb_ct_api_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetSTHRequest) GetLogPrefix() string {
+	if x != nil {
+		return x.LogPrefix
+	}
+	return ""
+}
+
+type GetSTHResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TreeSize       uint64 `protobuf:"varint,1,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
+	Timestamp      uint64 `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Sha256RootHash []byte `protobuf:"bytes,3,opt,name=sha256_root_hash,json=sha256RootHash,proto3" json:"sha256_root_hash,omitempty"`
+	// tree_head_signature is the TLS-encoded DigitallySigned struct.
+	TreeHeadSignature []byte `protobuf:"bytes,4,opt,name=tree_head_signature,json=treeHeadSignature,proto3" json:"tree_head_signature,omitempty"`
+}
+
+func (x *GetSTHResponse) Reset() {
+	*x = GetSTHResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSTHResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSTHResponse) ProtoMessage() {}
+
+func (x *GetSTHResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSTHResponse.ProtoReflect.Descriptor instead.
+func (*GetSTHResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_ctfe_ctapipb_ct_api_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetSTHResponse) GetTreeSize() uint64 {
+	if x != nil {
+		return x.TreeSize
+	}
+	return 0
+}
+
+func (x *GetSTHResponse) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *GetSTHResponse) GetSha256RootHash() []byte {
+	if x != nil {
+		return x.Sha256RootHash
+	}
+	return nil
+}
+
+func (x *GetSTHResponse) GetTreeHeadSignature() []byte {
+	if x != nil {
+		return x.TreeHeadSignature
+	}
+	return nil
+}
+
+type GetSTHConsistencyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LogPrefix string `protobuf:"bytes,1,opt,name=log_prefix,json=logPrefix,proto3" json:"log_prefix,omitempty"`
+	First     int64  `protobuf:"varint,2,opt,name=first,proto3" json:"first,omitempty"`
+	Second    int64  `protobuf:"varint,3,opt,name=second,proto3" json:"second,omitempty"`
+}
+
+func (x *GetSTHConsistencyRequest) Reset() {
+	*x = GetSTHConsistencyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSTHConsistencyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSTHConsistencyRequest) ProtoMessage() {}
+
+func (x *GetSTHConsistencyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSTHConsistencyRequest.ProtoReflect.Descriptor instead.
+func (*GetSTHConsistencyRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_ctfe_ctapipb_ct_api_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetSTHConsistencyRequest) GetLogPrefix() string {
+	if x != nil {
+		return x.LogPrefix
+	}
+	return ""
+}
+
+func (x *GetSTHConsistencyRequest) GetFirst() int64 {
+	if x != nil {
+		return x.First
+	}
+	return 0
+}
+
+func (x *GetSTHConsistencyRequest) GetSecond() int64 {
+	if x != nil {
+		return x.Second
+	}
+	return 0
+}
+
+type GetSTHConsistencyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Consistency [][]byte `protobuf:"bytes,1,rep,name=consistency,proto3" json:"consistency,omitempty"`
+}
+
+func (x *GetSTHConsistencyResponse) Reset() {
+	*x = GetSTHConsistencyResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSTHConsistencyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSTHConsistencyResponse) ProtoMessage() {}
+
+func (x *GetSTHConsistencyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSTHConsistencyResponse.ProtoReflect.Descriptor instead.
+func (*GetSTHConsistencyResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_ctfe_ctapipb_ct_api_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetSTHConsistencyResponse) GetConsistency() [][]byte {
+	if x != nil {
+		return x.Consistency
+	}
+	return nil
+}
+
+type GetProofByHashRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LogPrefix string `protobuf:"bytes,1,opt,name=log_prefix,json=logPrefix,proto3" json:"log_prefix,omitempty"`
+	LeafHash  []byte `protobuf:"bytes,2,opt,name=leaf_hash,json=leafHash,proto3" json:"leaf_hash,omitempty"`
+	TreeSize  int64  `protobuf:"varint,3,opt,name=tree_size,json=treeSize,proto3" json:"tree_size,omitempty"`
+}
+
+func (x *GetProofByHashRequest) Reset() {
+	*x = GetProofByHashRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetProofByHashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProofByHashRequest) ProtoMessage() {}
+
+func (x *GetProofByHashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProofByHashRequest.ProtoReflect.Descriptor instead.
+func (*GetProofByHashRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_ctfe_ctapipb_ct_api_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetProofByHashRequest) GetLogPrefix() string {
+	if x != nil {
+		return x.LogPrefix
+	}
+	return ""
+}
+
+func (x *GetProofByHashRequest) GetLeafHash() []byte {
+	if x != nil {
+		return x.LeafHash
+	}
+	return nil
+}
+
+func (x *GetProofByHashRequest) GetTreeSize() int64 {
+	if x != nil {
+		return x.TreeSize
+	}
+	return 0
+}
+
+type GetProofByHashResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LeafIndex int64    `protobuf:"varint,1,opt,name=leaf_index,json=leafIndex,proto3" json:"leaf_index,omitempty"`
+	AuditPath [][]byte `protobuf:"bytes,2,rep,name=audit_path,json=auditPath,proto3" json:"audit_path,omitempty"`
+}
+
+func (x *GetProofByHashResponse) Reset() {
+	*x = GetProofByHashResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetProofByHashResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProofByHashResponse) ProtoMessage() {}
+
+func (x *GetProofByHashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProofByHashResponse.ProtoReflect.Descriptor instead.
+func (*GetProofByHashResponse) Descriptor() ([]byte, []int) {
+	return file_trillian_ctfe_ctapipb_ct_api_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetProofByHashResponse) GetLeafIndex() int64 {
+	if x != nil {
+		return x.LeafIndex
+	}
+	return 0
+}
+
+func (x *GetProofByHashResponse) GetAuditPath() [][]byte {
+	if x != nil {
+		return x.AuditPath
+	}
+	return nil
+}
+
+type GetEntriesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LogPrefix string `protobuf:"bytes,1,opt,name=log_prefix,json=logPrefix,proto3" json:"log_prefix,omitempty"`
+	// start and end are the inclusive bounds of the range of entries.
+	Start int64 `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
+	End   int64 `protobuf:"varint,3,opt,name=end,proto3" json:"end,omitempty"`
+}
+
+func (x *GetEntriesRequest) Reset() {
+	*x = GetEntriesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEntriesRequest) ProtoMessage() {}
+
+func (x *GetEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEntriesRequest.ProtoReflect.Descriptor instead.
+func (*GetEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_trillian_ctfe_ctapipb_ct_api_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetEntriesRequest) GetLogPrefix() string {
+	if x != nil {
+		return x.LogPrefix
+	}
+	return ""
+}
+
+func (x *GetEntriesRequest) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *GetEntriesRequest) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+type LogEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index int64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// leaf_input is the TLS-encoded MerkleTreeLeaf.
+	LeafInput []byte `protobuf:"bytes,2,opt,name=leaf_input,json=leafInput,proto3" json:"leaf_input,omitempty"`
+	ExtraData []byte `protobuf:"bytes,3,opt,name=extra_data,json=extraData,proto3" json:"extra_data,omitempty"`
+}
+
+func (x *LogEntry) Reset() {
+	*x = LogEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogEntry) ProtoMessage() {}
+
+func (x *LogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
+func (*LogEntry) Descriptor() ([]byte, []int) {
+	return file_trillian_ctfe_ctapipb_ct_api_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LogEntry) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *LogEntry) GetLeafInput() []byte {
+	if x != nil {
+		return x.LeafInput
+	}
+	return nil
+}
+
+func (x *LogEntry) GetExtraData() []byte {
+	if x != nil {
+		return x.ExtraData
+	}
+	return nil
+}
+
+var File_trillian_ctfe_ctapipb_ct_api_proto protoreflect.FileDescriptor
+
+var file_trillian_ctfe_ctapipb_ct_api_proto_rawDesc = []byte{
+	0x0a, 0x22, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61, 0x6e, 0x2f, 0x63, 0x74, 0x66, 0x65, 0x2f,
+	0x63, 0x74, 0x61, 0x70, 0x69, 0x70, 0x62, 0x2f, 0x63, 0x74, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x63, 0x74, 0x61, 0x70, 0x69, 0x70, 0x62, 0x22, 0x46, 0x0a,
+	0x0f, 0x41, 0x64, 0x64, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x67, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x6f, 0x67, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12,
+	0x14, 0x0a, 0x05, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x05,
+	0x63, 0x68, 0x61, 0x69, 0x6e, 0x22, 0x9f, 0x01, 0x0a, 0x10, 0x41, 0x64, 0x64, 0x43, 0x68, 0x61,
+	0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x63,
+	0x74, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0a, 0x73, 0x63, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x78, 0x74,
+	0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x65,
+	0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x2e, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x53, 0x54,
+	0x48, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x67, 0x5f,
+	0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x6f,
+	0x67, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x22, 0xa5, 0x01, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x53,
+	0x54, 0x48, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x72,
+	0x65, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x74,
+	0x72, 0x65, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x28, 0x0a, 0x10, 0x73, 0x68, 0x61, 0x32, 0x35, 0x36, 0x5f,
+	0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x0e, 0x73, 0x68, 0x61, 0x32, 0x35, 0x36, 0x52, 0x6f, 0x6f, 0x74, 0x48, 0x61, 0x73, 0x68, 0x12,
+	0x2e, 0x0a, 0x13, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x5f, 0x73, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x11, 0x74, 0x72,
+	0x65, 0x65, 0x48, 0x65, 0x61, 0x64, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22,
+	0x67, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x53, 0x54, 0x48, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74,
+	0x65, 0x6e, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x6c,
+	0x6f, 0x67, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x6c, 0x6f, 0x67, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69,
+	0x72, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x66, 0x69, 0x72, 0x73, 0x74,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x06, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x22, 0x3d, 0x0a, 0x19, 0x47, 0x65, 0x74, 0x53,
+	0x54, 0x48, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74,
+	0x65, 0x6e, 0x63, 0x79, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x73,
+	0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x22, 0x70, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x50, 0x72,
+	0x6f, 0x6f, 0x66, 0x42, 0x79, 0x48, 0x61, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x67, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x6f, 0x67, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12,
+	0x1b, 0x0a, 0x09, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x08, 0x6c, 0x65, 0x61, 0x66, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1b, 0x0a, 0x09,
+	0x74, 0x72, 0x65, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x08, 0x74, 0x72, 0x65, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x56, 0x0a, 0x16, 0x47, 0x65, 0x74,
+	0x50, 0x72, 0x6f, 0x6f, 0x66, 0x42, 0x79, 0x48, 0x61, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x69, 0x6e, 0x64, 0x65,
+	0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6c, 0x65, 0x61, 0x66, 0x49, 0x6e, 0x64,
+	0x65, 0x78, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x75, 0x64, 0x69, 0x74, 0x5f, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x09, 0x61, 0x75, 0x64, 0x69, 0x74, 0x50, 0x61, 0x74,
+	0x68, 0x22, 0x5a, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x67, 0x5f, 0x70, 0x72,
+	0x65, 0x66, 0x69, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x6f, 0x67, 0x50,
+	0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x65,
+	0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x65, 0x6e, 0x64, 0x22, 0x5e, 0x0a,
+	0x08, 0x4c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12,
+	0x1d, 0x0a, 0x0a, 0x6c, 0x65, 0x61, 0x66, 0x5f, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x09, 0x6c, 0x65, 0x61, 0x66, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x65, 0x78, 0x74, 0x72, 0x61, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x09, 0x65, 0x78, 0x74, 0x72, 0x61, 0x44, 0x61, 0x74, 0x61, 0x32, 0xc1, 0x03,
+	0x0a, 0x05, 0x43, 0x54, 0x4c, 0x6f, 0x67, 0x12, 0x41, 0x0a, 0x08, 0x41, 0x64, 0x64, 0x43, 0x68,
+	0x61, 0x69, 0x6e, 0x12, 0x18, 0x2e, 0x63, 0x74, 0x61, 0x70, 0x69, 0x70, 0x62, 0x2e, 0x41, 0x64,
+	0x64, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e,
+	0x63, 0x74, 0x61, 0x70, 0x69, 0x70, 0x62, 0x2e, 0x41, 0x64, 0x64, 0x43, 0x68, 0x61, 0x69, 0x6e,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x44, 0x0a, 0x0b, 0x41, 0x64,
+	0x64, 0x50, 0x72, 0x65, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x12, 0x18, 0x2e, 0x63, 0x74, 0x61, 0x70,
+	0x69, 0x70, 0x62, 0x2e, 0x41, 0x64, 0x64, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x63, 0x74, 0x61, 0x70, 0x69, 0x70, 0x62, 0x2e, 0x41, 0x64,
+	0x64, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
+	0x12, 0x3b, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x53, 0x54, 0x48, 0x12, 0x16, 0x2e, 0x63, 0x74, 0x61,
+	0x70, 0x69, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x54, 0x48, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x17, 0x2e, 0x63, 0x74, 0x61, 0x70, 0x69, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74,
+	0x53, 0x54, 0x48, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x5c, 0x0a,
+	0x11, 0x47, 0x65, 0x74, 0x53, 0x54, 0x48, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e,
+	0x63, 0x79, 0x12, 0x21, 0x2e, 0x63, 0x74, 0x61, 0x70, 0x69, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74,
+	0x53, 0x54, 0x48, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x63, 0x74, 0x61, 0x70, 0x69, 0x70, 0x62, 0x2e,
+	0x47, 0x65, 0x74, 0x53, 0x54, 0x48, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63,
+	0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x53, 0x0a, 0x0e, 0x47,
+	0x65, 0x74, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x42, 0x79, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1e, 0x2e,
+	0x63, 0x74, 0x61, 0x70, 0x69, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x72, 0x6f, 0x6f, 0x66,
+	0x42, 0x79, 0x48, 0x61, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e,
+	0x63, 0x74, 0x61, 0x70, 0x69, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x72, 0x6f, 0x6f, 0x66,
+	0x42, 0x79, 0x48, 0x61, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
+	0x12, 0x3f, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x1a,
+	0x2e, 0x63, 0x74, 0x61, 0x70, 0x69, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x45, 0x6e, 0x74, 0x72,
+	0x69, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x63, 0x74, 0x61,
+	0x70, 0x69, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x22, 0x00, 0x30,
+	0x01, 0x42, 0x4b, 0x5a, 0x49, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x52, 0x61, 0x72, 0x69, 0x6d, 0x6f, 0x56, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2f, 0x63, 0x65, 0x72,
+	0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x2d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x61,
+	0x72, 0x65, 0x6e, 0x63, 0x79, 0x2d, 0x67, 0x6f, 0x2f, 0x74, 0x72, 0x69, 0x6c, 0x6c, 0x69, 0x61,
+	0x6e, 0x2f, 0x63, 0x74, 0x66, 0x65, 0x2f, 0x63, 0x74, 0x61, 0x70, 0x69, 0x70, 0x62, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_trillian_ctfe_ctapipb_ct_api_proto_rawDescOnce sync.Once
+	file_trillian_ctfe_ctapipb_ct_api_proto_rawDescData = file_trillian_ctfe_ctapipb_ct_api_proto_rawDesc
+)
+
+func file_trillian_ctfe_ctapipb_ct_api_proto_rawDescGZIP() []byte {
+	file_trillian_ctfe_ctapipb_ct_api_proto_rawDescOnce.Do(func() {
+		file_trillian_ctfe_ctapipb_ct_api_proto_rawDescData = protoimpl.X.CompressGZIP(file_trillian_ctfe_ctapipb_ct_api_proto_rawDescData)
+	})
+	return file_trillian_ctfe_ctapipb_ct_api_proto_rawDescData
+}
+
+var file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_trillian_ctfe_ctapipb_ct_api_proto_goTypes = []interface{}{
+	(*AddChainRequest)(nil),           // 0: ctapipb.AddChainRequest
+	(*AddChainResponse)(nil),          // 1: ctapipb.AddChainResponse
+	(*GetSTHRequest)(nil),             // 2: ctapipb.GetSTHRequest
+	(*GetSTHResponse)(nil),            // 3: ctapipb.GetSTHResponse
+	(*GetSTHConsistencyRequest)(nil),  // 4: ctapipb.GetSTHConsistencyRequest
+	(*GetSTHConsistencyResponse)(nil), // 5: ctapipb.GetSTHConsistencyResponse
+	(*GetProofByHashRequest)(nil),     // 6: ctapipb.GetProofByHashRequest
+	(*GetProofByHashResponse)(nil),    // 7: ctapipb.GetProofByHashResponse
+	(*GetEntriesRequest)(nil),         // 8: ctapipb.GetEntriesRequest
+	(*LogEntry)(nil),                  // 9: ctapipb.LogEntry
+}
+var file_trillian_ctfe_ctapipb_ct_api_proto_depIdxs = []int32{
+	0, // 0: ctapipb.CTLog.AddChain:input_type -> ctapipb.AddChainRequest
+	0, // 1: ctapipb.CTLog.AddPreChain:input_type -> ctapipb.AddChainRequest
+	2, // 2: ctapipb.CTLog.GetSTH:input_type -> ctapipb.GetSTHRequest
+	4, // 3: ctapipb.CTLog.GetSTHConsistency:input_type -> ctapipb.GetSTHConsistencyRequest
+	6, // 4: ctapipb.CTLog.GetProofByHash:input_type -> ctapipb.GetProofByHashRequest
+	8, // 5: ctapipb.CTLog.GetEntries:input_type -> ctapipb.GetEntriesRequest
+	1, // 6: ctapipb.CTLog.AddChain:output_type -> ctapipb.AddChainResponse
+	1, // 7: ctapipb.CTLog.AddPreChain:output_type -> ctapipb.AddChainResponse
+	3, // 8: ctapipb.CTLog.GetSTH:output_type -> ctapipb.GetSTHResponse
+	5, // 9: ctapipb.CTLog.GetSTHConsistency:output_type -> ctapipb.GetSTHConsistencyResponse
+	7, // 10: ctapipb.CTLog.GetProofByHash:output_type -> ctapipb.GetProofByHashResponse
+	9, // 11: ctapipb.CTLog.GetEntries:output_type -> ctapipb.LogEntry
+	6, // [6:12] is the sub-list for method output_type
+	0, // [0:6] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_trillian_ctfe_ctapipb_ct_api_proto_init() }
+func file_trillian_ctfe_ctapipb_ct_api_proto_init() {
+	if File_trillian_ctfe_ctapipb_ct_api_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddChainRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddChainResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSTHRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSTHResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSTHConsistencyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSTHConsistencyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetProofByHashRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetProofByHashResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetEntriesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_trillian_ctfe_ctapipb_ct_api_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_trillian_ctfe_ctapipb_ct_api_proto_goTypes,
+		DependencyIndexes: file_trillian_ctfe_ctapipb_ct_api_proto_depIdxs,
+		MessageInfos:      file_trillian_ctfe_ctapipb_ct_api_proto_msgTypes,
+	}.Build()
+	File_trillian_ctfe_ctapipb_ct_api_proto = out.File
+	file_trillian_ctfe_ctapipb_ct_api_proto_rawDesc = nil
+	file_trillian_ctfe_ctapipb_ct_api_proto_goTypes = nil
+	file_trillian_ctfe_ctapipb_ct_api_proto_depIdxs = nil
+}
@@ -0,0 +1,356 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.20.1
+// source: trillian/ctfe/ctapipb/ct_api.proto
+
+package ctapipb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	CTLog_AddChain_FullMethodName          = "/ctapipb.CTLog/AddChain"
+	CTLog_AddPreChain_FullMethodName       = "/ctapipb.CTLog/AddPreChain"
+	CTLog_GetSTH_FullMethodName            = "/ctapipb.CTLog/GetSTH"
+	CTLog_GetSTHConsistency_FullMethodName = "/ctapipb.CTLog/GetSTHConsistency"
+	CTLog_GetProofByHash_FullMethodName    = "/ctapipb.CTLog/GetProofByHash"
+	CTLog_GetEntries_FullMethodName        = "/ctapipb.CTLog/GetEntries"
+)
+
+// CTLogClient is the client API for CTLog service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CTLogClient interface {
+	// AddChain submits a certificate chain, as add-chain.
+	AddChain(ctx context.Context, in *AddChainRequest, opts ...grpc.CallOption) (*AddChainResponse, error)
+	// AddPreChain submits a precertificate chain, as add-pre-chain.
+	AddPreChain(ctx context.Context, in *AddChainRequest, opts ...grpc.CallOption) (*AddChainResponse, error)
+	// GetSTH returns the log's latest signed tree head, as get-sth.
+	GetSTH(ctx context.Context, in *GetSTHRequest, opts ...grpc.CallOption) (*GetSTHResponse, error)
+	// GetSTHConsistency returns a consistency proof between two tree sizes, as
+	// get-sth-consistency.
+	GetSTHConsistency(ctx context.Context, in *GetSTHConsistencyRequest, opts ...grpc.CallOption) (*GetSTHConsistencyResponse, error)
+	// GetProofByHash returns an inclusion proof for a leaf hash, as
+	// get-proof-by-hash.
+	GetProofByHash(ctx context.Context, in *GetProofByHashRequest, opts ...grpc.CallOption) (*GetProofByHashResponse, error)
+	// GetEntries streams the entries in a range of the log. Unlike get-entries,
+	// the range is not truncated: the server fetches it from the backend in
+	// batches and streams all of the entries which are in the current tree.
+	GetEntries(ctx context.Context, in *GetEntriesRequest, opts ...grpc.CallOption) (CTLog_GetEntriesClient, error)
+}
+
+type cTLogClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCTLogClient(cc grpc.ClientConnInterface) CTLogClient {
+	return &cTLogClient{cc}
+}
+
+func (c *cTLogClient) AddChain(ctx context.Context, in *AddChainRequest, opts ...grpc.CallOption) (*AddChainResponse, error) {
+	out := new(AddChainResponse)
+	err := c.cc.Invoke(ctx, CTLog_AddChain_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cTLogClient) AddPreChain(ctx context.Context, in *AddChainRequest, opts ...grpc.CallOption) (*AddChainResponse, error) {
+	out := new(AddChainResponse)
+	err := c.cc.Invoke(ctx, CTLog_AddPreChain_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cTLogClient) GetSTH(ctx context.Context, in *GetSTHRequest, opts ...grpc.CallOption) (*GetSTHResponse, error) {
+	out := new(GetSTHResponse)
+	err := c.cc.Invoke(ctx, CTLog_GetSTH_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cTLogClient) GetSTHConsistency(ctx context.Context, in *GetSTHConsistencyRequest, opts ...grpc.CallOption) (*GetSTHConsistencyResponse, error) {
+	out := new(GetSTHConsistencyResponse)
+	err := c.cc.Invoke(ctx, CTLog_GetSTHConsistency_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cTLogClient) GetProofByHash(ctx context.Context, in *GetProofByHashRequest, opts ...grpc.CallOption) (*GetProofByHashResponse, error) {
+	out := new(GetProofByHashResponse)
+	err := c.cc.Invoke(ctx, CTLog_GetProofByHash_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cTLogClient) GetEntries(ctx context.Context, in *GetEntriesRequest, opts ...grpc.CallOption) (CTLog_GetEntriesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CTLog_ServiceDesc.Streams[0], CTLog_GetEntries_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cTLogGetEntriesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CTLog_GetEntriesClient interface {
+	Recv() (*LogEntry, error)
+	grpc.ClientStream
+}
+
+type cTLogGetEntriesClient struct {
+	grpc.ClientStream
+}
+
+func (x *cTLogGetEntriesClient) Recv() (*LogEntry, error) {
+	m := new(LogEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CTLogServer is the server API for CTLog service.
+// All implementations must embed UnimplementedCTLogServer
+// for forward compatibility
+type CTLogServer interface {
+	// AddChain submits a certificate chain, as add-chain.
+	AddChain(context.Context, *AddChainRequest) (*AddChainResponse, error)
+	// AddPreChain submits a precertificate chain, as add-pre-chain.
+	AddPreChain(context.Context, *AddChainRequest) (*AddChainResponse, error)
+	// GetSTH returns the log's latest signed tree head, as get-sth.
+	GetSTH(context.Context, *GetSTHRequest) (*GetSTHResponse, error)
+	// GetSTHConsistency returns a consistency proof between two tree sizes, as
+	// get-sth-consistency.
+	GetSTHConsistency(context.Context, *GetSTHConsistencyRequest) (*GetSTHConsistencyResponse, error)
+	// GetProofByHash returns an inclusion proof for a leaf hash, as
+	// get-proof-by-hash.
+	GetProofByHash(context.Context, *GetProofByHashRequest) (*GetProofByHashResponse, error)
+	// GetEntries streams the entries in a range of the log. Unlike get-entries,
+	// the range is not truncated: the server fetches it from the backend in
+	// batches and streams all of the entries which are in the current tree.
+	GetEntries(*GetEntriesRequest, CTLog_GetEntriesServer) error
+	mustEmbedUnimplementedCTLogServer()
+}
+
+// UnimplementedCTLogServer must be embedded to have forward compatible implementations.
+type UnimplementedCTLogServer struct {
+}
+
+func (UnimplementedCTLogServer) AddChain(context.Context, *AddChainRequest) (*AddChainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddChain not implemented")
+}
+func (UnimplementedCTLogServer) AddPreChain(context.Context, *AddChainRequest) (*AddChainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddPreChain not implemented")
+}
+func (UnimplementedCTLogServer) GetSTH(context.Context, *GetSTHRequest) (*GetSTHResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSTH not implemented")
+}
+func (UnimplementedCTLogServer) GetSTHConsistency(context.Context, *GetSTHConsistencyRequest) (*GetSTHConsistencyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSTHConsistency not implemented")
+}
+func (UnimplementedCTLogServer) GetProofByHash(context.Context, *GetProofByHashRequest) (*GetProofByHashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProofByHash not implemented")
+}
+func (UnimplementedCTLogServer) GetEntries(*GetEntriesRequest, CTLog_GetEntriesServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetEntries not implemented")
+}
+func (UnimplementedCTLogServer) mustEmbedUnimplementedCTLogServer() {}
+
+// UnsafeCTLogServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CTLogServer will
+// result in compilation errors.
+type UnsafeCTLogServer interface {
+	mustEmbedUnimplementedCTLogServer()
+}
+
+func RegisterCTLogServer(s grpc.ServiceRegistrar, srv CTLogServer) {
+	s.RegisterService(&CTLog_ServiceDesc, srv)
+}
+
+func _CTLog_AddChain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddChainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CTLogServer).AddChain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CTLog_AddChain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CTLogServer).AddChain(ctx, req.(*AddChainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CTLog_AddPreChain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddChainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CTLogServer).AddPreChain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CTLog_AddPreChain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CTLogServer).AddPreChain(ctx, req.(*AddChainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CTLog_GetSTH_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSTHRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CTLogServer).GetSTH(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CTLog_GetSTH_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CTLogServer).GetSTH(ctx, req.(*GetSTHRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CTLog_GetSTHConsistency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSTHConsistencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CTLogServer).GetSTHConsistency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CTLog_GetSTHConsistency_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CTLogServer).GetSTHConsistency(ctx, req.(*GetSTHConsistencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CTLog_GetProofByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProofByHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CTLogServer).GetProofByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CTLog_GetProofByHash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CTLogServer).GetProofByHash(ctx, req.(*GetProofByHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CTLog_GetEntries_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetEntriesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CTLogServer).GetEntries(m, &cTLogGetEntriesServer{stream})
+}
+
+type CTLog_GetEntriesServer interface {
+	Send(*LogEntry) error
+	grpc.ServerStream
+}
+
+type cTLogGetEntriesServer struct {
+	grpc.ServerStream
+}
+
+func (x *cTLogGetEntriesServer) Send(m *LogEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// CTLog_ServiceDesc is the grpc.ServiceDesc for CTLog service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CTLog_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ctapipb.CTLog",
+	HandlerType: (*CTLogServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddChain",
+			Handler:    _CTLog_AddChain_Handler,
+		},
+		{
+			MethodName: "AddPreChain",
+			Handler:    _CTLog_AddPreChain_Handler,
+		},
+		{
+			MethodName: "GetSTH",
+			Handler:    _CTLog_GetSTH_Handler,
+		},
+		{
+			MethodName: "GetSTHConsistency",
+			Handler:    _CTLog_GetSTHConsistency_Handler,
+		},
+		{
+			MethodName: "GetProofByHash",
+			Handler:    _CTLog_GetProofByHash_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetEntries",
+			Handler:       _CTLog_GetEntries_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "trillian/ctfe/ctapipb/ct_api.proto",
+}
@@ -0,0 +1,352 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"golang.org/x/net/dns/dnsmessage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// maxDNSProofHashes is the number of proof hashes returned per CT-over-DNS
+	// query, so that they fit in a single TXT string.
+	maxDNSProofHashes = 7
+	// dnsSTHTTL is the TTL of sth answers, which change as the log grows.
+	dnsSTHTTL = 60
+	// dnsProofTTL is the TTL of hash, tree and sth-consistency answers, which
+	// never change.
+	dnsProofTTL = 86400
+	// maxTXTString is the maximum length of a single TXT string.
+	maxTXTString = 255
+)
+
+var (
+	dnsOnce    sync.Once
+	dnsQueries monitoring.Counter // logid, type, rcode => value
+)
+
+// errNoSuchName is returned for queries about names which don't exist.
+var errNoSuchName = errors.New("no such name")
+
+// DNSFrontend answers CT-over-DNS queries about logs, as described in
+// https://github.com/google/certificate-transparency-rfcs/blob/master/dns/draft-ct-over-dns.md.
+// Each log is served under its own zone, which supports the following TXT
+// queries:
+//
+//	sth.<zone>                                      the latest STH
+//	<base32 leaf hash>.hash.<zone>                   the index of a leaf
+//	<start>.<leaf index>.<tree size>.tree.<zone>     an inclusion proof
+//	<start>.<first>.<second>.sth-consistency.<zone>  a consistency proof
+//
+// Proofs are returned in chunks of up to 7 hashes, beginning at the start
+// index, as raw bytes.
+type DNSFrontend struct {
+	mu    sync.RWMutex
+	zones map[string]*logInfo // zone in lower case, with trailing dot => log
+}
+
+// NewDNSFrontend creates a DNSFrontend which serves no logs.
+func NewDNSFrontend(mf monitoring.MetricFactory) *DNSFrontend {
+	dnsOnce.Do(func() {
+		dnsQueries = mf.NewCounter("dns_queries", "Number of CT-over-DNS queries", "logid", "type", "rcode")
+	})
+	return &DNSFrontend{zones: make(map[string]*logInfo)}
+}
+
+// AddLog serves queries about the given log under the given zone, e.g.
+// "yyz.ct.example.com".
+func (d *DNSFrontend) AddLog(zone string, inst *Instance) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.zones[strings.ToLower(strings.TrimSuffix(zone, "."))+"."] = inst.li
+}
+
+// Serve answers queries received on conn until the context is done or the
+// connection fails.
+func (d *DNSFrontend) Serve(ctx context.Context, conn net.PacketConn) error {
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	buf := make([]byte, 4096)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		query := append([]byte(nil), buf[:n]...)
+		go func() {
+			if rsp := d.answer(ctx, query); rsp != nil {
+				if _, err := conn.WriteTo(rsp, addr); err != nil {
+					klog.V(1).Infof("Failed to send DNS response to %v: %v", addr, err)
+				}
+			}
+		}()
+	}
+}
+
+// answer returns the response to a DNS query message, or nil if the message
+// should be dropped.
+func (d *DNSFrontend) answer(ctx context.Context, query []byte) []byte {
+	var p dnsmessage.Parser
+	hdr, err := p.Start(query)
+	if err != nil || hdr.Response {
+		return nil
+	}
+	rspHdr := dnsmessage.Header{
+		ID:               hdr.ID,
+		Response:         true,
+		OpCode:           hdr.OpCode,
+		RecursionDesired: hdr.RecursionDesired,
+	}
+	q, err := p.Question()
+	if err != nil {
+		rspHdr.RCode = dnsmessage.RCodeFormatError
+		return buildDNSResponse(rspHdr, nil, nil, 0)
+	}
+	if hdr.OpCode != 0 {
+		rspHdr.RCode = dnsmessage.RCodeNotImplemented
+		return buildDNSResponse(rspHdr, &q, nil, 0)
+	}
+
+	name := strings.ToLower(q.Name.String())
+	li, labels := d.findZone(name)
+	if li == nil {
+		rspHdr.RCode = dnsmessage.RCodeRefused
+		return buildDNSResponse(rspHdr, &q, nil, 0)
+	}
+	rspHdr.Authoritative = true
+	if len(labels) == 0 || (q.Type != dnsmessage.TypeTXT && q.Type != dnsmessage.TypeALL) {
+		// The name may exist, but has no data of the requested type.
+		return buildDNSResponse(rspHdr, &q, nil, 0)
+	}
+
+	// Label metrics by query type, but don't let arbitrary names add labels.
+	qtype := labels[len(labels)-1]
+	switch qtype {
+	case "sth", "hash", "tree", "sth-consistency":
+	default:
+		qtype = "unknown"
+	}
+	txt, ttl, err := d.lookup(ctx, li, labels)
+	switch {
+	case errors.Is(err, errNoSuchName):
+		rspHdr.RCode = dnsmessage.RCodeNameError
+	case err != nil:
+		klog.Warningf("%s: CT-over-DNS query %q failed: %v", li.LogPrefix, name, err)
+		rspHdr.RCode = dnsmessage.RCodeServerFailure
+	}
+	dnsQueries.Inc(strconv.FormatInt(li.logID, 10), qtype, rspHdr.RCode.String())
+	if err != nil {
+		return buildDNSResponse(rspHdr, &q, nil, 0)
+	}
+	return buildDNSResponse(rspHdr, &q, &txt, ttl)
+}
+
+// findZone returns the log whose zone contains the given fully-qualified
+// name, and the labels of the name which precede the zone.
+func (d *DNSFrontend) findZone(name string) (*logInfo, []string) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for rest := name; len(rest) > 0; {
+		if li, ok := d.zones[rest]; ok {
+			prefix := strings.TrimSuffix(strings.TrimSuffix(name, rest), ".")
+			if len(prefix) == 0 {
+				return li, nil
+			}
+			return li, strings.Split(prefix, ".")
+		}
+		i := strings.IndexByte(rest, '.')
+		if i < 0 {
+			break
+		}
+		rest = rest[i+1:]
+	}
+	return nil, nil
+}
+
+// lookup returns the TXT data for a query about the log, whose labels
+// precede the log's zone.
+func (d *DNSFrontend) lookup(ctx context.Context, li *logInfo, labels []string) (string, uint32, error) {
+	ctx, cancel := context.WithDeadline(ctx, getRPCDeadlineTime(li))
+	defer cancel()
+
+	switch {
+	case len(labels) == 1 && labels[0] == "sth":
+		sth, err := li.getSTH(ctx)
+		if err != nil {
+			return "", 0, err
+		}
+		sig, err := tls.Marshal(sth.TreeHeadSignature)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to marshal STH signature: %v", err)
+		}
+		return fmt.Sprintf("%d.%d.%s.%s", sth.TreeSize, sth.Timestamp,
+			base64.StdEncoding.EncodeToString(sth.SHA256RootHash[:]),
+			base64.StdEncoding.EncodeToString(sig)), dnsSTHTTL, nil
+
+	case len(labels) == 2 && labels[1] == "hash":
+		leafHash, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(labels[0]))
+		if err != nil || len(leafHash) != 32 {
+			return "", 0, errNoSuchName
+		}
+		sth, err := li.getSTH(ctx)
+		if err != nil {
+			return "", 0, err
+		}
+		rsp, err := li.rpcClient.GetInclusionProofByHash(ctx, &trillian.GetInclusionProofByHashRequest{
+			LogId:           li.logID,
+			LeafHash:        leafHash,
+			TreeSize:        int64(sth.TreeSize),
+			OrderBySequence: true,
+		})
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return "", 0, errNoSuchName
+			}
+			return "", 0, fmt.Errorf("backend GetInclusionProofByHash request failed: %w", err)
+		}
+		if len(rsp.Proof) == 0 || rsp.Proof[0] == nil {
+			return "", 0, errNoSuchName
+		}
+		return strconv.FormatInt(rsp.Proof[0].LeafIndex, 10), dnsProofTTL, nil
+
+	case len(labels) == 4 && labels[3] == "tree":
+		start, index, size, err := parseDNSInts(labels[:3])
+		if err != nil || index >= size {
+			return "", 0, errNoSuchName
+		}
+		rsp, err := li.rpcClient.GetInclusionProof(ctx, &trillian.GetInclusionProofRequest{
+			LogId:     li.logID,
+			LeafIndex: index,
+			TreeSize:  size,
+		})
+		if err != nil {
+			return "", 0, fmt.Errorf("backend GetInclusionProof request failed: %w", err)
+		}
+		if rsp.Proof == nil {
+			return "", 0, errNoSuchName
+		}
+		return proofChunk(rsp.Proof.Hashes, start)
+
+	case len(labels) == 4 && labels[3] == "sth-consistency":
+		start, first, second, err := parseDNSInts(labels[:3])
+		if err != nil || first <= 0 || first > second {
+			return "", 0, errNoSuchName
+		}
+		rsp, err := li.rpcClient.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{
+			LogId:          li.logID,
+			FirstTreeSize:  first,
+			SecondTreeSize: second,
+		})
+		if err != nil {
+			return "", 0, fmt.Errorf("backend GetConsistencyProof request failed: %w", err)
+		}
+		if rsp.Proof == nil {
+			return "", 0, errNoSuchName
+		}
+		return proofChunk(rsp.Proof.Hashes, start)
+	}
+	return "", 0, errNoSuchName
+}
+
+// parseDNSInts parses three non-negative decimal labels.
+func parseDNSInts(labels []string) (int64, int64, int64, error) {
+	var vals [3]int64
+	for i, l := range labels {
+		v, err := strconv.ParseInt(l, 10, 64)
+		if err != nil || v < 0 {
+			return 0, 0, 0, fmt.Errorf("bad number %q", l)
+		}
+		vals[i] = v
+	}
+	return vals[0], vals[1], vals[2], nil
+}
+
+// proofChunk returns up to maxDNSProofHashes hashes of a proof, beginning
+// with the one at index start, concatenated.
+func proofChunk(hashes [][]byte, start int64) (string, uint32, error) {
+	if start > int64(len(hashes)) {
+		return "", 0, errNoSuchName
+	}
+	end := start + maxDNSProofHashes
+	if end > int64(len(hashes)) {
+		end = int64(len(hashes))
+	}
+	var b strings.Builder
+	for _, h := range hashes[start:end] {
+		b.Write(h)
+	}
+	return b.String(), dnsProofTTL, nil
+}
+
+// buildDNSResponse builds a response with the given header and question, and
+// the given TXT data as its answer if it is not nil.
+func buildDNSResponse(hdr dnsmessage.Header, q *dnsmessage.Question, txt *string, ttl uint32) []byte {
+	b := dnsmessage.NewBuilder(make([]byte, 0, 512), hdr)
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil
+	}
+	if q != nil {
+		if err := b.Question(*q); err != nil {
+			return nil
+		}
+	}
+	if txt != nil {
+		if err := b.StartAnswers(); err != nil {
+			return nil
+		}
+		// Data longer than a single TXT string is split across several, which
+		// clients concatenate.
+		var strs []string
+		for s := *txt; ; s = s[maxTXTString:] {
+			if len(s) <= maxTXTString {
+				strs = append(strs, s)
+				break
+			}
+			strs = append(strs, s[:maxTXTString])
+		}
+		rh := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: ttl}
+		if err := b.TXTResource(rh, dnsmessage.TXTResource{TXT: strs}); err != nil {
+			klog.Warningf("Failed to build DNS answer: %v", err)
+			return nil
+		}
+	}
+	rsp, err := b.Finish()
+	if err != nil {
+		klog.Warningf("Failed to build DNS response: %v", err)
+		return nil
+	}
+	return rsp
+}
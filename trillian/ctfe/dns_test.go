@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"bytes"
+	"context"
+	"encoding/base32"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"golang.org/x/net/dns/dnsmessage"
+
+	cttestonly "github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/testonly"
+)
+
+// dnsQuery sends a TXT query for name to the frontend, and returns the
+// response code and TXT data.
+func dnsQuery(t *testing.T, d *DNSFrontend, name string) (dnsmessage.RCode, string) {
+	t.Helper()
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: 7, RecursionDesired: true})
+	if err := b.StartQuestions(); err != nil {
+		t.Fatalf("StartQuestions()=%v", err)
+	}
+	if err := b.Question(dnsmessage.Question{Name: dnsmessage.MustNewName(name), Type: dnsmessage.TypeTXT, Class: dnsmessage.ClassINET}); err != nil {
+		t.Fatalf("Question()=%v", err)
+	}
+	query, err := b.Finish()
+	if err != nil {
+		t.Fatalf("Finish()=%v", err)
+	}
+
+	var msg dnsmessage.Message
+	if err := msg.Unpack(d.answer(context.Background(), query)); err != nil {
+		t.Fatalf("failed to parse response to %s: %v", name, err)
+	}
+	if msg.ID != 7 || !msg.Response {
+		t.Errorf("response to %s has ID %d, Response %v; want 7, true", name, msg.ID, msg.Response)
+	}
+	var txt strings.Builder
+	for _, a := range msg.Answers {
+		if r, ok := a.Body.(*dnsmessage.TXTResource); ok {
+			txt.WriteString(strings.Join(r.TXT, ""))
+		}
+	}
+	return msg.RCode, txt.String()
+}
+
+func TestDNSFrontend(t *testing.T) {
+	signer, err := setupSigner(fakeSignature)
+	if err != nil {
+		t.Fatalf("Failed to create test signer: %v", err)
+	}
+	info := setupTest(t, []string{cttestonly.CACertPEM}, signer)
+	defer info.mockCtrl.Finish()
+	d := NewDNSFrontend(monitoring.InertMetricFactory{})
+	d.AddLog("Test.CT.example.com", &Instance{li: info.li})
+
+	root := makeGetRootResponseForTest(t, 12345000000, 25, []byte("abcdabcdabcdabcdabcdabcdabcdabcd"))
+	info.client.EXPECT().GetLatestSignedLogRoot(gomock.Any(), gomock.Any()).Return(root, nil).AnyTimes()
+	rcode, txt := dnsQuery(t, d, "sth.test.ct.example.com.")
+	if rcode != dnsmessage.RCodeSuccess {
+		t.Fatalf("sth query rcode=%v; want success", rcode)
+	}
+	if want := "25.12345.YWJjZGFiY2RhYmNkYWJjZGFiY2RhYmNkYWJjZGFiY2Q=.BAMABnNpZ25lZA=="; txt != want {
+		t.Errorf("sth query=%q; want %q", txt, want)
+	}
+
+	leafHash := bytes.Repeat([]byte{1}, 32)
+	info.client.EXPECT().GetInclusionProofByHash(gomock.Any(), cmpMatcher{&trillian.GetInclusionProofByHashRequest{LogId: 0x42, LeafHash: leafHash, TreeSize: 25, OrderBySequence: true}}).Return(
+		&trillian.GetInclusionProofByHashResponse{Proof: []*trillian.Proof{{LeafIndex: 17}}}, nil)
+	b32 := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(leafHash))
+	if rcode, txt := dnsQuery(t, d, b32+".hash.test.ct.example.com."); rcode != dnsmessage.RCodeSuccess || txt != "17" {
+		t.Errorf("hash query=%v, %q; want success, \"17\"", rcode, txt)
+	}
+
+	var hashes [][]byte
+	for i := 0; i < 9; i++ {
+		hashes = append(hashes, bytes.Repeat([]byte{byte(i)}, 32))
+	}
+	info.client.EXPECT().GetInclusionProof(gomock.Any(), cmpMatcher{&trillian.GetInclusionProofRequest{LogId: 0x42, LeafIndex: 17, TreeSize: 300}}).Return(
+		&trillian.GetInclusionProofResponse{Proof: &trillian.Proof{LeafIndex: 17, Hashes: hashes}}, nil).Times(2)
+	if rcode, txt := dnsQuery(t, d, "0.17.300.tree.test.ct.example.com."); rcode != dnsmessage.RCodeSuccess || txt != string(bytes.Join(hashes[:7], nil)) {
+		t.Errorf("tree query from 0=%v, %x; want success, first 7 hashes", rcode, txt)
+	}
+	if rcode, txt := dnsQuery(t, d, "7.17.300.tree.test.ct.example.com."); rcode != dnsmessage.RCodeSuccess || txt != string(bytes.Join(hashes[7:], nil)) {
+		t.Errorf("tree query from 7=%v, %x; want success, last 2 hashes", rcode, txt)
+	}
+
+	for _, tc := range []struct {
+		name string
+		want dnsmessage.RCode
+	}{
+		{name: "sth.other.example.com.", want: dnsmessage.RCodeRefused},
+		{name: "foo.test.ct.example.com.", want: dnsmessage.RCodeNameError},
+		{name: "notbase32!.hash.test.ct.example.com.", want: dnsmessage.RCodeNameError},
+		{name: "0.300.17.tree.test.ct.example.com.", want: dnsmessage.RCodeNameError},
+		{name: "test.ct.example.com.", want: dnsmessage.RCodeSuccess},
+	} {
+		if rcode, _ := dnsQuery(t, d, tc.name); rcode != tc.want {
+			t.Errorf("query %s rcode=%v; want %v", tc.name, rcode, tc.want)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/ctapipb"
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+)
+
+var (
+	grpcAPIOnce sync.Once
+	grpcReqs    monitoring.Counter   // logid, ep => value
+	grpcRsps    monitoring.Counter   // logid, ep, code => value
+	grpcLatency monitoring.Histogram // logid, ep, code => value
+)
+
+// GRPCServer implements the CTLog gRPC service for a set of logs, sharing
+// their configuration and backends with the HTTP handlers.
+type GRPCServer struct {
+	ctapipb.UnimplementedCTLogServer
+
+	mu   sync.RWMutex
+	logs map[string]*logInfo // by log prefix
+}
+
+// NewGRPCServer creates a GRPCServer which serves no logs until they are
+// added with AddLog.
+func NewGRPCServer(mf monitoring.MetricFactory) *GRPCServer {
+	grpcAPIOnce.Do(func() {
+		grpcReqs = mf.NewCounter("grpc_reqs", "Number of gRPC API requests", "logid", "ep")
+		grpcRsps = mf.NewCounter("grpc_rsps", "Number of gRPC API responses", "logid", "ep", "code")
+		grpcLatency = mf.NewHistogram("grpc_latency", "Latency of gRPC API responses in seconds", "logid", "ep", "code")
+	})
+	return &GRPCServer{logs: make(map[string]*logInfo)}
+}
+
+// AddLog serves the given log, to requests naming its prefix.
+func (s *GRPCServer) AddLog(inst *Instance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs[inst.li.instanceOpts.Validated.Config.Prefix] = inst.li
+}
+
+// call runs fn on the log with the given prefix, recording metrics and
+// converting the HTTP status it returns into a gRPC error. fn is passed an
+// HTTP request which carries the gRPC metadata as headers, so that the log's
+// quota and API key settings apply as they would to an HTTP request.
+func (s *GRPCServer) call(ctx context.Context, prefix string, ep EntrypointName, fn func(context.Context, *logInfo, *http.Request) (int, error)) error {
+	s.mu.RLock()
+	li := s.logs[prefix]
+	s.mu.RUnlock()
+	if li == nil {
+		return status.Errorf(codes.NotFound, "unknown log %q", prefix)
+	}
+
+	label0 := strconv.FormatInt(li.logID, 10)
+	grpcReqs.Inc(label0, string(ep))
+	startTime := li.TimeSource.Now()
+	ctx = li.RequestLog.Start(ctx)
+	li.RequestLog.LogPrefix(ctx, li.LogPrefix)
+	if ep != GetEntriesName {
+		// GetEntries makes as many backend requests as the range needs, and
+		// imposes the deadline on each of them instead.
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, getRPCDeadlineTime(li))
+		defer cancel()
+	}
+
+	statusCode, err := fn(ctx, li, grpcRequest(ctx))
+	li.RequestLog.Status(ctx, statusCode)
+	if err != nil {
+		klog.Warningf("%s: gRPC %s error: %v", li.LogPrefix, ep, err)
+		err = li.toGRPCError(statusCode, err)
+	}
+	code := status.Code(err).String()
+	grpcRsps.Inc(label0, string(ep), code)
+	grpcLatency.Observe(li.TimeSource.Now().Sub(startTime).Seconds(), label0, string(ep), code)
+	return err
+}
+
+// grpcRequest returns an HTTP request with the peer address and metadata of
+// the gRPC request in ctx.
+func grpcRequest(ctx context.Context) *http.Request {
+	r := &http.Request{Header: make(http.Header)}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		r.RemoteAddr = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for k, vals := range md {
+			for _, v := range vals {
+				r.Header.Add(k, v)
+			}
+		}
+	}
+	return r.WithContext(ctx)
+}
+
+// toGRPCError converts an error with the HTTP status the equivalent ct/v1
+// request would fail with to a gRPC status error.
+func (li *logInfo) toGRPCError(statusCode int, err error) error {
+	var code codes.Code
+	switch statusCode {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusPreconditionFailed:
+		code = codes.FailedPrecondition
+	case http.StatusConflict:
+		code = codes.Aborted
+	case http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		code = codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	case http.StatusGatewayTimeout:
+		code = codes.DeadlineExceeded
+	case http.StatusInternalServerError:
+		code = codes.Internal
+		if li.instanceOpts.MaskInternalErrors {
+			return status.Error(code, http.StatusText(statusCode))
+		}
+	default:
+		code = codes.Unknown
+	}
+	return status.Error(code, err.Error())
+}
+
+// AddChain implements ctapipb.CTLogServer.
+func (s *GRPCServer) AddChain(ctx context.Context, req *ctapipb.AddChainRequest) (*ctapipb.AddChainResponse, error) {
+	return s.addChain(ctx, req, false)
+}
+
+// AddPreChain implements ctapipb.CTLogServer.
+func (s *GRPCServer) AddPreChain(ctx context.Context, req *ctapipb.AddChainRequest) (*ctapipb.AddChainResponse, error) {
+	return s.addChain(ctx, req, true)
+}
+
+func (s *GRPCServer) addChain(ctx context.Context, req *ctapipb.AddChainRequest, isPrecert bool) (*ctapipb.AddChainResponse, error) {
+	ep := AddChainName
+	if isPrecert {
+		ep = AddPreChainName
+	}
+	var rsp *ctapipb.AddChainResponse
+	err := s.call(ctx, req.GetLogPrefix(), ep, func(ctx context.Context, li *logInfo, r *http.Request) (int, error) {
+		chargeTo, code, err := li.admitChain(ctx, isPrecert, r.Header.Get(authorizationHeader), li.chargeUser(r))
+		if err != nil {
+			return code, err
+		}
+		if len(req.GetChain()) == 0 {
+			return http.StatusBadRequest, errors.New("cert chain was empty")
+		}
+		sct, code, err := li.logChain(ctx, ct.AddChainRequest{Chain: req.GetChain()}, isPrecert, chargeTo)
+		if err != nil {
+			return code, err
+		}
+		logID, err := GetCTLogID(li.signer.Public())
+		if err != nil {
+			return http.StatusInternalServerError, fmt.Errorf("failed to marshal logID: %s", err)
+		}
+		sig, err := tls.Marshal(sct.Signature)
+		if err != nil {
+			return http.StatusInternalServerError, fmt.Errorf("failed to marshal signature: %s", err)
+		}
+		rsp = &ctapipb.AddChainResponse{
+			SctVersion: uint32(sct.SCTVersion),
+			Id:         logID[:],
+			Timestamp:  sct.Timestamp,
+			Extensions: sct.Extensions,
+			Signature:  sig,
+		}
+		return http.StatusOK, nil
+	})
+	return rsp, err
+}
+
+// GetSTH implements ctapipb.CTLogServer.
+func (s *GRPCServer) GetSTH(ctx context.Context, req *ctapipb.GetSTHRequest) (*ctapipb.GetSTHResponse, error) {
+	var rsp *ctapipb.GetSTHResponse
+	err := s.call(ctx, req.GetLogPrefix(), GetSTHName, func(ctx context.Context, li *logInfo, r *http.Request) (int, error) {
+		qctx := ctx
+		if li.instanceOpts.RemoteQuotaUser != nil {
+			qctx = context.WithValue(qctx, remoteQuotaCtxKey, li.instanceOpts.RemoteQuotaUser(r))
+		}
+		sth, err := li.getSTH(qctx)
+		if err != nil {
+			return li.toHTTPStatus(err), err
+		}
+		sig, err := tls.Marshal(sth.TreeHeadSignature)
+		if err != nil {
+			return http.StatusInternalServerError, fmt.Errorf("failed to tls.Marshal signature: %s", err)
+		}
+		rsp = &ctapipb.GetSTHResponse{
+			TreeSize:          sth.TreeSize,
+			Timestamp:         sth.Timestamp,
+			Sha256RootHash:    sth.SHA256RootHash[:],
+			TreeHeadSignature: sig,
+		}
+		return http.StatusOK, nil
+	})
+	return rsp, err
+}
+
+// GetSTHConsistency implements ctapipb.CTLogServer.
+func (s *GRPCServer) GetSTHConsistency(ctx context.Context, req *ctapipb.GetSTHConsistencyRequest) (*ctapipb.GetSTHConsistencyResponse, error) {
+	var rsp *ctapipb.GetSTHConsistencyResponse
+	err := s.call(ctx, req.GetLogPrefix(), GetSTHConsistencyName, func(ctx context.Context, li *logInfo, r *http.Request) (int, error) {
+		first, second := req.GetFirst(), req.GetSecond()
+		if first < 0 || second < first {
+			return http.StatusBadRequest, fmt.Errorf("invalid consistency range: %d, %d", first, second)
+		}
+		li.RequestLog.FirstAndSecond(ctx, first, second)
+		proof, code, err := li.consistencyProof(ctx, first, second, li.chargeUser(r))
+		if err != nil {
+			return code, err
+		}
+		rsp = &ctapipb.GetSTHConsistencyResponse{Consistency: proof}
+		return http.StatusOK, nil
+	})
+	return rsp, err
+}
+
+// GetProofByHash implements ctapipb.CTLogServer.
+func (s *GRPCServer) GetProofByHash(ctx context.Context, req *ctapipb.GetProofByHashRequest) (*ctapipb.GetProofByHashResponse, error) {
+	var rsp *ctapipb.GetProofByHashResponse
+	err := s.call(ctx, req.GetLogPrefix(), GetProofByHashName, func(ctx context.Context, li *logInfo, r *http.Request) (int, error) {
+		if len(req.GetLeafHash()) == 0 {
+			return http.StatusBadRequest, errors.New("missing leaf hash")
+		}
+		if req.GetTreeSize() < 1 {
+			return http.StatusBadRequest, fmt.Errorf("invalid tree size: %d", req.GetTreeSize())
+		}
+		li.RequestLog.LeafHash(ctx, req.GetLeafHash())
+		li.RequestLog.TreeSize(ctx, req.GetTreeSize())
+		proof, code, err := li.proofByHash(ctx, req.GetLeafHash(), req.GetTreeSize(), li.chargeUser(r))
+		if err != nil {
+			return code, err
+		}
+		rsp = &ctapipb.GetProofByHashResponse{LeafIndex: proof.LeafIndex, AuditPath: proof.AuditPath}
+		return http.StatusOK, nil
+	})
+	return rsp, err
+}
+
+// GetEntries implements ctapipb.CTLogServer.
+func (s *GRPCServer) GetEntries(req *ctapipb.GetEntriesRequest, stream ctapipb.CTLog_GetEntriesServer) error {
+	return s.call(stream.Context(), req.GetLogPrefix(), GetEntriesName, func(ctx context.Context, li *logInfo, r *http.Request) (int, error) {
+		start, end := req.GetStart(), req.GetEnd()
+		if start < 0 || end < start {
+			return http.StatusBadRequest, fmt.Errorf("invalid range: [%d,%d]", start, end)
+		}
+		li.RequestLog.StartAndEnd(ctx, start, end)
+		chargeTo := li.chargeUser(r)
+		for start <= end {
+			batchEnd := end
+			if batchEnd-start >= MaxGetEntriesAllowed {
+				batchEnd = start + MaxGetEntriesAllowed - 1
+			}
+			bctx, cancel := context.WithDeadline(ctx, getRPCDeadlineTime(li))
+			leaves, treeSize, code, err := li.leavesByRange(bctx, start, batchEnd, chargeTo)
+			cancel()
+			if err != nil {
+				return code, err
+			}
+			if len(leaves) == 0 {
+				return http.StatusInternalServerError, fmt.Errorf("backend returned no leaves for [%d,%d]", start, batchEnd)
+			}
+			for _, leaf := range leaves {
+				if err := stream.Send(&ctapipb.LogEntry{Index: leaf.LeafIndex, LeafInput: leaf.LeafValue, ExtraData: leaf.ExtraData}); err != nil {
+					return http.StatusInternalServerError, fmt.Errorf("failed to send entry %d: %v", leaf.LeafIndex, err)
+				}
+			}
+			start += int64(len(leaves))
+			// Stop at the end of the current tree, rather than failing once the
+			// range goes past it.
+			if last := int64(treeSize) - 1; end > last {
+				end = last
+			}
+		}
+		return http.StatusOK, nil
+	})
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/configpb"
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/ctapipb"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	cttestonly "github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/testonly"
+)
+
+// grpcTestClient serves the log in info over an in-memory connection, and
+// returns a client for it.
+func grpcTestClient(t *testing.T, info handlerTestInfo) ctapipb.CTLogClient {
+	t.Helper()
+	s := NewGRPCServer(monitoring.InertMetricFactory{})
+	s.AddLog(&Instance{li: info.li})
+	srv := grpc.NewServer()
+	ctapipb.RegisterCTLogServer(srv, s)
+	lis := bufconn.Listen(1 << 20)
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			t.Errorf("Serve()=%v", err)
+		}
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}))
+	if err != nil {
+		t.Fatalf("grpc.Dial()=%v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return ctapipb.NewCTLogClient(conn)
+}
+
+func TestGRPCGetSTH(t *testing.T) {
+	signer, err := setupSigner(fakeSignature)
+	if err != nil {
+		t.Fatalf("Failed to create test signer: %v", err)
+	}
+	info := setupTest(t, nil, signer)
+	defer info.mockCtrl.Finish()
+	client := grpcTestClient(t, info)
+	ctx := context.Background()
+
+	root := makeGetRootResponseForTest(t, 12345000000, 25, []byte("abcdabcdabcdabcdabcdabcdabcdabcd"))
+	info.client.EXPECT().GetLatestSignedLogRoot(gomock.Any(), gomock.Any()).Return(root, nil)
+	got, err := client.GetSTH(ctx, &ctapipb.GetSTHRequest{LogPrefix: "test"})
+	if err != nil {
+		t.Fatalf("GetSTH()=%v", err)
+	}
+	want := &ctapipb.GetSTHResponse{
+		TreeSize:          25,
+		Timestamp:         12345,
+		Sha256RootHash:    []byte("abcdabcdabcdabcdabcdabcdabcdabcd"),
+		TreeHeadSignature: []byte{0x04, 0x03, 0x00, 0x06, 's', 'i', 'g', 'n', 'e', 'd'},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("GetSTH() diff (-want +got):\n%s", diff)
+	}
+
+	if _, err := client.GetSTH(ctx, &ctapipb.GetSTHRequest{LogPrefix: "other"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetSTH(other)=%v; want NotFound", err)
+	}
+}
+
+func TestGRPCGetProofByHash(t *testing.T) {
+	info := setupTest(t, nil, nil)
+	defer info.mockCtrl.Finish()
+	client := grpcTestClient(t, info)
+	ctx := context.Background()
+
+	hash := []byte("0123456789abcdef0123456789abcdef")
+	info.client.EXPECT().GetInclusionProofByHash(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.NotFound, "no such leaf"))
+	if _, err := client.GetProofByHash(ctx, &ctapipb.GetProofByHashRequest{LogPrefix: "test", LeafHash: hash, TreeSize: 6}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetProofByHash()=%v; want NotFound", err)
+	}
+	if _, err := client.GetProofByHash(ctx, &ctapipb.GetProofByHashRequest{LogPrefix: "test", LeafHash: hash}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetProofByHash(no size)=%v; want InvalidArgument", err)
+	}
+}
+
+func TestGRPCGetEntries(t *testing.T) {
+	defer func(old int64) { MaxGetEntriesAllowed = old }(MaxGetEntriesAllowed)
+	MaxGetEntriesAllowed = 2
+
+	info := setupTest(t, nil, nil)
+	defer info.mockCtrl.Finish()
+	client := grpcTestClient(t, info)
+
+	leaves := func(start, count int64) []*trillian.LogLeaf {
+		var l []*trillian.LogLeaf
+		for i := start; i < start+count; i++ {
+			l = append(l, &trillian.LogLeaf{LeafIndex: i, LeafValue: []byte{byte(i)}, ExtraData: []byte("extra")})
+		}
+		return l
+	}
+	root := mustMarshalRoot(t, &types.LogRootV1{TreeSize: 5})
+	for _, start := range []int64{1, 3} {
+		info.client.EXPECT().GetLeavesByRange(gomock.Any(), cmpMatcher{&trillian.GetLeavesByRangeRequest{LogId: 0x42, StartIndex: start, Count: 2}}).Return(
+			&trillian.GetLeavesByRangeResponse{SignedLogRoot: root, Leaves: leaves(start, 2)}, nil)
+	}
+
+	// The range goes past the end of the tree, so the stream stops at the last
+	// entry.
+	stream, err := client.GetEntries(context.Background(), &ctapipb.GetEntriesRequest{LogPrefix: "test", Start: 1, End: 9})
+	if err != nil {
+		t.Fatalf("GetEntries()=%v", err)
+	}
+	var got []int64
+	for {
+		e, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv()=%v", err)
+		}
+		if e.ExtraData == nil || len(e.LeafInput) != 1 || int64(e.LeafInput[0]) != e.Index {
+			t.Errorf("Recv()=%v, inconsistent entry", e)
+		}
+		got = append(got, e.Index)
+	}
+	if want := []int64{1, 2, 3, 4}; !cmp.Equal(got, want) {
+		t.Errorf("GetEntries() indices=%v, want %v", got, want)
+	}
+}
+
+func TestGRPCAddChainRejected(t *testing.T) {
+	info := setupTest(t, []string{cttestonly.CACertPEM}, nil)
+	defer info.mockCtrl.Finish()
+	store, err := NewStaticAPIKeyStore(&configpb.APIKeyConfig{Keys: []*configpb.APIKey{{Name: "ca", KeySha256: keyHash("secret")}}})
+	if err != nil {
+		t.Fatalf("NewStaticAPIKeyStore()=%v", err)
+	}
+	info.li.instanceOpts.APIKeys = NewAPIKeyAuthenticator(store, true, monitoring.InertMetricFactory{})
+	info.li.instanceOpts.Maintenance = NewMaintenanceMode(time.Minute, monitoring.InertMetricFactory{})
+	client := grpcTestClient(t, info)
+	ctx := context.Background()
+	req := &ctapipb.AddChainRequest{LogPrefix: "test", Chain: [][]byte{[]byte("not a certificate")}}
+
+	if _, err := client.AddChain(ctx, req); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("AddChain(no key)=%v; want Unauthenticated", err)
+	}
+	keyCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret")
+	if _, err := client.AddChain(keyCtx, req); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("AddChain(bad chain)=%v; want InvalidArgument", err)
+	}
+	info.li.instanceOpts.Maintenance.Set(true)
+	if _, err := client.AddPreChain(keyCtx, req); status.Code(err) != codes.Unavailable {
+		t.Errorf("AddPreChain(maintenance)=%v; want Unavailable", err)
+	}
+}
@@ -15,15 +15,19 @@
 package ctfe
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"math"
+	"mime"
 	"net/http"
 	"strconv"
 	"strings"
@@ -55,10 +59,26 @@ const (
 	cacheControlHeader = "Cache-Control"
 	// Value for Cache-Control header when response contains immutable data, i.e. entries or proofs. Allows the response to be cached for 1 day.
 	cacheControlImmutable = "public, max-age=86400"
+	// Value for Cache-Control header for get-sth responses. The STH changes as
+	// often as the log integrates new entries, so caches hold it only briefly.
+	cacheControlSTH = "public, max-age=10"
+	// Value for Cache-Control header for get-roots responses. The roots only
+	// change when the log's config does.
+	cacheControlRoots = "public, max-age=3600"
+	// HTTP Authorization header
+	authorizationHeader = "Authorization"
+	// HTTP ETag header
+	etagHeader = "ETag"
+	// HTTP If-None-Match header
+	ifNoneMatchHeader = "If-None-Match"
 	// HTTP content type header
 	contentTypeHeader string = "Content-Type"
+	// HTTP Retry-After header
+	retryAfterHeader = "Retry-After"
 	// MIME content type for JSON
 	contentTypeJSON string = "application/json"
+	// MIME content type for a PEM certificate chain, as defined in RFC 8555
+	contentTypePEMChain = "application/pem-certificate-chain"
 	// The name of the JSON response map key in get-roots responses
 	jsonMapKeyCertificates string = "certificates"
 	// The name of the get-entries start parameter
@@ -101,6 +121,7 @@ const (
 	GetEntriesName        = EntrypointName("GetEntries")
 	GetRootsName          = EntrypointName("GetRoots")
 	GetEntryAndProofName  = EntrypointName("GetEntryAndProof")
+	GetCheckpointName     = EntrypointName("GetCheckpoint")
 )
 
 var (
@@ -119,6 +140,7 @@ var (
 	rspsCounter        monitoring.Counter   // logid, ep, rc => value
 	rspLatency         monitoring.Histogram // logid, ep, rc => value
 	alignedGetEntries  monitoring.Counter   // logid, aligned => count
+	chainRejections    monitoring.Counter   // logid, ep, reason => count
 )
 
 // setupMetrics initializes all the exported metrics.
@@ -135,10 +157,11 @@ func setupMetrics(mf monitoring.MetricFactory) {
 	rspsCounter = mf.NewCounter("http_rsps", "Number of responses", "logid", "ep", "rc")
 	rspLatency = mf.NewHistogram("http_latency", "Latency of responses in seconds", "logid", "ep", "rc")
 	alignedGetEntries = mf.NewCounter("aligned_get_entries", "Number of get-entries requests which were aligned to size limit boundaries", "logid", "aligned")
+	chainRejections = mf.NewCounter("chain_rejections", "Number of submitted chains rejected, by cause", "logid", "ep", "reason")
 }
 
 // Entrypoints is a list of entrypoint names as exposed in statistics/logging.
-var Entrypoints = []EntrypointName{AddChainName, AddPreChainName, GetSTHName, GetSTHConsistencyName, GetProofByHashName, GetEntriesName, GetRootsName, GetEntryAndProofName}
+var Entrypoints = []EntrypointName{AddChainName, AddPreChainName, GetSTHName, GetSTHConsistencyName, GetProofByHashName, GetEntriesName, GetRootsName, GetEntryAndProofName, GetCheckpointName}
 
 // PathHandlers maps from a path to the relevant AppHandler instance.
 type PathHandlers map[string]AppHandler
@@ -196,18 +219,67 @@ func (a AppHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	rspsCounter.Inc(label0, label1, strconv.Itoa(statusCode))
 	if err != nil {
 		klog.Warningf("%s: %s handler error: %v", a.Info.LogPrefix, a.Name, err)
+		if d, ok := retryAfter(err); ok {
+			// Round up so that clients never retry too early.
+			w.Header().Set(retryAfterHeader, strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10))
+		}
 		a.Info.SendHTTPError(w, statusCode, err)
 		return
 	}
 
-	// Additional check, for consistency the handler must return an error for non-200 st
-	if statusCode != http.StatusOK {
+	// Additional check, for consistency the handler must return an error for
+	// non-200 st, other than a 304 for a conditional request.
+	if statusCode != http.StatusOK && statusCode != http.StatusNotModified {
 		klog.Warningf("%s: %s handler non 200 without error: %d %v", a.Info.LogPrefix, a.Name, statusCode, err)
 		a.Info.SendHTTPError(w, http.StatusInternalServerError, fmt.Errorf("http handler misbehaved, st: %d", statusCode))
 		return
 	}
 }
 
+// notModified sets the ETag and Cache-Control headers of a response, and
+// reports whether the request's If-None-Match header matches the entity tag,
+// in which case it has written a 304 (Not Modified) response.
+func notModified(w http.ResponseWriter, r *http.Request, etag, cacheControl string) bool {
+	w.Header().Set(etagHeader, etag)
+	w.Header().Set(cacheControlHeader, cacheControl)
+	// Entity tags are compared weakly, as for If-None-Match in RFC 9110.
+	want := strings.TrimPrefix(etag, "W/")
+	for _, v := range r.Header.Values(ifNoneMatchHeader) {
+		for _, tag := range strings.Split(v, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == want {
+				w.WriteHeader(http.StatusNotModified)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rootsSHA256 returns the SHA-256 hash of the concatenated DER encodings of
+// the given roots, in the order returned by get-roots.
+func rootsSHA256(roots *x509util.PEMCertPool) []byte {
+	h := sha256.New()
+	for _, cert := range roots.RawCertificates() {
+		h.Write(cert.Raw)
+	}
+	return h.Sum(nil)
+}
+
+// retryAfter returns how long a client should wait before retrying a request
+// which failed with err, if that is known.
+func retryAfter(err error) (time.Duration, bool) {
+	var bue *BackendUnavailableError
+	if errors.As(err, &bue) {
+		return bue.RetryAfter, true
+	}
+	var me *MaintenanceError
+	if errors.As(err, &me) {
+		return me.RetryAfter, true
+	}
+	return 0, false
+}
+
 // CertValidationOpts contains various parameters for certificate chain validation
 type CertValidationOpts struct {
 	// trustedRoots is a pool of certificates that defines the roots the CT log will accept
@@ -232,6 +304,22 @@ type CertValidationOpts struct {
 	extKeyUsages []x509.ExtKeyUsage
 	// rejectExtIds contains a list of X.509 extension IDs to reject during chain verification.
 	rejectExtIds []asn1.ObjectIdentifier
+	// maxChainLength, if positive, is the maximum number of certificates in
+	// the verified chain, including the root.
+	maxChainLength int
+	// requireServerAuthPath requires the leaf and every intermediate on the
+	// verified chain to allow the ServerAuth EKU.
+	requireServerAuthPath bool
+	// rejectBridgedChains rejects chains whose verified path goes through a
+	// cross-signed version of a trusted root.
+	rejectBridgedChains bool
+	// rejectExpiredIntermediates rejects chains with an expired intermediate.
+	rejectExpiredIntermediates bool
+	// maxNotBeforeFuture, if not nil, is how far in the future a certificate's
+	// NotBefore may be.
+	maxNotBeforeFuture *time.Duration
+	// clockSkew is the tolerance applied to validity period checks.
+	clockSkew time.Duration
 }
 
 // NewCertValidationOpts builds validation options based on parameters.
@@ -283,6 +371,9 @@ func newLogInfo(
 	cfg := vCfg.Config
 
 	logID, prefix := cfg.LogId, cfg.Prefix
+	if len(cfg.VirtualHost) > 0 {
+		prefix = cfg.VirtualHost + "/" + prefix
+	}
 	li := &logInfo{
 		logID:          logID,
 		LogPrefix:      fmt.Sprintf("%s{%d}", prefix, logID),
@@ -343,6 +434,7 @@ func (li *logInfo) Handlers(prefix string) PathHandlers {
 		prefix + ct.GetEntriesPath:        AppHandler{Info: li, Handler: getEntries, Name: GetEntriesName, Method: http.MethodGet},
 		prefix + ct.GetRootsPath:          AppHandler{Info: li, Handler: getRoots, Name: GetRootsName, Method: http.MethodGet},
 		prefix + ct.GetEntryAndProofPath:  AppHandler{Info: li, Handler: getEntryAndProof, Name: GetEntryAndProofName, Method: http.MethodGet},
+		prefix + CheckpointPath:           AppHandler{Info: li, Handler: getCheckpoint, Name: GetCheckpointName, Method: http.MethodGet},
 	}
 	// Remove endpoints not provided by readonly logs and mirrors.
 	if li.instanceOpts.Validated.Config.IsReadonly || li.instanceOpts.Validated.Config.IsMirror {
@@ -355,6 +447,11 @@ func (li *logInfo) Handlers(prefix string) PathHandlers {
 
 // SendHTTPError generates a custom error page to give more information on why something didn't work
 func (li *logInfo) SendHTTPError(w http.ResponseWriter, statusCode int, err error) {
+	var cve *ChainValidationError
+	if li.instanceOpts.ChainDiagnostics && errors.As(err, &cve) {
+		li.sendChainDiagnosis(w, statusCode, err, cve)
+		return
+	}
 	errorBody := http.StatusText(statusCode)
 	if !li.instanceOpts.MaskInternalErrors || statusCode != http.StatusInternalServerError {
 		errorBody += fmt.Sprintf("\n%v", err)
@@ -362,6 +459,30 @@ func (li *logInfo) SendHTTPError(w http.ResponseWriter, statusCode int, err erro
 	http.Error(w, errorBody, statusCode)
 }
 
+// chainDiagnosis is the JSON error body for a rejected chain when
+// InstanceOptions.ChainDiagnostics is set.
+type chainDiagnosis struct {
+	Error     string `json:"error"`
+	Reason    string `json:"reason"`
+	CertIndex int    `json:"cert_index"`
+}
+
+// sendChainDiagnosis writes an error response describing why a chain was
+// rejected as a JSON chainDiagnosis.
+func (li *logInfo) sendChainDiagnosis(w http.ResponseWriter, statusCode int, err error, cve *ChainValidationError) {
+	body, jsonErr := json.Marshal(chainDiagnosis{Error: err.Error(), Reason: cve.Reason, CertIndex: cve.CertIndex})
+	if jsonErr != nil {
+		http.Error(w, fmt.Sprintf("%s\n%v", http.StatusText(statusCode), err), statusCode)
+		return
+	}
+	w.Header().Set(contentTypeHeader, contentTypeJSON)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(body); err != nil {
+		klog.Warningf("%s: failed to write chain diagnosis: %v", li.LogPrefix, err)
+	}
+}
+
 // getSTH returns the current STH as known to the STH getter, and updates tree
 // size / timestamp metrics correspondingly.
 func (li *logInfo) getSTH(ctx context.Context) (*ct.SignedTreeHead, error) {
@@ -398,6 +519,51 @@ func ParseBodyAsJSONChain(r *http.Request) (ct.AddChainRequest, error) {
 	return req, nil
 }
 
+// ParseBodyAsPEMChain tries to extract cert-chain out of a request whose body
+// is a sequence of PEM CERTIFICATE blocks, as output by openssl.
+func ParseBodyAsPEMChain(r *http.Request) (ct.AddChainRequest, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		klog.V(1).Infof("Failed to read request body: %v", err)
+		return ct.AddChainRequest{}, err
+	}
+
+	var req ct.AddChainRequest
+	rest := body
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return ct.AddChainRequest{}, fmt.Errorf("unexpected PEM block type %q", block.Type)
+		}
+		req.Chain = append(req.Chain, block.Bytes)
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return ct.AddChainRequest{}, errors.New("trailing data after PEM certificates")
+	}
+
+	// The cert chain is not allowed to be empty. We'll defer other validation for later
+	if len(req.Chain) == 0 {
+		klog.V(1).Infof("Request chain is empty: %q", body)
+		return ct.AddChainRequest{}, errors.New("cert chain was empty")
+	}
+
+	return req, nil
+}
+
+// parseAddChainBody extracts the cert-chain from an add-chain or
+// add-pre-chain request, which is a PEM chain if its Content-Type says so,
+// and JSON otherwise.
+func parseAddChainBody(r *http.Request) (ct.AddChainRequest, error) {
+	if mt, _, err := mime.ParseMediaType(r.Header.Get(contentTypeHeader)); err == nil && mt == contentTypePEMChain {
+		return ParseBodyAsPEMChain(r)
+	}
+	return ParseBodyAsJSONChain(r)
+}
+
 // appendUserCharge adds the specified user to the passed in ChargeTo and
 // and returns the result.
 // If the passed-in ChargeTo is nil, then a new one is created with the passed
@@ -419,9 +585,35 @@ func (li *logInfo) chargeUser(r *http.Request) *trillian.ChargeTo {
 	return nil
 }
 
-// addChainInternal is called by add-chain and add-pre-chain as the logic involved in
-// processing these requests is almost identical
-func addChainInternal(ctx context.Context, li *logInfo, w http.ResponseWriter, r *http.Request, isPrecert bool) (int, error) {
+// admitChain checks that a chain may be submitted to the log, before looking
+// at the chain itself, and returns the quota users to charge for it. auth is
+// the submitter's Authorization header, and remote the charge for their
+// address.
+func (li *logInfo) admitChain(ctx context.Context, isPrecert bool, auth string, remote *trillian.ChargeTo) (*trillian.ChargeTo, int, error) {
+	if err := li.instanceOpts.Maintenance.check(); err != nil {
+		return nil, http.StatusServiceUnavailable, err
+	}
+	apiKey, code, err := li.instanceOpts.APIKeys.authenticate(ctx, auth)
+	if err != nil {
+		return nil, code, fmt.Errorf("%s: %s", li.LogPrefix, err)
+	}
+	cfg := li.instanceOpts.Validated.Config
+	if isPrecert && cfg.CertsOnly {
+		return nil, http.StatusBadRequest, fmt.Errorf("%s: log only accepts final certificates, submit them to add-chain", li.LogPrefix)
+	}
+	if !isPrecert && cfg.PrecertsOnly {
+		return nil, http.StatusBadRequest, fmt.Errorf("%s: log only accepts precertificates, submit them to add-pre-chain", li.LogPrefix)
+	}
+	if apiKey != nil {
+		// Charge the key holder rather than their (possibly shared) address.
+		return &trillian.ChargeTo{User: []string{apiKey.QuotaUser()}}, http.StatusOK, nil
+	}
+	return remote, http.StatusOK, nil
+}
+
+// logChain verifies a submitted chain, queues it in the log and returns an
+// SCT for it.
+func (li *logInfo) logChain(ctx context.Context, addChainReq ct.AddChainRequest, isPrecert bool, chargeTo *trillian.ChargeTo) (*ct.SignedCertificateTimestamp, int, error) {
 	var method EntrypointName
 	var etype ct.LogEntryType
 	if isPrecert {
@@ -432,18 +624,19 @@ func addChainInternal(ctx context.Context, li *logInfo, w http.ResponseWriter, r
 		etype = ct.X509LogEntryType
 	}
 
-	// Check the contents of the request and convert to slice of certificates.
-	addChainReq, err := ParseBodyAsJSONChain(r)
-	if err != nil {
-		return http.StatusBadRequest, fmt.Errorf("%s: failed to parse add-chain body: %s", li.LogPrefix, err)
-	}
 	// Log the DERs now because they might not parse as valid X.509.
 	for _, der := range addChainReq.Chain {
 		li.RequestLog.AddDERToChain(ctx, der)
 	}
 	chain, err := verifyAddChain(li, addChainReq, isPrecert)
 	if err != nil {
-		return http.StatusBadRequest, fmt.Errorf("failed to verify add-chain contents: %s", err)
+		reason := ChainRejectInvalidChain
+		var cve *ChainValidationError
+		if errors.As(err, &cve) {
+			reason = cve.Reason
+		}
+		chainRejections.Inc(strconv.FormatInt(li.logID, 10), string(method), reason)
+		return nil, http.StatusBadRequest, fmt.Errorf("failed to verify add-chain contents: %w", err)
 	}
 	for _, cert := range chain {
 		li.RequestLog.AddCertToChain(ctx, cert)
@@ -455,18 +648,21 @@ func addChainInternal(ctx context.Context, li *logInfo, w http.ResponseWriter, r
 	// Build the MerkleTreeLeaf that gets sent to the backend, and make a trillian.LogLeaf for it.
 	merkleLeaf, err := ct.MerkleTreeLeafFromChain(chain, etype, timeMillis)
 	if err != nil {
-		return http.StatusBadRequest, fmt.Errorf("failed to build MerkleTreeLeaf: %s", err)
+		return nil, http.StatusBadRequest, fmt.Errorf("failed to build MerkleTreeLeaf: %s", err)
 	}
+	// Any configured SCT extensions go into the leaf, as the SCT is built from
+	// (and its signature covers) the leaf's TimestampedEntry.
+	merkleLeaf.TimestampedEntry.Extensions = li.instanceOpts.Validated.Config.SctExtensions
 	leaf, err := buildLogLeafForAddChain(li, *merkleLeaf, chain, isPrecert)
 	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("failed to build LogLeaf: %s", err)
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to build LogLeaf: %s", err)
 	}
 
 	// Send the Merkle tree leaf on to the Log server.
 	req := trillian.QueueLeafRequest{
 		LogId:    li.logID,
 		Leaf:     &leaf,
-		ChargeTo: li.chargeUser(r),
+		ChargeTo: chargeTo,
 	}
 	if li.instanceOpts.CertificateQuotaUser != nil {
 		// TODO(al): ignore pre-issuers? Probably doesn't matter
@@ -479,45 +675,64 @@ func addChainInternal(ctx context.Context, li *logInfo, w http.ResponseWriter, r
 	rsp, err := li.rpcClient.QueueLeaf(ctx, &req)
 	klog.V(2).Infof("%s: %s <= grpc.QueueLeaves err=%v", li.LogPrefix, method, err)
 	if err != nil {
-		return li.toHTTPStatus(err), fmt.Errorf("backend QueueLeaves request failed: %s", err)
+		return nil, li.toHTTPStatus(err), fmt.Errorf("backend QueueLeaves request failed: %w", err)
 	}
 	if rsp == nil {
-		return http.StatusInternalServerError, errors.New("missing QueueLeaves response")
+		return nil, http.StatusInternalServerError, errors.New("missing QueueLeaves response")
 	}
 	if rsp.QueuedLeaf == nil {
-		return http.StatusInternalServerError, errors.New("QueueLeaf did not return the leaf")
+		return nil, http.StatusInternalServerError, errors.New("QueueLeaf did not return the leaf")
 	}
 
 	// Always use the returned leaf as the basis for an SCT.
 	var loggedLeaf ct.MerkleTreeLeaf
 	if rest, err := tls.Unmarshal(rsp.QueuedLeaf.Leaf.LeafValue, &loggedLeaf); err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("failed to reconstruct MerkleTreeLeaf: %s", err)
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to reconstruct MerkleTreeLeaf: %s", err)
 	} else if len(rest) > 0 {
-		return http.StatusInternalServerError, fmt.Errorf("extra data (%d bytes) on reconstructing MerkleTreeLeaf", len(rest))
+		return nil, http.StatusInternalServerError, fmt.Errorf("extra data (%d bytes) on reconstructing MerkleTreeLeaf", len(rest))
 	}
 
 	// As the Log server has definitely got the Merkle tree leaf, we can
 	// generate an SCT and respond with it.
 	sct, err := buildV1SCT(li.signer, &loggedLeaf)
 	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("failed to generate SCT: %s", err)
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to generate SCT: %s", err)
 	}
 	sctBytes, err := tls.Marshal(*sct)
 	if err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("failed to marshall SCT: %s", err)
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to marshall SCT: %s", err)
 	}
 	// We could possibly fail to issue the SCT after this but it's v. unlikely.
 	li.RequestLog.IssueSCT(ctx, sctBytes)
-	err = marshalAndWriteAddChainResponse(sct, li.signer, w)
-	if err != nil {
-		// reason is logged and http status is already set
-		return http.StatusInternalServerError, fmt.Errorf("failed to write response: %s", err)
-	}
 	klog.V(3).Infof("%s: %s <= SCT", li.LogPrefix, method)
 	if sct.Timestamp == timeMillis {
 		lastSCTTimestamp.Set(float64(sct.Timestamp), strconv.FormatInt(li.logID, 10))
 	}
+	return sct, http.StatusOK, nil
+}
+
+// addChainInternal is called by add-chain and add-pre-chain as the logic involved in
+// processing these requests is almost identical
+func addChainInternal(ctx context.Context, li *logInfo, w http.ResponseWriter, r *http.Request, isPrecert bool) (int, error) {
+	chargeTo, code, err := li.admitChain(ctx, isPrecert, r.Header.Get(authorizationHeader), li.chargeUser(r))
+	if err != nil {
+		return code, err
+	}
 
+	// Check the contents of the request and convert to slice of certificates.
+	addChainReq, err := parseAddChainBody(r)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("%s: failed to parse add-chain body: %s", li.LogPrefix, err)
+	}
+	sct, code, err := li.logChain(ctx, addChainReq, isPrecert, chargeTo)
+	if err != nil {
+		return code, err
+	}
+	err = marshalAndWriteAddChainResponse(sct, li.signer, w)
+	if err != nil {
+		// reason is logged and http status is already set
+		return http.StatusInternalServerError, fmt.Errorf("failed to write response: %s", err)
+	}
 	return http.StatusOK, nil
 }
 
@@ -539,6 +754,12 @@ func getSTH(ctx context.Context, li *logInfo, w http.ResponseWriter, r *http.Req
 	if err != nil {
 		return li.toHTTPStatus(err), err
 	}
+	// The tag is weak because the timestamp and signature of an STH for the
+	// same tree can differ.
+	etag := fmt.Sprintf(`W/"%d-%x"`, sth.TreeSize, sth.SHA256RootHash[:])
+	if notModified(w, r, etag, cacheControlSTH) {
+		return http.StatusNotModified, nil
+	}
 	if err := writeSTH(sth, w); err != nil {
 		return http.StatusInternalServerError, err
 	}
@@ -574,6 +795,46 @@ func writeSTH(sth *ct.SignedTreeHead, w http.ResponseWriter) error {
 	return nil
 }
 
+// consistencyProof returns a proof that the tree of size first is a prefix of
+// the tree of size second.
+func (li *logInfo) consistencyProof(ctx context.Context, first, second int64, chargeTo *trillian.ChargeTo) ([][]byte, int, error) {
+	if first == 0 {
+		klog.V(2).Infof("%s: GetSTHConsistency(%d, %d) starts from 0 so return empty proof", li.LogPrefix, first, second)
+		return emptyProof, http.StatusOK, nil
+	}
+	req := trillian.GetConsistencyProofRequest{
+		LogId:          li.logID,
+		FirstTreeSize:  first,
+		SecondTreeSize: second,
+		ChargeTo:       chargeTo,
+	}
+
+	klog.V(2).Infof("%s: GetSTHConsistency(%d, %d) => grpc.GetConsistencyProof %+v", li.LogPrefix, first, second, prototext.Format(&req))
+	rsp, err := li.rpcClient.GetConsistencyProof(ctx, &req)
+	klog.V(2).Infof("%s: GetSTHConsistency <= grpc.GetConsistencyProof err=%v", li.LogPrefix, err)
+	if err != nil {
+		return nil, li.toHTTPStatus(err), fmt.Errorf("backend GetConsistencyProof request failed: %w", err)
+	}
+
+	var currentRoot types.LogRootV1
+	if err := currentRoot.UnmarshalBinary(rsp.GetSignedLogRoot().GetLogRoot()); err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to unmarshal root: %v", rsp.GetSignedLogRoot().GetLogRoot())
+	}
+	// We can get here with a tree size too small to satisfy the proof.
+	if currentRoot.TreeSize < uint64(second) {
+		return nil, http.StatusBadRequest, fmt.Errorf("need tree size: %d for proof but only got: %d", second, currentRoot.TreeSize)
+	}
+
+	// Additional sanity checks, none of the hashes in the returned path should be empty
+	if !checkAuditPath(rsp.Proof.Hashes) {
+		return nil, http.StatusInternalServerError, fmt.Errorf("backend returned invalid proof: %v", rsp.Proof)
+	}
+	if rsp.Proof.Hashes == nil {
+		return emptyProof, http.StatusOK, nil
+	}
+	return rsp.Proof.Hashes, http.StatusOK, nil
+}
+
 // nolint:staticcheck
 func getSTHConsistency(ctx context.Context, li *logInfo, w http.ResponseWriter, r *http.Request) (int, error) {
 	first, second, err := parseGetSTHConsistencyRange(r)
@@ -582,43 +843,10 @@ func getSTHConsistency(ctx context.Context, li *logInfo, w http.ResponseWriter,
 	}
 	li.RequestLog.FirstAndSecond(ctx, first, second)
 	var jsonRsp ct.GetSTHConsistencyResponse
-	if first != 0 {
-		req := trillian.GetConsistencyProofRequest{
-			LogId:          li.logID,
-			FirstTreeSize:  first,
-			SecondTreeSize: second,
-			ChargeTo:       li.chargeUser(r),
-		}
-
-		klog.V(2).Infof("%s: GetSTHConsistency(%d, %d) => grpc.GetConsistencyProof %+v", li.LogPrefix, first, second, prototext.Format(&req))
-		rsp, err := li.rpcClient.GetConsistencyProof(ctx, &req)
-		klog.V(2).Infof("%s: GetSTHConsistency <= grpc.GetConsistencyProof err=%v", li.LogPrefix, err)
-		if err != nil {
-			return li.toHTTPStatus(err), fmt.Errorf("backend GetConsistencyProof request failed: %s", err)
-		}
-
-		var currentRoot types.LogRootV1
-		if err := currentRoot.UnmarshalBinary(rsp.GetSignedLogRoot().GetLogRoot()); err != nil {
-			return http.StatusInternalServerError, fmt.Errorf("failed to unmarshal root: %v", rsp.GetSignedLogRoot().GetLogRoot())
-		}
-		// We can get here with a tree size too small to satisfy the proof.
-		if currentRoot.TreeSize < uint64(second) {
-			return http.StatusBadRequest, fmt.Errorf("need tree size: %d for proof but only got: %d", second, currentRoot.TreeSize)
-		}
-
-		// Additional sanity checks, none of the hashes in the returned path should be empty
-		if !checkAuditPath(rsp.Proof.Hashes) {
-			return http.StatusInternalServerError, fmt.Errorf("backend returned invalid proof: %v", rsp.Proof)
-		}
-
-		// We got a valid response from the server. Marshal it as JSON and return it to the client
-		jsonRsp.Consistency = rsp.Proof.Hashes
-		if jsonRsp.Consistency == nil {
-			jsonRsp.Consistency = emptyProof
-		}
-	} else {
-		klog.V(2).Infof("%s: GetSTHConsistency(%d, %d) starts from 0 so return empty proof", li.LogPrefix, first, second)
-		jsonRsp.Consistency = emptyProof
+	var code int
+	jsonRsp.Consistency, code, err = li.consistencyProof(ctx, first, second, li.chargeUser(r))
+	if err != nil {
+		return code, err
 	}
 
 	w.Header().Set(cacheControlHeader, cacheControlImmutable)
@@ -637,25 +865,9 @@ func getSTHConsistency(ctx context.Context, li *logInfo, w http.ResponseWriter,
 	return http.StatusOK, nil
 }
 
-// nolint:staticcheck
-func getProofByHash(ctx context.Context, li *logInfo, w http.ResponseWriter, r *http.Request) (int, error) {
-	// Accept any non empty hash that decodes from base64 and let the backend validate it further
-	hash := r.FormValue(getProofParamHash)
-	if len(hash) == 0 {
-		return http.StatusBadRequest, errors.New("get-proof-by-hash: missing / empty hash param for get-proof-by-hash")
-	}
-	leafHash, err := base64.StdEncoding.DecodeString(hash)
-	if err != nil {
-		return http.StatusBadRequest, fmt.Errorf("get-proof-by-hash: invalid base64 hash: %s", err)
-	}
-
-	treeSize, err := strconv.ParseInt(r.FormValue(getProofParamTreeSize), 10, 64)
-	if err != nil || treeSize < 1 {
-		return http.StatusBadRequest, fmt.Errorf("get-proof-by-hash: missing or invalid tree_size: %v", r.FormValue(getProofParamTreeSize))
-	}
-	li.RequestLog.LeafHash(ctx, leafHash)
-	li.RequestLog.TreeSize(ctx, treeSize)
-
+// proofByHash returns the inclusion proof for the earliest leaf with the given
+// hash in the tree of the given size.
+func (li *logInfo) proofByHash(ctx context.Context, leafHash []byte, treeSize int64, chargeTo *trillian.ChargeTo) (*ct.GetProofByHashResponse, int, error) {
 	// Per RFC 6962 section 4.5 the API returns a single proof. This should be the lowest leaf index
 	// Because we request order by sequence and we only passed one hash then the first result is
 	// the correct proof to return
@@ -664,45 +876,71 @@ func getProofByHash(ctx context.Context, li *logInfo, w http.ResponseWriter, r *
 		LeafHash:        leafHash,
 		TreeSize:        treeSize,
 		OrderBySequence: true,
-		ChargeTo:        li.chargeUser(r),
+		ChargeTo:        chargeTo,
 	}
 	rsp, err := li.rpcClient.GetInclusionProofByHash(ctx, &req)
 	if err != nil {
-		return li.toHTTPStatus(err), fmt.Errorf("backend GetInclusionProofByHash request failed: %s", err)
+		return nil, li.toHTTPStatus(err), fmt.Errorf("backend GetInclusionProofByHash request failed: %w", err)
 	}
 
 	var currentRoot types.LogRootV1
 	if err := currentRoot.UnmarshalBinary(rsp.GetSignedLogRoot().GetLogRoot()); err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("failed to unmarshal root: %v", rsp.GetSignedLogRoot().GetLogRoot())
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to unmarshal root: %v", rsp.GetSignedLogRoot().GetLogRoot())
 	}
 	// We could fail to get the proof because the tree size that the server has
 	// is not large enough.
 	if currentRoot.TreeSize < uint64(treeSize) {
-		return http.StatusNotFound, fmt.Errorf("log returned tree size: %d but we expected: %d", currentRoot.TreeSize, treeSize)
+		return nil, http.StatusNotFound, fmt.Errorf("log returned tree size: %d but we expected: %d", currentRoot.TreeSize, treeSize)
 	}
 
 	// Additional sanity checks on the response.
 	if len(rsp.Proof) == 0 {
 		// The backend returns the STH even when there is no proof, so explicitly
 		// map this to 4xx.
-		return http.StatusNotFound, errors.New("get-proof-by-hash: backend did not return a proof")
+		return nil, http.StatusNotFound, errors.New("get-proof-by-hash: backend did not return a proof")
 	}
 	if !checkAuditPath(rsp.Proof[0].Hashes) {
-		return http.StatusInternalServerError, fmt.Errorf("get-proof-by-hash: backend returned invalid proof: %v", rsp.Proof[0])
+		return nil, http.StatusInternalServerError, fmt.Errorf("get-proof-by-hash: backend returned invalid proof: %v", rsp.Proof[0])
 	}
 
-	// All checks complete, marshal and return the response
-	proofRsp := ct.GetProofByHashResponse{
+	proofRsp := &ct.GetProofByHashResponse{
 		LeafIndex: rsp.Proof[0].LeafIndex,
 		AuditPath: rsp.Proof[0].Hashes,
 	}
 	if proofRsp.AuditPath == nil {
 		proofRsp.AuditPath = emptyProof
 	}
+	return proofRsp, http.StatusOK, nil
+}
+
+// nolint:staticcheck
+func getProofByHash(ctx context.Context, li *logInfo, w http.ResponseWriter, r *http.Request) (int, error) {
+	// Accept any non empty hash that decodes from base64 and let the backend validate it further
+	hash := r.FormValue(getProofParamHash)
+	if len(hash) == 0 {
+		return http.StatusBadRequest, errors.New("get-proof-by-hash: missing / empty hash param for get-proof-by-hash")
+	}
+	leafHash, err := base64.StdEncoding.DecodeString(hash)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("get-proof-by-hash: invalid base64 hash: %s", err)
+	}
+
+	treeSize, err := strconv.ParseInt(r.FormValue(getProofParamTreeSize), 10, 64)
+	if err != nil || treeSize < 1 {
+		return http.StatusBadRequest, fmt.Errorf("get-proof-by-hash: missing or invalid tree_size: %v", r.FormValue(getProofParamTreeSize))
+	}
+	li.RequestLog.LeafHash(ctx, leafHash)
+	li.RequestLog.TreeSize(ctx, treeSize)
+
+	proofRsp, code, err := li.proofByHash(ctx, leafHash, treeSize, li.chargeUser(r))
+	if err != nil {
+		return code, err
+	}
 
+	// All checks complete, marshal and return the response
 	w.Header().Set(cacheControlHeader, cacheControlImmutable)
 	w.Header().Set(contentTypeHeader, contentTypeJSON)
-	jsonData, err := json.Marshal(&proofRsp)
+	jsonData, err := json.Marshal(proofRsp)
 	if err != nil {
 		klog.Warningf("%s: Failed to marshal get-proof-by-hash resp: %v", li.LogPrefix, proofRsp)
 		return http.StatusInternalServerError, fmt.Errorf("failed to marshal get-proof-by-hash resp: %s", err)
@@ -717,49 +955,58 @@ func getProofByHash(ctx context.Context, li *logInfo, w http.ResponseWriter, r *
 	return http.StatusOK, nil
 }
 
-// nolint:staticcheck
-func getEntries(ctx context.Context, li *logInfo, w http.ResponseWriter, r *http.Request) (int, error) {
-	// The first job is to parse the params and make sure they're sensible. We just make
-	// sure the range is valid. We don't do an extra roundtrip to get the current tree
-	// size and prefer to let the backend handle this case
-	start, end, err := parseGetEntriesRange(r, MaxGetEntriesAllowed, li.logID)
-	if err != nil {
-		return http.StatusBadRequest, fmt.Errorf("bad range on get-entries request: %s", err)
-	}
-	li.RequestLog.StartAndEnd(ctx, start, end)
-
-	// Now make a request to the backend to get the relevant leaves
-	var leaves []*trillian.LogLeaf
+// leavesByRange fetches the leaves in the inclusive range [start, end] from
+// the backend, which may return fewer than requested. It also returns the
+// size of the tree they were fetched from.
+func (li *logInfo) leavesByRange(ctx context.Context, start, end int64, chargeTo *trillian.ChargeTo) ([]*trillian.LogLeaf, uint64, int, error) {
 	count := end + 1 - start
 	req := trillian.GetLeavesByRangeRequest{
 		LogId:      li.logID,
 		StartIndex: start,
 		Count:      count,
-		ChargeTo:   li.chargeUser(r),
+		ChargeTo:   chargeTo,
 	}
 	rsp, err := li.rpcClient.GetLeavesByRange(ctx, &req)
 	if err != nil {
-		return li.toHTTPStatus(err), fmt.Errorf("backend GetLeavesByRange request failed: %s", err)
+		return nil, 0, li.toHTTPStatus(err), fmt.Errorf("backend GetLeavesByRange request failed: %w", err)
 	}
 	var currentRoot types.LogRootV1
 	if err := currentRoot.UnmarshalBinary(rsp.GetSignedLogRoot().GetLogRoot()); err != nil {
-		return http.StatusInternalServerError, fmt.Errorf("failed to unmarshal root: %v", rsp.GetSignedLogRoot().GetLogRoot())
+		return nil, 0, http.StatusInternalServerError, fmt.Errorf("failed to unmarshal root: %v", rsp.GetSignedLogRoot().GetLogRoot())
 	}
 	if currentRoot.TreeSize <= uint64(start) {
 		// If the returned tree is too small to contain any leaves return the 4xx
 		// explicitly here.
-		return http.StatusBadRequest, fmt.Errorf("need tree size: %d to get leaves but only got: %d", start+1, currentRoot.TreeSize)
+		return nil, 0, http.StatusBadRequest, fmt.Errorf("need tree size: %d to get leaves but only got: %d", start+1, currentRoot.TreeSize)
 	}
 	// Do some sanity checks on the result.
 	if len(rsp.Leaves) > int(count) {
-		return http.StatusInternalServerError, fmt.Errorf("backend returned too many leaves: %d vs [%d,%d]", len(rsp.Leaves), start, end)
+		return nil, 0, http.StatusInternalServerError, fmt.Errorf("backend returned too many leaves: %d vs [%d,%d]", len(rsp.Leaves), start, end)
 	}
 	for i, leaf := range rsp.Leaves {
 		if leaf.LeafIndex != start+int64(i) {
-			return http.StatusInternalServerError, fmt.Errorf("backend returned unexpected leaf index: rsp.Leaves[%d].LeafIndex=%d for range [%d,%d]", i, leaf.LeafIndex, start, end)
+			return nil, 0, http.StatusInternalServerError, fmt.Errorf("backend returned unexpected leaf index: rsp.Leaves[%d].LeafIndex=%d for range [%d,%d]", i, leaf.LeafIndex, start, end)
 		}
 	}
-	leaves = rsp.Leaves
+	return rsp.Leaves, currentRoot.TreeSize, http.StatusOK, nil
+}
+
+// nolint:staticcheck
+func getEntries(ctx context.Context, li *logInfo, w http.ResponseWriter, r *http.Request) (int, error) {
+	// The first job is to parse the params and make sure they're sensible. We just make
+	// sure the range is valid. We don't do an extra roundtrip to get the current tree
+	// size and prefer to let the backend handle this case
+	start, end, err := parseGetEntriesRange(r, MaxGetEntriesAllowed, li.logID)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("bad range on get-entries request: %s", err)
+	}
+	li.RequestLog.StartAndEnd(ctx, start, end)
+
+	// Now make a request to the backend to get the relevant leaves
+	leaves, _, code, err := li.leavesByRange(ctx, start, end, li.chargeUser(r))
+	if err != nil {
+		return code, err
+	}
 
 	// Now we've checked the RPC response and it seems to be valid we need
 	// to serialize the leaves in JSON format for the HTTP response. Doing a
@@ -786,7 +1033,12 @@ func getEntries(ctx context.Context, li *logInfo, w http.ResponseWriter, r *http
 	return http.StatusOK, nil
 }
 
-func getRoots(_ context.Context, li *logInfo, w http.ResponseWriter, _ *http.Request) (int, error) {
+func getRoots(_ context.Context, li *logInfo, w http.ResponseWriter, r *http.Request) (int, error) {
+	etag := fmt.Sprintf(`"%x"`, rootsSHA256(li.validationOpts.trustedRoots))
+	if notModified(w, r, etag, cacheControlRoots) {
+		return http.StatusNotModified, nil
+	}
+
 	// Pull out the raw certificates from the parsed versions
 	rawCerts := make([][]byte, 0, len(li.validationOpts.trustedRoots.RawCertificates()))
 	for _, cert := range li.validationOpts.trustedRoots.RawCertificates() {
@@ -824,7 +1076,7 @@ func getEntryAndProof(ctx context.Context, li *logInfo, w http.ResponseWriter, r
 	}
 	rsp, err := li.rpcClient.GetEntryAndProof(ctx, &req)
 	if err != nil {
-		return li.toHTTPStatus(err), fmt.Errorf("backend GetEntryAndProof request failed: %s", err)
+		return li.toHTTPStatus(err), fmt.Errorf("backend GetEntryAndProof request failed: %w", err)
 	}
 
 	var currentRoot types.LogRootV1
@@ -882,12 +1134,12 @@ func verifyAddChain(li *logInfo, req ct.AddChainRequest, expectingPrecert bool)
 	if err != nil {
 		// We rejected it because the cert failed checks or we could not find a path to a root etc.
 		// Lots of possible causes for errors
-		return nil, fmt.Errorf("chain failed to verify: %s", err)
+		return nil, fmt.Errorf("chain failed to verify: %w", err)
 	}
 
 	isPrecert, err := IsPrecertificate(validPath[0])
 	if err != nil {
-		return nil, fmt.Errorf("precert test failed: %s", err)
+		return nil, rejectChain(ChainRejectBadPoison, 0, fmt.Errorf("precert test failed: %s", err))
 	}
 
 	// The type of the leaf must match the one the handler expects
@@ -897,7 +1149,7 @@ func verifyAddChain(li *logInfo, req ct.AddChainRequest, expectingPrecert bool)
 		} else {
 			klog.Warningf("%s: Precert (or cert with invalid CT ext) submitted as cert chain: %q", li.LogPrefix, req.Chain)
 		}
-		return nil, fmt.Errorf("cert / precert mismatch: %T", expectingPrecert)
+		return nil, rejectChain(ChainRejectWrongType, 0, fmt.Errorf("cert / precert mismatch: %T", expectingPrecert))
 	}
 
 	return validPath, nil
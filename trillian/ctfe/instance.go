@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
 	"github.com/RarimoVoting/certificate-transparency-go/schedule"
 	"github.com/RarimoVoting/certificate-transparency-go/trillian/util"
 	"github.com/RarimoVoting/certificate-transparency-go/x509"
@@ -69,6 +70,19 @@ type InstanceOptions struct {
 	// instances will use it, i.e. when IsMirror == true in the config. If it is
 	// empty then the DefaultMirrorSTHStorage will be used.
 	STHStorage MirrorSTHStorage
+	// ObservedSTHs, if set, records the STHs fetched by RunUpdateSTH, so that
+	// they survive restarts and can be audited later.
+	ObservedSTHs ctutil.STHStorage
+	// APIKeys, if set, authenticates submitters by API key. Submissions made
+	// with a key are charged quota for the key rather than RemoteQuotaUser.
+	APIKeys *APIKeyAuthenticator
+	// Maintenance, if set, allows writes to be rejected while the log is under
+	// maintenance.
+	Maintenance *MaintenanceMode
+	// ChainDiagnostics indicates if add-chain and add-pre-chain responses for
+	// rejected chains should have a JSON body giving the cause of the
+	// rejection and the offending certificate, rather than a plain text one.
+	ChainDiagnostics bool
 	// MaskInternalErrors indicates if internal server errors should be masked
 	// or returned to the user containing the full error message.
 	MaskInternalErrors bool
@@ -83,18 +97,53 @@ type Instance struct {
 }
 
 // RunUpdateSTH regularly updates the Instance STH so our metrics stay
-// up-to-date with any tree head changes that are not triggered by us.
+// up-to-date with any tree head changes that are not triggered by us. The
+// STHs are recorded in the ObservedSTHs storage of the instance, if it has
+// one.
 func (i *Instance) RunUpdateSTH(ctx context.Context, period time.Duration) {
 	c := i.li.instanceOpts.Validated.Config
+	store := i.li.instanceOpts.ObservedSTHs
+	var logID [32]byte
+	if store != nil {
+		var err error
+		if logID, err = i.ctLogID(); err != nil {
+			klog.Warningf("Not recording STHs of %v (%d): %v", c.Prefix, c.LogId, err)
+			store = nil
+		}
+	}
 	klog.Infof("Start internal get-sth operations on %v (%d)", c.Prefix, c.LogId)
 	schedule.Every(ctx, period, func(ctx context.Context) {
 		klog.V(1).Infof("Force internal get-sth for %v (%d)", c.Prefix, c.LogId)
-		if _, err := i.li.getSTH(ctx); err != nil {
+		sth, err := i.li.getSTH(ctx)
+		if err != nil {
 			klog.Warningf("Failed to retrieve STH for %v (%d): %v", c.Prefix, c.LogId, err)
+			return
+		}
+		if store != nil {
+			if err := store.AddSTH(ctx, logID, sth); err != nil {
+				klog.Warningf("Failed to record STH for %v (%d): %v", c.Prefix, c.LogId, err)
+			}
 		}
 	})
 }
 
+// ctLogID returns the CT log ID of the instance, the SHA-256 hash of its
+// public key.
+func (i *Instance) ctLogID() ([32]byte, error) {
+	pubKey := i.GetPublicKey()
+	if pubKey == nil {
+		pubKey = i.li.instanceOpts.Validated.PubKey
+	}
+	if pubKey == nil {
+		return [32]byte{}, errors.New("no public key")
+	}
+	logID, err := GetCTLogID(pubKey)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("failed to get log ID: %v", err)
+	}
+	return logID, nil
+}
+
 // GetPublicKey returns the public key from the instance's signer.
 func (i *Instance) GetPublicKey() crypto.PublicKey {
 	if i.li != nil && i.li.signer != nil {
@@ -167,6 +216,13 @@ func setUpLogInfo(ctx context.Context, opts InstanceOptions) (*logInfo, error) {
 		notAfterLimit:   vCfg.NotAfterLimit,
 		acceptOnlyCA:    cfg.AcceptOnlyCa,
 		extKeyUsages:    vCfg.KeyUsages,
+
+		maxChainLength:             int(cfg.MaxChainLength),
+		requireServerAuthPath:      cfg.RequireServerAuthPath,
+		rejectBridgedChains:        cfg.RejectBridgedChains,
+		rejectExpiredIntermediates: cfg.RejectExpiredIntermediates,
+		maxNotBeforeFuture:         vCfg.MaxNotBeforeFuture,
+		clockSkew:                  vCfg.ClockSkew,
 	}
 	var err error
 	validationOpts.rejectExtIds, err = parseOIDs(cfg.RejectExtensions)
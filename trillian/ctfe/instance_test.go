@@ -19,12 +19,15 @@ import (
 	"errors"
 	"fmt"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
 	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/configpb"
+	"github.com/golang/mock/gomock"
 	"github.com/google/trillian/crypto/keys"
 	"github.com/google/trillian/crypto/keys/pem"
 	"github.com/google/trillian/crypto/keyspb"
@@ -298,3 +301,46 @@ func TestErrorMasking(t *testing.T) {
 	}
 
 }
+
+func TestRunUpdateSTHRecordsSTHs(t *testing.T) {
+	signer, err := setupSigner(fakeSignature)
+	if err != nil {
+		t.Fatalf("Failed to create test signer: %v", err)
+	}
+	info := setupTest(t, nil, signer)
+	defer info.mockCtrl.Finish()
+	store, err := ctutil.OpenFileSTHStorage(filepath.Join(t.TempDir(), "sths.jsonl"))
+	if err != nil {
+		t.Fatalf("OpenFileSTHStorage()=%v", err)
+	}
+	defer store.Close()
+	info.li.instanceOpts.ObservedSTHs = store
+	inst := &Instance{li: info.li}
+	logID, err := GetCTLogID(signer.Public())
+	if err != nil {
+		t.Fatalf("GetCTLogID()=%v", err)
+	}
+	info.client.EXPECT().GetLatestSignedLogRoot(gomock.Any(), gomock.Any()).Return(makeGetRootResponseForTest(t, 12345000000, 25, []byte("abcdabcdabcdabcdabcdabcdabcdabcd")), nil).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		inst.RunUpdateSTH(ctx, time.Millisecond)
+		close(done)
+	}()
+	var sths []*ct.SignedTreeHead
+	for deadline := time.Now().Add(5 * time.Second); len(sths) == 0 && time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if sths, err = store.STHs(ctx, logID); err != nil {
+			t.Fatalf("STHs()=%v", err)
+		}
+	}
+	cancel()
+	<-done
+	// The same STH is only recorded once, however often it is fetched.
+	if sths, err = store.STHs(context.Background(), logID); err != nil {
+		t.Fatalf("STHs()=%v", err)
+	}
+	if len(sths) != 1 || sths[0].TreeSize != 25 || sths[0].Timestamp != 12345 {
+		t.Errorf("STHs()=%+v; want the STH of size 25 at 12345", sths)
+	}
+}
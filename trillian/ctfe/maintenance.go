@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/trillian/monitoring"
+	"k8s.io/klog/v2"
+)
+
+var (
+	maintenanceOnce  sync.Once
+	maintenanceGauge monitoring.Gauge
+)
+
+// MaintenanceMode is a switch which can be shared by log instances. While it
+// is on, their write entrypoints (add-chain and add-pre-chain) fail with HTTP
+// 503 and a Retry-After header, so that the Trillian backend can be drained,
+// while the read entrypoints keep working.
+type MaintenanceMode struct {
+	on         atomic.Bool
+	retryAfter time.Duration
+}
+
+// NewMaintenanceMode creates a MaintenanceMode which is initially off, and
+// which asks clients to retry writes after retryAfter while it is on.
+func NewMaintenanceMode(retryAfter time.Duration, mf monitoring.MetricFactory) *MaintenanceMode {
+	maintenanceOnce.Do(func() {
+		maintenanceGauge = mf.NewGauge("maintenance_mode", "Set to 1 while writes are rejected for maintenance")
+	})
+	maintenanceGauge.Set(0)
+	return &MaintenanceMode{retryAfter: retryAfter}
+}
+
+// Set turns maintenance mode on or off.
+func (m *MaintenanceMode) Set(on bool) {
+	if m.on.Swap(on) != on {
+		klog.Infof("Maintenance mode on: %v", on)
+	}
+	if on {
+		maintenanceGauge.Set(1)
+	} else {
+		maintenanceGauge.Set(0)
+	}
+}
+
+// Enabled reports whether maintenance mode is on.
+func (m *MaintenanceMode) Enabled() bool {
+	return m != nil && m.on.Load()
+}
+
+// check returns a MaintenanceError if maintenance mode is on.
+func (m *MaintenanceMode) check() error {
+	if !m.Enabled() {
+		return nil
+	}
+	return &MaintenanceError{RetryAfter: m.retryAfter}
+}
+
+// MaintenanceError is returned for write requests received in maintenance
+// mode.
+type MaintenanceError struct {
+	// RetryAfter is how long clients are asked to wait before retrying.
+	RetryAfter time.Duration
+}
+
+func (e *MaintenanceError) Error() string {
+	return fmt.Sprintf("log is in maintenance mode, retry after %v", e.RetryAfter)
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"k8s.io/klog/v2"
+)
+
+// Log states reported in LogMetadata.
+const (
+	LogStateUsable   = "usable"
+	LogStateReadonly = "readonly"
+	LogStateFrozen   = "frozen"
+	LogStateMirror   = "mirror"
+)
+
+// LogMetadata describes a log served by a CTFE, for monitors and integrators.
+// Binary fields are base64-encoded in JSON, as in the CT log list.
+type LogMetadata struct {
+	// VirtualHost is the host the log is served on, if it is restricted to one.
+	VirtualHost string `json:"virtual_host,omitempty"`
+	// Path is the URL path under which the log's ct/v1 entrypoints are served.
+	Path string `json:"path"`
+	// TreeID is the ID of the log's Trillian tree.
+	TreeID int64 `json:"tree_id"`
+	// LogID is the SHA-256 hash of the log's public key, as in SCTs.
+	LogID []byte `json:"log_id"`
+	// Key is the log's DER-encoded public key.
+	Key []byte `json:"key"`
+	// RootsSHA256 is the SHA-256 hash of the concatenated DER encodings of the
+	// log's accepted roots, in the order returned by get-roots.
+	RootsSHA256 []byte `json:"accepted_roots_sha256"`
+	// RootsCount is the number of accepted roots.
+	RootsCount int `json:"accepted_roots_count"`
+	// TemporalInterval, if set, restricts the certificates the log accepts by
+	// their NotAfter time.
+	TemporalInterval *LogTemporalInterval `json:"temporal_interval,omitempty"`
+	// MMD is the log's maximum merge delay in seconds, if configured.
+	MMD int32 `json:"mmd,omitempty"`
+	// State is one of the LogState* values.
+	State string `json:"state"`
+}
+
+// LogTemporalInterval is the range of NotAfter times which a log accepts.
+// Either end may be unset, in which case it is unbounded.
+type LogTemporalInterval struct {
+	StartInclusive *time.Time `json:"start_inclusive,omitempty"`
+	EndExclusive   *time.Time `json:"end_exclusive,omitempty"`
+}
+
+// Metadata returns a description of the log, whose ct/v1 entrypoints are
+// served under the given handler prefix (e.g. "/logs").
+func (i *Instance) Metadata(handlerPrefix string) (*LogMetadata, error) {
+	vCfg := i.li.instanceOpts.Validated
+	cfg := vCfg.Config
+	pubKey := i.GetPublicKey()
+	if pubKey == nil {
+		pubKey = vCfg.PubKey
+	}
+	if pubKey == nil {
+		return nil, errors.New("no public key")
+	}
+	der, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %v", err)
+	}
+	logID := sha256.Sum256(der)
+
+	md := &LogMetadata{
+		VirtualHost: cfg.VirtualHost,
+		Path:        strings.TrimRight(handlerPrefix, "/") + "/" + strings.Trim(cfg.Prefix, "/"),
+		TreeID:      cfg.LogId,
+		LogID:       logID[:],
+		Key:         der,
+		RootsSHA256: rootsSHA256(i.li.validationOpts.trustedRoots),
+		RootsCount:  len(i.li.validationOpts.trustedRoots.RawCertificates()),
+		MMD:         cfg.MaxMergeDelaySec,
+		State:       LogStateUsable,
+	}
+	if vCfg.NotAfterStart != nil || vCfg.NotAfterLimit != nil {
+		md.TemporalInterval = &LogTemporalInterval{StartInclusive: vCfg.NotAfterStart, EndExclusive: vCfg.NotAfterLimit}
+	}
+	switch {
+	case vCfg.FrozenSTH != nil:
+		md.State = LogStateFrozen
+	case cfg.IsReadonly:
+		md.State = LogStateReadonly
+	case cfg.IsMirror:
+		md.State = LogStateMirror
+	}
+	return md, nil
+}
+
+// LogListHandler returns a handler which serves a JSON object whose "logs"
+// field lists the given logs.
+func LogListHandler(logs []*LogMetadata) (http.Handler, error) {
+	if logs == nil {
+		logs = []*LogMetadata{}
+	}
+	body, err := json.Marshal(struct {
+		Logs []*LogMetadata `json:"logs"`
+	}{Logs: logs})
+	if err != nil {
+		return nil, err
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set(contentTypeHeader, contentTypeJSON)
+		if _, err := w.Write(body); err != nil {
+			klog.Warningf("Failed to write log list: %v", err)
+		}
+	}), nil
+}
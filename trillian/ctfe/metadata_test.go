@@ -0,0 +1,92 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cttestonly "github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/testonly"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLogList(t *testing.T) {
+	signer, err := setupSigner(fakeSignature)
+	if err != nil {
+		t.Fatalf("Failed to create test signer: %v", err)
+	}
+	info := setupTest(t, []string{cttestonly.CACertPEM}, signer)
+	defer info.mockCtrl.Finish()
+	vCfg := info.li.instanceOpts.Validated
+	vCfg.Config.MaxMergeDelaySec = 86400
+	vCfg.Config.IsReadonly = true
+	limit := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	vCfg.NotAfterLimit = &limit
+
+	md, err := (&Instance{li: info.li}).Metadata("/logs/")
+	if err != nil {
+		t.Fatalf("Metadata()=%v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey()=%v", err)
+	}
+	logID := sha256.Sum256(der)
+	roots := sha256.Sum256(info.roots.RawCertificates()[0].Raw)
+	want := &LogMetadata{
+		Path:             "/logs/test",
+		TreeID:           0x42,
+		LogID:            logID[:],
+		Key:              der,
+		RootsSHA256:      roots[:],
+		RootsCount:       1,
+		TemporalInterval: &LogTemporalInterval{EndExclusive: &limit},
+		MMD:              86400,
+		State:            LogStateReadonly,
+	}
+	if diff := cmp.Diff(want, md); diff != "" {
+		t.Errorf("Metadata() diff (-want +got):\n%s", diff)
+	}
+
+	h, err := LogListHandler([]*LogMetadata{md})
+	if err != nil {
+		t.Fatalf("LogListHandler()=%v", err)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/logs", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /logs=%d, want %d", w.Code, http.StatusOK)
+	}
+	var got struct {
+		Logs []*LogMetadata `json:"logs"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to parse log list: %v", err)
+	}
+	if diff := cmp.Diff([]*LogMetadata{want}, got.Logs); diff != "" {
+		t.Errorf("log list diff (-want +got):\n%s", diff)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/logs", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /logs=%d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	coalesceOnce      sync.Once
+	queueLeafCoalesce monitoring.Counter // logid => value
+)
+
+// CoalescingLogClient wraps a TrillianLogClient so that concurrent QueueLeaf
+// calls for the same leaf share a single backend RPC. This cuts backend load
+// when the same chain is submitted many times at once, as happens when CAs
+// retry or submit through several frontends.
+//
+// The Trillian log API has no batched equivalent of QueueLeaf, so distinct
+// leaves are still queued individually. Callers whose RPC was coalesced
+// receive the response to the first caller's RPC, which is what the backend
+// would have returned for a duplicate anyway, but they are not charged quota
+// for it. The shared RPC runs with its own deadline, detached from the
+// callers' contexts, so that a caller which gives up does not fail the others.
+type CoalescingLogClient struct {
+	trillian.TrillianLogClient
+	deadline time.Duration
+	group    singleflight.Group
+	// waiting, if set, is called when a caller starts waiting for a shared
+	// RPC. Used by tests.
+	waiting func()
+}
+
+// NewCoalescingLogClient returns a CoalescingLogClient wrapping client, whose
+// shared RPCs time out after deadline, if it is positive.
+func NewCoalescingLogClient(client trillian.TrillianLogClient, deadline time.Duration, mf monitoring.MetricFactory) *CoalescingLogClient {
+	coalesceOnce.Do(func() {
+		queueLeafCoalesce = mf.NewCounter("queue_leaf_coalesced", "Number of QueueLeaf calls which shared another call's backend RPC", "logid")
+	})
+	return &CoalescingLogClient{TrillianLogClient: client, deadline: deadline}
+}
+
+// QueueLeaf queues the leaf, sharing the backend RPC with any concurrent call
+// for a leaf with the same identity hash in the same log.
+func (c *CoalescingLogClient) QueueLeaf(ctx context.Context, req *trillian.QueueLeafRequest, opts ...grpc.CallOption) (*trillian.QueueLeafResponse, error) {
+	key := fmt.Sprintf("%d/%x", req.LogId, req.GetLeaf().GetLeafIdentityHash())
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		rctx, cancel := context.Background(), context.CancelFunc(func() {})
+		if c.deadline > 0 {
+			rctx, cancel = context.WithTimeout(rctx, c.deadline)
+		}
+		defer cancel()
+		return c.TrillianLogClient.QueueLeaf(rctx, req, opts...)
+	})
+	if c.waiting != nil {
+		c.waiting()
+	}
+	select {
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case res := <-ch:
+		if res.Shared {
+			queueLeafCoalesce.Inc(strconv.FormatInt(req.LogId, 10))
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*trillian.QueueLeafResponse), nil
+	}
+}
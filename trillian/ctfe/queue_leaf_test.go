@@ -0,0 +1,141 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctfe
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// blockingQueueClient counts QueueLeaf calls, which block until released,
+// and fail if their context is done by then.
+type blockingQueueClient struct {
+	trillian.TrillianLogClient
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingQueueClient() *blockingQueueClient {
+	return &blockingQueueClient{started: make(chan struct{}, 100), release: make(chan struct{})}
+}
+
+func (c *blockingQueueClient) QueueLeaf(ctx context.Context, req *trillian.QueueLeafRequest, _ ...grpc.CallOption) (*trillian.QueueLeafResponse, error) {
+	c.calls.Add(1)
+	c.started <- struct{}{}
+	<-c.release
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: req.Leaf}}, nil
+}
+
+// newTestCoalescingClient returns a CoalescingLogClient which signals on the
+// returned channel each time a caller starts waiting for an RPC.
+func newTestCoalescingClient(backend trillian.TrillianLogClient) (*CoalescingLogClient, <-chan struct{}) {
+	client := NewCoalescingLogClient(backend, time.Minute, monitoring.InertMetricFactory{})
+	waiting := make(chan struct{}, 100)
+	client.waiting = func() { waiting <- struct{}{} }
+	return client, waiting
+}
+
+func TestCoalescingLogClient(t *testing.T) {
+	backend := newBlockingQueueClient()
+	client, waiting := newTestCoalescingClient(backend)
+
+	const callers = 5
+	leaves := []*trillian.LogLeaf{{LeafIdentityHash: []byte("a")}, {LeafIdentityHash: []byte("b")}}
+	rsps := make([]*trillian.QueueLeafResponse, 2*callers)
+	var wg sync.WaitGroup
+	call := func(i int) {
+		defer wg.Done()
+		rsp, err := client.QueueLeaf(context.Background(), &trillian.QueueLeafRequest{LogId: 1, Leaf: leaves[i%2]})
+		if err != nil {
+			t.Errorf("QueueLeaf()=%v", err)
+		}
+		rsps[i] = rsp
+	}
+
+	// Start one call for each leaf, and wait for them to reach the backend.
+	wg.Add(2)
+	go call(0)
+	go call(1)
+	<-backend.started
+	<-backend.started
+	// The remaining calls should join the ones in flight.
+	for i := 2; i < len(rsps); i++ {
+		wg.Add(1)
+		go call(i)
+	}
+	for i := 0; i < len(rsps); i++ {
+		<-waiting
+	}
+	close(backend.release)
+	wg.Wait()
+
+	if got, want := backend.calls.Load(), int32(2); got != want {
+		t.Errorf("backend saw %d calls, want %d", got, want)
+	}
+	for i, rsp := range rsps {
+		if rsp == nil || rsp.QueuedLeaf.Leaf != leaves[i%2] {
+			t.Errorf("caller %d got response %v, want leaf %v", i, rsp, leaves[i%2])
+		}
+	}
+}
+
+func TestCoalescingLogClientCancel(t *testing.T) {
+	backend := newBlockingQueueClient()
+	client, waiting := newTestCoalescingClient(backend)
+	req := &trillian.QueueLeafRequest{LogId: 1, Leaf: &trillian.LogLeaf{LeafIdentityHash: []byte("a")}}
+
+	// The first caller starts the RPC, then gives up.
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := client.QueueLeaf(ctx, req)
+		firstErr <- err
+	}()
+	<-backend.started
+	<-waiting
+
+	secondErr := make(chan error, 1)
+	go func() {
+		_, err := client.QueueLeaf(context.Background(), req)
+		secondErr <- err
+	}()
+	<-waiting
+	cancel()
+	if got, want := status.Code(<-firstErr), codes.Canceled; got != want {
+		t.Errorf("first QueueLeaf() returned code %v, want %v", got, want)
+	}
+
+	// The shared RPC, and so the second caller, are unaffected.
+	close(backend.release)
+	if err := <-secondErr; err != nil {
+		t.Errorf("second QueueLeaf()=%v, want nil", err)
+	}
+	if got, want := backend.calls.Load(), int32(1); got != want {
+		t.Errorf("backend saw %d calls, want %d", got, want)
+	}
+}
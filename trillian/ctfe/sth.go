@@ -19,8 +19,10 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"sync"
 
 	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
 	"github.com/google/trillian"
 	"github.com/google/trillian/types"
 	"google.golang.org/protobuf/encoding/prototext"
@@ -168,3 +170,89 @@ type DefaultMirrorSTHStorage struct{}
 func (st DefaultMirrorSTHStorage) GetMirrorSTH(ctx context.Context, maxTreeSize int64) (*ct.SignedTreeHead, error) {
 	return nil, errors.New("not implemented")
 }
+
+// maxMemoryMirrorSTHs bounds the number of STHs kept by MemoryMirrorSTHStorage.
+const maxMemoryMirrorSTHs = 64
+
+// MemoryMirrorSTHStorage keeps the most recent STHs of a source log in memory,
+// so that a mirror can serve the latest one covered by its own tree while it
+// catches up. It is safe for concurrent use.
+type MemoryMirrorSTHStorage struct {
+	mu   sync.RWMutex
+	sths []*ct.SignedTreeHead // ordered by TreeSize, then Timestamp
+}
+
+// NewMemoryMirrorSTHStorage creates an empty MemoryMirrorSTHStorage.
+func NewMemoryMirrorSTHStorage() *MemoryMirrorSTHStorage {
+	return &MemoryMirrorSTHStorage{}
+}
+
+// AddSTH records an STH of the source log. The caller is responsible for
+// verifying its signature. STHs which are not newer than the latest one
+// already stored are ignored.
+func (st *MemoryMirrorSTHStorage) AddSTH(sth *ct.SignedTreeHead) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if n := len(st.sths); n > 0 {
+		last := st.sths[n-1]
+		switch {
+		case sth.TreeSize < last.TreeSize:
+			return
+		case sth.TreeSize == last.TreeSize:
+			if sth.Timestamp > last.Timestamp {
+				st.sths[n-1] = sth
+			}
+			return
+		}
+	}
+	st.sths = append(st.sths, sth)
+	if len(st.sths) > maxMemoryMirrorSTHs {
+		st.sths = st.sths[len(st.sths)-maxMemoryMirrorSTHs:]
+	}
+}
+
+// GetMirrorSTH returns the largest stored STH of TreeSize <= maxTreeSize.
+func (st *MemoryMirrorSTHStorage) GetMirrorSTH(ctx context.Context, maxTreeSize int64) (*ct.SignedTreeHead, error) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	for i := len(st.sths) - 1; i >= 0; i-- {
+		if sth := st.sths[i]; int64(sth.TreeSize) <= maxTreeSize {
+			return sth, nil
+		}
+	}
+	return nil, fmt.Errorf("no source log STH of size <= %d available yet", maxTreeSize)
+}
+
+// PersistentMirrorSTHStorage is a MirrorSTHStorage which keeps the STHs of a
+// source log in a ctutil.STHStorage, so that a mirror restarted while it
+// catches up still serves the STHs it observed before.
+type PersistentMirrorSTHStorage struct {
+	st    ctutil.STHStorage
+	logID [sha256.Size]byte
+}
+
+// NewPersistentMirrorSTHStorage creates a PersistentMirrorSTHStorage for the
+// source log with the given ID, the SHA-256 hash of its public key.
+func NewPersistentMirrorSTHStorage(st ctutil.STHStorage, logID [sha256.Size]byte) *PersistentMirrorSTHStorage {
+	return &PersistentMirrorSTHStorage{st: st, logID: logID}
+}
+
+// AddSTH records an STH of the source log. The caller is responsible for
+// verifying its signature.
+func (st *PersistentMirrorSTHStorage) AddSTH(ctx context.Context, sth *ct.SignedTreeHead) error {
+	return st.st.AddSTH(ctx, st.logID, sth)
+}
+
+// GetMirrorSTH returns the largest stored STH of TreeSize <= maxTreeSize.
+func (st *PersistentMirrorSTHStorage) GetMirrorSTH(ctx context.Context, maxTreeSize int64) (*ct.SignedTreeHead, error) {
+	sths, err := st.st.STHs(ctx, st.logID)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(sths) - 1; i >= 0; i-- {
+		if sth := sths[i]; int64(sth.TreeSize) <= maxTreeSize {
+			return sth, nil
+		}
+	}
+	return nil, fmt.Errorf("no source log STH of size <= %d available yet", maxTreeSize)
+}
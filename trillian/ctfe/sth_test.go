@@ -17,13 +17,16 @@ package ctfe
 import (
 	"context"
 	"crypto"
+	"crypto/sha256"
 	"errors"
 	"io"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 
 	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/ctutil"
 	"github.com/RarimoVoting/certificate-transparency-go/tls"
 	"github.com/RarimoVoting/certificate-transparency-go/trillian/mockclient"
 	"github.com/golang/mock/gomock"
@@ -282,6 +285,87 @@ func TestDefaultMirrorSTHStorage(t *testing.T) {
 	}
 }
 
+func TestMemoryMirrorSTHStorage(t *testing.T) {
+	st := NewMemoryMirrorSTHStorage()
+	if sth, err := st.GetMirrorSTH(context.Background(), 9999); err == nil {
+		t.Fatalf("GetMirrorSTH() on empty storage=%v, nil, want: err", sth)
+	}
+	for _, sth := range []*ct.SignedTreeHead{
+		{TreeSize: 10, Timestamp: 100},
+		{TreeSize: 20, Timestamp: 200},
+		{TreeSize: 20, Timestamp: 250}, // Newer STH of the same size.
+		{TreeSize: 15, Timestamp: 300}, // Smaller tree, ignored.
+		{TreeSize: 30, Timestamp: 400},
+	} {
+		st.AddSTH(sth)
+	}
+
+	for _, tc := range []struct {
+		maxTreeSize int64
+		wantTS      uint64
+		wantErr     bool
+	}{
+		{maxTreeSize: 5, wantErr: true},
+		{maxTreeSize: 10, wantTS: 100},
+		{maxTreeSize: 19, wantTS: 100},
+		{maxTreeSize: 25, wantTS: 250},
+		{maxTreeSize: 9999, wantTS: 400},
+	} {
+		sth, err := st.GetMirrorSTH(context.Background(), tc.maxTreeSize)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("GetMirrorSTH(%d)=%v, %v, want err: %v", tc.maxTreeSize, sth, err, tc.wantErr)
+			continue
+		}
+		if err == nil && sth.Timestamp != tc.wantTS {
+			t.Errorf("GetMirrorSTH(%d) returned STH with timestamp %d, want %d", tc.maxTreeSize, sth.Timestamp, tc.wantTS)
+		}
+	}
+}
+
+func TestPersistentMirrorSTHStorage(t *testing.T) {
+	ctx := context.Background()
+	fs, err := ctutil.OpenFileSTHStorage(filepath.Join(t.TempDir(), "sths.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+	logID := sha256.Sum256([]byte("source log key"))
+	st := NewPersistentMirrorSTHStorage(fs, logID)
+	if sth, err := st.GetMirrorSTH(ctx, 9999); err == nil {
+		t.Fatalf("GetMirrorSTH() on empty storage=%v, nil, want: err", sth)
+	}
+	for _, sth := range []*ct.SignedTreeHead{
+		{TreeSize: 10, Timestamp: 100},
+		{TreeSize: 20, Timestamp: 200},
+		{TreeSize: 20, Timestamp: 250},
+	} {
+		if err := st.AddSTH(ctx, sth); err != nil {
+			t.Fatalf("AddSTH()=%v", err)
+		}
+	}
+
+	// A storage created afresh, as after a restart, serves the same STHs.
+	st = NewPersistentMirrorSTHStorage(fs, logID)
+	for _, tc := range []struct {
+		maxTreeSize int64
+		wantTS      uint64
+		wantErr     bool
+	}{
+		{maxTreeSize: 5, wantErr: true},
+		{maxTreeSize: 19, wantTS: 100},
+		{maxTreeSize: 9999, wantTS: 250},
+	} {
+		sth, err := st.GetMirrorSTH(ctx, tc.maxTreeSize)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("GetMirrorSTH(%d)=%v, %v, want err: %v", tc.maxTreeSize, sth, err, tc.wantErr)
+			continue
+		}
+		if err == nil && sth.Timestamp != tc.wantTS {
+			t.Errorf("GetMirrorSTH(%d) returned STH with timestamp %d, want %d", tc.maxTreeSize, sth.Timestamp, tc.wantTS)
+		}
+	}
+}
+
 type fakeSigner struct {
 	sig []byte
 	err error
@@ -21,6 +21,7 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
+	mrand "math/rand"
 	"time"
 
 	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/configpb"
@@ -37,6 +38,14 @@ type ChainGenerator interface {
 	PreCertChain() ([]ct.ASN1Cert, []byte, error)
 }
 
+// BadPoisonChainGenerator is implemented by ChainGenerators which can also
+// generate precertificate chains whose poison extension is malformed.
+type BadPoisonChainGenerator interface {
+	// BadPoisonPreCertChain generates a precertificate chain whose poison
+	// extension is either not critical or not ASN.1 NULL.
+	BadPoisonPreCertChain() ([]ct.ASN1Cert, error)
+}
+
 // GeneratorFactory is a method that builds a Log-specific ChainGenerator.
 type GeneratorFactory func(c *configpb.LogConfig) (ChainGenerator, error)
 
@@ -47,13 +56,34 @@ type SyntheticChainGenerator struct {
 	leafCert *x509.Certificate
 	caCert   *x509.Certificate
 	// Signer which matches the caCert
-	signer   crypto.Signer
-	notAfter time.Time
+	signer crypto.Signer
+	opts   SyntheticChainOptions
+}
+
+// SyntheticChainOptions holds the options of a SyntheticChainGenerator.
+type SyntheticChainOptions struct {
+	// NotAfter is the NotAfter of the leaf certs, unless NotAfterLimit is set.
+	// Defaults to 24 hours from now.
+	NotAfter time.Time
+	// NotAfterStart and NotAfterLimit, if the latter is set, are the window
+	// [NotAfterStart, NotAfterLimit) over which the NotAfter of the leaf certs
+	// are uniformly spread, e.g. a temporal shard of a log.
+	NotAfterStart, NotAfterLimit time.Time
+	// PreIssuerChance is the chance of issuing a precert through a fresh
+	// precert signing cert rather than directly by the CA, as the N in 1-in-N
+	// (0 for never).
+	PreIssuerChance int
 }
 
 // NewSyntheticChainGenerator returns a ChainGenerator that mints synthetic certificates based on the
 // given template chain.  The provided signer should match the public key of the first issuer cert.
 func NewSyntheticChainGenerator(chain []ct.ASN1Cert, signer crypto.Signer, notAfter time.Time) (ChainGenerator, error) {
+	return NewSyntheticChainGeneratorFromOpts(chain, signer, SyntheticChainOptions{NotAfter: notAfter})
+}
+
+// NewSyntheticChainGeneratorFromOpts returns a ChainGenerator like
+// NewSyntheticChainGenerator, configured by the given options.
+func NewSyntheticChainGeneratorFromOpts(chain []ct.ASN1Cert, signer crypto.Signer, opts SyntheticChainOptions) (ChainGenerator, error) {
 	if len(chain) < 2 {
 		return nil, fmt.Errorf("chain too short (%d)", len(chain))
 	}
@@ -65,22 +95,39 @@ func NewSyntheticChainGenerator(chain []ct.ASN1Cert, signer crypto.Signer, notAf
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse issuer cert: %v", err)
 	}
-	if notAfter.IsZero() {
-		notAfter = time.Now().Add(24 * time.Hour)
+	if !opts.NotAfterLimit.IsZero() && !opts.NotAfterStart.Before(opts.NotAfterLimit) {
+		return nil, fmt.Errorf("empty NotAfter window [%v, %v)", opts.NotAfterStart, opts.NotAfterLimit)
+	}
+	if opts.NotAfter.IsZero() {
+		opts.NotAfter = time.Now().Add(24 * time.Hour)
 	}
 	return &SyntheticChainGenerator{
 		chain:    chain,
 		leafCert: leaf,
 		caCert:   issuer,
 		signer:   signer,
-		notAfter: notAfter,
+		opts:     opts,
 	}, nil
 }
 
+// notAfter returns the NotAfter for a new leaf cert.
+func (g *SyntheticChainGenerator) notAfter() time.Time {
+	if g.opts.NotAfterLimit.IsZero() {
+		return g.opts.NotAfter
+	}
+	window := g.opts.NotAfterLimit.Sub(g.opts.NotAfterStart)
+	// NotAfter has a one second granularity, so stay a second clear of the
+	// exclusive limit.
+	if window > time.Second {
+		window -= time.Second
+	}
+	return g.opts.NotAfterStart.Add(time.Duration(mrand.Int63n(int64(window))))
+}
+
 // CertChain builds a new synthetic chain with a fresh leaf cert, changing SubjectKeyId and re-signing.
 func (g *SyntheticChainGenerator) CertChain() ([]ct.ASN1Cert, error) {
 	cert := *g.leafCert
-	cert.NotAfter = g.notAfter
+	cert.NotAfter = g.notAfter()
 	chain := make([]ct.ASN1Cert, len(g.chain))
 	copy(chain[1:], g.chain[1:])
 
@@ -102,7 +149,12 @@ func (g *SyntheticChainGenerator) CertChain() ([]ct.ASN1Cert, error) {
 }
 
 // PreCertChain builds a new synthetic precert chain; also returns the leaf TBS data.
+// The precert may be issued through a fresh precert signing cert, according to the
+// PreIssuerChance option.
 func (g *SyntheticChainGenerator) PreCertChain() ([]ct.ASN1Cert, []byte, error) {
+	if g.opts.PreIssuerChance > 0 && mrand.Intn(g.opts.PreIssuerChance) == 0 {
+		return makePreIssuerPrecertChain(g.chain, g.caCert, g.signer, g.notAfter())
+	}
 	prechain := make([]ct.ASN1Cert, len(g.chain))
 	copy(prechain[1:], g.chain[1:])
 
@@ -110,7 +162,7 @@ func (g *SyntheticChainGenerator) PreCertChain() ([]ct.ASN1Cert, []byte, error)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse certificate to build precert from: %v", err)
 	}
-	cert.NotAfter = g.notAfter
+	cert.NotAfter = g.notAfter()
 
 	prechain[0].Data, err = buildNewPrecertData(cert, g.caCert, g.signer)
 	if err != nil {
@@ -125,6 +177,32 @@ func (g *SyntheticChainGenerator) PreCertChain() ([]ct.ASN1Cert, []byte, error)
 	return prechain, tbs, nil
 }
 
+// BadPoisonPreCertChain builds a new synthetic precert chain whose poison
+// extension is either not critical or not ASN.1 NULL, but which is otherwise
+// valid.
+func (g *SyntheticChainGenerator) BadPoisonPreCertChain() ([]ct.ASN1Cert, error) {
+	prechain := make([]ct.ASN1Cert, len(g.chain))
+	copy(prechain[1:], g.chain[1:])
+
+	cert, err := x509.ParseCertificate(g.chain[0].Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate to build precert from: %v", err)
+	}
+	cert.NotAfter = g.notAfter()
+
+	poison := ctPoison
+	if mrand.Intn(2) == 0 {
+		poison.Critical = false
+	} else {
+		poison.Value = []byte{0x04, 0x00} // Empty OCTET STRING
+	}
+	prechain[0].Data, err = buildPrecertData(cert, g.caCert, g.signer, poison)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %v", err)
+	}
+	return prechain, nil
+}
+
 // buildLeafTBS builds the raw pre-cert data (a DER-encoded TBSCertificate) that is included
 // in the log.
 func buildLeafTBS(precertData []byte, preIssuer *x509.Certificate) ([]byte, error) {
@@ -136,8 +214,9 @@ func buildLeafTBS(precertData []byte, preIssuer *x509.Certificate) ([]byte, erro
 }
 
 // makePreIssuerPrecertChain builds a precert chain where the pre-cert is signed by a new
-// pre-issuer intermediate.
-func makePreIssuerPrecertChain(chain []ct.ASN1Cert, issuer *x509.Certificate, signer crypto.Signer) ([]ct.ASN1Cert, []byte, error) {
+// pre-issuer intermediate. If notAfter is not zero, it overrides the NotAfter of the
+// pre-cert.
+func makePreIssuerPrecertChain(chain []ct.ASN1Cert, issuer *x509.Certificate, signer crypto.Signer, notAfter time.Time) ([]ct.ASN1Cert, []byte, error) {
 	prechain := make([]ct.ASN1Cert, len(chain)+1)
 	copy(prechain[2:], chain[1:])
 
@@ -176,6 +255,9 @@ func makePreIssuerPrecertChain(chain []ct.ASN1Cert, issuer *x509.Certificate, si
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse certificate to build precert from: %v", err)
 	}
+	if !notAfter.IsZero() {
+		cert.NotAfter = notAfter
+	}
 
 	prechain[0].Data, err = buildNewPrecertData(cert, preIssuer, preSigner)
 	if err != nil {
@@ -227,7 +309,9 @@ func verifyChain(rawChain []ct.ASN1Cert) error {
 		Roots:             rootPool,
 		Intermediates:     intermediatePool,
 		DisableTimeChecks: true,
-		KeyUsages:         []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		// The log accepted the chain whatever its policies.
+		DisablePolicyChecks: true,
+		KeyUsages:           []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
 	}
 
 	chain[0].UnhandledCriticalExtensions = nil
@@ -242,9 +326,28 @@ func verifyChain(rawChain []ct.ASN1Cert) error {
 	return nil
 }
 
+// SyntheticGeneratorOptions holds the options of SyntheticGeneratorFactoryFromOpts.
+type SyntheticGeneratorOptions struct {
+	// LeafNotAfter, if set, is the NotAfter of all the leaf certs, in RFC3339 format.
+	LeafNotAfter string
+	// SpreadNotAfter, unless LeafNotAfter is set, spreads the NotAfter of the leaf
+	// certs across the temporal shard window of each log (see NotAfterWindowForLog),
+	// rather than using a single NotAfter per log (see NotAfterForLog).
+	SpreadNotAfter bool
+	// PreIssuerChance is the chance of issuing a precert through a precert signing
+	// cert, as the N in 1-in-N (0 for never).
+	PreIssuerChance int
+}
+
 // SyntheticGeneratorFactory returns a function that creates per-Log ChainGenerator instances
 // that create synthetic certificates (details of which are specified by the arguments).
 func SyntheticGeneratorFactory(testDir, leafNotAfter string) (GeneratorFactory, error) {
+	return SyntheticGeneratorFactoryFromOpts(testDir, SyntheticGeneratorOptions{LeafNotAfter: leafNotAfter})
+}
+
+// SyntheticGeneratorFactoryFromOpts returns a function that creates per-Log ChainGenerator
+// instances that create synthetic certificates, configured by the given options.
+func SyntheticGeneratorFactoryFromOpts(testDir string, opts SyntheticGeneratorOptions) (GeneratorFactory, error) {
 	leafChain, err := GetChain(testDir, "leaf01.chain")
 	if err != nil {
 		return nil, fmt.Errorf("failed to load certificate: %v", err)
@@ -254,22 +357,26 @@ func SyntheticGeneratorFactory(testDir, leafNotAfter string) (GeneratorFactory,
 		return nil, fmt.Errorf("failed to retrieve signer for re-signing: %v", err)
 	}
 	var notAfterOverride time.Time
-	if leafNotAfter != "" {
-		notAfterOverride, err = time.Parse(time.RFC3339, leafNotAfter)
+	if opts.LeafNotAfter != "" {
+		notAfterOverride, err = time.Parse(time.RFC3339, opts.LeafNotAfter)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse leaf notAfter: %v", err)
 		}
 	}
 	// Build a synthetic generator for each target log.
 	return func(c *configpb.LogConfig) (ChainGenerator, error) {
-		notAfter := notAfterOverride
-		if notAfter.IsZero() {
-			var err error
-			notAfter, err = NotAfterForLog(c)
-			if err != nil {
-				return nil, fmt.Errorf("failed to determine notAfter for %s: %v", c.Prefix, err)
-			}
+		genOpts := SyntheticChainOptions{NotAfter: notAfterOverride, PreIssuerChance: opts.PreIssuerChance}
+		var err error
+		switch {
+		case !notAfterOverride.IsZero():
+		case opts.SpreadNotAfter:
+			genOpts.NotAfterStart, genOpts.NotAfterLimit, err = NotAfterWindowForLog(c)
+		default:
+			genOpts.NotAfter, err = NotAfterForLog(c)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine notAfter for %s: %v", c.Prefix, err)
 		}
-		return NewSyntheticChainGenerator(leafChain, signer, notAfter)
+		return NewSyntheticChainGeneratorFromOpts(leafChain, signer, genOpts)
 	}, nil
 }
@@ -26,6 +26,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -49,8 +50,10 @@ var (
 	httpServers = flag.String("ct_http_servers", "localhost:8092", "Comma-separated list of (assumed interchangeable) servers, each as address:port")
 
 	// Options for synthetic cert generation.
-	testDir      = flag.String("testdata_dir", "testdata", "Name of directory with test data")
-	leafNotAfter = flag.String("leaf_not_after", "", "Not-After date to use for leaf certs, RFC3339/ISO-8601 format (e.g. 2017-11-26T12:29:19Z)")
+	testDir         = flag.String("testdata_dir", "testdata", "Name of directory with test data")
+	leafNotAfter    = flag.String("leaf_not_after", "", "Not-After date to use for leaf certs, RFC3339/ISO-8601 format (e.g. 2017-11-26T12:29:19Z)")
+	spreadNotAfter  = flag.Bool("spread_not_after", false, "Spread the Not-After dates of synthetic leaf certs across each log's temporal shard window (ignored if --leaf_not_after is set)")
+	preIssuerChance = flag.Int("pre_issuer_chance", 0, "Chance of issuing a synthetic pre-cert through a precert signing cert, as the N in 1-in-N (0 for never)")
 	// Options for copied-cert generation.
 	srcLogURI       = flag.String("src_log_uri", "", "URI for source log to copy certificates from")
 	srcPubKey       = flag.String("src_pub_key", "", "Name of file containing source log's public key")
@@ -74,6 +77,10 @@ var (
 	ignoreErrors        = flag.Bool("ignore_errors", false, "Whether to ignore errors and retry the operation")
 	maxRetry            = flag.Duration("max_retry", 60*time.Second, "How long to keep retrying when ignore_errors is set")
 	reqDeadline         = flag.Duration("req_deadline", 10*time.Second, "Deadline to set on individual requests")
+
+	slo        = flag.String("slo", "", "Latency and error-budget objectives per entrypoint, which each log must meet, e.g. 'AddChain:p99=2s,errors=0.01;*:p50=100ms' (see integration.ParseSLOs)")
+	reportJSON = flag.String("report_json", "", "File to write a JSON report of the run to")
+	reportHTML = flag.String("report_html", "", "File to write an HTML report of the run to")
 )
 var (
 	addChainBias             = flag.Int("add_chain", 20, "Bias for add-chain operations")
@@ -180,6 +187,10 @@ func main() {
 	if err != nil {
 		klog.Exitf("Failed to read log config: %v", err)
 	}
+	slos, err := integration.ParseSLOs(*slo)
+	if err != nil {
+		klog.Exitf("Failed to parse --slo: %v", err)
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -191,7 +202,11 @@ func main() {
 		// Test cert chains will be generated as synthetic certs from a template.
 		// Retrieve the test data holding the template and key.
 		klog.Infof("Testing with synthetic certs based on data from %s", *testDir)
-		generatorFactory, err = integration.SyntheticGeneratorFactory(*testDir, *leafNotAfter)
+		generatorFactory, err = integration.SyntheticGeneratorFactoryFromOpts(*testDir, integration.SyntheticGeneratorOptions{
+			LeafNotAfter:    *leafNotAfter,
+			SpreadNotAfter:  *spreadNotAfter,
+			PreIssuerChance: *preIssuerChance,
+		})
 		if err != nil {
 			klog.Exitf("Failed to make cert generator: %v", err)
 		}
@@ -262,6 +277,7 @@ func main() {
 
 	type result struct {
 		prefix string
+		report *integration.HammerReport
 		err    error
 	}
 	results := make(chan result, len(cfg))
@@ -303,11 +319,12 @@ func main() {
 			RequestDeadline:          *reqDeadline,
 			DuplicateChance:          *dupeChance,
 			StrictSTHConsistencySize: *strictSTHConsistencySize,
+			SLOs:                     slos,
 		}
 		go func(cfg integration.HammerConfig) {
 			defer wg.Done()
-			err := integration.HammerCTLog(ctx, cfg)
-			results <- result{prefix: cfg.LogCfg.Prefix, err: err}
+			report, err := integration.HammerCTLogWithReport(ctx, cfg)
+			results <- result{prefix: cfg.LogCfg.Prefix, report: report, err: err}
 		}(cfg)
 	}
 	wg.Wait()
@@ -315,14 +332,40 @@ func main() {
 	klog.Infof("completed tests on all %d logs:", len(cfg))
 	close(results)
 	errCount := 0
+	var reports []*integration.HammerReport
 	for e := range results {
 		if e.err != nil {
 			errCount++
 			klog.Errorf("  %s: failed with %v", e.prefix, e.err)
 		}
+		if e.report != nil {
+			reports = append(reports, e.report)
+		}
 	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Prefix < reports[j].Prefix })
+	writeReport(*reportJSON, reports, integration.WriteJSONReport)
+	writeReport(*reportHTML, reports, integration.WriteHTMLReport)
 	if errCount > 0 {
 		klog.Exitf("non-zero error count (%d), exiting", errCount)
 	}
 	klog.Info("  no errors; done")
 }
+
+// writeReport writes the reports to the named file with the given writer
+// function, if the file name is not empty.
+func writeReport(path string, reports []*integration.HammerReport, write func(io.Writer, []*integration.HammerReport) error) {
+	if path == "" {
+		return
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		klog.Exitf("Failed to create report: %v", err)
+	}
+	if err := write(f, reports); err != nil {
+		klog.Exitf("Failed to write report to %s: %v", path, err)
+	}
+	if err := f.Close(); err != nil {
+		klog.Exitf("Failed to close report %s: %v", path, err)
+	}
+	klog.Infof("Wrote report to %s", path)
+}
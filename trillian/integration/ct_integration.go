@@ -561,7 +561,7 @@ func RunCTIntegrationForLog(cfg *configpb.LogConfig, servers, metricsServers, te
 	fmt.Printf("%s: GetProofByHash(wrong,%d)=(nil,_)\n", t.prefix, sthN1.TreeSize)
 
 	// Stage 17: build and add a pre-certificate signed by a pre-issuer.
-	preIssuerChain, preTBS, err := makePreIssuerPrecertChain(chain[1], issuer, signer)
+	preIssuerChain, preTBS, err := makePreIssuerPrecertChain(chain[1], issuer, signer, time.Time{})
 	if err != nil {
 		return fmt.Errorf("failed to build pre-issued pre-certificate: %v", err)
 	}
@@ -823,6 +823,19 @@ func (t *testInfo) checkCTConsistencyProof(sth1, sth2 *ct.SignedTreeHead, pf [][
 // buildNewPrecertData creates a new pre-certificate based on the given template cert (which is
 // modified)
 func buildNewPrecertData(cert, issuer *x509.Certificate, signer crypto.Signer) ([]byte, error) {
+	return buildPrecertData(cert, issuer, signer, ctPoison)
+}
+
+// ctPoison is the CT precertificate poison extension.
+var ctPoison = pkix.Extension{
+	Id:       x509.OIDExtensionCTPoison,
+	Critical: true,
+	Value:    []byte{0x05, 0x00}, // ASN.1 NULL
+}
+
+// buildPrecertData builds a precertificate from cert with the given poison
+// extension, signed by the issuer.
+func buildPrecertData(cert, issuer *x509.Certificate, signer crypto.Signer, poison pkix.Extension) ([]byte, error) {
 	// Randomize the subject key ID.
 	randData := make([]byte, 128)
 	if _, err := cryptorand.Read(randData); err != nil {
@@ -831,11 +844,7 @@ func buildNewPrecertData(cert, issuer *x509.Certificate, signer crypto.Signer) (
 	cert.SubjectKeyId = randData
 
 	// Add the CT poison extension.
-	cert.ExtraExtensions = append(cert.ExtraExtensions, pkix.Extension{
-		Id:       x509.OIDExtensionCTPoison,
-		Critical: true,
-		Value:    []byte{0x05, 0x00}, // ASN.1 NULL
-	})
+	cert.ExtraExtensions = append(cert.ExtraExtensions, poison)
 
 	// Create a fresh certificate, signed by the issuer.
 	cert.AuthorityKeyId = issuer.SubjectKeyId
@@ -1022,3 +1031,33 @@ func NotAfterForLog(c *configpb.LogConfig) (time.Time, error) {
 	limit := c.NotAfterLimit.AsTime()
 	return limit.Add(-1 * time.Hour), nil
 }
+
+// NotAfterWindowForLog returns the window [start, limit) of NotAfter times to
+// be used for certs submitted to the given log instance, which is its temporal
+// shard if it has one. Open-ended shards, and logs without a shard, get a
+// window of two days, from now if unbounded below.
+func NotAfterWindowForLog(c *configpb.LogConfig) (time.Time, time.Time, error) {
+	const window = 48 * time.Hour
+	start, limit := time.Now(), time.Time{}
+	if c.NotAfterStart != nil {
+		if err := c.NotAfterStart.CheckValid(); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("failed to parse NotAfterStart: %v", err)
+		}
+		start = c.NotAfterStart.AsTime()
+	}
+	if c.NotAfterLimit != nil {
+		if err := c.NotAfterLimit.CheckValid(); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("failed to parse NotAfterLimit: %v", err)
+		}
+		limit = c.NotAfterLimit.AsTime()
+		if c.NotAfterStart == nil {
+			start = limit.Add(-window)
+		}
+	} else {
+		limit = start.Add(window)
+	}
+	if !start.Before(limit) {
+		return time.Time{}, time.Time{}, fmt.Errorf("empty NotAfter window [%v, %v)", start, limit)
+	}
+	return start, limit, nil
+}
@@ -15,13 +15,21 @@
 package integration
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	cryptorand "crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -47,6 +55,9 @@ const (
 
 	// How far beyond current tree size to request for invalid requests.
 	invalidStretch = int64(1000000000)
+
+	// Size of the bodies of deliberately-oversized add-[pre-]chain requests.
+	oversizedBodySize = 4 << 20
 )
 
 var (
@@ -95,6 +106,13 @@ const (
 	NewCert        = Choice("NewCert")
 	LastCert       = Choice("LastCert")
 	FirstCert      = Choice("FirstCert")
+
+	// Choices for malformed add-[pre-]chain submissions.
+	TruncatedDER      = Choice("TruncatedDER")
+	BadPoison         = Choice("BadPoison")
+	BogusIntermediate = Choice("BogusIntermediate")
+	OversizedBody     = Choice("OversizedBody")
+	InvalidJSON       = Choice("InvalidJSON")
 )
 
 // Limiter is an interface to allow different rate limiters to be used with the
@@ -121,6 +139,10 @@ type HammerConfig struct {
 	ChainGenerator ChainGenerator
 	// ClientPool provides the clients used to make requests.
 	ClientPool ClientPool
+	// HTTPClient is used for the requests which the clients of ClientPool
+	// cannot make, such as ones with malformed bodies. If nil, a client using
+	// DefaultTransport is used.
+	HTTPClient *http.Client
 	// Bias values to favor particular log operations.
 	EPBias HammerBias
 	// Range of how many entries to get.
@@ -152,6 +174,9 @@ type HammerConfig struct {
 	// If set to false, Hammer will request a consistency proof between the
 	// current tree size, and a random smaller size greater than zero.
 	StrictSTHConsistencySize bool
+	// SLOs holds the latency and error-budget objectives of the valid
+	// requests, per entrypoint, which the run must meet; see ParseSLOs.
+	SLOs map[ctfe.EntrypointName]SLO
 }
 
 // HammerBias indicates the bias for selecting different log operations.
@@ -305,6 +330,8 @@ type hammerState struct {
 	pending pendingCerts
 	// Operations that are required to fix dependencies.
 	nextOp []ctfe.EntrypointName
+	// Outcomes and latencies of the requests, for the report of the run.
+	stats *runStats
 
 	hasher merkle.LogHasher
 }
@@ -330,6 +357,9 @@ func newHammerState(cfg *HammerConfig) (*hammerState, error) {
 	if cfg.MaxRetryDuration <= 0 {
 		cfg.MaxRetryDuration = 60 * time.Second
 	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Transport: DefaultTransport}
+	}
 
 	if cfg.LogCfg.IsMirror {
 		klog.Warningf("%v: disabling add-[pre-]chain for mirror log", cfg.LogCfg.Prefix)
@@ -340,6 +370,7 @@ func newHammerState(cfg *HammerConfig) (*hammerState, error) {
 	state := hammerState{
 		cfg:    cfg,
 		nextOp: make([]ctfe.EntrypointName, 0),
+		stats:  newRunStats(),
 		hasher: rfc6962.DefaultHasher,
 	}
 	return &state, nil
@@ -458,9 +489,13 @@ func (s *hammerState) addChain(ctx context.Context) error {
 }
 
 func (s *hammerState) addChainInvalid(ctx context.Context) error {
-	choices := []Choice{EmptyChain, PrecertNotCert, NoChainToRoot, UnparsableCert}
-	choice := choices[rand.Intn(len(choices))]
+	choices := []Choice{EmptyChain, PrecertNotCert, NoChainToRoot, UnparsableCert, TruncatedDER, BogusIntermediate, OversizedBody, InvalidJSON}
+	return s.addChainInvalidChoice(ctx, choices[rand.Intn(len(choices))])
+}
 
+// addChainInvalidChoice makes an add-chain request which is invalid in the
+// way described by choice, and checks that the log rejects it.
+func (s *hammerState) addChainInvalidChoice(ctx context.Context, choice Choice) error {
 	var err error
 	var chain []ct.ASN1Cert
 	switch choice {
@@ -484,6 +519,26 @@ func (s *hammerState) addChainInvalid(ctx context.Context) error {
 		}
 		// Remove the initial ASN.1 SEQUENCE type byte (0x30) to make an unparsable cert.
 		chain[0].Data[0] = 0x00
+	case TruncatedDER:
+		chain, err = s.cfg.ChainGenerator.CertChain()
+		if err != nil {
+			return fmt.Errorf("failed to make chain(%s): %v", choice, err)
+		}
+		chain[0].Data = chain[0].Data[:len(chain[0].Data)/2]
+	case BogusIntermediate:
+		chain, err = s.cfg.ChainGenerator.CertChain()
+		if err != nil {
+			return fmt.Errorf("failed to make chain(%s): %v", choice, err)
+		}
+		if chain, err = bogusIntermediateChain(chain); err != nil {
+			return fmt.Errorf("failed to make chain(%s): %v", choice, err)
+		}
+	case OversizedBody, InvalidJSON:
+		chain, err = s.cfg.ChainGenerator.CertChain()
+		if err != nil {
+			return fmt.Errorf("failed to make chain(%s): %v", choice, err)
+		}
+		return s.postInvalidBody(ctx, ct.AddChainPath, choice, chain)
 	default:
 		klog.Exitf("Unhandled choice %s", choice)
 	}
@@ -496,7 +551,7 @@ func (s *hammerState) addChainInvalid(ctx context.Context) error {
 	if err == nil {
 		return fmt.Errorf("unexpected success: add-chain(%s): %+v", choice, sct)
 	}
-	return nil
+	return checkRejected("add-chain", choice, err)
 }
 
 // chooseCertToAdd determines whether to add a new or pre-existing cert.
@@ -547,9 +602,9 @@ func (s *hammerState) addPreChain(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to make pre-cert chain (%s): %v", choice, err)
 	}
-	issuer, err := x509.ParseCertificate(prechain[1].Data)
+	issuer, err := precertIssuer(prechain)
 	if err != nil {
-		return fmt.Errorf("failed to parse pre-cert issuer: %v", err)
+		return err
 	}
 
 	sct, err := s.client().AddPreChain(ctx, prechain)
@@ -587,10 +642,44 @@ func (s *hammerState) addPreChain(ctx context.Context) error {
 	return nil
 }
 
+// precertIssuer returns the cert whose key hash goes into the log entry for
+// the given pre-cert chain: the pre-cert's issuer, or the issuer of that if it
+// is a precert signing cert (RFC 6962 s3.1).
+func precertIssuer(prechain []ct.ASN1Cert) (*x509.Certificate, error) {
+	issuer, err := x509.ParseCertificate(prechain[1].Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pre-cert issuer: %v", err)
+	}
+	if !isPrecertSigningCert(issuer) {
+		return issuer, nil
+	}
+	if len(prechain) < 3 {
+		return nil, errors.New("pre-cert chain has precert signing cert but no issuer for it")
+	}
+	issuer, err = x509.ParseCertificate(prechain[2].Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse precert signing cert issuer: %v", err)
+	}
+	return issuer, nil
+}
+
+func isPrecertSigningCert(cert *x509.Certificate) bool {
+	for _, eku := range cert.ExtKeyUsage {
+		if eku == x509.ExtKeyUsageCertificateTransparency {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *hammerState) addPreChainInvalid(ctx context.Context) error {
-	choices := []Choice{EmptyChain, CertNotPrecert, NoChainToRoot, UnparsableCert}
-	choice := choices[rand.Intn(len(choices))]
+	choices := []Choice{EmptyChain, CertNotPrecert, NoChainToRoot, UnparsableCert, TruncatedDER, BadPoison, BogusIntermediate, OversizedBody, InvalidJSON}
+	return s.addPreChainInvalidChoice(ctx, choices[rand.Intn(len(choices))])
+}
 
+// addPreChainInvalidChoice makes an add-pre-chain request which is invalid
+// in the way described by choice, and checks that the log rejects it.
+func (s *hammerState) addPreChainInvalidChoice(ctx context.Context, choice Choice) error {
 	var err error
 	var prechain []ct.ASN1Cert
 	switch choice {
@@ -614,6 +703,35 @@ func (s *hammerState) addPreChainInvalid(ctx context.Context) error {
 		}
 		// Remove the initial ASN.1 SEQUENCE type byte (0x30) to make an unparsable cert.
 		prechain[0].Data[0] = 0x00
+	case TruncatedDER:
+		prechain, _, err = s.cfg.ChainGenerator.PreCertChain()
+		if err != nil {
+			return fmt.Errorf("failed to make pre-chain(%s): %v", choice, err)
+		}
+		prechain[0].Data = prechain[0].Data[:len(prechain[0].Data)/2]
+	case BadPoison:
+		gen, ok := s.cfg.ChainGenerator.(BadPoisonChainGenerator)
+		if !ok {
+			return errSkip{}
+		}
+		prechain, err = gen.BadPoisonPreCertChain()
+		if err != nil {
+			return fmt.Errorf("failed to make pre-chain(%s): %v", choice, err)
+		}
+	case BogusIntermediate:
+		prechain, _, err = s.cfg.ChainGenerator.PreCertChain()
+		if err != nil {
+			return fmt.Errorf("failed to make pre-chain(%s): %v", choice, err)
+		}
+		if prechain, err = bogusIntermediateChain(prechain); err != nil {
+			return fmt.Errorf("failed to make pre-chain(%s): %v", choice, err)
+		}
+	case OversizedBody, InvalidJSON:
+		prechain, _, err = s.cfg.ChainGenerator.PreCertChain()
+		if err != nil {
+			return fmt.Errorf("failed to make pre-chain(%s): %v", choice, err)
+		}
+		return s.postInvalidBody(ctx, ct.AddPreChainPath, choice, prechain)
 	default:
 		klog.Exitf("Unhandled choice %s", choice)
 	}
@@ -624,7 +742,121 @@ func (s *hammerState) addPreChainInvalid(ctx context.Context) error {
 		klog.V(3).Infof("   HTTP status %d body %s", err.StatusCode, err.Body)
 	}
 	if err == nil {
-		return fmt.Errorf("unexpected success: add-pre-chain: %+v", sct)
+		return fmt.Errorf("unexpected success: add-pre-chain(%s): %+v", choice, sct)
+	}
+	return checkRejected("add-pre-chain", choice, err)
+}
+
+// bogusIntermediateChain re-issues the leaf of chain from a fresh
+// intermediate, which has the same subject as the real one but a new key and
+// is self-signed, so that the chain looks plausible but does not verify.
+func bogusIntermediateChain(chain []ct.ASN1Cert) ([]ct.ASN1Cert, error) {
+	if len(chain) < 2 {
+		return nil, fmt.Errorf("chain too short (%d)", len(chain))
+	}
+	leaf, err := x509.ParseCertificate(chain[0].Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse leaf: %v", err)
+	}
+	intermediate, err := x509.ParseCertificate(chain[1].Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse intermediate: %v", err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bogus intermediate key: %v", err)
+	}
+	intermediate.SignatureAlgorithm = x509.UnknownSignatureAlgorithm
+	bogus := make([]ct.ASN1Cert, len(chain))
+	copy(bogus[2:], chain[2:])
+	if bogus[1].Data, err = x509.CreateCertificate(cryptorand.Reader, intermediate, intermediate, key.Public(), key); err != nil {
+		return nil, fmt.Errorf("failed to create bogus intermediate: %v", err)
+	}
+	if intermediate, err = x509.ParseCertificate(bogus[1].Data); err != nil {
+		return nil, fmt.Errorf("failed to re-parse bogus intermediate: %v", err)
+	}
+
+	// Keep the leaf's poison extension, if any, which is not part of the
+	// fields used by CreateCertificate.
+	for _, ext := range leaf.Extensions {
+		if ext.Id.Equal(x509.OIDExtensionCTPoison) {
+			leaf.ExtraExtensions = append(leaf.ExtraExtensions, ext)
+		}
+	}
+	leaf.SignatureAlgorithm = x509.UnknownSignatureAlgorithm
+	if bogus[0].Data, err = x509.CreateCertificate(cryptorand.Reader, leaf, intermediate, leaf.PublicKey, key); err != nil {
+		return nil, fmt.Errorf("failed to re-issue leaf: %v", err)
+	}
+	return bogus, nil
+}
+
+// postInvalidBody posts an add-[pre-]chain request for chain to the given
+// path, with a body which is malformed in the way described by choice, and
+// checks that the log rejects it.
+func (s *hammerState) postInvalidBody(ctx context.Context, path string, choice Choice, chain []ct.ASN1Cert) error {
+	req := ct.AddChainRequest{Chain: make([][]byte, len(chain))}
+	for i, c := range chain {
+		req.Chain[i] = c.Data
+	}
+	if choice == OversizedBody {
+		// Pad the chain with an unparsable certificate, to exceed any sensible
+		// limit on the body size.
+		req.Chain = append(req.Chain, make([]byte, oversizedBodySize))
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request(%s): %v", choice, err)
+	}
+	if choice == InvalidJSON {
+		// Cut the body in the middle of the chain.
+		body = body[:len(body)/2]
+	}
+
+	op := strings.TrimPrefix(path, "/ct/v1/")
+	err = s.postRaw(ctx, path, body)
+	klog.V(3).Infof("invalid %s(%s) => error %v", op, choice, err)
+	if err, ok := err.(client.RspError); ok {
+		klog.V(3).Infof("   HTTP status %d body %.200s", err.StatusCode, err.Body)
+	}
+	if err == nil {
+		return fmt.Errorf("unexpected success: %s(%s)", op, choice)
+	}
+	return checkRejected(op, choice, err)
+}
+
+// postRaw posts body as JSON to the given path of the log, and returns a
+// client.RspError unless the response status is 200 OK.
+func (s *hammerState) postRaw(ctx context.Context, path string, body []byte) error {
+	uri := s.client().BaseURI() + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := s.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	rspBody, err := io.ReadAll(io.LimitReader(rsp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return client.RspError{Err: fmt.Errorf("got HTTP status %q", rsp.Status), StatusCode: rsp.StatusCode, Body: rspBody}
+	}
+	return nil
+}
+
+// checkRejected checks that the error of an invalid operation shows that the
+// log rejected it as a bad request, rather than failing to handle it.
+func checkRejected(op string, choice Choice, err error) error {
+	var rspErr client.RspError
+	if !errors.As(err, &rspErr) {
+		return fmt.Errorf("invalid %s(%s) got no response: %v", op, choice, err)
+	}
+	if rspErr.StatusCode < 400 || rspErr.StatusCode >= 500 {
+		return fmt.Errorf("invalid %s(%s) got HTTP status %d; want 4xx", op, choice, rspErr.StatusCode)
 	}
 	return nil
 }
@@ -1060,6 +1292,7 @@ func (s *hammerState) retryOneOp(ctx context.Context) error {
 	if invalid {
 		klog.V(3).Infof("perform invalid %s operation", ep)
 		invalidReqs.Inc(s.label(), string(ep))
+		s.stats.observeInvalid(ep)
 		err := s.performInvalidOp(ctx, ep)
 		if _, ok := err.(errSkip); ok {
 			klog.V(2).Infof("invalid operation %s was skipped", ep)
@@ -1084,12 +1317,14 @@ func (s *hammerState) retryOneOp(ctx context.Context) error {
 		switch err.(type) {
 		case nil:
 			rsps.Inc(s.label(), string(ep), strconv.Itoa(status))
+			s.stats.observe(ep, period, false)
 			return nil
 		case errSkip:
 			klog.V(2).Infof("operation %s was skipped", ep)
 			return nil
 		default:
 			errs.Inc(s.label(), string(ep))
+			s.stats.observe(ep, period, true)
 			if s.cfg.IgnoreErrors {
 				left := time.Until(deadline)
 				if left < 0 {
@@ -1111,9 +1346,18 @@ func (s *hammerState) checkCTConsistencyProof(sth1, sth2 *ct.SignedTreeHead, pf
 
 // HammerCTLog performs load/stress operations according to given config.
 func HammerCTLog(ctx context.Context, cfg HammerConfig) error {
+	_, err := HammerCTLogWithReport(ctx, cfg)
+	return err
+}
+
+// HammerCTLogWithReport performs load/stress operations according to given
+// config, like HammerCTLog, and also returns a report of the run. The run
+// fails if it does not meet the SLOs of the config. The report is returned
+// even if the run fails, unless the hammer could not start.
+func HammerCTLogWithReport(ctx context.Context, cfg HammerConfig) (*HammerReport, error) {
 	s, err := newHammerState(&cfg)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -1122,7 +1366,21 @@ func HammerCTLog(ctx context.Context, cfg HammerConfig) error {
 		klog.Info(s.String())
 	})
 
-	for count := uint64(1); count < cfg.Operations; count++ {
+	start := time.Now()
+	err = s.run(ctx)
+	rep := s.stats.report(cfg.LogCfg.Prefix, start, err, cfg.SLOs)
+	if err != nil {
+		return rep, err
+	}
+	if v := rep.Violations(); len(v) > 0 {
+		return rep, fmt.Errorf("%s: SLO violations: %s", cfg.LogCfg.Prefix, strings.Join(v, "; "))
+	}
+	return rep, nil
+}
+
+// run performs the configured number of operations.
+func (s *hammerState) run(ctx context.Context) error {
+	for count := uint64(1); count < s.cfg.Operations; count++ {
 		if err := s.retryOneOp(ctx); err != nil {
 			return err
 		}
@@ -1131,7 +1389,6 @@ func HammerCTLog(ctx context.Context, cfg HammerConfig) error {
 			return err
 		}
 	}
-	klog.Infof("%s: completed %d operations on log", cfg.LogCfg.Prefix, cfg.Operations)
-
+	klog.Infof("%s: completed %d operations on log", s.cfg.LogCfg.Prefix, s.cfg.Operations)
 	return nil
 }
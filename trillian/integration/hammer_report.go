@@ -0,0 +1,323 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe"
+)
+
+// maxLatencySamples is the number of latencies kept per entrypoint to
+// estimate the latency percentiles of a run.
+const maxLatencySamples = 100000
+
+// AllEntrypoints is the entrypoint name which, in SLOs, applies to all the
+// entrypoints without their own SLO.
+const AllEntrypoints = ctfe.EntrypointName("*")
+
+// SLO holds service level objectives for the valid requests to an
+// entrypoint, which are checked at the end of a hammer run. Zero latencies
+// are not checked.
+type SLO struct {
+	// P50, P90 and P99 are the highest tolerated latency percentiles, and Max
+	// the highest tolerated latency.
+	P50, P90, P99, Max time.Duration
+	// ErrorBudget is the highest tolerated fraction of valid requests which
+	// fail; if zero, no error is tolerated.
+	ErrorBudget float64
+}
+
+// ParseSLOs parses SLOs from a spec of the form
+//
+//	<entrypoint>:<objective>=<value>,...;<entrypoint>:...
+//
+// where entrypoints are ctfe.EntrypointName values, and objectives are p50,
+// p90, p99 and max, with duration values, and errors, with a fraction value.
+// The entrypoint "*" applies to all the entrypoints without their own SLO.
+// For example:
+//
+//	AddChain:p99=2s,errors=0.01;*:p50=100ms
+func ParseSLOs(spec string) (map[ctfe.EntrypointName]SLO, error) {
+	slos := make(map[ctfe.EntrypointName]SLO)
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, objectives, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("SLO %q: missing entrypoint", part)
+		}
+		ep := ctfe.EntrypointName(strings.TrimSpace(name))
+		if ep != AllEntrypoints && !isEntrypoint(ep) {
+			return nil, fmt.Errorf("SLO %q: unknown entrypoint %q", part, ep)
+		}
+		if _, ok := slos[ep]; ok {
+			return nil, fmt.Errorf("SLO %q: duplicate entrypoint %q", part, ep)
+		}
+		var slo SLO
+		for _, objective := range strings.Split(objectives, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(objective), "=")
+			if !ok {
+				return nil, fmt.Errorf("SLO %q: objective %q is not key=value", part, objective)
+			}
+			var err error
+			switch key {
+			case "p50":
+				slo.P50, err = time.ParseDuration(value)
+			case "p90":
+				slo.P90, err = time.ParseDuration(value)
+			case "p99":
+				slo.P99, err = time.ParseDuration(value)
+			case "max":
+				slo.Max, err = time.ParseDuration(value)
+			case "errors":
+				slo.ErrorBudget, err = strconv.ParseFloat(value, 64)
+				if err == nil && (slo.ErrorBudget < 0 || slo.ErrorBudget > 1) {
+					err = fmt.Errorf("fraction %v out of [0, 1]", slo.ErrorBudget)
+				}
+			default:
+				err = fmt.Errorf("unknown objective %q", key)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("SLO %q: %v", part, err)
+			}
+		}
+		slos[ep] = slo
+	}
+	return slos, nil
+}
+
+func isEntrypoint(ep ctfe.EntrypointName) bool {
+	for _, e := range ctfe.Entrypoints {
+		if e == ep {
+			return true
+		}
+	}
+	return false
+}
+
+// latencyStats records the outcomes and latencies of the valid requests to
+// an entrypoint, keeping a uniform sample of the latencies.
+type latencyStats struct {
+	requests, errors int
+	max              time.Duration
+	samples          []time.Duration
+}
+
+func (l *latencyStats) observe(d time.Duration, failed bool) {
+	l.requests++
+	if failed {
+		l.errors++
+	}
+	if d > l.max {
+		l.max = d
+	}
+	if len(l.samples) < maxLatencySamples {
+		l.samples = append(l.samples, d)
+	} else if i := rand.Intn(l.requests); i < maxLatencySamples {
+		l.samples[i] = d
+	}
+}
+
+// percentile returns the latency percentile p, in (0, 100], of the sorted
+// samples, using the nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// runStats records the requests of a hammer run, per entrypoint.
+type runStats struct {
+	mu   sync.Mutex
+	eps  map[ctfe.EntrypointName]*latencyStats
+	invs map[ctfe.EntrypointName]int
+}
+
+func newRunStats() *runStats {
+	return &runStats{eps: make(map[ctfe.EntrypointName]*latencyStats), invs: make(map[ctfe.EntrypointName]int)}
+}
+
+// observe records a valid request to ep which took d, and failed or not.
+func (r *runStats) observe(ep ctfe.EntrypointName, d time.Duration, failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.eps[ep]
+	if !ok {
+		l = &latencyStats{}
+		r.eps[ep] = l
+	}
+	l.observe(d, failed)
+}
+
+// observeInvalid records an invalid request to ep.
+func (r *runStats) observeInvalid(ep ctfe.EntrypointName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.invs[ep]++
+}
+
+// EndpointReport summarizes the requests of a hammer run to an entrypoint.
+// Latencies are in milliseconds.
+type EndpointReport struct {
+	Entrypoint      ctfe.EntrypointName `json:"entrypoint"`
+	Requests        int                 `json:"requests"`
+	Errors          int                 `json:"errors"`
+	ErrorRate       float64             `json:"error_rate"`
+	InvalidRequests int                 `json:"invalid_requests"`
+	P50             float64             `json:"p50_ms"`
+	P90             float64             `json:"p90_ms"`
+	P99             float64             `json:"p99_ms"`
+	Max             float64             `json:"max_ms"`
+	// Violations describes the objectives of the entrypoint's SLO which the
+	// run did not meet.
+	Violations []string `json:"violations,omitempty"`
+}
+
+// HammerReport summarizes a hammer run against a log.
+type HammerReport struct {
+	Prefix    string            `json:"prefix"`
+	Start     time.Time         `json:"start"`
+	Seconds   float64           `json:"seconds"`
+	Endpoints []*EndpointReport `json:"endpoints"`
+	// Error is the error which ended the run early, if any.
+	Error string `json:"error,omitempty"`
+	// Passed is set if the run completed and met all its SLOs.
+	Passed bool `json:"passed"`
+}
+
+// Violations returns the SLO violations of all the entrypoints.
+func (r *HammerReport) Violations() []string {
+	var v []string
+	for _, ep := range r.Endpoints {
+		for _, violation := range ep.Violations {
+			v = append(v, fmt.Sprintf("%s: %s", ep.Entrypoint, violation))
+		}
+	}
+	return v
+}
+
+// report builds the report of a run which started at start, and ended with
+// err, checking it against slos.
+func (r *runStats) report(prefix string, start time.Time, err error, slos map[ctfe.EntrypointName]SLO) *HammerReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rep := &HammerReport{Prefix: prefix, Start: start, Seconds: time.Since(start).Seconds()}
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	for _, ep := range ctfe.Entrypoints {
+		l, ok := r.eps[ep]
+		if !ok && r.invs[ep] == 0 {
+			continue
+		}
+		if !ok {
+			l = &latencyStats{}
+		}
+		er := &EndpointReport{Entrypoint: ep, Requests: l.requests, Errors: l.errors, InvalidRequests: r.invs[ep]}
+		if l.requests > 0 {
+			er.ErrorRate = float64(l.errors) / float64(l.requests)
+		}
+		sorted := append([]time.Duration(nil), l.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		p50, p90, p99 := percentile(sorted, 50), percentile(sorted, 90), percentile(sorted, 99)
+		er.P50, er.P90, er.P99, er.Max = ms(p50), ms(p90), ms(p99), ms(l.max)
+
+		slo, ok := slos[ep]
+		if !ok {
+			slo, ok = slos[AllEntrypoints]
+		}
+		if ok && l.requests > 0 {
+			for _, c := range []struct {
+				name      string
+				got, want time.Duration
+			}{
+				{"p50", p50, slo.P50},
+				{"p90", p90, slo.P90},
+				{"p99", p99, slo.P99},
+				{"max", l.max, slo.Max},
+			} {
+				if c.want > 0 && c.got > c.want {
+					er.Violations = append(er.Violations, fmt.Sprintf("%s latency %v > %v", c.name, c.got, c.want))
+				}
+			}
+			if er.ErrorRate > slo.ErrorBudget {
+				er.Violations = append(er.Violations, fmt.Sprintf("error rate %.4f > %.4f", er.ErrorRate, slo.ErrorBudget))
+			}
+		}
+		rep.Endpoints = append(rep.Endpoints, er)
+	}
+	if err != nil {
+		rep.Error = err.Error()
+	}
+	rep.Passed = err == nil && len(rep.Violations()) == 0
+	return rep
+}
+
+// WriteJSONReport writes the reports of hammer runs as JSON.
+func WriteJSONReport(w io.Writer, reports []*HammerReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reports)
+}
+
+var reportTmpl = template.Must(template.New("report").Funcs(template.FuncMap{
+	"ms": func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>CT hammer report</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th:first-child, td:first-child, td.violations { text-align: left; }
+.fail { background: #fdd; }
+</style>
+</head>
+<body>
+<h1>CT hammer report</h1>
+{{range .}}
+<h2>{{.Prefix}}: {{if .Passed}}passed{{else}}FAILED{{end}}</h2>
+<p>Started {{.Start.Format "2006-01-02T15:04:05Z07:00"}}, ran for {{printf "%.1f" .Seconds}}s.{{with .Error}} Error: {{.}}{{end}}</p>
+<table>
+<tr><th>Entrypoint</th><th>Requests</th><th>Errors</th><th>Error rate</th><th>Invalid</th><th>p50 (ms)</th><th>p90 (ms)</th><th>p99 (ms)</th><th>Max (ms)</th><th>SLO violations</th></tr>
+{{range .Endpoints}}<tr{{if .Violations}} class="fail"{{end}}><td>{{.Entrypoint}}</td><td>{{.Requests}}</td><td>{{.Errors}}</td><td>{{printf "%.4f" .ErrorRate}}</td><td>{{.InvalidRequests}}</td><td>{{ms .P50}}</td><td>{{ms .P90}}</td><td>{{ms .P99}}</td><td>{{ms .Max}}</td><td class="violations">{{range $i, $v := .Violations}}{{if $i}}; {{end}}{{$v}}{{end}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+// WriteHTMLReport writes the reports of hammer runs as an HTML page.
+func WriteHTMLReport(w io.Writer, reports []*HammerReport) error {
+	return reportTmpl.Execute(w, reports)
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseSLOs(t *testing.T) {
+	for _, test := range []struct {
+		spec    string
+		want    map[ctfe.EntrypointName]SLO
+		wantErr string
+	}{
+		{spec: "", want: map[ctfe.EntrypointName]SLO{}},
+		{
+			spec: "AddChain:p99=2s,errors=0.01; *:p50=100ms,p90=500ms,max=10s",
+			want: map[ctfe.EntrypointName]SLO{
+				ctfe.AddChainName: {P99: 2 * time.Second, ErrorBudget: 0.01},
+				AllEntrypoints:    {P50: 100 * time.Millisecond, P90: 500 * time.Millisecond, Max: 10 * time.Second},
+			},
+		},
+		{spec: "AddChain", wantErr: "missing entrypoint"},
+		{spec: "add-chain:p99=1s", wantErr: "unknown entrypoint"},
+		{spec: "GetSTH:p99=1s;GetSTH:p50=1s", wantErr: "duplicate entrypoint"},
+		{spec: "GetSTH:p99", wantErr: "not key=value"},
+		{spec: "GetSTH:p99=fast", wantErr: "invalid duration"},
+		{spec: "GetSTH:p75=1s", wantErr: "unknown objective"},
+		{spec: "GetSTH:errors=2", wantErr: "out of [0, 1]"},
+	} {
+		t.Run(test.spec, func(t *testing.T) {
+			got, err := ParseSLOs(test.spec)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("ParseSLOs()=%v; want error containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSLOs()=%v", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ParseSLOs() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+	for _, test := range []struct {
+		p    float64
+		want time.Duration
+	}{
+		{p: 50, want: 50 * time.Millisecond},
+		{p: 99, want: 99 * time.Millisecond},
+		{p: 100, want: 100 * time.Millisecond},
+		{p: 0.1, want: time.Millisecond},
+	} {
+		if got := percentile(sorted, test.p); got != test.want {
+			t.Errorf("percentile(%v)=%v; want %v", test.p, got, test.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile(nil)=%v; want 0", got)
+	}
+}
+
+func TestReport(t *testing.T) {
+	stats := newRunStats()
+	for i := 1; i <= 100; i++ {
+		stats.observe(ctfe.AddChainName, time.Duration(i)*time.Millisecond, i%20 == 0)
+		stats.observe(ctfe.GetSTHName, time.Millisecond, false)
+	}
+	stats.observeInvalid(ctfe.GetEntriesName)
+
+	for _, test := range []struct {
+		name           string
+		slos           map[ctfe.EntrypointName]SLO
+		err            error
+		wantViolations []string
+		wantPassed     bool
+	}{
+		{name: "no-slos", wantPassed: true},
+		{
+			name: "met",
+			slos: map[ctfe.EntrypointName]SLO{
+				ctfe.AddChainName: {P50: 50 * time.Millisecond, P99: time.Second, ErrorBudget: 0.05},
+				AllEntrypoints:    {P99: 5 * time.Millisecond},
+			},
+			wantPassed: true,
+		},
+		{
+			name: "violated",
+			slos: map[ctfe.EntrypointName]SLO{
+				ctfe.AddChainName: {P90: 80 * time.Millisecond, Max: 99 * time.Millisecond, ErrorBudget: 0.01},
+				AllEntrypoints:    {P50: time.Microsecond},
+			},
+			wantViolations: []string{
+				"AddChain: p90 latency 90ms > 80ms",
+				"AddChain: max latency 100ms > 99ms",
+				"AddChain: error rate 0.0500 > 0.0100",
+				"GetSTH: p50 latency 1ms > 1µs",
+			},
+		},
+		{name: "failed", err: errors.New("boom")},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			rep := stats.report("log", time.Now(), test.err, test.slos)
+			if diff := cmp.Diff(test.wantViolations, rep.Violations()); diff != "" {
+				t.Errorf("Violations() diff (-want +got):\n%s", diff)
+			}
+			if rep.Passed != test.wantPassed {
+				t.Errorf("Passed=%t; want %t", rep.Passed, test.wantPassed)
+			}
+			if len(rep.Endpoints) != 3 {
+				t.Fatalf("got %d endpoints; want 3", len(rep.Endpoints))
+			}
+			ep := rep.Endpoints[0]
+			if ep.Entrypoint != ctfe.AddChainName || ep.Requests != 100 || ep.Errors != 5 || ep.P50 != 50 || ep.Max != 100 {
+				t.Errorf("add-chain report %+v; want 100 requests, 5 errors, p50 50ms, max 100ms", ep)
+			}
+			if ep := rep.Endpoints[2]; ep.Entrypoint != ctfe.GetEntriesName || ep.Requests != 0 || ep.InvalidRequests != 1 {
+				t.Errorf("get-entries report %+v; want 1 invalid request", ep)
+			}
+
+			var buf bytes.Buffer
+			if err := WriteJSONReport(&buf, []*HammerReport{rep}); err != nil {
+				t.Fatalf("WriteJSONReport()=%v", err)
+			}
+			var got []*HammerReport
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("json.Unmarshal()=%v", err)
+			}
+			if len(got) != 1 || got[0].Passed != rep.Passed || len(got[0].Endpoints) != 3 {
+				t.Errorf("JSON report round trip %+v; want %+v", got, rep)
+			}
+			buf.Reset()
+			if err := WriteHTMLReport(&buf, []*HammerReport{rep}); err != nil {
+				t.Fatalf("WriteHTMLReport()=%v", err)
+			}
+			if !strings.Contains(buf.String(), "<td>AddChain</td>") {
+				t.Errorf("HTML report lacks add-chain row:\n%s", buf.String())
+			}
+		})
+	}
+}
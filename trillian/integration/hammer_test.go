@@ -15,9 +15,11 @@
 package integration
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -28,6 +30,7 @@ import (
 	"github.com/RarimoVoting/certificate-transparency-go/client"
 	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
 	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe"
 	"github.com/RarimoVoting/certificate-transparency-go/trillian/ctfe/configpb"
 	"github.com/RarimoVoting/certificate-transparency-go/x509"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -163,9 +166,155 @@ func TestHammer_NotAfter(t *testing.T) {
 	}
 }
 
+func TestNotAfterWindowForLog(t *testing.T) {
+	now := time.Now()
+	start, limit := now.Add(-24*time.Hour), now.Add(72*time.Hour)
+	tests := []struct {
+		desc                 string
+		start, limit         time.Time
+		wantStart, wantLimit time.Time
+		wantErr              bool
+	}{
+		{desc: "unsharded", wantStart: now, wantLimit: now.Add(48 * time.Hour)},
+		{desc: "sharded", start: start, limit: limit, wantStart: start, wantLimit: limit},
+		{desc: "open-start", limit: limit, wantStart: limit.Add(-48 * time.Hour), wantLimit: limit},
+		{desc: "open-limit", start: start, wantStart: start, wantLimit: start.Add(48 * time.Hour)},
+		{desc: "empty", start: limit, limit: start, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cfg := &configpb.LogConfig{}
+			if !test.start.IsZero() {
+				cfg.NotAfterStart = timestamppb.New(test.start)
+			}
+			if !test.limit.IsZero() {
+				cfg.NotAfterLimit = timestamppb.New(test.limit)
+			}
+			gotStart, gotLimit, err := NotAfterWindowForLog(cfg)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("NotAfterWindowForLog()=_,_,%v; want err=%t", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			// Allow for the passage of time in the unbounded cases.
+			if d := gotStart.Sub(test.wantStart); d < 0 || d > time.Minute {
+				t.Errorf("NotAfterWindowForLog() start=%v; want %v", gotStart, test.wantStart)
+			}
+			if d := gotLimit.Sub(test.wantLimit); d < 0 || d > time.Minute {
+				t.Errorf("NotAfterWindowForLog() limit=%v; want %v", gotLimit, test.wantLimit)
+			}
+		})
+	}
+}
+
+func TestSyntheticSpreadNotAfter(t *testing.T) {
+	keys := loadTestKeys(t)
+	start := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	limit := start.Add(72 * time.Hour)
+	generator, err := NewSyntheticChainGeneratorFromOpts(keys.leafChain, keys.signer, SyntheticChainOptions{
+		NotAfterStart:   start,
+		NotAfterLimit:   limit,
+		PreIssuerChance: 2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to build chain generator: %v", err)
+	}
+
+	seen := make(map[time.Time]bool)
+	for i := 0; i < 20; i++ {
+		chain, err := generator.CertChain()
+		if err != nil {
+			t.Fatalf("CertChain()=%v", err)
+		}
+		prechain, _, err := generator.PreCertChain()
+		if err != nil {
+			t.Fatalf("PreCertChain()=%v", err)
+		}
+		for _, c := range []ct.ASN1Cert{chain[0], prechain[0]} {
+			notAfter := mustParse(t, c).NotAfter
+			if notAfter.Before(start) || !notAfter.Before(limit) {
+				t.Errorf("cert has NotAfter = %v, want %v <= NotAfter < %v", notAfter, start, limit)
+			}
+			seen[notAfter] = true
+		}
+	}
+	if len(seen) < 2 {
+		t.Errorf("got %d distinct NotAfter values; want them spread across the window", len(seen))
+	}
+
+	if _, err := NewSyntheticChainGeneratorFromOpts(keys.leafChain, keys.signer, SyntheticChainOptions{
+		NotAfterStart: limit,
+		NotAfterLimit: start,
+	}); err == nil {
+		t.Error("NewSyntheticChainGeneratorFromOpts(empty window)=_,nil; want error")
+	}
+}
+
+func TestPreIssuerPreCertChain(t *testing.T) {
+	keys := loadTestKeys(t)
+	notAfter := time.Now().Add(36 * time.Hour).Truncate(time.Second)
+	generator, err := NewSyntheticChainGeneratorFromOpts(keys.leafChain, keys.signer, SyntheticChainOptions{
+		NotAfter:        notAfter,
+		PreIssuerChance: 1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to build chain generator: %v", err)
+	}
+	prechain, tbs, err := generator.PreCertChain()
+	if err != nil {
+		t.Fatalf("PreCertChain()=%v", err)
+	}
+	if got, want := len(prechain), len(keys.leafChain)+1; got != want {
+		t.Fatalf("len(prechain)=%d; want %d", got, want)
+	}
+	if err := verifyChain(prechain); err != nil {
+		t.Errorf("verifyChain()=%v; want nil", err)
+	}
+	precert := mustParse(t, prechain[0])
+	if !precert.NotAfter.Equal(notAfter) {
+		t.Errorf("precert has NotAfter = %v, want %v", precert.NotAfter, notAfter)
+	}
+	if !isPrecertSigningCert(mustParse(t, prechain[1])) {
+		t.Error("prechain[1] is not a precert signing cert")
+	}
+
+	// The log entry is issued by the CA, with the TBS rewritten to match.
+	issuer, err := precertIssuer(prechain)
+	if err != nil {
+		t.Fatalf("precertIssuer()=%v", err)
+	}
+	if want := mustParse(t, keys.leafChain[1]); !issuer.Equal(want) {
+		t.Errorf("precertIssuer()=%q; want %q", issuer.Subject, want.Subject)
+	}
+	wantTBS, err := x509.BuildPrecertTBS(precert.RawTBSCertificate, mustParse(t, prechain[1]))
+	if err != nil {
+		t.Fatalf("BuildPrecertTBS()=%v", err)
+	}
+	if !bytes.Equal(tbs, wantTBS) {
+		t.Error("PreCertChain() returned TBS not rewritten for the precert signing cert")
+	}
+
+	// The hammer submits such chains like any other.
+	s, lc := newFakeCTServer(t)
+	defer s.close()
+	s.reset()
+	hs, err := newHammerState(&HammerConfig{
+		ChainGenerator: generator,
+		ClientPool:     RandomPool{lc},
+		LogCfg:         &configpb.LogConfig{},
+	})
+	if err != nil {
+		t.Fatalf("newHammerState() returned err = %v", err)
+	}
+	if err := hs.addPreChain(context.Background()); err != nil {
+		t.Fatalf("addPreChain()=%v", err)
+	}
+}
+
 // fakeCTServer is a fake HTTP server that mimics a CT frontend.
-// It supports add-chain and add-pre-chain methods and saves the first certificate of the chain in
-// the addCerts field.
+// It supports add-chain and add-pre-chain methods, which only check that the certificates of the
+// chain parse, and saves the first certificate of the chain in the addCerts field.
 // Callers should call reset() before usage to reset internal state and defer-call close() to ensure
 // the server is stopped and resources are freed.
 type fakeCTServer struct {
@@ -191,12 +340,20 @@ func (s *fakeCTServer) addChain(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	cert, err := x509.ParseCertificate(addReq.Chain[0])
-	if err != nil {
-		writeErr(w, http.StatusBadRequest, err)
+	if len(addReq.Chain) == 0 {
+		writeErr(w, http.StatusBadRequest, errors.New("empty chain"))
 		return
 	}
-	s.addedCerts = append(s.addedCerts, cert)
+	var chain []*x509.Certificate
+	for _, der := range addReq.Chain {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, err)
+			return
+		}
+		chain = append(chain, cert)
+	}
+	s.addedCerts = append(s.addedCerts, chain[0])
 
 	dsBytes, err := tls.Marshal(tls.DigitallySigned{})
 	if err != nil {
@@ -457,3 +614,139 @@ func TestStrictSTHConsistencySize(t *testing.T) {
 		})
 	}
 }
+
+func TestInvalidChains(t *testing.T) {
+	keys := loadTestKeys(t)
+	generator, err := NewSyntheticChainGenerator(keys.leafChain, keys.signer, time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to build chain generator: %v", err)
+	}
+
+	t.Run("BadPoison", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			prechain, err := generator.(BadPoisonChainGenerator).BadPoisonPreCertChain()
+			if err != nil {
+				t.Fatalf("BadPoisonPreCertChain()=%v", err)
+			}
+			if err := verifyChain(prechain); err != nil {
+				t.Errorf("verifyChain()=%v; want nil", err)
+			}
+			cert, err := x509.ParseCertificate(prechain[0].Data)
+			if err != nil {
+				t.Fatalf("ParseCertificate()=%v", err)
+			}
+			if _, err := ctfe.IsPrecertificate(cert); err == nil {
+				t.Error("IsPrecertificate()=nil; want error for bad poison")
+			}
+		}
+	})
+
+	for _, precert := range []bool{false, true} {
+		t.Run(fmt.Sprintf("BogusIntermediate(precert=%t)", precert), func(t *testing.T) {
+			chain, err := generator.CertChain()
+			if precert {
+				chain, _, err = generator.PreCertChain()
+			}
+			if err != nil {
+				t.Fatalf("failed to make chain: %v", err)
+			}
+			bogus, err := bogusIntermediateChain(chain)
+			if err != nil {
+				t.Fatalf("bogusIntermediateChain()=%v", err)
+			}
+			leaf, err := x509.ParseCertificate(bogus[0].Data)
+			if err != nil {
+				t.Fatalf("ParseCertificate(leaf)=%v", err)
+			}
+			if isPrecert, err := ctfe.IsPrecertificate(leaf); err != nil || isPrecert != precert {
+				t.Errorf("IsPrecertificate()=%t, %v; want %t, nil", isPrecert, err, precert)
+			}
+			intermediate, err := x509.ParseCertificate(bogus[1].Data)
+			if err != nil {
+				t.Fatalf("ParseCertificate(intermediate)=%v", err)
+			}
+			if got, want := intermediate.Subject.String(), mustParse(t, chain[1]).Subject.String(); got != want {
+				t.Errorf("bogus intermediate subject %q; want %q", got, want)
+			}
+			if err := leaf.CheckSignatureFrom(intermediate); err != nil {
+				t.Errorf("leaf not signed by bogus intermediate: %v", err)
+			}
+			if err := leaf.CheckSignatureFrom(mustParse(t, chain[1])); err == nil {
+				t.Error("leaf signed by the real intermediate")
+			}
+		})
+	}
+}
+
+func mustParse(t *testing.T, c ct.ASN1Cert) *x509.Certificate {
+	t.Helper()
+	cert, err := x509.ParseCertificate(c.Data)
+	if err != nil {
+		t.Fatalf("ParseCertificate()=%v", err)
+	}
+	return cert
+}
+
+func TestAddChainInvalid(t *testing.T) {
+	keys := loadTestKeys(t)
+	generator, err := NewSyntheticChainGenerator(keys.leafChain, keys.signer, time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to build chain generator: %v", err)
+	}
+	s, lc := newFakeCTServer(t)
+	defer s.close()
+	hs, err := newHammerState(&HammerConfig{
+		ChainGenerator: generator,
+		ClientPool:     RandomPool{lc},
+		LogCfg:         &configpb.LogConfig{},
+	})
+	if err != nil {
+		t.Fatalf("newHammerState()=%v", err)
+	}
+
+	ctx := context.Background()
+	for _, test := range []struct {
+		choice Choice
+		// wantErr is set for the choices which the fake server, which does
+		// not validate chains, wrongly accepts.
+		wantErr bool
+	}{
+		{choice: UnparsableCert},
+		{choice: TruncatedDER},
+		{choice: OversizedBody},
+		{choice: InvalidJSON},
+		{choice: BogusIntermediate, wantErr: true},
+	} {
+		t.Run(string(test.choice), func(t *testing.T) {
+			for name, add := range map[string]func(context.Context, Choice) error{
+				"add-chain":     hs.addChainInvalidChoice,
+				"add-pre-chain": hs.addPreChainInvalidChoice,
+			} {
+				s.reset()
+				err := add(ctx, test.choice)
+				if gotErr := err != nil; gotErr != test.wantErr {
+					t.Errorf("%s(%s)=%v; want error: %t", name, test.choice, err, test.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckRejected(t *testing.T) {
+	for _, test := range []struct {
+		err     error
+		wantErr bool
+	}{
+		{err: client.RspError{StatusCode: http.StatusBadRequest}},
+		{err: client.RspError{StatusCode: http.StatusRequestEntityTooLarge}},
+		{err: fmt.Errorf("wrapped: %w", client.RspError{StatusCode: http.StatusBadRequest})},
+		{err: client.RspError{StatusCode: http.StatusInternalServerError}, wantErr: true},
+		{err: client.RspError{StatusCode: http.StatusOK}, wantErr: true},
+		{err: io.ErrUnexpectedEOF, wantErr: true},
+	} {
+		err := checkRejected("add-chain", InvalidJSON, test.err)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("checkRejected(%v)=%v; want error: %t", test.err, err, test.wantErr)
+		}
+	}
+}
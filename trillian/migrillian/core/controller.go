@@ -16,7 +16,9 @@
 package core
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"strconv"
@@ -29,6 +31,7 @@ import (
 	"github.com/RarimoVoting/certificate-transparency-go/trillian/migrillian/configpb"
 	"k8s.io/klog/v2"
 
+	"github.com/google/trillian/client/backoff"
 	"github.com/google/trillian/monitoring"
 	"github.com/google/trillian/util/clock"
 	"github.com/google/trillian/util/election2"
@@ -52,6 +55,12 @@ type treeMetrics struct {
 	entriesStored    monitoring.Counter
 	sthTimestamp     monitoring.Gauge
 	sthTreeSize      monitoring.Gauge
+	verifiedTreeSize monitoring.Gauge
+	rootMismatches   monitoring.Counter
+	entriesPerSecond monitoring.Gauge
+	lag              monitoring.Gauge
+	fetchErrors      monitoring.Counter
+	submitErrors     monitoring.Counter
 }
 
 // initMetrics creates metrics using the factory, if not yet created.
@@ -68,10 +77,33 @@ func initMetrics(mf monitoring.MetricFactory) {
 			entriesStored:    mf.NewCounter("entries_stored", "Entries successfully submitted to Trillian.", treeID),
 			sthTimestamp:     mf.NewGauge("sth_timestamp", "Timestamp of the last seen STH.", treeID),
 			sthTreeSize:      mf.NewGauge("sth_tree_size", "Tree size of the last seen STH.", treeID),
+			verifiedTreeSize: mf.NewGauge("verified_tree_size", "Size of the Trillian tree last verified against the source log.", treeID),
+			rootMismatches:   mf.NewCounter("root_mismatches", "Number of Trillian roots which did not match the source log.", treeID),
+			entriesPerSecond: mf.NewGauge("entries_per_second", "Rate of entries submitted (or verified, in dry-run mode) since the last STH.", treeID),
+			lag:              mf.NewGauge("lag", "Number of entries of the last seen STH not submitted yet.", treeID),
+			fetchErrors:      mf.NewCounter("fetch_errors", "Number of failures to fetch entries from the source log.", treeID),
+			submitErrors:     mf.NewCounter("submit_errors", "Number of failures to submit entries to Trillian.", treeID),
 		}
 	})
 }
 
+// DefaultRootWaitTimeout is the default maximal time to wait for Trillian to
+// integrate the migrated entries before verifying its root.
+const DefaultRootWaitTimeout = 10 * time.Minute
+
+// DefaultSubmitBackoff is the default back-off strategy for retrying entry
+// submissions when Trillian write quota is exceeded.
+var DefaultSubmitBackoff = backoff.Backoff{
+	Min:    1 * time.Second,
+	Max:    1 * time.Minute,
+	Factor: 3,
+	Jitter: true,
+}
+
+// rootPollInterval is the interval between polls of the Trillian root while
+// waiting for it to integrate the migrated entries.
+var rootPollInterval = 5 * time.Second
+
 // Options holds configuration for a Controller.
 type Options struct {
 	scanner.FetcherOptions
@@ -80,6 +112,30 @@ type Options struct {
 	NoConsistencyCheck bool
 	StartDelay         time.Duration
 	StopAfter          time.Duration
+	// OnSTH, if set, is called with each STH of the source log once all the
+	// entries it covers have been submitted to Trillian, and the root of the
+	// Trillian tree has been verified against it.
+	OnSTH func(*ct.SignedTreeHead)
+	// Checkpoints, if set, persists the progress of the migration, so that
+	// an interrupted migration resumes after the last entries submitted.
+	Checkpoints scanner.CheckpointStore
+	// CheckpointInterval is the number of submitted entries after which an
+	// intermediate checkpoint is saved and the Trillian tree is verified to
+	// be a prefix of the source log. If zero, checkpoints are only made once
+	// all the entries of an STH have been submitted.
+	CheckpointInterval uint64
+	// RootWaitTimeout is the maximal time to wait for Trillian to integrate
+	// the entries of an STH before verifying its root; if zero,
+	// DefaultRootWaitTimeout is used.
+	RootWaitTimeout time.Duration
+	// SubmitBackoff is the back-off strategy for retrying entry submissions
+	// when Trillian write quota is exceeded; if its Max is zero,
+	// DefaultSubmitBackoff is used.
+	SubmitBackoff backoff.Backoff
+	// DryRun, if true, makes the Controller only fetch the entries and verify
+	// them against the source log's STH, without submitting them to Trillian
+	// or saving checkpoints.
+	DryRun bool
 }
 
 // OptionsFromConfig returns Options created from the passed in config.
@@ -132,18 +188,24 @@ func NewController(
 }
 
 // RunWhenMasterWithRestarts calls RunWhenMaster, and, if the migration is
-// configured with continuous mode, restarts it whenever it returns.
-func (c *Controller) RunWhenMasterWithRestarts(ctx context.Context) {
+// configured with continuous mode, restarts it whenever it returns. In
+// non-continuous mode, it returns the error of RunWhenMaster, so that a
+// migration is only reported as successful once it has been verified.
+func (c *Controller) RunWhenMasterWithRestarts(ctx context.Context) error {
 	uri := c.ctClient.BaseURI()
 	treeID := c.plClient.treeID
 	for run := true; run; run = c.opts.Continuous && ctx.Err() == nil {
 		klog.Infof("Starting migration Controller (%d<-%q)", treeID, uri)
 		if err := c.RunWhenMaster(ctx); err != nil {
 			klog.Errorf("Controller.RunWhenMaster(%d<-%q): %v", treeID, uri, err)
+			if !c.opts.Continuous {
+				return fmt.Errorf("migration %d<-%q: %v", treeID, uri, err)
+			}
 			continue
 		}
 		klog.Infof("Controller stopped (%d<-%q)", treeID, uri)
 	}
+	return nil
 }
 
 // RunWhenMaster is a master-elected version of Run method. It executes Run
@@ -280,6 +342,16 @@ func (c *Controller) fetchTail(ctx context.Context, begin uint64) (uint64, error
 	if int64(begin) > fo.StartIndex {
 		fo.StartIndex = int64(begin)
 	}
+	if c.opts.Checkpoints != nil {
+		cp, err := c.opts.Checkpoints.Load(ctx, c.checkpointKey())
+		if err != nil {
+			return 0, err
+		}
+		if cp != nil && cp.NextIndex > fo.StartIndex && (fo.EndIndex == 0 || cp.NextIndex <= fo.EndIndex) {
+			klog.Infof("%s: resuming from checkpoint at %d", c.label, cp.NextIndex)
+			fo.StartIndex = cp.NextIndex
+		}
+	}
 	klog.Infof("%s: fetching range [%d, %d)", c.label, fo.StartIndex, fo.EndIndex)
 
 	fetcher := scanner.NewFetcher(c.ctClient, &fo)
@@ -289,13 +361,28 @@ func (c *Controller) fetchTail(ctx context.Context, begin uint64) (uint64, error
 	}
 	metrics.sthTimestamp.Set(float64(sth.Timestamp), c.label)
 	metrics.sthTreeSize.Set(float64(sth.TreeSize), c.label)
+	metrics.lag.Set(float64(sth.TreeSize)-float64(fo.StartIndex), c.label)
 	if sth.TreeSize <= begin {
+		if c.opts.OnSTH != nil {
+			c.opts.OnSTH(sth)
+		}
 		return begin, nil
 	}
 
 	if err := c.verifyConsistency(ctx, treeSize, rootHash, sth); err != nil {
 		return 0, err
 	}
+	end := uint64(fo.EndIndex)
+	if end == 0 || end > sth.TreeSize {
+		end = sth.TreeSize
+	}
+	var dry *dryRun
+	if c.opts.DryRun && uint64(fo.StartIndex) < end {
+		if dry, err = c.newDryRun(ctx, uint64(fo.StartIndex), sth); err != nil {
+			return 0, err
+		}
+	}
+	prog := newProgress(fo.StartIndex)
 
 	var wg sync.WaitGroup
 	batches := make(chan scanner.EntryBatch, c.opts.ChannelSize)
@@ -306,7 +393,7 @@ func (c *Controller) fetchTail(ctx context.Context, begin uint64) (uint64, error
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := c.runSubmitter(cctx, batches); err != nil {
+			if err := c.runSubmitter(cctx, batches, prog, sth, dry); err != nil {
 				klog.Errorf("%s: Stopping due to submitter error: %v", c.label, err)
 				cancel() // Stop the other submitters and the Fetcher.
 			}
@@ -325,12 +412,35 @@ func (c *Controller) fetchTail(ctx context.Context, begin uint64) (uint64, error
 	close(batches)
 	wg.Wait()
 	if err != nil {
+		if ctx.Err() == nil {
+			metrics.fetchErrors.Inc(c.label)
+		}
 		return 0, err
 	}
 	// Run may have returned nil despite a cancel() call.
 	if err := cctx.Err(); err != nil {
 		return 0, fmt.Errorf("failed to fetch and submit the entire tail: %v", err)
 	}
+	if c.opts.DryRun {
+		if dry != nil {
+			if err := c.verifyDryRun(ctx, dry, end, sth); err != nil {
+				return 0, err
+			}
+		}
+		if c.opts.OnSTH != nil {
+			c.opts.OnSTH(sth)
+		}
+		return sth.TreeSize, nil
+	}
+	if err := c.saveCheckpoint(ctx, end, sth); err != nil {
+		return 0, err
+	}
+	if err := c.verifyRoot(ctx, end, sth); err != nil {
+		return 0, err
+	}
+	if c.opts.OnSTH != nil {
+		c.opts.OnSTH(sth)
+	}
 	return sth.TreeSize, nil
 }
 
@@ -353,24 +463,203 @@ func (c *Controller) verifyConsistency(ctx context.Context, treeSize uint64, roo
 		pf, rootHash, sth.SHA256RootHash[:])
 }
 
+// checkRoot checks that the Trillian tree of the given size and root hash is
+// a prefix of the source log's tree of sth, or the other way round. When the
+// sizes are equal, the root hashes must be equal.
+func (c *Controller) checkRoot(ctx context.Context, size uint64, root []byte, sth *ct.SignedTreeHead) error {
+	var err error
+	switch {
+	case size == sth.TreeSize:
+		if !bytes.Equal(root, sth.SHA256RootHash[:]) {
+			err = fmt.Errorf("root hash %x of trillian tree of size %d differs from source STH root %x", root, size, sth.SHA256RootHash[:])
+		}
+	case size == 0:
+	case size < sth.TreeSize:
+		var pf [][]byte
+		if pf, err = c.ctClient.GetSTHConsistency(ctx, size, sth.TreeSize); err != nil {
+			return err
+		}
+		if err = proof.VerifyConsistency(rfc6962.DefaultHasher, size, sth.TreeSize, pf, root, sth.SHA256RootHash[:]); err != nil {
+			err = fmt.Errorf("trillian tree of size %d is not a prefix of source STH of size %d: %v", size, sth.TreeSize, err)
+		}
+	default:
+		var pf [][]byte
+		if pf, err = c.plClient.getConsistencyProof(ctx, sth.TreeSize, size); err != nil {
+			return err
+		}
+		if err = proof.VerifyConsistency(rfc6962.DefaultHasher, sth.TreeSize, size, pf, sth.SHA256RootHash[:], root); err != nil {
+			err = fmt.Errorf("source STH of size %d is not a prefix of trillian tree of size %d: %v", sth.TreeSize, size, err)
+		}
+	}
+	if err != nil {
+		metrics.rootMismatches.Inc(c.label)
+		return err
+	}
+	metrics.verifiedTreeSize.Set(float64(size), c.label)
+	return nil
+}
+
+// verifyRoot waits until Trillian has integrated the entries up to end, and
+// checks that its tree matches the source log's tree of sth.
+func (c *Controller) verifyRoot(ctx context.Context, end uint64, sth *ct.SignedTreeHead) error {
+	timeout := c.opts.RootWaitTimeout
+	if timeout <= 0 {
+		timeout = DefaultRootWaitTimeout
+	}
+	wctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		size, root, err := c.plClient.getRoot(wctx)
+		if err != nil {
+			return err
+		}
+		if size >= end {
+			if err := c.checkRoot(ctx, size, root, sth); err != nil {
+				return err
+			}
+			klog.Infof("%s: verified Trillian tree of size %d against source STH of size %d", c.label, size, sth.TreeSize)
+			return nil
+		}
+		klog.V(1).Infof("%s: waiting for Trillian to integrate entries [%d, %d)", c.label, size, end)
+		if err := clock.SleepContext(wctx, rootPollInterval); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return fmt.Errorf("trillian tree size is %d after %v, want %d", size, timeout, end)
+			}
+			return err
+		}
+	}
+}
+
+// checkpointKey returns the key of the checkpoints of the migration.
+func (c *Controller) checkpointKey() string {
+	return c.ctClient.BaseURI() + "#" + c.label
+}
+
+// saveCheckpoint records that the entries before next have been submitted,
+// if there is a CheckpointStore.
+func (c *Controller) saveCheckpoint(ctx context.Context, next uint64, sth *ct.SignedTreeHead) error {
+	if c.opts.Checkpoints == nil {
+		return nil
+	}
+	cp := scanner.Checkpoint{LogURI: c.checkpointKey(), STH: sth, NextIndex: int64(next)}
+	if err := c.opts.Checkpoints.Save(ctx, &cp); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %v", err)
+	}
+	return nil
+}
+
+// progress tracks the prefix of the entries which have been submitted, as
+// batches may be submitted out of order.
+type progress struct {
+	mu sync.Mutex
+	// next is the index of the first entry not submitted yet.
+	next int64
+	// saved is the index of the last checkpoint.
+	saved int64
+	// done holds the ends of the batches submitted beyond next, by start.
+	done map[int64]int64
+	// started is the time when the progress started from index first.
+	started time.Time
+	first   int64
+}
+
+// newProgress returns a progress starting at index next.
+func newProgress(next int64) *progress {
+	return &progress{next: next, saved: next, done: make(map[int64]int64), started: time.Now(), first: next}
+}
+
+// rate returns the number of entries per second submitted in the prefix.
+func (p *progress) rate() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	secs := time.Since(p.started).Seconds()
+	if secs <= 0 {
+		return 0
+	}
+	return float64(p.next-p.first) / secs
+}
+
+// complete records that the entries [start, end) have been submitted. It
+// returns the new next index, and whether it has grown by at least interval
+// since the last checkpoint.
+func (p *progress) complete(start, end int64, interval uint64) (int64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done[start] = end
+	for {
+		end, ok := p.done[p.next]
+		if !ok {
+			break
+		}
+		delete(p.done, p.next)
+		p.next = end
+	}
+	if interval == 0 || uint64(p.next-p.saved) < interval {
+		return p.next, false
+	}
+	p.saved = p.next
+	return p.next, true
+}
+
+// checkpoint saves an intermediate checkpoint at next, and checks that the
+// current Trillian tree is a prefix of the source log's tree of sth.
+func (c *Controller) checkpoint(ctx context.Context, next int64, sth *ct.SignedTreeHead) error {
+	if err := c.saveCheckpoint(ctx, uint64(next), sth); err != nil {
+		return err
+	}
+	size, root, err := c.plClient.getRoot(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.checkRoot(ctx, size, root, sth); err != nil {
+		return err
+	}
+	klog.Infof("%s: checkpoint at %d, verified Trillian tree of size %d", c.label, next, size)
+	return nil
+}
+
 // runSubmitter obtains CT log entry batches from the controller's channel and
-// submits them through Trillian client. Returns when the channel is closed, or
+// submits them through Trillian client, or passes them to dry in dry-run
+// mode, recording the progress in prog. Returns when the channel is closed,
 // the client returns a non-recoverable error (an example of a recoverable
-// error is when Trillian write quota is exceeded).
-func (c *Controller) runSubmitter(ctx context.Context, batches <-chan scanner.EntryBatch) error {
+// error is when Trillian write quota is exceeded), or an intermediate
+// verification of the Trillian tree fails.
+func (c *Controller) runSubmitter(ctx context.Context, batches <-chan scanner.EntryBatch, prog *progress, sth *ct.SignedTreeHead, dry *dryRun) error {
+	bo := c.opts.SubmitBackoff
+	if bo.Max == 0 {
+		bo = DefaultSubmitBackoff
+	}
 	for b := range batches {
 		entries := float64(len(b.Entries))
 		metrics.entriesSeen.Add(entries, c.label)
 
 		end := b.Start + int64(len(b.Entries))
-		if err := c.plClient.addSequencedLeaves(ctx, &b); err != nil {
-			// addSequencedLeaves failed to submit entries despite retries. At this
-			// point there is not much we can do. Seemingly the best strategy is to
-			// shut down the Controller.
-			return fmt.Errorf("failed to add batch [%d, %d): %v", b.Start, end, err)
+		if c.opts.DryRun {
+			if err := dry.add(&b); err != nil {
+				return fmt.Errorf("failed to hash batch [%d, %d): %v", b.Start, end, err)
+			}
+			klog.V(1).Infof("%s: hashed batch [%d, %d)", c.label, b.Start, end)
+		} else {
+			if err := c.plClient.addSequencedLeaves(ctx, &b, bo); err != nil {
+				// addSequencedLeaves failed to submit entries despite retries. At
+				// this point there is not much we can do. Seemingly the best
+				// strategy is to shut down the Controller.
+				if ctx.Err() == nil {
+					metrics.submitErrors.Inc(c.label)
+				}
+				return fmt.Errorf("failed to add batch [%d, %d): %v", b.Start, end, err)
+			}
+			klog.Infof("%s: added batch [%d, %d)", c.label, b.Start, end)
+			metrics.entriesStored.Add(entries, c.label)
+		}
+		next, save := prog.complete(b.Start, end, c.opts.CheckpointInterval)
+		metrics.lag.Set(float64(sth.TreeSize)-float64(next), c.label)
+		metrics.entriesPerSecond.Set(prog.rate(), c.label)
+		if save && !c.opts.DryRun {
+			if err := c.checkpoint(ctx, next, sth); err != nil {
+				return err
+			}
 		}
-		klog.Infof("%s: added batch [%d, %d)", c.label, b.Start, end)
-		metrics.entriesStored.Add(entries, c.label)
 	}
 	return nil
 }
@@ -16,9 +16,30 @@ package core
 
 import (
 	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/client"
+	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/scanner"
+	"github.com/RarimoVoting/certificate-transparency-go/testdata"
+	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/trillian/migrillian/configpb"
+	"github.com/google/trillian"
+	"github.com/google/trillian/monitoring"
+	"github.com/google/trillian/types"
+	"github.com/google/trillian/util/election2"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"github.com/transparency-dev/merkle/testonly"
+	"google.golang.org/grpc"
 )
 
 func TestVerifyConsistencyEmptyHead(t *testing.T) {
@@ -27,3 +48,297 @@ func TestVerifyConsistencyEmptyHead(t *testing.T) {
 		t.Errorf("verifyConsistency should always succeed given empty root")
 	}
 }
+
+// sourceLog is a CT log serving the entries of an in-memory tree.
+type sourceLog struct {
+	tree    *testonly.Tree
+	entries []ct.LeafEntry
+
+	mu sync.Mutex
+	// minStart is the lowest start of the entries requested.
+	minStart int64
+}
+
+func newSourceLog(t *testing.T, size int) *sourceLog {
+	t.Helper()
+	l := &sourceLog{tree: testonly.New(rfc6962.DefaultHasher), minStart: -1}
+	extra, err := tls.Marshal(ct.CertificateChain{})
+	if err != nil {
+		t.Fatalf("tls.Marshal()=%v", err)
+	}
+	block, _ := pem.Decode([]byte(testdata.TestCertPEM))
+	for i := 0; i < size; i++ {
+		leaf := ct.MerkleTreeLeaf{
+			LeafType: ct.TimestampedEntryLeafType,
+			TimestampedEntry: &ct.TimestampedEntry{
+				Timestamp: uint64(i),
+				EntryType: ct.X509LogEntryType,
+				X509Entry: &ct.ASN1Cert{Data: block.Bytes},
+			},
+		}
+		data, err := tls.Marshal(leaf)
+		if err != nil {
+			t.Fatalf("tls.Marshal()=%v", err)
+		}
+		l.entries = append(l.entries, ct.LeafEntry{LeafInput: data, ExtraData: extra})
+		l.tree.AppendData(data)
+	}
+	return l
+}
+
+func (l *sourceLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	param := func(name string) uint64 {
+		v, _ := strconv.ParseUint(r.FormValue(name), 10, 64)
+		return v
+	}
+	var rsp interface{}
+	switch r.URL.Path {
+	case ct.GetSTHPath:
+		sig, _ := tls.Marshal(ct.DigitallySigned{Algorithm: tls.SignatureAndHashAlgorithm{Hash: tls.SHA256, Signature: tls.ECDSA}, Signature: []byte{1}})
+		rsp = ct.GetSTHResponse{TreeSize: l.tree.Size(), SHA256RootHash: l.tree.Hash(), TreeHeadSignature: sig}
+	case ct.GetEntriesPath:
+		start, end := param("start"), param("end")
+		if end >= uint64(len(l.entries)) {
+			end = uint64(len(l.entries)) - 1
+		}
+		l.mu.Lock()
+		if l.minStart < 0 || int64(start) < l.minStart {
+			l.minStart = int64(start)
+		}
+		l.mu.Unlock()
+		rsp = ct.GetEntriesResponse{Entries: l.entries[start : end+1]}
+	case ct.GetSTHConsistencyPath:
+		pf, err := l.tree.ConsistencyProof(param("first"), param("second"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rsp = ct.GetSTHConsistencyResponse{Consistency: pf}
+	case ct.GetEntryAndProofPath:
+		index, size := param("leaf_index"), param("tree_size")
+		pf, err := l.tree.InclusionProof(index, size)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rsp = ct.GetEntryAndProofResponse{LeafInput: l.entries[index].LeafInput, ExtraData: l.entries[index].ExtraData, AuditPath: pf}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	json.NewEncoder(w).Encode(rsp) // nolint:errcheck
+}
+
+// fakeTrillian is a pre-ordered Trillian log which integrates the leaves
+// submitted as soon as they form a prefix of the log.
+type fakeTrillian struct {
+	trillian.TrillianLogClient
+	mu     sync.Mutex
+	tree   *testonly.Tree
+	leaves map[int64][]byte
+	// corrupt is the index of a leaf whose value is altered, or -1.
+	corrupt int64
+	// stalled stops the integration of leaves.
+	stalled bool
+}
+
+func newFakeTrillian() *fakeTrillian {
+	return &fakeTrillian{tree: testonly.New(rfc6962.DefaultHasher), leaves: make(map[int64][]byte), corrupt: -1}
+}
+
+func (f *fakeTrillian) add(index int64, value []byte) {
+	if index == f.corrupt {
+		value = append([]byte("corrupt"), value...)
+	}
+	f.leaves[index] = value
+	for !f.stalled {
+		v, ok := f.leaves[int64(f.tree.Size())]
+		if !ok {
+			break
+		}
+		f.tree.AppendData(v)
+	}
+}
+
+func (f *fakeTrillian) AddSequencedLeaves(_ context.Context, req *trillian.AddSequencedLeavesRequest, _ ...grpc.CallOption) (*trillian.AddSequencedLeavesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, leaf := range req.Leaves {
+		f.add(leaf.LeafIndex, leaf.LeafValue)
+	}
+	return &trillian.AddSequencedLeavesResponse{}, nil
+}
+
+func (f *fakeTrillian) GetLatestSignedLogRoot(context.Context, *trillian.GetLatestSignedLogRootRequest, ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	root, err := (&types.LogRootV1{TreeSize: f.tree.Size(), RootHash: f.tree.Hash()}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: &trillian.SignedLogRoot{LogRoot: root}}, nil
+}
+
+func (f *fakeTrillian) GetConsistencyProof(_ context.Context, req *trillian.GetConsistencyProofRequest, _ ...grpc.CallOption) (*trillian.GetConsistencyProofResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pf, err := f.tree.ConsistencyProof(uint64(req.FirstTreeSize), uint64(req.SecondTreeSize))
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.GetConsistencyProofResponse{Proof: &trillian.Proof{Hashes: pf}}, nil
+}
+
+func newTestController(t *testing.T, src *sourceLog, dst *fakeTrillian, opts Options) *Controller {
+	t.Helper()
+	srv := httptest.NewServer(src)
+	t.Cleanup(srv.Close)
+	ctClient, err := client.New(srv.URL, srv.Client(), jsonclient.Options{})
+	if err != nil {
+		t.Fatalf("client.New()=%v", err)
+	}
+	plClient, err := NewPreorderedLogClient(dst, &trillian.Tree{TreeId: 1, TreeType: trillian.TreeType_PREORDERED_LOG}, configpb.IdentityFunction_SHA256_LEAF_INDEX, "1")
+	if err != nil {
+		t.Fatalf("NewPreorderedLogClient()=%v", err)
+	}
+	opts.FetcherOptions = scanner.FetcherOptions{BatchSize: 3, ParallelFetch: 2}
+	opts.Submitters = 2
+	return NewController(opts, ctClient, plClient, election2.NoopFactory{}, monitoring.InertMetricFactory{})
+}
+
+func TestRunVerifiesRoot(t *testing.T) {
+	defer func(d time.Duration) { rootPollInterval = d }(rootPollInterval)
+	rootPollInterval = time.Millisecond
+
+	for _, test := range []struct {
+		name     string
+		corrupt  int64
+		stalled  bool
+		interval uint64
+		wantErr  bool
+	}{
+		{name: "ok", corrupt: -1},
+		{name: "ok-intermediate", corrupt: -1, interval: 4},
+		{name: "corrupt", corrupt: 13, wantErr: true},
+		{name: "corrupt-intermediate", corrupt: 2, interval: 4, wantErr: true},
+		{name: "corrupt-last", corrupt: 19, interval: 4, wantErr: true},
+		{name: "not-integrated", corrupt: -1, stalled: true, wantErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			src, dst := newSourceLog(t, 20), newFakeTrillian()
+			dst.corrupt, dst.stalled = test.corrupt, test.stalled
+			checkpoints := scanner.NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoints.json"))
+			var verified *ct.SignedTreeHead
+			c := newTestController(t, src, dst, Options{
+				Checkpoints:        checkpoints,
+				CheckpointInterval: test.interval,
+				RootWaitTimeout:    100 * time.Millisecond,
+				OnSTH:              func(sth *ct.SignedTreeHead) { verified = sth },
+			})
+
+			err := c.Run(context.Background())
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Run()=%v; want error: %t", err, test.wantErr)
+			}
+			if got := verified != nil; got == test.wantErr {
+				t.Errorf("OnSTH called: %t; want %t", got, !test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			cp, err := checkpoints.Load(context.Background(), c.checkpointKey())
+			if err != nil || cp == nil || cp.NextIndex != 20 {
+				t.Errorf("Load()=%+v, %v; want checkpoint at 20", cp, err)
+			}
+		})
+	}
+}
+
+func TestRunResumes(t *testing.T) {
+	ctx := context.Background()
+	src, dst := newSourceLog(t, 20), newFakeTrillian()
+	// The first 12 entries were submitted, and 8 integrated, before the
+	// migration was interrupted.
+	for i := 0; i < 12; i++ {
+		dst.stalled = i >= 8
+		dst.add(int64(i), src.entries[i].LeafInput)
+	}
+	dst.stalled = false
+	if got := dst.tree.Size(); got != 8 {
+		t.Fatalf("Trillian tree size %d; want 8", got)
+	}
+	checkpoints := scanner.NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoints.json"))
+	c := newTestController(t, src, dst, Options{Checkpoints: checkpoints})
+	if err := c.saveCheckpoint(ctx, 12, nil); err != nil {
+		t.Fatalf("saveCheckpoint()=%v", err)
+	}
+
+	if err := c.Run(ctx); err != nil {
+		t.Fatalf("Run()=%v", err)
+	}
+	if src.minStart != 12 {
+		t.Errorf("Run() fetched entries from %d; want 12", src.minStart)
+	}
+	if got := dst.tree.Size(); got != 20 {
+		t.Errorf("Trillian tree size %d; want 20", got)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		start, end int64
+		tampered   int
+		wantErr    bool
+	}{
+		{name: "all", tampered: -1},
+		{name: "suffix", start: 7, tampered: -1},
+		{name: "range", start: 5, end: 15, tampered: -1},
+		{name: "single", start: 19, tampered: -1},
+		{name: "tampered", tampered: 11, wantErr: true},
+		{name: "tampered-suffix", start: 7, tampered: 11, wantErr: true},
+		{name: "tampered-range", start: 5, end: 15, tampered: 11, wantErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			src, dst := newSourceLog(t, 20), newFakeTrillian()
+			if test.tampered >= 0 {
+				src.entries[test.tampered] = src.entries[test.tampered+1]
+			}
+			checkpoints := scanner.NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoints.json"))
+			c := newTestController(t, src, dst, Options{Checkpoints: checkpoints, CheckpointInterval: 4, DryRun: true})
+			c.opts.StartIndex, c.opts.EndIndex = test.start, test.end
+
+			err := c.Run(context.Background())
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Run()=%v; want error: %t", err, test.wantErr)
+			}
+			if got := dst.tree.Size(); got != 0 {
+				t.Errorf("Trillian tree size %d; want 0", got)
+			}
+			if cp, err := checkpoints.Load(context.Background(), c.checkpointKey()); err != nil || cp != nil {
+				t.Errorf("Load()=%+v, %v; want no checkpoint", cp, err)
+			}
+		})
+	}
+}
+
+func TestProgress(t *testing.T) {
+	p := newProgress(10)
+	for _, test := range []struct {
+		start, end int64
+		wantNext   int64
+		wantSave   bool
+	}{
+		{start: 13, end: 16, wantNext: 10},
+		{start: 16, end: 19, wantNext: 10},
+		{start: 10, end: 13, wantNext: 19, wantSave: true},
+		{start: 22, end: 25, wantNext: 19},
+		{start: 19, end: 22, wantNext: 25, wantSave: true},
+		{start: 25, end: 28, wantNext: 28},
+	} {
+		next, save := p.complete(test.start, test.end, 5)
+		if next != test.wantNext || save != test.wantSave {
+			t.Errorf("complete(%d, %d)=%d, %t; want %d, %t", test.start, test.end, next, save, test.wantNext, test.wantSave)
+		}
+	}
+}
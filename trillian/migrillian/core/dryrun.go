@@ -0,0 +1,144 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	ct "github.com/RarimoVoting/certificate-transparency-go"
+	"github.com/RarimoVoting/certificate-transparency-go/scanner"
+	"k8s.io/klog/v2"
+
+	"github.com/transparency-dev/merkle/compact"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+var rangeFactory = &compact.RangeFactory{Hash: rfc6962.DefaultHasher.HashChildren}
+
+// dryRun verifies the entries fetched in dry-run mode, instead of submitting
+// them to Trillian: it hashes them in order into a compact range, whose root
+// must match the source log's STH.
+type dryRun struct {
+	mu  sync.Mutex
+	rng *compact.Range
+	// pending holds the batches fetched beyond the end of rng, by start.
+	pending map[int64][]ct.LeafEntry
+}
+
+// newDryRun returns a dryRun for the entries of the source log from start,
+// in its tree of sth.
+func (c *Controller) newDryRun(ctx context.Context, start uint64, sth *ct.SignedTreeHead) (*dryRun, error) {
+	rng, err := c.prefixRange(ctx, start, sth)
+	if err != nil {
+		return nil, err
+	}
+	return &dryRun{rng: rng, pending: make(map[int64][]ct.LeafEntry)}, nil
+}
+
+// prefixRange returns the compact range of the entries [0, start) of the
+// source log's tree of sth. The nodes of the range are the left siblings in
+// the inclusion proof of entry start, which is verified against sth.
+func (c *Controller) prefixRange(ctx context.Context, start uint64, sth *ct.SignedTreeHead) (*compact.Range, error) {
+	if start == 0 {
+		return rangeFactory.NewEmptyRange(0), nil
+	}
+	rsp, err := c.ctClient.GetEntryAndProof(ctx, start, sth.TreeSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proof of entry %d: %v", start, err)
+	}
+	leafHash := rfc6962.DefaultHasher.HashLeaf(rsp.LeafInput)
+	if err := proof.VerifyInclusion(rfc6962.DefaultHasher, start, sth.TreeSize, leafHash, rsp.AuditPath, sth.SHA256RootHash[:]); err != nil {
+		return nil, fmt.Errorf("invalid proof of entry %d: %v", start, err)
+	}
+	nodes, err := proof.Inclusion(start, sth.TreeSize)
+	if err != nil {
+		return nil, err
+	}
+	// The nodes IDs[begin:end] make up a single ephemeral node of the proof,
+	// which is to the right of the entry.
+	_, begin, end := nodes.Ephem()
+	var hashes [][]byte
+	for i, id := range nodes.IDs {
+		j := i
+		if i >= begin && i < end {
+			continue
+		} else if end > begin && i >= end {
+			j = i - (end - begin) + 1
+		}
+		if id.Index+1 <= start>>id.Level {
+			// The nodes are ordered by increasing level, while the compact
+			// range is ordered from left to right.
+			hashes = append([][]byte{rsp.AuditPath[j]}, hashes...)
+		}
+	}
+	return rangeFactory.NewRange(0, start, hashes)
+}
+
+// add hashes the entries of b, once all the entries before it are hashed.
+func (d *dryRun) add(b *scanner.EntryBatch) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pending[b.Start] = b.Entries
+	for {
+		start := int64(d.rng.End())
+		entries, ok := d.pending[start]
+		if !ok {
+			return nil
+		}
+		delete(d.pending, start)
+		for _, e := range entries {
+			if err := d.rng.Append(rfc6962.DefaultHasher.HashLeaf(e.LeafInput), nil); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// verifyDryRun checks that d has hashed the entries up to end, and that they
+// form the source log's tree of sth, or a prefix of it.
+func (c *Controller) verifyDryRun(ctx context.Context, d *dryRun, end uint64, sth *ct.SignedTreeHead) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if got := d.rng.End(); got != end {
+		return fmt.Errorf("fetched entries up to %d, want %d", got, end)
+	}
+	root, err := d.rng.GetRootHash(nil)
+	if err != nil {
+		return err
+	}
+	if end == sth.TreeSize {
+		if !bytes.Equal(root, sth.SHA256RootHash[:]) {
+			err = fmt.Errorf("root hash %x of fetched entries differs from source STH root %x", root, sth.SHA256RootHash[:])
+		}
+	} else {
+		var pf [][]byte
+		if pf, err = c.ctClient.GetSTHConsistency(ctx, end, sth.TreeSize); err != nil {
+			return err
+		}
+		if err = proof.VerifyConsistency(rfc6962.DefaultHasher, end, sth.TreeSize, pf, root, sth.SHA256RootHash[:]); err != nil {
+			err = fmt.Errorf("fetched entries up to %d are not a prefix of source STH of size %d: %v", end, sth.TreeSize, err)
+		}
+	}
+	if err != nil {
+		metrics.rootMismatches.Inc(c.label)
+		return err
+	}
+	klog.Infof("%s: dry run verified entries up to %d against source STH of size %d", c.label, end, sth.TreeSize)
+	return nil
+}
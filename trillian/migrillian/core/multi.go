@@ -16,21 +16,25 @@ package core
 
 import (
 	"context"
+	"errors"
 	"sync"
 )
 
 // RunMigration migrates data from a number of CT logs to Trillian. Each log's
 // migration is coordinated by the corresponding Controller. This function
 // terminates when all Controllers are done (possibly with an error, or as a
-// result of canceling the passed in context).
-func RunMigration(ctx context.Context, ctrls []*Controller) {
+// result of canceling the passed in context), and returns the errors of the
+// non-continuous migrations which did not complete and verify.
+func RunMigration(ctx context.Context, ctrls []*Controller) error {
 	var wg sync.WaitGroup
-	for _, ctrl := range ctrls {
+	errs := make([]error, len(ctrls))
+	for i, ctrl := range ctrls {
 		wg.Add(1)
-		go func(ctrl *Controller) {
+		go func(i int, ctrl *Controller) {
 			defer wg.Done()
-			ctrl.RunWhenMasterWithRestarts(ctx)
-		}(ctrl)
+			errs[i] = ctrl.RunWhenMasterWithRestarts(ctx)
+		}(i, ctrl)
 	}
 	wg.Wait()
+	return errors.Join(errs...)
 }
@@ -20,7 +20,6 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"time"
 
 	ct "github.com/RarimoVoting/certificate-transparency-go"
 	"github.com/RarimoVoting/certificate-transparency-go/scanner"
@@ -88,14 +87,27 @@ func (c *PreorderedLogClient) getRoot(ctx context.Context) (uint64, []byte, erro
 	return logRoot.TreeSize, logRoot.RootHash, nil
 }
 
+// getConsistencyProof returns a proof that the Trillian tree of size first
+// is a prefix of the one of size second.
+func (c *PreorderedLogClient) getConsistencyProof(ctx context.Context, first, second uint64) ([][]byte, error) {
+	req := trillian.GetConsistencyProofRequest{LogId: c.treeID, FirstTreeSize: int64(first), SecondTreeSize: int64(second)}
+	rsp, err := c.cli.GetConsistencyProof(ctx, &req)
+	if err != nil {
+		return nil, err
+	} else if rsp == nil || rsp.Proof == nil {
+		return nil, errors.New("missing consistency proof")
+	}
+	return rsp.Proof.Hashes, nil
+}
+
 // addSequencedLeaves converts a batch of CT log entries into Trillian log
 // leaves and submits them to Trillian via AddSequencedLeaves API.
 //
 // If and while Trillian returns "quota exceeded" errors, the function will
-// retry the request with a limited exponential back-off.
+// retry the request with the passed in limited exponential back-off.
 //
 // Returns an error if Trillian replies with a severe/unknown error.
-func (c *PreorderedLogClient) addSequencedLeaves(ctx context.Context, b *scanner.EntryBatch) error {
+func (c *PreorderedLogClient) addSequencedLeaves(ctx context.Context, b *scanner.EntryBatch, bo backoff.Backoff) error {
 	// TODO(pavelkalinnikov): Verify range inclusion against the remote STH.
 	leaves := make([]*trillian.LogLeaf, len(b.Entries))
 	for i, e := range b.Entries {
@@ -106,14 +118,6 @@ func (c *PreorderedLogClient) addSequencedLeaves(ctx context.Context, b *scanner
 	}
 	req := trillian.AddSequencedLeavesRequest{LogId: c.treeID, Leaves: leaves}
 
-	// TODO(pavelkalinnikov): Make this strategy configurable.
-	bo := backoff.Backoff{
-		Min:    1 * time.Second,
-		Max:    1 * time.Minute,
-		Factor: 3,
-		Jitter: true,
-	}
-
 	var err error
 	boerr := bo.Retry(ctx, func() error {
 		var rsp *trillian.AddSequencedLeavesResponse
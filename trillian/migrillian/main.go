@@ -33,6 +33,7 @@ import (
 
 	"github.com/RarimoVoting/certificate-transparency-go/client"
 	"github.com/RarimoVoting/certificate-transparency-go/jsonclient"
+	"github.com/RarimoVoting/certificate-transparency-go/scanner"
 	"github.com/RarimoVoting/certificate-transparency-go/trillian/migrillian/configpb"
 	"github.com/RarimoVoting/certificate-transparency-go/trillian/migrillian/core"
 	"github.com/google/trillian"
@@ -55,6 +56,19 @@ var (
 
 	metricsEndpoint = flag.String("metrics_endpoint", "localhost:8099", "Endpoint for serving metrics")
 
+	checkpointFile     = flag.String("checkpoint_file", "", "File in which to persist the progress of the migrations, so that they resume where they left off if restarted")
+	checkpointInterval = flag.Uint64("checkpoint_interval", 100000, "Number of submitted entries between intermediate checkpoints, at which progress is saved and the Trillian tree is verified against the source log (0 = only at the end)")
+	rootWaitTimeout    = flag.Duration("root_wait_timeout", core.DefaultRootWaitTimeout, "Max time to wait for Trillian to integrate the migrated entries before verifying its root hash")
+
+	batchSize        = flag.Int("batch_size", 0, "Max number of entries to fetch per get-entries request; overrides the config if non-zero")
+	numFetchers      = flag.Int("num_fetchers", 0, "Number of parallel fetchers per migration; overrides the config if non-zero")
+	numSubmitters    = flag.Int("num_submitters", 0, "Number of parallel submitters per migration; overrides the config if non-zero")
+	channelSize      = flag.Int("channel_size", 0, "Capacity of the channel of fetched batches per migration; overrides the config if non-zero")
+	adaptiveFetch    = flag.Bool("adaptive_fetch", false, "If true, reduce the fetch parallelism and back off while the source log throttles requests")
+	submitBackoffMin = flag.Duration("submit_backoff_min", core.DefaultSubmitBackoff.Min, "Initial back-off when Trillian write quota is exceeded")
+	submitBackoffMax = flag.Duration("submit_backoff_max", core.DefaultSubmitBackoff.Max, "Max back-off when Trillian write quota is exceeded")
+	dryRun           = flag.Bool("dry_run", false, "If true, only fetch the entries and verify them against the source log's STH, without submitting them to Trillian")
+
 	maxIdleConnsPerHost = flag.Int("max_idle_conns_per_host", 10, "Max idle HTTP connections per host (0 = DefaultMaxIdleConnsPerHost)")
 	maxIdleConns        = flag.Int("max_idle_conns", 100, "Max number of idle HTTP connections across all hosts (0 = unlimited)")
 )
@@ -109,7 +123,9 @@ func main() {
 	defer cancel()
 	go util.AwaitSignal(cctx, cancel)
 
-	core.RunMigration(cctx, ctrls)
+	if err := core.RunMigration(cctx, ctrls); err != nil {
+		klog.Exitf("Migration failed: %v", err)
+	}
 }
 
 // getController creates a single log migration Controller.
@@ -133,6 +149,29 @@ func getController(
 
 	opts := core.OptionsFromConfig(cfg)
 	opts.StartDelay = *electionDelay
+	opts.CheckpointInterval = *checkpointInterval
+	opts.RootWaitTimeout = *rootWaitTimeout
+	if *batchSize > 0 {
+		opts.BatchSize = *batchSize
+	}
+	if *numFetchers > 0 {
+		opts.ParallelFetch = *numFetchers
+	}
+	if *numSubmitters > 0 {
+		opts.Submitters = *numSubmitters
+	}
+	if *channelSize > 0 {
+		opts.ChannelSize = *channelSize
+	}
+	if *adaptiveFetch {
+		opts.RateControl = &scanner.RateControlOptions{}
+	}
+	opts.SubmitBackoff = core.DefaultSubmitBackoff
+	opts.SubmitBackoff.Min, opts.SubmitBackoff.Max = *submitBackoffMin, *submitBackoffMax
+	opts.DryRun = *dryRun
+	if len(*checkpointFile) > 0 {
+		opts.Checkpoints = scanner.NewFileCheckpointStore(*checkpointFile)
+	}
 	return core.NewController(opts, ctClient, plClient, ef, mf), nil
 }
 
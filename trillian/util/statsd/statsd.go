@@ -0,0 +1,213 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package statsd provides a monitoring.MetricFactory which exports metrics to
+// a statsd or DogStatsD agent.
+package statsd
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/trillian/monitoring"
+	"k8s.io/klog/v2"
+)
+
+// Client sends metric updates to a statsd agent, one per packet.
+type Client struct {
+	// DogStatsD selects the DogStatsD dialect, in which metric labels are sent
+	// as tags and histograms as distributions, in seconds. Otherwise label
+	// values are appended to the metric name, and histograms are sent as
+	// timers, in milliseconds. The histograms of the CT servers all observe
+	// latencies in seconds.
+	DogStatsD bool
+	// Prefix, if set, is prepended to all metric names, followed by a dot.
+	Prefix string
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Dial returns a Client which sends metric updates over UDP to the agent at
+// the given host:port address.
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial statsd agent at %s: %v", addr, err)
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient returns a Client which writes metric updates to w.
+func NewClient(w io.Writer) *Client {
+	return &Client{w: w}
+}
+
+// send writes a single metric update. Failures are only logged, as metrics
+// are best-effort.
+func (c *Client) send(name string, labelNames, labelVals []string, val float64, kind string) {
+	var b strings.Builder
+	if len(c.Prefix) > 0 {
+		b.WriteString(c.Prefix)
+		b.WriteByte('.')
+	}
+	b.WriteString(sanitize(name))
+	if !c.DogStatsD {
+		for _, v := range labelVals {
+			b.WriteByte('.')
+			b.WriteString(sanitize(v))
+		}
+	}
+	b.WriteByte(':')
+	b.WriteString(strconv.FormatFloat(val, 'g', -1, 64))
+	b.WriteByte('|')
+	b.WriteString(kind)
+	if c.DogStatsD && len(labelNames) > 0 {
+		b.WriteString("|#")
+		for i, n := range labelNames {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(sanitize(n))
+			b.WriteByte(':')
+			if i < len(labelVals) {
+				b.WriteString(sanitize(labelVals[i]))
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := io.WriteString(c.w, b.String()); err != nil {
+		klog.V(1).Infof("Failed to send metric %s to statsd: %v", name, err)
+	}
+}
+
+// sanitize replaces the characters which are special in the statsd protocol.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', '@', ',', '#', ' ', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// MetricFactory creates metrics which are exported through a Client. Metric
+// values are also kept in memory, so that they can be read back.
+type MetricFactory struct {
+	Client *Client
+}
+
+// NewCounter creates a new Counter object backed by statsd.
+func (f MetricFactory) NewCounter(name, help string, labelNames ...string) monitoring.Counter {
+	return &counter{
+		Counter:    monitoring.InertMetricFactory{}.NewCounter(name, help, labelNames...),
+		client:     f.Client,
+		name:       name,
+		labelNames: labelNames,
+	}
+}
+
+// NewGauge creates a new Gauge object backed by statsd.
+func (f MetricFactory) NewGauge(name, help string, labelNames ...string) monitoring.Gauge {
+	return &gauge{
+		Gauge:      monitoring.InertMetricFactory{}.NewGauge(name, help, labelNames...),
+		client:     f.Client,
+		name:       name,
+		labelNames: labelNames,
+	}
+}
+
+// NewHistogram creates a new Histogram object backed by statsd. The agent is
+// responsible for aggregating the observations.
+func (f MetricFactory) NewHistogram(name, help string, labelNames ...string) monitoring.Histogram {
+	return &histogram{
+		Histogram:  monitoring.InertMetricFactory{}.NewHistogram(name, help, labelNames...),
+		client:     f.Client,
+		name:       name,
+		labelNames: labelNames,
+	}
+}
+
+// NewHistogramWithBuckets creates a new Histogram object backed by statsd.
+// The buckets are ignored, as the agent chooses its own aggregation.
+func (f MetricFactory) NewHistogramWithBuckets(name, help string, _ []float64, labelNames ...string) monitoring.Histogram {
+	return f.NewHistogram(name, help, labelNames...)
+}
+
+type counter struct {
+	monitoring.Counter
+	client     *Client
+	name       string
+	labelNames []string
+}
+
+func (c *counter) Inc(labelVals ...string) {
+	c.Add(1, labelVals...)
+}
+
+func (c *counter) Add(val float64, labelVals ...string) {
+	c.Counter.Add(val, labelVals...)
+	c.client.send(c.name, c.labelNames, labelVals, val, "c")
+}
+
+// gauge always sends its new absolute value, so that lost packets don't
+// leave the agent's value permanently wrong.
+type gauge struct {
+	monitoring.Gauge
+	client     *Client
+	name       string
+	labelNames []string
+}
+
+func (g *gauge) Inc(labelVals ...string) {
+	g.Add(1, labelVals...)
+}
+
+func (g *gauge) Dec(labelVals ...string) {
+	g.Add(-1, labelVals...)
+}
+
+func (g *gauge) Add(val float64, labelVals ...string) {
+	g.Gauge.Add(val, labelVals...)
+	g.client.send(g.name, g.labelNames, labelVals, g.Gauge.Value(labelVals...), "g")
+}
+
+func (g *gauge) Set(val float64, labelVals ...string) {
+	g.Gauge.Set(val, labelVals...)
+	g.client.send(g.name, g.labelNames, labelVals, val, "g")
+}
+
+type histogram struct {
+	monitoring.Histogram
+	client     *Client
+	name       string
+	labelNames []string
+}
+
+func (h *histogram) Observe(val float64, labelVals ...string) {
+	h.Histogram.Observe(val, labelVals...)
+	if h.client.DogStatsD {
+		h.client.send(h.name, h.labelNames, labelVals, val, "d")
+		return
+	}
+	// Timers are in milliseconds, and observations in seconds.
+	h.client.send(h.name, h.labelNames, labelVals, val*1000, "ms")
+}
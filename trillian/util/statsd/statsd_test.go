@@ -0,0 +1,92 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statsd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// packets records each write as a separate packet.
+type packets []string
+
+func (p *packets) Write(b []byte) (int, error) {
+	*p = append(*p, string(b))
+	return len(b), nil
+}
+
+func TestMetricFactory(t *testing.T) {
+	for _, tc := range []struct {
+		desc      string
+		dogStatsD bool
+		want      []string
+	}{
+		{
+			desc: "statsd",
+			want: []string{
+				"ct.reqs.42.add_chain:1|c",
+				"ct.reqs.42.add_chain:2|c",
+				"ct.sth_size.42:10|g",
+				"ct.sth_size.42:11|g",
+				"ct.sth_size.42:10|g",
+				"ct.latency.42:250|ms",
+			},
+		},
+		{
+			desc:      "dogstatsd",
+			dogStatsD: true,
+			want: []string{
+				"ct.reqs:1|c|#logid:42,ep:add_chain",
+				"ct.reqs:2|c|#logid:42,ep:add_chain",
+				"ct.sth_size:10|g|#logid:42",
+				"ct.sth_size:11|g|#logid:42",
+				"ct.sth_size:10|g|#logid:42",
+				"ct.latency:0.25|d|#logid:42",
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			var got packets
+			client := NewClient(&got)
+			client.DogStatsD = tc.dogStatsD
+			client.Prefix = "ct"
+			mf := MetricFactory{Client: client}
+
+			c := mf.NewCounter("reqs", "Requests", "logid", "ep")
+			c.Inc("42", "add chain")
+			c.Add(2, "42", "add chain")
+			g := mf.NewGauge("sth_size", "STH size", "logid")
+			g.Set(10, "42")
+			g.Inc("42")
+			g.Dec("42")
+			h := mf.NewHistogram("latency", "Latency", "logid")
+			h.Observe(0.25, "42")
+
+			if diff := cmp.Diff(tc.want, []string(got)); diff != "" {
+				t.Errorf("sent metrics diff (-want +got):\n%s", diff)
+			}
+			if got, want := c.Value("42", "add chain"), 3.0; got != want {
+				t.Errorf("counter Value()=%v, want %v", got, want)
+			}
+			if got, want := g.Value("42"), 10.0; got != want {
+				t.Errorf("gauge Value()=%v, want %v", got, want)
+			}
+			if count, sum := h.Info("42"); count != 1 || sum != 0.25 {
+				t.Errorf("histogram Info()=%d, %v; want 1, 0.25", count, sum)
+			}
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"crypto/elliptic"
 	"math/big"
 	"sync"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
 )
 
 // This file holds ECC curves that are not supported by the main Go crypto/elliptic
@@ -35,3 +37,55 @@ func secp192r1() elliptic.Curve {
 	initonce.Do(initAllCurves)
 	return p192r1
 }
+
+// specifiedECDomain holds explicit curve parameters over a prime field, the
+// ECParameters of RFC 3279 s2.3.5 (also SpecifiedECDomain of SEC 1).
+type specifiedECDomain struct {
+	Version int
+	FieldID struct {
+		FieldType  asn1.ObjectIdentifier
+		Parameters asn1.RawValue
+	}
+	Curve struct {
+		A, B []byte
+		Seed asn1.BitString `asn1:"optional"`
+	}
+	Base     []byte
+	Order    *big.Int
+	Cofactor *big.Int `asn1:"optional"`
+}
+
+// oidPrimeField is the field type of curves over prime fields, RFC 3279
+// s2.3.5.
+var oidPrimeField = asn1.ObjectIdentifier{1, 2, 840, 10045, 1, 1}
+
+// curveFromExplicitParams returns the supported curve with the parameters
+// of params, or nil if there is none. All the supported curves have a = -3.
+func curveFromExplicitParams(params *specifiedECDomain) elliptic.Curve {
+	if !params.FieldID.FieldType.Equal(oidPrimeField) {
+		return nil
+	}
+	prime := new(big.Int)
+	if rest, err := asn1.Unmarshal(params.FieldID.Parameters.FullBytes, &prime); err != nil || len(rest) != 0 {
+		return nil
+	}
+	if params.Cofactor != nil && params.Cofactor.Cmp(big.NewInt(1)) != 0 {
+		return nil
+	}
+	for _, curve := range []elliptic.Curve{elliptic.P224(), elliptic.P256(), elliptic.P384(), elliptic.P521(), secp192r1(), sm2P256V1()} {
+		cp := curve.Params()
+		if cp.P.Cmp(prime) != 0 || params.Order == nil || cp.N.Cmp(params.Order) != 0 {
+			continue
+		}
+		a := new(big.Int).SetBytes(params.Curve.A)
+		if a.Cmp(new(big.Int).Sub(cp.P, big.NewInt(3))) != 0 || new(big.Int).SetBytes(params.Curve.B).Cmp(cp.B) != 0 {
+			continue
+		}
+		x, y := elliptic.Unmarshal(curve, params.Base)
+		if x == nil || x.Cmp(cp.Gx) != 0 || y.Cmp(cp.Gy) != 0 {
+			continue
+		}
+		return curve
+	}
+	return nil
+}
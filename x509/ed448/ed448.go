@@ -0,0 +1,217 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ed448 implements the Ed448 signature algorithm (pure Ed448 with
+// an empty context) as specified in RFC 8032.
+//
+// It exists so that the x509 package can parse, verify and create
+// certificates with Ed448 keys, as it does with crypto/ed25519. Its
+// arithmetic is based on math/big, so it is neither fast nor constant-time:
+// it is suitable for verifying signatures, but private keys used with it
+// should not be exposed to timing attacks.
+package ed448
+
+import (
+	"bytes"
+	"crypto"
+	cryptorand "crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// PublicKeySize is the size, in bytes, of public keys as used in this package.
+	PublicKeySize = 57
+	// PrivateKeySize is the size, in bytes, of private keys as used in this package.
+	PrivateKeySize = 114
+	// SignatureSize is the size, in bytes, of signatures generated and verified by this package.
+	SignatureSize = 114
+	// SeedSize is the size, in bytes, of private key seeds. These are the private key representations used by RFC 8032.
+	SeedSize = 57
+)
+
+// PublicKey is the type of Ed448 public keys.
+type PublicKey []byte
+
+// Equal reports whether pub and x have the same value.
+func (pub PublicKey) Equal(x crypto.PublicKey) bool {
+	xx, ok := x.(PublicKey)
+	if !ok {
+		return false
+	}
+	return bytes.Equal(pub, xx)
+}
+
+// PrivateKey is the type of Ed448 private keys: the seed followed by the
+// public key.
+type PrivateKey []byte
+
+// Public returns the PublicKey corresponding to priv.
+func (priv PrivateKey) Public() crypto.PublicKey {
+	publicKey := make([]byte, PublicKeySize)
+	copy(publicKey, priv[SeedSize:])
+	return PublicKey(publicKey)
+}
+
+// Equal reports whether priv and x have the same value.
+func (priv PrivateKey) Equal(x crypto.PrivateKey) bool {
+	xx, ok := x.(PrivateKey)
+	if !ok {
+		return false
+	}
+	return bytes.Equal(priv, xx)
+}
+
+// Seed returns the private key seed corresponding to priv. It is provided for
+// interoperability with RFC 8032. RFC 8032's private keys correspond to seeds
+// in this package.
+func (priv PrivateKey) Seed() []byte {
+	seed := make([]byte, SeedSize)
+	copy(seed, priv[:SeedSize])
+	return seed
+}
+
+// Sign signs the given message with priv. rand is ignored. Ed448 performs
+// two passes over messages to be signed and therefore cannot handle
+// pre-hashed messages: opts.HashFunc() must return zero.
+func (priv PrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) (signature []byte, err error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, errors.New("ed448: cannot sign hashed message")
+	}
+	return Sign(priv, message), nil
+}
+
+// GenerateKey generates a public/private key pair using entropy from rand.
+// If rand is nil, crypto/rand.Reader will be used.
+func GenerateKey(rand io.Reader) (PublicKey, PrivateKey, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, nil, err
+	}
+	privateKey := NewKeyFromSeed(seed)
+	return privateKey.Public().(PublicKey), privateKey, nil
+}
+
+// NewKeyFromSeed calculates a private key from a seed. It will panic if
+// len(seed) is not SeedSize. This function is provided for interoperability
+// with RFC 8032. RFC 8032's private keys correspond to seeds in this
+// package.
+func NewKeyFromSeed(seed []byte) PrivateKey {
+	if l := len(seed); l != SeedSize {
+		panic("ed448: bad seed length: " + strconv.Itoa(l))
+	}
+	s, _ := expandSeed(seed)
+	privateKey := make([]byte, 0, PrivateKeySize)
+	privateKey = append(privateKey, seed...)
+	return append(privateKey, basePoint().mul(s).encode()...)
+}
+
+// Sign signs the message with privateKey and returns a signature. It will
+// panic if len(privateKey) is not PrivateKeySize.
+func Sign(privateKey PrivateKey, message []byte) []byte {
+	if l := len(privateKey); l != PrivateKeySize {
+		panic("ed448: bad private key length: " + strconv.Itoa(l))
+	}
+	s, prefix := expandSeed(privateKey[:SeedSize])
+	r := hashScalar(prefix, message)
+	encodedR := basePoint().mul(r).encode()
+	k := hashScalar(encodedR, privateKey[SeedSize:], message)
+
+	// S = (r + k * s) mod L
+	k.Mul(k, s)
+	k.Add(k, r)
+	k.Mod(k, order)
+
+	signature := make([]byte, 0, SignatureSize)
+	signature = append(signature, encodedR...)
+	return append(signature, encodeScalar(k)...)
+}
+
+// Verify reports whether sig is a valid signature of message by publicKey.
+// It will panic if len(publicKey) is not PublicKeySize.
+func Verify(publicKey PublicKey, message, sig []byte) bool {
+	if l := len(publicKey); l != PublicKeySize {
+		panic("ed448: bad public key length: " + strconv.Itoa(l))
+	}
+	if len(sig) != SignatureSize {
+		return false
+	}
+	a, ok := decodePoint(publicKey)
+	if !ok {
+		return false
+	}
+	r, ok := decodePoint(sig[:57])
+	if !ok {
+		return false
+	}
+	s := decodeScalar(sig[57:])
+	if s.Cmp(order) >= 0 {
+		return false
+	}
+	k := hashScalar(sig[:57], publicKey, message)
+
+	// Check [4][S]B = [4]R + [4][k]A, as RFC 8032 section 5.2.7 does.
+	four := big.NewInt(4)
+	lhs := basePoint().mul(s).mul(four)
+	rhs := r.add(a.mul(k)).mul(four)
+	return lhs.equal(rhs)
+}
+
+// expandSeed returns the secret scalar and the prefix derived from seed, as
+// in RFC 8032 section 5.2.5.
+func expandSeed(seed []byte) (*big.Int, []byte) {
+	h := make([]byte, 114)
+	sha3.ShakeSum256(h, seed)
+	s := make([]byte, 57)
+	copy(s, h[:57])
+	s[0] &= 0xfc
+	s[55] |= 0x80
+	s[56] = 0
+	return decodeScalar(s), h[57:]
+}
+
+// dom4 is the domain separation prefix of pure Ed448 with an empty context.
+var dom4 = []byte("SigEd448\x00\x00")
+
+// hashScalar returns SHAKE256(dom4 || parts..., 114) as an integer modulo
+// the order of the base point.
+func hashScalar(parts ...[]byte) *big.Int {
+	h := sha3.NewShake256()
+	h.Write(dom4)
+	for _, p := range parts {
+		h.Write(p)
+	}
+	digest := make([]byte, 114)
+	h.Read(digest)
+	k := decodeScalar(digest)
+	return k.Mod(k, order)
+}
+
+// decodeScalar decodes a little-endian integer.
+func decodeScalar(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i, v := range b {
+		be[len(b)-1-i] = v
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+// encodeScalar encodes an integer modulo the order of the base point as 57
+// little-endian bytes.
+func encodeScalar(k *big.Int) []byte {
+	be := k.FillBytes(make([]byte, 57))
+	le := make([]byte, 57)
+	for i, v := range be {
+		le[56-i] = v
+	}
+	return le
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed448
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"testing"
+)
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// RFC 8032 section 7.4.
+var rfc8032Vectors = []struct {
+	desc, seed, pub, msg, sig string
+}{
+	{
+		desc: "blank",
+		seed: "6c82a562cb808d10d632be89c8513ebf6c929f34ddfa8c9f63c9960ef6e348a3528c8a3fcc2f044e39a3fc5b94492f8f032e7549a20098f95b",
+		pub:  "5fd7449b59b461fd2ce787ec616ad46a1da1342485a70e1f8a0ea75d80e96778edf124769b46c7061bd6783df1e50f6cd1fa1abeafe8256180",
+		msg:  "",
+		sig:  "533a37f6bbe457251f023c0d88f976ae2dfb504a843e34d2074fd823d41a591f2b233f034f628281f2fd7a22ddd47d7828c59bd0a21bfd3980ff0d2028d4b18a9df63e006c5d1c2d345b925d8dc00b4104852db99ac5c7cdda8530a113a0f4dbb61149f05a7363268c71d95808ff2e652600",
+	},
+	{
+		desc: "1 octet",
+		seed: "c4eab05d357007c632f3dbb48489924d552b08fe0c353a0d4a1f00acda2c463afbea67c5e8d2877c5e3bc397a659949ef8021e954e0a12274e",
+		pub:  "43ba28f430cdff456ae531545f7ecd0ac834a55d9358c0372bfa0c6c6798c0866aea01eb00742802b8438ea4cb82169c235160627b4c3a9480",
+		msg:  "03",
+		sig:  "26b8f91727bd62897af15e41eb43c377efb9c610d48f2335cb0bd0087810f4352541b143c4b981b7e18f62de8ccdf633fc1bf037ab7cd779805e0dbcc0aae1cbcee1afb2e027df36bc04dcecbf154336c19f0af7e0a6472905e799f1953d2a0ff3348ab21aa4adafd1d234441cf807c03a00",
+	},
+}
+
+func TestRFC8032Vectors(t *testing.T) {
+	for _, test := range rfc8032Vectors {
+		t.Run(test.desc, func(t *testing.T) {
+			priv := NewKeyFromSeed(mustDecode(t, test.seed))
+			pub := priv.Public().(PublicKey)
+			if want := mustDecode(t, test.pub); !bytes.Equal(pub, want) {
+				t.Errorf("Public()=%x; want %x", pub, want)
+			}
+			msg := mustDecode(t, test.msg)
+			sig := Sign(priv, msg)
+			if want := mustDecode(t, test.sig); !bytes.Equal(sig, want) {
+				t.Errorf("Sign()=%x; want %x", sig, want)
+			}
+			if !Verify(pub, msg, sig) {
+				t.Error("Verify()=false; want true")
+			}
+		})
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	pub, priv, err := GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("test message")
+	sig, err := priv.Sign(nil, msg, crypto.Hash(0))
+	if err != nil {
+		t.Fatalf("Sign()=nil,%v; want _,nil", err)
+	}
+	if !Verify(pub, msg, sig) {
+		t.Error("Verify()=false; want true")
+	}
+	if Verify(pub, []byte("wrong message"), sig) {
+		t.Error("Verify(wrong message)=true; want false")
+	}
+	badSig := append([]byte(nil), sig...)
+	badSig[SignatureSize-2] ^= 1
+	if Verify(pub, msg, badSig) {
+		t.Error("Verify(corrupted signature)=true; want false")
+	}
+	if Verify(pub, msg, sig[:SignatureSize-1]) {
+		t.Error("Verify(short signature)=true; want false")
+	}
+	if _, err := priv.Sign(nil, msg, crypto.SHA256); err == nil {
+		t.Error("Sign(SHA256)=_,nil; want error")
+	}
+	if !priv.Public().(PublicKey).Equal(pub) {
+		t.Error("Public().Equal(pub)=false; want true")
+	}
+	if !bytes.Equal(NewKeyFromSeed(priv.Seed()), priv) {
+		t.Error("NewKeyFromSeed(Seed()) differs from the key")
+	}
+}
+
+func TestBasePointOrder(t *testing.T) {
+	if !basePoint().mul(order).equal(identity()) {
+		t.Error("L*B is not the identity")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed448
+
+import (
+	"math/big"
+)
+
+// The curve edwards448 of RFC 8032 section 5.2: x^2 + y^2 = 1 + d*x^2*y^2
+// over GF(p).
+var (
+	prime, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 16)
+	curveD   = new(big.Int).Sub(prime, big.NewInt(39081))
+	// order is the order of the base point.
+	order, _ = new(big.Int).SetString("3fffffffffffffffffffffffffffffffffffffffffffffffffffffff7cca23e9c44edb49aed63690216cc2728dc58f552378c292ab5844f3", 16)
+	baseX, _ = new(big.Int).SetString("224580040295924300187604334099896036246789641632564134246125461686950415467406032909029192869357953282578032075146446173674602635247710", 10)
+	baseY, _ = new(big.Int).SetString("298819210078481492676017930443930673437544040154080242095928241372331506189835876003536878655418784733982303233503462500531545062832660", 10)
+	// sqrtExp is (p+1)/4, as p = 3 mod 4.
+	sqrtExp = new(big.Int).Rsh(new(big.Int).Add(prime, big.NewInt(1)), 2)
+)
+
+// point is a point of the curve in projective coordinates (X:Y:Z), standing
+// for (X/Z, Y/Z).
+type point struct {
+	x, y, z *big.Int
+}
+
+func basePoint() *point {
+	return &point{new(big.Int).Set(baseX), new(big.Int).Set(baseY), big.NewInt(1)}
+}
+
+func identity() *point {
+	return &point{big.NewInt(0), big.NewInt(1), big.NewInt(1)}
+}
+
+func mulMod(a, b *big.Int) *big.Int {
+	r := new(big.Int).Mul(a, b)
+	return r.Mod(r, prime)
+}
+
+func addMod(a, b *big.Int) *big.Int {
+	r := new(big.Int).Add(a, b)
+	return r.Mod(r, prime)
+}
+
+func subMod(a, b *big.Int) *big.Int {
+	r := new(big.Int).Sub(a, b)
+	return r.Mod(r, prime)
+}
+
+// add returns p + q, with the formulas of RFC 8032 section 5.2.4, which are
+// complete, so also serve for doubling.
+func (p *point) add(q *point) *point {
+	a := mulMod(p.z, q.z)
+	b := mulMod(a, a)
+	c := mulMod(p.x, q.x)
+	d := mulMod(p.y, q.y)
+	e := mulMod(curveD, mulMod(c, d))
+	f := subMod(b, e)
+	g := addMod(b, e)
+	h := mulMod(addMod(p.x, p.y), addMod(q.x, q.y))
+	return &point{
+		x: mulMod(mulMod(a, f), subMod(subMod(h, c), d)),
+		y: mulMod(mulMod(a, g), subMod(d, c)),
+		z: mulMod(f, g),
+	}
+}
+
+// mul returns k * p.
+func (p *point) mul(k *big.Int) *point {
+	r := identity()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		r = r.add(r)
+		if k.Bit(i) == 1 {
+			r = r.add(p)
+		}
+	}
+	return r
+}
+
+func (p *point) equal(q *point) bool {
+	return mulMod(p.x, q.z).Cmp(mulMod(q.x, p.z)) == 0 &&
+		mulMod(p.y, q.z).Cmp(mulMod(q.y, p.z)) == 0
+}
+
+// encode returns the 57-byte encoding of p of RFC 8032 section 5.2.2.
+func (p *point) encode() []byte {
+	zInv := new(big.Int).ModInverse(p.z, prime)
+	x, y := mulMod(p.x, zInv), mulMod(p.y, zInv)
+	out := encodeScalar(y)
+	out[56] |= byte(x.Bit(0)) << 7
+	return out
+}
+
+// decodePoint decodes a point encoded as in RFC 8032 section 5.2.3.
+func decodePoint(b []byte) (*point, bool) {
+	if len(b) != 57 {
+		return nil, false
+	}
+	sign := uint(b[56] >> 7)
+	yBytes := make([]byte, 57)
+	copy(yBytes, b)
+	yBytes[56] &= 0x7f
+	y := decodeScalar(yBytes)
+	if y.Cmp(prime) >= 0 {
+		return nil, false
+	}
+
+	// x^2 = (y^2 - 1) / (d*y^2 - 1)
+	y2 := mulMod(y, y)
+	u := subMod(y2, big.NewInt(1))
+	v := subMod(mulMod(curveD, y2), big.NewInt(1))
+	vInv := new(big.Int).ModInverse(v, prime)
+	if vInv == nil {
+		return nil, false
+	}
+	x2 := mulMod(u, vInv)
+	x := new(big.Int).Exp(x2, sqrtExp, prime)
+	if mulMod(x, x).Cmp(x2) != 0 {
+		return nil, false
+	}
+	if x.Sign() == 0 && sign == 1 {
+		return nil, false
+	}
+	if x.Bit(0) != sign {
+		x.Sub(prime, x)
+	}
+	return &point{x, y, big.NewInt(1)}, true
+}
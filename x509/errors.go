@@ -34,6 +34,8 @@ const (
 	ErrTrailingRevocationInvalidityDate
 	ErrInvalidRevocationIssuer
 	ErrUnhandledCriticalRevokedCertExtension
+	ErrCertListDeltaCRLWithoutCRLNumber
+	ErrCertListDeltaCRLNumberNotAfterBase
 
 	ErrMaxID
 )
@@ -278,6 +280,21 @@ var errorInfo = []Error{
 		Category: MalformedCRL,
 		Fatal:    true,
 	},
+	{
+		ID:       ErrCertListDeltaCRLWithoutCRLNumber,
+		Summary:  "x509: delta certificate list has no crl-number",
+		Field:    "tbsCertList.crlExtensions.*.CRLNumber",
+		SpecRef:  "RFC 5280 s5.2.3",
+		SpecText: "conforming CRL issuers MUST include this extension in all CRLs",
+		Category: MalformedCRL,
+	},
+	{
+		ID:       ErrCertListDeltaCRLNumberNotAfterBase,
+		Summary:  "x509: delta certificate list crl-number %d is not after its base-crl-number %d",
+		Field:    "tbsCertList.crlExtensions.*.BaseCRLNumber",
+		SpecRef:  "RFC 5280 s5.2.4",
+		Category: MalformedCRL,
+	},
 }
 
 func init() {
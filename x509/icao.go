@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+)
+
+// ICAO Doc 9303 Part 12, section 7.1.1
+//
+//	id-icao-mrtd-security-extensions OBJECT IDENTIFIER ::= { 2 23 136 1 1 6 }
+//	id-icao-mrtd-security-extensions-nameChange OBJECT IDENTIFIER ::= { id-icao-mrtd-security-extensions 1 }
+//	id-icao-mrtd-security-extensions-documentTypeList OBJECT IDENTIFIER ::= { id-icao-mrtd-security-extensions 2 }
+var (
+	OIDExtensionICAONameChange       = asn1.ObjectIdentifier{2, 23, 136, 1, 1, 6, 1}
+	OIDExtensionICAODocumentTypeList = asn1.ObjectIdentifier{2, 23, 136, 1, 1, 6, 2}
+)
+
+// documentTypeListSyntax is the value of the document type list extension:
+//
+//	DocumentTypeListSyntax ::= SEQUENCE {
+//	  version DocumentTypeListVersion,
+//	  docTypeList SET OF DocumentType }
+//	DocumentType ::= PrintableString(SIZE(1..2))
+type documentTypeListSyntax struct {
+	Version     int
+	DocTypeList []asn1.RawValue `asn1:"set"`
+}
+
+// parseICAODocumentTypeList parses the value of the document type list
+// extension of a Document Signer certificate. The document types written
+// with another string type than PrintableString, as some issuers do, are
+// kept, with a non-fatal error.
+func parseICAODocumentTypeList(data []byte, nfe *NonFatalErrors) []string {
+	var syntax documentTypeListSyntax
+	if rest, err := asn1.Unmarshal(data, &syntax); err != nil {
+		nfe.AddError(fmt.Errorf("x509: failed to parse ICAO document type list: %v", err))
+		return nil
+	} else if len(rest) != 0 {
+		nfe.AddError(errors.New("x509: trailing data after ICAO document type list"))
+	}
+	if syntax.Version != 0 {
+		nfe.AddError(fmt.Errorf("x509: unknown ICAO document type list version %d", syntax.Version))
+	}
+	var docTypes []string
+	for _, raw := range syntax.DocTypeList {
+		if raw.Class != asn1.ClassUniversal {
+			nfe.AddError(fmt.Errorf("x509: ICAO document type with unexpected tag %d", raw.Tag))
+			continue
+		}
+		switch raw.Tag {
+		case asn1.TagPrintableString:
+		case asn1.TagUTF8String, asn1.TagIA5String:
+			nfe.AddError(fmt.Errorf("x509: ICAO document type %q is not a PrintableString", raw.Bytes))
+		default:
+			nfe.AddError(fmt.Errorf("x509: ICAO document type with unexpected tag %d", raw.Tag))
+			continue
+		}
+		if len(raw.Bytes) < 1 || len(raw.Bytes) > 2 {
+			nfe.AddError(fmt.Errorf("x509: ICAO document type %q is not 1 or 2 characters long", raw.Bytes))
+		}
+		docTypes = append(docTypes, string(raw.Bytes))
+	}
+	if len(docTypes) == 0 {
+		nfe.AddError(errors.New("x509: empty ICAO document type list"))
+	}
+	return docTypes
+}
+
+// parseICAONameChange parses the value of the name change extension of a
+// CSCA link certificate, which should be NULL; its presence is what
+// matters.
+func parseICAONameChange(data []byte, nfe *NonFatalErrors) bool {
+	var null asn1.RawValue
+	if rest, err := asn1.Unmarshal(data, &null); err != nil || len(rest) != 0 ||
+		null.Class != asn1.ClassUniversal || null.Tag != asn1.TagNull {
+		nfe.AddError(errors.New("x509: ICAO name change extension is not NULL"))
+	}
+	return true
+}
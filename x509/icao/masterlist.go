@@ -0,0 +1,353 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package icao parses the PKI material of ICAO ePassports (ICAO Doc 9303),
+// with the certificate types of this module's x509 package.
+package icao
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/sha1" // for crypto.SHA1
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+)
+
+// ICAO Doc 9303 Part 12, section 9, and RFC 5652.
+var (
+	// OIDCSCAMasterList is the content type of CSCA master lists.
+	OIDCSCAMasterList = asn1.ObjectIdentifier{2, 23, 136, 1, 1, 2}
+
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+)
+
+// contentInfo is a CMS ContentInfo (RFC 5652 section 3).
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+// signedData is a CMS SignedData (RFC 5652 section 5.1). Certificates is the
+// implicitly tagged SET OF certificates.
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type encapsulatedContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"optional,explicit,tag:0"`
+}
+
+// signerInfo is a CMS SignerInfo (RFC 5652 section 5.3). SID is either an
+// IssuerAndSerialNumber or an implicitly tagged SubjectKeyIdentifier, and
+// SignedAttrs the implicitly tagged SET OF signed attributes.
+type signerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+// cscaMasterList is the content of a master list (ICAO Doc 9303 Part 12,
+// section 9).
+type cscaMasterList struct {
+	Version  int
+	CertList []asn1.RawValue `asn1:"set"`
+}
+
+// MasterList is a CSCA master list: a signed list of the Country Signing CA
+// certificates of a number of countries.
+type MasterList struct {
+	// Raw is the DER encoding of the master list, a CMS ContentInfo.
+	Raw     []byte
+	Version int
+	// Certificates are the CSCA certificates of the list.
+	Certificates []*x509.Certificate
+	// Signer is the Master List Signer certificate which signed the list.
+	Signer *x509.Certificate
+	// SignerCertificates are the certificates carried along with the
+	// signature, which hold Signer, and usually its issuer.
+	SignerCertificates []*x509.Certificate
+	// Errors holds the errors of the certificates which could not be parsed,
+	// and are missing from Certificates and SignerCertificates.
+	Errors []error
+}
+
+// ParseMasterList parses a DER-encoded CSCA master list, and checks its
+// signature with the signer certificate it holds. Certificates are parsed
+// tolerating the deviations selected by opts; those which cannot be parsed
+// are left out of the result and reported in its Errors. Whether the signer
+// is trusted is checked separately, by MasterList.VerifySigner.
+func ParseMasterList(data []byte, opts x509.ParseOptions) (*MasterList, error) {
+	var ci contentInfo
+	if rest, err := asn1.Unmarshal(data, &ci); err != nil {
+		return nil, fmt.Errorf("icao: failed to parse ContentInfo: %v", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("icao: trailing data after master list")
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("icao: content type %v is not SignedData", ci.ContentType)
+	}
+	var sd signedData
+	if rest, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("icao: failed to parse SignedData: %v", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("icao: trailing data after SignedData")
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(OIDCSCAMasterList) {
+		return nil, fmt.Errorf("icao: content type %v is not a CSCA master list", sd.EncapContentInfo.EContentType)
+	}
+	if len(sd.EncapContentInfo.EContent) == 0 {
+		return nil, errors.New("icao: master list content is missing")
+	}
+	if len(sd.SignerInfos) != 1 {
+		return nil, fmt.Errorf("icao: master list has %d signers, want 1", len(sd.SignerInfos))
+	}
+
+	ml := &MasterList{Raw: data}
+	ml.SignerCertificates = ml.parseCertificates(splitSet(sd.Certificates.Bytes, &ml.Errors), "SignedData", opts)
+	si := sd.SignerInfos[0]
+	for _, cert := range ml.SignerCertificates {
+		if isSigner(si.SID, cert) {
+			ml.Signer = cert
+			break
+		}
+	}
+	if ml.Signer == nil {
+		return nil, errors.New("icao: signer certificate not found in SignedData")
+	}
+	if err := checkSignerInfo(&si, sd.EncapContentInfo, ml.Signer); err != nil {
+		return nil, err
+	}
+
+	var list cscaMasterList
+	if rest, err := asn1.Unmarshal(sd.EncapContentInfo.EContent, &list); err != nil {
+		return nil, fmt.Errorf("icao: failed to parse CscaMasterList: %v", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("icao: trailing data after CscaMasterList")
+	}
+	ml.Version = list.Version
+	ders := make([][]byte, len(list.CertList))
+	for i, raw := range list.CertList {
+		ders[i] = raw.FullBytes
+	}
+	ml.Certificates = ml.parseCertificates(ders, "master list", opts)
+	return ml, nil
+}
+
+// splitSet returns the DER encodings of the elements of the SET OF whose
+// contents are data, adding any error to errs.
+func splitSet(data []byte, errs *[]error) [][]byte {
+	var ders [][]byte
+	for len(data) > 0 {
+		var raw asn1.RawValue
+		rest, err := asn1.Unmarshal(data, &raw)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("icao: failed to split certificates: %v", err))
+			break
+		}
+		ders = append(ders, raw.FullBytes)
+		data = rest
+	}
+	return ders
+}
+
+// parseCertificates parses ders, recording those which cannot be parsed
+// in ml.Errors.
+func (ml *MasterList) parseCertificates(ders [][]byte, where string, opts x509.ParseOptions) []*x509.Certificate {
+	var certs []*x509.Certificate
+	for i, der := range ders {
+		cert, _, err := x509.ParseCertificateWithOptions(der, opts)
+		if x509.IsFatal(err) {
+			ml.Errors = append(ml.Errors, fmt.Errorf("icao: certificate %d of %s: %w", i, where, err))
+			continue
+		}
+		certs = append(certs, cert)
+	}
+	return certs
+}
+
+// isSigner reports whether cert is identified by the signer identifier sid.
+func isSigner(sid asn1.RawValue, cert *x509.Certificate) bool {
+	if sid.Class == asn1.ClassContextSpecific && sid.Tag == 0 {
+		return len(cert.SubjectKeyId) > 0 && bytes.Equal(sid.Bytes, cert.SubjectKeyId)
+	}
+	var isn issuerAndSerialNumber
+	if rest, err := asn1.Unmarshal(sid.FullBytes, &isn); err != nil || len(rest) != 0 {
+		return false
+	}
+	return bytes.Equal(isn.Issuer.FullBytes, cert.RawIssuer) && isn.SerialNumber.Cmp(cert.SerialNumber) == 0
+}
+
+// checkSignerInfo checks the signature of si over the content, made with
+// the key of signer.
+func checkSignerInfo(si *signerInfo, content encapsulatedContentInfo, signer *x509.Certificate) error {
+	hash, ok := hashFromOID(si.DigestAlgorithm.Algorithm)
+	if !ok {
+		return fmt.Errorf("icao: unsupported digest algorithm %v", si.DigestAlgorithm.Algorithm)
+	}
+	algo := signatureAlgorithm(si.SignatureAlgorithm, hash)
+	if algo == x509.UnknownSignatureAlgorithm {
+		return fmt.Errorf("icao: unsupported signature algorithm %v", si.SignatureAlgorithm.Algorithm)
+	}
+
+	signed := content.EContent
+	if len(si.SignedAttrs.FullBytes) > 0 {
+		// The signature is over the DER encoding of the SET OF attributes,
+		// rather than over their implicitly tagged encoding.
+		signed = append([]byte(nil), si.SignedAttrs.FullBytes...)
+		signed[0] = 0x31
+		if err := checkSignedAttrs(signed, content, hash); err != nil {
+			return err
+		}
+	}
+	if err := signer.CheckSignature(algo, signed, si.Signature); err != nil {
+		return fmt.Errorf("icao: invalid master list signature: %v", err)
+	}
+	return nil
+}
+
+// checkSignedAttrs checks that the signed attributes, DER-encoded in attrs,
+// hold the content type and the digest of the content.
+func checkSignedAttrs(attrs []byte, content encapsulatedContentInfo, hash crypto.Hash) error {
+	var parsed []attribute
+	if rest, err := asn1.UnmarshalWithParams(attrs, &parsed, "set"); err != nil {
+		return fmt.Errorf("icao: failed to parse signed attributes: %v", err)
+	} else if len(rest) != 0 {
+		return errors.New("icao: trailing data after signed attributes")
+	}
+	var contentType asn1.ObjectIdentifier
+	var digest []byte
+	for _, attr := range parsed {
+		switch {
+		case attr.Type.Equal(oidContentType):
+			if rest, err := asn1.Unmarshal(attr.Values.Bytes, &contentType); err != nil || len(rest) != 0 {
+				return errors.New("icao: invalid content-type attribute")
+			}
+		case attr.Type.Equal(oidMessageDigest):
+			if rest, err := asn1.Unmarshal(attr.Values.Bytes, &digest); err != nil || len(rest) != 0 {
+				return errors.New("icao: invalid message-digest attribute")
+			}
+		}
+	}
+	if !contentType.Equal(content.EContentType) {
+		return fmt.Errorf("icao: signed content type %v does not match %v", contentType, content.EContentType)
+	}
+	h := hash.New()
+	h.Write(content.EContent)
+	if digest == nil || !bytes.Equal(digest, h.Sum(nil)) {
+		return errors.New("icao: message digest does not match master list content")
+	}
+	return nil
+}
+
+func hashFromOID(oid asn1.ObjectIdentifier) (crypto.Hash, bool) {
+	switch {
+	case oid.Equal(oidSHA1):
+		return crypto.SHA1, true
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256, true
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384, true
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+var (
+	rsaAlgorithms = map[crypto.Hash]x509.SignatureAlgorithm{
+		crypto.SHA1:   x509.SHA1WithRSA,
+		crypto.SHA256: x509.SHA256WithRSA,
+		crypto.SHA384: x509.SHA384WithRSA,
+		crypto.SHA512: x509.SHA512WithRSA,
+	}
+	ecdsaAlgorithms = map[crypto.Hash]x509.SignatureAlgorithm{
+		crypto.SHA1:   x509.ECDSAWithSHA1,
+		crypto.SHA256: x509.ECDSAWithSHA256,
+		crypto.SHA384: x509.ECDSAWithSHA384,
+		crypto.SHA512: x509.ECDSAWithSHA512,
+	}
+)
+
+// signatureAlgorithm returns the signature algorithm of a SignerInfo. CMS
+// allows the signature algorithm to be given as the key algorithm, in which
+// case the digest algorithm completes it.
+func signatureAlgorithm(ai pkix.AlgorithmIdentifier, hash crypto.Hash) x509.SignatureAlgorithm {
+	if algo := x509.SignatureAlgorithmFromAI(ai); algo != x509.UnknownSignatureAlgorithm {
+		return algo
+	}
+	switch {
+	case ai.Algorithm.Equal(x509.OIDPublicKeyRSA):
+		return rsaAlgorithms[hash]
+	case ai.Algorithm.Equal(x509.OIDPublicKeyECDSA):
+		return ecdsaAlgorithms[hash]
+	}
+	return x509.UnknownSignatureAlgorithm
+}
+
+// CertPool returns a pool of the CSCA certificates of the master list.
+func (ml *MasterList) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	for _, cert := range ml.Certificates {
+		pool.AddCert(cert)
+	}
+	return pool
+}
+
+// VerifySigner checks that the signer of the master list chains up to
+// opts.Roots, which must be set: the CSCA certificates of the list itself
+// cannot vouch for it. The other certificates carried with the signature are
+// used as intermediates if opts.Intermediates is nil, and any key usage is
+// accepted if opts.KeyUsages is empty.
+func (ml *MasterList) VerifySigner(opts x509.VerifyOptions) ([][]*x509.Certificate, error) {
+	if opts.Roots == nil {
+		return nil, errors.New("icao: no roots to verify the master list signer")
+	}
+	if opts.Intermediates == nil {
+		opts.Intermediates = x509.NewCertPool()
+		for _, cert := range ml.SignerCertificates {
+			if cert != ml.Signer {
+				opts.Intermediates.AddCert(cert)
+			}
+		}
+	}
+	if len(opts.KeyUsages) == 0 {
+		opts.KeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageAny}
+	}
+	return ml.Signer.Verify(opts)
+}
@@ -0,0 +1,237 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package icao
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+)
+
+var (
+	notBefore = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter  = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// makeCert issues a certificate for template, signed by parent and
+// parentKey, or self-signed if parent is nil.
+func makeCert(t *testing.T, template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.NotBefore, template.NotAfter = notBefore, notAfter
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func makeCSCA(t *testing.T, country string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	return makeCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Country: []string{country}, CommonName: "CSCA " + country},
+		SubjectKeyId:          []byte(country),
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+}
+
+func mustMarshal(t *testing.T, val interface{}) []byte {
+	t.Helper()
+	der, err := asn1.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// masterListSpec describes how to build a test master list.
+type masterListSpec struct {
+	certs      [][]byte
+	signer     *x509.Certificate
+	signerKey  *ecdsa.PrivateKey
+	extraCerts [][]byte
+	// bySKI identifies the signer by its subject key identifier.
+	bySKI bool
+	// noAttrs signs the content directly, without signed attributes.
+	noAttrs bool
+	// alterContent changes the content after it is signed.
+	alterContent bool
+}
+
+func buildMasterList(t *testing.T, spec masterListSpec) []byte {
+	t.Helper()
+	content := mustMarshal(t, cscaMasterList{CertList: rawValues(t, spec.certs)})
+	digest := sha256.Sum256(content)
+
+	var signedAttrs asn1.RawValue
+	signed := content
+	if !spec.noAttrs {
+		attrs := concat(
+			mustMarshal(t, attribute{Type: oidContentType, Values: setOf(mustMarshal(t, OIDCSCAMasterList))}),
+			mustMarshal(t, attribute{Type: oidMessageDigest, Values: setOf(mustMarshal(t, digest[:]))}),
+		)
+		signedAttrs = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attrs}
+		signed = mustMarshal(t, setOf(attrs))
+	}
+	hashed := sha256.Sum256(signed)
+	signature, err := ecdsa.SignASN1(rand.Reader, spec.signerKey, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sid := asn1.RawValue{FullBytes: mustMarshal(t, issuerAndSerialNumber{
+		Issuer:       asn1.RawValue{FullBytes: spec.signer.RawIssuer},
+		SerialNumber: spec.signer.SerialNumber,
+	})}
+	if spec.bySKI {
+		sid = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: spec.signer.SubjectKeyId}
+	}
+	if spec.alterContent {
+		content = mustMarshal(t, cscaMasterList{Version: 1, CertList: rawValues(t, spec.certs)})
+	}
+	sha256AI := pkix.AlgorithmIdentifier{Algorithm: oidSHA256}
+	sd := signedData{
+		Version:          3,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{sha256AI},
+		EncapContentInfo: encapsulatedContentInfo{EContentType: OIDCSCAMasterList, EContent: content},
+		Certificates: asn1.RawValue{
+			Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true,
+			Bytes: concat(append([][]byte{spec.signer.Raw}, spec.extraCerts...)...),
+		},
+		SignerInfos: []signerInfo{{
+			Version:            1,
+			SID:                sid,
+			DigestAlgorithm:    sha256AI,
+			SignedAttrs:        signedAttrs,
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: x509.OIDPublicKeyECDSA},
+			Signature:          signature,
+		}},
+	}
+	// The explicit tagging of the content is done by hand.
+	return mustMarshal(t, struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: mustMarshal(t, sd)},
+	})
+}
+
+func rawValues(t *testing.T, ders [][]byte) []asn1.RawValue {
+	var raws []asn1.RawValue
+	for _, der := range ders {
+		raws = append(raws, asn1.RawValue{FullBytes: der})
+	}
+	return raws
+}
+
+func setOf(contents []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: contents}
+}
+
+func TestParseMasterList(t *testing.T) {
+	cscaA, cscaAKey := makeCSCA(t, "AA")
+	cscaB, _ := makeCSCA(t, "BB")
+	signer, signerKey := makeCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{Country: []string{"AA"}, CommonName: "Master List Signer AA"},
+		SubjectKeyId: []byte{1, 2, 3},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}, cscaA, cscaAKey)
+	_, otherKey := makeCSCA(t, "CC")
+	notACert := mustMarshal(t, []int{1, 2, 3})
+	certs := [][]byte{cscaA.Raw, cscaB.Raw, notACert}
+
+	for _, test := range []struct {
+		desc    string
+		spec    masterListSpec
+		wantErr bool
+	}{
+		{desc: "signed-attrs", spec: masterListSpec{certs: certs, signer: signer, signerKey: signerKey, extraCerts: [][]byte{cscaA.Raw}}},
+		{desc: "by-ski-no-attrs", spec: masterListSpec{certs: certs, signer: signer, signerKey: signerKey, bySKI: true, noAttrs: true}},
+		{desc: "wrong-key", spec: masterListSpec{certs: certs, signer: signer, signerKey: otherKey}, wantErr: true},
+		{desc: "altered-content", spec: masterListSpec{certs: certs, signer: signer, signerKey: signerKey, alterContent: true}, wantErr: true},
+		{desc: "altered-content-no-attrs", spec: masterListSpec{certs: certs, signer: signer, signerKey: signerKey, noAttrs: true, alterContent: true}, wantErr: true},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ml, err := ParseMasterList(buildMasterList(t, test.spec), x509.ParseOptions{})
+			if err != nil {
+				if !test.wantErr {
+					t.Fatalf("ParseMasterList()=nil,%v; want _,nil", err)
+				}
+				return
+			}
+			if test.wantErr {
+				t.Fatal("ParseMasterList()=_,nil; want error")
+			}
+			if ml.Signer == nil || ml.Signer.SerialNumber.Cmp(signer.SerialNumber) != 0 {
+				t.Errorf("Signer=%v; want the master list signer", ml.Signer)
+			}
+			if len(ml.Certificates) != 2 || len(ml.Errors) != 1 {
+				t.Errorf("ParseMasterList() gave %d certificates, errors %v; want 2 and 1 error", len(ml.Certificates), ml.Errors)
+			}
+			if got := len(ml.CertPool().Subjects()); got != 2 {
+				t.Errorf("CertPool() holds %d certificates; want 2", got)
+			}
+		})
+	}
+}
+
+func TestMasterListVerifySigner(t *testing.T) {
+	cscaA, cscaAKey := makeCSCA(t, "AA")
+	cscaB, _ := makeCSCA(t, "BB")
+	signer, signerKey := makeCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{Country: []string{"AA"}, CommonName: "Master List Signer AA"},
+	}, cscaA, cscaAKey)
+	ml, err := ParseMasterList(buildMasterList(t, masterListSpec{certs: [][]byte{cscaA.Raw, cscaB.Raw}, signer: signer, signerKey: signerKey}), x509.ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseMasterList()=nil,%v; want _,nil", err)
+	}
+
+	opts := x509.VerifyOptions{CurrentTime: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if _, err := ml.VerifySigner(opts); err == nil {
+		t.Error("VerifySigner(no roots)=_,nil; want error")
+	}
+	opts.Roots = x509.NewCertPool()
+	opts.Roots.AddCert(cscaB)
+	if _, err := ml.VerifySigner(opts); err == nil {
+		t.Error("VerifySigner(other CSCA)=_,nil; want error")
+	}
+	opts.Roots.AddCert(cscaA)
+	if _, err := ml.VerifySigner(opts); err != nil {
+		t.Errorf("VerifySigner()=_,%v; want _,nil", err)
+	}
+}
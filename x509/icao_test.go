@@ -0,0 +1,210 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+)
+
+func documentTypeList(t *testing.T, tag int, docTypes ...string) []byte {
+	t.Helper()
+	var syntax documentTypeListSyntax
+	for _, dt := range docTypes {
+		syntax.DocTypeList = append(syntax.DocTypeList, asn1.RawValue{Class: asn1.ClassUniversal, Tag: tag, Bytes: []byte(dt)})
+	}
+	value, err := asn1.Marshal(syntax)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestParseICAOExtensions(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		desc           string
+		exts           []pkix.Extension
+		wantDocTypes   []string
+		wantNameChange bool
+		wantNonFatal   bool
+	}{
+		{
+			desc:         "document-types",
+			exts:         []pkix.Extension{{Id: OIDExtensionICAODocumentTypeList, Critical: true, Value: documentTypeList(t, asn1.TagPrintableString, "P", "ID")}},
+			wantDocTypes: []string{"P", "ID"},
+		},
+		{
+			desc:         "document-types-utf8",
+			exts:         []pkix.Extension{{Id: OIDExtensionICAODocumentTypeList, Value: documentTypeList(t, asn1.TagUTF8String, "P")}},
+			wantDocTypes: []string{"P"},
+			wantNonFatal: true,
+		},
+		{
+			desc:         "document-types-too-long",
+			exts:         []pkix.Extension{{Id: OIDExtensionICAODocumentTypeList, Value: documentTypeList(t, asn1.TagPrintableString, "PAS")}},
+			wantDocTypes: []string{"PAS"},
+			wantNonFatal: true,
+		},
+		{
+			desc:           "name-change",
+			exts:           []pkix.Extension{{Id: OIDExtensionICAONameChange, Value: asn1.NullBytes}},
+			wantNameChange: true,
+		},
+		{
+			desc:           "name-change-not-null",
+			exts:           []pkix.Extension{{Id: OIDExtensionICAONameChange, Value: []byte{0x04, 0x00}}},
+			wantNameChange: true,
+			wantNonFatal:   true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			template := &Certificate{
+				SerialNumber:    big.NewInt(1),
+				Subject:         pkix.Name{Country: []string{"AA"}, CommonName: "Document Signer"},
+				NotBefore:       time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				NotAfter:        time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+				ExtraExtensions: test.exts,
+			}
+			der, err := CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cert, err := ParseCertificate(der)
+			if IsFatal(err) {
+				t.Fatalf("ParseCertificate()=nil,%v; want _,non-fatal", err)
+			}
+			if gotNonFatal := err != nil; gotNonFatal != test.wantNonFatal {
+				t.Errorf("ParseCertificate()=_,%v; want non-fatal error: %v", err, test.wantNonFatal)
+			}
+			if !reflect.DeepEqual(cert.ICAODocumentTypes, test.wantDocTypes) {
+				t.Errorf("ICAODocumentTypes=%q; want %q", cert.ICAODocumentTypes, test.wantDocTypes)
+			}
+			if cert.ICAONameChange != test.wantNameChange {
+				t.Errorf("ICAONameChange=%v; want %v", cert.ICAONameChange, test.wantNameChange)
+			}
+			if len(cert.UnhandledCriticalExtensions) != 0 {
+				t.Errorf("UnhandledCriticalExtensions=%v; want none", cert.UnhandledCriticalExtensions)
+			}
+		})
+	}
+}
+
+// explicitP256Params returns the explicit parameters of P-256, with its b
+// coefficient replaced by b if not nil.
+func explicitP256Params(t *testing.T, b *big.Int) []byte {
+	t.Helper()
+	cp := elliptic.P256().Params()
+	if b == nil {
+		b = cp.B
+	}
+	var params specifiedECDomain
+	params.Version = 1
+	params.FieldID.FieldType = oidPrimeField
+	prime, err := asn1.Marshal(cp.P)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params.FieldID.Parameters = asn1.RawValue{FullBytes: prime}
+	params.Curve.A = new(big.Int).Sub(cp.P, big.NewInt(3)).FillBytes(make([]byte, 32))
+	params.Curve.B = b.FillBytes(make([]byte, 32))
+	params.Base = elliptic.Marshal(elliptic.P256(), cp.Gx, cp.Gy)
+	params.Order = cp.N
+	params.Cofactor = big.NewInt(1)
+	der, err := asn1.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+// selfSignedWithECParams returns a certificate self-signed by priv, whose
+// public key has the given DER-encoded ECDSA parameters.
+func selfSignedWithECParams(t *testing.T, priv *ecdsa.PrivateKey, params []byte) []byte {
+	t.Helper()
+	name, err := asn1.Marshal(pkix.Name{CommonName: "CSCA"}.ToRDNSequence())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigAlgo := pkix.AlgorithmIdentifier{Algorithm: oidSignatureECDSAWithSHA256}
+	pubBytes := elliptic.Marshal(priv.Curve, priv.X, priv.Y)
+	tbs := tbsCertificate{
+		Version:            2,
+		SerialNumber:       big.NewInt(1),
+		SignatureAlgorithm: sigAlgo,
+		Issuer:             asn1.RawValue{FullBytes: name},
+		Validity:           validity{NotBefore: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), NotAfter: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Subject:            asn1.RawValue{FullBytes: name},
+		PublicKey: publicKeyInfo{
+			Algorithm: pkix.AlgorithmIdentifier{Algorithm: OIDPublicKeyECDSA, Parameters: asn1.RawValue{FullBytes: params}},
+			PublicKey: asn1.BitString{Bytes: pubBytes, BitLength: 8 * len(pubBytes)},
+		},
+	}
+	tbsDER, err := asn1.Marshal(tbs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbs.Raw = tbsDER
+	digest := sha256.Sum256(tbsDER)
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := asn1.Marshal(certificate{
+		TBSCertificate:     tbs,
+		SignatureAlgorithm: sigAlgo,
+		SignatureValue:     asn1.BitString{Bytes: sig, BitLength: 8 * len(sig)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestParseExplicitCurveParameters(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der := selfSignedWithECParams(t, priv, explicitP256Params(t, nil))
+	cert, err := ParseCertificate(der)
+	if err == nil || IsFatal(err) {
+		t.Fatalf("ParseCertificate(explicit P-256)=_,%v; want non-fatal error", err)
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || pub.Curve != elliptic.P256() || pub.X.Cmp(priv.X) != 0 {
+		t.Fatalf("PublicKey=%v; want the P-256 key", cert.PublicKey)
+	}
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		t.Errorf("CheckSignature()=%v; want nil", err)
+	}
+
+	// Parameters of an unsupported curve leave the key unparsed.
+	der = selfSignedWithECParams(t, priv, explicitP256Params(t, big.NewInt(7)))
+	if _, err := ParseCertificate(der); !IsFatal(err) {
+		t.Errorf("ParseCertificate(unknown curve)=_,%v; want fatal error", err)
+	}
+	cert, deviations, err := ParseCertificateWithOptions(der, ParseOptions{Tolerate: AllDeviations})
+	if IsFatal(err) {
+		t.Fatalf("ParseCertificateWithOptions(unknown curve)=nil,%v; want _,non-fatal", err)
+	}
+	if cert.PublicKey != nil || deviations&DeviationUnknownCurve == 0 {
+		t.Errorf("ParseCertificateWithOptions(unknown curve) gave key %v, deviations %v; want nil key, %v", cert.PublicKey, deviations, DeviationUnknownCurve)
+	}
+}
@@ -54,6 +54,7 @@ type constraintsSpec struct {
 	bad  []string
 	ekus []string
 	cn   string
+	sans []string
 }
 
 type leafSpec struct {
@@ -1617,6 +1618,109 @@ var nameConstraintsTests = []nameConstraintsTest{
 		},
 		ignoreCN: true,
 	},
+
+	// #86: an excluded subtree excludes the wildcard names which cover it.
+	{
+		roots: []constraintsSpec{
+			{
+				bad: []string{"dns:bar.foo.com"},
+			},
+		},
+		intermediates: [][]constraintsSpec{
+			{
+				{},
+			},
+		},
+		leaf: leafSpec{
+			sans: []string{"dns:*.foo.com"},
+		},
+		expectedError: "\"*.foo.com\" is excluded",
+	},
+
+	// #87: an excluded subtree requiring a subdomain does not exclude a
+	// wildcard name which only covers its parent.
+	{
+		roots: []constraintsSpec{
+			{
+				bad: []string{"dns:.bar.foo.com"},
+			},
+		},
+		intermediates: [][]constraintsSpec{
+			{
+				{},
+			},
+		},
+		leaf: leafSpec{
+			sans: []string{"dns:*.foo.com"},
+		},
+	},
+
+	// #88: the names claimed by intermediates, such as cross-certificates,
+	// must be permitted by the constraints above them.
+	{
+		roots: []constraintsSpec{
+			{
+				ok: []string{"dns:foo.com"},
+			},
+		},
+		intermediates: [][]constraintsSpec{
+			{
+				{
+					sans: []string{"dns:bar.com"},
+				},
+			},
+		},
+		leaf: leafSpec{
+			sans: []string{"dns:foo.com"},
+		},
+		expectedError: "\"bar.com\" is not permitted",
+	},
+
+	// #89: the names claimed by intermediates must not be excluded by the
+	// constraints above them, whatever their type.
+	{
+		roots: []constraintsSpec{
+			{
+				bad: []string{"ip:10.0.0.0/8", "uri:bar.com"},
+			},
+		},
+		intermediates: [][]constraintsSpec{
+			{
+				{
+					sans: []string{"ip:10.1.2.3"},
+				},
+			},
+			{
+				{},
+			},
+		},
+		leaf: leafSpec{
+			sans: []string{"uri:https://foo.com/"},
+		},
+		expectedError: "\"10.1.2.3\" is excluded",
+	},
+
+	// #90: a constrained cross-certificate, alongside an unconstrained one
+	// for the same CA, only allows chains through the latter.
+	{
+		roots: make([]constraintsSpec, 1),
+		intermediates: [][]constraintsSpec{
+			{
+				{
+					bad: []string{"dns:foo.com"},
+				},
+				{},
+			},
+			{
+				{
+					sans: []string{"dns:foo.com"},
+				},
+			},
+		},
+		leaf: leafSpec{
+			sans: []string{"dns:bar.com"},
+		},
+	},
 }
 
 func makeConstraintsCACert(constraints constraintsSpec, name string, key *ecdsa.PrivateKey, parent *Certificate, parentKey *ecdsa.PrivateKey) (*Certificate, error) {
@@ -1638,6 +1742,9 @@ func makeConstraintsCACert(constraints constraintsSpec, name string, key *ecdsa.
 	if err := addConstraintsToTemplate(constraints, template); err != nil {
 		return nil, err
 	}
+	if err := addSANsToTemplate(constraints.sans, template); err != nil {
+		return nil, err
+	}
 
 	if parent == nil {
 		parent = template
@@ -1667,7 +1774,31 @@ func makeConstraintsLeafCert(leaf leafSpec, key *ecdsa.PrivateKey, parent *Certi
 		IsCA:                  false,
 	}
 
-	for _, name := range leaf.sans {
+	if err := addSANsToTemplate(leaf.sans, template); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if template.ExtKeyUsage, template.UnknownExtKeyUsage, err = parseEKUs(leaf.ekus); err != nil {
+		return nil, err
+	}
+
+	if parent == nil {
+		parent = template
+	}
+
+	derBytes, err := CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseCertificate(derBytes)
+}
+
+// addSANsToTemplate adds the names in sans, of the form "type:name", to
+// template.
+func addSANsToTemplate(sans []string, template *Certificate) error {
+	for _, name := range sans {
 		switch {
 		case strings.HasPrefix(name, "dns:"):
 			template.DNSNames = append(template.DNSNames, name[4:])
@@ -1675,14 +1806,14 @@ func makeConstraintsLeafCert(leaf leafSpec, key *ecdsa.PrivateKey, parent *Certi
 		case strings.HasPrefix(name, "ip:"):
 			ip := net.ParseIP(name[3:])
 			if ip == nil {
-				return nil, fmt.Errorf("cannot parse IP %q", name[3:])
+				return fmt.Errorf("cannot parse IP %q", name[3:])
 			}
 			template.IPAddresses = append(template.IPAddresses, ip)
 
 		case strings.HasPrefix(name, "invalidip:"):
 			ipBytes, err := hex.DecodeString(name[10:])
 			if err != nil {
-				return nil, fmt.Errorf("cannot parse invalid IP: %s", err)
+				return fmt.Errorf("cannot parse invalid IP: %s", err)
 			}
 			template.IPAddresses = append(template.IPAddresses, net.IP(ipBytes))
 
@@ -1692,7 +1823,7 @@ func makeConstraintsLeafCert(leaf leafSpec, key *ecdsa.PrivateKey, parent *Certi
 		case strings.HasPrefix(name, "uri:"):
 			uri, err := url.Parse(name[4:])
 			if err != nil {
-				return nil, fmt.Errorf("cannot parse URI %q: %s", name[4:], err)
+				return fmt.Errorf("cannot parse URI %q: %s", name[4:], err)
 			}
 			template.URIs = append(template.URIs, uri)
 
@@ -1700,7 +1831,7 @@ func makeConstraintsLeafCert(leaf leafSpec, key *ecdsa.PrivateKey, parent *Certi
 			// This is a special case for testing unknown
 			// name types. A custom SAN extension is
 			// injected into the certificate.
-			if len(leaf.sans) != 1 {
+			if len(sans) != 1 {
 				panic("when using unknown name types, it must be the sole name")
 			}
 
@@ -1716,25 +1847,11 @@ func makeConstraintsLeafCert(leaf leafSpec, key *ecdsa.PrivateKey, parent *Certi
 			})
 
 		default:
-			return nil, fmt.Errorf("unknown name type %q", name)
+			return fmt.Errorf("unknown name type %q", name)
 		}
 	}
 
-	var err error
-	if template.ExtKeyUsage, template.UnknownExtKeyUsage, err = parseEKUs(leaf.ekus); err != nil {
-		return nil, err
-	}
-
-	if parent == nil {
-		parent = template
-	}
-
-	derBytes, err := CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
-	if err != nil {
-		return nil, err
-	}
-
-	return ParseCertificate(derBytes)
+	return nil
 }
 
 func customConstraintsExtension(typeNum int, constraint []byte, isExcluded bool) pkix.Extension {
@@ -0,0 +1,204 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+)
+
+// Deviation is a set of classes of deviation from DER and RFC 5280 which
+// parsing can tolerate.
+type Deviation uint
+
+// Classes of Deviation.
+const (
+	// DeviationLaxEncoding covers the encoding errors accepted by the "lax"
+	// mode of the asn1 package: integers which are not minimally encoded,
+	// empty OIDs and invalid PrintableString characters.
+	DeviationLaxEncoding Deviation = 1 << iota
+	// DeviationBadUTF8 covers UTF8Strings which are not valid UTF-8. The
+	// invalid bytes are replaced with U+FFFD.
+	DeviationBadUTF8
+	// DeviationNegativeSerial covers negative serial numbers.
+	DeviationNegativeSerial
+	// DeviationInvalidTime covers times which are not canonically encoded,
+	// e.g. with fractional seconds or a +0000 offset.
+	DeviationInvalidTime
+	// DeviationUnknownCurve covers ECDSA public keys on unsupported named
+	// curves. The PublicKey of the certificate is left nil.
+	DeviationUnknownCurve
+	// DeviationOther covers the other problems reported as NonFatalErrors,
+	// such as malformed extensions.
+	DeviationOther
+
+	// AllDeviations tolerates every class of deviation.
+	AllDeviations = DeviationLaxEncoding | DeviationBadUTF8 | DeviationNegativeSerial | DeviationInvalidTime | DeviationUnknownCurve | DeviationOther
+)
+
+var deviationNames = []string{"lax-encoding", "bad-utf8", "negative-serial", "invalid-time", "unknown-curve", "other"}
+
+func (d Deviation) String() string {
+	var names []string
+	for i, name := range deviationNames {
+		if d&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// ParseOptions selects the deviations tolerated when parsing certificates.
+// The zero value is strict: any deviation makes parsing fail. CT logs, which
+// must accept what CAs issued, would rather tolerate AllDeviations.
+type ParseOptions struct {
+	Tolerate Deviation
+}
+
+// deviationError is a non-fatal error due to a deviation of a given class.
+type deviationError struct {
+	class Deviation
+	err   error
+}
+
+func (e deviationError) Error() string {
+	return e.err.Error()
+}
+
+func (e deviationError) Unwrap() error {
+	return e.err
+}
+
+// deviationOf returns the class of deviation of a non-fatal parsing error.
+func deviationOf(err error) Deviation {
+	var de deviationError
+	if errors.As(err, &de) {
+		return de.class
+	}
+	return DeviationOther
+}
+
+// unmarshalLax unmarshals data into val, relaxing the checks of the asn1
+// package one class of deviation at a time as needed, and adding the
+// errors of the strict checks to nfe.
+func unmarshalLax(data []byte, val interface{}, nfe *NonFatalErrors) ([]byte, error) {
+	var params []string
+	var deviations []error
+	for {
+		rest, err := asn1.UnmarshalWithParams(data, val, strings.Join(params, ","))
+		if err == nil {
+			nfe.Errors = append(nfe.Errors, deviations...)
+			return rest, nil
+		}
+		class, param := DeviationLaxEncoding, "lax"
+		switch err.(type) {
+		case asn1.InvalidUTF8Error:
+			class, param = DeviationBadUTF8, "laxutf8"
+		case asn1.InvalidTimeError:
+			class, param = DeviationInvalidTime, "laxtime"
+		}
+		for _, p := range params {
+			if p == param {
+				return nil, err
+			}
+		}
+		params = append(params, param)
+		deviations = append(deviations, deviationError{class, err})
+	}
+}
+
+// check returns the classes of deviation of the non-fatal errors in nfe,
+// and an error: the first error of a class which is not tolerated, or else
+// a NonFatalErrors holding the tolerated errors, if any.
+func (opts ParseOptions) check(nfe *NonFatalErrors) (Deviation, error) {
+	var exercised Deviation
+	for _, err := range nfe.Errors {
+		exercised |= deviationOf(err)
+	}
+	for _, err := range nfe.Errors {
+		if opts.Tolerate&deviationOf(err) == 0 {
+			return exercised, err
+		}
+	}
+	if nfe.HasError() {
+		return exercised, *nfe
+	}
+	return exercised, nil
+}
+
+// legacyNonFatal applies the fixed behaviour of the parsing functions which
+// take no ParseOptions to the non-fatal errors in nfe: invalid UTF-8,
+// invalid times and unknown curves are fatal, and negative serial numbers
+// are silently accepted.
+func legacyNonFatal(nfe *NonFatalErrors) error {
+	var kept NonFatalErrors
+	for _, err := range nfe.Errors {
+		switch deviationOf(err) {
+		case DeviationBadUTF8, DeviationInvalidTime, DeviationUnknownCurve:
+			return err
+		case DeviationNegativeSerial:
+			continue
+		}
+		kept.AddError(err)
+	}
+	if kept.HasError() {
+		return kept
+	}
+	return nil
+}
+
+// ParseCertificateWithOptions parses a single certificate from the given
+// ASN.1 DER data, tolerating the deviations selected by opts. It also returns
+// the classes of deviation met. The error is fatal if the certificate could
+// not be parsed or has a deviation which is not tolerated; otherwise it is
+// nil, or a NonFatalErrors describing the tolerated deviations.
+func ParseCertificateWithOptions(asn1Data []byte, opts ParseOptions) (*Certificate, Deviation, error) {
+	var nfe NonFatalErrors
+	cert, err := parseCertificateDER(asn1Data, &nfe)
+	if err != nil {
+		return nil, 0, err
+	}
+	exercised, err := opts.check(&nfe)
+	if IsFatal(err) {
+		return nil, exercised, err
+	}
+	return cert, exercised, err
+}
+
+// ParseCertificatesWithOptions parses one or more concatenated certificates
+// from the given ASN.1 DER data, as ParseCertificateWithOptions does.
+func ParseCertificatesWithOptions(asn1Data []byte, opts ParseOptions) ([]*Certificate, Deviation, error) {
+	var nfe NonFatalErrors
+	certs, err := parseCertificatesDER(asn1Data, &nfe)
+	if err != nil {
+		return nil, 0, err
+	}
+	exercised, err := opts.check(&nfe)
+	if IsFatal(err) {
+		return nil, exercised, err
+	}
+	return certs, exercised, err
+}
+
+// ParseTBSCertificateWithOptions parses a single TBSCertificate from the
+// given ASN.1 DER data, as ParseCertificateWithOptions does.
+func ParseTBSCertificateWithOptions(asn1Data []byte, opts ParseOptions) (*Certificate, Deviation, error) {
+	var nfe NonFatalErrors
+	cert, err := parseTBSCertificate(asn1Data, &nfe)
+	if err != nil {
+		return nil, 0, err
+	}
+	exercised, err := opts.check(&nfe)
+	if IsFatal(err) {
+		return nil, exercised, err
+	}
+	return cert, exercised, err
+}
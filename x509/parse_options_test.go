@@ -0,0 +1,203 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+)
+
+// reencode rewrites the contents of the primitive elements of der with f,
+// fixing up the lengths of the constructed elements holding them.
+func reencode(t *testing.T, der []byte, f func(tag byte, content []byte) []byte) []byte {
+	t.Helper()
+	var out []byte
+	for len(der) > 0 {
+		if len(der) < 2 {
+			t.Fatalf("truncated element %x", der)
+		}
+		tag, length, hdr := der[0], int(der[1]), 2
+		if length&0x80 != 0 {
+			n := length & 0x7f
+			length = 0
+			for _, b := range der[2 : 2+n] {
+				length = length<<8 | int(b)
+			}
+			hdr += n
+		}
+		content := der[hdr : hdr+length]
+		der = der[hdr+length:]
+		if tag&0x20 != 0 {
+			content = reencode(t, content, f)
+		} else {
+			content = f(tag, content)
+		}
+		out = append(out, tag)
+		switch l := len(content); {
+		case l < 0x80:
+			out = append(out, byte(l))
+		case l < 0x100:
+			out = append(out, 0x81, byte(l))
+		default:
+			out = append(out, 0x82, byte(l>>8), byte(l))
+		}
+		out = append(out, content...)
+	}
+	return out
+}
+
+func TestParseCertificateWithOptions(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &Certificate{
+		SerialNumber: big.NewInt(12345),
+		Subject:      pkix.Name{CommonName: "a_b"},
+		NotBefore:    time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		NotAfter:     time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	der, err := CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	negTemplate := *template
+	negTemplate.SerialNumber = big.NewInt(-12345)
+	negDER, err := CreateCertificate(rand.Reader, &negTemplate, &negTemplate, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256 := []byte{0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07}
+	secp256k1 := []byte{0x2b, 0x81, 0x04, 0x00, 0x0a}
+
+	for _, test := range []struct {
+		desc string
+		der  []byte
+		want Deviation
+		// legacyFatal is whether ParseCertificate fails.
+		legacyFatal bool
+	}{
+		{desc: "valid", der: der},
+		{
+			desc: "non-minimal-serial",
+			der: reencode(t, der, func(tag byte, content []byte) []byte {
+				if tag == 0x02 && bytes.Equal(content, []byte{0x30, 0x39}) {
+					return []byte{0x00, 0x30, 0x39}
+				}
+				return content
+			}),
+			want: DeviationLaxEncoding,
+		},
+		{
+			desc: "bad-utf8",
+			der: reencode(t, der, func(tag byte, content []byte) []byte {
+				if tag == 0x0c && string(content) == "a_b" {
+					return []byte("a\xc9b")
+				}
+				return content
+			}),
+			want:        DeviationBadUTF8,
+			legacyFatal: true,
+		},
+		{desc: "negative-serial", der: negDER, want: DeviationNegativeSerial},
+		{
+			desc: "invalid-time",
+			der: reencode(t, der, func(tag byte, content []byte) []byte {
+				if tag == 0x17 && string(content) == "200102030405Z" {
+					return []byte("200102030405+0000")
+				}
+				return content
+			}),
+			want:        DeviationInvalidTime,
+			legacyFatal: true,
+		},
+		{
+			desc: "unknown-curve",
+			der: reencode(t, der, func(tag byte, content []byte) []byte {
+				if tag == 0x06 && bytes.Equal(content, p256) {
+					return secp256k1
+				}
+				return content
+			}),
+			want:        DeviationUnknownCurve,
+			legacyFatal: true,
+		},
+		{
+			desc: "bad-utf8-and-invalid-time",
+			der: reencode(t, der, func(tag byte, content []byte) []byte {
+				switch {
+				case tag == 0x0c && string(content) == "a_b":
+					return []byte("a\xc9b")
+				case tag == 0x17 && string(content) == "200102030405Z":
+					return []byte("200102030405+0000")
+				}
+				return content
+			}),
+			want:        DeviationBadUTF8 | DeviationInvalidTime,
+			legacyFatal: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			cert, got, err := ParseCertificateWithOptions(test.der, ParseOptions{Tolerate: AllDeviations})
+			if IsFatal(err) {
+				t.Fatalf("ParseCertificateWithOptions(all)=%v", err)
+			}
+			if got != test.want {
+				t.Errorf("ParseCertificateWithOptions(all) deviations=%v, want %v", got, test.want)
+			}
+			if (err != nil) != (test.want != 0) {
+				t.Errorf("ParseCertificateWithOptions(all) err=%v, want non-fatal error: %v", err, test.want != 0)
+			}
+			if cert == nil {
+				t.Fatal("ParseCertificateWithOptions(all) returned nil certificate")
+			}
+
+			_, got, err = ParseCertificateWithOptions(test.der, ParseOptions{})
+			if fatal := IsFatal(err); fatal != (test.want != 0) {
+				t.Errorf("ParseCertificateWithOptions(strict)=%v, want fatal: %v", err, test.want != 0)
+			}
+			if got != test.want {
+				t.Errorf("ParseCertificateWithOptions(strict) deviations=%v, want %v", got, test.want)
+			}
+
+			// Tolerating all but one of the deviations met fails.
+			for d := Deviation(1); d&AllDeviations != 0; d <<= 1 {
+				if test.want&d == 0 {
+					continue
+				}
+				if _, _, err := ParseCertificateWithOptions(test.der, ParseOptions{Tolerate: AllDeviations &^ d}); !IsFatal(err) {
+					t.Errorf("ParseCertificateWithOptions(all but %v)=%v, want fatal", d, err)
+				}
+			}
+
+			if _, err := ParseCertificate(test.der); IsFatal(err) != test.legacyFatal {
+				t.Errorf("ParseCertificate()=%v, want fatal: %v", err, test.legacyFatal)
+			}
+		})
+	}
+}
+
+func TestDeviationString(t *testing.T) {
+	for _, test := range []struct {
+		d    Deviation
+		want string
+	}{
+		{0, "none"},
+		{DeviationBadUTF8, "bad-utf8"},
+		{DeviationLaxEncoding | DeviationOther, "lax-encoding|other"},
+	} {
+		if got := test.d.String(); got != test.want {
+			t.Errorf("Deviation(%d).String()=%q, want %q", test.d, got, test.want)
+		}
+	}
+}
@@ -16,6 +16,8 @@ import (
 
 	// TODO(robpercival): change this to crypto/ed25519 when Go 1.13 is min version
 	"golang.org/x/crypto/ed25519"
+
+	"github.com/RarimoVoting/certificate-transparency-go/x509/ed448"
 )
 
 // Generated using:
@@ -51,6 +53,9 @@ var pkcs8P521PrivateKeyHex = `3081ee020100301006072a8648ce3d020106052b8104002304
 // From RFC 8410, Section 7.
 var pkcs8Ed25519PrivateKeyHex = `302e020100300506032b657004220420d4ee72dbf913584ad5b6d8f1f769f8ad3afe7c28cbf1d4fbe097a88f44755842`
 
+// Holds the first Ed448 secret key of RFC 8032, Section 7.4.
+var pkcs8Ed448PrivateKeyHex = `3047020100300506032b6571043b04396c82a562cb808d10d632be89c8513ebf6c929f34ddfa8c9f63c9960ef6e348a3528c8a3fcc2f044e39a3fc5b94492f8f032e7549a20098f95b`
+
 func TestPKCS8(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -92,6 +97,11 @@ func TestPKCS8(t *testing.T) {
 			keyHex:  pkcs8Ed25519PrivateKeyHex,
 			keyType: reflect.TypeOf(ed25519.PrivateKey{}),
 		},
+		{
+			name:    "Ed448 private key",
+			keyHex:  pkcs8Ed448PrivateKeyHex,
+			keyType: reflect.TypeOf(ed448.PrivateKey{}),
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,342 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"bytes"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+)
+
+// This file implements the certificate policy processing of RFC 5280,
+// section 6.1, as updated by RFC 9618, which replaces the valid policy tree
+// by a valid policy graph so that processing takes polynomial time.
+
+// policyGraphNode is a node of the valid policy graph.
+type policyGraphNode struct {
+	validPolicy       asn1.ObjectIdentifier
+	expectedPolicySet []asn1.ObjectIdentifier
+	parents           map[*policyGraphNode]bool
+	children          map[*policyGraphNode]bool
+}
+
+func newPolicyGraphNode(valid asn1.ObjectIdentifier, parents []*policyGraphNode) *policyGraphNode {
+	n := &policyGraphNode{
+		validPolicy:       valid,
+		expectedPolicySet: []asn1.ObjectIdentifier{valid},
+		parents:           make(map[*policyGraphNode]bool),
+		children:          make(map[*policyGraphNode]bool),
+	}
+	for _, p := range parents {
+		p.children[n] = true
+		n.parents[p] = true
+	}
+	return n
+}
+
+// policyGraph is the valid policy graph, with a stratum of nodes for each
+// certificate processed, keyed by the string form of their valid policy.
+type policyGraph struct {
+	strata []map[string]*policyGraphNode
+	// parentIndex maps the expected policies of the nodes of the previous
+	// stratum to those nodes.
+	parentIndex map[string][]*policyGraphNode
+	depth       int
+}
+
+func newPolicyGraph() *policyGraph {
+	root := newPolicyGraphNode(OIDAnyPolicy, nil)
+	return &policyGraph{strata: []map[string]*policyGraphNode{{OIDAnyPolicy.String(): root}}}
+}
+
+func (pg *policyGraph) insert(n *policyGraphNode) {
+	pg.strata[pg.depth][n.validPolicy.String()] = n
+}
+
+func (pg *policyGraph) parentsWithExpected(expected asn1.ObjectIdentifier) []*policyGraphNode {
+	if pg.depth == 0 {
+		return nil
+	}
+	return pg.parentIndex[expected.String()]
+}
+
+func (pg *policyGraph) parentWithAnyPolicy() *policyGraphNode {
+	if pg.depth == 0 {
+		return nil
+	}
+	return pg.strata[pg.depth-1][OIDAnyPolicy.String()]
+}
+
+func (pg *policyGraph) parents() map[string]*policyGraphNode {
+	if pg.depth == 0 {
+		return nil
+	}
+	return pg.strata[pg.depth-1]
+}
+
+func (pg *policyGraph) leaves() map[string]*policyGraphNode {
+	return pg.strata[pg.depth]
+}
+
+func (pg *policyGraph) leafWithPolicy(policy asn1.ObjectIdentifier) *policyGraphNode {
+	return pg.strata[pg.depth][policy.String()]
+}
+
+func (pg *policyGraph) deleteLeaf(policy asn1.ObjectIdentifier) {
+	n := pg.strata[pg.depth][policy.String()]
+	if n == nil {
+		return
+	}
+	for p := range n.parents {
+		delete(p.children, n)
+	}
+	for c := range n.children {
+		delete(c.parents, n)
+	}
+	delete(pg.strata[pg.depth], policy.String())
+}
+
+// validPolicyNodes returns the nodes, other than anyPolicy ones, whose only
+// parent is an anyPolicy node.
+func (pg *policyGraph) validPolicyNodes() []*policyGraphNode {
+	var valid []*policyGraphNode
+	for i := pg.depth; i >= 0; i-- {
+		for _, n := range pg.strata[i] {
+			if n.validPolicy.Equal(OIDAnyPolicy) || len(n.parents) != 1 {
+				continue
+			}
+			for p := range n.parents {
+				if p.validPolicy.Equal(OIDAnyPolicy) {
+					valid = append(valid, n)
+				}
+			}
+		}
+	}
+	return valid
+}
+
+// prune removes the nodes without children, except in the first and last
+// strata.
+func (pg *policyGraph) prune() {
+	for i := pg.depth - 1; i > 0; i-- {
+		for key, n := range pg.strata[i] {
+			if len(n.children) == 0 {
+				for p := range n.parents {
+					delete(p.children, n)
+				}
+				delete(pg.strata[i], key)
+			}
+		}
+	}
+}
+
+func (pg *policyGraph) incrDepth() {
+	pg.parentIndex = make(map[string][]*policyGraphNode)
+	for _, n := range pg.strata[pg.depth] {
+		for _, e := range n.expectedPolicySet {
+			pg.parentIndex[e.String()] = append(pg.parentIndex[e.String()], n)
+		}
+	}
+	pg.depth++
+	pg.strata = append(pg.strata, make(map[string]*policyGraphNode))
+}
+
+// policiesValid reports whether chain, from the leaf to the trust anchor,
+// is valid for the policies of opts.
+func policiesValid(chain []*Certificate, opts *VerifyOptions) bool {
+	if len(chain) == 1 {
+		return true
+	}
+
+	// n is the length of the chain without the trust anchor.
+	n := len(chain) - 1
+
+	pg := newPolicyGraph()
+	var inhibitAnyPolicy, explicitPolicy, policyMapping int
+	if !opts.InhibitAnyPolicy {
+		inhibitAnyPolicy = n + 1
+	}
+	if !opts.RequireExplicitPolicy {
+		explicitPolicy = n + 1
+	}
+	if !opts.InhibitPolicyMapping {
+		policyMapping = n + 1
+	}
+
+	// No policies is the same as anyPolicy.
+	initialUserPolicySet := make(map[string]bool)
+	for _, p := range opts.CertificatePolicies {
+		initialUserPolicySet[p.String()] = true
+	}
+	if len(initialUserPolicySet) == 0 {
+		initialUserPolicySet[OIDAnyPolicy.String()] = true
+	}
+
+	// Certificates are processed from the one issued by the trust anchor
+	// down to the leaf, so i counts down where RFC 5280 counts up.
+	for i := n - 1; i >= 0; i-- {
+		cert := chain[i]
+		isSelfIssued := bytes.Equal(cert.RawIssuer, cert.RawSubject)
+
+		// 6.1.3 (e)
+		if len(cert.PolicyIdentifiers) == 0 {
+			pg = nil
+		}
+
+		// 6.1.3 (f)
+		if explicitPolicy == 0 && pg == nil {
+			return false
+		}
+
+		if pg != nil {
+			pg.incrDepth()
+
+			// 6.1.3 (d) (1)
+			policies := make(map[string]bool)
+			for _, policy := range cert.PolicyIdentifiers {
+				policies[policy.String()] = true
+				if policy.Equal(OIDAnyPolicy) {
+					continue
+				}
+				parents := pg.parentsWithExpected(policy)
+				if len(parents) == 0 {
+					if anyParent := pg.parentWithAnyPolicy(); anyParent != nil {
+						parents = []*policyGraphNode{anyParent}
+					}
+				}
+				if len(parents) > 0 {
+					pg.insert(newPolicyGraphNode(policy, parents))
+				}
+			}
+
+			// 6.1.3 (d) (2): n-i < n is the "i < n" of RFC 5280.
+			if policies[OIDAnyPolicy.String()] && (inhibitAnyPolicy > 0 || (n-i < n && isSelfIssued)) {
+				type missingPolicy struct {
+					policy  asn1.ObjectIdentifier
+					parents []*policyGraphNode
+				}
+				missing := make(map[string]*missingPolicy)
+				leaves := pg.leaves()
+				for _, p := range pg.parents() {
+					for _, expected := range p.expectedPolicySet {
+						key := expected.String()
+						if leaves[key] != nil {
+							continue
+						}
+						if missing[key] == nil {
+							missing[key] = &missingPolicy{policy: expected}
+						}
+						missing[key].parents = append(missing[key].parents, p)
+					}
+				}
+				for _, m := range missing {
+					pg.insert(newPolicyGraphNode(m.policy, m.parents))
+				}
+			}
+
+			// 6.1.3 (d) (3)
+			pg.prune()
+
+			// 6.1.4 (b)
+			if i != 0 && len(cert.PolicyMappings) > 0 {
+				var issuerPolicies []asn1.ObjectIdentifier
+				mappings := make(map[string][]asn1.ObjectIdentifier)
+				for _, mapping := range cert.PolicyMappings {
+					if policyMapping > 0 {
+						if mapping.IssuerDomainPolicy.Equal(OIDAnyPolicy) || mapping.SubjectDomainPolicy.Equal(OIDAnyPolicy) {
+							return false
+						}
+						key := mapping.IssuerDomainPolicy.String()
+						if mappings[key] == nil {
+							issuerPolicies = append(issuerPolicies, mapping.IssuerDomainPolicy)
+						}
+						mappings[key] = append(mappings[key], mapping.SubjectDomainPolicy)
+					} else {
+						pg.deleteLeaf(mapping.IssuerDomainPolicy)
+						pg.prune()
+					}
+				}
+				for _, issuerPolicy := range issuerPolicies {
+					subjectPolicies := mappings[issuerPolicy.String()]
+					if matching := pg.leafWithPolicy(issuerPolicy); matching != nil {
+						matching.expectedPolicySet = subjectPolicies
+					} else if matching := pg.leafWithPolicy(OIDAnyPolicy); matching != nil {
+						node := newPolicyGraphNode(issuerPolicy, []*policyGraphNode{matching})
+						node.expectedPolicySet = subjectPolicies
+						pg.insert(node)
+					}
+				}
+			}
+		}
+
+		if i != 0 {
+			// 6.1.4 (h)
+			if !isSelfIssued {
+				if explicitPolicy > 0 {
+					explicitPolicy--
+				}
+				if policyMapping > 0 {
+					policyMapping--
+				}
+				if inhibitAnyPolicy > 0 {
+					inhibitAnyPolicy--
+				}
+			}
+
+			// 6.1.4 (i)
+			if (cert.RequireExplicitPolicy > 0 || cert.RequireExplicitPolicyZero) && cert.RequireExplicitPolicy < explicitPolicy {
+				explicitPolicy = cert.RequireExplicitPolicy
+			}
+			if (cert.InhibitPolicyMapping > 0 || cert.InhibitPolicyMappingZero) && cert.InhibitPolicyMapping < policyMapping {
+				policyMapping = cert.InhibitPolicyMapping
+			}
+			// 6.1.4 (j)
+			if (cert.InhibitAnyPolicy > 0 || cert.InhibitAnyPolicyZero) && cert.InhibitAnyPolicy < inhibitAnyPolicy {
+				inhibitAnyPolicy = cert.InhibitAnyPolicy
+			}
+		}
+	}
+
+	// 6.1.5 (a)
+	if explicitPolicy > 0 {
+		explicitPolicy--
+	}
+	// 6.1.5 (b)
+	if chain[0].RequireExplicitPolicyZero {
+		explicitPolicy = 0
+	}
+
+	// 6.1.5 (g)
+	var validPolicyNodeSet []*policyGraphNode
+	if pg != nil {
+		validPolicyNodeSet = pg.validPolicyNodes()
+		if currentAny := pg.leafWithPolicy(OIDAnyPolicy); currentAny != nil {
+			validPolicyNodeSet = append(validPolicyNodeSet, currentAny)
+		}
+	}
+	authorityConstrainedPolicySet := make(map[string]bool)
+	for _, n := range validPolicyNodeSet {
+		authorityConstrainedPolicySet[n.validPolicy.String()] = true
+	}
+	userConstrainedPolicySet := make(map[string]bool)
+	for p := range authorityConstrainedPolicySet {
+		userConstrainedPolicySet[p] = true
+	}
+	if len(initialUserPolicySet) != 1 || !initialUserPolicySet[OIDAnyPolicy.String()] {
+		for p := range userConstrainedPolicySet {
+			if !initialUserPolicySet[p] {
+				delete(userConstrainedPolicySet, p)
+			}
+		}
+		if authorityConstrainedPolicySet[OIDAnyPolicy.String()] {
+			for p := range initialUserPolicySet {
+				userConstrainedPolicySet[p] = true
+			}
+		}
+	}
+
+	return explicitPolicy > 0 || len(userConstrainedPolicySet) > 0
+}
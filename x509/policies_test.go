@@ -0,0 +1,274 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+)
+
+var (
+	testPolicy1 = asn1.ObjectIdentifier{1, 2, 3, 1}
+	testPolicy2 = asn1.ObjectIdentifier{1, 2, 3, 2}
+)
+
+// policyCert describes the policy extensions of a certificate of a test
+// chain.
+type policyCert struct {
+	policies []asn1.ObjectIdentifier
+	mappings []PolicyMapping
+	// requireExplicit, inhibitMapping and inhibitAny are the skipCerts of
+	// the policy constraints, or -1 if absent.
+	requireExplicit, inhibitMapping, inhibitAny int
+}
+
+func noConstraints(policies ...asn1.ObjectIdentifier) policyCert {
+	return policyCert{policies: policies, requireExplicit: -1, inhibitMapping: -1, inhibitAny: -1}
+}
+
+// policyChain issues a chain of certificates with the given policy
+// extensions, from the leaf to the root, and returns the leaf, a pool of the
+// intermediates and a pool holding the root.
+func policyChain(t *testing.T, certs []policyCert) (*Certificate, *CertPool, *CertPool) {
+	t.Helper()
+	intermediates, roots := NewCertPool(), NewCertPool()
+	var parent *Certificate
+	var parentKey *ecdsa.PrivateKey
+	var cert *Certificate
+	for i := len(certs) - 1; i >= 0; i-- {
+		pc := certs[i]
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &Certificate{
+			SerialNumber:          big.NewInt(int64(i + 1)),
+			Subject:               pkix.Name{CommonName: "cert" + string(rune('0'+i))},
+			NotBefore:             time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			NotAfter:              time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+			BasicConstraintsValid: true,
+			IsCA:                  i > 0,
+			PolicyIdentifiers:     pc.policies,
+		}
+		if len(pc.mappings) > 0 {
+			value, err := asn1.Marshal(pc.mappings)
+			if err != nil {
+				t.Fatal(err)
+			}
+			template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{Id: OIDExtensionPolicyMappings, Critical: true, Value: value})
+		}
+		if pc.requireExplicit >= 0 || pc.inhibitMapping >= 0 {
+			var constraints struct {
+				RequireExplicitPolicy int `asn1:"optional,tag:0,default:-1"`
+				InhibitPolicyMapping  int `asn1:"optional,tag:1,default:-1"`
+			}
+			constraints.RequireExplicitPolicy, constraints.InhibitPolicyMapping = pc.requireExplicit, pc.inhibitMapping
+			value, err := asn1.Marshal(constraints)
+			if err != nil {
+				t.Fatal(err)
+			}
+			template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{Id: OIDExtensionPolicyConstraints, Critical: true, Value: value})
+		}
+		if pc.inhibitAny >= 0 {
+			value, err := asn1.Marshal(pc.inhibitAny)
+			if err != nil {
+				t.Fatal(err)
+			}
+			template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{Id: OIDExtensionInhibitAnyPolicy, Critical: true, Value: value})
+		}
+		if parent == nil {
+			parent, parentKey = template, key
+		}
+		der, err := CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cert, err = ParseCertificate(der); err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case i == len(certs)-1:
+			roots.AddCert(cert)
+		case i > 0:
+			intermediates.AddCert(cert)
+		}
+		parent, parentKey = cert, key
+	}
+	return cert, intermediates, roots
+}
+
+func TestParsePolicyExtensions(t *testing.T) {
+	leaf, _, _ := policyChain(t, []policyCert{
+		{
+			policies:        []asn1.ObjectIdentifier{testPolicy1},
+			mappings:        []PolicyMapping{{IssuerDomainPolicy: testPolicy1, SubjectDomainPolicy: testPolicy2}},
+			requireExplicit: 0,
+			inhibitMapping:  3,
+			inhibitAny:      2,
+		},
+	})
+	if len(leaf.UnhandledCriticalExtensions) != 0 {
+		t.Errorf("UnhandledCriticalExtensions=%v, want none", leaf.UnhandledCriticalExtensions)
+	}
+	if len(leaf.PolicyMappings) != 1 || !leaf.PolicyMappings[0].IssuerDomainPolicy.Equal(testPolicy1) || !leaf.PolicyMappings[0].SubjectDomainPolicy.Equal(testPolicy2) {
+		t.Errorf("PolicyMappings=%v, want %v->%v", leaf.PolicyMappings, testPolicy1, testPolicy2)
+	}
+	if leaf.RequireExplicitPolicy != 0 || !leaf.RequireExplicitPolicyZero {
+		t.Errorf("RequireExplicitPolicy=%d,%v, want 0,true", leaf.RequireExplicitPolicy, leaf.RequireExplicitPolicyZero)
+	}
+	if leaf.InhibitPolicyMapping != 3 || leaf.InhibitPolicyMappingZero {
+		t.Errorf("InhibitPolicyMapping=%d,%v, want 3,false", leaf.InhibitPolicyMapping, leaf.InhibitPolicyMappingZero)
+	}
+	if leaf.InhibitAnyPolicy != 2 || leaf.InhibitAnyPolicyZero {
+		t.Errorf("InhibitAnyPolicy=%d,%v, want 2,false", leaf.InhibitAnyPolicy, leaf.InhibitAnyPolicyZero)
+	}
+}
+
+func TestVerifyPolicies(t *testing.T) {
+	anyPolicy := OIDAnyPolicy
+	for _, test := range []struct {
+		desc string
+		// certs are from the leaf to the root.
+		certs   []policyCert
+		opts    VerifyOptions
+		wantErr bool
+	}{
+		{
+			desc:  "no-policies",
+			certs: []policyCert{noConstraints(), noConstraints(), noConstraints()},
+		},
+		{
+			desc:    "no-policies-explicit",
+			certs:   []policyCert{noConstraints(), noConstraints(), noConstraints()},
+			opts:    VerifyOptions{RequireExplicitPolicy: true},
+			wantErr: true,
+		},
+		{
+			desc:  "any-policy-explicit",
+			certs: []policyCert{noConstraints(testPolicy1), noConstraints(anyPolicy), noConstraints()},
+			opts:  VerifyOptions{RequireExplicitPolicy: true, CertificatePolicies: []asn1.ObjectIdentifier{testPolicy1}},
+		},
+		{
+			desc:    "other-policy-explicit",
+			certs:   []policyCert{noConstraints(testPolicy2), noConstraints(anyPolicy), noConstraints()},
+			opts:    VerifyOptions{RequireExplicitPolicy: true, CertificatePolicies: []asn1.ObjectIdentifier{testPolicy1}},
+			wantErr: true,
+		},
+		{
+			desc:  "other-policy-not-explicit",
+			certs: []policyCert{noConstraints(testPolicy2), noConstraints(anyPolicy), noConstraints()},
+			opts:  VerifyOptions{CertificatePolicies: []asn1.ObjectIdentifier{testPolicy1}},
+		},
+		{
+			desc:    "intermediate-requires-explicit",
+			certs:   []policyCert{noConstraints(), {policies: []asn1.ObjectIdentifier{anyPolicy}, requireExplicit: 0, inhibitMapping: -1, inhibitAny: -1}, noConstraints()},
+			wantErr: true,
+		},
+		{
+			desc:  "intermediate-requires-explicit-satisfied",
+			certs: []policyCert{noConstraints(testPolicy1), {policies: []asn1.ObjectIdentifier{anyPolicy}, requireExplicit: 0, inhibitMapping: -1, inhibitAny: -1}, noConstraints()},
+		},
+		{
+			desc: "mapping",
+			certs: []policyCert{
+				noConstraints(testPolicy2),
+				{policies: []asn1.ObjectIdentifier{testPolicy1}, mappings: []PolicyMapping{{IssuerDomainPolicy: testPolicy1, SubjectDomainPolicy: testPolicy2}}, requireExplicit: -1, inhibitMapping: -1, inhibitAny: -1},
+				noConstraints(),
+			},
+			opts: VerifyOptions{RequireExplicitPolicy: true, CertificatePolicies: []asn1.ObjectIdentifier{testPolicy1}},
+		},
+		{
+			desc: "mapping-subject-policy-not-acceptable",
+			certs: []policyCert{
+				noConstraints(testPolicy2),
+				{policies: []asn1.ObjectIdentifier{testPolicy1}, mappings: []PolicyMapping{{IssuerDomainPolicy: testPolicy1, SubjectDomainPolicy: testPolicy2}}, requireExplicit: -1, inhibitMapping: -1, inhibitAny: -1},
+				noConstraints(),
+			},
+			opts:    VerifyOptions{RequireExplicitPolicy: true, CertificatePolicies: []asn1.ObjectIdentifier{testPolicy2}},
+			wantErr: true,
+		},
+		{
+			desc: "mapping-inhibited",
+			certs: []policyCert{
+				noConstraints(testPolicy2),
+				{policies: []asn1.ObjectIdentifier{testPolicy1}, mappings: []PolicyMapping{{IssuerDomainPolicy: testPolicy1, SubjectDomainPolicy: testPolicy2}}, requireExplicit: -1, inhibitMapping: -1, inhibitAny: -1},
+				{policies: []asn1.ObjectIdentifier{anyPolicy}, requireExplicit: -1, inhibitMapping: 0, inhibitAny: -1},
+				noConstraints(),
+			},
+			opts:    VerifyOptions{RequireExplicitPolicy: true},
+			wantErr: true,
+		},
+		{
+			desc: "mapping-to-any-policy",
+			certs: []policyCert{
+				noConstraints(testPolicy1),
+				{policies: []asn1.ObjectIdentifier{anyPolicy}, mappings: []PolicyMapping{{IssuerDomainPolicy: testPolicy1, SubjectDomainPolicy: anyPolicy}}, requireExplicit: -1, inhibitMapping: -1, inhibitAny: -1},
+				noConstraints(),
+			},
+			wantErr: true,
+		},
+		{
+			desc: "any-policy-not-inhibited",
+			certs: []policyCert{
+				noConstraints(testPolicy1),
+				noConstraints(anyPolicy),
+				noConstraints(anyPolicy),
+				noConstraints(),
+			},
+			opts: VerifyOptions{RequireExplicitPolicy: true},
+		},
+		{
+			desc: "any-policy-inhibited",
+			certs: []policyCert{
+				noConstraints(testPolicy1),
+				noConstraints(anyPolicy),
+				{policies: []asn1.ObjectIdentifier{anyPolicy}, requireExplicit: -1, inhibitMapping: -1, inhibitAny: 0},
+				noConstraints(),
+			},
+			opts:    VerifyOptions{RequireExplicitPolicy: true},
+			wantErr: true,
+		},
+		{
+			desc: "any-policy-inhibited-by-option",
+			certs: []policyCert{
+				noConstraints(testPolicy1),
+				noConstraints(anyPolicy),
+				noConstraints(),
+			},
+			opts:    VerifyOptions{RequireExplicitPolicy: true, InhibitAnyPolicy: true},
+			wantErr: true,
+		},
+		{
+			desc:  "checks-disabled",
+			certs: []policyCert{noConstraints(), noConstraints(), noConstraints()},
+			opts:  VerifyOptions{RequireExplicitPolicy: true, DisablePolicyChecks: true},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			leaf, intermediates, roots := policyChain(t, test.certs)
+			opts := test.opts
+			opts.Intermediates, opts.Roots = intermediates, roots
+			opts.CurrentTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			opts.KeyUsages = []ExtKeyUsage{ExtKeyUsageAny}
+			chains, err := leaf.Verify(opts)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("Verify()=%v, %v; want err: %v", len(chains), err, test.wantErr)
+			}
+			if err != nil {
+				if cie, ok := err.(CertificateInvalidError); !ok || cie.Reason != NoValidChains {
+					t.Errorf("Verify()=%v, want NoValidChains error", err)
+				}
+			}
+		})
+	}
+}
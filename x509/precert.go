@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+)
+
+// Precertificate is a Certificate Transparency pre-certificate (RFC 6962
+// s3.1), with the data needed to check the SCTs issued for it.
+type Precertificate struct {
+	// Raw is the DER encoding of the pre-certificate, holding the CT poison
+	// extension, as submitted to logs.
+	Raw []byte
+	// TBSCertificate is the DER encoding of the TBSCertificate signed by
+	// SCTs for the pre-certificate: without the poison extension, and with
+	// the issuance information of the final certificate if a pre-certificate
+	// signing certificate was used. It is the TBSCertificate of the final
+	// certificate before the SCT list is added.
+	TBSCertificate []byte
+	// IssuerKeyHash is the SHA-256 hash of the SubjectPublicKeyInfo of the
+	// issuer of the final certificate, which SCTs also sign.
+	IssuerKeyHash [sha256.Size]byte
+}
+
+// CreatePrecertificate creates a pre-certificate for the final certificate
+// described by template, to be issued by issuer. The pre-certificate is
+// signed by priv, and has the public key pub, as for CreateCertificate.
+//
+// If preIssuer is nil, the pre-certificate is signed by issuer, and priv is
+// the private key of issuer. Otherwise preIssuer is a pre-certificate signing
+// certificate issued by issuer, with the CertificateTransparency extended key
+// usage, and priv is its private key.
+//
+// template must not hold the CT poison or SCT list extensions. The final
+// certificate must later be created from the same template, with the SCTs
+// in its SCTList, and signed by issuer.
+func CreatePrecertificate(rand io.Reader, template, issuer *Certificate, pub, priv interface{}, preIssuer *Certificate) (*Precertificate, error) {
+	if template == nil || issuer == nil {
+		return nil, errors.New("x509: template and issuer are required")
+	}
+	for _, ext := range template.ExtraExtensions {
+		if ext.Id.Equal(OIDExtensionCTPoison) || ext.Id.Equal(OIDExtensionCTSCT) {
+			return nil, fmt.Errorf("x509: template has extension %v, which is added to certificates as needed", ext.Id)
+		}
+	}
+	if len(template.SCTList.SCTList) > 0 {
+		return nil, errors.New("x509: template has an SCT list, which only goes in the final certificate")
+	}
+
+	parent := issuer
+	if preIssuer != nil {
+		seenCTEKU := false
+		for _, eku := range preIssuer.ExtKeyUsage {
+			if eku == ExtKeyUsageCertificateTransparency {
+				seenCTEKU = true
+				break
+			}
+		}
+		if !seenCTEKU {
+			return nil, errors.New("x509: pre-issuer does not have CertificateTransparency extended key usage")
+		}
+		if !bytes.Equal(preIssuer.RawIssuer, issuer.RawSubject) {
+			return nil, errors.New("x509: pre-issuer is not issued by issuer")
+		}
+		if err := preIssuer.CheckSignatureFrom(issuer); err != nil {
+			return nil, fmt.Errorf("x509: pre-issuer is not signed by issuer: %v", err)
+		}
+		parent = preIssuer
+	}
+
+	precertTemplate := *template
+	precertTemplate.ExtraExtensions = append(append([]pkix.Extension(nil), template.ExtraExtensions...),
+		pkix.Extension{Id: OIDExtensionCTPoison, Critical: true, Value: asn1.NullBytes})
+	der, err := CreateCertificate(rand, &precertTemplate, parent, pub, priv)
+	if err != nil {
+		return nil, err
+	}
+	precert, err := ParseCertificate(der)
+	if IsFatal(err) {
+		return nil, fmt.Errorf("x509: failed to parse pre-certificate: %v", err)
+	}
+	tbs, err := BuildPrecertTBS(precert.RawTBSCertificate, preIssuer)
+	if err != nil {
+		return nil, fmt.Errorf("x509: failed to build pre-certificate TBSCertificate: %v", err)
+	}
+	return &Precertificate{
+		Raw:            der,
+		TBSCertificate: tbs,
+		IssuerKeyHash:  sha256.Sum256(issuer.RawSubjectPublicKeyInfo),
+	}, nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+)
+
+// makePrecertIssuer creates a certificate for template, signed by parent
+// and parentKey, or self-signed if parent is nil.
+func makePrecertIssuer(t *testing.T, template, parent *Certificate, parentKey *ecdsa.PrivateKey) (*Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestCreatePrecertificate(t *testing.T) {
+	notBefore := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	ca, caKey := makePrecertIssuer(t, &Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		SubjectKeyId:          []byte{1, 2, 3, 4},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, nil)
+	preIssuer, preIssuerKey := makePrecertIssuer(t, &Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "Test Precert Signer"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		SubjectKeyId:          []byte{5, 6, 7, 8},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		ExtKeyUsage:           []ExtKeyUsage{ExtKeyUsageCertificateTransparency},
+	}, ca, caKey)
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "leaf.example.com"},
+		DNSNames:     []string{"leaf.example.com"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []ExtKeyUsage{ExtKeyUsageServerAuth},
+	}
+
+	finalDER, err := CreateCertificate(rand.Reader, template, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	final, err := ParseCertificate(finalDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantKeyHash := sha256.Sum256(ca.RawSubjectPublicKeyInfo)
+
+	for _, test := range []struct {
+		desc       string
+		preIssuer  *Certificate
+		signer     *ecdsa.PrivateKey
+		wantIssuer string
+	}{
+		{desc: "ca", signer: caKey, wantIssuer: "Test CA"},
+		{desc: "precert-signer", preIssuer: preIssuer, signer: preIssuerKey, wantIssuer: "Test Precert Signer"},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			precert, err := CreatePrecertificate(rand.Reader, template, ca, &leafKey.PublicKey, test.signer, test.preIssuer)
+			if err != nil {
+				t.Fatalf("CreatePrecertificate()=nil,%v; want _,nil", err)
+			}
+			cert, err := ParseCertificate(precert.Raw)
+			if err != nil {
+				t.Fatalf("ParseCertificate(precert)=nil,%v; want _,nil", err)
+			}
+			if !cert.IsPrecertificate() {
+				t.Error("IsPrecertificate()=false; want true")
+			}
+			if got := cert.Issuer.CommonName; got != test.wantIssuer {
+				t.Errorf("precert issuer=%q; want %q", got, test.wantIssuer)
+			}
+			if !bytes.Equal(precert.TBSCertificate, final.RawTBSCertificate) {
+				t.Error("TBSCertificate differs from the TBSCertificate of the final certificate")
+			}
+			if precert.IssuerKeyHash != wantKeyHash {
+				t.Errorf("IssuerKeyHash=%x; want %x", precert.IssuerKeyHash, wantKeyHash)
+			}
+		})
+	}
+}
+
+func TestCreatePrecertificateErrors(t *testing.T) {
+	notBefore := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	caTemplate := func(cn string) *Certificate {
+		return &Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: cn},
+			NotBefore:             notBefore,
+			NotAfter:              notAfter,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+	}
+	ca, caKey := makePrecertIssuer(t, caTemplate("Test CA"), nil, nil)
+	// otherCA has the same name as ca, but another key.
+	otherCA, otherCAKey := makePrecertIssuer(t, caTemplate("Test CA"), nil, nil)
+	noEKU, noEKUKey := makePrecertIssuer(t, caTemplate("No EKU"), ca, caKey)
+	signerTemplate := caTemplate("Test Precert Signer")
+	signerTemplate.ExtKeyUsage = []ExtKeyUsage{ExtKeyUsageCertificateTransparency}
+	impostor, impostorKey := makePrecertIssuer(t, signerTemplate, otherCA, otherCAKey)
+
+	template := &Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "leaf.example.com"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	poisoned := *template
+	poisoned.ExtraExtensions = []pkix.Extension{{Id: OIDExtensionCTPoison, Critical: true, Value: asn1.NullBytes}}
+
+	for _, test := range []struct {
+		desc      string
+		template  *Certificate
+		preIssuer *Certificate
+		signer    *ecdsa.PrivateKey
+	}{
+		{desc: "poisoned-template", template: &poisoned, signer: caKey},
+		{desc: "pre-issuer-without-eku", template: template, preIssuer: noEKU, signer: noEKUKey},
+		{desc: "pre-issuer-of-other-ca", template: template, preIssuer: impostor, signer: impostorKey},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if _, err := CreatePrecertificate(rand.Reader, test.template, ca, &caKey.PublicKey, test.signer, test.preIssuer); err == nil {
+				t.Error("CreatePrecertificate()=_,nil; want _,non-nil")
+			}
+		})
+	}
+}
@@ -7,7 +7,13 @@ package x509
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/rsa"
 	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
 	"time"
 
 	"github.com/RarimoVoting/certificate-transparency-go/asn1"
@@ -107,6 +113,18 @@ type IssuingDistributionPoint struct {
 	OnlyContainsAttributeCerts bool                  `asn1:"optional,tag:5"`
 }
 
+// Reasons returns the reasons covered by the CRL, or zero if the CRL covers
+// all reasons.
+func (idp IssuingDistributionPoint) Reasons() ReasonFlag {
+	var reasons ReasonFlag
+	for i := 0; i < idp.OnlySomeReasons.BitLength; i++ {
+		if idp.OnlySomeReasons.At(i) != 0 {
+			reasons |= 1 << uint(i)
+		}
+	}
+	return reasons
+}
+
 // TBSCertList represents the ASN.1 structure of the same name from RFC
 // 5280, section 5.1.  It has the same content as pkix.TBSCertificateList
 // but the extensions are included in a parsed format.
@@ -131,6 +149,12 @@ type TBSCertList struct {
 	IssuingCertificateURL        []string
 }
 
+// IsDeltaCRL reports whether the list is a delta CRL, holding the changes
+// since the complete CRL numbered BaseCRLNumber.
+func (tbs *TBSCertList) IsDeltaCRL() bool {
+	return tbs.BaseCRLNumber >= 0
+}
+
 // ParseCertificateList parses a CertificateList (e.g. a CRL) from the given
 // bytes. It's often the case that PEM encoded CRLs will appear where they
 // should be DER encoded, so this function will transparently handle PEM
@@ -268,6 +292,15 @@ func ParseCertificateListDER(derBytes []byte) (*CertificateList, error) {
 		}
 	}
 
+	if tbs := &certList.TBSCertList; tbs.IsDeltaCRL() {
+		// RFC 5280 s5.2.4
+		if tbs.CRLNumber < 0 {
+			errs.AddID(ErrCertListDeltaCRLWithoutCRLNumber)
+		} else if tbs.CRLNumber <= tbs.BaseCRLNumber {
+			errs.AddID(ErrCertListDeltaCRLNumberNotAfterBase, tbs.CRLNumber, tbs.BaseCRLNumber)
+		}
+	}
+
 	if errs.Fatal() {
 		return nil, &errs
 	}
@@ -277,6 +310,73 @@ func ParseCertificateListDER(derBytes []byte) (*CertificateList, error) {
 	return &certList, &errs
 }
 
+// ApplyDeltaCRL returns the revoked certificates of the complete CRL base
+// updated with the changes in the delta CRL delta, as described in RFC 5280
+// s5.2.4. Entries of delta with reason RemoveFromCRL are dropped. The
+// signatures of the lists are not checked.
+func ApplyDeltaCRL(base, delta *CertificateList) ([]*RevokedCertificate, error) {
+	if base.TBSCertList.IsDeltaCRL() {
+		return nil, errors.New("x509: base CRL is a delta CRL")
+	}
+	if !delta.TBSCertList.IsDeltaCRL() {
+		return nil, errors.New("x509: delta CRL has no delta CRL indicator")
+	}
+	if base.TBSCertList.CRLNumber < delta.TBSCertList.BaseCRLNumber {
+		return nil, fmt.Errorf("x509: base CRL number %d is before the base CRL number %d of the delta CRL", base.TBSCertList.CRLNumber, delta.TBSCertList.BaseCRLNumber)
+	}
+	same, err := asn1Equal(base.TBSCertList.Issuer, delta.TBSCertList.Issuer)
+	if err != nil {
+		return nil, err
+	} else if !same {
+		return nil, errors.New("x509: base and delta CRLs have different issuers")
+	}
+	same, err = asn1Equal(base.TBSCertList.IssuingDistributionPoint, delta.TBSCertList.IssuingDistributionPoint)
+	if err != nil {
+		return nil, err
+	} else if !same {
+		return nil, errors.New("x509: base and delta CRLs have different scopes")
+	}
+
+	changes := make(map[string]*RevokedCertificate)
+	for _, rc := range delta.TBSCertList.RevokedCertificates {
+		changes[rc.SerialNumber.String()] = rc
+	}
+	var revoked []*RevokedCertificate
+	for _, rc := range base.TBSCertList.RevokedCertificates {
+		serial := rc.SerialNumber.String()
+		if change, ok := changes[serial]; ok {
+			delete(changes, serial)
+			rc = change
+		}
+		if rc.RevocationReason != RemoveFromCRL {
+			revoked = append(revoked, rc)
+		}
+	}
+	for _, rc := range delta.TBSCertList.RevokedCertificates {
+		serial := rc.SerialNumber.String()
+		if change, ok := changes[serial]; ok {
+			delete(changes, serial)
+			if change.RevocationReason != RemoveFromCRL {
+				revoked = append(revoked, change)
+			}
+		}
+	}
+	return revoked, nil
+}
+
+// asn1Equal reports whether a and b have the same DER encoding.
+func asn1Equal(a, b interface{}) (bool, error) {
+	aDER, err := asn1.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	bDER, err := asn1.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(aDER, bDER), nil
+}
+
 func parseIssuingDistributionPoint(data []byte, idp *IssuingDistributionPoint, name *GeneralNames, errs *Errors) {
 	// RFC 5280 s5.2.5
 	if rest, err := asn1.Unmarshal(data, idp); err != nil {
@@ -363,3 +463,223 @@ func (c *Certificate) CheckCertificateListSignature(crl *CertificateList) error
 	algo := SignatureAlgorithmFromAI(crl.SignatureAlgorithm)
 	return c.CheckSignature(algo, crl.TBSCertList.Raw, crl.SignatureValue.RightAlign())
 }
+
+// RevocationListEntry describes a revoked certificate to include in a CRL
+// created by CreateRevocationList.
+type RevocationListEntry struct {
+	SerialNumber   *big.Int
+	RevocationTime time.Time
+	// ReasonCode is included as a reason code entry extension, unless it is
+	// Unspecified, in which case RFC 5280 s5.3.1 says it should be absent.
+	ReasonCode RevocationReasonCode
+	// InvalidityDate, if non-zero, is included as an invalidity date entry
+	// extension.
+	InvalidityDate time.Time
+	// ExtraExtensions are added to the entry extensions as they are.
+	ExtraExtensions []pkix.Extension
+}
+
+// RevocationList contains the contents of a CRL to create with
+// CreateRevocationList.
+type RevocationList struct {
+	// SignatureAlgorithm is the algorithm used to sign the CRL. If zero, a
+	// default for the signing key is used.
+	SignatureAlgorithm  SignatureAlgorithm
+	RevokedCertificates []RevocationListEntry
+	// Number is the CRL number, which must be non-negative and at most 20
+	// octets long.
+	Number *big.Int
+	// BaseCRLNumber, if non-nil, makes the CRL a delta CRL holding the
+	// changes since the complete CRL of that number.
+	BaseCRLNumber *big.Int
+	ThisUpdate    time.Time
+	NextUpdate    time.Time
+	// IssuingDistributionPoint, if non-nil, is included as a critical
+	// issuing distribution point extension.
+	IssuingDistributionPoint *IssuingDistributionPoint
+	// IssuingDPURIs, if set, replace the full name of the distribution
+	// point of the IssuingDistributionPoint extension, which they imply.
+	IssuingDPURIs []string
+	// ExtraExtensions are added to the CRL extensions as they are.
+	ExtraExtensions []pkix.Extension
+}
+
+// tbsCertList is pkix.TBSCertificateList, keeping the encoding of the issuer
+// name as it is in the issuing certificate.
+type tbsCertList struct {
+	Version             int
+	Signature           pkix.AlgorithmIdentifier
+	Issuer              asn1.RawValue
+	ThisUpdate          time.Time
+	NextUpdate          time.Time                 `asn1:"optional"`
+	RevokedCertificates []pkix.RevokedCertificate `asn1:"optional"`
+	Extensions          []pkix.Extension          `asn1:"tag:0,optional,explicit"`
+}
+
+type certList struct {
+	TBSCertList        asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	SignatureValue     asn1.BitString
+}
+
+// CreateRevocationList returns a DER encoded version 2 CRL with the contents
+// of template, issued and signed by issuer with priv, the private key of
+// issuer. The CRL includes the authority key identifier of issuer, if it has
+// a subject key identifier.
+func CreateRevocationList(rand io.Reader, template *RevocationList, issuer *Certificate, priv crypto.Signer) ([]byte, error) {
+	if template == nil {
+		return nil, errors.New("x509: template can not be nil")
+	}
+	if issuer == nil {
+		return nil, errors.New("x509: issuer can not be nil")
+	}
+	if issuer.KeyUsage != 0 && issuer.KeyUsage&KeyUsageCRLSign == 0 {
+		return nil, errors.New("x509: issuer must have the crlSign key usage bit set")
+	}
+	if template.NextUpdate.Before(template.ThisUpdate) {
+		return nil, errors.New("x509: template.ThisUpdate is after template.NextUpdate")
+	}
+	if err := checkCRLNumber("template.Number", template.Number); err != nil {
+		return nil, err
+	}
+	if template.BaseCRLNumber != nil {
+		if err := checkCRLNumber("template.BaseCRLNumber", template.BaseCRLNumber); err != nil {
+			return nil, err
+		}
+		if template.BaseCRLNumber.Cmp(template.Number) >= 0 {
+			return nil, errors.New("x509: template.BaseCRLNumber must be before template.Number")
+		}
+	}
+
+	hashFunc, signatureAlgorithm, err := signingParamsForPublicKey(priv.Public(), template.SignatureAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked := make([]pkix.RevokedCertificate, len(template.RevokedCertificates))
+	for i, rc := range template.RevokedCertificates {
+		if rc.SerialNumber == nil {
+			return nil, fmt.Errorf("x509: revoked certificate %d has no serial number", i)
+		}
+		// Force revocation times to UTC per RFC 5280.
+		revoked[i] = pkix.RevokedCertificate{SerialNumber: rc.SerialNumber, RevocationTime: rc.RevocationTime.UTC()}
+		if rc.ReasonCode != Unspecified {
+			value, err := asn1.Marshal(asn1.Enumerated(rc.ReasonCode))
+			if err != nil {
+				return nil, err
+			}
+			revoked[i].Extensions = append(revoked[i].Extensions, pkix.Extension{Id: OIDExtensionCRLReasons, Value: value})
+		}
+		if !rc.InvalidityDate.IsZero() {
+			// RFC 5280 s5.3.2: InvalidityDate ::= GeneralizedTime
+			value, err := asn1.MarshalWithParams(rc.InvalidityDate.UTC(), "generalized")
+			if err != nil {
+				return nil, err
+			}
+			revoked[i].Extensions = append(revoked[i].Extensions, pkix.Extension{Id: OIDExtensionInvalidityDate, Value: value})
+		}
+		revoked[i].Extensions = append(revoked[i].Extensions, rc.ExtraExtensions...)
+	}
+
+	var extensions []pkix.Extension
+	if len(issuer.SubjectKeyId) > 0 {
+		value, err := asn1.Marshal(authKeyId{Id: issuer.SubjectKeyId})
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, pkix.Extension{Id: OIDExtensionAuthorityKeyId, Value: value})
+	}
+	value, err := asn1.Marshal(template.Number)
+	if err != nil {
+		return nil, err
+	}
+	extensions = append(extensions, pkix.Extension{Id: OIDExtensionCRLNumber, Value: value})
+	if template.BaseCRLNumber != nil {
+		value, err := asn1.Marshal(template.BaseCRLNumber)
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, pkix.Extension{Id: OIDExtensionDeltaCRLIndicator, Critical: true, Value: value})
+	}
+	if template.IssuingDistributionPoint != nil || len(template.IssuingDPURIs) > 0 {
+		var idp IssuingDistributionPoint
+		if template.IssuingDistributionPoint != nil {
+			idp = *template.IssuingDistributionPoint
+		}
+		if len(template.IssuingDPURIs) > 0 {
+			idp.DistributionPoint.FullName = nil
+			for _, uri := range template.IssuingDPURIs {
+				if err := isIA5String(uri); err != nil {
+					return nil, err
+				}
+				idp.DistributionPoint.FullName = append(idp.DistributionPoint.FullName, asn1.RawValue{Tag: nameTypeURI, Class: asn1.ClassContextSpecific, Bytes: []byte(uri)})
+			}
+		}
+		value, err := asn1.Marshal(idp)
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, pkix.Extension{Id: OIDExtensionIssuingDistributionPoint, Critical: true, Value: value})
+	}
+	extensions = append(extensions, template.ExtraExtensions...)
+
+	asn1Issuer, err := subjectBytes(issuer)
+	if err != nil {
+		return nil, err
+	}
+	tbsCertListContents, err := asn1.Marshal(tbsCertList{
+		Version:             1,
+		Signature:           signatureAlgorithm,
+		Issuer:              asn1.RawValue{FullBytes: asn1Issuer},
+		ThisUpdate:          template.ThisUpdate.UTC(),
+		NextUpdate:          template.NextUpdate.UTC(),
+		RevokedCertificates: revoked,
+		Extensions:          extensions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signed := tbsCertListContents
+	if hashFunc != 0 {
+		h := hashFunc.New()
+		h.Write(signed)
+		signed = h.Sum(nil)
+	}
+
+	var signerOpts crypto.SignerOpts = hashFunc
+	if template.SignatureAlgorithm != 0 && template.SignatureAlgorithm.isRSAPSS() {
+		signerOpts = &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+			Hash:       hashFunc,
+		}
+	}
+
+	signature, err := priv.Sign(rand, signed, signerOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(certList{
+		TBSCertList:        asn1.RawValue{FullBytes: tbsCertListContents},
+		SignatureAlgorithm: signatureAlgorithm,
+		SignatureValue:     asn1.BitString{Bytes: signature, BitLength: len(signature) * 8},
+	})
+}
+
+// checkCRLNumber checks that n is a valid CRL number, as described in RFC
+// 5280 s5.2.3.
+func checkCRLNumber(field string, n *big.Int) error {
+	if n == nil {
+		return fmt.Errorf("x509: %s is required", field)
+	}
+	if n.Sign() < 0 {
+		return fmt.Errorf("x509: %s must be non-negative", field)
+	}
+	// The DER encoding of a non-negative integer may need an extra leading
+	// zero octet.
+	if n.BitLen() > 20*8-1 {
+		return fmt.Errorf("x509: %s must be at most 20 octets long", field)
+	}
+	return nil
+}
@@ -6,9 +6,13 @@
 package x509
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"encoding/pem"
 	"math/big"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -2123,3 +2127,314 @@ func TestParseGIAG2CertificateList(t *testing.T) {
 		t.Errorf("CheckCertificateListSignature(giag2CRL)=%v; want nil", err)
 	}
 }
+
+func makeCRLIssuer(t *testing.T) (*Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "CRL Issuer"},
+		NotBefore:             time.Unix(1000, 0),
+		NotAfter:              time.Unix(100000, 0),
+		KeyUsage:              KeyUsageCertSign | KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		SubjectKeyId:          []byte{1, 2, 3, 4},
+	}
+	der, err := CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatalf("failed to create issuer: %v", err)
+	}
+	issuer, err := ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse issuer: %v", err)
+	}
+	return issuer, priv
+}
+
+func TestCreateRevocationList(t *testing.T) {
+	issuer, priv := makeCRLIssuer(t)
+	loc := time.FixedZone("Oz/Atlantis", int((2 * time.Hour).Seconds()))
+	thisUpdate := time.Unix(2000, 0).In(loc)
+	invalid := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	extra := pkix.Extension{Id: asn1.ObjectIdentifier{1, 2, 3}, Value: []byte{0x05, 0x00}}
+
+	template := &RevocationList{
+		RevokedCertificates: []RevocationListEntry{
+			{SerialNumber: big.NewInt(1), RevocationTime: thisUpdate},
+			{SerialNumber: big.NewInt(2), RevocationTime: thisUpdate, ReasonCode: KeyCompromise, InvalidityDate: invalid},
+			{SerialNumber: big.NewInt(3), RevocationTime: thisUpdate, ReasonCode: RemoveFromCRL, ExtraExtensions: []pkix.Extension{extra}},
+		},
+		Number:        big.NewInt(12),
+		BaseCRLNumber: big.NewInt(10),
+		ThisUpdate:    thisUpdate,
+		NextUpdate:    thisUpdate.Add(time.Hour),
+		IssuingDistributionPoint: &IssuingDistributionPoint{
+			OnlyContainsUserCerts: true,
+			OnlySomeReasons:       asn1.BitString{Bytes: []byte{0x60}, BitLength: 3},
+		},
+		IssuingDPURIs:   []string{"http://crl.example.com/delta.crl"},
+		ExtraExtensions: []pkix.Extension{extra},
+	}
+	der, err := CreateRevocationList(rand.Reader, template, issuer, priv)
+	if err != nil {
+		t.Fatalf("CreateRevocationList()=nil,%v; want _,nil", err)
+	}
+	crl, err := ParseCertificateListDER(der)
+	if err != nil {
+		t.Fatalf("ParseCertificateListDER()=nil,%v; want _,nil", err)
+	}
+	if err := issuer.CheckCertificateListSignature(crl); err != nil {
+		t.Errorf("CheckCertificateListSignature()=%v; want nil", err)
+	}
+
+	tbs := crl.TBSCertList
+	if got, want := tbs.Version, 1; got != want {
+		t.Errorf("Version=%d; want %d", got, want)
+	}
+	if !tbs.ThisUpdate.Equal(thisUpdate) || tbs.ThisUpdate.Location() != time.UTC {
+		t.Errorf("ThisUpdate=%v; want %v in UTC", tbs.ThisUpdate, thisUpdate)
+	}
+	if got, want := tbs.CRLNumber, 12; got != want {
+		t.Errorf("CRLNumber=%d; want %d", got, want)
+	}
+	if !tbs.IsDeltaCRL() || tbs.BaseCRLNumber != 10 {
+		t.Errorf("IsDeltaCRL()=%v, BaseCRLNumber=%d; want true, 10", tbs.IsDeltaCRL(), tbs.BaseCRLNumber)
+	}
+	if !reflect.DeepEqual(tbs.AuthorityKeyID, issuer.SubjectKeyId) {
+		t.Errorf("AuthorityKeyID=%x; want %x", tbs.AuthorityKeyID, issuer.SubjectKeyId)
+	}
+	if !tbs.IssuingDistributionPoint.OnlyContainsUserCerts {
+		t.Error("IssuingDistributionPoint.OnlyContainsUserCerts=false; want true")
+	}
+	if got, want := tbs.IssuingDistributionPoint.Reasons(), KeyCompromiseFlag|CACompromiseFlag; got != want {
+		t.Errorf("IssuingDistributionPoint.Reasons()=%v; want %v", got, want)
+	}
+	if got, want := tbs.IssuingDPFullNames.URIs, template.IssuingDPURIs; !reflect.DeepEqual(got, want) {
+		t.Errorf("IssuingDPFullNames.URIs=%v; want %v", got, want)
+	}
+	if got, want := tbs.Extensions[len(tbs.Extensions)-1], extra; !reflect.DeepEqual(got, want) {
+		t.Errorf("last extension=%+v; want %+v", got, want)
+	}
+
+	if got, want := len(tbs.RevokedCertificates), 3; got != want {
+		t.Fatalf("len(RevokedCertificates)=%d; want %d", got, want)
+	}
+	for i, rc := range tbs.RevokedCertificates {
+		want := template.RevokedCertificates[i]
+		if rc.SerialNumber.Cmp(want.SerialNumber) != 0 {
+			t.Errorf("RevokedCertificates[%d].SerialNumber=%v; want %v", i, rc.SerialNumber, want.SerialNumber)
+		}
+		if !rc.RevocationTime.Equal(want.RevocationTime) {
+			t.Errorf("RevokedCertificates[%d].RevocationTime=%v; want %v", i, rc.RevocationTime, want.RevocationTime)
+		}
+		if rc.RevocationReason != want.ReasonCode {
+			t.Errorf("RevokedCertificates[%d].RevocationReason=%v; want %v", i, rc.RevocationReason, want.ReasonCode)
+		}
+		if !rc.InvalidityDate.Equal(want.InvalidityDate) {
+			t.Errorf("RevokedCertificates[%d].InvalidityDate=%v; want %v", i, rc.InvalidityDate, want.InvalidityDate)
+		}
+	}
+	if got := len(tbs.RevokedCertificates[0].Extensions); got != 0 {
+		t.Errorf("len(RevokedCertificates[0].Extensions)=%d; want 0 for an unspecified reason", got)
+	}
+	if got, want := tbs.RevokedCertificates[2].Extensions[1], extra; !reflect.DeepEqual(got, want) {
+		t.Errorf("RevokedCertificates[2].Extensions[1]=%+v; want %+v", got, want)
+	}
+}
+
+func TestCreateRevocationListErrors(t *testing.T) {
+	issuer, priv := makeCRLIssuer(t)
+	noCRLSign := *issuer
+	noCRLSign.KeyUsage = KeyUsageCertSign
+	now := time.Unix(2000, 0)
+
+	tests := []struct {
+		desc     string
+		template *RevocationList
+		issuer   *Certificate
+		wantErr  string
+	}{
+		{desc: "nil-template", issuer: issuer, wantErr: "template can not be nil"},
+		{desc: "nil-issuer", template: &RevocationList{Number: big.NewInt(1)}, wantErr: "issuer can not be nil"},
+		{
+			desc:     "no-crl-sign",
+			template: &RevocationList{Number: big.NewInt(1)},
+			issuer:   &noCRLSign,
+			wantErr:  "crlSign key usage",
+		},
+		{
+			desc:     "next-before-this",
+			template: &RevocationList{Number: big.NewInt(1), ThisUpdate: now, NextUpdate: now.Add(-time.Hour)},
+			issuer:   issuer,
+			wantErr:  "ThisUpdate is after",
+		},
+		{desc: "no-number", template: &RevocationList{}, issuer: issuer, wantErr: "Number is required"},
+		{
+			desc:     "negative-number",
+			template: &RevocationList{Number: big.NewInt(-1)},
+			issuer:   issuer,
+			wantErr:  "must be non-negative",
+		},
+		{
+			desc:     "long-number",
+			template: &RevocationList{Number: new(big.Int).Lsh(big.NewInt(1), 20*8)},
+			issuer:   issuer,
+			wantErr:  "at most 20 octets",
+		},
+		{
+			desc:     "base-not-before",
+			template: &RevocationList{Number: big.NewInt(3), BaseCRLNumber: big.NewInt(3)},
+			issuer:   issuer,
+			wantErr:  "BaseCRLNumber must be before",
+		},
+		{
+			desc: "no-serial",
+			template: &RevocationList{
+				Number:              big.NewInt(1),
+				RevokedCertificates: []RevocationListEntry{{RevocationTime: now}},
+			},
+			issuer:  issuer,
+			wantErr: "no serial number",
+		},
+		{
+			desc:     "non-ascii-uri",
+			template: &RevocationList{Number: big.NewInt(1), IssuingDPURIs: []string{"http://\u00e9.example.com"}},
+			issuer:   issuer,
+			wantErr:  "IA5String",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := CreateRevocationList(rand.Reader, test.template, test.issuer, priv)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("CreateRevocationList()=_,%v; want error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseDeltaCRLNumbers(t *testing.T) {
+	for _, test := range []struct {
+		desc      string
+		crlNumber int
+		wantErr   string
+	}{
+		{desc: "delta", crlNumber: 5},
+		{desc: "not-after-base", crlNumber: 3, wantErr: "crl-number 3 is not after its base-crl-number 3"},
+		{desc: "no-crl-number", crlNumber: -1, wantErr: "has no crl-number"},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			exts := []pkix.Extension{{Id: OIDExtensionDeltaCRLIndicator, Critical: true, Value: []byte{0x02, 0x01, 0x03}}}
+			if test.crlNumber >= 0 {
+				exts = append(exts, pkix.Extension{Id: OIDExtensionCRLNumber, Value: []byte{0x02, 0x01, byte(test.crlNumber)}})
+			}
+			der, err := asn1.Marshal(pkix.CertificateList{
+				TBSCertList: pkix.TBSCertificateList{
+					Version:    1,
+					Signature:  pkix.AlgorithmIdentifier{Algorithm: oidSignatureECDSAWithSHA256},
+					ThisUpdate: time.Unix(2000, 0).UTC(),
+					Extensions: exts,
+				},
+				SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSignatureECDSAWithSHA256},
+			})
+			if err != nil {
+				t.Fatalf("failed to marshal CRL: %v", err)
+			}
+
+			crl, err := ParseCertificateListDER(der)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ParseCertificateListDER()=_,%v; want _,nil", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("ParseCertificateListDER()=_,%v; want error containing %q", err, test.wantErr)
+			}
+			if crl == nil {
+				t.Fatal("ParseCertificateListDER()=nil; want non-fatal errors only")
+			}
+			if !crl.TBSCertList.IsDeltaCRL() {
+				t.Error("IsDeltaCRL()=false; want true")
+			}
+			if got := crl.TBSCertList.CRLNumber; got != test.crlNumber {
+				t.Errorf("CRLNumber=%d; want %d", got, test.crlNumber)
+			}
+		})
+	}
+}
+
+func TestApplyDeltaCRL(t *testing.T) {
+	issuer, priv := makeCRLIssuer(t)
+	otherIssuer, otherPriv := makeCRLIssuer(t)
+	otherIssuer.RawSubject = nil
+	otherIssuer.Subject = pkix.Name{CommonName: "Other CRL Issuer"}
+	now := time.Unix(2000, 0)
+
+	create := func(issuer *Certificate, priv *ecdsa.PrivateKey, template *RevocationList) *CertificateList {
+		t.Helper()
+		template.ThisUpdate = now
+		template.NextUpdate = now.Add(time.Hour)
+		der, err := CreateRevocationList(rand.Reader, template, issuer, priv)
+		if err != nil {
+			t.Fatalf("CreateRevocationList()=nil,%v; want _,nil", err)
+		}
+		crl, err := ParseCertificateListDER(der)
+		if err != nil {
+			t.Fatalf("ParseCertificateListDER()=nil,%v; want _,nil", err)
+		}
+		return crl
+	}
+	entry := func(serial int64, reason RevocationReasonCode) RevocationListEntry {
+		return RevocationListEntry{SerialNumber: big.NewInt(serial), RevocationTime: now, ReasonCode: reason}
+	}
+
+	base := create(issuer, priv, &RevocationList{
+		Number:              big.NewInt(10),
+		RevokedCertificates: []RevocationListEntry{entry(1, KeyCompromise), entry(2, CertificateHold), entry(3, Superseded)},
+	})
+	delta := create(issuer, priv, &RevocationList{
+		Number:              big.NewInt(11),
+		BaseCRLNumber:       big.NewInt(10),
+		RevokedCertificates: []RevocationListEntry{entry(2, RemoveFromCRL), entry(3, KeyCompromise), entry(4, Unspecified), entry(5, RemoveFromCRL)},
+	})
+
+	revoked, err := ApplyDeltaCRL(base, delta)
+	if err != nil {
+		t.Fatalf("ApplyDeltaCRL()=nil,%v; want _,nil", err)
+	}
+	var got []string
+	for _, rc := range revoked {
+		got = append(got, rc.SerialNumber.String()+":"+strconv.Itoa(int(rc.RevocationReason)))
+	}
+	if want := []string{"1:1", "3:1", "4:0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ApplyDeltaCRL()=%v; want %v", got, want)
+	}
+
+	newerBase := create(issuer, priv, &RevocationList{Number: big.NewInt(9)})
+	scoped := create(issuer, priv, &RevocationList{
+		Number:                   big.NewInt(11),
+		BaseCRLNumber:            big.NewInt(9),
+		IssuingDistributionPoint: &IssuingDistributionPoint{OnlyContainsCACerts: true},
+	})
+	otherDelta := create(otherIssuer, otherPriv, &RevocationList{Number: big.NewInt(11), BaseCRLNumber: big.NewInt(10)})
+	for _, test := range []struct {
+		desc        string
+		base, delta *CertificateList
+		wantErr     string
+	}{
+		{desc: "base-is-delta", base: delta, delta: delta, wantErr: "base CRL is a delta CRL"},
+		{desc: "not-delta", base: base, delta: base, wantErr: "no delta CRL indicator"},
+		{desc: "old-base", base: newerBase, delta: delta, wantErr: "is before the base CRL number"},
+		{desc: "other-issuer", base: base, delta: otherDelta, wantErr: "different issuers"},
+		{desc: "other-scope", base: base, delta: scoped, wantErr: "different scopes"},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if _, err := ApplyDeltaCRL(test.base, test.delta); err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("ApplyDeltaCRL()=_,%v; want error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/big"
+	"math/bits"
+	"sync"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+)
+
+// This file holds the Chinese national algorithms found in certificates: the
+// SM2 elliptic curve (GB/T 32918), whose signatures use the SM3 hash function
+// (GB/T 32905). Only the verification of signatures is supported.
+
+// GM/T 0006 Cryptographic Application Identifier Criterion Specification
+//
+//	sm2p256v1  OBJECT IDENTIFIER ::= { 1 2 156 10197 1 301 }
+//	sm2-with-sm3  OBJECT IDENTIFIER ::= { 1 2 156 10197 1 501 }
+var (
+	OIDNamedCurveSM2       = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+	oidSignatureSM2WithSM3 = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 501}
+)
+
+// sm2DefaultUserID is the signer identity used for SM2 signatures in
+// certificates (GM/T 0009, section 10).
+var sm2DefaultUserID = []byte("1234567812345678")
+
+var sm2Once sync.Once
+var sm2p256v1 *elliptic.CurveParams
+
+func initSM2P256V1() {
+	// See GB/T 32918.5, section A.2.
+	sm2p256v1 = &elliptic.CurveParams{Name: "SM2-P-256"}
+	sm2p256v1.P, _ = new(big.Int).SetString("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFF", 16)
+	sm2p256v1.N, _ = new(big.Int).SetString("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFF7203DF6B21C6052B53BBF40939D54123", 16)
+	sm2p256v1.B, _ = new(big.Int).SetString("28E9FA9E9D9F5E344D5A9E4BCF6509A7F39789F515AB8F92DDBCBD414D940E93", 16)
+	sm2p256v1.Gx, _ = new(big.Int).SetString("32C4AE2C1F1981195F9904466A39C9948FE30BBFF2660BE1715A4589334C74C7", 16)
+	sm2p256v1.Gy, _ = new(big.Int).SetString("BC3736A2F4F6779C59BDCEE36B692153D0A9877CC62A474002DF32E52139F0A0", 16)
+	sm2p256v1.BitSize = 256
+}
+
+// sm2P256V1 returns the SM2 curve. Its a coefficient is -3, as assumed by
+// elliptic.CurveParams.
+func sm2P256V1() elliptic.Curve {
+	sm2Once.Do(initSM2P256V1)
+	return sm2p256v1
+}
+
+// sm2Digest returns the hash signed by an SM2 signature over msg by the
+// owner of pub with the given identity: SM3(Z || msg), where Z hashes the
+// identity, the curve and the public key.
+func sm2Digest(pub *ecdsa.PublicKey, uid, msg []byte) []byte {
+	params := pub.Curve.Params()
+	size := (params.BitSize + 7) / 8
+	h := newSM3()
+	var entl [2]byte
+	binary.BigEndian.PutUint16(entl[:], uint16(len(uid)*8))
+	h.Write(entl[:])
+	h.Write(uid)
+	a := new(big.Int).Sub(params.P, big.NewInt(3))
+	for _, v := range []*big.Int{a, params.B, params.Gx, params.Gy, pub.X, pub.Y} {
+		h.Write(v.FillBytes(make([]byte, size)))
+	}
+	z := h.Sum(nil)
+
+	h.Reset()
+	h.Write(z)
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+// sm2Verify checks the DER-encoded SM2 signature sig over msg by pub, using
+// the default identity.
+func sm2Verify(pub *ecdsa.PublicKey, msg, sig []byte) error {
+	if pub.Curve != sm2P256V1() {
+		return errors.New("x509: SM2 signature with a key not on the SM2 curve")
+	}
+	ecdsaSig := new(ecdsaSignature)
+	if rest, err := asn1.Unmarshal(sig, ecdsaSig); err != nil {
+		return err
+	} else if len(rest) != 0 {
+		return errors.New("x509: trailing data after SM2 signature")
+	}
+	n := pub.Curve.Params().N
+	r, s := ecdsaSig.R, ecdsaSig.S
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		return errors.New("x509: SM2 signature contained out of range values")
+	}
+	t := new(big.Int).Add(r, s)
+	t.Mod(t, n)
+	if t.Sign() == 0 {
+		return errors.New("x509: SM2 verification failure")
+	}
+
+	x1, y1 := pub.Curve.ScalarBaseMult(s.Bytes())
+	x2, y2 := pub.Curve.ScalarMult(pub.X, pub.Y, t.Bytes())
+	x, _ := pub.Curve.Add(x1, y1, x2, y2)
+
+	e := new(big.Int).SetBytes(sm2Digest(pub, sm2DefaultUserID, msg))
+	e.Add(e, x)
+	e.Mod(e, n)
+	if e.Cmp(r) != 0 {
+		return errors.New("x509: SM2 verification failure")
+	}
+	return nil
+}
+
+// sm3 is an implementation of the SM3 hash function (GB/T 32905).
+type sm3 struct {
+	h   [8]uint32
+	x   [sm3BlockSize]byte
+	nx  int
+	len uint64
+}
+
+const (
+	sm3Size      = 32
+	sm3BlockSize = 64
+)
+
+func newSM3() hash.Hash {
+	d := new(sm3)
+	d.Reset()
+	return d
+}
+
+func (d *sm3) Reset() {
+	d.h = [8]uint32{0x7380166f, 0x4914b2b9, 0x172442d7, 0xda8a0600, 0xa96f30bc, 0x163138aa, 0xe38dee4d, 0xb0fb0e4e}
+	d.nx = 0
+	d.len = 0
+}
+
+func (d *sm3) Size() int      { return sm3Size }
+func (d *sm3) BlockSize() int { return sm3BlockSize }
+
+func (d *sm3) Write(p []byte) (int, error) {
+	n := len(p)
+	d.len += uint64(n)
+	if d.nx > 0 {
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		p = p[c:]
+		if d.nx == sm3BlockSize {
+			d.block(d.x[:])
+			d.nx = 0
+		}
+	}
+	for len(p) >= sm3BlockSize {
+		d.block(p[:sm3BlockSize])
+		p = p[sm3BlockSize:]
+	}
+	if len(p) > 0 {
+		d.nx = copy(d.x[:], p)
+	}
+	return n, nil
+}
+
+func (d *sm3) Sum(in []byte) []byte {
+	// Padding is done on a copy, so that the caller can keep writing.
+	c := *d
+	length := c.len * 8
+	var pad [sm3BlockSize + 8]byte
+	pad[0] = 0x80
+	padLen := sm3BlockSize - int(c.len%sm3BlockSize)
+	if padLen < 9 {
+		padLen += sm3BlockSize
+	}
+	binary.BigEndian.PutUint64(pad[padLen-8:], length)
+	c.Write(pad[:padLen])
+
+	var out [sm3Size]byte
+	for i, v := range c.h {
+		binary.BigEndian.PutUint32(out[4*i:], v)
+	}
+	return append(in, out[:]...)
+}
+
+// block applies the compression function to a 64-byte block.
+func (d *sm3) block(p []byte) {
+	var w [68]uint32
+	for i := 0; i < 16; i++ {
+		w[i] = binary.BigEndian.Uint32(p[4*i:])
+	}
+	for j := 16; j < 68; j++ {
+		x := w[j-16] ^ w[j-9] ^ bits.RotateLeft32(w[j-3], 15)
+		w[j] = x ^ bits.RotateLeft32(x, 15) ^ bits.RotateLeft32(x, 23) ^ bits.RotateLeft32(w[j-13], 7) ^ w[j-6]
+	}
+
+	a, b, c, dd, e, f, g, h := d.h[0], d.h[1], d.h[2], d.h[3], d.h[4], d.h[5], d.h[6], d.h[7]
+	for j := 0; j < 64; j++ {
+		var t, ff, gg uint32
+		if j < 16 {
+			t = 0x79cc4519
+			ff = a ^ b ^ c
+			gg = e ^ f ^ g
+		} else {
+			t = 0x7a879d8a
+			ff = (a & b) | (a & c) | (b & c)
+			gg = (e & f) | (^e & g)
+		}
+		a12 := bits.RotateLeft32(a, 12)
+		ss1 := bits.RotateLeft32(a12+e+bits.RotateLeft32(t, j%32), 7)
+		ss2 := ss1 ^ a12
+		tt1 := ff + dd + ss2 + (w[j] ^ w[j+4])
+		tt2 := gg + h + ss1 + w[j]
+		dd = c
+		c = bits.RotateLeft32(b, 9)
+		b = a
+		a = tt1
+		h = g
+		g = bits.RotateLeft32(f, 19)
+		f = e
+		e = tt2 ^ bits.RotateLeft32(tt2, 9) ^ bits.RotateLeft32(tt2, 17)
+	}
+	d.h[0] ^= a
+	d.h[1] ^= b
+	d.h[2] ^= c
+	d.h[3] ^= dd
+	d.h[4] ^= e
+	d.h[5] ^= f
+	d.h[6] ^= g
+	d.h[7] ^= h
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+)
+
+func TestSM3(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want string
+	}{
+		// GB/T 32905, appendix A.
+		{in: "abc", want: "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"},
+		{in: strings.Repeat("abcd", 16), want: "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732"},
+	} {
+		h := newSM3()
+		// Write in pieces, to exercise buffering.
+		for i := 0; i < len(test.in); i += 5 {
+			end := i + 5
+			if end > len(test.in) {
+				end = len(test.in)
+			}
+			h.Write([]byte(test.in[i:end]))
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != test.want {
+			t.Errorf("SM3(%q)=%s; want %s", test.in, got, test.want)
+		}
+	}
+}
+
+// sm2Sign returns the DER-encoded SM2 signature of msg by priv, using the
+// default identity.
+func sm2Sign(t *testing.T, priv *ecdsa.PrivateKey, msg []byte) []byte {
+	t.Helper()
+	n := priv.Curve.Params().N
+	e := new(big.Int).SetBytes(sm2Digest(&priv.PublicKey, sm2DefaultUserID, msg))
+	for {
+		k, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.Sign() == 0 {
+			continue
+		}
+		x1, _ := priv.Curve.ScalarBaseMult(k.Bytes())
+		r := new(big.Int).Add(e, x1)
+		r.Mod(r, n)
+		if r.Sign() == 0 || new(big.Int).Add(r, k).Cmp(n) == 0 {
+			continue
+		}
+		// s = (1 + d)^-1 * (k - r*d) mod n
+		s := new(big.Int).Mul(r, priv.D)
+		s.Sub(k, s)
+		inv := new(big.Int).Add(priv.D, big.NewInt(1))
+		inv.ModInverse(inv, n)
+		s.Mul(s, inv)
+		s.Mod(s, n)
+		if s.Sign() == 0 {
+			continue
+		}
+		sig, err := asn1.Marshal(ecdsaSignature{R: r, S: s})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+}
+
+func generateSM2Key(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	curve := sm2P256V1()
+	d, err := rand.Int(rand.Reader, new(big.Int).Sub(curve.Params().N, big.NewInt(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Add(d, big.NewInt(1))
+	priv := &ecdsa.PrivateKey{D: d}
+	priv.Curve = curve
+	priv.X, priv.Y = curve.ScalarBaseMult(d.Bytes())
+	return priv
+}
+
+func TestSM2Curve(t *testing.T) {
+	params := sm2P256V1().Params()
+	if !params.IsOnCurve(params.Gx, params.Gy) {
+		t.Error("SM2 base point is not on the curve")
+	}
+	// The base point has order N.
+	nMinus1 := new(big.Int).Sub(params.N, big.NewInt(1))
+	x, y := params.ScalarBaseMult(nMinus1.Bytes())
+	if x.Cmp(params.Gx) != 0 || new(big.Int).Add(y, params.Gy).Cmp(params.P) != 0 {
+		t.Error("(N-1)*G != -G")
+	}
+}
+
+func TestParseSM2Certificate(t *testing.T) {
+	priv := generateSM2Key(t)
+	curveParams, err := asn1.Marshal(OIDNamedCurveSM2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err := asn1.Marshal(pkix.Name{CommonName: "SM2 Test"}.ToRDNSequence())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigAlgo := pkix.AlgorithmIdentifier{Algorithm: oidSignatureSM2WithSM3}
+	pubBytes := elliptic.Marshal(priv.Curve, priv.X, priv.Y)
+	tbs := tbsCertificate{
+		Version:            2,
+		SerialNumber:       big.NewInt(1),
+		SignatureAlgorithm: sigAlgo,
+		Issuer:             asn1.RawValue{FullBytes: name},
+		Validity:           validity{NotBefore: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), NotAfter: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Subject:            asn1.RawValue{FullBytes: name},
+		PublicKey: publicKeyInfo{
+			Algorithm: pkix.AlgorithmIdentifier{Algorithm: OIDPublicKeyECDSA, Parameters: asn1.RawValue{FullBytes: curveParams}},
+			PublicKey: asn1.BitString{Bytes: pubBytes, BitLength: 8 * len(pubBytes)},
+		},
+	}
+	tbsDER, err := asn1.Marshal(tbs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbs.Raw = tbsDER
+	sig := sm2Sign(t, priv, tbsDER)
+	der, err := asn1.Marshal(certificate{
+		TBSCertificate:     tbs,
+		SignatureAlgorithm: sigAlgo,
+		SignatureValue:     asn1.BitString{Bytes: sig, BitLength: 8 * len(sig)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate()=nil,%v; want _,nil", err)
+	}
+	if cert.SignatureAlgorithm != SM2WithSM3 {
+		t.Errorf("SignatureAlgorithm=%v; want %v", cert.SignatureAlgorithm, SM2WithSM3)
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || pub.Curve != sm2P256V1() || pub.X.Cmp(priv.X) != 0 {
+		t.Fatalf("PublicKey=%v; want SM2 key", cert.PublicKey)
+	}
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		t.Errorf("CheckSignature()=%v; want nil", err)
+	}
+	tampered := append([]byte(nil), cert.RawTBSCertificate...)
+	tampered[len(tampered)-1] ^= 1
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, tampered, cert.Signature); err == nil {
+		t.Error("CheckSignature(tampered)=nil; want error")
+	}
+	// An SM2 signature cannot be checked with a key on another curve.
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSignature(SM2WithSM3, cert.RawTBSCertificate, cert.Signature, &p256.PublicKey); err == nil {
+		t.Error("checkSignature(P-256 key)=nil; want error")
+	}
+
+	marshaled, err := MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey()=nil,%v; want _,nil", err)
+	}
+	if _, err := ParsePKIXPublicKey(marshaled); err != nil {
+		t.Errorf("ParsePKIXPublicKey()=nil,%v; want _,nil", err)
+	}
+}
@@ -0,0 +1,326 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxCertificateSize is the default limit on the size of a single
+// certificate, or PEM block, read by a CertificateReader.
+const DefaultMaxCertificateSize = 1 << 20
+
+// CertificateReaderOptions holds the options of a CertificateReader.
+type CertificateReaderOptions struct {
+	// MaxCertificateSize limits the size of a DER certificate or PEM block,
+	// and so the memory used by the reader. Larger items are skipped and
+	// reported as errors. If zero, DefaultMaxCertificateSize is used.
+	MaxCertificateSize int
+	// ParseOptions, if set, selects the deviations tolerated when parsing
+	// certificates. Otherwise certificates are parsed as by ParseCertificate.
+	ParseOptions *ParseOptions
+}
+
+// StreamError describes an item of a certificate stream which could not be
+// read or parsed. A CertificateReader can carry on reading after it.
+type StreamError struct {
+	// Index is the position of the item among the items of the stream,
+	// counting from zero.
+	Index int
+	// Offset is the offset in the stream of the start of the item.
+	Offset int64
+	Err    error
+}
+
+func (e *StreamError) Error() string {
+	return fmt.Sprintf("x509: certificate %d at offset %d: %v", e.Index, e.Offset, e.Err)
+}
+
+func (e *StreamError) Unwrap() error {
+	return e.Err
+}
+
+// CertificateReader reads certificates one at a time from a stream, either of
+// PEM blocks or of concatenated DER certificates, so that very large bundles
+// can be processed in bounded memory. The format is detected from the start
+// of the stream. In a PEM stream, text outside the blocks and blocks other
+// than CERTIFICATE blocks are skipped.
+type CertificateReader struct {
+	r      *bufio.Reader
+	opts   CertificateReaderOptions
+	offset int64
+	index  int
+	der    bool
+	probed bool
+	err    error
+}
+
+// NewCertificateReader returns a CertificateReader reading from r. If opts is
+// nil, the defaults are used.
+func NewCertificateReader(r io.Reader, opts *CertificateReaderOptions) *CertificateReader {
+	cr := &CertificateReader{r: bufio.NewReader(r)}
+	if opts != nil {
+		cr.opts = *opts
+	}
+	if cr.opts.MaxCertificateSize <= 0 {
+		cr.opts.MaxCertificateSize = DefaultMaxCertificateSize
+	}
+	return cr
+}
+
+// Next returns the next certificate of the stream. It returns io.EOF at the
+// end of the stream. If a certificate cannot be read or parsed, it returns a
+// *StreamError, and the following certificates can still be read by calling
+// Next again. Any other error, such as an I/O error, is returned again by
+// all the following calls. As for ParseCertificate, the certificate may be
+// returned along with a non-fatal error.
+func (cr *CertificateReader) Next() (*Certificate, error) {
+	if cr.err != nil {
+		return nil, cr.err
+	}
+	if !cr.probed {
+		cr.probed = true
+		if err := cr.probe(); err != nil {
+			cr.err = err
+			return nil, err
+		}
+	}
+
+	var offset int64
+	var der []byte
+	var err error
+	if cr.der {
+		offset, der, err = cr.nextDER()
+	} else {
+		offset, der, err = cr.nextPEM()
+	}
+	if err != nil {
+		var streamErr *StreamError
+		if !errors.As(err, &streamErr) {
+			cr.err = err
+		}
+		return nil, err
+	}
+
+	index := cr.index
+	cr.index++
+	var cert *Certificate
+	if cr.opts.ParseOptions != nil {
+		cert, _, err = ParseCertificateWithOptions(der, *cr.opts.ParseOptions)
+	} else {
+		cert, err = ParseCertificate(der)
+	}
+	if IsFatal(err) {
+		return nil, &StreamError{Index: index, Offset: offset, Err: err}
+	}
+	return cert, err
+}
+
+// probe detects the format of the stream: DER certificates start with the
+// header of a SEQUENCE with a long-form length, which text does not.
+func (cr *CertificateReader) probe() error {
+	if err := cr.skipSpace(); err != nil {
+		return err
+	}
+	start, err := cr.r.Peek(2)
+	if err == io.EOF {
+		return nil
+	} else if err != nil && len(start) < 2 {
+		return err
+	}
+	cr.der = len(start) == 2 && start[0] == 0x30 && start[1] > 0x80
+	return nil
+}
+
+// skipSpace skips the whitespace at the current position.
+func (cr *CertificateReader) skipSpace() error {
+	for {
+		b, err := cr.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			cr.offset++
+		default:
+			return cr.r.UnreadByte()
+		}
+	}
+}
+
+// nextDER reads the next DER certificate, returning its offset.
+func (cr *CertificateReader) nextDER() (int64, []byte, error) {
+	offset := cr.offset
+	truncated := fmt.Errorf("x509: truncated certificate at offset %d", offset)
+	header, err := cr.r.Peek(2)
+	if len(header) == 0 && err == io.EOF {
+		return 0, nil, io.EOF
+	} else if len(header) < 2 {
+		return 0, nil, orIfEOF(err, truncated)
+	}
+	if header[0] != 0x30 {
+		return 0, nil, fmt.Errorf("x509: unexpected tag %#x at offset %d", header[0], offset)
+	}
+	// Only the long form of lengths, with up to four octets, is expected of
+	// a certificate.
+	n := int(header[1] & 0x7f)
+	if header[1]&0x80 == 0 || n == 0 || n > 4 {
+		return 0, nil, fmt.Errorf("x509: invalid certificate length at offset %d", offset)
+	}
+	header, err = cr.r.Peek(2 + n)
+	if len(header) < 2+n {
+		return 0, nil, orIfEOF(err, truncated)
+	}
+	length := 0
+	for _, b := range header[2:] {
+		length = length<<8 | int(b)
+	}
+	total := int64(2+n) + int64(length)
+	if total > int64(cr.opts.MaxCertificateSize) {
+		// The item can be skipped without holding it in memory.
+		if _, err := io.CopyN(io.Discard, cr.r, total); err != nil {
+			return 0, nil, orIfEOF(err, truncated)
+		}
+		cr.offset += total
+		index := cr.index
+		cr.index++
+		return 0, nil, &StreamError{Index: index, Offset: offset, Err: fmt.Errorf("certificate of %d bytes is larger than %d", total, cr.opts.MaxCertificateSize)}
+	}
+	der := make([]byte, total)
+	if _, err := io.ReadFull(cr.r, der); err != nil {
+		return 0, nil, orIfEOF(err, truncated)
+	}
+	cr.offset += total
+	return offset, der, nil
+}
+
+// orIfEOF returns err, or eofErr if err marks an unexpected end of stream.
+func orIfEOF(err, eofErr error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return eofErr
+	}
+	return err
+}
+
+// nextPEM reads the next CERTIFICATE PEM block, returning its offset and
+// its contents.
+func (cr *CertificateReader) nextPEM() (int64, []byte, error) {
+	for {
+		offset := cr.offset
+		line, err := cr.readLine()
+		if err != nil {
+			return 0, nil, err
+		}
+		typ, ok := pemBegin(line)
+		if !ok {
+			continue
+		}
+
+		// Accumulate the block up to its END line, or until it is too
+		// large, in which case the rest of it is skipped.
+		block := append([]byte(nil), line...)
+		tooLarge := false
+		for {
+			line, err = cr.readLine()
+			if err == io.EOF {
+				index := cr.index
+				cr.index++
+				return 0, nil, &StreamError{Index: index, Offset: offset, Err: errors.New("unterminated PEM block")}
+			} else if err != nil {
+				return 0, nil, err
+			}
+			if !tooLarge {
+				if len(block)+len(line) > cr.opts.MaxCertificateSize {
+					tooLarge, block = true, nil
+				} else {
+					block = append(block, line...)
+				}
+			}
+			if bytes.HasPrefix(line, []byte("-----END ")) {
+				break
+			}
+		}
+		if typ != "CERTIFICATE" {
+			continue
+		}
+
+		index := cr.index
+		if tooLarge {
+			cr.index++
+			return 0, nil, &StreamError{Index: index, Offset: offset, Err: fmt.Errorf("PEM block is larger than %d bytes", cr.opts.MaxCertificateSize)}
+		}
+		p, _ := pem.Decode(block)
+		if p == nil || p.Type != typ {
+			cr.index++
+			return 0, nil, &StreamError{Index: index, Offset: offset, Err: errors.New("invalid PEM block")}
+		}
+		return offset, p.Bytes, nil
+	}
+}
+
+// readLine returns the next line of the stream, including its newline. Only
+// the first MaxCertificateSize+1 bytes of longer lines are returned, so that
+// they are found to be too large without being held in memory.
+func (cr *CertificateReader) readLine() ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := cr.r.ReadSlice('\n')
+		cr.offset += int64(len(chunk))
+		if room := cr.opts.MaxCertificateSize + 1 - len(line); room > 0 {
+			if len(chunk) > room {
+				chunk = chunk[:room]
+			}
+			line = append(line, chunk...)
+		}
+		switch {
+		case err == bufio.ErrBufferFull:
+			continue
+		case err == io.EOF && len(line) > 0:
+			return line, nil
+		case err != nil:
+			return nil, err
+		}
+		return line, nil
+	}
+}
+
+// pemBegin returns the type of the PEM block started by line, if any.
+func pemBegin(line []byte) (string, bool) {
+	line = bytes.TrimRight(line, " \t\r\n")
+	if !bytes.HasPrefix(line, []byte("-----BEGIN ")) || !bytes.HasSuffix(line, []byte("-----")) {
+		return "", false
+	}
+	typ := line[len("-----BEGIN ") : len(line)-len("-----")]
+	return string(typ), len(typ) > 0
+}
+
+// AppendCertsFromReader reads certificates from r, as a CertificateReader
+// with default options does, and appends them to s. Certificates which
+// cannot be parsed are skipped. It returns the number of certificates added,
+// and the error, if any, which stopped the reading.
+func (s *CertPool) AppendCertsFromReader(r io.Reader) (int, error) {
+	cr := NewCertificateReader(r, nil)
+	added := 0
+	for {
+		cert, err := cr.Next()
+		if err == io.EOF {
+			return added, nil
+		}
+		var streamErr *StreamError
+		if errors.As(err, &streamErr) {
+			continue
+		} else if IsFatal(err) {
+			return added, err
+		}
+		s.AddCert(cert)
+		added++
+	}
+}
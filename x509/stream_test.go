@@ -0,0 +1,177 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+)
+
+func makeStreamCerts(t *testing.T, n int) [][]byte {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ders [][]byte
+	for i := 0; i < n; i++ {
+		template := &Certificate{
+			SerialNumber: big.NewInt(int64(i + 1)),
+			Subject:      pkix.Name{CommonName: "stream"},
+			NotBefore:    time.Unix(1000, 0),
+			NotAfter:     time.Unix(100000, 0),
+		}
+		der, err := CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ders = append(ders, der)
+	}
+	return ders
+}
+
+// streamResult summarizes the result of a call to CertificateReader.Next.
+func streamResult(cert *Certificate, err error) string {
+	var streamErr *StreamError
+	switch {
+	case err == io.EOF:
+		return "EOF"
+	case errors.As(err, &streamErr):
+		return "error"
+	case IsFatal(err):
+		return "fatal"
+	}
+	return "serial " + cert.SerialNumber.String()
+}
+
+func readStream(cr *CertificateReader, max int) []string {
+	var got []string
+	for i := 0; i < max; i++ {
+		got = append(got, streamResult(cr.Next()))
+		if last := got[len(got)-1]; last == "EOF" || last == "fatal" {
+			break
+		}
+	}
+	return got
+}
+
+func TestCertificateReaderPEM(t *testing.T) {
+	ders := makeStreamCerts(t, 3)
+	encode := func(typ string, der []byte) string {
+		return string(pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}))
+	}
+	corrupt := append([]byte(nil), ders[1]...)
+	corrupt[len(corrupt)/2] ^= 0xff
+	huge := bytes.Repeat([]byte{0x30}, 2048)
+
+	stream := "Some leading text\n" +
+		encode("CERTIFICATE", ders[0]) +
+		encode("PRIVATE KEY", []byte{1, 2, 3}) +
+		"-----BEGIN CERTIFICATE-----\nnot base64!\n-----END CERTIFICATE-----\n" +
+		encode("CERTIFICATE", corrupt[:len(corrupt)-10]) +
+		encode("CERTIFICATE", huge) +
+		"between blocks\r\n" +
+		strings.Replace(encode("CERTIFICATE", ders[1]), "\n", "\r\n", -1) +
+		encode("CERTIFICATE", ders[2]) +
+		"-----BEGIN CERTIFICATE-----\nMIIB\n"
+
+	cr := NewCertificateReader(strings.NewReader(stream), &CertificateReaderOptions{MaxCertificateSize: 2048})
+	got := readStream(cr, 20)
+	want := []string{"serial 1", "error", "error", "error", "serial 2", "serial 3", "error", "EOF"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Next() results=%v; want %v", got, want)
+	}
+	if _, err := cr.Next(); err != io.EOF {
+		t.Errorf("Next() after EOF=%v; want io.EOF", err)
+	}
+}
+
+func TestCertificateReaderErrorDetails(t *testing.T) {
+	ders := makeStreamCerts(t, 1)
+	good := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ders[0]}))
+	bad := "-----BEGIN CERTIFICATE-----\nMAA=\n-----END CERTIFICATE-----\n"
+
+	cr := NewCertificateReader(strings.NewReader(good+bad), nil)
+	if _, err := cr.Next(); err != nil {
+		t.Fatalf("Next()=%v; want nil", err)
+	}
+	_, err := cr.Next()
+	var streamErr *StreamError
+	if !errors.As(err, &streamErr) {
+		t.Fatalf("Next()=%v; want *StreamError", err)
+	}
+	if streamErr.Index != 1 || streamErr.Offset != int64(len(good)) {
+		t.Errorf("StreamError Index=%d, Offset=%d; want 1, %d", streamErr.Index, streamErr.Offset, len(good))
+	}
+}
+
+func TestCertificateReaderDER(t *testing.T) {
+	ders := makeStreamCerts(t, 3)
+	notCert := []byte{0x30, 0x81, 0x03, 0x02, 0x01, 0x01}
+	large := append([]byte{0x30, 0x82, 0x08, 0x00}, make([]byte, 0x800)...)
+
+	var stream []byte
+	for _, item := range [][]byte{ders[0], notCert, ders[1], large, ders[2]} {
+		stream = append(stream, item...)
+	}
+	cr := NewCertificateReader(bytes.NewReader(stream), &CertificateReaderOptions{MaxCertificateSize: 2048})
+	got := readStream(cr, 20)
+	want := []string{"serial 1", "error", "serial 2", "error", "serial 3", "EOF"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Next() results=%v; want %v", got, want)
+	}
+
+	// A truncated certificate cannot be skipped.
+	cr = NewCertificateReader(bytes.NewReader(stream[:len(ders[0])+len(notCert)+10]), nil)
+	got = readStream(cr, 20)
+	want = []string{"serial 1", "error", "fatal"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Next() results=%v; want %v", got, want)
+	}
+}
+
+func TestCertificateReaderIOError(t *testing.T) {
+	ders := makeStreamCerts(t, 1)
+	ioErr := errors.New("disk on fire")
+	r := io.MultiReader(bytes.NewReader(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ders[0]})), iotest.ErrReader(ioErr))
+	cr := NewCertificateReader(r, nil)
+	if _, err := cr.Next(); err != nil {
+		t.Fatalf("Next()=%v; want nil", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := cr.Next(); err != ioErr {
+			t.Errorf("Next()=%v; want %v", err, ioErr)
+		}
+	}
+}
+
+func TestAppendCertsFromReader(t *testing.T) {
+	ders := makeStreamCerts(t, 2)
+	stream := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ders[0]})) +
+		"-----BEGIN CERTIFICATE-----\nMAA=\n-----END CERTIFICATE-----\n" +
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ders[1]}))
+
+	pool := NewCertPool()
+	added, err := pool.AppendCertsFromReader(strings.NewReader(stream))
+	if err != nil {
+		t.Fatalf("AppendCertsFromReader()=_,%v; want _,nil", err)
+	}
+	if added != 2 || len(pool.Subjects()) != 2 {
+		t.Errorf("AppendCertsFromReader() added %d, pool holds %d; want 2, 2", added, len(pool.Subjects()))
+	}
+}
@@ -16,6 +16,8 @@ import (
 	"strings"
 	"time"
 	"unicode/utf8"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
 )
 
 // ignoreCN disables interpreting Common Name as a hostname. See issue 24151.
@@ -65,6 +67,8 @@ const (
 	// CANotAuthorizedForExtKeyUsage results when an intermediate or root
 	// certificate does not permit a requested extended key usage.
 	CANotAuthorizedForExtKeyUsage
+	// NoValidChains results when there are no valid chains to return.
+	NoValidChains
 )
 
 // CertificateInvalidError results when an odd error occurs. Users of this
@@ -95,6 +99,12 @@ func (e CertificateInvalidError) Error() string {
 		return "x509: issuer has name constraints but leaf doesn't have a SAN extension"
 	case UnconstrainedName:
 		return "x509: issuer has name constraints but leaf contains unknown or unconstrained name: " + e.Detail
+	case NoValidChains:
+		s := "x509: no valid chains built"
+		if e.Detail != "" {
+			s = fmt.Sprintf("%s: %s", s, e.Detail)
+		}
+		return s
 	}
 	return "x509: unknown error"
 }
@@ -199,6 +209,7 @@ type VerifyOptions struct {
 	DisableEKUChecks               bool
 	DisablePathLenChecks           bool
 	DisableNameConstraintChecks    bool
+	DisablePolicyChecks            bool
 	// KeyUsage specifies which Extended Key Usage values are acceptable. A leaf
 	// certificate is accepted if it contains any of the listed values. An empty
 	// list means ExtKeyUsageServerAuth. To accept any key usage, include
@@ -213,6 +224,18 @@ type VerifyOptions struct {
 	// certificates from consuming excessive amounts of CPU time when
 	// validating.
 	MaxConstraintComparisions int
+	// CertificatePolicies is the user-initial-policy-set of RFC 5280 6.1.1:
+	// the policies acceptable for the chain. An empty list means anyPolicy.
+	// Chains are only required to be valid for one of the policies if
+	// RequireExplicitPolicy is set, or if a certificate of the chain requires
+	// explicit policies.
+	CertificatePolicies []asn1.ObjectIdentifier
+	// RequireExplicitPolicy, InhibitPolicyMapping and InhibitAnyPolicy are
+	// the initial-explicit-policy, initial-policy-mapping-inhibit and
+	// initial-any-policy-inhibit inputs of RFC 5280 6.1.1.
+	RequireExplicitPolicy bool
+	InhibitPolicyMapping  bool
+	InhibitAnyPolicy      bool
 }
 
 const (
@@ -403,7 +426,7 @@ func domainToReverseLabels(domain string) (reverseLabels []string, ok bool) {
 	return reverseLabels, true
 }
 
-func matchEmailConstraint(mailbox rfc2821Mailbox, constraint string) (bool, error) {
+func matchEmailConstraint(mailbox rfc2821Mailbox, constraint string, excluded bool) (bool, error) {
 	// If the constraint contains an @, then it specifies an exact mailbox
 	// name.
 	if strings.Contains(constraint, "@") {
@@ -416,10 +439,10 @@ func matchEmailConstraint(mailbox rfc2821Mailbox, constraint string) (bool, erro
 
 	// Otherwise the constraint is like a DNS constraint of the domain part
 	// of the mailbox.
-	return matchDomainConstraint(mailbox.domain, constraint)
+	return matchDomainConstraint(mailbox.domain, constraint, excluded)
 }
 
-func matchURIConstraint(uri *url.URL, constraint string) (bool, error) {
+func matchURIConstraint(uri *url.URL, constraint string, excluded bool) (bool, error) {
 	// From RFC 5280, Section 4.2.1.10:
 	// “a uniformResourceIdentifier that does not include an authority
 	// component with a host name specified as a fully qualified domain
@@ -446,7 +469,7 @@ func matchURIConstraint(uri *url.URL, constraint string) (bool, error) {
 		return false, fmt.Errorf("URI with IP (%q) cannot be matched against constraints", uri.String())
 	}
 
-	return matchDomainConstraint(host, constraint)
+	return matchDomainConstraint(host, constraint, excluded)
 }
 
 func matchIPConstraint(ip net.IP, constraint *net.IPNet) (bool, error) {
@@ -463,7 +486,10 @@ func matchIPConstraint(ip net.IP, constraint *net.IPNet) (bool, error) {
 	return true, nil
 }
 
-func matchDomainConstraint(domain, constraint string) (bool, error) {
+// matchDomainConstraint reports whether domain is within the subtree of
+// constraint. When checking an excluded subtree, a wildcard domain matches if
+// any of the names it covers does.
+func matchDomainConstraint(domain, constraint string, excluded bool) (bool, error) {
 	// The meaning of zero length constraints is not specified, but this
 	// code follows NSS and accepts them as matching everything.
 	if len(constraint) == 0 {
@@ -491,6 +517,12 @@ func matchDomainConstraint(domain, constraint string) (bool, error) {
 		return false, fmt.Errorf("x509: internal error: cannot parse domain %q", constraint)
 	}
 
+	// The leftmost label of a wildcard domain stands for any label, so
+	// *.example.com is excluded by an excluded subtree of foo.example.com.
+	if excluded && !mustHaveSubdomains && len(domainLabels) == len(constraintLabels) && domainLabels[len(domainLabels)-1] == "*" {
+		domainLabels = append(domainLabels[:len(domainLabels)-1:len(domainLabels)-1], constraintLabels[len(constraintLabels)-1])
+	}
+
 	if len(domainLabels) < len(constraintLabels) ||
 		(mustHaveSubdomains && len(domainLabels) == len(constraintLabels)) {
 		return false, nil
@@ -515,7 +547,7 @@ func (c *Certificate) checkNameConstraints(count *int,
 	nameType string,
 	name string,
 	parsedName interface{},
-	match func(parsedName, constraint interface{}) (match bool, err error),
+	match func(parsedName, constraint interface{}, excluded bool) (match bool, err error),
 	permitted, excluded interface{}) error {
 
 	excludedValue := reflect.ValueOf(excluded)
@@ -527,7 +559,7 @@ func (c *Certificate) checkNameConstraints(count *int,
 
 	for i := 0; i < excludedValue.Len(); i++ {
 		constraint := excludedValue.Index(i).Interface()
-		match, err := match(parsedName, constraint)
+		match, err := match(parsedName, constraint, true)
 		if err != nil {
 			return CertificateInvalidError{c, CANotAuthorizedForThisName, err.Error()}
 		}
@@ -549,7 +581,7 @@ func (c *Certificate) checkNameConstraints(count *int,
 		constraint := permittedValue.Index(i).Interface()
 
 		var err error
-		if ok, err = match(parsedName, constraint); err != nil {
+		if ok, err = match(parsedName, constraint, false); err != nil {
 			return CertificateInvalidError{c, CANotAuthorizedForThisName, err.Error()}
 		}
 
@@ -565,6 +597,73 @@ func (c *Certificate) checkNameConstraints(count *int,
 	return nil
 }
 
+// checkSANConstraints checks that the name constraints of c permit the names
+// in the SAN extension of sanCert.
+func (c *Certificate) checkSANConstraints(sanCert *Certificate, count *int, maxConstraintComparisons int) error {
+	return forEachSAN(sanCert.getSANExtension(), func(tag int, data []byte) error {
+		switch tag {
+		case nameTypeEmail:
+			name := string(data)
+			mailbox, ok := parseRFC2821Mailbox(name)
+			if !ok {
+				return fmt.Errorf("x509: cannot parse rfc822Name %q", mailbox)
+			}
+
+			if err := c.checkNameConstraints(count, maxConstraintComparisons, "email address", name, mailbox,
+				func(parsedName, constraint interface{}, excluded bool) (bool, error) {
+					return matchEmailConstraint(parsedName.(rfc2821Mailbox), constraint.(string), excluded)
+				}, c.PermittedEmailAddresses, c.ExcludedEmailAddresses); err != nil {
+				return err
+			}
+
+		case nameTypeDNS:
+			name := string(data)
+			if _, ok := domainToReverseLabels(name); !ok {
+				return fmt.Errorf("x509: cannot parse dnsName %q", name)
+			}
+
+			if err := c.checkNameConstraints(count, maxConstraintComparisons, "DNS name", name, name,
+				func(parsedName, constraint interface{}, excluded bool) (bool, error) {
+					return matchDomainConstraint(parsedName.(string), constraint.(string), excluded)
+				}, c.PermittedDNSDomains, c.ExcludedDNSDomains); err != nil {
+				return err
+			}
+
+		case nameTypeURI:
+			name := string(data)
+			uri, err := url.Parse(name)
+			if err != nil {
+				return fmt.Errorf("x509: internal error: URI SAN %q failed to parse", name)
+			}
+
+			if err := c.checkNameConstraints(count, maxConstraintComparisons, "URI", name, uri,
+				func(parsedName, constraint interface{}, excluded bool) (bool, error) {
+					return matchURIConstraint(parsedName.(*url.URL), constraint.(string), excluded)
+				}, c.PermittedURIDomains, c.ExcludedURIDomains); err != nil {
+				return err
+			}
+
+		case nameTypeIP:
+			ip := net.IP(data)
+			if l := len(ip); l != net.IPv4len && l != net.IPv6len {
+				return fmt.Errorf("x509: internal error: IP SAN %x failed to parse", data)
+			}
+
+			if err := c.checkNameConstraints(count, maxConstraintComparisons, "IP address", ip.String(), ip,
+				func(parsedName, constraint interface{}, excluded bool) (bool, error) {
+					return matchIPConstraint(parsedName.(net.IP), constraint.(*net.IPNet))
+				}, c.PermittedIPRanges, c.ExcludedIPRanges); err != nil {
+				return err
+			}
+
+		default:
+			// Unknown SAN types are ignored.
+		}
+
+		return nil
+	})
+}
+
 // isValid performs validity checks on c given that it is a candidate to append
 // to the chain in currentChain.
 func (c *Certificate) isValid(certType int, currentChain []*Certificate, opts *VerifyOptions) error {
@@ -621,72 +720,17 @@ func (c *Certificate) isValid(certType int, currentChain []*Certificate, opts *V
 		// In order to ensure VerifyHostname will not accept an unchecked name,
 		// return an error here.
 		return CertificateInvalidError{c, NameConstraintsWithoutSANs, ""}
-	} else if checkNameConstraints && leaf.hasSANExtension() {
-		err := forEachSAN(leaf.getSANExtension(), func(tag int, data []byte) error {
-			switch tag {
-			case nameTypeEmail:
-				name := string(data)
-				mailbox, ok := parseRFC2821Mailbox(name)
-				if !ok {
-					return fmt.Errorf("x509: cannot parse rfc822Name %q", mailbox)
-				}
-
-				if err := c.checkNameConstraints(&comparisonCount, maxConstraintComparisons, "email address", name, mailbox,
-					func(parsedName, constraint interface{}) (bool, error) {
-						return matchEmailConstraint(parsedName.(rfc2821Mailbox), constraint.(string))
-					}, c.PermittedEmailAddresses, c.ExcludedEmailAddresses); err != nil {
-					return err
-				}
-
-			case nameTypeDNS:
-				name := string(data)
-				if _, ok := domainToReverseLabels(name); !ok {
-					return fmt.Errorf("x509: cannot parse dnsName %q", name)
-				}
-
-				if err := c.checkNameConstraints(&comparisonCount, maxConstraintComparisons, "DNS name", name, name,
-					func(parsedName, constraint interface{}) (bool, error) {
-						return matchDomainConstraint(parsedName.(string), constraint.(string))
-					}, c.PermittedDNSDomains, c.ExcludedDNSDomains); err != nil {
-					return err
-				}
-
-			case nameTypeURI:
-				name := string(data)
-				uri, err := url.Parse(name)
-				if err != nil {
-					return fmt.Errorf("x509: internal error: URI SAN %q failed to parse", name)
-				}
-
-				if err := c.checkNameConstraints(&comparisonCount, maxConstraintComparisons, "URI", name, uri,
-					func(parsedName, constraint interface{}) (bool, error) {
-						return matchURIConstraint(parsedName.(*url.URL), constraint.(string))
-					}, c.PermittedURIDomains, c.ExcludedURIDomains); err != nil {
-					return err
-				}
-
-			case nameTypeIP:
-				ip := net.IP(data)
-				if l := len(ip); l != net.IPv4len && l != net.IPv6len {
-					return fmt.Errorf("x509: internal error: IP SAN %x failed to parse", data)
-				}
-
-				if err := c.checkNameConstraints(&comparisonCount, maxConstraintComparisons, "IP address", ip.String(), ip,
-					func(parsedName, constraint interface{}) (bool, error) {
-						return matchIPConstraint(parsedName.(net.IP), constraint.(*net.IPNet))
-					}, c.PermittedIPRanges, c.ExcludedIPRanges); err != nil {
-					return err
-				}
-
-			default:
-				// Unknown SAN types are ignored.
+	} else if checkNameConstraints {
+		// RFC 5280 applies name constraints to every certificate below the
+		// constraining CA, not just the leaf: the names claimed by CA
+		// certificates, such as cross-certificates, are checked too.
+		for _, sanCert := range currentChain {
+			if !sanCert.hasSANExtension() {
+				continue
+			}
+			if err := c.checkSANConstraints(sanCert, &comparisonCount, maxConstraintComparisons); err != nil {
+				return err
 			}
-
-			return nil
-		})
-
-		if err != nil {
-			return err
 		}
 	}
 
@@ -739,6 +783,10 @@ func (c *Certificate) isValid(certType int, currentChain []*Certificate, opts *V
 // root that enumerates EKUs prevents a leaf from asserting an EKU not in that
 // list.
 //
+// Certificate policies are processed as specified by RFC 5280, section 6.1,
+// and RFC 9618, with the inputs in opts. Chains which are not valid for them
+// are not returned.
+//
 // WARNING: this function doesn't do any revocation checking.
 func (c *Certificate) Verify(opts VerifyOptions) (chains [][]*Certificate, err error) {
 	// Platform-specific verification needs the ASN.1 contents so
@@ -787,6 +835,19 @@ func (c *Certificate) Verify(opts VerifyOptions) (chains [][]*Certificate, err e
 		}
 	}
 
+	if !opts.DisablePolicyChecks {
+		var policyChains [][]*Certificate
+		for _, candidate := range candidateChains {
+			if policiesValid(candidate, &opts) {
+				policyChains = append(policyChains, candidate)
+			}
+		}
+		if len(policyChains) == 0 {
+			return nil, CertificateInvalidError{c, NoValidChains, "all candidate chains have invalid policies"}
+		}
+		candidateChains = policyChains
+	}
+
 	keyUsages := opts.KeyUsages
 	if len(keyUsages) == 0 {
 		keyUsages = []ExtKeyUsage{ExtKeyUsageServerAuth}
@@ -867,10 +928,19 @@ func (c *Certificate) buildChains(cache map[*Certificate][][]*Certificate, curre
 			if cache == nil {
 				cache = make(map[*Certificate][][]*Certificate)
 			}
+			// The chains above candidate depend on the path below it if
+			// CA certificates on that path claim names, which must satisfy
+			// the name constraints above, so those are not cached.
+			cacheable := true
+			for _, cert := range currentChain[1:] {
+				cacheable = cacheable && !cert.hasSANExtension()
+			}
 			childChains, ok := cache[candidate]
-			if !ok {
+			if !ok || !cacheable {
 				childChains, err = candidate.buildChains(cache, appendToFreshChain(currentChain, candidate), sigChecks, opts)
-				cache[candidate] = childChains
+				if cacheable {
+					cache[candidate] = childChains
+				}
 			}
 			chains = append(chains, childChains...)
 		}
@@ -20,6 +20,8 @@
 //   - Add options to disable various validation checks (times, EKUs etc).
 //   - Use NonFatalErrors type for some errors and continue parsing; this
 //     can be checked with IsFatal(err).
+//   - ParseOptions to select the classes of deviation tolerated by parsing
+//     (in parse_options.go).
 //   - Support for short bitlength ECDSA curves (in curves.go).
 //   - Certificate Transparency specific function:
 //   - Parsing and marshaling of SCTList extension.
@@ -35,10 +37,19 @@
 //   - RPKI support:
 //   - Support for SubjectInfoAccess extension
 //   - Support for RFC3779 extensions (in rpki.go)
+//   - ePassport support:
+//   - Support for ICAO Doc 9303 extensions (in icao.go)
+//   - Support for explicit ECDSA curve parameters matching supported curves
 //   - RSAES-OAEP support:
 //   - Support for parsing RSASES-OAEP public keys from certificates
 //   - Ed25519 support:
 //   - Support for parsing and marshaling Ed25519 keys
+//   - RFC 8410 support:
+//   - Support for Ed448 keys and signatures (with the ed448 package), and
+//     for parsing and marshaling X25519 and X448 keys
+//   - SM2 support:
+//   - Support for parsing SM2 keys and verifying SM2-with-SM3 signatures
+//     (in sm2.go)
 //   - General improvements:
 //   - Export and use OID values throughout.
 //   - Export OIDFromNamedCurve().
@@ -51,6 +62,7 @@ import (
 	"bytes"
 	"crypto"
 	"crypto/dsa"
+	"crypto/ecdh"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rsa"
@@ -75,6 +87,7 @@ import (
 
 	"github.com/RarimoVoting/certificate-transparency-go/asn1"
 	"github.com/RarimoVoting/certificate-transparency-go/tls"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/ed448"
 	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
 )
 
@@ -87,8 +100,9 @@ type pkixPublicKey struct {
 
 // ParsePKIXPublicKey parses a public key in PKIX, ASN.1 DER form.
 //
-// It returns a *rsa.PublicKey, *dsa.PublicKey, *ecdsa.PublicKey, or
-// ed25519.PublicKey. More types might be supported in the future.
+// It returns a *rsa.PublicKey, *dsa.PublicKey, *ecdsa.PublicKey,
+// ed25519.PublicKey, ed448.PublicKey, *ecdh.PublicKey (for X25519) or
+// X448PublicKey. More types might be supported in the future.
 //
 // This kind of key is commonly encoded in PEM blocks of type "PUBLIC KEY".
 func ParsePKIXPublicKey(derBytes []byte) (pub interface{}, err error) {
@@ -144,6 +158,18 @@ func marshalPublicKey(pub interface{}) (publicKeyBytes []byte, publicKeyAlgorith
 	case ed25519.PublicKey:
 		publicKeyBytes = pub
 		publicKeyAlgorithm.Algorithm = OIDPublicKeyEd25519
+	case ed448.PublicKey:
+		publicKeyBytes = pub
+		publicKeyAlgorithm.Algorithm = OIDPublicKeyEd448
+	case *ecdh.PublicKey:
+		if pub.Curve() != ecdh.X25519() {
+			return nil, pkix.AlgorithmIdentifier{}, errors.New("x509: unsupported ECDH curve")
+		}
+		publicKeyBytes = pub.Bytes()
+		publicKeyAlgorithm.Algorithm = OIDPublicKeyX25519
+	case X448PublicKey:
+		publicKeyBytes = pub
+		publicKeyAlgorithm.Algorithm = OIDPublicKeyX448
 	default:
 		return nil, pkix.AlgorithmIdentifier{}, fmt.Errorf("x509: unsupported public key type: %T", pub)
 	}
@@ -153,8 +179,9 @@ func marshalPublicKey(pub interface{}) (publicKeyBytes []byte, publicKeyAlgorith
 
 // MarshalPKIXPublicKey converts a public key to PKIX, ASN.1 DER form.
 //
-// The following key types are currently supported: *rsa.PublicKey, *ecdsa.PublicKey
-// and ed25519.PublicKey. Unsupported key types result in an error.
+// The following key types are currently supported: *rsa.PublicKey,
+// *ecdsa.PublicKey, ed25519.PublicKey, ed448.PublicKey, *ecdh.PublicKey (for
+// X25519) and X448PublicKey. Unsupported key types result in an error.
 //
 // This kind of key is commonly encoded in PEM blocks of type "PUBLIC KEY".
 func MarshalPKIXPublicKey(pub interface{}) ([]byte, error) {
@@ -257,6 +284,8 @@ const (
 	SHA384WithRSAPSS
 	SHA512WithRSAPSS
 	PureEd25519
+	SM2WithSM3
+	PureEd448
 )
 
 // RFC 4055,  6. Basic object identifiers
@@ -321,6 +350,9 @@ const (
 	ECDSA
 	Ed25519
 	RSAESOAEP
+	Ed448
+	X25519
+	X448
 )
 
 var publicKeyAlgoName = [...]string{
@@ -329,6 +361,9 @@ var publicKeyAlgoName = [...]string{
 	ECDSA:     "ECDSA",
 	Ed25519:   "Ed25519",
 	RSAESOAEP: "RSAESOAEP",
+	Ed448:     "Ed448",
+	X25519:    "X25519",
+	X448:      "X448",
 }
 
 func (algo PublicKeyAlgorithm) String() string {
@@ -391,7 +426,10 @@ func (algo PublicKeyAlgorithm) String() string {
 //
 // RFC 8410 3 Curve25519 and Curve448 Algorithm Identifiers
 //
+// id-X25519    OBJECT IDENTIFIER ::= { 1 3 101 110 }
+// id-X448      OBJECT IDENTIFIER ::= { 1 3 101 111 }
 // id-Ed25519   OBJECT IDENTIFIER ::= { 1 3 101 112 }
+// id-Ed448     OBJECT IDENTIFIER ::= { 1 3 101 113 }
 
 var (
 	oidSignatureMD2WithRSA      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 2}
@@ -408,6 +446,7 @@ var (
 	oidSignatureECDSAWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
 	oidSignatureECDSAWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
 	oidSignatureEd25519         = asn1.ObjectIdentifier{1, 3, 101, 112}
+	oidSignatureEd448           = asn1.ObjectIdentifier{1, 3, 101, 113}
 
 	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
 	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
@@ -446,6 +485,8 @@ var signatureAlgorithmDetails = []struct {
 	{ECDSAWithSHA384, "ECDSA-SHA384", oidSignatureECDSAWithSHA384, ECDSA, crypto.SHA384},
 	{ECDSAWithSHA512, "ECDSA-SHA512", oidSignatureECDSAWithSHA512, ECDSA, crypto.SHA512},
 	{PureEd25519, "Ed25519", oidSignatureEd25519, Ed25519, crypto.Hash(0) /* no pre-hashing */},
+	{SM2WithSM3, "SM2-SM3", oidSignatureSM2WithSM3, ECDSA, crypto.Hash(0) /* hashed with SM3 by sm2Verify */},
+	{PureEd448, "Ed448", oidSignatureEd448, Ed448, crypto.Hash(0) /* no pre-hashing */},
 }
 
 // pssParameters reflects the parameters in an AlgorithmIdentifier that
@@ -508,7 +549,7 @@ func rsaPSSParameters(hashFunc crypto.Hash) asn1.RawValue {
 // SignatureAlgorithmFromAI converts an PKIX algorithm identifier to the
 // equivalent local constant.
 func SignatureAlgorithmFromAI(ai pkix.AlgorithmIdentifier) SignatureAlgorithm {
-	if ai.Algorithm.Equal(oidSignatureEd25519) {
+	if ai.Algorithm.Equal(oidSignatureEd25519) || ai.Algorithm.Equal(oidSignatureEd448) {
 		// RFC 8410, Section 3
 		// > For all of the OIDs, the parameters MUST be absent.
 		if len(ai.Parameters.FullBytes) != 0 {
@@ -586,8 +627,15 @@ var (
 	OIDPublicKeyECDSA       = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
 	OIDPublicKeyRSAObsolete = asn1.ObjectIdentifier{2, 5, 8, 1, 1}
 	OIDPublicKeyEd25519     = oidSignatureEd25519
+	OIDPublicKeyEd448       = oidSignatureEd448
+	OIDPublicKeyX25519      = asn1.ObjectIdentifier{1, 3, 101, 110}
+	OIDPublicKeyX448        = asn1.ObjectIdentifier{1, 3, 101, 111}
 )
 
+// X448PublicKey is an X448 public key (RFC 7748), which is only usable for
+// key agreement.
+type X448PublicKey []byte
+
 func getPublicKeyAlgorithmFromOID(oid asn1.ObjectIdentifier) PublicKeyAlgorithm {
 	switch {
 	case oid.Equal(OIDPublicKeyRSA):
@@ -600,6 +648,12 @@ func getPublicKeyAlgorithmFromOID(oid asn1.ObjectIdentifier) PublicKeyAlgorithm
 		return RSAESOAEP
 	case oid.Equal(OIDPublicKeyEd25519):
 		return Ed25519
+	case oid.Equal(OIDPublicKeyEd448):
+		return Ed448
+	case oid.Equal(OIDPublicKeyX25519):
+		return X25519
+	case oid.Equal(OIDPublicKeyX448):
+		return X448
 	}
 	return UnknownPublicKeyAlgorithm
 }
@@ -648,6 +702,8 @@ func namedCurveFromOID(oid asn1.ObjectIdentifier, nfe *NonFatalErrors) elliptic.
 		return secp192r1()
 	case oid.Equal(OIDNamedCurveP192_2):
 		return secp192r1()
+	case oid.Equal(OIDNamedCurveSM2):
+		return sm2P256V1()
 	}
 	return nil
 }
@@ -666,6 +722,8 @@ func OIDFromNamedCurve(curve elliptic.Curve) (asn1.ObjectIdentifier, bool) {
 		return OIDNamedCurveP521, true
 	case secp192r1():
 		return OIDNamedCurveP192, true
+	case sm2P256V1():
+		return OIDNamedCurveSM2, true
 	}
 
 	return nil, false
@@ -897,9 +955,35 @@ type Certificate struct {
 
 	PolicyIdentifiers []asn1.ObjectIdentifier
 
+	// PolicyMappings contains the policy mappings of the certificate, from
+	// RFC 5280, 4.2.1.5.
+	PolicyMappings []PolicyMapping
+
+	// InhibitAnyPolicy and InhibitAnyPolicyZero hold the inhibitAnyPolicy
+	// extension, RFC 5280, 4.2.1.14. InhibitAnyPolicyZero indicates that a
+	// zero InhibitAnyPolicy is an actual value of zero rather than an absent
+	// extension.
+	InhibitAnyPolicy     int
+	InhibitAnyPolicyZero bool
+
+	// InhibitPolicyMapping, RequireExplicitPolicy and their Zero flags hold
+	// the policyConstraints extension, RFC 5280, 4.2.1.11, as
+	// InhibitAnyPolicy does.
+	InhibitPolicyMapping      int
+	InhibitPolicyMappingZero  bool
+	RequireExplicitPolicy     int
+	RequireExplicitPolicyZero bool
+
 	RPKIAddressRanges                   []*IPAddressFamilyBlocks
 	RPKIASNumbers, RPKIRoutingDomainIDs *ASIdentifiers
 
+	// ICAODocumentTypes holds the document type list extension of ePassport
+	// Document Signer certificates (ICAO Doc 9303 Part 12), e.g. "P" for
+	// passports. ICAONameChange indicates the name change extension of CSCA
+	// link certificates.
+	ICAODocumentTypes []string
+	ICAONameChange    bool
+
 	// Certificate Transparency SCT extension contents; this is a TLS-encoded
 	// SignedCertificateTimestampList (RFC 6962 s3.3).
 	RawSCT  []byte
@@ -1070,7 +1154,7 @@ func checkSignature(algo SignatureAlgorithm, signed, signature []byte, publicKey
 
 	switch hashType {
 	case crypto.Hash(0):
-		if pubKeyAlgo != Ed25519 {
+		if pubKeyAlgo != Ed25519 && pubKeyAlgo != Ed448 && algo != SM2WithSM3 {
 			return ErrUnsupportedAlgorithm
 		}
 	case crypto.MD5:
@@ -1120,6 +1204,9 @@ func checkSignature(algo SignatureAlgorithm, signed, signature []byte, publicKey
 		if pubKeyAlgo != ECDSA {
 			return signaturePublicKeyAlgoMismatchError(pubKeyAlgo, pub)
 		}
+		if algo == SM2WithSM3 {
+			return sm2Verify(pub, signed, signature)
+		}
 		ecdsaSig := new(ecdsaSignature)
 		if rest, err := asn1.Unmarshal(signature, ecdsaSig); err != nil {
 			return err
@@ -1141,6 +1228,14 @@ func checkSignature(algo SignatureAlgorithm, signed, signature []byte, publicKey
 			return errors.New("x509: Ed25519 verification failure")
 		}
 		return
+	case ed448.PublicKey:
+		if pubKeyAlgo != Ed448 {
+			return signaturePublicKeyAlgoMismatchError(pubKeyAlgo, pub)
+		}
+		if !ed448.Verify(pub, signed, signature) {
+			return errors.New("x509: Ed448 verification failure")
+		}
+		return
 	}
 	return ErrUnsupportedAlgorithm
 }
@@ -1161,11 +1256,23 @@ func (h UnhandledCriticalExtension) Error() string {
 	return fmt.Sprintf("x509: unhandled critical extension (%v)", h.ID)
 }
 
-// removeExtension takes a DER-encoded TBSCertificate, removes the extension
-// specified by oid (preserving the order of other extensions), and returns the
-// result still as a DER-encoded TBSCertificate.  This function will fail if
-// there is not exactly 1 extension of the type specified by the oid present.
-func removeExtension(tbsData []byte, oid asn1.ObjectIdentifier) ([]byte, error) {
+// rawTBSCertificate is a DER-encoded TBSCertificate split into its
+// DER-encoded elements, so that some of them can be changed without
+// re-encoding the others. Re-encoding a parsed TBSCertificate would turn
+// encodings which are valid but not canonical, e.g. a GeneralizedTime before
+// 2050 or an explicit FALSE criticality, into canonical ones, which changes
+// the leaf hash of the certificate in a CT log.
+type rawTBSCertificate struct {
+	fields     []asn1.RawValue
+	issuerAt   int
+	extsAt     int             // -1 if there are no extensions.
+	extensions []asn1.RawValue // DER-encoded extensions.
+	extIDs     []asn1.ObjectIdentifier
+}
+
+// parseRawTBSCertificate checks that tbsData is a valid TBSCertificate, and
+// splits it into its elements.
+func parseRawTBSCertificate(tbsData []byte) (*rawTBSCertificate, error) {
 	var tbs tbsCertificate
 	rest, err := asn1.Unmarshal(tbsData, &tbs)
 	if err != nil {
@@ -1173,30 +1280,124 @@ func removeExtension(tbsData []byte, oid asn1.ObjectIdentifier) ([]byte, error)
 	} else if rLen := len(rest); rLen > 0 {
 		return nil, fmt.Errorf("trailing data (%d bytes) after TBSCertificate", rLen)
 	}
+
+	var seq asn1.RawValue
+	if _, err := asn1.Unmarshal(tbsData, &seq); err != nil {
+		return nil, fmt.Errorf("failed to parse TBSCertificate: %v", err)
+	}
+	raw := &rawTBSCertificate{issuerAt: 2, extsAt: -1}
+	for data := seq.Bytes; len(data) > 0; {
+		var field asn1.RawValue
+		if data, err = asn1.Unmarshal(data, &field); err != nil {
+			return nil, fmt.Errorf("failed to parse TBSCertificate field: %v", err)
+		}
+		if len(raw.fields) == 0 && field.Class == asn1.ClassContextSpecific && field.Tag == 0 {
+			// An explicit version precedes the serial number.
+			raw.issuerAt = 3
+		}
+		if field.Class == asn1.ClassContextSpecific && field.Tag == 3 {
+			raw.extsAt = len(raw.fields)
+		}
+		raw.fields = append(raw.fields, field)
+	}
+	if raw.extsAt < 0 {
+		return raw, nil
+	}
+
+	var exts asn1.RawValue
+	if _, err := asn1.Unmarshal(raw.fields[raw.extsAt].Bytes, &exts); err != nil {
+		return nil, fmt.Errorf("failed to parse extensions: %v", err)
+	}
+	for data := exts.Bytes; len(data) > 0; {
+		var ext asn1.RawValue
+		if data, err = asn1.Unmarshal(data, &ext); err != nil {
+			return nil, fmt.Errorf("failed to parse extension: %v", err)
+		}
+		var id asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(ext.Bytes, &id); err != nil {
+			return nil, fmt.Errorf("failed to parse extension ID: %v", err)
+		}
+		raw.extensions = append(raw.extensions, ext)
+		raw.extIDs = append(raw.extIDs, id)
+	}
+	return raw, nil
+}
+
+// findExtension returns the position of the only extension with the given
+// oid, or -1 if there is none.
+func (raw *rawTBSCertificate) findExtension(oid asn1.ObjectIdentifier) (int, error) {
 	extAt := -1
-	for i, ext := range tbs.Extensions {
-		if ext.Id.Equal(oid) {
+	for i, id := range raw.extIDs {
+		if id.Equal(oid) {
 			if extAt != -1 {
-				return nil, errors.New("multiple extensions of specified type present")
+				return -1, errors.New("multiple extensions of specified type present")
 			}
 			extAt = i
 		}
 	}
-	if extAt == -1 {
-		return nil, errors.New("no extension of specified type present")
-	}
-	tbs.Extensions = append(tbs.Extensions[:extAt], tbs.Extensions[extAt+1:]...)
-	// Clear out the asn1.RawContent so the re-marshal operation sees the
-	// updated structure (rather than just copying the out-of-date DER data).
-	tbs.Raw = nil
+	return extAt, nil
+}
 
-	data, err := asn1.Marshal(tbs)
+func (raw *rawTBSCertificate) removeExtension(i int) {
+	raw.extensions = append(raw.extensions[:i], raw.extensions[i+1:]...)
+	raw.extIDs = append(raw.extIDs[:i], raw.extIDs[i+1:]...)
+}
+
+// marshal returns the DER encoding of the TBSCertificate, in which only the
+// changed elements and the lengths of their parents are re-encoded.
+func (raw *rawTBSCertificate) marshal() ([]byte, error) {
+	var body []byte
+	for i, field := range raw.fields {
+		if i != raw.extsAt {
+			body = append(body, field.FullBytes...)
+			continue
+		}
+		if len(raw.extensions) == 0 {
+			// Extensions are optional, and omitted rather than empty.
+			continue
+		}
+		var extsBody []byte
+		for _, ext := range raw.extensions {
+			extsBody = append(extsBody, ext.FullBytes...)
+		}
+		exts, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: extsBody})
+		if err != nil {
+			return nil, fmt.Errorf("failed to re-marshal extensions: %v", err)
+		}
+		wrapped, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 3, IsCompound: true, Bytes: exts})
+		if err != nil {
+			return nil, fmt.Errorf("failed to re-marshal extensions: %v", err)
+		}
+		body = append(body, wrapped...)
+	}
+	data, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: body})
 	if err != nil {
 		return nil, fmt.Errorf("failed to re-marshal TBSCertificate: %v", err)
 	}
 	return data, nil
 }
 
+// removeExtension takes a DER-encoded TBSCertificate, removes the extension
+// specified by oid (preserving the order and encoding of the other
+// extensions), and returns the result still as a DER-encoded TBSCertificate.
+// This function will fail if there is not exactly 1 extension of the type
+// specified by the oid present.
+func removeExtension(tbsData []byte, oid asn1.ObjectIdentifier) ([]byte, error) {
+	raw, err := parseRawTBSCertificate(tbsData)
+	if err != nil {
+		return nil, err
+	}
+	extAt, err := raw.findExtension(oid)
+	if err != nil {
+		return nil, err
+	}
+	if extAt == -1 {
+		return nil, errors.New("no extension of specified type present")
+	}
+	raw.removeExtension(extAt)
+	return raw.marshal()
+}
+
 // RemoveSCTList takes a DER-encoded TBSCertificate and removes the CT SCT
 // extension that contains the SCT list (preserving the order of other
 // extensions), and returns the result still as a DER-encoded TBSCertificate.
@@ -1218,7 +1419,7 @@ func RemoveCTPoison(tbsData []byte) ([]byte, error) {
 // TBSCertificate.
 //
 // This function removes the CT poison extension (there must be exactly 1 of
-// these), preserving the order of other extensions.
+// these), preserving the order and encoding of other extensions.
 //
 // If preIssuer is provided, this should be a special intermediate certificate
 // that was used to sign the precert (indicated by having the special
@@ -1229,35 +1430,20 @@ func RemoveCTPoison(tbsData []byte) ([]byte, error) {
 //   - The precert's AuthorityKeyId is changed to the AuthorityKeyId of the
 //     intermediate.
 func BuildPrecertTBS(tbsData []byte, preIssuer *Certificate) ([]byte, error) {
-	data, err := removeExtension(tbsData, OIDExtensionCTPoison)
+	raw, err := parseRawTBSCertificate(tbsData)
 	if err != nil {
 		return nil, err
 	}
-
-	var tbs tbsCertificate
-	rest, err := asn1.Unmarshal(data, &tbs)
+	poisonAt, err := raw.findExtension(OIDExtensionCTPoison)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse TBSCertificate: %v", err)
-	} else if rLen := len(rest); rLen > 0 {
-		return nil, fmt.Errorf("trailing data (%d bytes) after TBSCertificate", rLen)
+		return nil, err
 	}
+	if poisonAt == -1 {
+		return nil, errors.New("no extension of specified type present")
+	}
+	raw.removeExtension(poisonAt)
 
 	if preIssuer != nil {
-		// Update the precert's Issuer field.  Use the RawIssuer rather than the
-		// parsed Issuer to avoid any chance of ASN.1 differences (e.g. switching
-		// from UTF8String to PrintableString).
-		tbs.Issuer.FullBytes = preIssuer.RawIssuer
-
-		// Also need to update the cert's AuthorityKeyID extension
-		// to that of the preIssuer.
-		var issuerKeyID []byte
-		for _, ext := range preIssuer.Extensions {
-			if ext.Id.Equal(OIDExtensionAuthorityKeyId) {
-				issuerKeyID = ext.Value
-				break
-			}
-		}
-
 		// Check the preIssuer has the CT EKU.
 		seenCTEKU := false
 		for _, eku := range preIssuer.ExtKeyUsage {
@@ -1270,40 +1456,52 @@ func BuildPrecertTBS(tbsData []byte, preIssuer *Certificate) ([]byte, error) {
 			return nil, fmt.Errorf("issuer does not have CertificateTransparency extended key usage")
 		}
 
-		keyAt := -1
-		for i, ext := range tbs.Extensions {
+		// Update the precert's Issuer field.  Use the RawIssuer rather than the
+		// parsed Issuer to avoid any chance of ASN.1 differences (e.g. switching
+		// from UTF8String to PrintableString).
+		raw.fields[raw.issuerAt] = asn1.RawValue{FullBytes: preIssuer.RawIssuer}
+
+		// Also need to update the cert's AuthorityKeyID extension
+		// to that of the preIssuer.
+		var issuerKeyID []byte
+		for _, ext := range preIssuer.Extensions {
 			if ext.Id.Equal(OIDExtensionAuthorityKeyId) {
-				keyAt = i
+				issuerKeyID = ext.Value
 				break
 			}
 		}
+		keyAt, err := raw.findExtension(OIDExtensionAuthorityKeyId)
+		if err != nil {
+			return nil, err
+		}
 		if keyAt >= 0 {
-			// PreCert has an auth-key-id; replace it with the value from the preIssuer
-			if issuerKeyID != nil {
-				tbs.Extensions[keyAt].Value = issuerKeyID
+			if issuerKeyID == nil {
+				raw.removeExtension(keyAt)
 			} else {
-				tbs.Extensions = append(tbs.Extensions[:keyAt], tbs.Extensions[keyAt+1:]...)
+				// PreCert has an auth-key-id; replace its value with the one
+				// from the preIssuer, keeping its criticality.
+				var ext pkix.Extension
+				if _, err := asn1.Unmarshal(raw.extensions[keyAt].FullBytes, &ext); err != nil {
+					return nil, fmt.Errorf("failed to parse AuthorityKeyId extension: %v", err)
+				}
+				ext.Value = issuerKeyID
+				data, err := asn1.Marshal(ext)
+				if err != nil {
+					return nil, fmt.Errorf("failed to re-marshal AuthorityKeyId extension: %v", err)
+				}
+				raw.extensions[keyAt] = asn1.RawValue{FullBytes: data}
 			}
 		} else if issuerKeyID != nil {
 			// PreCert did not have an auth-key-id, but the preIssuer does, so add it at the end.
-			authKeyIDExt := pkix.Extension{
-				Id:       OIDExtensionAuthorityKeyId,
-				Critical: false,
-				Value:    issuerKeyID,
+			data, err := asn1.Marshal(pkix.Extension{Id: OIDExtensionAuthorityKeyId, Value: issuerKeyID})
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal AuthorityKeyId extension: %v", err)
 			}
-			tbs.Extensions = append(tbs.Extensions, authKeyIDExt)
+			raw.extensions = append(raw.extensions, asn1.RawValue{FullBytes: data})
+			raw.extIDs = append(raw.extIDs, OIDExtensionAuthorityKeyId)
 		}
-
-		// Clear out the asn1.RawContent so the re-marshal operation sees the
-		// updated structure (rather than just copying the out-of-date DER data).
-		tbs.Raw = nil
 	}
-
-	data, err = asn1.Marshal(tbs)
-	if err != nil {
-		return nil, fmt.Errorf("failed to re-marshal TBSCertificate: %v", err)
-	}
-	return data, nil
+	return raw.marshal()
 }
 
 type basicConstraints struct {
@@ -1317,6 +1515,19 @@ type policyInformation struct {
 	// policyQualifiers omitted
 }
 
+// PolicyMapping is a mapping of a policy of the issuer of a certificate to
+// an equivalent policy of its subject, RFC 5280, 4.2.1.5.
+type PolicyMapping struct {
+	IssuerDomainPolicy  asn1.ObjectIdentifier
+	SubjectDomainPolicy asn1.ObjectIdentifier
+}
+
+// RFC 5280, 4.2.1.11
+type policyConstraints struct {
+	RequireExplicitPolicy int `asn1:"optional,tag:0,default:-1"`
+	InhibitPolicyMapping  int `asn1:"optional,tag:1,default:-1"`
+}
+
 const (
 	nameTypeEmail = 1
 	nameTypeDNS   = 2
@@ -1368,14 +1579,9 @@ func parsePublicKey(algo PublicKeyAlgorithm, keyData *publicKeyInfo, nfe *NonFat
 		}
 
 		p := new(pkcs1PublicKey)
-		rest, err := asn1.Unmarshal(asn1Data, p)
+		rest, err := unmarshalLax(asn1Data, p, nfe)
 		if err != nil {
-			var laxErr error
-			rest, laxErr = asn1.UnmarshalWithParams(asn1Data, p, "lax")
-			if laxErr != nil {
-				return nil, laxErr
-			}
-			nfe.AddError(err)
+			return nil, err
 		}
 		if len(rest) != 0 {
 			return nil, errors.New("x509: trailing data after RSA public key")
@@ -1396,14 +1602,9 @@ func parsePublicKey(algo PublicKeyAlgorithm, keyData *publicKeyInfo, nfe *NonFat
 		return pub, nil
 	case DSA:
 		var p *big.Int
-		rest, err := asn1.Unmarshal(asn1Data, &p)
+		rest, err := unmarshalLax(asn1Data, &p, nfe)
 		if err != nil {
-			var laxErr error
-			rest, laxErr = asn1.UnmarshalWithParams(asn1Data, &p, "lax")
-			if laxErr != nil {
-				return nil, laxErr
-			}
-			nfe.AddError(err)
+			return nil, err
 		}
 		if len(rest) != 0 {
 			return nil, errors.New("x509: trailing data after DSA public key")
@@ -1434,43 +1635,71 @@ func parsePublicKey(algo PublicKeyAlgorithm, keyData *publicKeyInfo, nfe *NonFat
 		namedCurveOID := new(asn1.ObjectIdentifier)
 		rest, err := asn1.Unmarshal(paramsData, namedCurveOID)
 		if err != nil {
-			algoParams := new(SECP192r1Params)
-			algoParams.Integer1 = new(big.Int)
-			algoParams.Integer2 = new(big.Int)
-			algoParams.Integer3 = new(big.Int)
-			algoParams.PrimeFieldSeq.Integer = new(big.Int)
-
-			rest, err = asn1.Unmarshal(paramsData, algoParams)
+			// Explicit curve parameters, as commonly found in ePassport
+			// certificates, are accepted if they are those of a supported
+			// curve.
+			var explicit specifiedECDomain
+			rest, err = asn1.Unmarshal(paramsData, &explicit)
 			if err != nil {
 				return nil, errors.New("x509: failed to parse ECDSA parameters as named curve")
 			}
-
-			namedCurveOID = &algoParams.PrimeFieldSeq.PrimeField
+			if len(rest) != 0 {
+				return nil, errors.New("x509: trailing data after ECDSA parameters")
+			}
+			namedCurve := curveFromExplicitParams(&explicit)
+			if namedCurve == nil {
+				// The key is left unparsed, as for unknown algorithms.
+				nfe.AddError(deviationError{DeviationUnknownCurve, errors.New("x509: unsupported explicit elliptic curve parameters")})
+				return nil, nil
+			}
+			nfe.AddError(fmt.Errorf("x509: explicit parameters given for elliptic curve %s", namedCurve.Params().Name))
+			return unmarshalECDSAKey(namedCurve, asn1Data)
 		}
 		if len(rest) != 0 {
 			return nil, errors.New("x509: trailing data after ECDSA parameters")
 		}
 		namedCurve := namedCurveFromOID(*namedCurveOID, nfe)
 		if namedCurve == nil {
-			return nil, fmt.Errorf("x509: unsupported elliptic curve %v", namedCurveOID)
+			// The key is left unparsed, as for unknown algorithms.
+			nfe.AddError(deviationError{DeviationUnknownCurve, fmt.Errorf("x509: unsupported elliptic curve %v", namedCurveOID)})
+			return nil, nil
 		}
-		x, y := elliptic.Unmarshal(namedCurve, asn1Data)
-		if x == nil {
-			return nil, errors.New("x509: failed to unmarshal elliptic curve point")
+		return unmarshalECDSAKey(namedCurve, asn1Data)
+	case Ed25519:
+		return ed25519.PublicKey(asn1Data), nil
+	case Ed448:
+		if len(asn1Data) != ed448.PublicKeySize {
+			return nil, errors.New("x509: wrong Ed448 public key size")
 		}
-		pub := &ecdsa.PublicKey{
-			Curve: namedCurve,
-			X:     x,
-			Y:     y,
+		return ed448.PublicKey(asn1Data), nil
+	case X25519:
+		pub, err := ecdh.X25519().NewPublicKey(asn1Data)
+		if err != nil {
+			return nil, fmt.Errorf("x509: invalid X25519 public key: %v", err)
 		}
 		return pub, nil
-	case Ed25519:
-		return ed25519.PublicKey(asn1Data), nil
+	case X448:
+		if len(asn1Data) != 56 {
+			return nil, errors.New("x509: wrong X448 public key size")
+		}
+		return X448PublicKey(asn1Data), nil
 	default:
 		return nil, nil
 	}
 }
 
+func unmarshalECDSAKey(curve elliptic.Curve, data []byte) (*ecdsa.PublicKey, error) {
+	x, y := elliptic.Unmarshal(curve, data)
+	if x == nil {
+		return nil, errors.New("x509: failed to unmarshal elliptic curve point")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// SECP192r1Params holds explicit ECDSA parameters with a seed.
+//
+// Deprecated: explicit parameters are parsed as specifiedECDomain, and
+// matched against the supported curves.
 type SECP192r1Params struct {
 	Integer1      *big.Int
 	PrimeFieldSeq struct {
@@ -1865,25 +2094,18 @@ func parseCertificate(in *certificate) (*Certificate, error) {
 
 	out.Version = in.TBSCertificate.Version + 1
 	out.SerialNumber = in.TBSCertificate.SerialNumber
+	if out.SerialNumber != nil && out.SerialNumber.Sign() < 0 {
+		nfe.AddError(deviationError{DeviationNegativeSerial, errors.New("x509: negative serial number")})
+	}
 
 	var issuer, subject pkix.RDNSequence
-	if rest, err := asn1.Unmarshal(in.TBSCertificate.Subject.FullBytes, &subject); err != nil {
-		var laxErr error
-		rest, laxErr = asn1.UnmarshalWithParams(in.TBSCertificate.Subject.FullBytes, &subject, "lax")
-		if laxErr != nil {
-			return nil, laxErr
-		}
-		nfe.AddError(err)
+	if rest, err := unmarshalLax(in.TBSCertificate.Subject.FullBytes, &subject, &nfe); err != nil {
+		return nil, err
 	} else if len(rest) != 0 {
 		return nil, errors.New("x509: trailing data after X.509 subject")
 	}
-	if rest, err := asn1.Unmarshal(in.TBSCertificate.Issuer.FullBytes, &issuer); err != nil {
-		var laxErr error
-		rest, laxErr = asn1.UnmarshalWithParams(in.TBSCertificate.Issuer.FullBytes, &issuer, "lax")
-		if laxErr != nil {
-			return nil, laxErr
-		}
-		nfe.AddError(err)
+	if rest, err := unmarshalLax(in.TBSCertificate.Issuer.FullBytes, &issuer, &nfe); err != nil {
+		return nil, err
 	} else if len(rest) != 0 {
 		return nil, errors.New("x509: trailing data after X.509 subject")
 	}
@@ -1978,14 +2200,9 @@ func parseCertificate(in *certificate) (*Certificate, error) {
 				if len(e.Value) == 0 {
 					nfe.AddError(errors.New("x509: empty ExtendedKeyUsage"))
 				} else {
-					rest, err := asn1.Unmarshal(e.Value, &keyUsage)
+					rest, err := unmarshalLax(e.Value, &keyUsage, &nfe)
 					if err != nil {
-						var laxErr error
-						rest, laxErr = asn1.UnmarshalWithParams(e.Value, &keyUsage, "lax")
-						if laxErr != nil {
-							return nil, laxErr
-						}
-						nfe.AddError(err)
+						return nil, err
 					}
 					if len(rest) != 0 {
 						return nil, errors.New("x509: trailing data after X.509 ExtendedKeyUsage")
@@ -2023,6 +2240,59 @@ func parseCertificate(in *certificate) (*Certificate, error) {
 					out.PolicyIdentifiers[i] = policy.Policy
 				}
 
+			case OIDExtensionPolicyMappings[3]:
+				// RFC 5280 4.2.1.5: Policy Mappings
+				var mappings []PolicyMapping
+				if rest, err := asn1.Unmarshal(e.Value, &mappings); err != nil {
+					nfe.AddError(fmt.Errorf("x509: failed to parse policy mappings: %v", err))
+					unhandled = true
+				} else if len(rest) != 0 {
+					nfe.AddError(errors.New("x509: trailing data after X.509 policy mappings"))
+					unhandled = true
+				} else {
+					out.PolicyMappings = mappings
+				}
+
+			case OIDExtensionPolicyConstraints[3]:
+				// RFC 5280 4.2.1.11: Policy Constraints
+				constraints := policyConstraints{RequireExplicitPolicy: -1, InhibitPolicyMapping: -1}
+				if rest, err := asn1.Unmarshal(e.Value, &constraints); err != nil {
+					nfe.AddError(fmt.Errorf("x509: failed to parse policy constraints: %v", err))
+					unhandled = true
+				} else if len(rest) != 0 {
+					nfe.AddError(errors.New("x509: trailing data after X.509 policy constraints"))
+					unhandled = true
+				} else if constraints.RequireExplicitPolicy < -1 || constraints.InhibitPolicyMapping < -1 {
+					nfe.AddError(errors.New("x509: negative X.509 policy constraints"))
+					unhandled = true
+				} else {
+					if constraints.RequireExplicitPolicy >= 0 {
+						out.RequireExplicitPolicy = constraints.RequireExplicitPolicy
+						out.RequireExplicitPolicyZero = constraints.RequireExplicitPolicy == 0
+					}
+					if constraints.InhibitPolicyMapping >= 0 {
+						out.InhibitPolicyMapping = constraints.InhibitPolicyMapping
+						out.InhibitPolicyMappingZero = constraints.InhibitPolicyMapping == 0
+					}
+				}
+
+			case OIDExtensionInhibitAnyPolicy[3]:
+				// RFC 5280 4.2.1.14: Inhibit anyPolicy
+				var skipCerts int
+				if rest, err := asn1.Unmarshal(e.Value, &skipCerts); err != nil {
+					nfe.AddError(fmt.Errorf("x509: failed to parse inhibitAnyPolicy: %v", err))
+					unhandled = true
+				} else if len(rest) != 0 {
+					nfe.AddError(errors.New("x509: trailing data after X.509 inhibitAnyPolicy"))
+					unhandled = true
+				} else if skipCerts < 0 {
+					nfe.AddError(errors.New("x509: negative X.509 inhibitAnyPolicy"))
+					unhandled = true
+				} else {
+					out.InhibitAnyPolicy = skipCerts
+					out.InhibitAnyPolicyZero = skipCerts == 0
+				}
+
 			default:
 				// Unknown extensions are recorded if critical.
 				unhandled = true
@@ -2078,6 +2348,10 @@ func parseCertificate(in *certificate) (*Certificate, error) {
 			out.RPKIAddressRanges = parseRPKIAddrBlocks(e.Value, &nfe)
 		} else if e.Id.Equal(OIDExtensionASList) {
 			out.RPKIASNumbers, out.RPKIRoutingDomainIDs = parseRPKIASIdentifiers(e.Value, &nfe)
+		} else if e.Id.Equal(OIDExtensionICAODocumentTypeList) {
+			out.ICAODocumentTypes = parseICAODocumentTypeList(e.Value, &nfe)
+		} else if e.Id.Equal(OIDExtensionICAONameChange) {
+			out.ICAONameChange = parseICAONameChange(e.Value, &nfe)
 		} else if e.Id.Equal(OIDExtensionCTSCT) {
 			if rest, err := asn1.Unmarshal(e.Value, &out.RawSCT); err != nil {
 				nfe.AddError(fmt.Errorf("failed to asn1.Unmarshal SCT list extension: %v", err))
@@ -2108,66 +2382,60 @@ func parseCertificate(in *certificate) (*Certificate, error) {
 // ParseTBSCertificate parses a single TBSCertificate from the given ASN.1 DER data.
 // The parsed data is returned in a Certificate struct for ease of access.
 func ParseTBSCertificate(asn1Data []byte) (*Certificate, error) {
-	var tbsCert tbsCertificate
 	var nfe NonFatalErrors
-	rest, err := asn1.Unmarshal(asn1Data, &tbsCert)
+	ret, err := parseTBSCertificate(asn1Data, &nfe)
 	if err != nil {
-		var laxErr error
-		rest, laxErr = asn1.UnmarshalWithParams(asn1Data, &tbsCert, "lax")
-		if laxErr != nil {
-			return nil, laxErr
-		}
-		nfe.AddError(err)
+		return nil, err
 	}
-	if len(rest) > 0 {
-		return nil, asn1.SyntaxError{Msg: "trailing data"}
+	if err := legacyNonFatal(&nfe); IsFatal(err) {
+		return nil, err
+	} else if err != nil {
+		return ret, err
 	}
-	ret, err := parseCertificate(&certificate{
-		Raw:            tbsCert.Raw,
-		TBSCertificate: tbsCert})
+	return ret, nil
+}
+
+func parseTBSCertificate(asn1Data []byte, nfe *NonFatalErrors) (*Certificate, error) {
+	var tbsCert tbsCertificate
+	rest, err := unmarshalLax(asn1Data, &tbsCert, nfe)
 	if err != nil {
-		errs, ok := err.(NonFatalErrors)
-		if !ok {
-			return nil, err
-		}
-		nfe.Errors = append(nfe.Errors, errs.Errors...)
+		return nil, err
 	}
-	if nfe.HasError() {
-		return ret, nfe
+	if len(rest) > 0 {
+		return nil, asn1.SyntaxError{Msg: "trailing data"}
 	}
-	return ret, nil
+	return parseCertificateInto(&certificate{
+		Raw:            tbsCert.Raw,
+		TBSCertificate: tbsCert}, nfe)
 }
 
 // ParseCertificate parses a single certificate from the given ASN.1 DER data.
 // This function can return both a Certificate and an error (in which case the
 // error will be of type NonFatalErrors).
 func ParseCertificate(asn1Data []byte) (*Certificate, error) {
-	var cert certificate
 	var nfe NonFatalErrors
-	rest, err := asn1.Unmarshal(asn1Data, &cert)
+	ret, err := parseCertificateDER(asn1Data, &nfe)
 	if err != nil {
-		var laxErr error
-		rest, laxErr = asn1.UnmarshalWithParams(asn1Data, &cert, "lax")
-		if laxErr != nil {
-			return nil, laxErr
-		}
-		nfe.AddError(err)
+		return nil, err
 	}
-	if len(rest) > 0 {
-		return nil, asn1.SyntaxError{Msg: "trailing data"}
+	if err := legacyNonFatal(&nfe); IsFatal(err) {
+		return nil, err
+	} else if err != nil {
+		return ret, err
 	}
-	ret, err := parseCertificate(&cert)
+	return ret, nil
+}
+
+func parseCertificateDER(asn1Data []byte, nfe *NonFatalErrors) (*Certificate, error) {
+	var cert certificate
+	rest, err := unmarshalLax(asn1Data, &cert, nfe)
 	if err != nil {
-		errs, ok := err.(NonFatalErrors)
-		if !ok {
-			return nil, err
-		}
-		nfe.Errors = append(nfe.Errors, errs.Errors...)
+		return nil, err
 	}
-	if nfe.HasError() {
-		return ret, nfe
+	if len(rest) > 0 {
+		return nil, asn1.SyntaxError{Msg: "trailing data"}
 	}
-	return ret, nil
+	return parseCertificateInto(&cert, nfe)
 }
 
 // ParseCertificates parses one or more certificates from the given ASN.1 DER
@@ -2175,39 +2443,51 @@ func ParseCertificate(asn1Data []byte) (*Certificate, error) {
 // This function can return both a slice of Certificate and an error (in which
 // case the error will be of type NonFatalErrors).
 func ParseCertificates(asn1Data []byte) ([]*Certificate, error) {
-	var v []*certificate
 	var nfe NonFatalErrors
+	ret, err := parseCertificatesDER(asn1Data, &nfe)
+	if err != nil {
+		return nil, err
+	}
+	if err := legacyNonFatal(&nfe); IsFatal(err) {
+		return nil, err
+	} else if err != nil {
+		return ret, err
+	}
+	return ret, nil
+}
 
+func parseCertificatesDER(asn1Data []byte, nfe *NonFatalErrors) ([]*Certificate, error) {
+	var v []*certificate
 	for len(asn1Data) > 0 {
 		cert := new(certificate)
 		var err error
-		asn1Data, err = asn1.Unmarshal(asn1Data, cert)
+		asn1Data, err = unmarshalLax(asn1Data, cert, nfe)
 		if err != nil {
-			var laxErr error
-			asn1Data, laxErr = asn1.UnmarshalWithParams(asn1Data, &cert, "lax")
-			if laxErr != nil {
-				return nil, laxErr
-			}
-			nfe.AddError(err)
+			return nil, err
 		}
 		v = append(v, cert)
 	}
 
 	ret := make([]*Certificate, len(v))
 	for i, ci := range v {
-		cert, err := parseCertificate(ci)
+		cert, err := parseCertificateInto(ci, nfe)
 		if err != nil {
-			errs, ok := err.(NonFatalErrors)
-			if !ok {
-				return nil, err
-			}
-			nfe.Errors = append(nfe.Errors, errs.Errors...)
+			return nil, err
 		}
 		ret[i] = cert
 	}
+	return ret, nil
+}
 
-	if nfe.HasError() {
-		return ret, nfe
+// parseCertificateInto parses in, adding the non-fatal errors met to nfe.
+func parseCertificateInto(in *certificate, nfe *NonFatalErrors) (*Certificate, error) {
+	ret, err := parseCertificate(in)
+	if err != nil {
+		errs, ok := err.(NonFatalErrors)
+		if !ok {
+			return nil, err
+		}
+		nfe.Errors = append(nfe.Errors, errs.Errors...)
 	}
 	return ret, nil
 }
@@ -2265,6 +2545,8 @@ var (
 	OIDExtensionCTPoison = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 3}
 	// OIDExtensionCTSCT is defined in RFC 6962 s3.3.
 	OIDExtensionCTSCT = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+	// OIDExtensionCTOCSPSCT is defined in RFC 6962 s3.3, for OCSP responses.
+	OIDExtensionCTOCSPSCT = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 5}
 	// OIDExtensionIPPrefixList is defined in RFC 3779 s2.
 	OIDExtensionIPPrefixList = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 7}
 	// OIDExtensionASList is defined in RFC 3779 s3.
@@ -2667,8 +2949,12 @@ func signingParamsForPublicKey(pub interface{}, requestedSigAlgo SignatureAlgori
 		pubType = Ed25519
 		sigAlgo.Algorithm = oidSignatureEd25519
 
+	case ed448.PublicKey:
+		pubType = Ed448
+		sigAlgo.Algorithm = oidSignatureEd448
+
 	default:
-		err = errors.New("x509: only RSA, ECDSA and Ed25519 keys supported")
+		err = errors.New("x509: only RSA, ECDSA, Ed25519 and Ed448 keys supported")
 	}
 
 	if err != nil {
@@ -2687,7 +2973,7 @@ func signingParamsForPublicKey(pub interface{}, requestedSigAlgo SignatureAlgori
 				return
 			}
 			sigAlgo.Algorithm, hashFunc = details.oid, details.hash
-			if hashFunc == 0 && pubType != Ed25519 {
+			if hashFunc == 0 && pubType != Ed25519 && pubType != Ed448 {
 				err = errors.New("x509: cannot sign with hash function requested")
 				return
 			}
@@ -2738,9 +3024,10 @@ var emptyASN1Subject = []byte{0x30, 0}
 //
 // The returned slice is the certificate in DER encoding.
 //
-// The currently supported key types are *rsa.PublicKey, *ecdsa.PublicKey and
-// ed25519.PublicKey. pub must be a supported key type, and priv must be a
-// crypto.Signer with a supported public key.
+// The currently supported key types are *rsa.PublicKey, *ecdsa.PublicKey,
+// ed25519.PublicKey and ed448.PublicKey, and for pub only, *ecdh.PublicKey
+// (for X25519) and X448PublicKey. pub must be a supported key type, and priv
+// must be a crypto.Signer with a supported public key.
 //
 // The AuthorityKeyId will be taken from the SubjectKeyId of parent, if any,
 // unless the resulting certificate is self-signed. Otherwise the value from
@@ -3073,9 +3360,9 @@ func parseCSRExtensions(rawAttributes []asn1.RawValue) ([]pkix.Extension, error)
 //
 // priv is the private key to sign the CSR with, and the corresponding public
 // key will be included in the CSR. It must implement crypto.Signer and its
-// Public() method must return a *rsa.PublicKey or a *ecdsa.PublicKey or a
-// ed25519.PublicKey. (A *rsa.PrivateKey, *ecdsa.PrivateKey or
-// ed25519.PrivateKey satisfies this.)
+// Public() method must return a *rsa.PublicKey, a *ecdsa.PublicKey, an
+// ed25519.PublicKey or an ed448.PublicKey. (A *rsa.PrivateKey,
+// *ecdsa.PrivateKey, ed25519.PrivateKey or ed448.PrivateKey satisfies this.)
 //
 // The returned slice is the certificate request in DER encoding.
 func CreateCertificateRequest(rand io.Reader, template *CertificateRequest, priv interface{}) (csr []byte, err error) {
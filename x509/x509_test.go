@@ -7,6 +7,7 @@ package x509
 import (
 	"bytes"
 	"crypto/dsa"
+	"crypto/ecdh"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -30,6 +31,7 @@ import (
 	"time"
 
 	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/ed448"
 	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
 	"golang.org/x/crypto/ed25519"
 )
@@ -112,6 +114,51 @@ func TestParsePKIXPublicKeyEd25519(t *testing.T) {
 	}
 }
 
+func TestMarshalPKIXPublicKeyRFC8410(t *testing.T) {
+	ed448Pub, _, err := ed448.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x25519Priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x448Pub := make(X448PublicKey, 56)
+	if _, err := rand.Read(x448Pub); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name string
+		pub  interface{}
+		oid  asn1.ObjectIdentifier
+	}{
+		{"Ed448", ed448Pub, OIDPublicKeyEd448},
+		{"X25519", x25519Priv.PublicKey(), OIDPublicKeyX25519},
+		{"X448", x448Pub, OIDPublicKeyX448},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			der, err := MarshalPKIXPublicKey(test.pub)
+			if err != nil {
+				t.Fatalf("MarshalPKIXPublicKey()=nil,%v; want _,nil", err)
+			}
+			var pki publicKeyInfo
+			if _, err := asn1.Unmarshal(der, &pki); err != nil {
+				t.Fatal(err)
+			}
+			if !pki.Algorithm.Algorithm.Equal(test.oid) || len(pki.Algorithm.Parameters.FullBytes) != 0 {
+				t.Errorf("algorithm=%v, parameters %x; want %v without parameters", pki.Algorithm.Algorithm, pki.Algorithm.Parameters.FullBytes, test.oid)
+			}
+			pub, err := ParsePKIXPublicKey(der)
+			if err != nil {
+				t.Fatalf("ParsePKIXPublicKey()=nil,%v; want _,nil", err)
+			}
+			if !reflect.DeepEqual(pub, test.pub) {
+				t.Errorf("ParsePKIXPublicKey()=%v; want %v", pub, test.pub)
+			}
+		})
+	}
+}
+
 // From RFC 8410 section 10.1.
 var pemPublicKeyEd25519 = `-----BEGIN PUBLIC KEY-----
 MCowBQYDK2VwAyEAGb9ECWmEzf6FQbrBZ9w7lshQhqowtrbLDFw4rXAxZuE=
@@ -675,6 +722,16 @@ func TestCreateSelfSignedCertificate(t *testing.T) {
 		t.Fatalf("Failed to generate Ed25519 key: %s", err)
 	}
 
+	ed448Pub, ed448Priv, err := ed448.GenerateKey(random)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed448 key: %s", err)
+	}
+
+	x25519Priv, err := ecdh.X25519().GenerateKey(random)
+	if err != nil {
+		t.Fatalf("Failed to generate X25519 key: %s", err)
+	}
+
 	tests := []struct {
 		name      string
 		pub, priv interface{}
@@ -689,6 +746,9 @@ func TestCreateSelfSignedCertificate(t *testing.T) {
 		{"ECDSA/RSAPSS", &ecdsaPriv.PublicKey, testPrivateKey, false, SHA256WithRSAPSS},
 		{"RSAPSS/ECDSA", &testPrivateKey.PublicKey, ecdsaPriv, false, ECDSAWithSHA384},
 		{"Ed25519", ed25519Pub, ed25519Priv, true, PureEd25519},
+		{"Ed448", ed448Pub, ed448Priv, true, PureEd448},
+		{"X25519/ECDSA", x25519Priv.PublicKey(), ecdsaPriv, false, ECDSAWithSHA256},
+		{"X448/Ed448", X448PublicKey(make([]byte, 56)), ed448Priv, false, PureEd448},
 	}
 
 	testExtKeyUsage := []ExtKeyUsage{ExtKeyUsageClientAuth, ExtKeyUsageServerAuth}
@@ -1938,6 +1998,101 @@ func TestBuildPrecertTBS(t *testing.T) {
 	}
 }
 
+// nonCanonicalExtension is a pkix.Extension whose criticality is always
+// encoded, even if FALSE, as some CAs do.
+type nonCanonicalExtension struct {
+	Id       asn1.ObjectIdentifier
+	Critical bool
+	Value    []byte
+}
+
+// generalizedValidity encodes dates as GeneralizedTime, even before 2050, as
+// some CAs do.
+type generalizedValidity struct {
+	NotBefore, NotAfter time.Time `asn1:"generalized"`
+}
+
+type nonCanonicalTBS struct {
+	Version            int `asn1:"optional,explicit,default:0,tag:0"`
+	SerialNumber       *big.Int
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Issuer             asn1.RawValue
+	Validity           generalizedValidity
+	Subject            asn1.RawValue
+	PublicKey          asn1.RawValue
+	Extensions         []asn1.RawValue `asn1:"optional,explicit,tag:3"`
+}
+
+func TestBuildPrecertTBSPreservesEncoding(t *testing.T) {
+	template := Certificate{
+		SerialNumber: big.NewInt(123),
+		Subject:      pkix.Name{CommonName: "precert subject"},
+		NotBefore:    time.Unix(1700000000, 0),
+		NotAfter:     time.Unix(1800000000, 0),
+	}
+	cert := makeCert(t, &template, &template)
+
+	ext := func(id asn1.ObjectIdentifier, critical bool, value []byte) asn1.RawValue {
+		t.Helper()
+		data, err := asn1.Marshal(nonCanonicalExtension{Id: id, Critical: critical, Value: value})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return asn1.RawValue{FullBytes: data}
+	}
+	poison := ext(OIDExtensionCTPoison, true, asn1.NullBytes)
+	aki := ext(OIDExtensionAuthorityKeyId, false, []byte{0x30, 0x06, 0x80, 0x04, 1, 2, 3, 4})
+	san := ext(OIDExtensionSubjectAltName, false, []byte{0x30, 0x0d, 0x82, 0x0b, 'e', 'x', 'a', 'm', 'p', 'l', 'e', '.', 'c', 'o', 'm'})
+	tbs := func(exts ...asn1.RawValue) []byte {
+		t.Helper()
+		data, err := asn1.Marshal(nonCanonicalTBS{
+			Version:            2,
+			SerialNumber:       cert.SerialNumber,
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSignatureSHA256WithRSA, Parameters: asn1.NullRawValue},
+			Issuer:             asn1.RawValue{FullBytes: cert.RawIssuer},
+			Validity:           generalizedValidity{NotBefore: template.NotBefore.UTC(), NotAfter: template.NotAfter.UTC()},
+			Subject:            asn1.RawValue{FullBytes: cert.RawSubject},
+			PublicKey:          asn1.RawValue{FullBytes: cert.RawSubjectPublicKeyInfo},
+			Extensions:         exts,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	for _, test := range []struct {
+		name string
+		in   []byte
+		want []byte
+	}{
+		{name: "poison-first", in: tbs(poison, aki, san), want: tbs(aki, san)},
+		{name: "poison-middle", in: tbs(aki, poison, san), want: tbs(aki, san)},
+		{name: "poison-last", in: tbs(aki, san, poison), want: tbs(aki, san)},
+		{name: "poison-only", in: tbs(poison), want: tbs()},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := BuildPrecertTBS(test.in, nil)
+			if err != nil {
+				t.Fatalf("BuildPrecertTBS()=nil,%v", err)
+			}
+			if !bytes.Equal(got, test.want) {
+				t.Errorf("BuildPrecertTBS()=%x, want %x", got, test.want)
+			}
+		})
+	}
+
+	// The removal of an SCT list preserves the encoding just the same.
+	sctList := ext(OIDExtensionCTSCT, false, []byte{0x04, 0x02, 0x00, 0x00})
+	got, err := RemoveSCTList(tbs(aki, sctList, san))
+	if err != nil {
+		t.Fatalf("RemoveSCTList()=nil,%v", err)
+	}
+	if want := tbs(aki, san); !bytes.Equal(got, want) {
+		t.Errorf("RemoveSCTList()=%x, want %x", got, want)
+	}
+}
+
 func TestImports(t *testing.T) {
 	t.Skip("Import test skipped for forked codebase")
 	if testing.Short() {
@@ -0,0 +1,214 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x509util
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+)
+
+const (
+	// DefaultMaxAIAFetches is the default limit on the number of URLs
+	// retrieved by AIAFetcher.FetchIntermediates.
+	DefaultMaxAIAFetches = 10
+	// maxAIAResponseSize limits the size of the issuer certificates fetched.
+	maxAIAResponseSize = 1 << 20
+)
+
+// AIAFetcher retrieves issuer certificates from the caIssuers URLs of the
+// Authority Information Access extension of certificates ("AIA chasing").
+// Responses are cached by URL, so an AIAFetcher can be reused across chains.
+// It is safe for concurrent use.
+type AIAFetcher struct {
+	// Client is used for the requests; if nil, http.DefaultClient is used.
+	Client *http.Client
+	// MaxFetches limits the number of URLs retrieved by a call to
+	// FetchIntermediates. If zero, DefaultMaxAIAFetches is used.
+	MaxFetches int
+
+	mu    sync.Mutex
+	cache map[string][]*x509.Certificate
+}
+
+// Fetch returns the certificates served at url, which may be a DER
+// certificate, PEM certificates, or a certs-only PKCS#7 (.p7c) bundle.
+func (f *AIAFetcher) Fetch(ctx context.Context, url string) ([]*x509.Certificate, error) {
+	f.mu.Lock()
+	certs, ok := f.cache[url]
+	f.mu.Unlock()
+	if ok {
+		return certs, nil
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request for %q: %v", url, err)
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issuer from %q: %v", url, err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get issuer from %q: HTTP status %q", url, rsp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(rsp.Body, maxAIAResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issuer from %q: %v", url, err)
+	}
+	if len(body) > maxAIAResponseSize {
+		return nil, fmt.Errorf("issuer data from %q is larger than %d bytes", url, maxAIAResponseSize)
+	}
+	certs, err = parseIssuerCerts(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse issuer from %q: %v", url, err)
+	}
+
+	f.mu.Lock()
+	if f.cache == nil {
+		f.cache = make(map[string][]*x509.Certificate)
+	}
+	f.cache[url] = certs
+	f.mu.Unlock()
+	return certs, nil
+}
+
+// FetchIntermediates follows the AIA caIssuers URLs of the certificates of
+// chain, and of the certificates found there in turn, and returns the
+// certificates found that are not already in chain. Issuers of an
+// intermediate which is cross-signed are all returned, so that the
+// alternative paths through it can be explored. Fetching stops at
+// self-signed certificates, or when MaxFetches URLs have been retrieved.
+//
+// Certificates may be returned along with an error which combines the
+// failures of the individual fetches.
+func (f *AIAFetcher) FetchIntermediates(ctx context.Context, chain []*x509.Certificate) ([]*x509.Certificate, error) {
+	maxFetches := f.MaxFetches
+	if maxFetches <= 0 {
+		maxFetches = DefaultMaxAIAFetches
+	}
+
+	seen := make(map[string]bool)
+	for _, cert := range chain {
+		seen[string(cert.Raw)] = true
+	}
+	fetched := make(map[string]bool)
+	queue := append([]*x509.Certificate(nil), chain...)
+	var found []*x509.Certificate
+	var errs []error
+	for len(queue) > 0 && len(fetched) < maxFetches {
+		cert := queue[0]
+		queue = queue[1:]
+		if bytes.Equal(cert.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(cert) == nil {
+			continue
+		}
+		for _, url := range cert.IssuingCertificateURL {
+			if fetched[url] || len(fetched) >= maxFetches {
+				continue
+			}
+			fetched[url] = true
+			issuers, err := f.Fetch(ctx, url)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for _, issuer := range issuers {
+				if seen[string(issuer.Raw)] {
+					continue
+				}
+				seen[string(issuer.Raw)] = true
+				found = append(found, issuer)
+				queue = append(queue, issuer)
+			}
+		}
+	}
+	return found, errors.Join(errs...)
+}
+
+// parseIssuerCerts parses the certificates of an AIA caIssuers response.
+func parseIssuerCerts(data []byte) ([]*x509.Certificate, error) {
+	if bytes.Contains(data, []byte("-----BEGIN ")) {
+		var certs []*x509.Certificate
+		for _, der := range dePEM(data, "CERTIFICATE") {
+			cert, err := x509.ParseCertificate(der)
+			if x509.IsFatal(err) {
+				return nil, err
+			}
+			certs = append(certs, cert)
+		}
+		if len(certs) == 0 {
+			return nil, errors.New("no PEM certificates found")
+		}
+		return certs, nil
+	}
+	if certs, err := parsePKCS7Certs(data); err == nil {
+		return certs, nil
+	}
+	certs, err := x509.ParseCertificates(data)
+	if x509.IsFatal(err) {
+		return nil, err
+	}
+	return certs, nil
+}
+
+var oidPKCS7SignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+
+// parsePKCS7Certs parses the certificates of a PKCS#7 SignedData structure
+// (RFC 2315 s9.1), as used for certs-only bundles.
+func parsePKCS7Certs(data []byte) ([]*x509.Certificate, error) {
+	var contentInfo struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue `asn1:"explicit,tag:0"`
+	}
+	if rest, err := asn1.Unmarshal(data, &contentInfo); err != nil {
+		return nil, err
+	} else if len(rest) != 0 {
+		return nil, errors.New("trailing data after PKCS#7 ContentInfo")
+	}
+	if !contentInfo.ContentType.Equal(oidPKCS7SignedData) {
+		return nil, fmt.Errorf("PKCS#7 content type %v is not SignedData", contentInfo.ContentType)
+	}
+	var signedData struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      asn1.RawValue
+		Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+		CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+		SignerInfos      asn1.RawValue
+	}
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
+		return nil, err
+	}
+	certs, err := x509.ParseCertificates(signedData.Certificates.Bytes)
+	if x509.IsFatal(err) {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates in PKCS#7 SignedData")
+	}
+	return certs, nil
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x509util_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+)
+
+type aiaServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	files    map[string][]byte
+	requests int
+}
+
+func newAIAServer() *aiaServer {
+	s := &aiaServer{files: make(map[string][]byte)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests++
+		data, ok := s.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	return s
+}
+
+func (s *aiaServer) requestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *aiaServer) serve(path string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[path] = data
+}
+
+// pkcs7Certs returns a certs-only PKCS#7 SignedData holding ders.
+func pkcs7Certs(t *testing.T, ders ...[]byte) []byte {
+	t.Helper()
+	var certs []byte
+	for _, der := range ders {
+		certs = append(certs, der...)
+	}
+	emptySet := asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true}
+	signedData, err := asn1.Marshal(struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      struct{ ContentType asn1.ObjectIdentifier }
+		Certificates     asn1.RawValue
+		SignerInfos      asn1.RawValue
+	}{
+		Version:          1,
+		DigestAlgorithms: emptySet,
+		ContentInfo:      struct{ ContentType asn1.ObjectIdentifier }{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certs},
+		SignerInfos:      emptySet,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := asn1.Marshal(struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{
+		ContentType: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2},
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedData},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+type aiaCA struct {
+	cert *x509.Certificate
+	priv *ecdsa.PrivateKey
+}
+
+func newAIACert(t *testing.T, cn string, serial int64, issuer *aiaCA, priv *ecdsa.PrivateKey, aia string) *aiaCA {
+	t.Helper()
+	if priv == nil {
+		var err error
+		if priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			t.Fatal(err)
+		}
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	if aia != "" {
+		template.IssuingCertificateURL = []string{aia}
+	}
+	parent, signer := template, priv
+	if issuer != nil {
+		parent, signer = issuer.cert, issuer.priv
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &priv.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &aiaCA{cert: cert, priv: priv}
+}
+
+func TestAIAFetcher(t *testing.T) {
+	srv := newAIAServer()
+	defer srv.Close()
+
+	// The intermediate is cross-signed by two roots, both of which are
+	// served as issuers of the leaf's issuer.
+	root1 := newAIACert(t, "Root 1", 1, nil, nil, "")
+	root2 := newAIACert(t, "Root 2", 2, nil, nil, "")
+	int1 := newAIACert(t, "Intermediate", 3, root1, nil, srv.URL+"/root1.pem")
+	int2 := newAIACert(t, "Intermediate", 4, root2, int1.priv, srv.URL+"/root2.der")
+	leaf := newAIACert(t, "Leaf", 5, int1, nil, srv.URL+"/int.p7c")
+	leaf.cert.IssuingCertificateURL = append(leaf.cert.IssuingCertificateURL, srv.URL+"/missing")
+
+	srv.serve("/int.p7c", pkcs7Certs(t, int1.cert.Raw, int2.cert.Raw))
+	srv.serve("/root1.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root1.cert.Raw}))
+	srv.serve("/root2.der", root2.cert.Raw)
+
+	ctx := context.Background()
+	fetcher := &x509util.AIAFetcher{}
+	found, err := fetcher.FetchIntermediates(ctx, []*x509.Certificate{leaf.cert})
+	if err == nil {
+		t.Error("FetchIntermediates()=_,nil; want error for missing URL")
+	}
+	var got []string
+	for _, cert := range found {
+		got = append(got, cert.Subject.CommonName+"/"+cert.SerialNumber.String())
+	}
+	sort.Strings(got)
+	want := []string{"Intermediate/3", "Intermediate/4", "Root 1/1", "Root 2/2"}
+	if len(got) != len(want) {
+		t.Fatalf("FetchIntermediates() found %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("FetchIntermediates() found %v; want %v", got, want)
+		}
+	}
+
+	// Both paths through the cross-signed intermediate lead to a root.
+	opts := x509.VerifyOptions{Roots: x509.NewCertPool(), Intermediates: x509.NewCertPool()}
+	opts.Roots.AddCert(root1.cert)
+	opts.Roots.AddCert(root2.cert)
+	for _, cert := range found {
+		opts.Intermediates.AddCert(cert)
+	}
+	chains, err := leaf.cert.Verify(opts)
+	if err != nil {
+		t.Fatalf("Verify()=_,%v; want _,nil", err)
+	}
+	if len(chains) != 2 {
+		t.Errorf("Verify() found %d chains; want 2", len(chains))
+	}
+
+	// Responses are cached.
+	requests := srv.requestCount()
+	if _, err := fetcher.FetchIntermediates(ctx, []*x509.Certificate{leaf.cert}); err == nil {
+		t.Error("FetchIntermediates()=_,nil; want error for missing URL")
+	}
+	if got := srv.requestCount(); got != requests+1 {
+		t.Errorf("FetchIntermediates() made %d requests; want only 1 for the uncached URL", got-requests)
+	}
+
+	// The number of fetches is bounded.
+	limited := &x509util.AIAFetcher{MaxFetches: 1}
+	found, err = limited.FetchIntermediates(ctx, []*x509.Certificate{leaf.cert})
+	if err != nil || len(found) != 2 {
+		t.Errorf("FetchIntermediates(MaxFetches=1)=%d certs,%v; want 2 certs,nil", len(found), err)
+	}
+}
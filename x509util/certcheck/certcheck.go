@@ -17,12 +17,15 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"flag"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/RarimoVoting/certificate-transparency-go/x509"
 	"github.com/RarimoVoting/certificate-transparency-go/x509util"
@@ -41,8 +44,13 @@ var (
 	checkEKU                 = flag.Bool("check_eku", true, "Check EKU nesting validity")
 	checkPathLen             = flag.Bool("check_path_len", true, "Check path len constraint validity")
 	checkNameConstraint      = flag.Bool("check_name_constraint", true, "Check name constraints")
+	checkPolicies            = flag.Bool("check_policies", true, "Check certificate policies")
 	checkUnknownCriticalExts = flag.Bool("check_unknown_critical_exts", true, "Check for unknown critical extensions")
-	checkRevoked             = flag.Bool("check_revocation", false, "Check revocation status of certificate")
+	checkRevoked             = flag.Bool("check_revocation", false, "Check revocation status of each certificate of the chain against its CRLs and OCSP responders")
+	fetchIntermediates       = flag.Bool("fetch_intermediates", true, "Fetch missing intermediates from the AIA caIssuers URLs of the chain when validating")
+	maxAIAFetches            = flag.Int("max_aia_fetches", x509util.DefaultMaxAIAFetches, "Maximum number of AIA URLs to fetch per chain")
+	httpTimeout              = flag.Duration("http_timeout", 10*time.Second, "Timeout for HTTP requests")
+	lintProfile              = flag.String("lint_profile", "", "Lint certificates against the named x509util profile (basic, webpki); lint errors set a non-zero exit code")
 )
 
 func addCerts(filename string, pool *x509.CertPool) {
@@ -67,6 +75,21 @@ func main() {
 	klog.InitFlags(nil)
 	flag.Parse()
 
+	var profile *x509util.LintProfile
+	if *lintProfile != "" {
+		var err error
+		if profile, err = x509util.LintProfileByName(*lintProfile); err != nil {
+			klog.Exitf("Invalid --lint_profile: %v", err)
+		}
+	}
+
+	var fetcher *x509util.AIAFetcher
+	if *fetchIntermediates {
+		fetcher = &x509util.AIAFetcher{Client: &http.Client{Timeout: *httpTimeout}, MaxFetches: *maxAIAFetches}
+	}
+
+	revocationChecker := &x509util.RevocationChecker{Timeout: *httpTimeout}
+
 	failed := false
 	for _, target := range flag.Args() {
 		var err error
@@ -85,13 +108,21 @@ func main() {
 		} else if err != nil && *strict {
 			failed = true
 		}
-		for _, cert := range chain {
+		for i, cert := range chain {
 			if *verbose {
 				fmt.Print(x509util.CertificateToString(cert))
 			}
-			if *checkRevoked {
-				if err := checkRevocation(cert, *verbose); err != nil {
-					klog.Errorf("%s: certificate is revoked: %v", target, err)
+			if *checkRevoked && !isSelfSigned(cert) {
+				if checkRevocation(revocationChecker, target, i, cert, issuerInChain(chain, cert), *verbose) {
+					failed = true
+				}
+			}
+			if profile != nil {
+				findings := x509util.Lint(cert, profile)
+				for _, f := range findings {
+					fmt.Printf("%s: lint %v\n", target, f)
+				}
+				if sev, ok := x509util.LintMaxSeverity(findings); ok && sev >= x509util.LintError {
 					failed = true
 				}
 			}
@@ -104,11 +135,16 @@ func main() {
 				DisableEKUChecks:               !*checkEKU,
 				DisablePathLenChecks:           !*checkPathLen,
 				DisableNameConstraintChecks:    !*checkNameConstraint,
+				DisablePolicyChecks:            !*checkPolicies,
 			}
-			if err := validateChain(chain, opts, *root, *intermediate, *useSystemRoots); err != nil {
+			chains, err := validateChain(chain, opts, *root, *intermediate, *useSystemRoots, fetcher)
+			if err != nil {
 				klog.Errorf("%s: verification error: %v", target, err)
 				failed = true
 			}
+			for i, c := range chains {
+				fmt.Printf("%s: chain %d: %s\n", target, i+1, chainToString(c))
+			}
 		}
 	}
 	if failed {
@@ -188,7 +224,10 @@ func chainFromFile(filename string) ([]*x509.Certificate, error) {
 	return chain, nil
 }
 
-func validateChain(chain []*x509.Certificate, opts x509.VerifyOptions, rootsFile, intermediatesFile string, useSystemRoots bool) error {
+// validateChain verifies chain, and returns all the chains it finds from its
+// leaf to a trusted root. If fetcher is not nil, it is used to retrieve the
+// intermediates missing from chain, and any cross-signed alternatives.
+func validateChain(chain []*x509.Certificate, opts x509.VerifyOptions, rootsFile, intermediatesFile string, useSystemRoots bool, fetcher *x509util.AIAFetcher) ([][]*x509.Certificate, error) {
 	roots := x509.NewCertPool()
 	if useSystemRoots {
 		systemRoots, err := x509.SystemCertPool()
@@ -218,36 +257,57 @@ func validateChain(chain []*x509.Certificate, opts x509.VerifyOptions, rootsFile
 			opts.Intermediates.AddCert(chain[i])
 		}
 	}
-	_, err := chain[0].Verify(opts)
-	return err
-}
-
-func checkRevocation(cert *x509.Certificate, verbose bool) error {
-	for _, crldp := range cert.CRLDistributionPoints {
-		crlDataList, err := x509util.ReadPossiblePEMURL(crldp, "X509 CRL")
+	if fetcher != nil {
+		fetched, err := fetcher.FetchIntermediates(context.Background(), chain)
 		if err != nil {
-			klog.Errorf("failed to retrieve CRL from %q: %v", crldp, err)
-			continue
+			klog.Warningf("Failed to fetch some intermediates: %v", err)
 		}
-		for _, crlData := range crlDataList {
-			crl, err := x509.ParseCertificateList(crlData)
-			if x509.IsFatal(err) {
-				klog.Errorf("failed to parse CRL from %q: %v", crldp, err)
-				continue
-			}
-			if err != nil {
-				klog.Errorf("non-fatal error parsing CRL from %q: %v", crldp, err)
-			}
-			if verbose {
-				fmt.Printf("\nRevocation data from %s:\n", crldp)
-				fmt.Print(x509util.CRLToString(crl))
-			}
-			for _, c := range crl.TBSCertList.RevokedCertificates {
-				if c.SerialNumber.Cmp(cert.SerialNumber) == 0 {
-					return fmt.Errorf("certificate is revoked since %v", c.RevocationTime)
-				}
-			}
+		for _, cert := range fetched {
+			klog.V(1).Infof("Fetched intermediate %s", x509util.NameToString(cert.Subject))
+			opts.Intermediates.AddCert(cert)
+		}
+	}
+	return chain[0].Verify(opts)
+}
+
+// chainToString describes a chain by the subjects of its certificates.
+func chainToString(chain []*x509.Certificate) string {
+	names := make([]string, len(chain))
+	for i, cert := range chain {
+		names[i] = x509util.NameToString(cert.Subject)
+	}
+	return strings.Join(names, " -> ")
+}
+
+// issuerInChain returns the certificate of chain which issued cert, or nil.
+func issuerInChain(chain []*x509.Certificate, cert *x509.Certificate) *x509.Certificate {
+	for _, c := range chain {
+		if c != cert && bytes.Equal(c.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(c) == nil {
+			return c
 		}
 	}
 	return nil
 }
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(cert) == nil
+}
+
+// checkRevocation reports the revocation status of cert, the certificate at
+// position index in the chain of target, and returns whether it is revoked.
+func checkRevocation(rc *x509util.RevocationChecker, target string, index int, cert, issuer *x509.Certificate, verbose bool) bool {
+	results := rc.Check(context.Background(), cert, issuer)
+	status := x509util.CombinedRevocationStatus(results)
+	fmt.Printf("%s: certificate %d revocation status: %s\n", target, index, status)
+	for _, r := range results {
+		fmt.Printf("%s: certificate %d:   %v\n", target, index, r)
+		if verbose && r.CRL != nil {
+			fmt.Printf("\nRevocation data from %s:\n", r.URL)
+			fmt.Print(x509util.CRLToString(r.CRL))
+		}
+	}
+	if len(results) == 0 {
+		klog.Warningf("%s: certificate %d has no CRL distribution points or OCSP responders", target, index)
+	}
+	return status == x509util.RevocationRevoked
+}
@@ -0,0 +1,341 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x509util
+
+import (
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+)
+
+// LintSeverity is the severity of a LintFinding.
+type LintSeverity int
+
+// Severities of lint findings, in increasing order.
+const (
+	LintNotice LintSeverity = iota
+	LintWarning
+	LintError
+)
+
+func (s LintSeverity) String() string {
+	switch s {
+	case LintNotice:
+		return "notice"
+	case LintWarning:
+		return "warning"
+	case LintError:
+		return "error"
+	}
+	return fmt.Sprintf("LintSeverity(%d)", int(s))
+}
+
+// LintFinding is a problem found in a certificate by a lint check.
+type LintFinding struct {
+	// Check is the name of the check which found the problem.
+	Check    string
+	Severity LintSeverity
+	Message  string
+}
+
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.Severity, f.Check, f.Message)
+}
+
+// LintProfile holds the requirements that certificates are linted against.
+// Zero values disable the corresponding requirements.
+type LintProfile struct {
+	// Name identifies the profile.
+	Name string
+	// Checks holds the names of the checks to run; if empty, all the
+	// registered checks are run.
+	Checks []string
+	// MinSerialBits is the minimum bit length of serial numbers, as a proxy
+	// for the entropy they hold.
+	MinSerialBits int
+	// MinRSABits is the minimum size of RSA moduli.
+	MinRSABits int
+	// AllowedCurves, if not empty, holds the elliptic curves allowed for
+	// ECDSA keys.
+	AllowedCurves []elliptic.Curve
+	// RejectDSA makes DSA keys an error.
+	RejectDSA bool
+	// MaxLeafValidity is the maximum validity period of end-entity
+	// certificates.
+	MaxLeafValidity time.Duration
+	// ForbiddenExtensions holds extensions that certificates must not have.
+	ForbiddenExtensions []asn1.ObjectIdentifier
+}
+
+// LintProfileByName returns a new copy of the named built-in profile:
+//   - "basic": structural checks only, with no policy on key sizes or
+//     validity periods.
+//   - "webpki": the main requirements of the CA/Browser Forum Baseline
+//     Requirements on TLS server certificates.
+func LintProfileByName(name string) (*LintProfile, error) {
+	switch name {
+	case "basic":
+		return &LintProfile{Name: name}, nil
+	case "webpki":
+		return &LintProfile{
+			Name:            name,
+			MinSerialBits:   64,
+			MinRSABits:      2048,
+			AllowedCurves:   []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()},
+			RejectDSA:       true,
+			MaxLeafValidity: 398 * 24 * time.Hour,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown lint profile %q", name)
+}
+
+// LintCheck is a named check on certificates.
+type LintCheck struct {
+	Name        string
+	Description string
+	// Run returns the problems found in cert under the given profile.
+	Run func(cert *x509.Certificate, profile *LintProfile) []LintFinding
+}
+
+var (
+	lintMu     sync.RWMutex
+	lintChecks []LintCheck
+)
+
+// RegisterLintCheck adds a check to the registry used by Lint. Check names
+// must be unique.
+func RegisterLintCheck(check LintCheck) error {
+	if check.Name == "" || check.Run == nil {
+		return fmt.Errorf("lint check %q has no name or no Run function", check.Name)
+	}
+	lintMu.Lock()
+	defer lintMu.Unlock()
+	for _, c := range lintChecks {
+		if c.Name == check.Name {
+			return fmt.Errorf("lint check %q already registered", check.Name)
+		}
+	}
+	lintChecks = append(lintChecks, check)
+	return nil
+}
+
+// LintChecks returns the registered checks, in registration order.
+func LintChecks() []LintCheck {
+	lintMu.RLock()
+	defer lintMu.RUnlock()
+	return append([]LintCheck(nil), lintChecks...)
+}
+
+// Lint runs the checks selected by profile over cert, and returns the
+// problems found. A nil profile runs all the checks of the "basic" profile.
+// A check named by the profile but not registered is reported as an error
+// finding.
+func Lint(cert *x509.Certificate, profile *LintProfile) []LintFinding {
+	if profile == nil {
+		profile = &LintProfile{Name: "basic"}
+	}
+	checks := LintChecks()
+	if len(profile.Checks) > 0 {
+		byName := make(map[string]LintCheck)
+		for _, c := range checks {
+			byName[c.Name] = c
+		}
+		checks = nil
+		for _, name := range profile.Checks {
+			c, ok := byName[name]
+			if !ok {
+				c = LintCheck{Name: name, Run: func(*x509.Certificate, *LintProfile) []LintFinding {
+					return []LintFinding{{Check: name, Severity: LintError, Message: "unknown lint check"}}
+				}}
+			}
+			checks = append(checks, c)
+		}
+	}
+
+	var findings []LintFinding
+	for _, c := range checks {
+		for _, f := range c.Run(cert, profile) {
+			if f.Check == "" {
+				f.Check = c.Name
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// LintMaxSeverity returns the highest severity of findings, and false if
+// there are none.
+func LintMaxSeverity(findings []LintFinding) (LintSeverity, bool) {
+	if len(findings) == 0 {
+		return LintNotice, false
+	}
+	max := findings[0].Severity
+	for _, f := range findings[1:] {
+		if f.Severity > max {
+			max = f.Severity
+		}
+	}
+	return max, true
+}
+
+func init() {
+	for _, c := range []LintCheck{
+		{Name: "serial_entropy", Description: "Serial numbers are positive, at most 20 octets and large enough to hold the required entropy", Run: lintSerialEntropy},
+		{Name: "san_cn_consistency", Description: "End-entity certificates have a subjectAltName that includes the subject common name", Run: lintSANCNConsistency},
+		{Name: "key_size", Description: "Public keys are of allowed types and sizes", Run: lintKeySize},
+		{Name: "validity_span", Description: "Validity periods are well-ordered and not too long", Run: lintValiditySpan},
+		{Name: "forbidden_extensions", Description: "Certificates have none of the forbidden extensions", Run: lintForbiddenExtensions},
+	} {
+		if err := RegisterLintCheck(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func lintError(format string, args ...interface{}) LintFinding {
+	return LintFinding{Severity: LintError, Message: fmt.Sprintf(format, args...)}
+}
+
+func lintWarning(format string, args ...interface{}) LintFinding {
+	return LintFinding{Severity: LintWarning, Message: fmt.Sprintf(format, args...)}
+}
+
+func lintSerialEntropy(cert *x509.Certificate, profile *LintProfile) []LintFinding {
+	serial := cert.SerialNumber
+	if serial == nil {
+		return []LintFinding{lintError("no serial number")}
+	}
+	if serial.Sign() <= 0 {
+		return []LintFinding{lintError("serial number %v is not positive", serial)}
+	}
+	var findings []LintFinding
+	// RFC 5280 s4.1.2.2 limits serial numbers to 20 octets, including the
+	// leading zero octet of positive numbers with their top bit set.
+	if octets := serial.BitLen()/8 + 1; octets > 20 {
+		findings = append(findings, lintError("serial number is %d octets long, more than 20", octets))
+	}
+	if profile.MinSerialBits > 0 && serial.BitLen() < profile.MinSerialBits {
+		findings = append(findings, lintError("serial number has %d bits, fewer than %d", serial.BitLen(), profile.MinSerialBits))
+	}
+	return findings
+}
+
+func lintSANCNConsistency(cert *x509.Certificate, _ *LintProfile) []LintFinding {
+	if cert.IsCA {
+		return nil
+	}
+	hasSAN := len(cert.DNSNames)+len(cert.IPAddresses)+len(cert.EmailAddresses)+len(cert.URIs) > 0
+	if !hasSAN {
+		return []LintFinding{lintWarning("no subjectAltName")}
+	}
+	var findings []LintFinding
+	for _, cn := range cert.Subject.Names {
+		if !cn.Type.Equal(oidCommonName) {
+			continue
+		}
+		name, ok := cn.Value.(string)
+		if !ok {
+			findings = append(findings, lintError("common name %v is not a string", cn.Value))
+			continue
+		}
+		if !sanContains(cert, name) {
+			findings = append(findings, lintError("common name %q is not in the subjectAltName", name))
+		}
+	}
+	return findings
+}
+
+var oidCommonName = asn1.ObjectIdentifier{2, 5, 4, 3}
+
+// sanContains reports whether name is one of the DNS names, IP addresses or
+// email addresses of cert; DNS names match case-insensitively.
+func sanContains(cert *x509.Certificate, name string) bool {
+	for _, dns := range cert.DNSNames {
+		if strings.EqualFold(dns, name) {
+			return true
+		}
+	}
+	for _, ip := range cert.IPAddresses {
+		if ip.String() == name {
+			return true
+		}
+	}
+	for _, email := range cert.EmailAddresses {
+		if email == name {
+			return true
+		}
+	}
+	return false
+}
+
+func lintKeySize(cert *x509.Certificate, profile *LintProfile) []LintFinding {
+	switch pub := cert.PublicKey.(type) {
+	case nil:
+		return []LintFinding{lintError("public key not parsed (algorithm %v)", cert.PublicKeyAlgorithm)}
+	case *rsa.PublicKey:
+		if bits := pub.N.BitLen(); profile.MinRSABits > 0 && bits < profile.MinRSABits {
+			return []LintFinding{lintError("RSA key has %d bits, fewer than %d", bits, profile.MinRSABits)}
+		}
+		if pub.E < 3 || pub.E%2 == 0 {
+			return []LintFinding{lintError("RSA public exponent %d is invalid", pub.E)}
+		}
+	case *ecdsa.PublicKey:
+		if len(profile.AllowedCurves) == 0 {
+			return nil
+		}
+		for _, curve := range profile.AllowedCurves {
+			if pub.Curve == curve {
+				return nil
+			}
+		}
+		return []LintFinding{lintError("elliptic curve %s is not allowed", pub.Curve.Params().Name)}
+	case *dsa.PublicKey:
+		if profile.RejectDSA {
+			return []LintFinding{lintError("DSA keys are not allowed")}
+		}
+	}
+	return nil
+}
+
+func lintValiditySpan(cert *x509.Certificate, profile *LintProfile) []LintFinding {
+	if cert.NotAfter.Before(cert.NotBefore) {
+		return []LintFinding{lintError("NotAfter (%v) is before NotBefore (%v)", cert.NotAfter, cert.NotBefore)}
+	}
+	// The validity period includes both its ends, RFC 5280 s4.1.2.5.
+	span := cert.NotAfter.Sub(cert.NotBefore) + time.Second
+	if !cert.IsCA && profile.MaxLeafValidity > 0 && span > profile.MaxLeafValidity {
+		return []LintFinding{lintError("validity period of %v is longer than %v", span, profile.MaxLeafValidity)}
+	}
+	return nil
+}
+
+func lintForbiddenExtensions(cert *x509.Certificate, profile *LintProfile) []LintFinding {
+	var findings []LintFinding
+	for _, oid := range profile.ForbiddenExtensions {
+		if count, _ := OIDInExtensions(oid, cert.Extensions); count > 0 {
+			findings = append(findings, lintError("forbidden extension %v present", oid))
+		}
+	}
+	return findings
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x509util_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+)
+
+var lintOIDForbidden = asn1.ObjectIdentifier{1, 2, 3, 4}
+
+func lintCert(t *testing.T, modify func(*x509.Certificate), pub, priv interface{}) *x509.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: new(big.Int).Lsh(big.NewInt(1), 100),
+		Subject:      pkix.Name{CommonName: "www.example.com"},
+		DNSNames:     []string{"www.example.com"},
+		NotBefore:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if modify != nil {
+		modify(template)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pub, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestLint(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p224, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsa1024, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		desc    string
+		modify  func(*x509.Certificate)
+		pub     interface{}
+		priv    interface{}
+		profile string
+		want    []string // check names of the findings
+	}{
+		{desc: "clean", profile: "webpki"},
+		{desc: "clean-basic", profile: "basic"},
+		{
+			desc:    "small-serial",
+			modify:  func(c *x509.Certificate) { c.SerialNumber = big.NewInt(42) },
+			profile: "webpki",
+			want:    []string{"serial_entropy"},
+		},
+		{
+			desc:    "small-serial-basic",
+			modify:  func(c *x509.Certificate) { c.SerialNumber = big.NewInt(42) },
+			profile: "basic",
+		},
+		{
+			desc:    "long-serial",
+			modify:  func(c *x509.Certificate) { c.SerialNumber = new(big.Int).Lsh(big.NewInt(1), 160) },
+			profile: "basic",
+			want:    []string{"serial_entropy"},
+		},
+		{
+			desc:    "cn-not-in-san",
+			modify:  func(c *x509.Certificate) { c.Subject.CommonName = "other.example.com" },
+			profile: "basic",
+			want:    []string{"san_cn_consistency"},
+		},
+		{
+			desc:    "cn-case",
+			modify:  func(c *x509.Certificate) { c.Subject.CommonName = "WWW.Example.com" },
+			profile: "basic",
+		},
+		{
+			desc:    "no-san",
+			modify:  func(c *x509.Certificate) { c.DNSNames = nil },
+			profile: "basic",
+			want:    []string{"san_cn_consistency"},
+		},
+		{
+			desc:    "small-rsa",
+			pub:     &rsa1024.PublicKey,
+			priv:    rsa1024,
+			profile: "webpki",
+			want:    []string{"key_size"},
+		},
+		{
+			desc:    "p224",
+			pub:     &p224.PublicKey,
+			priv:    p224,
+			profile: "webpki",
+			want:    []string{"key_size"},
+		},
+		{
+			desc:    "long-validity",
+			modify:  func(c *x509.Certificate) { c.NotAfter = c.NotBefore.AddDate(2, 0, 0) },
+			profile: "webpki",
+			want:    []string{"validity_span"},
+		},
+		{
+			desc: "long-validity-ca",
+			modify: func(c *x509.Certificate) {
+				c.NotAfter = c.NotBefore.AddDate(10, 0, 0)
+				c.IsCA, c.BasicConstraintsValid = true, true
+				c.DNSNames = nil
+			},
+			profile: "webpki",
+		},
+		{
+			desc: "forbidden-extension",
+			modify: func(c *x509.Certificate) {
+				c.ExtraExtensions = []pkix.Extension{{Id: lintOIDForbidden, Value: asn1.NullBytes}}
+			},
+			profile: "forbidden",
+			want:    []string{"forbidden_extensions"},
+		},
+		{
+			desc:    "selected-checks",
+			modify:  func(c *x509.Certificate) { c.SerialNumber = big.NewInt(42); c.DNSNames = nil },
+			profile: "key-size-only",
+		},
+		{
+			desc:    "unknown-check",
+			profile: "unknown-check",
+			want:    []string{"no_such_check"},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			pub, priv := test.pub, test.priv
+			if pub == nil {
+				pub, priv = &p256.PublicKey, p256
+			}
+			cert := lintCert(t, test.modify, pub, priv)
+
+			var profile *x509util.LintProfile
+			switch test.profile {
+			case "forbidden":
+				profile = &x509util.LintProfile{ForbiddenExtensions: []asn1.ObjectIdentifier{lintOIDForbidden}}
+			case "key-size-only":
+				profile = &x509util.LintProfile{Checks: []string{"key_size"}, MinSerialBits: 64}
+			case "unknown-check":
+				profile = &x509util.LintProfile{Checks: []string{"no_such_check"}}
+			default:
+				profile, err = x509util.LintProfileByName(test.profile)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			findings := x509util.Lint(cert, profile)
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.Check)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(test.want, ",") {
+				t.Errorf("Lint()=%v; want findings from %v", findings, test.want)
+			}
+		})
+	}
+}
+
+func TestRegisterLintCheck(t *testing.T) {
+	if err := x509util.RegisterLintCheck(x509util.LintCheck{Name: "key_size", Run: func(*x509.Certificate, *x509util.LintProfile) []x509util.LintFinding { return nil }}); err == nil {
+		t.Error("RegisterLintCheck(duplicate)=nil; want error")
+	}
+
+	check := x509util.LintCheck{
+		Name: "test_no_email",
+		Run: func(cert *x509.Certificate, _ *x509util.LintProfile) []x509util.LintFinding {
+			if len(cert.EmailAddresses) > 0 {
+				return []x509util.LintFinding{{Severity: x509util.LintNotice, Message: "has email"}}
+			}
+			return nil
+		},
+	}
+	if err := x509util.RegisterLintCheck(check); err != nil {
+		t.Fatalf("RegisterLintCheck()=%v; want nil", err)
+	}
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := lintCert(t, func(c *x509.Certificate) { c.EmailAddresses = []string{"a@example.com"} }, &priv.PublicKey, priv)
+	findings := x509util.Lint(cert, &x509util.LintProfile{Checks: []string{"test_no_email"}})
+	if len(findings) != 1 || findings[0].Check != "test_no_email" {
+		t.Fatalf("Lint()=%v; want one test_no_email finding", findings)
+	}
+	if sev, ok := x509util.LintMaxSeverity(findings); !ok || sev != x509util.LintNotice {
+		t.Errorf("LintMaxSeverity()=%v,%v; want notice,true", sev, ok)
+	}
+}
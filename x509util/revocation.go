@@ -0,0 +1,330 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x509util
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/asn1"
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/ocsp"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+)
+
+// DefaultRevocationTimeout is the default timeout of the requests made by a
+// RevocationChecker.
+const DefaultRevocationTimeout = 10 * time.Second
+
+// maxRevocationResponseSize limits the size of the CRLs and OCSP responses
+// fetched.
+const maxRevocationResponseSize = 32 << 20
+
+// RevocationStatus is the revocation status of a certificate.
+type RevocationStatus int
+
+// Revocation statuses.
+const (
+	// RevocationUnknown means the status could not be determined.
+	RevocationUnknown RevocationStatus = iota
+	// RevocationGood means the certificate is not revoked.
+	RevocationGood
+	// RevocationRevoked means the certificate is revoked.
+	RevocationRevoked
+)
+
+func (s RevocationStatus) String() string {
+	switch s {
+	case RevocationUnknown:
+		return "unknown"
+	case RevocationGood:
+		return "good"
+	case RevocationRevoked:
+		return "revoked"
+	}
+	return fmt.Sprintf("RevocationStatus(%d)", int(s))
+}
+
+// Sources of revocation information, as reported in RevocationResult.Source.
+const (
+	RevocationSourceCRL  = "CRL"
+	RevocationSourceOCSP = "OCSP"
+)
+
+// RevocationResult is the status of a certificate according to one source of
+// revocation information.
+type RevocationResult struct {
+	Status RevocationStatus
+	// Source is RevocationSourceCRL or RevocationSourceOCSP.
+	Source string
+	// URL is the CRL distribution point or OCSP responder consulted.
+	URL string
+	// RevokedAt and Reason describe the revocation of revoked certificates.
+	RevokedAt time.Time
+	Reason    x509.RevocationReasonCode
+	// CRL is the CRL consulted, for CRL sources.
+	CRL *x509.CertificateList
+	// Err holds the reason why the status is unknown.
+	Err error
+}
+
+func (r RevocationResult) String() string {
+	var suffix string
+	switch {
+	case r.Status == RevocationRevoked:
+		suffix = fmt.Sprintf(" since %v (%s)", r.RevokedAt, RevocationReasonToString(r.Reason))
+	case r.Err != nil:
+		suffix = fmt.Sprintf(": %v", r.Err)
+	}
+	return fmt.Sprintf("%s %s from %s%s", r.Status, r.Source, r.URL, suffix)
+}
+
+// CombinedRevocationStatus returns the overall status given by results: a
+// certificate is revoked if any source says so, and good if it is not
+// revoked and some source says it is good.
+func CombinedRevocationStatus(results []RevocationResult) RevocationStatus {
+	status := RevocationUnknown
+	for _, r := range results {
+		if r.Status > status {
+			status = r.Status
+		}
+	}
+	return status
+}
+
+// RevocationChecker checks the revocation status of certificates against
+// their CRL distribution points and OCSP responders. CRLs and OCSP responses
+// are cached until their next update. It is safe for concurrent use.
+type RevocationChecker struct {
+	// Client is used for the requests; if nil, http.DefaultClient is used.
+	Client *http.Client
+	// Timeout bounds each request; if zero, DefaultRevocationTimeout is
+	// used.
+	Timeout time.Duration
+	// Now returns the current time; if nil, time.Now is used.
+	Now func() time.Time
+
+	mu    sync.Mutex
+	crls  map[string]*x509.CertificateList
+	ocsps map[string]*ocsp.Response
+}
+
+func (rc *RevocationChecker) now() time.Time {
+	if rc.Now != nil {
+		return rc.Now()
+	}
+	return time.Now()
+}
+
+// Check consults all the CRL distribution points and OCSP responders of cert,
+// and returns their results. issuer is the certificate which issued cert; it
+// is needed for OCSP, and to check the signatures of CRLs. If issuer is nil,
+// OCSP responders are reported as unknown and CRL signatures are not checked.
+func (rc *RevocationChecker) Check(ctx context.Context, cert, issuer *x509.Certificate) []RevocationResult {
+	var results []RevocationResult
+	for _, url := range cert.CRLDistributionPoints {
+		results = append(results, rc.checkCRL(ctx, cert, issuer, url))
+	}
+	for _, url := range cert.OCSPServer {
+		results = append(results, rc.checkOCSP(ctx, cert, issuer, url))
+	}
+	return results
+}
+
+func (rc *RevocationChecker) checkCRL(ctx context.Context, cert, issuer *x509.Certificate, url string) RevocationResult {
+	result := RevocationResult{Source: RevocationSourceCRL, URL: url}
+	crl, err := rc.getCRL(ctx, url)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.CRL = crl
+	if issuer != nil {
+		if err := issuer.CheckCertificateListSignature(crl); err != nil {
+			result.Err = fmt.Errorf("CRL from %q not signed by issuer: %v", url, err)
+			return result
+		}
+	}
+	if crl.TBSCertList.IsDeltaCRL() {
+		result.Err = errors.New("delta CRLs are not supported")
+		return result
+	}
+	var certIssuer pkix.RDNSequence
+	if _, err := asn1.Unmarshal(cert.RawIssuer, &certIssuer); err != nil || crl.TBSCertList.Issuer.String() != certIssuer.String() {
+		result.Err = fmt.Errorf("CRL issuer %q is not the certificate issuer", crl.TBSCertList.Issuer)
+		return result
+	}
+	for _, revoked := range crl.TBSCertList.RevokedCertificates {
+		if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+			result.Status = RevocationRevoked
+			result.RevokedAt = revoked.RevocationTime
+			result.Reason = revoked.RevocationReason
+			return result
+		}
+	}
+	result.Status = RevocationGood
+	return result
+}
+
+// getCRL returns the CRL at url, from the cache if it is still current.
+func (rc *RevocationChecker) getCRL(ctx context.Context, url string) (*x509.CertificateList, error) {
+	now := rc.now()
+	rc.mu.Lock()
+	crl, ok := rc.crls[url]
+	rc.mu.Unlock()
+	if ok && !crlExpired(crl, now) {
+		return crl, nil
+	}
+
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("unsupported CRL URL %q", url)
+	}
+	data, err := rc.do(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	crl, err = x509.ParseCertificateList(data)
+	if x509.IsFatal(err) {
+		return nil, fmt.Errorf("failed to parse CRL from %q: %v", url, err)
+	}
+	if crlExpired(crl, now) {
+		return nil, fmt.Errorf("CRL from %q expired at %v", url, crl.TBSCertList.NextUpdate)
+	}
+
+	rc.mu.Lock()
+	if rc.crls == nil {
+		rc.crls = make(map[string]*x509.CertificateList)
+	}
+	rc.crls[url] = crl
+	rc.mu.Unlock()
+	return crl, nil
+}
+
+// crlExpired reports whether crl is past its next update; CRLs without a
+// next update do not expire.
+func crlExpired(crl *x509.CertificateList, now time.Time) bool {
+	return !crl.TBSCertList.NextUpdate.IsZero() && crl.ExpiredAt(now)
+}
+
+func (rc *RevocationChecker) checkOCSP(ctx context.Context, cert, issuer *x509.Certificate, url string) RevocationResult {
+	result := RevocationResult{Source: RevocationSourceOCSP, URL: url}
+	if issuer == nil {
+		result.Err = errors.New("issuer certificate needed for OCSP")
+		return result
+	}
+	resp, err := rc.getOCSP(ctx, cert, issuer, url)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	switch resp.Status {
+	case ocsp.Good:
+		result.Status = RevocationGood
+	case ocsp.Revoked:
+		result.Status = RevocationRevoked
+		result.RevokedAt = resp.RevokedAt
+		result.Reason = x509.RevocationReasonCode(resp.RevocationReason)
+	default:
+		result.Err = errors.New("responder does not know the certificate")
+	}
+	return result
+}
+
+// getOCSP returns the response of the OCSP responder at url for cert, from
+// the cache if it is still current.
+func (rc *RevocationChecker) getOCSP(ctx context.Context, cert, issuer *x509.Certificate, url string) (*ocsp.Response, error) {
+	keyHash := sha256.Sum256(issuer.RawSubjectPublicKeyInfo)
+	key := fmt.Sprintf("%s|%x|%s", url, keyHash, cert.SerialNumber)
+	now := rc.now()
+	rc.mu.Lock()
+	resp, ok := rc.ocsps[key]
+	rc.mu.Unlock()
+	if ok && now.Before(resp.NextUpdate) {
+		return resp, nil
+	}
+
+	req, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCSP request: %v", err)
+	}
+	data, err := rc.do(ctx, http.MethodPost, url, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err = ocsp.ParseResponseForCert(data, cert, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OCSP response from %q: %v", url, err)
+	}
+	if !resp.NextUpdate.IsZero() && now.After(resp.NextUpdate) {
+		return nil, fmt.Errorf("OCSP response from %q expired at %v", url, resp.NextUpdate)
+	}
+
+	if !resp.NextUpdate.IsZero() {
+		rc.mu.Lock()
+		if rc.ocsps == nil {
+			rc.ocsps = make(map[string]*ocsp.Response)
+		}
+		rc.ocsps[key] = resp
+		rc.mu.Unlock()
+	}
+	return resp, nil
+}
+
+// do makes an HTTP request with the checker's timeout, and returns the body
+// of a successful response.
+func (rc *RevocationChecker) do(ctx context.Context, method, url string, body []byte) ([]byte, error) {
+	timeout := rc.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRevocationTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request for %q: %v", url, err)
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/ocsp-request")
+	}
+	client := rc.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s %q: %v", method, url, err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to %s %q: HTTP status %q", method, url, rsp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(rsp.Body, maxRevocationResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from %q: %v", url, err)
+	}
+	if len(data) > maxRevocationResponseSize {
+		return nil, fmt.Errorf("response from %q is larger than %d bytes", url, maxRevocationResponseSize)
+	}
+	return data, nil
+}
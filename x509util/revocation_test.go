@@ -0,0 +1,189 @@
+// Copyright 2026 Google LLC. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x509util_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RarimoVoting/certificate-transparency-go/x509"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/ocsp"
+	"github.com/RarimoVoting/certificate-transparency-go/x509/pkix"
+	"github.com/RarimoVoting/certificate-transparency-go/x509util"
+)
+
+func TestRevocationChecker(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Hour).UTC().Truncate(time.Second)
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Revocation CA"},
+		NotBefore:             now.Add(-24 * time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:              big.NewInt(1),
+		ThisUpdate:          now.Add(-time.Hour),
+		NextUpdate:          now.Add(time.Hour),
+		RevokedCertificates: []x509.RevocationListEntry{{SerialNumber: big.NewInt(3), RevocationTime: revokedAt, ReasonCode: x509.KeyCompromise}},
+	}, ca, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/crl":
+			_, _ = w.Write(crl)
+		case "/ocsp":
+			body, _ := io.ReadAll(r.Body)
+			req, err := ocsp.ParseRequest(body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			template := ocsp.Response{Status: ocsp.Good, SerialNumber: req.SerialNumber, ThisUpdate: now.Add(-time.Hour), NextUpdate: now.Add(time.Hour)}
+			if req.SerialNumber.Cmp(big.NewInt(3)) == 0 {
+				template.Status, template.RevokedAt, template.RevocationReason = ocsp.Revoked, revokedAt, ocsp.KeyCompromise
+			}
+			resp, err := ocsp.CreateResponse(ca, ca, template, caKey)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write(resp)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	requestCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+
+	leaf := func(serial int64, crlURL string) *x509.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(serial),
+			Subject:               pkix.Name{CommonName: "leaf"},
+			NotBefore:             now.Add(-time.Hour),
+			NotAfter:              now.Add(time.Hour),
+			CRLDistributionPoints: []string{srv.URL + crlURL},
+			OCSPServer:            []string{srv.URL + "/ocsp"},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+
+	ctx := context.Background()
+	rc := &x509util.RevocationChecker{Now: func() time.Time { return now }}
+	for _, test := range []struct {
+		desc   string
+		cert   *x509.Certificate
+		issuer *x509.Certificate
+		want   []x509util.RevocationStatus // CRL, then OCSP
+	}{
+		{desc: "good", cert: leaf(2, "/crl"), issuer: ca, want: []x509util.RevocationStatus{x509util.RevocationGood, x509util.RevocationGood}},
+		{desc: "revoked", cert: leaf(3, "/crl"), issuer: ca, want: []x509util.RevocationStatus{x509util.RevocationRevoked, x509util.RevocationRevoked}},
+		{desc: "no-issuer", cert: leaf(3, "/crl"), want: []x509util.RevocationStatus{x509util.RevocationRevoked, x509util.RevocationUnknown}},
+		{desc: "missing-crl", cert: leaf(2, "/missing"), issuer: ca, want: []x509util.RevocationStatus{x509util.RevocationUnknown, x509util.RevocationGood}},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			results := rc.Check(ctx, test.cert, test.issuer)
+			if len(results) != len(test.want) {
+				t.Fatalf("Check()=%v; want %d results", results, len(test.want))
+			}
+			for i, r := range results {
+				if r.Status != test.want[i] {
+					t.Errorf("Check()[%d]=%v; want status %v", i, r, test.want[i])
+				}
+				if r.Status == x509util.RevocationRevoked && (!r.RevokedAt.Equal(revokedAt) || r.Reason != x509.KeyCompromise) {
+					t.Errorf("Check()[%d]=%v; want revoked at %v for key compromise", i, r, revokedAt)
+				}
+				if (r.Status == x509util.RevocationUnknown) != (r.Err != nil) {
+					t.Errorf("Check()[%d]=%v; want an error only for unknown status", i, r)
+				}
+			}
+			want := x509util.RevocationGood
+			if test.want[0] == x509util.RevocationRevoked {
+				want = x509util.RevocationRevoked
+			}
+			if got := x509util.CombinedRevocationStatus(results); got != want {
+				t.Errorf("CombinedRevocationStatus()=%v; want %v", got, want)
+			}
+		})
+	}
+
+	// The CRL and OCSP responses are cached until their next update.
+	cert := leaf(3, "/crl")
+	rc.Check(ctx, cert, ca)
+	before := requestCount()
+	rc.Check(ctx, cert, ca)
+	if got := requestCount(); got != before {
+		t.Errorf("Check() made %d requests; want 0 with cached responses", got-before)
+	}
+	rc.Now = func() time.Time { return now.Add(2 * time.Hour) }
+	results := rc.Check(ctx, cert, ca)
+	if got := requestCount(); got != before+2 {
+		t.Errorf("Check() made %d requests; want 2 after expiry", got-before)
+	}
+	for _, r := range results {
+		if r.Status != x509util.RevocationUnknown {
+			t.Errorf("Check()=%v; want unknown status for stale data", r)
+		}
+	}
+}
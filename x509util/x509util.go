@@ -135,6 +135,8 @@ func curveOIDToString(oid asn1.ObjectIdentifier) (t string, bitlen int) {
 		return "secp521r1", 521
 	case oid.Equal(x509.OIDNamedCurveP192):
 		return "secp192r1", 192
+	case oid.Equal(x509.OIDNamedCurveSM2):
+		return "SM2", 256
 	}
 	return fmt.Sprintf("%v", oid), -1
 }